@@ -2,24 +2,59 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/cobra"
+	"ga4admin/internal/access"
+	"ga4admin/internal/audit"
+	"ga4admin/internal/backfill"
 	"ga4admin/internal/api"
+	"ga4admin/internal/ask"
 	"ga4admin/internal/cache"
+	"ga4admin/internal/catalog"
+	"ga4admin/internal/chart"
+	"ga4admin/internal/channelgroup"
 	"ga4admin/internal/config"
+	"ga4admin/internal/customdims"
+	"ga4admin/internal/dictionary"
+	"ga4admin/internal/doctor"
+	"ga4admin/internal/exitcode"
 	"ga4admin/internal/export"
+	"ga4admin/internal/htmlreport"
+	"ga4admin/internal/mcp"
+	"ga4admin/internal/notify"
+	"ga4admin/internal/pipeline"
+	"ga4admin/internal/plugin"
 	"ga4admin/internal/preset"
+	"ga4admin/internal/propertyspec"
 	"ga4admin/internal/query"
+	"ga4admin/internal/report"
 	"ga4admin/internal/results"
+	"ga4admin/internal/selfupdate"
+	"ga4admin/internal/serve"
+	"ga4admin/internal/workspace"
 )
 
 var (
 	version = "0.1.0"
+
+	// Overall command timeout from --timeout or config; 0 keeps per-command defaults
+	commandTimeout time.Duration
+
 	rootCmd = &cobra.Command{
 		Use:   "ga4admin",
 		Short: "GA4 Admin Tool for exploring and exporting Google Analytics 4 data",
@@ -31,7 +66,10 @@ Examples:
   ga4admin preset create tmobile --refresh-token <token>
   ga4admin accounts list
   ga4admin properties list --account <id>
-  ga4admin metadata dimensions --property <id>`,
+  ga4admin metadata dimensions --property <id>
+
+Exit codes: 0 success, 1 other failure, 2 authentication, 3 quota,
+4 validation, 5 network, 6 not found.`,
 		Version: version,
 	}
 
@@ -88,24 +126,215 @@ Examples:
 		Short: "Export configurations",
 		Long:  "Export Clarisights configurations and data extracts",
 	}
+
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Run curated reports",
+		Long:  "Run built-in reports (acquisition, landing pages, ecommerce funnel, geo) without knowing GA4 field names",
+	}
+
+	analyzeCmd = &cobra.Command{
+		Use:   "analyze",
+		Short: "Audit GA4 property setup",
+		Long:  "Cross-check GA4 configuration against collected data to find tracking gaps",
+	}
+
+	channelGroupsCmd = &cobra.Command{
+		Use:   "channelgroups",
+		Short: "Inspect channel groups",
+		Long:  "Show custom channel group rules and lint them against observed traffic",
+	}
+
+	fieldsCmd = &cobra.Command{
+		Use:   "fields",
+		Short: "Look up standard GA4 dimensions and metrics offline",
+		Long:  "Browse the bundled catalog of standard GA4 dimensions and metrics without credentials or a property",
+	}
+
+	aliasCmd = &cobra.Command{
+		Use:   "alias",
+		Short: "Manage property aliases",
+		Long:  "Name properties in the active preset so any --property flag accepts the alias instead of the numeric ID",
+	}
+
+	backfillCmd = &cobra.Command{
+		Use:   "backfill",
+		Short: "Fill gaps in named result tables",
+		Long:  "Find the date chunks of a query missing from its named result tables and run the queries that fill them",
+	}
+
+	pipelineCmd = &cobra.Command{
+		Use:   "pipeline",
+		Short: "Append daily query results to DuckDB tables",
+		Long:  "Define pipelines that append a query's rows to a DuckDB table, loading only the days the table doesn't have yet",
+	}
+
+	customDimsCmd = &cobra.Command{
+		Use:   "customdims",
+		Short: "Manage custom dimensions",
+		Long:  "Register and archive custom dimensions to match a declared spec",
+	}
+
+	applyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Apply a declarative property configuration",
+		Long: `Diff a YAML or JSON property file against the property's live configuration,
+print a plan and apply it after confirmation.
+
+The file may declare custom_dimensions, key_events, channel_groups and
+data_retention. Sections that are left out are not touched. A section that is
+present lists everything of that kind the property should have: items missing
+from it are archived or deleted unless --keep-unlisted is set. Built-in key
+events and system channel groups are never removed.
+
+Example property.yaml:
+
+  property: prod-web
+  custom_dimensions:
+    - parameter_name: plan_type
+      display_name: Plan Type
+  key_events:
+    - event_name: sign_up
+      counting_method: ONCE_PER_SESSION
+  data_retention:
+    event_data_retention: FOURTEEN_MONTHS
+
+Applying needs a refresh token granted the analytics.edit scope.`,
+		Run: applyCmdHandler,
+	}
+
+	pluginCmd = &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage plugins",
+		Long: `Plugins add commands without changing ga4admin: an executable named
+ga4admin-<name> on PATH runs as 'ga4admin <name>'. It gets the arguments after
+its name and the invocation's context in environment variables:
+` + config.HomeEnvVar + `, ` + plugin.PresetEnvVar + `, ` + plugin.PropertyEnvVar + `, ` + plugin.BinEnvVar + ` and ` + plugin.VersionEnvVar + `.
+--home, --preset and --property may be given before the plugin's name.`,
+	}
+
+	workspaceCmd = &cobra.Command{
+		Use:   "workspace",
+		Short: "Report across properties from several presets",
+		Long:  "Group properties reached through different presets (e.g. one per client account) and run the same report across all of them",
+	}
+
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Review configuration changes",
+		Long:  "Review the local log of every GA4 configuration change made with this preset",
+	}
+
+	streamsCmd = &cobra.Command{
+		Use:   "streams",
+		Short: "Inspect data streams",
+		Long:  "List a property's web and app data streams and show their tag settings",
+	}
+
+	linksCmd = &cobra.Command{
+		Use:   "links",
+		Short: "Audit product links",
+		Long:  "List BigQuery, Google Ads and Firebase links per property and find properties missing expected links",
+	}
+
+	quotaCmd = &cobra.Command{
+		Use:   "quota",
+		Short: "Property quota usage",
+		Long:  "Review and forecast a property's Data API token consumption",
+	}
+
+	selfUpdateCmd = &cobra.Command{
+		Use:   "self-update",
+		Short: "Update ga4admin to the latest release",
+		Long: `Check the release feed for a newer ga4admin, verify the download against the
+signed checksums published with the release, and replace this binary in place.
+
+Examples:
+  ga4admin self-update --check
+  ga4admin self-update
+  ga4admin self-update --channel beta --yes`,
+		Args: cobra.NoArgs,
+		Run:  selfUpdateCmdHandler,
+	}
+
+	versionCmd = &cobra.Command{
+		Use:   "version",
+		Short: "Show the version and check for updates",
+		Long: `Show the installed version, platform and install method. With --check, look up
+the latest release and print how to upgrade: brew or scoop for package manager
+installs, 'ga4admin self-update' otherwise.
+
+Once a day other commands check for a new release in the background and print a
+one-line notice when one is out. Set ` + selfupdate.NoticeEnvVar + ` to turn this off.
+
+Examples:
+  ga4admin version
+  ga4admin version --check`,
+		Args: cobra.NoArgs,
+		Run:  versionCmdHandler,
+	}
+
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the installation for problems",
+		Long: `Lint config.yaml and the preset files, check that credentials are not readable
+by other users (directories 0700, files 0600), open each preset's cache and
+connect to the Google OAuth, Admin API and Data API endpoints. Every check that
+doesn't pass comes with a fix.
+
+Examples:
+  ga4admin doctor
+  ga4admin doctor --skip-network`,
+		Args: cobra.NoArgs,
+		Run:  doctorCmdHandler,
+	}
 )
 
 func init() {
 	// Global flags
+	rootCmd.PersistentFlags().String("home", "", "Data directory for config, presets and caches (overrides "+config.HomeEnvVar+", default ~/.ga4admin)")
 	rootCmd.PersistentFlags().String("preset", "", "GA4 preset to use (overrides active preset)")
 	rootCmd.PersistentFlags().Bool("verbose", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Overall command timeout, e.g. 5m (overrides config and per-command defaults)")
+	rootCmd.PersistentFlags().Duration("request-timeout", 0, "Per HTTP request timeout, e.g. 45s (overrides config)")
+	rootCmd.PersistentFlags().String("trace", "", "Append sanitized API requests/responses to this file")
+	rootCmd.PersistentFlags().String("record-fixtures", "", "Record API responses into this directory for replay via "+api.ReplayEnvVar)
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		// Everything below reads the data directory, so it has to be set first
+		if home, _ := cmd.Flags().GetString("home"); home != "" {
+			if err := config.SetHomeDir(home); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitcode.For(err))
+			}
+		}
+		applyNetworkSettings(cmd, args)
+		resolvePropertyFlag(cmd)
+		startUpdateCheck(cmd)
+	}
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		waitForUpdateCheck()
+	}
 
 	// Config subcommands
 	configSetCmd := &cobra.Command{
 		Use:   "set",
-		Short: "Set global OAuth credentials",
-		Long:  "Configure global OAuth client credentials used across all presets",
+		Short: "Set global configuration",
+		Long:  "Configure global OAuth client credentials and options used across all presets",
 		Run:   configSetCmdHandler,
 	}
-	configSetCmd.Flags().String("client-id", "", "Google OAuth client ID (required)")
-	configSetCmd.Flags().String("client-secret", "", "Google OAuth client secret (required)")
-	configSetCmd.MarkFlagRequired("client-id")
-	configSetCmd.MarkFlagRequired("client-secret")
+	configSetCmd.Flags().String("client-id", "", "Google OAuth client ID")
+	configSetCmd.Flags().String("client-secret", "", "Google OAuth client secret")
+	configSetCmd.Flags().String("data-api-transport", "", "Data API transport: rest or grpc")
+	configSetCmd.Flags().String("admin-api-version", "", "Admin API version: auto, v1beta or v1alpha")
+	configSetCmd.Flags().String("request-timeout", "", "Default per HTTP request timeout, e.g. 45s (empty to clear)")
+	configSetCmd.Flags().String("command-timeout", "", "Default overall command timeout, e.g. 5m (empty to clear)")
+	configSetCmd.Flags().String("proxy", "", "HTTP(S) proxy URL (empty to use HTTP(S)_PROXY)")
+	configSetCmd.Flags().String("ca-bundle", "", "PEM file with additional trusted root CAs (empty to clear)")
+	configSetCmd.Flags().Int("max-conns-per-host", 0, fmt.Sprintf("Open connections per API host (0 for the default of %d)", api.DefaultMaxConnsPerHost))
+	configSetCmd.Flags().String("admin-api-endpoint", "", "Admin API base URL, e.g. a mock server (empty to reset)")
+	configSetCmd.Flags().String("data-api-endpoint", "", "Data API base URL, e.g. a regional endpoint (empty to reset)")
+	configSetCmd.Flags().String("assistant-endpoint", "", "OpenAI-compatible chat completions URL 'ask' may use (empty to turn off)")
+	configSetCmd.Flags().String("assistant-model", "", "Model to request from the assistant endpoint")
 	
 	configShowCmd := &cobra.Command{
 		Use:   "show", 
@@ -114,20 +343,140 @@ func init() {
 		Run:   configShowCmdHandler,
 	}
 
-	configCmd.AddCommand(configSetCmd, configShowCmd)
+	configNotifierCmd := &cobra.Command{
+		Use:   "notifier",
+		Short: "Manage export notifiers",
+		Long:  "Configure named SMTP or SendGrid notifiers that email exported results as attachments or links",
+	}
+
+	configNotifierSetCmd := &cobra.Command{
+		Use:   "set [name]",
+		Short: "Create or replace a notifier",
+		Args:  cobra.ExactArgs(1),
+		Run:   configNotifierSetCmdHandler,
+	}
+	configNotifierSetCmd.Flags().String("type", "", "Notifier type: smtp or sendgrid (required)")
+	configNotifierSetCmd.Flags().String("from", "", "Sender address (required)")
+	configNotifierSetCmd.Flags().StringSlice("to", []string{}, "Recipient addresses (comma-separated, required)")
+	configNotifierSetCmd.Flags().String("delivery", config.DeliveryAttachment, "Deliver exports as attachment or link")
+	configNotifierSetCmd.Flags().String("link-base-url", "", "URL where the export directory is served (link delivery)")
+	configNotifierSetCmd.Flags().String("smtp-host", "", "SMTP server host")
+	configNotifierSetCmd.Flags().Int("smtp-port", 587, "SMTP server port (465 uses implicit TLS)")
+	configNotifierSetCmd.Flags().String("smtp-username", "", "SMTP username")
+	configNotifierSetCmd.Flags().String("smtp-password-env", "", "Environment variable holding the SMTP password")
+	configNotifierSetCmd.Flags().String("sendgrid-api-key-env", "", "Environment variable holding the SendGrid API key")
+	configNotifierSetCmd.MarkFlagRequired("type")
+	configNotifierSetCmd.MarkFlagRequired("from")
+	configNotifierSetCmd.MarkFlagRequired("to")
+
+	configNotifierListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List notifiers",
+		Run:   configNotifierListCmdHandler,
+	}
+
+	configNotifierRemoveCmd := &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a notifier",
+		Args:  cobra.ExactArgs(1),
+		Run:   configNotifierRemoveCmdHandler,
+	}
+
+	configNotifierTestCmd := &cobra.Command{
+		Use:   "test [name]",
+		Short: "Send a test message through a notifier",
+		Args:  cobra.ExactArgs(1),
+		Run:   configNotifierTestCmdHandler,
+	}
+
+	configNotifierCmd.AddCommand(configNotifierSetCmd, configNotifierListCmd, configNotifierRemoveCmd, configNotifierTestCmd)
+
+	configChannelsCmd := &cobra.Command{
+		Use:   "channels",
+		Short: "Manage channel mappings",
+		Long: `Define your own channel taxonomy as ordered rules on source, medium and
+campaign. 'results show', 'results export' and 'results chart' add the
+channel as a column with --channels <mapping>.
+
+Patterns are case-insensitive regular expressions that must match the whole
+value; a rule matches when all of its patterns do, and the first matching
+rule wins.
+
+Examples:
+  ga4admin config channels add ours --channel "Paid Social" --source "facebook|instagram|linkedin" --medium "cpc|paid.*"
+  ga4admin config channels add ours --channel "Partners" --campaign "partner_.*"
+  ga4admin config channels test ours --source instagram --medium paid_social`,
+	}
+
+	configChannelsAddCmd := &cobra.Command{
+		Use:   "add [mapping]",
+		Short: "Add a rule to a channel mapping, creating it if needed",
+		Args:  cobra.ExactArgs(1),
+		Run:   configChannelsAddCmdHandler,
+	}
+	configChannelsAddCmd.Flags().String("channel", "", "Channel assigned by the rule (required)")
+	configChannelsAddCmd.Flags().String("source", "", "Pattern the source must match")
+	configChannelsAddCmd.Flags().String("medium", "", "Pattern the medium must match")
+	configChannelsAddCmd.Flags().String("campaign", "", "Pattern the campaign must match")
+	configChannelsAddCmd.Flags().Int("position", 0, "Insert the rule at this position, starting at 1 (default: last)")
+	configChannelsAddCmd.Flags().String("column", "", "Name of the added column (default \"channel\")")
+	configChannelsAddCmd.Flags().String("default", "", "Channel of rows no rule matches (default \"Unassigned\")")
+	configChannelsAddCmd.MarkFlagRequired("channel")
+
+	configChannelsListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List channel mappings and their rules",
+		Run:   configChannelsListCmdHandler,
+	}
+
+	configChannelsRemoveCmd := &cobra.Command{
+		Use:   "remove [mapping]",
+		Short: "Remove a channel mapping or one of its rules",
+		Args:  cobra.ExactArgs(1),
+		Run:   configChannelsRemoveCmdHandler,
+	}
+	configChannelsRemoveCmd.Flags().Int("rule", 0, "Remove only the rule at this position")
+
+	configChannelsTestCmd := &cobra.Command{
+		Use:   "test [mapping]",
+		Short: "Show the channel a source, medium and campaign map to",
+		Args:  cobra.ExactArgs(1),
+		Run:   configChannelsTestCmdHandler,
+	}
+	configChannelsTestCmd.Flags().String("source", "", "Source to test, e.g. google")
+	configChannelsTestCmd.Flags().String("medium", "", "Medium to test, e.g. cpc")
+	configChannelsTestCmd.Flags().String("campaign", "", "Campaign to test")
+
+	configChannelsCmd.AddCommand(configChannelsAddCmd, configChannelsListCmd, configChannelsRemoveCmd, configChannelsTestCmd)
+
+	configCmd.AddCommand(configSetCmd, configShowCmd, configNotifierCmd, configChannelsCmd)
 
 	// Preset subcommands
 	presetCreateCmd := &cobra.Command{
 		Use:   "create [name]",
 		Short: "Create a new preset",
-		Long:  "Create a new GA4 preset with refresh token for API access",
+		Long: `Create a new GA4 preset with refresh token for API access.
+
+Instead of a refresh token, --service-account authenticates with a service
+account JSON key. With --impersonate the service account acts as that user
+through Google Workspace domain-wide delegation, so GA4 sees the user's
+access; the delegation must grant the service account's client ID every scope
+in --scopes. The key file stays where it is; the preset stores its path.
+
+--scopes names the OAuth scopes the refresh token was granted: readonly (always
+included) and edit, which commands that change configuration ('apply',
+'customdims apply') and 'properties changelog' need. Unless --no-validate is
+given, the token is checked with Google and creation fails if it lacks a
+scope you named.`,
 		Args:  cobra.ExactArgs(1),
 		Run:   presetCreateCmdHandler,
 	}
-	presetCreateCmd.Flags().String("refresh-token", "", "Google OAuth refresh token (required)")
+	presetCreateCmd.Flags().String("refresh-token", "", "Google OAuth refresh token (required unless --service-account is given)")
 	presetCreateCmd.Flags().String("user-email", "", "User email for identification (optional)")
 	presetCreateCmd.Flags().Bool("no-validate", false, "Skip refresh token validation (advanced users only)")
-	presetCreateCmd.MarkFlagRequired("refresh-token")
+	presetCreateCmd.Flags().StringSlice("scopes", []string{"readonly"}, "OAuth scopes granted to the refresh token (readonly, edit)")
+	presetCreateCmd.Flags().String("service-account", "", "Service account JSON key file, used instead of --refresh-token")
+	presetCreateCmd.Flags().String("impersonate", "", "User the service account acts as through domain-wide delegation")
 
 	presetListCmd := &cobra.Command{
 		Use:   "list",
@@ -152,19 +501,89 @@ func init() {
 		Run:   presetUseCmdHandler,
 	}
 
-	presetCmd.AddCommand(presetCreateCmd, presetListCmd, presetDeleteCmd, presetUseCmd)
+	presetSyncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync accounts and properties for the active preset",
+		Long:  "Fetch all accounts and properties visible to the active preset and store them for offline access checks",
+		Run:   presetSyncCmdHandler,
+	}
+
+	presetReauthCmd := &cobra.Command{
+		Use:   "reauth [name]",
+		Short: "Replace a preset's expired refresh token",
+		Long: `Run the Google OAuth flow in a browser and store the new refresh token in the
+preset, keeping its accounts, aliases and cache. Use this when commands report
+that a preset's refresh token has expired or been revoked (invalid_grant).
+
+The flow listens for Google's redirect on a 127.0.0.1 port, so the OAuth client
+must be a Desktop app client. On a machine without a browser, pass a token
+obtained elsewhere with --refresh-token.`,
+		Args: cobra.ExactArgs(1),
+		Run:  presetReauthCmdHandler,
+	}
+	presetReauthCmd.Flags().String("refresh-token", "", "Use this refresh token instead of running the browser flow")
+	presetReauthCmd.Flags().Bool("edit", false, "Also request the analytics.edit scope, needed by commands that change configuration (kept when the preset already has it)")
+	presetReauthCmd.Flags().Bool("no-browser", false, "Print the authorization URL instead of opening a browser")
+	presetReauthCmd.Flags().Bool("device", false, "Sign in with a code entered on another device, for machines without a browser")
+
+	presetLoginCmd := &cobra.Command{
+		Use:   "login [name]",
+		Short: "Create a preset by signing in with Google",
+		Long: `Create a preset by signing in with Google instead of pasting a refresh token.
+
+By default the browser OAuth flow runs, as for 'preset reauth'. On a server
+without a browser, --device prints a short code and a URL: open the URL on any
+other device, enter the code and approve, and the preset is created once
+Google confirms. The device flow needs an OAuth client of type "TVs and
+Limited Input devices" ('ga4admin config set'), and Google decides which
+scopes it will grant that way.
+
+Examples:
+  ga4admin preset login acme
+  ga4admin preset login acme --device
+  ga4admin preset login acme --device --scopes readonly,edit`,
+		Args: cobra.ExactArgs(1),
+		Run:  presetLoginCmdHandler,
+	}
+	presetLoginCmd.Flags().Bool("device", false, "Sign in with a code entered on another device, for machines without a browser")
+	presetLoginCmd.Flags().Bool("no-browser", false, "Print the authorization URL instead of opening a browser")
+	presetLoginCmd.Flags().StringSlice("scopes", []string{"readonly"}, "OAuth scopes to request (readonly, edit)")
+	presetLoginCmd.Flags().String("user-email", "", "User email for identification (optional)")
+
+	presetImpersonateCmd := &cobra.Command{
+		Use:   "impersonate [name] [user-email]",
+		Short: "Change the user a service account preset acts as",
+		Long: `Change the user a service account preset acts as through domain-wide
+delegation, or with --clear make it act as the service account itself. The new
+user is checked by getting a token as them unless --no-validate is given.
+
+Examples:
+  ga4admin preset impersonate acme analyst@acme.com
+  ga4admin preset impersonate acme --clear`,
+		Args: cobra.RangeArgs(1, 2),
+		Run:  presetImpersonateCmdHandler,
+	}
+	presetImpersonateCmd.Flags().Bool("clear", false, "Stop impersonating and act as the service account itself")
+	presetImpersonateCmd.Flags().Bool("no-validate", false, "Skip getting a token as the new user")
+
+	presetCmd.AddCommand(presetCreateCmd, presetLoginCmd, presetListCmd, presetDeleteCmd, presetUseCmd, presetSyncCmd, presetReauthCmd, presetImpersonateCmd)
 
 	// Accounts subcommands
-	accountsCmd.AddCommand(&cobra.Command{
+	accountsListSubCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all accounts",
 		Run:   accountsListCmd,
-	})
-	accountsCmd.AddCommand(&cobra.Command{
+	}
+	accountsListSubCmd.Flags().Bool("refresh", false, "Fetch from the Admin API instead of the preset cache")
+
+	accountsTreeSubCmd := &cobra.Command{
 		Use:   "tree",
 		Short: "Show accounts with properties in tree view",
 		Run:   accountsTreeCmd,
-	})
+	}
+	accountsTreeSubCmd.Flags().Bool("refresh", false, "Fetch from the Admin API instead of the preset cache")
+
+	accountsCmd.AddCommand(accountsListSubCmd, accountsTreeSubCmd)
 
 	// Properties subcommands
 	propertiesListSubCmd := &cobra.Command{
@@ -173,6 +592,7 @@ func init() {
 		Run:   propertiesListCmd,
 	}
 	propertiesListSubCmd.Flags().String("account", "", "Account ID to list properties for (required)")
+	propertiesListSubCmd.Flags().Bool("refresh", false, "Fetch from the Admin API instead of the preset cache")
 	propertiesListSubCmd.MarkFlagRequired("account")
 	propertiesCmd.AddCommand(propertiesListSubCmd)
 	propertiesCmd.AddCommand(&cobra.Command{
@@ -181,6 +601,21 @@ func init() {
 		Args:  cobra.ExactArgs(1),
 		Run:   propertiesShowCmd,
 	})
+	propertiesChangelogSubCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Show who changed what across an account's properties",
+		Long:  "Show the account's change history from the Admin API: created dimensions, edited streams and other configuration changes, with who made them. Unlike 'audit list' this includes changes made in the GA4 UI and by other tools.",
+		Run:   propertiesChangelogCmd,
+	}
+	propertiesChangelogSubCmd.Flags().String("account", "", "Account ID to show changes for (required)")
+	propertiesChangelogSubCmd.Flags().Int("days", 30, "Show changes from the last N days")
+	propertiesChangelogSubCmd.Flags().String("property", "", "Only show changes to this property")
+	propertiesChangelogSubCmd.Flags().StringSlice("resource-type", nil, "Only show changes to these resource types (e.g. custom-dimension,data-stream)")
+	propertiesChangelogSubCmd.Flags().StringSlice("action", nil, "Only show these actions (created, updated, deleted)")
+	propertiesChangelogSubCmd.Flags().StringSlice("actor", nil, "Only show changes made by these email addresses")
+	propertiesChangelogSubCmd.Flags().Bool("details", false, "Show the before and after state of each change")
+	propertiesChangelogSubCmd.MarkFlagRequired("account")
+	propertiesCmd.AddCommand(propertiesChangelogSubCmd)
 
 	// Metadata subcommands
 	metadataDimensionsSubCmd := &cobra.Command{
@@ -211,28 +646,66 @@ func init() {
 	}
 	metadataEventsSubCmd.Flags().String("property", "", "Property ID to analyze events for (required)")
 	metadataEventsSubCmd.Flags().Int("days", 30, "Number of days to analyze (default: 30)")
-	metadataEventsSubCmd.Flags().Int("limit", 50, "Number of top events to show (default: 50)")
+	metadataEventsSubCmd.Flags().Int("limit", 50, "Number of top events to fetch and show (default: 50)")
+	metadataEventsSubCmd.Flags().String("order-by", "eventCount", "Rank events by eventCount or activeUsers")
+	metadataEventsSubCmd.Flags().String("by", "", "Break each event down by a secondary dimension (platform, country, device, or any dimension API name)")
+	metadataEventsSubCmd.Flags().Bool("trend", false, "Show a daily trend sparkline for each event")
 	metadataEventsSubCmd.MarkFlagRequired("property")
 
-	metadataCmd.AddCommand(metadataDimensionsSubCmd, metadataMetricsSubCmd, metadataEventsSubCmd)
+	metadataWarmSubCmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Pre-fetch metadata for every property in an account",
+		Long:  "Fetch and cache dimensions and metrics for all properties in an account so later commands are served from cache",
+		Run:   metadataWarmCmd,
+	}
+	metadataWarmSubCmd.Flags().String("account", "", "Account ID whose properties should be warmed (required)")
+	metadataWarmSubCmd.Flags().Int("concurrency", 4, "Maximum number of properties fetched in parallel")
+	metadataWarmSubCmd.Flags().Bool("force", false, "Refetch metadata even if it is already cached")
+	metadataWarmSubCmd.MarkFlagRequired("account")
+
+	metadataExportSubCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a data dictionary of a property",
+		Long: `Write a human-readable data dictionary of a property: every dimension and
+metric with its name, category, type and description, and the property's
+custom definitions with their scope and parameter. Custom dimension names and
+descriptions are taken from the Admin API, as entered in GA4.
+
+Formats:
+  xlsx  Excel workbook with Dimensions, Metrics and Custom Definitions sheets
+  md    Markdown document grouped by category
+
+Examples:
+  ga4admin metadata export --property 123456789 --format xlsx
+  ga4admin metadata export --property 123456789 --format md --output docs/dictionary.md`,
+		Run: metadataExportCmd,
+	}
+	metadataExportSubCmd.Flags().String("property", "", "Property ID to document (required)")
+	metadataExportSubCmd.Flags().String("format", "xlsx", "Output format (xlsx, md)")
+	metadataExportSubCmd.Flags().String("output", "", "Output file (default: data-dictionary-<property>.<format>)")
+	metadataExportSubCmd.MarkFlagRequired("property")
+
+	metadataCmd.AddCommand(metadataDimensionsSubCmd, metadataMetricsSubCmd, metadataEventsSubCmd, metadataWarmSubCmd, metadataExportSubCmd)
 
 	// Query subcommands
 	queryRunSubCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Execute a GA4 query",
+		Long:  "Execute a GA4 query and cache the result.\n\n" + filterGrammarHelp,
 		Run:   queryRunCmd,
 	}
-	queryRunSubCmd.Flags().String("property", "", "Property ID to query (required)")
-	queryRunSubCmd.Flags().StringSlice("dimensions", []string{}, "Dimension names (comma-separated)")
-	queryRunSubCmd.Flags().StringSlice("metrics", []string{}, "Metric names (comma-separated)")
-	queryRunSubCmd.Flags().String("start-date", "30daysAgo", "Start date (YYYY-MM-DD or relative)")
-	queryRunSubCmd.Flags().String("end-date", "yesterday", "End date (YYYY-MM-DD or relative)")
-	queryRunSubCmd.Flags().Int64("limit", 10000, "Maximum rows to return")
-	queryRunSubCmd.Flags().StringSlice("filters", []string{}, "Filters in format 'field:type:operation:value'")
-	queryRunSubCmd.Flags().String("order-by", "", "Order by field (prefix with - for descending)")
-	queryRunSubCmd.Flags().String("name", "", "Save query with this name")
+	addQueryConfigFlags(queryRunSubCmd)
+	queryRunSubCmd.Flags().String("name", "", "Save the result as a named table")
+	queryRunSubCmd.Flags().Bool("overwrite", false, "With --name, point an existing named table at this result")
+	queryRunSubCmd.Flags().Bool("if-not-exists", false, "With --name, keep an existing named table as it is")
+	queryRunSubCmd.MarkFlagsMutuallyExclusive("overwrite", "if-not-exists")
 	queryRunSubCmd.Flags().Bool("no-cache", false, "Skip cache and force fresh query")
-	queryRunSubCmd.MarkFlagRequired("property")
+	queryRunSubCmd.Flags().Duration("max-age", 0, "Treat cached results fetched longer ago than this as misses, e.g. 30m")
+	queryRunSubCmd.Flags().String("export-stream", "", "Stream all rows page by page into this CSV file, bypassing the cache")
+	queryRunSubCmd.Flags().Int64("page-size", 100000, "Rows per page with --export-stream (max 250000)")
+	queryRunSubCmd.Flags().String("chunk-by", "", "Split the date range into 'month' or 'week' requests and stitch the results")
+	queryRunSubCmd.Flags().Int("top", 0, "Keep only the N rows with the highest first metric")
+	queryRunSubCmd.Flags().Bool("bucket-other", false, "With --top, fold the remaining rows into an \"(other)\" row")
 
 	queryBuildSubCmd := &cobra.Command{
 		Use:   "build",
@@ -247,10 +720,100 @@ func init() {
 		Short: "List cached queries",
 		Run:   queryListCmd,
 	}
-	queryListSubCmd.Flags().String("property", "", "Filter by property ID")
+	queryListSubCmd.Flags().String("property", "", "Filter by property ID (default: all properties)")
+	queryListSubCmd.Flags().String("since", "", "Only list queries fetched since this age (e.g. 7d, 12h) or date (YYYY-MM-DD)")
 	queryListSubCmd.Flags().Int("limit", 20, "Maximum results to show")
 
-	queryCmd.AddCommand(queryRunSubCmd, queryBuildSubCmd, queryListSubCmd)
+	queryStatsSubCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show query latency, cache and field usage statistics",
+		Long:  "Summarize queries run against a property with the active preset: average and P95 latency, cache hit rate, row counts and the most-used dimensions and metrics",
+		Run:   queryStatsCmd,
+	}
+	queryStatsSubCmd.Flags().String("property", "", "GA4 property ID")
+	queryStatsSubCmd.Flags().Int("days", 30, "Only include queries from the last N days")
+	queryStatsSubCmd.Flags().Int("top", 10, "Number of most-used dimensions and metrics to show")
+	queryStatsSubCmd.MarkFlagRequired("property")
+
+	queryRunMatrixSubCmd := &cobra.Command{
+		Use:   "run-matrix",
+		Short: "Run a batch of queries defined in a CSV file",
+		Long: `Run one query per row of a CSV file and export each result to its own CSV file.
+
+The header names the columns: property and template are required, start_date,
+end_date and output are optional. A template is a built-in report name (see
+'ga4admin report list') or a query file path relative to the matrix file.
+Output paths may use {property}, {template}, {start_date}, {end_date} and {row},
+plus the result tokens {query}, {date} and {time} (see 'results export').
+
+Properties the active preset can't access are run with another preset whose
+synced account list includes them (see 'preset sync'), so one matrix can span
+clients owned by different presets. --strict-preset fails on them instead.`,
+		Run: queryRunMatrixCmd,
+	}
+	queryRunMatrixSubCmd.Flags().String("file", "", "Matrix CSV file (required)")
+	queryRunMatrixSubCmd.Flags().String("output", "{template}_{property}_{start_date}_{end_date}.csv", "Output path pattern for rows without an output column")
+	queryRunMatrixSubCmd.Flags().Int("concurrency", 4, "Maximum number of queries run in parallel")
+	queryRunMatrixSubCmd.Flags().String("start-date", "30daysAgo", "Start date for rows without one (YYYY-MM-DD or relative)")
+	queryRunMatrixSubCmd.Flags().String("end-date", "yesterday", "End date for rows without one (YYYY-MM-DD or relative)")
+	queryRunMatrixSubCmd.Flags().Int64("limit", 0, "Maximum rows per query (default: template's own limit)")
+	queryRunMatrixSubCmd.Flags().String("notify", "", "Email the exports through this notifier when the run finishes")
+	queryRunMatrixSubCmd.Flags().Bool("strict-preset", false, "Fail on properties the active preset can't access instead of routing them to another preset")
+	queryRunMatrixSubCmd.MarkFlagRequired("file")
+
+	queryPlanSubCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show which parts of a query would come from cache or the API",
+		Long: `Show how 'query run' would answer a query without running it: from the exact
+cached result, assembled from results cached for other date ranges of the same
+query, or from the API. Assembling needs the 'date' dimension and absolute dates.
+
+` + filterGrammarHelp,
+		Run: queryPlanCmd,
+	}
+	addQueryConfigFlags(queryPlanSubCmd)
+
+	queryValidateSubCmd := &cobra.Command{
+		Use:   "validate [query-file...]",
+		Short: "Validate query files without running them",
+		Long: `Check query files the way 'query run' would use them, without running them:
+
+  schema         the published JSON Schema: known keys (catches misspelled
+                 fields a run ignores), value types and allowed values
+  query          required fields, dates, filters, ordering and field scopes
+  limits         at most 9 dimensions and 10 metrics per request
+  fields         every field exists on the property (metadata)
+  compatibility  GA4 accepts the dimensions and metrics together
+
+Files can be given with --file or as arguments, so the command works as a
+pre-commit hook. Exits non-zero when any file fails.`,
+		Run: queryValidateCmd,
+	}
+	queryValidateSubCmd.Flags().StringSlice("file", nil, "Query file(s) to validate")
+	queryValidateSubCmd.Flags().String("property", "", "Property ID to validate against (overrides property_id in the files)")
+
+	querySchemaSubCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of query files",
+		Long: `Print the JSON Schema of query files, generated from the query structs, for
+editor autocomplete and validation. With --template, print the schema of saved
+query templates instead.
+
+Editors using yaml-language-server pick the schema up from a comment on the
+first line of a query file:
+
+  # yaml-language-server: $schema=https://raw.githubusercontent.com/avisekrath/csga4/main/schemas/query.schema.json
+
+Examples:
+  ga4admin query schema
+  ga4admin query schema --output schemas/query.schema.json
+  ga4admin query schema --template --output schemas/query-template.schema.json`,
+		Run: querySchemaCmd,
+	}
+	querySchemaSubCmd.Flags().Bool("template", false, "Print the query template schema")
+	querySchemaSubCmd.Flags().String("output", "", "Write the schema to this file instead of stdout")
+
+	queryCmd.AddCommand(queryRunSubCmd, queryBuildSubCmd, queryListSubCmd, queryStatsSubCmd, queryRunMatrixSubCmd, queryPlanSubCmd, queryValidateSubCmd, querySchemaSubCmd)
 
 	// Results subcommands
 	resultsListSubCmd := &cobra.Command{
@@ -258,8 +821,10 @@ func init() {
 		Short: "List cached query results",
 		Run:   resultsListCmd,
 	}
-	resultsListSubCmd.Flags().String("property", "", "Filter by property ID")
+	resultsListSubCmd.Flags().String("property", "", "Filter by property ID (default: all properties)")
+	resultsListSubCmd.Flags().String("since", "", "Only list results fetched since this age (e.g. 7d, 12h) or date (YYYY-MM-DD)")
 	resultsListSubCmd.Flags().Int("limit", 20, "Maximum results to show")
+	resultsListSubCmd.Flags().String("tag", "", "Only list results with this tag")
 
 	resultsShowSubCmd := &cobra.Command{
 		Use:   "show [result-id]",
@@ -268,17 +833,96 @@ func init() {
 		Run:   resultsShowCmd,
 	}
 	resultsShowSubCmd.Flags().Int("max-rows", 50, "Maximum rows to display")
-	resultsShowSubCmd.Flags().Int("max-width", 30, "Maximum column width")
+	resultsShowSubCmd.Flags().Int("page", 0, "Show this page of the rows, starting at 1")
+	resultsShowSubCmd.Flags().Int("page-size", 0, "Rows per page with --page (default: --max-rows)")
+	resultsShowSubCmd.Flags().Int("max-width", 30, "Maximum column width (0 for no limit)")
 	resultsShowSubCmd.Flags().Bool("show-totals", true, "Show totals/summary rows")
+	resultsShowSubCmd.Flags().Bool("markdown", false, "Print the table as markdown")
+	resultsShowSubCmd.Flags().Bool("plain-numbers", false, "Print metric values without thousands separators")
+	resultsShowSubCmd.Flags().String("pivot", "", "Turn this dimension's values into columns (e.g. date)")
+	resultsShowSubCmd.Flags().Bool("melt", false, "Turn metric columns into metric/value rows")
+	resultsShowSubCmd.Flags().StringArray("derive", nil, "Add a computed column, e.g. 'share=sessions/total(sessions)*100' (repeatable)")
+	resultsShowSubCmd.Flags().String("channels", "", "Add a channel column from this channel mapping (see 'config channels')")
 
 	resultsExportSubCmd := &cobra.Command{
 		Use:   "export [result-id] [output-file]",
 		Short: "Export query results to file",
+		Long: `Export a cached query result to CSV, JSON, or a standalone HTML report with
+the query details, charts and the data table.
+
+The output path may contain tokens filled from the result:
+{property}, {query}, {date} and {time} (when it was fetched), {start_date}
+and {end_date}, e.g. 'exports/{property}/{date}.csv'.`,
 		Args:  cobra.ExactArgs(2),
 		Run:   resultsExportCmd,
 	}
-	resultsExportSubCmd.Flags().String("format", "csv", "Export format (csv, json)")
+	resultsExportSubCmd.Flags().String("format", "csv", "Export format (csv, json, html)")
 	resultsExportSubCmd.Flags().Bool("prettify", false, "Prettify JSON output")
+	resultsExportSubCmd.Flags().String("title", "", "Title of an HTML report (default names the property)")
+	resultsExportSubCmd.Flags().String("notify", "", "Email the export through this notifier (see 'config notifier')")
+	resultsExportSubCmd.Flags().String("pivot", "", "Turn this dimension's values into columns before exporting")
+	resultsExportSubCmd.Flags().Bool("melt", false, "Turn metric columns into metric/value rows before exporting")
+	resultsExportSubCmd.Flags().StringArray("derive", nil, "Add a computed column, e.g. 'share=sessions/total(sessions)*100' (repeatable)")
+	resultsExportSubCmd.Flags().String("channels", "", "Add a channel column from this channel mapping (see 'config channels')")
+	resultsExportSubCmd.Flags().Bool("include-query", false, "Also write the query, with its resolved dates, to a .query.yaml file next to the export")
+
+	resultsChartSubCmd := &cobra.Command{
+		Use:   "chart [result-id]",
+		Short: "Chart a query result",
+		Long: `Chart one dimension of a cached result against one or more metrics. Time
+dimensions such as date are drawn as sparklines, others as bar charts. Rows
+that share an x value are summed.
+
+Examples:
+  ga4admin results chart <result-id> --x date --y sessions
+  ga4admin results chart <result-id> --x country --y activeUsers --limit 10
+  ga4admin results chart <result-id> --x date --y sessions,activeUsers --output trend.png`,
+		Args: cobra.ExactArgs(1),
+		Run:  resultsChartCmd,
+	}
+	resultsChartSubCmd.Flags().String("x", "", "Dimension for the x axis (required)")
+	resultsChartSubCmd.Flags().StringSlice("y", nil, "Metrics to chart, comma-separated (default: the first metric)")
+	resultsChartSubCmd.Flags().String("type", chart.TypeAuto, "Chart type: auto, line or bar")
+	resultsChartSubCmd.Flags().Int("width", 60, "Terminal chart width in columns")
+	resultsChartSubCmd.Flags().Int("limit", 20, "Maximum categories in a bar chart (0 for all)")
+	resultsChartSubCmd.Flags().String("output", "", "Also write the chart to a .png or .svg file")
+	resultsChartSubCmd.Flags().String("channels", "", "Add a channel column from this channel mapping, e.g. to chart with --x channel")
+	resultsChartSubCmd.MarkFlagRequired("x")
+
+	resultsTransformSubCmd := &cobra.Command{
+		Use:   "transform <result-id>",
+		Short: "Transform a result's rows with a Starlark script",
+		Long: `Run a Starlark (Python-like) script over a cached result's rows, e.g. to map
+channels or classify brand and non-brand search terms, and show or export
+the rows it emits.
+
+The script defines transform(row), called per row, or transform_rows(rows),
+called once with all rows. A row is a dict of column name to value:
+dimensions are strings, metrics numbers. transform returns the row, a new
+dict, a list of dicts, or None to drop the row. New string columns become
+dimensions, new number columns metrics.
+
+Scripts are sandboxed: no files, network or clock. Besides Starlark's
+built-ins they may use math, re.match(pattern, s), re.find(pattern, s),
+re.sub(pattern, repl, s) and print (to stderr).
+
+Example transform.star:
+  def transform(row):
+      query = row["searchTerm"].lower()
+      row["brand"] = "brand" if re.match(r"\bacme\b", query) else "non-brand"
+      return row
+
+Examples:
+  ga4admin results transform <result-id> --script transform.star
+  ga4admin results transform <result-id> --script transform.star --output brand.csv`,
+		Args: cobra.ExactArgs(1),
+		Run:  resultsTransformCmd,
+	}
+	resultsTransformSubCmd.Flags().String("script", "", "Starlark script defining transform(row) or transform_rows(rows) (required)")
+	resultsTransformSubCmd.Flags().String("output", "", "Write the rows to this .csv or .json file instead of showing them")
+	resultsTransformSubCmd.Flags().Int("max-rows", 50, "Maximum rows to display")
+	resultsTransformSubCmd.Flags().Int("max-width", 30, "Maximum column width (0 for no limit)")
+	resultsTransformSubCmd.MarkFlagRequired("script")
 
 	resultsStatsSubCmd := &cobra.Command{
 		Use:   "stats",
@@ -287,7 +931,160 @@ func init() {
 	}
 	resultsStatsSubCmd.Flags().String("property", "", "Property ID to analyze")
 
-	resultsCmd.AddCommand(resultsListSubCmd, resultsShowSubCmd, resultsExportSubCmd, resultsStatsSubCmd)
+	resultsViewSubCmd := &cobra.Command{
+		Use:   "view",
+		Short: "Manage saved views of results",
+		Long: `A view is a saved filter and ordering over a cached result. Its name works
+wherever a result ID does, e.g. 'results show us-only' or 'results export
+us-only us.csv', and is applied to the cached rows each time it is read.`,
+	}
+
+	resultsViewCreateSubCmd := &cobra.Command{
+		Use:   "create <result-id>",
+		Short: "Save a filtered, ordered view of a result",
+		Long: `Save a filtered, ordered view of a cached result under a name.
+
+--where is a DuckDB expression over the result's columns. Dimensions are
+text and metrics are numbers; quote names containing ':' with double quotes.
+--order is a comma-separated list of 'column [asc|desc]'.
+
+Examples:
+  ga4admin results view create <result-id> --where "country='US'" --order "sessions desc" --name us-only
+  ga4admin results view create <result-id> --where "sessions >= 100 AND deviceCategory <> 'tablet'" --name busy-pages
+  ga4admin results view create <result-id> --order "date, sessions desc" --name by-day`,
+		Args: cobra.RangeArgs(1, 2),
+		Run:  resultsViewCreateCmd,
+	}
+	resultsViewCreateSubCmd.Flags().String("name", "", "Name of the view (required)")
+	resultsViewCreateSubCmd.Flags().String("where", "", "Keep rows matching this DuckDB expression, e.g. \"country='US'\"")
+	resultsViewCreateSubCmd.Flags().String("order", "", "Order rows by these columns, e.g. 'sessions desc, country'")
+	resultsViewCreateSubCmd.Flags().String("description", "", "Description of the view")
+	resultsViewCreateSubCmd.MarkFlagRequired("name")
+
+	resultsViewListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved views of a property's results",
+		Run:   resultsViewListCmd,
+	}
+	resultsViewListSubCmd.Flags().String("property", "", "Property ID (required)")
+	resultsViewListSubCmd.MarkFlagRequired("property")
+
+	resultsViewDeleteSubCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a saved view",
+		Args:  cobra.ExactArgs(1),
+		Run:   resultsViewDeleteCmd,
+	}
+
+	resultsViewSubCmd.AddCommand(resultsViewCreateSubCmd, resultsViewListSubCmd, resultsViewDeleteSubCmd)
+
+	resultsRollupSubCmd := &cobra.Command{
+		Use:   "rollup",
+		Short: "Manage materialized rollups of results",
+		Long: `A rollup aggregates a cached result into a table of the local cache, e.g.
+daily sessions by source/medium, so dashboards can read it without touching
+the raw rows. Its name works wherever a result ID does, and its table is
+rollup_<name> in the preset's cache database.
+
+Rollups keep track of where their data came from:
+  fresh     built from the result its source refers to now
+  outdated  the source was saved again; 'refresh' rebuilds from the cache
+  stale     the data is older than --refresh-every; 'refresh' re-fetches it
+  broken    the source no longer exists`,
+	}
+
+	resultsRollupCreateSubCmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Aggregate a result into a rollup table",
+		Long: `Aggregate a named table, view or result into a rollup table.
+
+--group-by lists the dimensions to keep; the others are aggregated away.
+--metrics lists 'metric[:aggregation]', where aggregation is sum (the
+default), avg, min or max. Only rollups of named tables can be re-fetched
+when they go stale, as views and result IDs refer to one fixed result.
+
+Examples:
+  ga4admin results rollup create daily_source --from traffic --group-by date,sessionSourceMedium --metrics sessions,totalUsers
+  ga4admin results rollup create daily_source --from traffic --group-by date,sessionSourceMedium --metrics sessions --refresh-every 24h
+  ga4admin results rollup create engagement --from pages --group-by pagePath --metrics engagementRate:avg,screenPageViews`,
+		Args: cobra.ExactArgs(1),
+		Run:  resultsRollupCreateCmd,
+	}
+	resultsRollupCreateSubCmd.Flags().String("from", "", "Named table, view or result ID to aggregate (required)")
+	resultsRollupCreateSubCmd.Flags().StringSlice("group-by", nil, "Dimensions to group by, e.g. date,sessionSourceMedium")
+	resultsRollupCreateSubCmd.Flags().StringSlice("metrics", nil, "Metrics to aggregate as metric[:sum|avg|min|max] (required)")
+	resultsRollupCreateSubCmd.Flags().Duration("refresh-every", 0, "Consider the rollup stale once its data is this old, e.g. 24h")
+	resultsRollupCreateSubCmd.Flags().String("description", "", "Description of the rollup")
+	resultsRollupCreateSubCmd.MarkFlagRequired("from")
+	resultsRollupCreateSubCmd.MarkFlagRequired("metrics")
+
+	resultsRollupListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List rollups and whether they are current",
+		Run:   resultsRollupListCmd,
+	}
+	resultsRollupListSubCmd.Flags().String("property", "", "Only list rollups of this property's results")
+
+	resultsRollupRefreshSubCmd := &cobra.Command{
+		Use:   "refresh [name...]",
+		Short: "Rebuild outdated and stale rollups",
+		Long: `Rebuild rollups that aren't fresh; by default all of them.
+
+Outdated rollups are rebuilt from the cached result their source refers to.
+Stale rollups of named tables first fetch the table's query again from GA4
+and save it under the same name.
+
+Examples:
+  ga4admin results rollup refresh
+  ga4admin results rollup refresh daily_source --force
+  ga4admin results rollup refresh --no-fetch`,
+		Run: resultsRollupRefreshCmd,
+	}
+	resultsRollupRefreshSubCmd.Flags().Bool("force", false, "Rebuild fresh rollups too, re-fetching named table sources")
+	resultsRollupRefreshSubCmd.Flags().Bool("no-fetch", false, "Only rebuild from the cache; never call the API")
+
+	resultsRollupDeleteSubCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a rollup and its table",
+		Args:  cobra.ExactArgs(1),
+		Run:   resultsRollupDeleteCmd,
+	}
+
+	resultsRollupSubCmd.AddCommand(resultsRollupCreateSubCmd, resultsRollupListSubCmd, resultsRollupRefreshSubCmd, resultsRollupDeleteSubCmd)
+
+	resultsTagSubCmd := &cobra.Command{
+		Use:   "tag <result-id> <tag>...",
+		Short: "Tag a result so it can be found again",
+		Long: `Attach tags to a cached result, by ID or named table, and find it later with
+'results list --tag'. Tags are lowercase words of letters, digits and
+. _ : -, e.g. campaign-launch or client:acme. Tagged results are kept when
+the cache is cleaned up or the query is fetched again.
+
+Examples:
+  ga4admin results tag <result-id> campaign-launch q3
+  ga4admin results tag <result-id> q3 --remove`,
+		Args: cobra.MinimumNArgs(2),
+		Run:  resultsTagCmd,
+	}
+	resultsTagSubCmd.Flags().Bool("remove", false, "Remove the tags instead")
+
+	resultsNoteSubCmd := &cobra.Command{
+		Use:   "note <result-id> [note]",
+		Short: "Annotate a result",
+		Long: `Attach a note to a cached result, by ID or named table, replacing any
+earlier one. Without a note, the current one is shown. Notes appear in
+'results list' and 'results show', and annotated results are kept when the
+cache is cleaned up or the query is fetched again.
+
+Examples:
+  ga4admin results note <result-id> "baseline before migration"
+  ga4admin results note <result-id> --clear`,
+		Args: cobra.RangeArgs(1, 2),
+		Run:  resultsNoteCmd,
+	}
+	resultsNoteSubCmd.Flags().Bool("clear", false, "Remove the note")
+
+	resultsCmd.AddCommand(resultsListSubCmd, resultsShowSubCmd, resultsExportSubCmd, resultsChartSubCmd, resultsTransformSubCmd, resultsStatsSubCmd, resultsViewSubCmd, resultsRollupSubCmd, resultsTagSubCmd, resultsNoteSubCmd)
 
 	// Cache subcommands
 	cacheStatsSubCmd := &cobra.Command{
@@ -304,1676 +1101,9934 @@ func init() {
 	cacheCleanupSubCmd.Flags().Bool("expired", true, "Clean only expired entries")
 	cacheCleanupSubCmd.Flags().Bool("all", false, "Clean all cache entries (use with caution)")
 
-	cacheCmd.AddCommand(cacheStatsSubCmd, cacheCleanupSubCmd)
+	cachePolicySubCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Show or change how expired cache entries are handled",
+		Run:   cachePolicyShowCmd,
+	}
+
+	cachePolicySetSubCmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the cache mode",
+		Long: `Set how expired cache entries are handled:
+  strict  Expired entries are discarded and fetched again before returning (default)
+  swr     Stale-while-revalidate: expired entries are returned immediately and
+          refreshed in the background before the command exits`,
+		Run: cachePolicySetCmd,
+	}
+	cachePolicySetSubCmd.Flags().String("mode", "", "Cache mode (strict, swr)")
+	cachePolicySetSubCmd.MarkFlagRequired("mode")
+
+	cachePolicySubCmd.AddCommand(cachePolicySetSubCmd)
+
+	cacheCmd.AddCommand(cacheStatsSubCmd, cacheCleanupSubCmd, cachePolicySubCmd)
 
 	// Export subcommands
 	exportParseSubCmd := &cobra.Command{
 		Use:   "parse-json",
 		Short: "Parse JSON files into DuckDB tables",
-		Long:  "Stream JSON export files into structured DuckDB tables for efficient querying",
-		Run:   exportParseCmd,
+		Long: `Stream JSON export files into structured DuckDB tables for efficient querying.
+
+--dry-run scans the input directory without writing anything and reports the
+file count, schema variants (e.g. exports from older collectors without
+data_streams), estimated rows per table and malformed files. --validate-only
+just lists malformed files and exits non-zero when there are any.
+
+Each parse is recorded as a collection run. Rows are stored per run, so
+parsing a new collection into the same database keeps the earlier ones for
+comparison; the analysis views show each property as of its latest run.
+See 'export runs'.`,
+		Run: exportParseCmd,
 	}
 	exportParseSubCmd.Flags().String("input-dir", "UniversalMusic/properties", "Directory containing JSON files")
 	exportParseSubCmd.Flags().String("output-db", "UniversalMusic/universal_music_parsed.db", "Output DuckDB database path")
 	exportParseSubCmd.Flags().Int("batch-size", 20, "Number of files to process per transaction")
-
-	exportCmd.AddCommand(exportParseSubCmd)
-
-	// Test command (hidden) for OAuth validation
-	testCmd := &cobra.Command{
-		Use:    "test-auth",
-		Short:  "Test OAuth2 authentication",
-		Hidden: true,
-		Run:    testAuthCmdHandler,
+	exportParseSubCmd.Flags().String("label", "", "Name for this collection run, e.g. \"2026-10 audit\"")
+	exportParseSubCmd.Flags().Bool("dry-run", false, "Report what would be parsed without writing the database")
+	exportParseSubCmd.Flags().Bool("validate-only", false, "Only check that every JSON file can be parsed")
+	exportParseSubCmd.MarkFlagsMutuallyExclusive("dry-run", "validate-only")
+
+	exportMappingSubCmd := &cobra.Command{
+		Use:   "mapping",
+		Short: "Generate a custom dimension to Clarisights field mapping",
+		Long:  "Map custom dimension API names across all properties in an account to Clarisights field names, ready for bulk import",
+		Run:   exportMappingCmd,
+	}
+	exportMappingSubCmd.Flags().String("account", "", "GA4 account ID")
+	exportMappingSubCmd.Flags().String("format", "csv", "Output format (csv, json)")
+	exportMappingSubCmd.Flags().String("output", "", "Output file (default: clarisights_mapping_<account>.<format>)")
+	exportMappingSubCmd.MarkFlagRequired("account")
+
+	exportLookerStudioSubCmd := &cobra.Command{
+		Use:   "lookerstudio",
+		Short: "Export a cached result for Looker Studio",
+		Long:  "Write a community connector schema, getData extract and CSV for a cached query result so it can be charted in Looker Studio",
+		Run:   exportLookerStudioCmd,
+	}
+	exportLookerStudioSubCmd.Flags().String("result", "", "Query result ID (see 'ga4admin results list')")
+	exportLookerStudioSubCmd.Flags().String("output-dir", "", "Output directory (default: lookerstudio_<result-id>)")
+	exportLookerStudioSubCmd.MarkFlagRequired("result")
+
+	exportDBTSubCmd := &cobra.Command{
+		Use:   "dbt",
+		Short: "Generate dbt sources and staging models",
+		Long:  "Introspect a DuckDB database created by 'export parse-json' and generate a dbt sources.yml plus a starter staging model per table",
+		Run:   exportDBTCmd,
+	}
+	exportDBTSubCmd.Flags().String("db", "", "DuckDB database path (required)")
+	exportDBTSubCmd.Flags().String("output-dir", "dbt", "dbt project directory to write models into")
+	exportDBTSubCmd.Flags().String("source-name", "ga4admin", "dbt source name")
+	exportDBTSubCmd.MarkFlagRequired("db")
+
+	exportRunsSubCmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List collection runs in a parsed database",
+		Long:  "List the 'export parse-json' runs stored in a DuckDB database with what each parsed, for comparing collections over time",
+		Run:   exportRunsCmd,
+	}
+	exportRunsSubCmd.Flags().String("db", "UniversalMusic/universal_music_parsed.db", "DuckDB database path")
+
+	exportCompareRunsSubCmd := &cobra.Command{
+		Use:   "compare-runs",
+		Short: "Compare two collection runs in a parsed database",
+		Long: `Show properties gained and lost, custom dimension count changes and
+Clarisights readiness changes between two 'export parse-json' runs. By default
+the latest run is compared with the one before it.
+
+The same comparisons are available in the database as the property_changes,
+dimension_count_deltas and clarisights_changes views, filtered by from_run_id
+and to_run_id.`,
+		Run: exportCompareRunsCmd,
+	}
+	exportCompareRunsSubCmd.Flags().String("db", "UniversalMusic/universal_music_parsed.db", "DuckDB database path")
+	exportCompareRunsSubCmd.Flags().Int64("from", 0, "Earlier run ID (default: the run before --to)")
+	exportCompareRunsSubCmd.Flags().Int64("to", 0, "Later run ID (default: the latest run)")
+	exportCompareRunsSubCmd.Flags().String("format", "text", "Output format (text, json)")
+
+	exportWorkbookSubCmd := &cobra.Command{
+		Use:   "workbook <output.xlsx>",
+		Short: "Export the data dictionaries of an account's properties to one workbook",
+		Long: `Write an Excel workbook documenting every property of an account: a Summary
+sheet listing each property with its dimension, metric and custom definition
+counts, then one sheet per property with all its dimensions and metrics, as in
+'metadata export'. Metadata is fetched in parallel and cached; properties that
+fail are marked in the summary.
+
+Example:
+  ga4admin export workbook --account 123456 account-fields.xlsx`,
+		Args: cobra.ExactArgs(1),
+		Run:  exportWorkbookCmd,
+	}
+	exportWorkbookSubCmd.Flags().String("account", "", "Account ID whose properties to document (required)")
+	exportWorkbookSubCmd.Flags().Int("concurrency", 4, "Maximum number of properties fetched in parallel")
+	exportWorkbookSubCmd.Flags().Bool("refresh", false, "Refetch metadata even if it is already cached")
+	exportWorkbookSubCmd.MarkFlagRequired("account")
+
+	exportCmd.AddCommand(exportParseSubCmd, exportRunsSubCmd, exportCompareRunsSubCmd, exportMappingSubCmd, exportLookerStudioSubCmd, exportDBTSubCmd, exportWorkbookSubCmd)
+
+	// Report subcommands
+	reportListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List built-in reports",
+		Run:   reportListCmd,
 	}
 
-	// Add all commands to root
-	rootCmd.AddCommand(configCmd, presetCmd, accountsCmd, propertiesCmd, metadataCmd, queryCmd, resultsCmd, cacheCmd, exportCmd, testCmd)
-}
+	reportRunSubCmd := &cobra.Command{
+		Use:   "run <report>",
+		Short: "Run a built-in report",
+		Args:  cobra.ExactArgs(1),
+		Run:   reportRunCmd,
+	}
+	reportRunSubCmd.Flags().String("property", "", "Property ID to report on (required)")
+	reportRunSubCmd.Flags().String("start-date", "30daysAgo", "Start date (YYYY-MM-DD or relative)")
+	reportRunSubCmd.Flags().String("end-date", "yesterday", "End date (YYYY-MM-DD or relative)")
+	reportRunSubCmd.Flags().Int64("limit", 0, "Maximum rows to return (default: report's own limit)")
+	reportRunSubCmd.MarkFlagRequired("property")
+
+	reportCmd.AddCommand(reportListSubCmd, reportRunSubCmd)
+
+	// Analyze subcommands
+	analyzeConversionsSubCmd := &cobra.Command{
+		Use:   "conversions",
+		Short: "Report key event coverage",
+		Long:  "Compare configured key events with actual event volumes to find dead key events and unmarked conversions",
+		Run:   analyzeConversionsCmd,
+	}
+	analyzeConversionsSubCmd.Flags().String("property", "", "Property ID to analyze (required)")
+	analyzeConversionsSubCmd.Flags().Int("days", 30, "Number of days of event volume to consider")
+	analyzeConversionsSubCmd.Flags().Float64("min-share", 1.0, "Report unmarked events with at least this percent of all events")
+	analyzeConversionsSubCmd.MarkFlagRequired("property")
+
+	analyzeDimsMatrixSubCmd := &cobra.Command{
+		Use:   "dims-matrix",
+		Short: "Compare custom dimensions across an account's properties",
+		Long: `Show which custom dimensions each property of an account defines, as a
+matrix of custom dimension API names by property, and flag inconsistencies:
+
+  - the same API name with different display names on different properties
+  - the same parameter registered with different scopes (customEvent:plan
+    on some properties, customUser:plan on others)
+  - parameter names that differ only in case or separators
+    (article_author, articleAuthor)
+
+By default the matrix is built from each property's Data API metadata, served
+from the preset's cache when warm (see 'metadata warm'). --db reads the
+latest collection run of each property from an 'export parse-json' database
+instead, without calling any API.
 
-func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-}
+Examples:
+  ga4admin analyze dims-matrix --account 123456
+  ga4admin analyze dims-matrix --account 123456 --issues-only
+  ga4admin analyze dims-matrix --account 123456 --db exports/parsed.db --format csv`,
+		Run: analyzeDimsMatrixCmd,
+	}
+	analyzeDimsMatrixSubCmd.Flags().String("account", "", "Account ID whose properties are compared (required)")
+	analyzeDimsMatrixSubCmd.Flags().String("db", "", "Read custom dimensions from this 'export parse-json' database instead of the API")
+	analyzeDimsMatrixSubCmd.Flags().Bool("issues-only", false, "Show only dimensions with inconsistencies")
+	analyzeDimsMatrixSubCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+	analyzeDimsMatrixSubCmd.Flags().String("output", "", "Output file for csv and json (default: dims_matrix_<account>.<format>)")
+	analyzeDimsMatrixSubCmd.MarkFlagRequired("account")
+
+	analyzeReadinessSubCmd := &cobra.Command{
+		Use:   "clarisights-readiness",
+		Short: "Score and rank an account's properties for Clarisights",
+		Long: `Score each property of an account on what a Clarisights integration needs,
+and rank them best first:
+
+  channel group  40 points  A custom channel group exists
+  dimensions     40 points  Share of --require-dims the property offers
+  currency       20 points  The property reports in --currency (default: the
+                            currency most of the account's properties use)
+
+Without --require-dims the dimensions check is skipped and scores are out of
+the remaining 60 points, shown as a percentage. Dimensions are checked against
+cached metadata when warm (see 'metadata warm').
 
-// Command implementations
-func configSetCmdHandler(cmd *cobra.Command, args []string) {
-	clientID, _ := cmd.Flags().GetString("client-id")
-	clientSecret, _ := cmd.Flags().GetString("client-secret")
+Examples:
+  ga4admin analyze clarisights-readiness --account 123456
+  ga4admin analyze clarisights-readiness --account 123456 --require-dims customEvent:artist,customEvent:album --currency EUR
+  ga4admin analyze clarisights-readiness --account 123456 --format csv --output readiness.csv`,
+		Run: analyzeReadinessCmd,
+	}
+	analyzeReadinessSubCmd.Flags().String("account", "", "Account ID whose properties are scored (required)")
+	analyzeReadinessSubCmd.Flags().StringSlice("require-dims", nil, "Dimension API names every property must offer, comma-separated")
+	analyzeReadinessSubCmd.Flags().String("currency", "", "Currency every property should use (default: the account's most common)")
+	analyzeReadinessSubCmd.Flags().Int("concurrency", 4, "Maximum number of properties checked in parallel")
+	analyzeReadinessSubCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+	analyzeReadinessSubCmd.Flags().String("output", "", "Output file for csv and json (default: clarisights_readiness_<account>.<format>)")
+	analyzeReadinessSubCmd.MarkFlagRequired("account")
+
+	analyzeUTMSubCmd := &cobra.Command{
+		Use:   "utm",
+		Short: "Find inconsistent UTM tagging",
+		Long: `Read the session source, medium and campaign combinations of a property and
+flag values that differ only in letter case, whitespace or separators
+('Facebook' and 'facebook', 'spring sale ' and 'spring_sale'), along with
+the share of traffic without a campaign.
+
+--format csv writes a cleanup worksheet with one row per value to retag and
+its suggested replacement; --format json writes the full report.
 
-	fmt.Println("🔧 Setting global OAuth configuration...")
+Examples:
+  ga4admin analyze utm --property 123456789 --days 90
+  ga4admin analyze utm --property 123456789 --format csv --output utm_cleanup.csv`,
+		Run: analyzeUTMCmd,
+	}
+	analyzeUTMSubCmd.Flags().String("property", "", "Property ID to analyze (required)")
+	analyzeUTMSubCmd.Flags().Int("days", 90, "Number of days of traffic to check")
+	analyzeUTMSubCmd.Flags().Int("limit", 25, "Maximum issues to show in the table")
+	analyzeUTMSubCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+	analyzeUTMSubCmd.Flags().String("output", "", "Output file for csv and json (default: utm_cleanup_<property>.<format>)")
+	analyzeUTMSubCmd.MarkFlagRequired("property")
+
+	analyzeReferralSpamSubCmd := &cobra.Command{
+		Use:   "referral-spam",
+		Short: "Find spam referrers and foreign hostnames",
+		Long: `Check a property's referrers against a built-in list of known spam domains
+and for volume anomalies, and its hostnames against the sites of its web
+streams, then recommend filters that keep the spam out of reports.
+
+A referrer is flagged when it is on the blocklist, or when it has (almost)
+no engaged sessions and either only new users or most of its sessions on a
+single day.
 
-	// Validate inputs
-	if strings.TrimSpace(clientID) == "" {
-		fmt.Fprintf(os.Stderr, "Error: client-id cannot be empty\n")
-		os.Exit(1)
+Examples:
+  ga4admin analyze referral-spam --property 123456789
+  ga4admin analyze referral-spam --property 123456789 --hostname shop.example.com --blocklist our-spam.txt
+  ga4admin analyze referral-spam --property 123456789 --format csv`,
+		Run: analyzeReferralSpamCmd,
+	}
+	analyzeReferralSpamSubCmd.Flags().String("property", "", "Property ID to analyze (required)")
+	analyzeReferralSpamSubCmd.Flags().Int("days", 30, "Number of days of traffic to check")
+	analyzeReferralSpamSubCmd.Flags().Int64("min-sessions", 10, "Ignore referrers with fewer sessions for the volume checks")
+	analyzeReferralSpamSubCmd.Flags().Float64("max-engagement", 5, "Engagement rate in percent at or below which a referrer counts as unengaged")
+	analyzeReferralSpamSubCmd.Flags().StringSlice("hostname", nil, "Hostnames of your sites besides the web stream URLs (comma-separated)")
+	analyzeReferralSpamSubCmd.Flags().StringSlice("blocklist", nil, "Files with more spam domains, one per line")
+	analyzeReferralSpamSubCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+	analyzeReferralSpamSubCmd.Flags().String("output", "", "Output file for csv and json (default: referral_spam_<property>.<format>)")
+	analyzeReferralSpamSubCmd.MarkFlagRequired("property")
+
+	analyzeCmd.AddCommand(analyzeConversionsSubCmd, analyzeDimsMatrixSubCmd, analyzeReadinessSubCmd, analyzeUTMSubCmd, analyzeReferralSpamSubCmd)
+
+	// Streams subcommands
+	streamsListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a property's data streams",
+		Run:   streamsListCmd,
 	}
-	if strings.TrimSpace(clientSecret) == "" {
-		fmt.Fprintf(os.Stderr, "Error: client-secret cannot be empty\n")
-		os.Exit(1)
+	streamsListSubCmd.Flags().String("property", "", "Property ID to list streams for (required)")
+	streamsListSubCmd.MarkFlagRequired("property")
+
+	streamsSettingsSubCmd := &cobra.Command{
+		Use:   "settings [stream-id]",
+		Short: "Show a stream's enhanced measurement and redaction settings",
+		Long:  "Show a data stream's enhanced measurement toggles and data redaction settings. Use --format json to script tagging audits.",
+		Args:  cobra.ExactArgs(1),
+		Run:   streamsSettingsCmd,
 	}
+	streamsSettingsSubCmd.Flags().String("property", "", "Property ID the stream belongs to (required)")
+	streamsSettingsSubCmd.Flags().String("format", "text", "Output format (text, json)")
+	streamsSettingsSubCmd.MarkFlagRequired("property")
+
+	streamsCmd.AddCommand(streamsListSubCmd, streamsSettingsSubCmd)
 
-	// Save credentials
-	if err := config.SetClientCredentials(clientID, clientSecret); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
-		os.Exit(1)
+	// Links subcommands
+	linksListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a property's product links",
+		Run:   linksListCmd,
+	}
+	linksListSubCmd.Flags().String("property", "", "Property ID to list links for (required)")
+	linksListSubCmd.Flags().StringSlice("type", nil, "Link types to list: bigquery, google-ads, firebase (default all)")
+	linksListSubCmd.MarkFlagRequired("property")
+
+	linksAuditSubCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Find properties missing expected links",
+		Long:  "Check every property in an account for the expected product links and report the ones missing any",
+		Run:   linksAuditCmd,
+	}
+	linksAuditSubCmd.Flags().String("account", "", "Account ID to audit (required)")
+	linksAuditSubCmd.Flags().StringSlice("expect", []string{"bigquery", "google-ads"}, "Link types every property should have: bigquery, google-ads, firebase")
+	linksAuditSubCmd.Flags().Int("concurrency", 4, "Properties checked in parallel")
+	linksAuditSubCmd.MarkFlagRequired("account")
+
+	linksCmd.AddCommand(linksListSubCmd, linksAuditSubCmd)
+
+	// Channel group subcommands
+	channelGroupsShowSubCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show channel group rules",
+		Long:  "List a property's channel groups with their rules rendered as readable conditions",
+		Run:   channelGroupsShowCmd,
+	}
+	channelGroupsShowSubCmd.Flags().String("property", "", "Property ID (required)")
+	channelGroupsShowSubCmd.Flags().String("group", "", "Only show this channel group ID")
+	channelGroupsShowSubCmd.Flags().Bool("system", false, "Include system-defined channel groups")
+	channelGroupsShowSubCmd.MarkFlagRequired("property")
+
+	channelGroupsLintSubCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate channel group rules",
+		Long:  "Check custom channel group rules for invalid filters, and replay them against recent traffic to find rules that match nothing or are shadowed by earlier rules",
+		Run:   channelGroupsLintCmd,
+	}
+	channelGroupsLintSubCmd.Flags().String("property", "", "Property ID (required)")
+	channelGroupsLintSubCmd.Flags().String("group", "", "Only lint this channel group ID")
+	channelGroupsLintSubCmd.Flags().Int("days", 30, "Number of days of traffic to replay rules against")
+	channelGroupsLintSubCmd.MarkFlagRequired("property")
+
+	channelGroupsCmd.AddCommand(channelGroupsShowSubCmd, channelGroupsLintSubCmd)
+
+	// Custom dimension subcommands
+	customDimsApplySubCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create and archive custom dimensions to match a spec",
+		Long: `Compare a property's custom dimensions with a CSV, YAML or JSON spec, print
+the plan (create/skip/archive) and apply it after confirmation.
+
+CSV files need a header with parameter_name and display_name, and may add
+scope (EVENT, USER or ITEM; default EVENT), description and
+disallow_ads_personalization. YAML and JSON files hold the same fields in a
+custom_dimensions list. Dimensions match on scope and parameter name.
+
+Creating and archiving needs a refresh token granted the analytics.edit scope.`,
+		Run: customDimsApplyCmd,
+	}
+	customDimsApplySubCmd.Flags().String("property", "", "Property ID (required)")
+	customDimsApplySubCmd.Flags().String("file", "", "Spec file (.csv, .yaml, .yml or .json) (required)")
+	customDimsApplySubCmd.Flags().Bool("keep-unlisted", false, "Keep custom dimensions that are not in the spec instead of archiving them")
+	customDimsApplySubCmd.Flags().Bool("dry-run", false, "Print the plan without changing anything")
+	customDimsApplySubCmd.Flags().Bool("yes", false, "Apply without asking for confirmation")
+	customDimsApplySubCmd.MarkFlagRequired("property")
+	customDimsApplySubCmd.MarkFlagRequired("file")
+
+	customDimsCmd.AddCommand(customDimsApplySubCmd)
+
+	// Declarative apply flags
+	applyCmd.Flags().StringP("file", "f", "", "Property file (.yaml, .yml or .json) (required)")
+	applyCmd.Flags().String("property", "", "Property ID or alias (overrides the file's property)")
+	applyCmd.Flags().Bool("keep-unlisted", false, "Keep resources missing from a managed section instead of removing them")
+	applyCmd.Flags().Bool("dry-run", false, "Print the plan without changing anything")
+	applyCmd.Flags().Bool("yes", false, "Apply without asking for confirmation")
+	applyCmd.MarkFlagRequired("file")
+
+	// Audit subcommands
+	auditListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded configuration changes",
+		Long:  "List Admin API writes recorded by write commands (customdims apply, apply), newest first, with who made them and the resource state before and after",
+		Run:   auditListCmd,
 	}
+	auditListSubCmd.Flags().String("property", "", "Only list changes to this property")
+	auditListSubCmd.Flags().Int("days", 30, "Only list changes from the last N days")
+	auditListSubCmd.Flags().Int("limit", 50, "Maximum number of records to list (0 for all)")
+	auditListSubCmd.Flags().Bool("details", false, "Show the request body and the before and after state")
 
-	// Get config path for display
-	configPath, _ := config.GetConfigPath()
-	fmt.Printf("✅ OAuth credentials saved successfully\n")
-	fmt.Printf("📁 Config file: %s\n", configPath)
-	fmt.Println("🚀 You can now create presets with refresh tokens")
-}
+	auditCmd.AddCommand(auditListSubCmd)
 
-func configShowCmdHandler(cmd *cobra.Command, args []string) {
-	fmt.Println("📋 Current GA4 Admin Configuration:")
-	fmt.Println()
+	// Self-update flags
+	versionCmd.Flags().Bool("check", false, "Look up the latest release and print upgrade instructions")
+	versionCmd.Flags().String("channel", "", "Release channel to compare against: stable or beta (default: the installed build's)")
 
-	// Load configuration
-	appConfig, err := config.LoadConfig()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
-		os.Exit(1)
+	pluginListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List plugins found on PATH",
+		Args:  cobra.NoArgs,
+		Run:   pluginListCmdHandler,
 	}
+	pluginCmd.AddCommand(pluginListCmd)
 
-	// Display config path
-	configPath, _ := config.GetConfigPath()
-	fmt.Printf("📁 Config Location: %s\n", configPath)
-	fmt.Println()
+	doctorCmd.Flags().Bool("skip-network", false, "Skip the endpoint reachability checks")
 
-	// Display OAuth credentials status
-	if appConfig.ClientID != "" {
-		fmt.Printf("🔑 OAuth Client ID: %s...%s (configured)\n", 
-			appConfig.ClientID[:min(12, len(appConfig.ClientID))], 
-			appConfig.ClientID[max(0, len(appConfig.ClientID)-4):])
-		fmt.Printf("🔐 OAuth Client Secret: [HIDDEN] (configured)\n")
-	} else {
-		fmt.Println("❌ OAuth Client ID: Not configured")
-		fmt.Println("❌ OAuth Client Secret: Not configured")
-		fmt.Println()
-		fmt.Println("💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' to configure")
+	selfUpdateCmd.Flags().String("channel", selfupdate.ChannelStable, "Release channel: stable or beta")
+	selfUpdateCmd.Flags().Bool("check", false, "Only report whether an update is available")
+	selfUpdateCmd.Flags().Bool("yes", false, "Install without asking for confirmation")
+	selfUpdateCmd.Flags().Bool("checksum-only", false, "Install without a signature check (development builds have no release key)")
+	selfUpdateCmd.Flags().Bool("force", false, "Replace the binary even if a package manager installed it")
+
+	// Workspace subcommands
+	workspaceAddSubCmd := &cobra.Command{
+		Use:   "add <workspace> <preset> <property>",
+		Short: "Add a property to a workspace",
+		Long:  "Add a property, reached through the given preset, to a workspace (created if needed). The property may be an ID or one of that preset's aliases.",
+		Args:  cobra.ExactArgs(3),
+		Run:   workspaceAddCmd,
 	}
+	workspaceAddSubCmd.Flags().String("label", "", "Name shown for this property in merged output, e.g. the client's name")
 
-	// Display active preset
-	if appConfig.ActivePreset != "" {
-		fmt.Printf("🎯 Active Preset: %s\n", appConfig.ActivePreset)
-	} else {
-		fmt.Println("📝 Active Preset: None")
+	workspaceRemoveSubCmd := &cobra.Command{
+		Use:   "remove <workspace> <preset> <property>",
+		Short: "Remove a property from a workspace",
+		Args:  cobra.ExactArgs(3),
+		Run:   workspaceRemoveCmd,
 	}
 
-	// Display timestamps
-	fmt.Println()
-	fmt.Printf("📅 Created: %s\n", appConfig.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("🔄 Updated: %s\n", appConfig.UpdatedAt.Format("2006-01-02 15:04:05"))
-}
+	workspaceListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List workspaces and their properties",
+		Run:   workspaceListCmd,
+	}
 
-// Helper functions
-func min(a, b int) int {
-	if a < b {
-		return a
+	workspaceDeleteSubCmd := &cobra.Command{
+		Use:   "delete <workspace>",
+		Short: "Delete a workspace",
+		Args:  cobra.ExactArgs(1),
+		Run:   workspaceDeleteCmd,
+	}
+
+	workspaceRunSubCmd := &cobra.Command{
+		Use:   "run <workspace> <template>",
+		Short: "Run a report across every property in a workspace",
+		Long: `Run a built-in report (see 'ga4admin report list') or a query file against
+every property in a workspace, each with its own preset's credentials and
+cache, and merge the rows into one CSV. Merged rows start with member, preset
+and property_id columns. Properties that fail are reported and left out of the
+merged output.`,
+		Args: cobra.ExactArgs(2),
+		Run:  workspaceRunCmd,
+	}
+	workspaceRunSubCmd.Flags().String("output", "", "Merged CSV path (default: <workspace>_<template>.csv)")
+	workspaceRunSubCmd.Flags().String("start-date", "", "Start date (YYYY-MM-DD or relative; default: template's own, else 30daysAgo)")
+	workspaceRunSubCmd.Flags().String("end-date", "", "End date (YYYY-MM-DD or relative; default: template's own, else yesterday)")
+	workspaceRunSubCmd.Flags().Int64("limit", 0, "Maximum rows per property (default: template's own limit)")
+	workspaceRunSubCmd.Flags().Int("concurrency", 4, "Maximum number of queries run in parallel per preset")
+
+	workspaceCmd.AddCommand(workspaceAddSubCmd, workspaceRemoveSubCmd, workspaceListSubCmd, workspaceDeleteSubCmd, workspaceRunSubCmd)
+
+	// Quota subcommands
+	quotaForecastSubCmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Forecast token use of planned scheduled queries",
+		Long: `Estimate a property's daily and hourly Data API token use from the tokens
+logged for past queries plus the queries in a schedule plan, and check the
+estimate against GA4's quotas. The plan is a YAML file:
+
+  queries:
+    - template: acquisition          # built-in report or query file
+      every: 24h
+    - name: live pages
+      template: queries/pages.yaml   # relative to the plan file
+      every: 15m
+      no_cache: true
+
+Planned queries are priced from logged calls with the same fields. Runs less
+than an hour apart are answered from the cache unless no_cache is set.`,
+		Run: quotaForecastCmd,
+	}
+	quotaForecastSubCmd.Flags().String("property", "", "GA4 property ID (required)")
+	quotaForecastSubCmd.Flags().String("plan", "", "Schedule plan YAML file")
+	quotaForecastSubCmd.Flags().Int("days", 14, "Days of query history to base the forecast on")
+	quotaForecastSubCmd.Flags().Bool("analytics360", false, "Check against Analytics 360 quotas")
+	quotaForecastSubCmd.MarkFlagRequired("property")
+
+	quotaCmd.AddCommand(quotaForecastSubCmd)
+
+	// Realtime watch command
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch realtime active users",
+		Long:  "Poll the GA4 realtime API and show a continuously updating view of active users by page and country (Ctrl+C to stop)",
+		Run:   watchCmdHandler,
+	}
+	watchCmd.Flags().String("property", "", "Property ID to watch (required)")
+	watchCmd.Flags().Duration("interval", 30*time.Second, "Polling interval (minimum 10s)")
+	watchCmd.Flags().Int64("limit", 10, "Number of pages and countries to show")
+	watchCmd.MarkFlagRequired("property")
+
+	// Read-only SQL over the cache
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve read-only SQL over named result tables",
+		Long: `Serve the active preset's named result tables and rollups over HTTP, so
+internal tools can query them with SQL without a copy of the cache file.
+
+POST a statement to /sql, as text or as {"sql": "..."}, or GET /sql?q=...
+Only single SELECT statements are run, against an in-memory copy of the
+tables that can't read files or reach the cache. Tables are reloaded when
+they change. Answers are JSON, or CSV with ?format=csv.
+
+Every request needs "Authorization: Bearer <token>". The token is read from
+GA4ADMIN_SERVE_TOKEN, or generated and printed at startup.
+
+Examples:
+  GA4ADMIN_SERVE_TOKEN=secret ga4admin serve --listen 0.0.0.0:8765
+  curl -H "Authorization: Bearer secret" --data "SELECT * FROM traffic LIMIT 10" localhost:8765/sql`,
+		Run: serveCmdHandler,
+	}
+	serveCmd.Flags().String("listen", "127.0.0.1:8765", "Address to listen on")
+	serveCmd.Flags().String("property", "", "Only serve this property's tables")
+	serveCmd.Flags().Int("max-rows", serve.DefaultMaxRows, "Rows returned per statement before truncating")
+	serveCmd.Flags().Duration("query-timeout", serve.DefaultQueryTimeout, "Longest a statement may run")
+
+	// MCP server for LLM assistants
+	mcpCmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve GA4 tools to LLM assistants over MCP",
+		Long: `Run a Model Context Protocol server on stdin/stdout, so assistants can list
+properties, look up fields and run reports through the active preset's
+credentials, cache and quota tracking instead of their own API keys.
+
+Tools: list_accounts, list_properties, get_metadata, run_query.
+
+Register it with an assistant as a stdio server running 'ga4admin mcp', e.g.
+  {"mcpServers": {"ga4": {"command": "ga4admin", "args": ["mcp"]}}}`,
+		Run: mcpCmdHandler,
+	}
+	mcpCmd.Flags().Int64("max-rows", 1000, "Rows run_query may return per call")
+
+	// Questions in plain language
+	askCmd := &cobra.Command{
+		Use:   "ask <question>",
+		Short: "Turn a question into a GA4 query and run it",
+		Long: `Map a plain-language question to dimensions, metrics and a date range using
+the bundled field catalog and the property's custom definitions, show the
+resulting query and run it once confirmed.
+
+Name the property in the question ("... for property 123456789" or an alias)
+or with --property. Dates like "last month", "this week", "last 7 days",
+"yesterday" or "between 2025-01-01 and 2025-01-31" are understood; without
+one, the last 30 days are used.
+
+With an assistant configured ('config set --assistant-endpoint --assistant-model'),
+the question is sent to that OpenAI-compatible endpoint instead, with the API
+key from ` + ask.APIKeyEnv + `. Its answer is checked against the catalog, and
+the local matcher is used if it fails.
+
+Examples:
+  ga4admin ask "top landing pages by sessions last month for property 123456789"
+  ga4admin ask "daily users and conversions by channel last 14 days" --property shop
+  ga4admin ask "bounce rate by device this year" --property shop --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  askCmdHandler,
+	}
+	askCmd.Flags().String("property", "", "Property ID or alias, overriding one named in the question")
+	askCmd.Flags().BoolP("yes", "y", false, "Run the query without asking for confirmation")
+	askCmd.Flags().Bool("dry-run", false, "Show the query without running it")
+	askCmd.Flags().Bool("no-assistant", false, "Use the local field matcher even if an assistant is configured")
+
+	// Backfill subcommands
+	backfillPlanSubCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Plan (or run) the queries that complete a series of named tables",
+		Long: `Split a date range into chunks and check which are already saved as named
+tables of the template's query - results of the same dimensions, metrics and
+filters, named with 'query run --name' or an earlier backfill. Missing chunks,
+truncated tables and tables fetched before GA4 finished processing their last
+day are listed; --execute runs them and saves each as <prefix>_<start>_<end>.`,
+		Run: backfillPlanCmd,
+	}
+	backfillPlanSubCmd.Flags().String("property", "", "Property ID to backfill (required)")
+	backfillPlanSubCmd.Flags().String("query", "", "Query file or built-in report name (required)")
+	backfillPlanSubCmd.Flags().String("from", "", "First day to cover, YYYY-MM-DD (required)")
+	backfillPlanSubCmd.Flags().String("to", "", "Last day to cover, YYYY-MM-DD (default: yesterday)")
+	backfillPlanSubCmd.Flags().String("chunk", backfill.ChunkMonth, "Chunk size: day, week or month")
+	backfillPlanSubCmd.Flags().String("prefix", "", "Named table prefix (default: the query's file or report name)")
+	backfillPlanSubCmd.Flags().Bool("execute", false, "Run the pending chunks and save them as named tables")
+	backfillPlanSubCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+	backfillPlanSubCmd.Flags().String("output", "", "Output file for csv/json (default: backfill_<prefix>_<property>.<format>)")
+	backfillPlanSubCmd.MarkFlagRequired("property")
+	backfillPlanSubCmd.MarkFlagRequired("query")
+	backfillPlanSubCmd.MarkFlagRequired("from")
+
+	backfillCmd.AddCommand(backfillPlanSubCmd)
+
+	// Pipeline subcommands
+	pipelineAddSubCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add or replace a pipeline",
+		Long: `Add a pipeline that appends a query's rows to a table in a DuckDB database.
+The query - a query file or built-in report - must have the date dimension.
+The pipeline runs with the active preset's credentials.`,
+		Args: cobra.ExactArgs(1),
+		Run:  pipelineAddCmd,
+	}
+	pipelineAddSubCmd.Flags().String("property", "", "Property ID to load (required)")
+	pipelineAddSubCmd.Flags().String("query", "", "Query file or built-in report name (required)")
+	pipelineAddSubCmd.Flags().String("database", "", "DuckDB database file, created if needed (required)")
+	pipelineAddSubCmd.Flags().String("table", "", "Table to append to (default: the pipeline name)")
+	pipelineAddSubCmd.Flags().String("start", "", "First day to load, YYYY-MM-DD (required)")
+	pipelineAddSubCmd.Flags().String("chunk", backfill.ChunkMonth, "Request size when catching up: day, week or month")
+	pipelineAddSubCmd.MarkFlagRequired("property")
+	pipelineAddSubCmd.MarkFlagRequired("query")
+	pipelineAddSubCmd.MarkFlagRequired("database")
+	pipelineAddSubCmd.MarkFlagRequired("start")
+
+	pipelineListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List pipelines",
+		Run:   pipelineListCmd,
 	}
-	return b
-}
 
-func max(a, b int) int {
-	if a > b {
-		return a
+	pipelineRemoveSubCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a pipeline (its table is kept)",
+		Args:  cobra.ExactArgs(1),
+		Run:   pipelineRemoveCmd,
 	}
-	return b
-}
+
+	pipelineRunSubCmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Append the days a pipeline's table is missing",
+		Long: `Query the days from the pipeline's start date to yesterday that its table
+doesn't hold yet and append them. Days already in the table, or loaded
+without any rows, are skipped, so the command can run on a schedule.`,
+		Args: cobra.ExactArgs(1),
+		Run:  pipelineRunCmd,
+	}
+	pipelineRunSubCmd.Flags().String("to", "", "Last day to load, YYYY-MM-DD (default: yesterday)")
+	pipelineRunSubCmd.Flags().Bool("dry-run", false, "Show the days that would be loaded without querying")
+
+	pipelineCmd.AddCommand(pipelineAddSubCmd, pipelineListSubCmd, pipelineRemoveSubCmd, pipelineRunSubCmd)
+
+	// Test command (hidden) for OAuth validation
+	testCmd := &cobra.Command{
+		Use:    "test-auth",
+		Short:  "Test OAuth2 authentication",
+		Hidden: true,
+		Run:    testAuthCmdHandler,
+	}
+
+	// Add all commands to root
+	// Fields subcommands
+	fieldsDescribeSubCmd := &cobra.Command{
+		Use:   "describe <field>",
+		Short: "Describe a standard dimension or metric",
+		Args:  cobra.ExactArgs(1),
+		Run:   fieldsDescribeCmd,
+	}
+
+	fieldsListSubCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List standard dimensions and metrics",
+		Run:   fieldsListCmd,
+	}
+	fieldsListSubCmd.Flags().String("kind", "", "Only list dimensions or metrics (dimension, metric)")
+	fieldsListSubCmd.Flags().String("category", "", "Filter by category, e.g. 'Traffic source'")
+	fieldsListSubCmd.Flags().String("search", "", "Only list fields whose name or category contains this text")
+
+	fieldsGenerateSubCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Regenerate the field catalog from the Data API",
+		Long:  "Fetch standard field metadata with the active preset and write it in the bundled catalog format (for maintainers refreshing internal/catalog/catalog.json)",
+		Run:   fieldsGenerateCmd,
+	}
+	fieldsGenerateSubCmd.Flags().String("property", "0", "Property to read metadata from (0 returns only standard fields)")
+	fieldsGenerateSubCmd.Flags().String("output", "internal/catalog/catalog.json", "Output file")
+
+	fieldsCmd.AddCommand(fieldsDescribeSubCmd, fieldsListSubCmd, fieldsGenerateSubCmd)
+
+	// Alias subcommands
+	aliasCmd.AddCommand(&cobra.Command{
+		Use:   "set [name] [property-id]",
+		Short: "Create or update a property alias",
+		Long:  "Point an alias at a property ID in the active preset, e.g. 'ga4admin alias set prod-web 328687832'",
+		Args:  cobra.ExactArgs(2),
+		Run:   aliasSetCmd,
+	})
+	aliasCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List property aliases for the active preset",
+		Run:   aliasListCmd,
+	})
+	aliasCmd.AddCommand(&cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a property alias",
+		Args:  cobra.ExactArgs(1),
+		Run:   aliasRemoveCmd,
+	})
+
+	rootCmd.AddCommand(configCmd, presetCmd, accountsCmd, propertiesCmd, metadataCmd, queryCmd, resultsCmd, cacheCmd, exportCmd, reportCmd, analyzeCmd, channelGroupsCmd, customDimsCmd, applyCmd, auditCmd, streamsCmd, linksCmd, workspaceCmd, backfillCmd, pipelineCmd, quotaCmd, selfUpdateCmd, versionCmd, doctorCmd, pluginCmd, watchCmd, serveCmd, mcpCmd, askCmd, fieldsCmd, aliasCmd, testCmd)
+}
+
+func main() {
+	runPlugin(os.Args[1:])
+
+	if err := rootCmd.Execute(); err != nil {
+		// Run functions exit themselves, so errors here are usage errors:
+		// unknown commands or flags, missing arguments
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+}
+
+// runPlugin runs a ga4admin-<name> executable from PATH when the command
+// line names no built-in command, and exits with the plugin's exit code.
+// Built-in commands always win. --home, --preset and --property may come
+// before the plugin's name and are passed on in the environment.
+func runPlugin(args []string) {
+	globals := make(map[string]string)
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[0], "--"), "=")
+		if name != "home" && name != "preset" && name != "property" {
+			return
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return
+			}
+			value, args = args[1], args[1:]
+		}
+		globals[name] = value
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return
+	}
+	switch args[0] {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		// Added by cobra when it executes, so Find doesn't know them yet
+		return
+	}
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return
+	}
+
+	path, rest, ok := plugin.Find(args)
+	if !ok {
+		return
+	}
+
+	if err := config.SetHomeDir(globals["home"]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	presetName := globals["preset"]
+	if presetName == "" {
+		presetName, _ = config.GetActivePreset()
+	}
+	propertyID := globals["property"]
+	if propertyID != "" && !preset.IsPropertyID(propertyID) {
+		var p *config.Preset
+		var err error
+		if presetName != "" {
+			p, err = preset.LoadPreset(presetName)
+		}
+		if err == nil {
+			propertyID, err = preset.ResolveProperty(p, propertyID)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --property: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+	binary, _ := os.Executable()
+
+	code, err := plugin.Run(path, rest, plugin.Context{
+		Preset:     presetName,
+		PropertyID: propertyID,
+		Binary:     binary,
+		Version:    version,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failure)
+	}
+	os.Exit(code)
+}
+
+// Command implementations
+func configSetCmdHandler(cmd *cobra.Command, args []string) {
+	clientID, _ := cmd.Flags().GetString("client-id")
+	clientSecret, _ := cmd.Flags().GetString("client-secret")
+	transport, _ := cmd.Flags().GetString("data-api-transport")
+	adminVersion, _ := cmd.Flags().GetString("admin-api-version")
+
+	credentialsSet := cmd.Flags().Changed("client-id") || cmd.Flags().Changed("client-secret")
+	networkSet := false
+	for _, name := range []string{"request-timeout", "command-timeout", "proxy", "ca-bundle", "max-conns-per-host"} {
+		networkSet = networkSet || cmd.Flags().Changed(name)
+	}
+	endpointsSet := cmd.Flags().Changed("admin-api-endpoint") || cmd.Flags().Changed("data-api-endpoint")
+	assistantSet := cmd.Flags().Changed("assistant-endpoint") || cmd.Flags().Changed("assistant-model")
+	if !credentialsSet && transport == "" && adminVersion == "" && !networkSet && !endpointsSet && !assistantSet {
+		fmt.Fprintf(os.Stderr, "Error: nothing to set - provide --client-id/--client-secret, --data-api-transport, --admin-api-version, API endpoints, assistant or network options\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Println("🔧 Setting global configuration...")
+
+	if credentialsSet {
+		// Validate inputs
+		if strings.TrimSpace(clientID) == "" {
+			fmt.Fprintf(os.Stderr, "Error: client-id cannot be empty\n")
+			os.Exit(exitcode.Validation)
+		}
+		if strings.TrimSpace(clientSecret) == "" {
+			fmt.Fprintf(os.Stderr, "Error: client-secret cannot be empty\n")
+			os.Exit(exitcode.Validation)
+		}
+
+		// Save credentials
+		if err := config.SetClientCredentials(clientID, clientSecret); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ OAuth credentials saved successfully\n")
+	}
+
+	if transport != "" {
+		if err := config.SetDataAPITransport(strings.ToLower(strings.TrimSpace(transport))); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Data API transport set to %s\n", strings.ToLower(strings.TrimSpace(transport)))
+	}
+
+	if adminVersion != "" {
+		adminVersion = strings.ToLower(strings.TrimSpace(adminVersion))
+		if err := config.SetAdminAPIVersion(adminVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Admin API version set to %s\n", adminVersion)
+	}
+
+	if networkSet {
+		settings, err := config.GetNetworkSettings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if cmd.Flags().Changed("request-timeout") {
+			settings.RequestTimeout, _ = cmd.Flags().GetString("request-timeout")
+		}
+		if cmd.Flags().Changed("command-timeout") {
+			settings.CommandTimeout, _ = cmd.Flags().GetString("command-timeout")
+		}
+		if cmd.Flags().Changed("proxy") {
+			settings.ProxyURL, _ = cmd.Flags().GetString("proxy")
+		}
+		if cmd.Flags().Changed("ca-bundle") {
+			settings.CABundle, _ = cmd.Flags().GetString("ca-bundle")
+		}
+		if cmd.Flags().Changed("max-conns-per-host") {
+			settings.MaxConnsPerHost, _ = cmd.Flags().GetInt("max-conns-per-host")
+		}
+		if err := config.SetNetworkSettings(settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Network settings saved\n")
+	}
+
+	if endpointsSet {
+		appConfig, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		adminEndpoint, dataEndpoint := appConfig.AdminAPIEndpoint, appConfig.DataAPIEndpoint
+		if cmd.Flags().Changed("admin-api-endpoint") {
+			adminEndpoint, _ = cmd.Flags().GetString("admin-api-endpoint")
+		}
+		if cmd.Flags().Changed("data-api-endpoint") {
+			dataEndpoint, _ = cmd.Flags().GetString("data-api-endpoint")
+		}
+		if err := config.SetAPIEndpoints(strings.TrimSpace(adminEndpoint), strings.TrimSpace(dataEndpoint)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ API endpoints saved\n")
+	}
+
+	if assistantSet {
+		appConfig, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		settings := appConfig.Assistant
+		if cmd.Flags().Changed("assistant-endpoint") {
+			settings.Endpoint, _ = cmd.Flags().GetString("assistant-endpoint")
+			settings.Endpoint = strings.TrimSpace(settings.Endpoint)
+		}
+		if cmd.Flags().Changed("assistant-model") {
+			settings.Model, _ = cmd.Flags().GetString("assistant-model")
+			settings.Model = strings.TrimSpace(settings.Model)
+		}
+		if err := config.SetAssistantSettings(settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to save configuration: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Assistant settings saved\n")
+		if settings.Endpoint != "" && os.Getenv(ask.APIKeyEnv) == "" {
+			fmt.Printf("💡 Set %s to the endpoint's API key\n", ask.APIKeyEnv)
+		}
+	}
+
+	// Get config path for display
+	configPath, _ := config.GetConfigPath()
+	fmt.Printf("📁 Config file: %s\n", configPath)
+	if credentialsSet {
+		fmt.Println("🚀 You can now create presets with refresh tokens")
+	}
+}
+
+func configShowCmdHandler(cmd *cobra.Command, args []string) {
+	fmt.Println("📋 Current GA4 Admin Configuration:")
+	fmt.Println()
+
+	// Load configuration
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display config path
+	configPath, _ := config.GetConfigPath()
+	fmt.Printf("📁 Config Location: %s\n", configPath)
+	fmt.Println()
+
+	// Display OAuth credentials status
+	if appConfig.ClientID != "" {
+		fmt.Printf("🔑 OAuth Client ID: %s...%s (configured)\n", 
+			appConfig.ClientID[:min(12, len(appConfig.ClientID))], 
+			appConfig.ClientID[max(0, len(appConfig.ClientID)-4):])
+		fmt.Printf("🔐 OAuth Client Secret: [HIDDEN] (configured)\n")
+	} else {
+		fmt.Println("❌ OAuth Client ID: Not configured")
+		fmt.Println("❌ OAuth Client Secret: Not configured")
+		fmt.Println()
+		fmt.Println("💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' to configure")
+	}
+
+	// Display Data API transport
+	transport := appConfig.DataAPITransport
+	if transport == "" {
+		transport = config.TransportREST
+	}
+	fmt.Printf("🔌 Data API Transport: %s\n", transport)
+	adminVersion := appConfig.AdminAPIVersion
+	if adminVersion == "" {
+		adminVersion = config.AdminAPIAuto
+	}
+	fmt.Printf("🧭 Admin API Version: %s\n", adminVersion)
+
+	// Display endpoint overrides (environment variables take precedence)
+	if adminEndpoint, err := config.GetAdminAPIEndpoint(); err == nil && adminEndpoint != config.DefaultAdminAPIEndpoint {
+		fmt.Printf("🛰️  Admin API Endpoint: %s\n", adminEndpoint)
+	}
+	if dataEndpoint, err := config.GetDataAPIEndpoint(); err == nil && dataEndpoint != config.DefaultDataAPIEndpoint {
+		fmt.Printf("🛰️  Data API Endpoint: %s\n", dataEndpoint)
+	}
+	if appConfig.Assistant.Endpoint != "" {
+		fmt.Printf("🧠 Assistant: %s at %s\n", appConfig.Assistant.Model, appConfig.Assistant.Endpoint)
+	}
+
+	// Display network settings
+	network := appConfig.Network
+	if network.RequestTimeout != "" {
+		fmt.Printf("⏱️  Request Timeout: %s\n", network.RequestTimeout)
+	}
+	if network.CommandTimeout != "" {
+		fmt.Printf("⏱️  Command Timeout: %s\n", network.CommandTimeout)
+	}
+	if network.ProxyURL != "" {
+		fmt.Printf("🌐 Proxy: %s\n", network.ProxyURL)
+	}
+	if network.CABundle != "" {
+		fmt.Printf("🔒 CA Bundle: %s\n", network.CABundle)
+	}
+	if network.MaxConnsPerHost > 0 {
+		fmt.Printf("🔌 Max Connections per Host: %d\n", network.MaxConnsPerHost)
+	}
+
+	if len(appConfig.Notifiers) > 0 {
+		names := make([]string, len(appConfig.Notifiers))
+		for i, notifier := range appConfig.Notifiers {
+			names[i] = notifier.Name
+		}
+		fmt.Printf("📧 Notifiers: %s\n", strings.Join(names, ", "))
+	}
+	if len(appConfig.ChannelMappings) > 0 {
+		names := make([]string, len(appConfig.ChannelMappings))
+		for i, mapping := range appConfig.ChannelMappings {
+			names[i] = mapping.Name
+		}
+		fmt.Printf("🏷️  Channel mappings: %s\n", strings.Join(names, ", "))
+	}
+
+	// Display active preset
+	if appConfig.ActivePreset != "" {
+		fmt.Printf("🎯 Active Preset: %s\n", appConfig.ActivePreset)
+	} else {
+		fmt.Println("📝 Active Preset: None")
+	}
+
+	// Display timestamps
+	fmt.Println()
+	fmt.Printf("📅 Created: %s\n", appConfig.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("🔄 Updated: %s\n", appConfig.UpdatedAt.Format("2006-01-02 15:04:05"))
+}
+
+func configNotifierSetCmdHandler(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	notifier := config.NotifierConfig{Name: args[0]}
+	notifier.Type, _ = flags.GetString("type")
+	notifier.From, _ = flags.GetString("from")
+	notifier.To, _ = flags.GetStringSlice("to")
+	notifier.Delivery, _ = flags.GetString("delivery")
+	notifier.LinkBaseURL, _ = flags.GetString("link-base-url")
+	notifier.SMTPHost, _ = flags.GetString("smtp-host")
+	notifier.SMTPPort, _ = flags.GetInt("smtp-port")
+	notifier.SMTPUsername, _ = flags.GetString("smtp-username")
+	notifier.SMTPPasswordEnv, _ = flags.GetString("smtp-password-env")
+	notifier.SendGridAPIKeyEnv, _ = flags.GetString("sendgrid-api-key-env")
+	notifier.Type = strings.ToLower(notifier.Type)
+	notifier.Delivery = strings.ToLower(notifier.Delivery)
+
+	if err := config.SetNotifier(notifier); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Notifier '%s' saved (%s, %s delivery to %s)\n", notifier.Name, notifier.Type, notifier.Delivery, strings.Join(notifier.To, ", "))
+	fmt.Printf("💡 Try it with 'ga4admin config notifier test %s'\n", notifier.Name)
+}
+
+func configNotifierListCmdHandler(cmd *cobra.Command, args []string) {
+	notifiers, err := config.ListNotifiers()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(notifiers) == 0 {
+		fmt.Println("📭 No notifiers configured")
+		fmt.Println("💡 Add one with 'ga4admin config notifier set <name> --type smtp|sendgrid --from <addr> --to <addr>'")
+		return
+	}
+
+	fmt.Println("📧 Notifiers:")
+	for _, notifier := range notifiers {
+		fmt.Println()
+		fmt.Printf("   %s (%s)\n", notifier.Name, notifier.Type)
+		fmt.Printf("      From: %s\n", notifier.From)
+		fmt.Printf("      To: %s\n", strings.Join(notifier.To, ", "))
+		if notifier.Delivery == config.DeliveryLink {
+			fmt.Printf("      Delivery: links under %s\n", notifier.LinkBaseURL)
+		} else {
+			fmt.Println("      Delivery: attachments")
+		}
+		if notifier.Type == config.NotifierSMTP {
+			fmt.Printf("      Server: %s:%d\n", notifier.SMTPHost, notifier.SMTPPort)
+		}
+	}
+}
+
+func configNotifierRemoveCmdHandler(cmd *cobra.Command, args []string) {
+	if err := config.RemoveNotifier(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Removed notifier '%s'\n", args[0])
+}
+
+func configNotifierTestCmdHandler(cmd *cobra.Command, args []string) {
+	fmt.Printf("📧 Sending test message through notifier '%s'...\n", args[0])
+
+	err := sendExportNotification(args[0], "ga4admin test message",
+		"This is a test message from ga4admin. Exported results will be delivered like this.", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Println("✅ Test message sent")
+}
+
+func configChannelsAddCmdHandler(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	rule := config.ChannelRule{}
+	rule.Channel, _ = flags.GetString("channel")
+	rule.Source, _ = flags.GetString("source")
+	rule.Medium, _ = flags.GetString("medium")
+	rule.Campaign, _ = flags.GetString("campaign")
+	position, _ := flags.GetInt("position")
+
+	mapping := config.ChannelMapping{Name: args[0]}
+	if existing, err := config.GetChannelMapping(args[0]); err == nil {
+		mapping = *existing
+	}
+	if flags.Changed("column") {
+		mapping.Column, _ = flags.GetString("column")
+	}
+	if flags.Changed("default") {
+		mapping.Default, _ = flags.GetString("default")
+	}
+
+	if position < 0 || position > len(mapping.Rules)+1 {
+		fmt.Fprintf(os.Stderr, "Error: --position must be between 1 and %d\n", len(mapping.Rules)+1)
+		os.Exit(exitcode.Validation)
+	}
+	if position == 0 {
+		position = len(mapping.Rules) + 1
+	}
+	mapping.Rules = append(mapping.Rules[:position-1], append([]config.ChannelRule{rule}, mapping.Rules[position-1:]...)...)
+
+	if err := config.SetChannelMapping(mapping); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("✅ Added rule %d to channel mapping '%s': %s\n", position, mapping.Name, describeChannelRule(rule))
+	fmt.Printf("💡 Try it with 'ga4admin config channels test %s --source <source> --medium <medium>'\n", mapping.Name)
+}
+
+func configChannelsListCmdHandler(cmd *cobra.Command, args []string) {
+	mappings, err := config.ListChannelMappings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(mappings) == 0 {
+		fmt.Println("📭 No channel mappings configured")
+		fmt.Println("💡 Add one with 'ga4admin config channels add <mapping> --channel <channel> --source <pattern> --medium <pattern>'")
+		return
+	}
+
+	fmt.Println("🏷️  Channel mappings:")
+	for _, mapping := range mappings {
+		column, defaultChannel := mapping.Column, mapping.Default
+		if column == "" {
+			column = config.DefaultChannelColumn
+		}
+		if defaultChannel == "" {
+			defaultChannel = config.DefaultChannel
+		}
+
+		fmt.Println()
+		fmt.Printf("   %s (column '%s')\n", mapping.Name, column)
+		for i, rule := range mapping.Rules {
+			fmt.Printf("      %d. %s\n", i+1, describeChannelRule(rule))
+		}
+		fmt.Printf("      Otherwise: %s\n", defaultChannel)
+	}
+}
+
+func configChannelsRemoveCmdHandler(cmd *cobra.Command, args []string) {
+	position, _ := cmd.Flags().GetInt("rule")
+	if position == 0 {
+		if err := config.RemoveChannelMapping(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.NotFound)
+		}
+		fmt.Printf("✅ Removed channel mapping '%s'\n", args[0])
+		return
+	}
+
+	mapping, err := config.GetChannelMapping(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.NotFound)
+	}
+	if position < 1 || position > len(mapping.Rules) {
+		fmt.Fprintf(os.Stderr, "Error: Channel mapping '%s' has no rule %d\n", mapping.Name, position)
+		os.Exit(exitcode.Validation)
+	}
+
+	lastRule := len(mapping.Rules) == 1
+	if lastRule {
+		// A mapping without rules would assign every row the default channel
+		err = config.RemoveChannelMapping(mapping.Name)
+	} else {
+		mapping.Rules = append(mapping.Rules[:position-1], mapping.Rules[position:]...)
+		err = config.SetChannelMapping(*mapping)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Removed rule %d from channel mapping '%s'\n", position, mapping.Name)
+	if lastRule {
+		fmt.Println("   It was the last rule, so the mapping was removed too")
+	}
+}
+
+func configChannelsTestCmdHandler(cmd *cobra.Command, args []string) {
+	source, _ := cmd.Flags().GetString("source")
+	medium, _ := cmd.Flags().GetString("medium")
+	campaign, _ := cmd.Flags().GetString("campaign")
+
+	mapping, err := config.GetChannelMapping(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.NotFound)
+	}
+	matcher, err := results.NewChannelMatcher(*mapping)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	channel, rule := matcher.Channel(source, medium, campaign)
+	fmt.Printf("🏷️  %s\n", channel)
+	if rule == 0 {
+		fmt.Println("   No rule matched; this is the default channel")
+	} else {
+		fmt.Printf("   Matched rule %d: %s\n", rule, describeChannelRule(mapping.Rules[rule-1]))
+	}
+}
+
+// describeChannelRule formats a rule as e.g. "source ~ google, medium ~ cpc → Paid Search"
+func describeChannelRule(rule config.ChannelRule) string {
+	var conditions []string
+	for _, condition := range []struct{ field, pattern string }{
+		{"source", rule.Source}, {"medium", rule.Medium}, {"campaign", rule.Campaign},
+	} {
+		if condition.pattern != "" {
+			conditions = append(conditions, fmt.Sprintf("%s ~ %s", condition.field, condition.pattern))
+		}
+	}
+	return strings.Join(conditions, ", ") + " → " + rule.Channel
+}
+
+// sendExportNotification emails exported files through a configured notifier
+func sendExportNotification(notifierName, subject, summary string, paths []string) error {
+	notifierConfig, err := config.GetNotifier(notifierName)
+	if err != nil {
+		return err
+	}
+
+	msg, err := notify.ExportMessage(notifierConfig, subject, summary, paths)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := notify.New(notifierConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := commandContext(2*time.Minute)
+	defer cancel()
+
+	if err := notifier.Send(ctx, msg); err != nil {
+		return fmt.Errorf("notifier '%s' failed: %w", notifierName, err)
+	}
+	return nil
+}
+
+// Helper functions
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
 
 func presetCreateCmdHandler(cmd *cobra.Command, args []string) {
 	presetName := args[0]
 	refreshToken, _ := cmd.Flags().GetString("refresh-token")
 	userEmail, _ := cmd.Flags().GetString("user-email")
-	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	scopeNames, _ := cmd.Flags().GetStringSlice("scopes")
+	keyPath, _ := cmd.Flags().GetString("service-account")
+	impersonate, _ := cmd.Flags().GetString("impersonate")
+
+	scopes, err := api.ParseScopes(scopeNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --scopes: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	switch {
+	case refreshToken != "" && keyPath != "":
+		fmt.Fprintf(os.Stderr, "Error: Give either --refresh-token or --service-account, not both\n")
+		os.Exit(exitcode.Validation)
+	case refreshToken == "" && keyPath == "":
+		fmt.Fprintf(os.Stderr, "Error: --refresh-token or --service-account is required\n")
+		os.Exit(exitcode.Validation)
+	case impersonate != "" && keyPath == "":
+		fmt.Fprintf(os.Stderr, "Error: --impersonate needs --service-account\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("➕ Creating preset '%s'...\n", presetName)
+	if keyPath != "" {
+		createServiceAccountPreset(presetName, keyPath, impersonate, scopes, noValidate)
+		return
+	}
+
+	// Validate OAuth credentials are configured
+	hasCredentials, err := config.HasClientCredentials()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to check OAuth configuration: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !hasCredentials {
+		fmt.Fprintf(os.Stderr, "Error: OAuth client credentials not configured\n")
+		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Validate refresh token (unless --no-validate is specified)
+	if !noValidate {
+		fmt.Println("🔍 Validating refresh token...")
+		
+		// Create auth client for validation
+		authClient, err := api.NewAuthClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create auth client for validation: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+
+		// Test the refresh token
+		ctx, cancel := commandContext(30*time.Second)
+		defer cancel()
+
+		if err := authClient.ValidateRefreshToken(ctx, refreshToken); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Refresh token validation failed: %v\n", err)
+			fmt.Fprintf(os.Stderr, "\n💡 Common issues:\n")
+			fmt.Fprintf(os.Stderr, "   - Token has expired or been revoked\n")
+			fmt.Fprintf(os.Stderr, "   - Token doesn't have required GA4 permissions\n")
+			fmt.Fprintf(os.Stderr, "   - Network connectivity issues\n")
+			fmt.Fprintf(os.Stderr, "\n🔧 To skip validation: add --no-validate flag\n")
+			os.Exit(exitcode.For(err))
+		}
+
+		fmt.Println("✅ Refresh token is valid!")
+
+		// Record what Google actually granted rather than what was asked for
+		granted, err := authClient.GrantedScopes(ctx, refreshToken)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to check the token's scopes: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if len(granted) > 0 {
+			for _, scope := range scopes {
+				if !api.HasScope(granted, scope) {
+					fmt.Fprintf(os.Stderr, "Error: The refresh token was not granted %s (granted: %s)\n", api.ScopeName(scope), strings.Join(api.ScopeNames(granted), ", "))
+					fmt.Fprintf(os.Stderr, "💡 Create a token that includes it, or drop it from --scopes\n")
+					os.Exit(exitcode.Auth)
+				}
+			}
+			scopes = granted
+		}
+	} else {
+		fmt.Println("⚠️  Skipping token validation (--no-validate specified)")
+	}
+
+	// Create the preset
+	if err := preset.CreatePreset(presetName, refreshToken, userEmail, scopes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Get preset path for display
+	presetPath, _ := preset.GetPresetPath(presetName)
+	fmt.Printf("✅ Preset '%s' created successfully\n", presetName)
+	fmt.Printf("📁 Preset file: %s\n", presetPath)
+	if userEmail != "" {
+		fmt.Printf("👤 User email: %s\n", userEmail)
+	}
+	fmt.Printf("🔐 Scopes: %s\n", strings.Join(api.ScopeNames(scopes), ", "))
+	
+	if noValidate {
+		fmt.Println("⚠️  Remember: Token was not validated - test with API commands")
+	}
+	fmt.Println("🚀 You can now use 'ga4admin preset use " + presetName + "' to activate it")
+}
+
+// createServiceAccountPreset creates a preset that authenticates with a
+// service account key, first getting a token with it unless noValidate
+func createServiceAccountPreset(presetName, keyPath, impersonate string, scopes []string, noValidate bool) {
+	key, err := api.LoadServiceAccountKey(keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	if !noValidate {
+		fmt.Printf("🔍 Getting a token as %s...\n", describeServiceAccount(key.ClientEmail, impersonate))
+		ctx, cancel := commandContext(30*time.Second)
+		defer cancel()
+
+		if _, err := api.ServiceAccountToken(ctx, keyPath, impersonate, scopes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "\n🔧 To skip validation: add --no-validate flag\n")
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Println("✅ Service account credentials work!")
+	} else {
+		fmt.Println("⚠️  Skipping credential validation (--no-validate specified)")
+	}
+
+	if err := preset.CreateServiceAccountPreset(presetName, keyPath, impersonate, scopes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	presetPath, _ := preset.GetPresetPath(presetName)
+	fmt.Printf("✅ Preset '%s' created successfully\n", presetName)
+	fmt.Printf("📁 Preset file: %s\n", presetPath)
+	fmt.Printf("🤖 Service account: %s\n", describeServiceAccount(key.ClientEmail, impersonate))
+	fmt.Printf("🔐 Scopes: %s\n", strings.Join(api.ScopeNames(scopes), ", "))
+	fmt.Println("🚀 You can now use 'ga4admin preset use " + presetName + "' to activate it")
+}
+
+// describeServiceAccount names a service account and the user it impersonates
+func describeServiceAccount(clientEmail, impersonate string) string {
+	if impersonate == "" {
+		return clientEmail
+	}
+	return fmt.Sprintf("%s acting as %s", clientEmail, impersonate)
+}
+
+func presetImpersonateCmdHandler(cmd *cobra.Command, args []string) {
+	presetName := args[0]
+	clearSubject, _ := cmd.Flags().GetBool("clear")
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+
+	var subject string
+	switch {
+	case len(args) == 2 && clearSubject:
+		fmt.Fprintf(os.Stderr, "Error: Give a user or --clear, not both\n")
+		os.Exit(exitcode.Validation)
+	case len(args) == 2:
+		subject = args[1]
+	case !clearSubject:
+		fmt.Fprintf(os.Stderr, "Error: Give the user to impersonate, or --clear to act as the service account itself\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	p, err := preset.LoadPreset(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !api.UsesServiceAccount(p) {
+		fmt.Fprintf(os.Stderr, "Error: Preset '%s' signs in with a refresh token; only service account presets can impersonate users\n", presetName)
+		os.Exit(exitcode.Validation)
+	}
+	key, err := api.LoadServiceAccountKey(p.ServiceAccountKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if !noValidate {
+		fmt.Printf("🔍 Getting a token as %s...\n", describeServiceAccount(key.ClientEmail, subject))
+		ctx, cancel := commandContext(30*time.Second)
+		defer cancel()
+
+		if _, err := api.ServiceAccountToken(ctx, p.ServiceAccountKey, subject, p.Scopes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+
+	if err := preset.SetImpersonate(presetName, subject); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	fmt.Printf("✅ Preset '%s' now uses %s\n", presetName, describeServiceAccount(key.ClientEmail, subject))
+	if !p.SyncedAt.IsZero() {
+		fmt.Printf("💡 Accounts visible to the preset may have changed - run 'ga4admin preset sync' with it active\n")
+	}
+}
+
+func presetListCmdHandler(cmd *cobra.Command, args []string) {
+	fmt.Println("📝 Available GA4 Presets:")
+	fmt.Println()
+
+	// Get active preset name
+	activePresetName, err := config.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get active preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Load all presets
+	presets, err := preset.ListPresets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list presets: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(presets) == 0 {
+		fmt.Println("❌ No presets found")
+		fmt.Println()
+		fmt.Println("💡 Create your first preset with:")
+		fmt.Println("   ga4admin preset create <name> --refresh-token <token>")
+		return
+	}
+
+	// Display presets
+	for i, p := range presets {
+		// Active preset indicator
+		activeIndicator := "  "
+		if p.Name == activePresetName {
+			activeIndicator = "▶️ "
+		}
+
+		fmt.Printf("%s📋 %s\n", activeIndicator, p.Name)
+		
+		// User email if available
+		if p.UserEmail != "" {
+			fmt.Printf("   👤 %s\n", p.UserEmail)
+		}
+		if api.UsesServiceAccount(&p) {
+			clientEmail := p.ServiceAccountKey
+			if key, err := api.LoadServiceAccountKey(p.ServiceAccountKey); err == nil {
+				clientEmail = key.ClientEmail
+			}
+			fmt.Printf("   🤖 Service account: %s\n", describeServiceAccount(clientEmail, p.Impersonate))
+		}
+
+		// Account count
+		accountCount := len(p.Accounts)
+		if accountCount > 0 {
+			fmt.Printf("   🏢 %d account(s)\n", accountCount)
+		}
+		if !p.SyncedAt.IsZero() {
+			fmt.Printf("   🔗 Synced: %s\n", p.SyncedAt.Format("2006-01-02 15:04"))
+		}
+		if len(p.Scopes) > 0 {
+			fmt.Printf("   🔐 Scopes: %s\n", strings.Join(api.ScopeNames(p.Scopes), ", "))
+		}
+		if p.NeedsReauth {
+			fmt.Printf("   ⚠️  Refresh token expired or revoked - run 'ga4admin preset reauth %s'\n", p.Name)
+		}
+
+		// Timestamps
+		fmt.Printf("   📅 Created: %s\n", p.CreatedAt.Format("2006-01-02 15:04"))
+		fmt.Printf("   🔄 Last used: %s\n", p.LastUsed.Format("2006-01-02 15:04"))
+
+		// Add spacing between presets
+		if i < len(presets)-1 {
+			fmt.Println()
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("💡 Use 'ga4admin preset use <name>' to set active preset")
+}
+
+func presetDeleteCmdHandler(cmd *cobra.Command, args []string) {
+	presetName := args[0]
+
+	// Check if preset exists
+	exists, err := preset.PresetExists(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to check preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Error: Preset '%s' does not exist\n", presetName)
+		os.Exit(exitcode.NotFound)
+	}
+
+	// Confirmation prompt
+	fmt.Printf("⚠️  Are you sure you want to delete preset '%s'? (y/N): ", presetName)
+	var response string
+	fmt.Scanln(&response)
+	
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		fmt.Println("❌ Deletion cancelled")
+		return
+	}
+
+	// Delete the preset
+	if err := preset.DeletePreset(presetName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to delete preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Preset '%s' deleted successfully\n", presetName)
+}
+
+func presetUseCmdHandler(cmd *cobra.Command, args []string) {
+	presetName := args[0]
+
+	// Set active preset
+	if err := preset.SetActivePreset(presetName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to set active preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Activated preset '%s'\n", presetName)
+	if p, err := preset.LoadPreset(presetName); err == nil && p.NeedsReauth {
+		fmt.Printf("⚠️  Its refresh token was rejected last time - run 'ga4admin preset reauth %s'\n", presetName)
+		return
+	}
+	fmt.Println("🚀 You can now use GA4 API commands")
+}
+
+func presetReauthCmdHandler(cmd *cobra.Command, args []string) {
+	presetName := args[0]
+	refreshToken, _ := cmd.Flags().GetString("refresh-token")
+	withEdit, _ := cmd.Flags().GetBool("edit")
+	noBrowser, _ := cmd.Flags().GetBool("no-browser")
+	device, _ := cmd.Flags().GetBool("device")
+
+	existing, err := preset.LoadPreset(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if api.UsesServiceAccount(existing) {
+		fmt.Fprintf(os.Stderr, "Error: Preset '%s' authenticates with a service account key, not a refresh token\n", presetName)
+		fmt.Fprintf(os.Stderr, "💡 Replace the key file at %s, or change the user it acts as with 'ga4admin preset impersonate'\n", existing.ServiceAccountKey)
+		os.Exit(exitcode.Validation)
+	}
+	// Re-authenticating shouldn't quietly take away write access
+	withEdit = withEdit || api.HasScope(existing.Scopes, api.AnalyticsEditScope)
+
+	hasCredentials, err := config.HasClientCredentials()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to check OAuth configuration: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !hasCredentials {
+		fmt.Fprintf(os.Stderr, "Error: OAuth client credentials not configured\n")
+		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	authClient, err := api.NewAuthClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create auth client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("🔑 Re-authenticating preset '%s'...\n", presetName)
+
+	var granted []string
+	if refreshToken == "" {
+		scopes := []string{api.AnalyticsReadOnlyScope}
+		if withEdit {
+			scopes = append(scopes, api.AnalyticsEditScope)
+		}
+		refreshToken, granted = authorizeInteractively(authClient, scopes, device, noBrowser)
+	} else {
+		fmt.Println("🔍 Validating refresh token...")
+		ctx, cancel := commandContext(30*time.Second)
+		defer cancel()
+
+		if err := authClient.ValidateRefreshToken(ctx, refreshToken); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Refresh token validation failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if granted, err = authClient.GrantedScopes(ctx, refreshToken); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to check the token's scopes: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+
+	if err := preset.ReplaceRefreshToken(presetName, refreshToken, granted); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to update preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Preset '%s' has a new refresh token; accounts, aliases and cache were kept\n", presetName)
+	if len(granted) > 0 {
+		fmt.Printf("🔐 Scopes: %s\n", strings.Join(api.ScopeNames(granted), ", "))
+		if withEdit && !api.HasScope(granted, api.AnalyticsEditScope) {
+			fmt.Printf("⚠️  %s was not granted - commands that change configuration will fail until it is\n", api.ScopeName(api.AnalyticsEditScope))
+		}
+	}
+}
+
+func presetLoginCmdHandler(cmd *cobra.Command, args []string) {
+	presetName := args[0]
+	userEmail, _ := cmd.Flags().GetString("user-email")
+	scopeNames, _ := cmd.Flags().GetStringSlice("scopes")
+	noBrowser, _ := cmd.Flags().GetBool("no-browser")
+	device, _ := cmd.Flags().GetBool("device")
+
+	scopes, err := api.ParseScopes(scopeNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --scopes: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	if !preset.IsValidPresetName(presetName) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid preset name: must contain only letters, numbers, underscores, and hyphens (max 50 chars)\n")
+		os.Exit(exitcode.Validation)
+	}
+	exists, err := preset.PresetExists(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if exists {
+		fmt.Fprintf(os.Stderr, "Error: Preset '%s' already exists\n", presetName)
+		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin preset reauth %s' to give it a new refresh token\n", presetName)
+		os.Exit(exitcode.Validation)
+	}
+
+	hasCredentials, err := config.HasClientCredentials()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to check OAuth configuration: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !hasCredentials {
+		fmt.Fprintf(os.Stderr, "Error: OAuth client credentials not configured\n")
+		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	authClient, err := api.NewAuthClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create auth client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("🔑 Signing in to create preset '%s'...\n", presetName)
+	refreshToken, granted := authorizeInteractively(authClient, scopes, device, noBrowser)
+	var missing []string
+	if len(granted) > 0 {
+		for _, scope := range scopes {
+			if !api.HasScope(granted, scope) {
+				missing = append(missing, api.ScopeName(scope))
+			}
+		}
+		scopes = granted
+	}
+
+	if err := preset.CreatePreset(presetName, refreshToken, userEmail, scopes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	presetPath, _ := preset.GetPresetPath(presetName)
+	fmt.Printf("✅ Preset '%s' created successfully\n", presetName)
+	fmt.Printf("📁 Preset file: %s\n", presetPath)
+	fmt.Printf("🔐 Scopes: %s\n", strings.Join(api.ScopeNames(scopes), ", "))
+	if len(missing) > 0 {
+		fmt.Printf("⚠️  Not granted: %s - commands that need it will fail until 'ga4admin preset reauth %s'\n", strings.Join(missing, ", "), presetName)
+	}
+	fmt.Println("🚀 You can now use 'ga4admin preset use " + presetName + "' to activate it")
+}
+
+// authorizeInteractively has the user sign in, through the device flow or
+// the browser flow, and returns the new refresh token and the scopes Google
+// granted it. It exits on failure.
+func authorizeInteractively(authClient *api.AuthClient, scopes []string, device, noBrowser bool) (string, []string) {
+	var refreshToken string
+	var granted []string
+	var err error
+	if device {
+		// Google's device codes are valid for 30 minutes at most
+		ctx, cancel := commandContext(30*time.Minute)
+		defer cancel()
+
+		token, deviceErr := authClient.AuthorizeOnDevice(ctx, scopes, func(code api.DeviceCode) {
+			fmt.Println("📱 On any device with a browser, open:")
+			fmt.Printf("\n   %s\n\n", code.VerificationURL)
+			fmt.Println("   and enter the code:")
+			fmt.Printf("\n   %s\n\n", code.UserCode)
+			if !code.Expiry.IsZero() {
+				fmt.Printf("⏳ Waiting for approval (the code expires at %s)...\n", code.Expiry.Local().Format("15:04"))
+			} else {
+				fmt.Println("⏳ Waiting for approval...")
+			}
+		})
+		if err = deviceErr; err == nil {
+			refreshToken, granted = token.RefreshToken, api.TokenScopes(token)
+		}
+	} else {
+		ctx, cancel := commandContext(5*time.Minute)
+		defer cancel()
+
+		token, browserErr := authClient.AuthorizeInBrowser(ctx, scopes, func(authURL string) {
+			fmt.Println("🌐 Open this URL and sign in with the account the preset should use:")
+			fmt.Printf("\n   %s\n\n", authURL)
+			if !noBrowser {
+				if err := openBrowser(authURL); err != nil {
+					fmt.Printf("💡 Could not open a browser (%v) - open the URL manually\n", err)
+				}
+			}
+			fmt.Println("⏳ Waiting for authorization...")
+		})
+		if err = browserErr; err == nil {
+			refreshToken, granted = token.RefreshToken, api.TokenScopes(token)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if device {
+			fmt.Fprintf(os.Stderr, "💡 Without the device flow, create the preset on a machine with a browser, or pass a refresh token with 'preset create --refresh-token'\n")
+		}
+		os.Exit(exitcode.For(err))
+	}
+	return refreshToken, granted
+}
+
+// openBrowser asks the desktop environment to open url
+func openBrowser(url string) error {
+	var command *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		command = exec.Command("open", url)
+	case "windows":
+		command = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		command = exec.Command("xdg-open", url)
+	}
+	return command.Start()
+}
+
+func presetSyncCmdHandler(cmd *cobra.Command, args []string) {
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	fmt.Printf("🔄 Syncing accounts and properties for preset '%s'...\n", activePreset.Name)
+
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
+
+	var etags api.ETagStore
+	if cacheClient := openListingCache(); cacheClient != nil {
+		defer cacheClient.Close()
+		etags = cacheClient
+	}
+
+	if err := access.SyncPresetAccounts(ctx, activePreset, etags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to sync preset: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	propertyCount := 0
+	for _, account := range activePreset.Accounts {
+		propertyCount += len(account.Properties)
+	}
+
+	fmt.Printf("✅ Synced %d account(s) and %d propert(y/ies)\n", len(activePreset.Accounts), propertyCount)
+	fmt.Println("💡 Property access is now checked locally before queries run")
+}
+
+// Alias command handlers
+
+func aliasSetCmd(cmd *cobra.Command, args []string) {
+	alias, propertyID := args[0], args[1]
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	previous, replaced := activePreset.Aliases[alias]
+	if err := preset.SetAlias(activePreset.Name, alias, propertyID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if replaced && previous != propertyID {
+		fmt.Printf("✅ Alias '%s' now points to property %s (was %s)\n", alias, propertyID, previous)
+	} else {
+		fmt.Printf("✅ Alias '%s' points to property %s in preset '%s'\n", alias, propertyID, activePreset.Name)
+	}
+
+	// Synced presets know their properties, so catch typos early
+	if !activePreset.SyncedAt.IsZero() && findSyncedProperty(activePreset, propertyID) == nil {
+		fmt.Printf("⚠️  Property %s was not found in the last sync of preset '%s'\n", propertyID, activePreset.Name)
+		fmt.Println("💡 Run 'ga4admin preset sync' if the property was added recently")
+	}
+}
+
+func aliasListCmd(cmd *cobra.Command, args []string) {
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	aliases := preset.ListAliases(activePreset)
+	if len(aliases) == 0 {
+		fmt.Printf("📭 No aliases in preset '%s'\n", activePreset.Name)
+		fmt.Println("💡 Create one with 'ga4admin alias set <name> <property-id>'")
+		return
+	}
+
+	fmt.Printf("🏷️  Aliases in preset '%s':\n\n", activePreset.Name)
+	for _, alias := range aliases {
+		if property := findSyncedProperty(activePreset, alias.PropertyID); property != nil {
+			fmt.Printf("   %-20s %s (%s)\n", alias.Name, alias.PropertyID, property.DisplayName)
+		} else {
+			fmt.Printf("   %-20s %s\n", alias.Name, alias.PropertyID)
+		}
+	}
+}
+
+func aliasRemoveCmd(cmd *cobra.Command, args []string) {
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	if err := preset.RemoveAlias(activePreset.Name, args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Removed alias '%s'\n", args[0])
+}
+
+// findSyncedProperty returns the property from the preset's last sync, if present
+func findSyncedProperty(p *config.Preset, propertyID string) *config.Property {
+	for i := range p.Accounts {
+		for j := range p.Accounts[i].Properties {
+			if p.Accounts[i].Properties[j].ID == propertyID {
+				return &p.Accounts[i].Properties[j]
+			}
+		}
+	}
+	return nil
+}
+
+func accountsListCmd(cmd *cobra.Command, args []string) {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	fmt.Println("🏢 Listing GA4 accounts...")
+
+	cacheClient := openListingCache()
+	if cacheClient != nil {
+		defer cacheClient.Close()
+	}
+
+	accounts, cachedAt, err := getAccountsWithClient(cacheClient, refresh)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	printListingFreshness(cachedAt)
+
+	if len(accounts) == 0 {
+		fmt.Println("❌ No GA4 accounts found")
+		fmt.Println("💡 Ensure the refresh token has GA4 read permissions")
+		return
+	}
+
+	// Display accounts
+	fmt.Printf("📊 Found %d account(s):\n\n", len(accounts))
+	for i, account := range accounts {
+		fmt.Printf("🏢 %s (ID: %s)\n", account.DisplayName, account.ID)
+		fmt.Printf("   🌍 Region: %s\n", account.RegionCode)
+		fmt.Printf("   📅 Created: %s\n", account.CreateTime.Format("2006-01-02"))
+		
+		if i < len(accounts)-1 {
+			fmt.Println()
+		}
+	}
+
+	fmt.Println("\n💡 Use 'ga4admin accounts tree' for hierarchical view")
+	fmt.Println("💡 Use 'ga4admin properties list --account <id>' to see properties")
+}
+
+func accountsTreeCmd(cmd *cobra.Command, args []string) {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	fmt.Println("🌳 GA4 Account & Property Tree:")
+	fmt.Println()
+
+	cacheClient := openListingCache()
+	if cacheClient != nil {
+		defer cacheClient.Close()
+	}
+
+	// Get accounts
+	accounts, oldest, err := getAccountsWithClient(cacheClient, refresh)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(accounts) == 0 {
+		fmt.Println("❌ No GA4 accounts found")
+		fmt.Println("💡 Ensure the refresh token has GA4 read permissions")
+		return
+	}
+
+	// Display accounts with properties in tree format
+	for accountIndex, account := range accounts {
+		// Account level
+		isLastAccount := accountIndex == len(accounts)-1
+		accountPrefix := "├── "
+		childPrefix := "│   "
+		if isLastAccount {
+			accountPrefix = "└── "
+			childPrefix = "    "
+		}
+
+		fmt.Printf("%s🏢 %s (ID: %s)\n", accountPrefix, account.DisplayName, account.ID)
+		fmt.Printf("%s   🌍 %s • 📅 %s\n", childPrefix, account.RegionCode, account.CreateTime.Format("2006-01-02"))
+		
+		// Get properties for this account
+		properties, cachedAt, err := getPropertiesWithClient(cacheClient, account.ID, refresh)
+		if err != nil {
+			fmt.Printf("%s   ❌ Error loading properties: %v\n", childPrefix, err)
+			continue
+		}
+		if !cachedAt.IsZero() && (oldest.IsZero() || cachedAt.Before(oldest)) {
+			oldest = cachedAt
+		}
+
+		if len(properties) == 0 {
+			fmt.Printf("%s   📭 No properties found\n", childPrefix)
+		} else {
+			fmt.Printf("%s   📊 %d propert(y/ies):\n", childPrefix, len(properties))
+			
+			// Display properties in simple list
+			for propIndex, property := range properties {
+				isLastProp := propIndex == len(properties)-1
+				propPrefix := "├── "
+				if isLastProp {
+					propPrefix = "└── "
+				}
+				
+				// Service level indicator
+				serviceIcon := "📊"
+				if property.ServiceLevel == "GOOGLE_ANALYTICS_360" {
+					serviceIcon = "🎯" // Premium/360
+				}
+				
+				fmt.Printf("%s   %s%s %s (ID: %s)\n", 
+					childPrefix, propPrefix, serviceIcon, property.DisplayName, property.ID)
+				fmt.Printf("%s      💰 %s • 🌍 %s • 📅 %s\n", 
+					childPrefix, property.CurrencyCode, property.TimeZone, property.CreateTime.Format("2006-01-02"))
+			}
+		}
+		
+		if !isLastAccount {
+			fmt.Println()
+		}
+	}
+	
+	fmt.Println()
+	fmt.Printf("🎯 Total: %d account(s) discovered\n", len(accounts))
+	printListingFreshness(oldest)
+	fmt.Println("💡 Use 'ga4admin properties show <property-id>' for detailed property information")
+}
+
+// listingCacheTTLHours is how long account and property listings are reused.
+// They rarely change but are slow to fetch for large organizations.
+const listingCacheTTLHours = 6
+
+// Helper function to get accounts with proper error handling. cachedAt is
+// zero when the accounts came from the Admin API rather than the cache.
+func getAccountsWithClient(cacheClient *cache.CacheClient, refresh bool) (accounts []config.Account, cachedAt time.Time, err error) {
+	cachedAt, err = cachedListing(cacheClient, "accounts", refresh, &accounts, func(ctx context.Context, adminClient *api.AdminClient) error {
+		accounts, err = adminClient.ListAccounts(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+		return nil
+	})
+	return accounts, cachedAt, err
+}
+
+// Helper function to get an account's properties, from the cache when fresh
+func getPropertiesWithClient(cacheClient *cache.CacheClient, accountID string, refresh bool) (properties []config.Property, cachedAt time.Time, err error) {
+	cachedAt, err = cachedListing(cacheClient, "properties/"+accountID, refresh, &properties, func(ctx context.Context, adminClient *api.AdminClient) error {
+		properties, err = adminClient.ListProperties(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to list properties: %w", err)
+		}
+		return nil
+	})
+	return properties, cachedAt, err
+}
+
+// openListingCache opens the active preset's cache for Admin API listings.
+// It returns nil when there is no usable cache; listings are then fetched live.
+func openListingCache() *cache.CacheClient {
+	activePreset, err := preset.GetActivePreset()
+	if err != nil || activePreset == nil {
+		return nil
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to create cache client, using non-cached mode: %v\n", err)
+		return nil
+	}
+	return cacheClient
+}
+
+// cachedListing fills result from the listing cache unless refresh is set or
+// the entry has expired, otherwise calls fetch and caches what it returns
+func cachedListing(cacheClient *cache.CacheClient, key string, refresh bool, result interface{}, fetch func(ctx context.Context, adminClient *api.AdminClient) error) (time.Time, error) {
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if activePreset == nil {
+		return time.Time{}, fmt.Errorf("%w - run 'ga4admin preset use <name>' first", preset.ErrNoActivePreset)
+	}
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	if cacheClient != nil && !refresh {
+		cachedAt, found, err := cacheClient.GetCachedListing(ctx, key, result)
+		if err == nil && found {
+			return cachedAt, nil
+		}
+	}
+
+	// Create Admin API client
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create Admin API client: %w", err)
+	}
+	if cacheClient != nil {
+		adminClient.SetETagStore(cacheClient)
+	}
+
+	if err := fetch(ctx, adminClient); err != nil {
+		return time.Time{}, err
+	}
+
+	if cacheClient != nil {
+		if err := cacheClient.CacheListing(ctx, key, result, listingCacheTTLHours); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to cache listing: %v\n", err)
+		}
+	}
+	return time.Time{}, nil
+}
+
+// printListingFreshness tells the user a listing came from the cache and how old it is
+func printListingFreshness(cachedAt time.Time) {
+	if cachedAt.IsZero() {
+		return
+	}
+	fmt.Printf("🗄️  Cached %s — use --refresh to update\n", formatAge(time.Since(cachedAt)))
+}
+
+// formatAge renders a duration as a short relative age, e.g. "2h ago"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// reportContext names the time zone dates are in and the currency revenue is
+// in, e.g. "🌍 Time zone: Europe/Berlin · 💰 Currency: EUR"
+func reportContext(result *query.QueryResult) string {
+	if result.ResponseMetadata == nil {
+		return ""
+	}
+	var parts []string
+	if result.ResponseMetadata.TimeZone != "" {
+		parts = append(parts, "🌍 Time zone: "+result.ResponseMetadata.TimeZone)
+	}
+	if result.ResponseMetadata.CurrencyCode != "" {
+		parts = append(parts, "💰 Currency: "+result.ResponseMetadata.CurrencyCode)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// cacheAgeNote describes how long ago cached results were fetched, e.g.
+// " (fetched 2h ago)", or returns "" when their age is unknown
+func cacheAgeNote(result *query.QueryResult) string {
+	if result.CachedAt == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (fetched %s)", formatAge(time.Since(*result.CachedAt)))
+}
+
+func propertiesListCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	fmt.Printf("🏠 Listing GA4 properties for account %s...\n", accountID)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient := openListingCache()
+	if cacheClient != nil {
+		defer cacheClient.Close()
+	}
+
+	// List properties
+	properties, cachedAt, err := getPropertiesWithClient(cacheClient, accountID, refresh)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	printListingFreshness(cachedAt)
+
+	if len(properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		fmt.Println("💡 Ensure the account ID is correct and accessible")
+		return
+	}
+
+	// Display properties
+	fmt.Printf("🏠 Found %d propert(y/ies):\n\n", len(properties))
+	for i, property := range properties {
+		fmt.Printf("📊 %s (ID: %s)\n", property.DisplayName, property.ID)
+		fmt.Printf("   💰 Currency: %s\n", property.CurrencyCode)
+		fmt.Printf("   🌍 Timezone: %s\n", property.TimeZone)
+		fmt.Printf("   🏭 Industry: %s\n", property.IndustryCategory)
+		fmt.Printf("   📈 Service Level: %s\n", property.ServiceLevel)
+		fmt.Printf("   📅 Created: %s\n", property.CreateTime.Format("2006-01-02"))
+		
+		if i < len(properties)-1 {
+			fmt.Println()
+		}
+	}
+
+	fmt.Println("\n💡 Use 'ga4admin properties show <property-id>' for detailed information")
+}
+
+func propertiesShowCmd(cmd *cobra.Command, args []string) {
+	propertyID := args[0]
+	fmt.Printf("📊 Property details for %s...\n", propertyID)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create Admin API client
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Get property details
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	property, err := adminClient.GetProperty(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get property details: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display property details
+	fmt.Printf("📊 %s (ID: %s)\n\n", property.DisplayName, property.ID)
+	
+	fmt.Println("🔧 Configuration:")
+	fmt.Printf("   💰 Currency Code: %s\n", property.CurrencyCode)
+	fmt.Printf("   🌍 Timezone: %s\n", property.TimeZone)
+	fmt.Printf("   🏭 Industry Category: %s\n", property.IndustryCategory)
+	fmt.Printf("   📈 Service Level: %s\n", property.ServiceLevel)
+	fmt.Println()
+	
+	fmt.Println("📅 Timeline:")
+	fmt.Printf("   🆕 Created: %s\n", property.CreateTime.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   🔄 Last Accessed: %s\n", property.LastAccessed.Format("2006-01-02 15:04:05"))
+	fmt.Println()
+	
+	fmt.Println("💡 Next steps:")
+	fmt.Printf("   • ga4admin metadata dimensions --property %s\n", propertyID)
+	fmt.Printf("   • ga4admin metadata metrics --property %s\n", propertyID)
+	fmt.Printf("   • ga4admin metadata events --property %s\n", propertyID)
+}
+
+func metadataExportCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	format = strings.ToLower(format)
+	if format != "xlsx" && format != "md" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: xlsx, md\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if outputFile == "" {
+		outputFile = fmt.Sprintf("data-dictionary-%s.%s", propertyID, format)
+	}
+
+	fmt.Printf("📖 Building data dictionary for property %s...\n", propertyID)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	ensurePropertyAccess(activePreset, propertyID)
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	metadata, err := dataClient.GetMetadata(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// The Data API only describes custom dimensions generically; their
+	// display names and descriptions come from the Admin API
+	var customDimensions []api.CustomDimension
+	adminClient, err := api.NewAdminClient()
+	if err == nil {
+		customDimensions, err = adminClient.ListCustomDimensions(ctx, propertyID)
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Custom dimension details unavailable, using metadata descriptions: %v\n", err)
+	}
+
+	dict := dictionary.Build(propertyID, metadata, customDimensions)
+	if format == "md" {
+		err = dictionary.WriteMarkdown(dict, outputFile)
+	} else {
+		err = dictionary.WriteXLSX(dict, outputFile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ %d dimensions, %d metrics (%d custom definitions)\n", len(dict.Dimensions), len(dict.Metrics), len(dict.Custom()))
+	fmt.Printf("📁 File: %s\n", outputFile)
+}
+
+func metadataDimensionsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	customOnly, _ := cmd.Flags().GetBool("custom-only")
+	category, _ := cmd.Flags().GetString("category")
+
+	fmt.Printf("📏 Discovering dimensions for property %s...\n", propertyID)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	// Create Data API client with cache
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	// Get metadata
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	metadata, err := dataClient.GetMetadata(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Filter and display dimensions
+	filteredDimensions := make([]api.DimensionMetadata, 0)
+	for _, dim := range metadata.Dimensions {
+		// Apply filters
+		if customOnly && !dim.CustomDefinition {
+			continue
+		}
+		if category != "" && dim.Category != category {
+			continue
+		}
+		filteredDimensions = append(filteredDimensions, dim)
+	}
+
+	if len(filteredDimensions) == 0 {
+		fmt.Println("❌ No dimensions found matching your criteria")
+		return
+	}
+
+	// Display results
+	fmt.Printf("📊 Found %d dimension(s):\n\n", len(filteredDimensions))
+	
+	// Group by category
+	categories := make(map[string][]api.DimensionMetadata)
+	for _, dim := range filteredDimensions {
+		cat := dim.Category
+		if cat == "" {
+			cat = "Other"
+		}
+		categories[cat] = append(categories[cat], dim)
+	}
+
+	for category, dims := range categories {
+		fmt.Printf("🏷️  %s (%d)\n", category, len(dims))
+		for _, dim := range dims {
+			customIndicator := ""
+			if dim.CustomDefinition {
+				customIndicator = " 🔧"
+			}
+			
+			fmt.Printf("   • %s%s\n", dim.APIName, customIndicator)
+			fmt.Printf("     UI Name: %s\n", dim.UIName)
+			if dim.Description != "" {
+				fmt.Printf("     %s\n", dim.Description)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("💡 Total: %d dimensions (%d custom)\n", 
+		len(metadata.Dimensions), countCustom(metadata.Dimensions))
+	fmt.Printf("💡 Use 'ga4admin metadata metrics --property %s' to see available metrics\n", propertyID)
+}
+
+func metadataWarmCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	force, _ := cmd.Flags().GetBool("force")
+
+	fmt.Printf("🔥 Warming metadata cache for account %s...\n", accountID)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// List the account's properties
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	listCtx, listCancel := commandContext(30*time.Second)
+	properties, err := adminClient.ListProperties(listCtx, accountID)
+	listCancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list properties: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		return
+	}
+
+	propertyIDs := make([]string, len(properties))
+	propertyNames := make(map[string]string, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID
+		propertyNames[property.ID] = property.DisplayName
+	}
+
+	// Create Data API client with cache
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	fmt.Printf("🏠 Found %d propert(y/ies), fetching with concurrency %d\n\n", len(propertyIDs), concurrency)
+
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	done := 0
+	warmResults := dataClient.WarmMetadata(ctx, propertyIDs, concurrency, force, func(result api.MetadataWarmResult) {
+		done++
+		prefix := fmt.Sprintf("[%d/%d]", done, len(propertyIDs))
+		name := propertyNames[result.PropertyID]
+		switch {
+		case result.Err != nil:
+			fmt.Printf("%s ❌ %s (ID: %s): %v\n", prefix, name, result.PropertyID, result.Err)
+		case result.FromCache:
+			fmt.Printf("%s ⚡ %s (ID: %s): already cached\n", prefix, name, result.PropertyID)
+		default:
+			fmt.Printf("%s ✅ %s (ID: %s): %d dimensions, %d metrics in %s\n",
+				prefix, name, result.PropertyID, result.Dimensions, result.Metrics, result.Duration.Round(time.Millisecond))
+		}
+	})
+
+	// Summarize
+	fetched, cached, failed := 0, 0, 0
+	for _, result := range warmResults {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.FromCache:
+			cached++
+		default:
+			fetched++
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 Warmed %d propert(y/ies): %d fetched, %d already cached, %d failed\n", len(warmResults), fetched, cached, failed)
+	if failed > 0 {
+		os.Exit(exitcode.Failure)
+	}
+}
+
+func metadataMetricsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	customOnly, _ := cmd.Flags().GetBool("custom-only")
+	category, _ := cmd.Flags().GetString("category")
+	metricType, _ := cmd.Flags().GetString("type")
+
+	fmt.Printf("📈 Discovering metrics for property %s...\n", propertyID)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	// Create Data API client with cache
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	// Get metadata
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	metadata, err := dataClient.GetMetadata(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Filter and display metrics
+	filteredMetrics := make([]api.MetricMetadata, 0)
+	for _, metric := range metadata.Metrics {
+		// Apply filters
+		if customOnly && !metric.CustomDefinition {
+			continue
+		}
+		if category != "" && metric.Category != category {
+			continue
+		}
+		if metricType != "" && metric.Type != metricType {
+			continue
+		}
+		filteredMetrics = append(filteredMetrics, metric)
+	}
+
+	if len(filteredMetrics) == 0 {
+		fmt.Println("❌ No metrics found matching your criteria")
+		return
+	}
+
+	// Display results
+	fmt.Printf("📊 Found %d metric(s):\n\n", len(filteredMetrics))
+	
+	// Group by category
+	categories := make(map[string][]api.MetricMetadata)
+	for _, metric := range filteredMetrics {
+		cat := metric.Category
+		if cat == "" {
+			cat = "Other"
+		}
+		categories[cat] = append(categories[cat], metric)
+	}
+
+	for category, metrics := range categories {
+		fmt.Printf("🏷️  %s (%d)\n", category, len(metrics))
+		for _, metric := range metrics {
+			customIndicator := ""
+			if metric.CustomDefinition {
+				customIndicator = " 🔧"
+			}
+			
+			typeIndicator := ""
+			if metric.Type != "" {
+				typeIndicator = fmt.Sprintf(" [%s]", metric.Type)
+			}
+			
+			fmt.Printf("   • %s%s%s\n", metric.APIName, typeIndicator, customIndicator)
+			fmt.Printf("     UI Name: %s\n", metric.UIName)
+			if metric.Description != "" {
+				fmt.Printf("     %s\n", metric.Description)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("💡 Total: %d metrics (%d custom)\n", 
+		len(metadata.Metrics), countCustomMetrics(metadata.Metrics))
+	fmt.Printf("💡 Use 'ga4admin metadata events --property %s' to analyze event volumes\n", propertyID)
+}
+
+func metadataEventsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	days, _ := cmd.Flags().GetInt("days")
+	limit, _ := cmd.Flags().GetInt("limit")
+	orderBy, _ := cmd.Flags().GetString("order-by")
+	breakdownBy, _ := cmd.Flags().GetString("by")
+	showTrend, _ := cmd.Flags().GetBool("trend")
+
+	if limit <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --limit must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+	if orderBy != "eventCount" && orderBy != "activeUsers" {
+		fmt.Fprintf(os.Stderr, "Error: --order-by must be eventCount or activeUsers\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("📅 Analyzing events for property %s (%d days)...\n", propertyID, days)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	// Create Data API client with cache
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	// Analyze events
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
+
+	options := api.EventAnalysisOptions{
+		Limit:              limit,
+		OrderBy:            orderBy,
+		BreakdownDimension: resolveBreakdownDimension(breakdownBy),
+		Trend:              showTrend,
+	}
+
+	analysis, err := dataClient.AnalyzeEventsWithOptions(ctx, propertyID, days, options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to analyze events: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display results
+	if analysis.TotalEvents == 0 {
+		fmt.Printf("❌ No events found in the last %d days\n", days)
+		fmt.Println("💡 This might indicate no data collection or a very new property")
+		return
+	}
+
+	fmt.Printf("📊 Event Analysis Results:\n\n")
+	fmt.Printf("📈 Total Events: %d unique event types\n", analysis.TotalEvents)
+	fmt.Printf("🔢 Total Event Count: %s\n", formatNumber(analysis.TotalEventCount))
+	fmt.Printf("👥 Total Active Users: %s\n", formatNumber(analysis.TotalActiveUsers))
+	fmt.Printf("🎯 Events per User: %.1f\n", float64(analysis.TotalEventCount)/float64(analysis.TotalActiveUsers))
+	fmt.Println()
+
+	byLabel := "events"
+	if analysis.OrderBy == "activeUsers" {
+		byLabel = "users"
+	}
+	fmt.Printf("🔥 Top %d Events by %s:\n\n", len(analysis.Events), byLabel)
+	for i, event := range analysis.Events {
+		rank := i + 1
+		percentage := (float64(event.EventCount) / float64(analysis.TotalEventCount)) * 100
+		
+		fmt.Printf("%2d. %s\n", rank, event.EventName)
+		fmt.Printf("    📊 %s events (%.1f%% of total)\n", formatNumber(event.EventCount), percentage)
+		fmt.Printf("    👥 %s users (%.1f events/user)\n", formatNumber(event.ActiveUsers), event.EventsPerUser)
+
+		if showTrend && len(event.DailyCounts) > 0 {
+			fmt.Printf("    📈 %s\n", chart.Sparkline(countSeries(event.DailyCounts)))
+		}
+
+		if len(event.Breakdown) > 0 {
+			fmt.Printf("    🔍 By %s:\n", analysis.BreakdownDimension)
+			for j, part := range event.Breakdown {
+				if j == 5 {
+					fmt.Printf("       … %d more\n", len(event.Breakdown)-j)
+					break
+				}
+				share := (float64(part.EventCount) / float64(event.EventCount)) * 100
+				value := part.Value
+				if value == "" {
+					value = "(not set)"
+				}
+				fmt.Printf("       • %-20s %s (%.1f%%)\n", value, formatNumber(part.EventCount), share)
+			}
+		}
+		
+		// Identify potential conversion events
+		if audit.IsLikelyConversionEvent(event.EventName) {
+			fmt.Printf("    🎯 Likely conversion event\n")
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("💡 Analyzed %d days of data (updated %s)\n", days, analysis.AnalyzedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("💡 Use 'ga4admin metadata dimensions --property %s' to see available dimensions\n", propertyID)
+}
+
+// Helper functions
+func resolveBreakdownDimension(by string) string {
+	switch strings.ToLower(strings.TrimSpace(by)) {
+	case "":
+		return ""
+	case "device":
+		return "deviceCategory"
+	case "platform", "country", "city", "region", "browser":
+		return strings.ToLower(strings.TrimSpace(by))
+	default:
+		return strings.TrimSpace(by)
+	}
+}
+
+// countSeries converts daily or per-minute counts for chart.Sparkline
+func countSeries(counts []int64) []float64 {
+	values := make([]float64, len(counts))
+	for i, count := range counts {
+		values[i] = float64(count)
+	}
+	return values
+}
+
+func countCustom(dimensions []api.DimensionMetadata) int {
+	count := 0
+	for _, dim := range dimensions {
+		if dim.CustomDefinition {
+			count++
+		}
+	}
+	return count
+}
+
+func countCustomMetrics(metrics []api.MetricMetadata) int {
+	count := 0
+	for _, metric := range metrics {
+		if metric.CustomDefinition {
+			count++
+		}
+	}
+	return count
+}
+
+func formatNumber(n int64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	} else if n < 1000000 {
+		return fmt.Sprintf("%.1fK", float64(n)/1000)
+	} else if n < 1000000000 {
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+	return fmt.Sprintf("%.1fB", float64(n)/1000000000)
+}
+
+func reportListCmd(cmd *cobra.Command, args []string) {
+	fmt.Println("📑 Built-in Reports:")
+	fmt.Println()
+
+	category := ""
+	for _, template := range report.List() {
+		if template.Category != category {
+			category = template.Category
+			fmt.Printf("🏷️  %s\n", category)
+		}
+		fmt.Printf("   • %-20s %s\n", template.Name, template.Description)
+	}
+
+	fmt.Println()
+	fmt.Println("💡 Run one with 'ga4admin report run <report> --property <id>'")
+}
+
+func reportRunCmd(cmd *cobra.Command, args []string) {
+	reportName := args[0]
+	propertyID, _ := cmd.Flags().GetString("property")
+	startDate, _ := cmd.Flags().GetString("start-date")
+	endDate, _ := cmd.Flags().GetString("end-date")
+	limit, _ := cmd.Flags().GetInt64("limit")
+
+	template, err := report.Get(reportName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📑 Running '%s' report for property %s...\n", template.Name, propertyID)
+	fmt.Printf("   %s\n", template.Description)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	overrides := map[string]interface{}{
+		"property_id": propertyID,
+		"start_date":  startDate,
+		"end_date":    endDate,
+	}
+	if limit > 0 {
+		overrides["limit"] = limit
+	}
+
+	executor := newQueryExecutor(dataClient)
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
+
+	result, err := executor.ExecuteTemplate(ctx, template, overrides)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Report failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Report completed: %d rows in %s\n", result.RowCount, result.ExecutionTime)
+	fmt.Println()
+
+	printQueryResult(result)
+
+	fmt.Println()
+	fmt.Printf("💡 Query ID: %s\n", result.QueryID)
+	fmt.Printf("💡 Use 'ga4admin results export %s output.csv' to export data\n", result.QueryID)
+}
+
+func analyzeConversionsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	days, _ := cmd.Flags().GetInt("days")
+	minShare, _ := cmd.Flags().GetFloat64("min-share")
+
+	fmt.Printf("🎯 Analyzing key event coverage for property %s (%d days)...\n", propertyID, days)
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
+
+	coverage, err := audit.AnalyzeConversionCoverage(ctx, adminClient, dataClient, propertyID, days, minShare)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to analyze conversions: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📊 %d key event(s) configured, %s events in range\n\n", len(coverage.KeyEvents), formatNumber(coverage.TotalEventCount))
+
+	if len(coverage.KeyEvents) > 0 {
+		fmt.Println("🎯 Key Events:")
+		for _, event := range coverage.KeyEvents {
+			fmt.Printf("   • %-35s %12s (%.2f%%)\n", event.EventName, formatNumber(event.EventCount), event.Share)
+		}
+		fmt.Println()
+	}
+
+	if len(coverage.ZeroVolume) > 0 {
+		fmt.Printf("⚠️  Key events with zero volume (%d):\n", len(coverage.ZeroVolume))
+		for _, event := range coverage.ZeroVolume {
+			fmt.Printf("   • %s\n", event.EventName)
+		}
+		fmt.Println("💡 These may be misspelled, no longer sent, or tracked under a different name")
+		fmt.Println()
+	} else if len(coverage.KeyEvents) > 0 {
+		fmt.Println("✅ Every key event received data")
+		fmt.Println()
+	}
+
+	if len(coverage.UnmarkedHighVolume) > 0 {
+		fmt.Printf("🔎 Events that may be missing key event status (%d):\n", len(coverage.UnmarkedHighVolume))
+		for _, event := range coverage.UnmarkedHighVolume {
+			hint := ""
+			if audit.IsLikelyConversionEvent(event.EventName) {
+				hint = " 🎯 looks like a conversion"
+			}
+			fmt.Printf("   • %-35s %12s (%.2f%%)%s\n", event.EventName, formatNumber(event.EventCount), event.Share, hint)
+		}
+		fmt.Println("💡 Mark these as key events in GA4 Admin if they represent business outcomes")
+	} else {
+		fmt.Println("✅ No unmarked conversion candidates found")
+	}
+}
+
+func analyzeUTMCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	days, _ := cmd.Flags().GetInt("days")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	format = strings.ToLower(format)
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: table, csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if format == "table" && outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Error: --output needs --format csv or json\n")
+		os.Exit(exitcode.Validation)
+	}
+	if format != "table" && outputFile == "" {
+		outputFile = fmt.Sprintf("utm_cleanup_%s.%s", propertyID, format)
+	}
+
+	fmt.Printf("🏷️  Checking UTM tagging for property %s (%d days)...\n", propertyID, days)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(5*time.Minute)
+	defer cancel()
+
+	report, err := audit.AnalyzeUTM(ctx, dataClient, propertyID, days)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to analyze UTM tagging: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📊 %s sessions across %s source/medium/campaign combinations\n", formatNumber(report.TotalSessions), formatNumber(int64(report.Combinations)))
+	if report.Truncated {
+		fmt.Println("⚠️  Only the first combinations were read; shorten --days for a complete check")
+	}
+
+	if format != "table" {
+		if format == "json" {
+			err = audit.WriteUTMReportJSON(report, outputFile)
+		} else {
+			err = audit.WriteUTMWorksheetCSV(report, outputFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ %d issue(s), %.1f%% of sessions untagged\n", len(report.Issues), report.UntaggedShare())
+		fmt.Printf("📁 File: %s\n", outputFile)
+		return
+	}
+
+	fmt.Printf("\n🔗 Untagged traffic: %s sessions (%.1f%%) have no campaign\n", formatNumber(report.UntaggedSessions), report.UntaggedShare())
+	for i, value := range report.Untagged {
+		if i == 5 {
+			break
+		}
+		fmt.Printf("   • %-45s %12s\n", results.TruncateToWidth(value.Value, 45), formatNumber(value.Sessions))
+	}
+	if report.UntaggedSessions > 0 {
+		fmt.Println("💡 Direct and organic traffic is always untagged; look for paid, email or social sources here")
+	}
+	fmt.Println()
+
+	if len(report.Issues) == 0 {
+		fmt.Println("✅ No inconsistent source, medium or campaign values found")
+		return
+	}
+
+	fmt.Printf("⚠️  %d inconsistent value group(s):\n", len(report.Issues))
+	for i, issue := range report.Issues {
+		if limit > 0 && i == limit {
+			fmt.Printf("\n   ... and %d more\n", len(report.Issues)-limit)
+			break
+		}
+		fmt.Printf("\n   %s → %q (%s, %s sessions)\n", issue.Field, issue.Suggested, strings.Join(issue.Kinds, ", "), formatNumber(issue.Sessions))
+		for _, variant := range issue.Variants {
+			fmt.Printf("      %-45s %12s\n", results.TruncateToWidth(fmt.Sprintf("%q", variant.Value), 45), formatNumber(variant.Sessions))
+		}
+	}
+	fmt.Printf("\n💡 Write a cleanup worksheet with 'ga4admin analyze utm --property %s --format csv'\n", propertyID)
+}
+
+func analyzeReferralSpamCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+	opts := audit.SpamOptions{}
+	opts.Days, _ = cmd.Flags().GetInt("days")
+	opts.MinSessions, _ = cmd.Flags().GetInt64("min-sessions")
+	opts.MaxEngagement, _ = cmd.Flags().GetFloat64("max-engagement")
+	opts.Hostnames, _ = cmd.Flags().GetStringSlice("hostname")
+	opts.Blocklists, _ = cmd.Flags().GetStringSlice("blocklist")
+
+	format = strings.ToLower(format)
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: table, csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if format == "table" && outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Error: --output needs --format csv or json\n")
+		os.Exit(exitcode.Validation)
+	}
+	if format != "table" && outputFile == "" {
+		outputFile = fmt.Sprintf("referral_spam_%s.%s", propertyID, format)
+	}
+
+	fmt.Printf("🕷️  Checking property %s for referral spam (%d days)...\n", propertyID, opts.Days)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(3*time.Minute)
+	defer cancel()
+
+	report, err := audit.AnalyzeReferralSpam(ctx, adminClient, dataClient, propertyID, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to analyze referral spam: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📊 %s sessions checked\n", formatNumber(report.TotalSessions))
+	if len(report.Hostnames) > 0 {
+		fmt.Printf("🏠 Your hostnames: %s\n", strings.Join(report.Hostnames, ", "))
+	}
+
+	if format != "table" {
+		if format == "json" {
+			err = audit.WriteSpamJSON(report, outputFile)
+		} else {
+			err = audit.WriteSpamCSV(report, outputFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ %d suspicious referrer(s), %d foreign hostname(s)\n", len(report.Referrers), len(report.ForeignHosts))
+		fmt.Printf("📁 File: %s\n", outputFile)
+		return
+	}
+	fmt.Println()
+
+	if len(report.Referrers) == 0 {
+		fmt.Println("✅ No suspicious referrers found")
+	} else {
+		fmt.Printf("🚫 Suspicious referrers (%d, %.1f%% of sessions):\n", len(report.Referrers), report.SpamShare())
+		printSpamCandidates(report.Referrers)
+	}
+	fmt.Println()
+
+	switch {
+	case len(report.Hostnames) == 0:
+		fmt.Println("⚠️  Hostname check skipped: the property has no web stream URL")
+		fmt.Println("💡 Name your sites with --hostname, e.g. --hostname example.com")
+	case len(report.ForeignHosts) == 0:
+		fmt.Println("✅ All traffic hit your hostnames")
+	default:
+		fmt.Printf("🌐 Hostnames outside your sites (%d):\n", len(report.ForeignHosts))
+		printSpamCandidates(report.ForeignHosts)
+		fmt.Println("💡 Add hostnames that are yours with --hostname")
+	}
+
+	if report.SourceFilter == "" && report.HostnameFilter == "" {
+		return
+	}
+	fmt.Println()
+	fmt.Println("🔧 Recommended filters:")
+	if report.SourceFilter != "" {
+		fmt.Printf("   Exclude the referrers from queries:  --filters '%s'\n", report.SourceFilter)
+	}
+	if report.HostnameFilter != "" {
+		fmt.Printf("   Keep only your hostnames:            --filters '%s'\n", report.HostnameFilter)
+	}
+	fmt.Println("   Use the same regular expressions as exclude/include filters in explorations and Looker Studio.")
+	if len(report.UnwantedDomains) > 0 {
+		fmt.Printf("   GA4 Admin > Data streams > Configure tag settings > List unwanted referrals: %s\n", strings.Join(report.UnwantedDomains, ", "))
+		fmt.Println("💡 Unwanted referrals stop these domains from getting credit for sessions; GA4 has no filter that drops spam hits")
+	}
+}
+
+// printSpamCandidates lists flagged referrers or hostnames with their signals
+func printSpamCandidates(candidates []audit.SpamCandidate) {
+	for _, candidate := range candidates {
+		fmt.Printf("   • %-40s %10s sessions  %5.1f%% engaged  %s\n", results.TruncateToWidth(candidate.Value, 40),
+			formatNumber(candidate.Sessions), candidate.EngagementRate, strings.Join(candidate.Signals, ", "))
+	}
+}
+
+func analyzeDimsMatrixCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	dbPath, _ := cmd.Flags().GetString("db")
+	issuesOnly, _ := cmd.Flags().GetBool("issues-only")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	format = strings.ToLower(format)
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: table, csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if format == "table" && outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Error: --output needs --format csv or json\n")
+		os.Exit(exitcode.Validation)
+	}
+	if format != "table" && outputFile == "" {
+		outputFile = fmt.Sprintf("dims_matrix_%s.%s", accountID, format)
+	}
+
+	fmt.Printf("🧮 Comparing custom dimensions across account %s...\n", accountID)
+
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	var matrix *export.DimensionMatrix
+	var err error
+	if dbPath != "" {
+		matrix, err = export.LoadDimensionMatrix(ctx, dbPath, accountID)
+	} else {
+		activePreset, presetErr := preset.GetActivePreset()
+		if presetErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", presetErr)
+			os.Exit(exitcode.For(presetErr))
+		}
+		if activePreset == nil {
+			fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+			os.Exit(exitcode.Auth)
+		}
+
+		adminClient, clientErr := api.NewAdminClient()
+		if clientErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", clientErr)
+			os.Exit(exitcode.For(clientErr))
+		}
+
+		dataClient, clientErr := createDataClientWithCache()
+		if clientErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", clientErr)
+			os.Exit(exitcode.For(clientErr))
+		}
+		defer dataClient.Close()
+
+		matrix, err = export.BuildDimensionMatrix(ctx, adminClient, dataClient, accountID, func(propertyID string, err error) {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping property %s: %v\n", propertyID, err)
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to build dimension matrix: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(matrix.Properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		return
+	}
+	inconsistent := matrix.Inconsistent()
+	if issuesOnly {
+		matrix.Dimensions = inconsistent
+	}
+
+	switch format {
+	case "csv", "json":
+		if format == "json" {
+			err = export.WriteDimensionMatrixJSON(matrix, outputFile)
+		} else {
+			err = export.WriteDimensionMatrixCSV(matrix, outputFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ %d custom dimension(s) across %d properties, %d with inconsistencies\n", len(matrix.Dimensions), len(matrix.Properties), len(inconsistent))
+		fmt.Printf("📁 File: %s\n", outputFile)
+		return
+	}
+
+	fmt.Printf("🏠 %d properties, %d custom dimension(s), %d with inconsistencies\n\n", len(matrix.Properties), len(matrix.Dimensions), len(inconsistent))
+	printDimensionMatrix(matrix)
+}
+
+func analyzeReadinessCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	requiredDims, _ := cmd.Flags().GetStringSlice("require-dims")
+	currency, _ := cmd.Flags().GetString("currency")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	format = strings.ToLower(format)
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: table, csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if format == "table" && outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Error: --output needs --format csv or json\n")
+		os.Exit(exitcode.Validation)
+	}
+	if format != "table" && outputFile == "" {
+		outputFile = fmt.Sprintf("clarisights_readiness_%s.%s", accountID, format)
+	}
+	currency = strings.ToUpper(currency)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	listCtx, listCancel := commandContext(30*time.Second)
+	properties, err := adminClient.ListProperties(listCtx, accountID)
+	listCancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list properties: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if len(properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		return
+	}
+
+	currencyNote := "expected"
+	if currency == "" {
+		currency = audit.MajorityCurrency(properties)
+		currencyNote = "most common"
+	}
+	checks := []string{fmt.Sprintf("custom channel group (%d)", audit.ChannelGroupPoints)}
+	if len(requiredDims) > 0 {
+		checks = append(checks, fmt.Sprintf("%d required dimension(s) (%d)", len(requiredDims), audit.DimensionsPoints))
+	}
+	checks = append(checks, fmt.Sprintf("currency %s, %s (%d)", currency, currencyNote, audit.CurrencyPoints))
+	fmt.Printf("🏁 Scoring %d propert(y/ies) in account %s for Clarisights readiness\n", len(properties), accountID)
+	fmt.Printf("   Checks: %s\n\n", strings.Join(checks, " · "))
+
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	done := 0
+	opts := audit.ReadinessOptions{RequiredDimensions: requiredDims, Currency: currency, Concurrency: concurrency}
+	ranked := audit.ScoreReadiness(ctx, adminClient, dataClient, properties, opts, func(r *audit.PropertyReadiness) {
+		done++
+		status := fmt.Sprintf("%d%%", r.Score)
+		if r.Incomplete() {
+			status += " ❌ some checks failed"
+		}
+		fmt.Printf("[%d/%d] %s (ID: %s): %s\n", done, len(properties), r.DisplayName, r.PropertyID, status)
+	})
+	fmt.Println()
+
+	incomplete := 0
+	for _, r := range ranked {
+		if r.Incomplete() {
+			incomplete++
+		}
+	}
+
+	if format != "table" {
+		if format == "json" {
+			err = audit.WriteReadinessJSON(ranked, outputFile)
+		} else {
+			err = audit.WriteReadinessCSV(ranked, outputFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Ranked %d propert(y/ies)\n", len(ranked))
+		fmt.Printf("📁 File: %s\n", outputFile)
+	} else {
+		printReadiness(ranked)
+	}
+
+	if incomplete > 0 {
+		fmt.Printf("\n⚠️  %d propert(y/ies) could not be fully checked and score 0 on the failed checks\n", incomplete)
+		os.Exit(exitcode.Failure)
+	}
+}
+
+// printReadiness prints the ranked readiness table and what each property is
+// missing
+func printReadiness(ranked []*audit.PropertyReadiness) {
+	mark := func(check *audit.ReadinessCheck) string {
+		switch {
+		case check == nil:
+			return "–"
+		case check.Error != "":
+			return "❌ error"
+		case check.Passed():
+			return "✅ " + check.Detail
+		default:
+			return "⚠️  " + check.Detail
+		}
+	}
+
+	fmt.Printf("%-5s %-6s %-40s %s\n", "Rank", "Score", "Property", "Channel group · Dimensions · Currency")
+	for _, r := range ranked {
+		name := results.TruncateToWidth(fmt.Sprintf("%s (%s)", r.DisplayName, r.PropertyID), 40)
+		fmt.Printf("%-5d %-6s %-40s %s · %s · %s\n", r.Rank, fmt.Sprintf("%d%%", r.Score), name,
+			mark(r.Check(audit.CheckChannelGroup)), mark(r.Check(audit.CheckDimensions)), mark(r.Check(audit.CheckCurrency)))
+	}
+
+	var gaps []string
+	for _, r := range ranked {
+		var missing []string
+		if check := r.Check(audit.CheckChannelGroup); check != nil && check.Error == "" && !check.Passed() {
+			missing = append(missing, "a custom channel group")
+		}
+		if len(r.MissingDimensions) > 0 {
+			missing = append(missing, strings.Join(r.MissingDimensions, ", "))
+		}
+		if check := r.Check(audit.CheckCurrency); check != nil && !check.Passed() {
+			missing = append(missing, "currency "+check.Detail)
+		}
+		for _, check := range r.Checks {
+			if check.Error != "" {
+				missing = append(missing, fmt.Sprintf("%s check failed: %s", check.Name, check.Error))
+			}
+		}
+		if len(missing) > 0 {
+			gaps = append(gaps, fmt.Sprintf("   • %s (%s): %s", r.DisplayName, r.PropertyID, strings.Join(missing, "; ")))
+		}
+	}
+
+	if len(gaps) == 0 {
+		fmt.Println("\n✅ Every property is ready for Clarisights")
+		return
+	}
+	fmt.Printf("\n🔧 To fix (%d):\n", len(gaps))
+	for _, gap := range gaps {
+		fmt.Println(gap)
+	}
+}
+
+// printDimensionMatrix prints the matrix with numbered property columns, a
+// legend of the numbers and the issues found
+func printDimensionMatrix(matrix *export.DimensionMatrix) {
+	fmt.Println("Properties:")
+	for i, property := range matrix.Properties {
+		fmt.Printf("   [%d] %s (ID: %s)\n", i+1, property.PropertyName, property.PropertyID)
+	}
+	fmt.Println()
+
+	if len(matrix.Dimensions) == 0 {
+		fmt.Println("✅ No custom dimensions to show")
+		return
+	}
+
+	const maxNameWidth = 40
+	nameWidth := len("API Name")
+	for _, row := range matrix.Dimensions {
+		if width := results.DisplayWidth(row.APIName); width > nameWidth {
+			nameWidth = width
+		}
+	}
+	if nameWidth > maxNameWidth {
+		nameWidth = maxNameWidth
+	}
+	columnWidth := len(strconv.Itoa(len(matrix.Properties))) + 1
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "%-*s", nameWidth, "API Name")
+	for i := range matrix.Properties {
+		fmt.Fprintf(&header, " %*d", columnWidth, i+1)
+	}
+	fmt.Println(header.String())
+
+	for _, row := range matrix.Dimensions {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%-*s", nameWidth, results.TruncateToWidth(row.APIName, nameWidth))
+		for _, property := range matrix.Properties {
+			mark := "·"
+			if _, ok := row.Names[property.PropertyID]; ok {
+				mark = "✓"
+			}
+			fmt.Fprintf(&line, " %*s", columnWidth, mark)
+		}
+		if len(row.Issues) > 0 {
+			line.WriteString("  ⚠️")
+		}
+		fmt.Println(line.String())
+	}
+
+	inconsistent := matrix.Inconsistent()
+	if len(inconsistent) == 0 {
+		fmt.Println("\n✅ Names and scopes are consistent across properties")
+		return
+	}
+
+	fmt.Printf("\n⚠️  Inconsistencies (%d):\n", len(inconsistent))
+	for _, row := range inconsistent {
+		fmt.Printf("   • %s\n", row.APIName)
+		for _, issue := range row.Issues {
+			fmt.Printf("     - %s\n", issue)
+		}
+	}
+	fmt.Println("💡 Align display names in GA4 Admin, and register each parameter once, with one scope and one spelling")
+}
+
+func testAuthCmdHandler(cmd *cobra.Command, args []string) {
+	fmt.Println("🔐 Testing OAuth2 Authentication...")
+	
+	// Create auth client
+	authClient, err := api.NewAuthClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create auth client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	
+	// Get active preset info
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	
+	fmt.Printf("📋 Active Preset: %s\n", activePreset.Name)
+	if activePreset.UserEmail != "" {
+		fmt.Printf("👤 User: %s\n", activePreset.UserEmail)
+	}
+	
+	// Test token refresh, not a token saved by an earlier command
+	fmt.Println("🔄 Testing token refresh...")
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+	
+	authClient.ClearTokenCache()
+	token, err := authClient.GetAccessToken(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Token refresh failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	
+	fmt.Printf("✅ Token refresh successful!\n")
+	fmt.Printf("🎯 Access Token: %s...%s\n", token.AccessToken[:20], token.AccessToken[len(token.AccessToken)-4:])
+	fmt.Printf("⏰ Expires: %s\n", token.Expiry.Format("2006-01-02 15:04:05"))
+	fmt.Printf("⏳ Valid for: %s\n", time.Until(token.Expiry).Round(time.Second))
+	
+	// Test HTTP client
+	fmt.Println("🌐 Testing authenticated HTTP client...")
+	httpClient, err := authClient.AuthenticatedHTTPClient(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create HTTP client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	
+	// Make a test request to GA4 Admin API accounts endpoint
+	resp, err := httpClient.Get("https://analyticsadmin.googleapis.com/v1alpha/accounts")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Test API call failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer resp.Body.Close()
+	
+	fmt.Printf("🚀 Test API call successful!\n")
+	fmt.Printf("📊 Status: %s\n", resp.Status)
+	
+	if resp.StatusCode == 200 {
+		fmt.Println("✨ OAuth2 authentication is working correctly!")
+		fmt.Println("🎉 Ready for GA4 API integration")
+	} else {
+		fmt.Printf("⚠️  Unexpected status code: %d\n", resp.StatusCode)
+		fmt.Println("💡 This might indicate permission issues")
+	}
+	
+	// Show token cache info
+	tokenInfo := authClient.GetTokenInfo()
+	fmt.Println("\n📈 Token Cache Info:")
+	for key, value := range tokenInfo {
+		fmt.Printf("  %s: %v\n", key, value)
+	}
+}
+
+// applyNetworkSettings combines configured network settings with the global
+// --timeout/--request-timeout/--trace/--record-fixtures flags before any command runs
+func applyNetworkSettings(cmd *cobra.Command, args []string) {
+	settings, err := config.GetNetworkSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load network settings: %v\n", err)
+	}
+
+	options := api.NetworkOptions{
+		ProxyURL:        settings.ProxyURL,
+		CABundle:        settings.CABundle,
+		MaxConnsPerHost: settings.MaxConnsPerHost,
+	}
+	if settings.RequestTimeout != "" {
+		options.RequestTimeout, _ = time.ParseDuration(settings.RequestTimeout)
+	}
+	if settings.CommandTimeout != "" {
+		commandTimeout, _ = time.ParseDuration(settings.CommandTimeout)
+	}
+
+	if timeout, _ := cmd.Flags().GetDuration("request-timeout"); timeout > 0 {
+		options.RequestTimeout = timeout
+	}
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		commandTimeout = timeout
+	}
+
+	api.SetNetworkOptions(options)
+
+	if fixtureDir, _ := cmd.Flags().GetString("record-fixtures"); fixtureDir != "" {
+		if api.ReplayEnabled() {
+			fmt.Fprintf(os.Stderr, "Error: --record-fixtures cannot be combined with %s\n", api.ReplayEnvVar)
+			os.Exit(exitcode.Validation)
+		}
+		if err := api.EnableFixtureRecording(fixtureDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+
+	if tracePath, _ := cmd.Flags().GetString("trace"); tracePath != "" {
+		if err := api.EnableTrace(tracePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+}
+
+// resolvePropertyFlag rewrites an alias passed to --property into its property
+// ID, so every command taking the flag accepts aliases
+func resolvePropertyFlag(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("property")
+	if flag == nil || !flag.Changed || preset.IsPropertyID(flag.Value.String()) {
+		return
+	}
+
+	propertyID, err := resolvePropertyReference(flag.Value.String())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --property: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	flag.Value.Set(propertyID)
+}
+
+// resolvePropertyReference turns a property ID or alias into a property ID
+// using the active preset's aliases
+func resolvePropertyReference(value string) (string, error) {
+	if value == "" || preset.IsPropertyID(value) {
+		return value, nil
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		return "", err
+	}
+	return preset.ResolveProperty(activePreset, value)
+}
+
+// heartbeatInterval is how often a long-running operation reports that it is
+// still working
+const heartbeatInterval = 30 * time.Second
+
+// heartbeatRunning keeps nested operations from printing duplicate heartbeats
+var heartbeatRunning atomic.Bool
+
+// commandContext returns a context bounded by the configured command timeout,
+// or by the command's own default when none is configured. While it is live,
+// a heartbeat line goes to stderr every heartbeatInterval.
+func commandContext(defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if commandTimeout > 0 {
+		timeout = commandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if heartbeatRunning.CompareAndSwap(false, true) {
+		go heartbeat(ctx, timeout)
+	}
+	return ctx, cancel
+}
+
+// heartbeat reports elapsed time and API activity until ctx ends, so a slow
+// GA4 response can be told apart from a hang
+func heartbeat(ctx context.Context, timeout time.Duration) {
+	defer heartbeatRunning.Store(false)
+
+	start := time.Now()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			elapsed := time.Since(start).Round(time.Second)
+			fmt.Fprintf(os.Stderr, "⏳ Still working after %s (times out in %s): %s\n",
+				elapsed, (timeout - elapsed).Round(time.Second), describeActivity(api.CurrentActivity()))
+		}
+	}
+}
+
+// describeActivity summarizes API calls for a heartbeat line
+func describeActivity(activity api.Activity) string {
+	summary := fmt.Sprintf("%d API call(s) done", activity.Completed)
+	switch {
+	case activity.InFlight == 0:
+		return summary + ", none in flight"
+	case activity.InFlight == 1:
+		return fmt.Sprintf("%s, waiting %s for %s", summary, activity.OldestWaiting.Round(time.Second), activity.OldestCall)
+	}
+	return fmt.Sprintf("%s, %d in flight, longest waiting %s for %s", summary, activity.InFlight, activity.OldestWaiting.Round(time.Second), activity.OldestCall)
+}
+
+// enableAuditLog records every write adminClient sends in the preset's audit
+// log. The returned function closes the log.
+func enableAuditLog(cmd *cobra.Command, adminClient *api.AdminClient, presetName string) func() {
+	cacheClient, err := cache.NewCacheClient(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to open audit log: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	userName := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		userName = current.Username
+	}
+	adminClient.SetAuditRecorder(cacheClient, api.AuditActor{
+		Preset:  presetName,
+		User:    userName,
+		Command: cmd.CommandPath(),
+	})
+	return func() { cacheClient.Close() }
+}
+
+// Helper function to create a cache-enabled data client
+func createDataClientWithCache() (*api.DataClient, error) {
+	// Get active preset name for cache
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active preset: %w", err)
+	}
+	if activePreset == nil {
+		return nil, fmt.Errorf("no active preset - run 'ga4admin preset use <name>' first")
+	}
+
+	return createPresetDataClient(activePreset.Name)
+}
+
+// createPresetDataClient creates a cache-enabled data client authenticated as
+// the named preset, using that preset's cache
+func createPresetDataClient(presetName string) (*api.DataClient, error) {
+	// Create cache client
+	cacheClient, err := cache.NewCacheClient(presetName)
+	if err != nil {
+		// Fall back to non-cached client if cache fails
+		fmt.Fprintf(os.Stderr, "Warning: Failed to create cache client, using non-cached mode: %v\n", err)
+		return api.NewDataClientForPreset(presetName, nil)
+	}
+
+	// Create data client with cache
+	dataClient, err := api.NewDataClientForPreset(presetName, cacheClient)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := config.GetCacheMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to read cache policy, using strict mode: %v\n", err)
+	} else if mode == config.CacheModeSWR {
+		dataClient.SetStaleWhileRevalidate(true)
+	}
+
+	return dataClient, nil
+}
+
+// Helper function to create a query executor that logs executions to the
+// preset cache for 'query stats'
+func newQueryExecutor(dataClient *api.DataClient) *query.Executor {
+	executor := query.NewExecutor(dataClient)
+	if recorder, ok := dataClient.CacheClient().(query.StatsRecorder); ok {
+		executor.SetStatsRecorder(recorder)
+	}
+	executor.SetPropertySource(access.NewPropertyLookup(dataClient.PresetName()))
+	return executor
+}
+
+// Helper function to verify the active preset can reach a property before querying it
+func ensurePropertyAccess(activePreset *config.Preset, propertyID string) {
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	if err := access.CheckPropertyAccess(ctx, activePreset, propertyID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+}
+
+// Helper function to verify the active preset's refresh token may change
+// configuration before a command plans or makes changes
+func ensureEditScope(activePreset *config.Preset) {
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	if err := api.RequireScope(ctx, activePreset, api.AnalyticsEditScope); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, api.ErrMissingScope) {
+			fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin preset reauth %s --edit' to grant it\n", activePreset.Name)
+		}
+		os.Exit(exitcode.For(err))
+	}
+}
+
+// Query command handlers
+
+// addQueryConfigFlags registers the flags that define a query, shared by
+// 'query run' and 'query plan'
+func addQueryConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().String("property", "", "Property ID to query (required unless set in --file)")
+	cmd.Flags().String("file", "", "Load query from a YAML or JSON file (flags override file values)")
+	cmd.Flags().StringSlice("dimensions", []string{}, "Dimension names (comma-separated)")
+	cmd.Flags().StringSlice("metrics", []string{}, "Metric names, or 'name=expression' for calculated metrics (comma-separated)")
+	cmd.Flags().String("start-date", "30daysAgo", "Start date (YYYY-MM-DD or relative)")
+	cmd.Flags().String("end-date", "yesterday", "End date (YYYY-MM-DD or relative)")
+	cmd.Flags().Int64("limit", 10000, "Maximum rows to return")
+	cmd.Flags().Int64("offset", 0, "Skip this many rows before the first one returned")
+	cmd.Flags().StringSlice("filters", []string{}, "Filters in format 'field:type:operation:value' (see Filters above)")
+	cmd.Flags().String("order-by", "", "Comma-separated fields to order by, each prefixed with - for descending, e.g. -sessions,country")
+	cmd.Flags().Bool("keep-empty-rows", false, "Return rows where all metrics are zero")
+	cmd.Flags().StringSlice("aggregations", []string{}, "Metric aggregations to return (total,minimum,maximum,count)")
+}
+
+// queryConfigFromFlags builds a query configuration from the query file and
+// the flags registered by addQueryConfigFlags. It also returns the row cap for
+// streamed exports, which fetch every row unless a limit is given explicitly.
+func queryConfigFromFlags(cmd *cobra.Command) (*query.QueryConfig, int64) {
+	queryFile, _ := cmd.Flags().GetString("file")
+	propertyID, _ := cmd.Flags().GetString("property")
+	dimensions, _ := cmd.Flags().GetStringSlice("dimensions")
+	metricStrings, _ := cmd.Flags().GetStringSlice("metrics")
+	startDate, _ := cmd.Flags().GetString("start-date")
+	endDate, _ := cmd.Flags().GetString("end-date")
+	limit, _ := cmd.Flags().GetInt64("limit")
+	offset, _ := cmd.Flags().GetInt64("offset")
+	filterStrings, _ := cmd.Flags().GetStringSlice("filters")
+	orderBy, _ := cmd.Flags().GetString("order-by")
+	keepEmptyRows, _ := cmd.Flags().GetBool("keep-empty-rows")
+	aggregationStrings, _ := cmd.Flags().GetStringSlice("aggregations")
+
+	var streamMaxRows int64
+
+	// Build query configuration, starting from the query file if given
+	config := &query.QueryConfig{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Limit:     limit,
+	}
+	if queryFile != "" {
+		fileConfig, err := query.LoadQueryFile(queryFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Validation)
+		}
+		config = fileConfig
+		if config.Limit > 0 {
+			// A limit in the file caps streamed exports too
+			streamMaxRows = config.Limit
+		}
+		if config.StartDate == "" {
+			config.StartDate = startDate
+		}
+		if config.EndDate == "" {
+			config.EndDate = endDate
+		}
+		if config.Limit == 0 {
+			config.Limit = limit
+		}
+	}
+
+	// Explicit flags override file values
+	flags := cmd.Flags()
+	if flags.Changed("property") {
+		config.PropertyID = propertyID
+	}
+	if flags.Changed("dimensions") {
+		config.Dimensions = dimensions
+	}
+	if flags.Changed("metrics") {
+		metrics, calculatedMetrics, err := parseMetrics(metricStrings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid metrics: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Example: --metrics sessions,eventsPerSession=eventCount/sessions\n")
+			os.Exit(exitcode.Validation)
+		}
+		config.Metrics = metrics
+		config.CalculatedMetrics = calculatedMetrics
+	}
+	if flags.Changed("start-date") {
+		config.StartDate = startDate
+	}
+	if flags.Changed("end-date") {
+		config.EndDate = endDate
+	}
+	if flags.Changed("limit") {
+		config.Limit = limit
+		streamMaxRows = limit
+	}
+	if flags.Changed("offset") {
+		if offset < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --offset must not be negative\n")
+			os.Exit(exitcode.Validation)
+		}
+		config.Offset = offset
+	}
+	if flags.Changed("keep-empty-rows") {
+		config.KeepEmptyRows = keepEmptyRows
+	}
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = time.Now()
+
+	if config.PropertyID == "" {
+		fmt.Fprintf(os.Stderr, "Error: --property is required (or set property_id in the query file)\n")
+		os.Exit(exitcode.Validation)
+	}
+	// Query files may name the property by alias too
+	resolvedID, err := resolvePropertyReference(config.PropertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	config.PropertyID = resolvedID
+
+	// Validate basic requirements
+	if len(config.Dimensions) == 0 && len(config.MetricNames()) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: At least one dimension or metric is required\n")
+		fmt.Fprintf(os.Stderr, "Example: --dimensions sessionSource,sessionMedium --metrics activeUsers,sessions\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	// Parse metric aggregations if provided
+	if len(aggregationStrings) > 0 {
+		aggregations, err := parseAggregations(aggregationStrings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid aggregations: %v\n", err)
+			os.Exit(exitcode.Validation)
+		}
+		config.MetricAggregations = aggregations
+	}
+
+	// Parse filters if provided
+	if len(filterStrings) > 0 {
+		filters, err := parseFilters(filterStrings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid filter format: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Filter format: field:type:operation:value\n")
+			fmt.Fprintf(os.Stderr, "Examples: sessionSource:string:EXACT:google, sessions:between:10:100, country:in_list:CI:US|CA|GB\n")
+			os.Exit(exitcode.Validation)
+		}
+		config.Filters = filters
+	}
+
+	// Parse order by if provided
+	if orderBy != "" {
+		orderConfigs, err := parseOrderBys(orderBy, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid order-by format: %v\n", err)
+			os.Exit(exitcode.Validation)
+		}
+		config.OrderBy = orderConfigs
+	}
+
+	return config, streamMaxRows
+}
+
+func queryRunCmd(cmd *cobra.Command, args []string) {
+	queryName, _ := cmd.Flags().GetString("name")
+	exportStream, _ := cmd.Flags().GetString("export-stream")
+	pageSize, _ := cmd.Flags().GetInt64("page-size")
+	chunkBy, _ := cmd.Flags().GetString("chunk-by")
+	top, _ := cmd.Flags().GetInt("top")
+	bucketOther, _ := cmd.Flags().GetBool("bucket-other")
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	ifNotExists, _ := cmd.Flags().GetBool("if-not-exists")
+	// noCache, _ := cmd.Flags().GetBool("no-cache") // TODO: Implement cache skipping
+
+	config, streamMaxRows := queryConfigFromFlags(cmd)
+	if cmd.Flags().Changed("name") {
+		config.Name = queryName
+	}
+
+	if chunkBy != "" {
+		if chunkBy != query.ChunkByMonth && chunkBy != query.ChunkByWeek {
+			fmt.Fprintf(os.Stderr, "Error: --chunk-by must be '%s' or '%s'\n", query.ChunkByMonth, query.ChunkByWeek)
+			os.Exit(exitcode.Validation)
+		}
+		if exportStream != "" {
+			fmt.Fprintf(os.Stderr, "Error: --chunk-by cannot be combined with --export-stream\n")
+			os.Exit(exitcode.Validation)
+		}
+		// Every chunk would skip the offset's rows
+		if config.Offset > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --chunk-by cannot be combined with an offset\n")
+			os.Exit(exitcode.Validation)
+		}
+	}
+
+	if top < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --top must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+	if bucketOther && top == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --bucket-other needs --top\n")
+		os.Exit(exitcode.Validation)
+	}
+	if top > 0 && (exportStream != "" || chunkBy != "") {
+		fmt.Fprintf(os.Stderr, "Error: --top cannot be combined with --export-stream or --chunk-by\n")
+		os.Exit(exitcode.Validation)
+	}
+	if maxAge < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-age must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+	onConflict := cache.NameConflictFail
+	if overwrite {
+		onConflict = cache.NameConflictOverwrite
+	} else if ifNotExists {
+		onConflict = cache.NameConflictKeep
+	}
+	if queryName == "" && (overwrite || ifNotExists) {
+		fmt.Fprintf(os.Stderr, "Error: --overwrite and --if-not-exists need --name\n")
+		os.Exit(exitcode.Validation)
+	}
+	if queryName != "" && exportStream != "" {
+		fmt.Fprintf(os.Stderr, "Error: --name cannot be combined with --export-stream, whose rows aren't cached\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("🚀 Executing GA4 query for property %s...\n", config.PropertyID)
+
+	// Verify the active preset can reach the property before running the query
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	ensurePropertyAccess(activePreset, config.PropertyID)
+
+	// Create data client; streamed pages bypass the cache so memory stays bounded
+	var dataClient *api.DataClient
+	if exportStream != "" {
+		dataClient, err = api.NewDataClient()
+	} else {
+		dataClient, err = createDataClientWithCache()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+	dataClient.SetMaxCacheAge(maxAge)
+
+	// Catch misspelled fields locally with suggestions; if metadata can't be
+	// loaded, GA4 still validates the query
+	validateQueryFields(dataClient, config)
+
+	// Execute query
+	executor := newQueryExecutor(dataClient)
+
+	if exportStream != "" {
+		runQueryStream(executor, config, exportStream, pageSize, streamMaxRows)
+		return
+	}
+	if chunkBy != "" {
+		result := runQueryChunked(executor, dataClient, activePreset, config, chunkBy)
+		finishQueryRun(dataClient, result, queryName, config.Description, onConflict)
+		return
+	}
+
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
+
+	result, err := executor.Execute(ctx, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.IsInvalidArgument() && len(config.CalculatedMetrics) > 0 {
+			fmt.Fprintf(os.Stderr, "💡 GA4 rejected the request - check the calculated metric expressions:\n")
+			for _, calculated := range config.CalculatedMetrics {
+				fmt.Fprintf(os.Stderr, "   %s = %s\n", calculated.Name, calculated.Expression)
+			}
+		}
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display results
+	fmt.Printf("✅ Query completed successfully!\n")
+	fmt.Printf("📊 Returned %d rows in %s\n", result.RowCount, result.ExecutionTime)
+	if result.FromCache {
+		fmt.Printf("⚡ Results served from cache%s\n", cacheAgeNote(result))
+	}
+
+	if top > 0 {
+		result = applyTopN(ctx, executor, dataClient, result, top, bucketOther)
+	}
+	fmt.Println()
+
+	printQueryResult(result)
+	finishQueryRun(dataClient, result, queryName, config.Description, onConflict)
+}
+
+// finishQueryRun saves the result under its --name, if given, and prints
+// where to find it
+func finishQueryRun(dataClient *api.DataClient, result *query.QueryResult, name, description string, onConflict cache.NameConflict) {
+	fmt.Println()
+	if name != "" {
+		nameQueryResult(dataClient, result, name, description, onConflict)
+	}
+	fmt.Printf("💡 Query ID: %s\n", result.QueryID)
+	fmt.Printf("💡 Use 'ga4admin results show %s' to see full results\n", result.QueryID)
+	fmt.Printf("💡 Use 'ga4admin results export %s output.csv' to export data\n", result.QueryID)
+}
+
+// nameQueryResult points a named table at the result. A name that already
+// refers to another result fails unless onConflict says otherwise.
+func nameQueryResult(dataClient *api.DataClient, result *query.QueryResult, name, description string, onConflict cache.NameConflict) {
+	cacheClient, ok := dataClient.CacheClient().(*cache.CacheClient)
+	if !ok || cacheClient == nil {
+		fmt.Printf("⚠️  Result not saved as '%s': the cache is unavailable\n", name)
+		return
+	}
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	previous, err := cacheClient.CreateNamedTable(ctx, name, result.PropertyID, result.QueryID, description, onConflict)
+	switch {
+	case errors.Is(err, cache.ErrNamedTableExists):
+		fmt.Fprintf(os.Stderr, "Error: Named table '%s' already refers to result %s\n", name, previous)
+		fmt.Fprintf(os.Stderr, "💡 This result is cached as %s. Rerun with --overwrite to point '%s' at it, or --if-not-exists to keep the existing table\n", result.QueryID, name)
+		os.Exit(exitcode.For(err))
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: Failed to save named table '%s': %v\n", name, err)
+		os.Exit(exitcode.For(err))
+	case previous == "":
+		fmt.Printf("🏷️  Saved as named table '%s'\n", name)
+	case previous == result.QueryID:
+		fmt.Printf("🏷️  Named table '%s' already refers to this result\n", name)
+	case onConflict == cache.NameConflictKeep:
+		fmt.Printf("🏷️  Named table '%s' left on result %s (--if-not-exists)\n", name, previous)
+	default:
+		fmt.Printf("🏷️  Named table '%s' now refers to this result (was %s)\n", name, previous)
+	}
+}
+
+func queryValidateCmd(cmd *cobra.Command, args []string) {
+	files, _ := cmd.Flags().GetStringSlice("file")
+	propertyID, _ := cmd.Flags().GetString("property")
+	files = append(files, args...)
+
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: No query files given - use --file <path> or pass files as arguments\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	executor := newQueryExecutor(dataClient)
+
+	ctx, cancel := commandContext(time.Duration(len(files)) * 30 * time.Second)
+	defer cancel()
+
+	failed := 0
+	for i, file := range files {
+		if i > 0 {
+			fmt.Println()
+		}
+		if !validateQueryFile(ctx, executor, file, propertyID) {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("❌ %d of %d query file(s) failed validation\n", failed, len(files))
+		os.Exit(exitcode.Validation)
+	}
+	fmt.Printf("✅ %d query file(s) valid\n", len(files))
+}
+
+// validateQueryFile prints each validation stage for one query file and
+// reports whether all of them passed
+func validateQueryFile(ctx context.Context, executor *query.Executor, file, propertyID string) bool {
+	schemaErrors, err := query.ValidateQueryFileSchema(file)
+	if err == nil && len(schemaErrors) > 0 {
+		fmt.Printf("🔍 %s\n", file)
+		fmt.Printf("   ❌ schema: %d problem(s)\n", len(schemaErrors))
+		for _, schemaErr := range schemaErrors {
+			fmt.Printf("      %s\n", schemaErr)
+		}
+		return false
+	}
+
+	config, err := query.LoadQueryFileStrict(file)
+	if err != nil {
+		fmt.Printf("🔍 %s\n", file)
+		// YAML lists each bad key on its own line
+		fmt.Printf("   ❌ schema: %s\n", strings.ReplaceAll(err.Error(), "\n", "\n      "))
+		return false
+	}
+
+	if propertyID != "" {
+		config.PropertyID = propertyID
+	}
+	if config.PropertyID == "" {
+		fmt.Printf("🔍 %s\n", file)
+		fmt.Println("   ✅ schema")
+		fmt.Println("   ❌ query: no property - set property_id in the file or pass --property")
+		return false
+	}
+	resolvedID, err := resolvePropertyReference(config.PropertyID)
+	if err != nil {
+		fmt.Printf("🔍 %s\n", file)
+		fmt.Println("   ✅ schema")
+		fmt.Printf("   ❌ query: %v\n", err)
+		return false
+	}
+	config.PropertyID = resolvedID
+
+	// Match the defaults 'query run' applies to files without dates
+	if config.StartDate == "" {
+		config.StartDate = "30daysAgo"
+	}
+	if config.EndDate == "" {
+		config.EndDate = "yesterday"
+	}
+
+	fmt.Printf("🔍 %s (property %s)\n", file, config.PropertyID)
+	fmt.Println("   ✅ schema")
+
+	valid := true
+	for _, result := range executor.Validate(ctx, config) {
+		switch {
+		case result.Skipped != "":
+			fmt.Printf("   ⏭️  %s: skipped (%s)\n", result.Stage, result.Skipped)
+		case result.Err != nil:
+			fmt.Printf("   ❌ %s: %v\n", result.Stage, result.Err)
+			valid = false
+		default:
+			fmt.Printf("   ✅ %s\n", result.Stage)
+		}
+	}
+	return valid
+}
+
+func querySchemaCmd(cmd *cobra.Command, args []string) {
+	template, _ := cmd.Flags().GetBool("template")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	schema := query.QueryConfigSchema()
+	if template {
+		schema = query.QueryTemplateSchema()
+	}
+	data, err := schema.MarshalIndent()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to encode schema: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if outputPath == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write schema: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	fmt.Printf("✅ Wrote the %s schema to %s\n", schema.Title, outputPath)
+}
+
+func queryPlanCmd(cmd *cobra.Command, args []string) {
+	config, _ := queryConfigFromFlags(cmd)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	plan, err := newQueryExecutor(dataClient).Plan(ctx, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("🧭 Query plan for property %s (%s to %s)\n\n", config.PropertyID, config.StartDate, config.EndDate)
+
+	if plan.ExactQueryID != "" {
+		fmt.Printf("⚡ Served from cache: exact match %s\n", plan.ExactQueryID)
+		return
+	}
+	if len(plan.Segments) == 0 {
+		fmt.Printf("🌐 Would run against the API\n")
+		fmt.Printf("💡 Cached results for other date ranges can't be reused: %s\n", plan.Reason)
+		return
+	}
+
+	for _, segment := range plan.Segments {
+		if segment.QueryID != "" {
+			fmt.Printf("   ⚡ %s → %s  cache (%s)\n", segment.StartDate, segment.EndDate, segment.QueryID)
+		} else {
+			fmt.Printf("   🌐 %s → %s  API\n", segment.StartDate, segment.EndDate)
+		}
+	}
+	fmt.Println()
+	if plan.FromCache() {
+		fmt.Printf("✅ Fully covered - the result would be assembled from cache without calling the API\n")
+	} else {
+		fmt.Printf("🌐 Not fully covered - the whole range would be fetched from the API\n")
+	}
+}
+
+// runQueryChunked runs a query one month or week at a time and shows the
+// stitched result. Relative dates resolve in the property's time zone when
+// the preset has synced it, as GA4 would.
+func runQueryChunked(executor *query.Executor, dataClient *api.DataClient, activePreset *config.Preset, queryConfig *query.QueryConfig, chunkBy string) *query.QueryResult {
+	location := time.Local
+	if property := findSyncedProperty(activePreset, queryConfig.PropertyID); property != nil && property.TimeZone != "" {
+		if propertyLocation, err := time.LoadLocation(property.TimeZone); err == nil {
+			location = propertyLocation
+		}
+	}
+
+	chunks, err := query.SplitDateRange(queryConfig.StartDate, queryConfig.EndDate, chunkBy, time.Now(), location)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	fmt.Printf("🧩 Splitting %s to %s into %d %s chunks\n", chunks[0].StartDate, chunks[len(chunks)-1].EndDate, len(chunks), chunkBy)
+
+	// Each chunk gets the full per-request timeout
+	ctx, cancel := commandContext(time.Duration(len(chunks)) * 120 * time.Second)
+	defer cancel()
+
+	var truncated, sampled []string
+	result, err := executor.ExecuteChunked(ctx, queryConfig, chunks, func(p query.ChunkProgress) {
+		prefix := fmt.Sprintf("   [%d/%d] %s", p.Index+1, p.Total, p.Chunk.Label())
+		if p.Err != nil {
+			if p.Retrying {
+				fmt.Printf("%s ⚠️  attempt %d failed, retrying: %v\n", prefix, p.Attempt, p.Err)
+			}
+			return
+		}
+		fmt.Printf("%s ✅ %s rows\n", prefix, formatNumber(int64(p.Rows)))
+		if p.RowCount > p.Rows {
+			truncated = append(truncated, fmt.Sprintf("%s (%s of %s rows)", p.Chunk.Label(), formatNumber(int64(p.Rows)), formatNumber(int64(p.RowCount))))
+		}
+		if p.Sampled {
+			sampled = append(sampled, p.Chunk.Label())
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if cacheClient := dataClient.CacheClient(); cacheClient != nil {
+		if err := executor.StoreChunked(ctx, cacheClient, result, chunkBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to store stitched result: %v\n", err)
+		}
+	}
+
+	fmt.Printf("✅ Query completed successfully!\n")
+	fmt.Printf("📊 Returned %d rows from %d chunks in %s\n", len(result.Rows), len(chunks), result.ExecutionTime)
+	for _, chunk := range truncated {
+		fmt.Printf("⚠️  Chunk %s hit the row limit - raise --limit or use a smaller --chunk-by\n", chunk)
+	}
+	if len(sampled) > 0 {
+		fmt.Printf("⚠️  GA4 sampled %d chunk(s): %s\n", len(sampled), strings.Join(sampled, ", "))
+		if chunkBy == query.ChunkByMonth {
+			fmt.Printf("💡 Try --chunk-by week for smaller, unsampled requests\n")
+		}
+	}
+	if len(queryConfig.MetricAggregations) > 0 {
+		fmt.Printf("💡 Totals, minimums and maximums are not available for chunked queries\n")
+	}
+	fmt.Println()
+
+	printQueryResult(result)
+	return result
+}
+
+// applyTopN trims a result to its top rows by the first metric and stores the
+// trimmed result so results show and export see what was printed
+func applyTopN(ctx context.Context, executor *query.Executor, dataClient *api.DataClient, result *query.QueryResult, top int, bucketOther bool) *query.QueryResult {
+	trimmed, summary, err := query.TopN(result, top, bucketOther)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if summary.Bucketed == 0 {
+		fmt.Printf("🔝 All %d rows are within the top %d\n", summary.Kept, top)
+		return result
+	}
+
+	if bucketOther {
+		fmt.Printf("🔝 Kept the top %d rows by %s; %d more folded into %s\n", summary.Kept, result.MetricHeaders[0].Name, summary.Bucketed, query.OtherBucket)
+	} else {
+		fmt.Printf("🔝 Kept the top %d rows by %s; dropped %d\n", summary.Kept, result.MetricHeaders[0].Name, summary.Bucketed)
+	}
+	if len(summary.Unsummed) > 0 {
+		fmt.Printf("⚠️  %s left empty for %s - rates, averages and user counts can't be summed\n", strings.Join(summary.Unsummed, ", "), query.OtherBucket)
+	}
+	if summary.Partial {
+		fmt.Printf("⚠️  GA4 matched %d rows but only %d were fetched, so %s is incomplete - raise --limit\n", result.RowCount, len(result.Rows), query.OtherBucket)
+	}
+
+	if cacheClient := dataClient.CacheClient(); cacheClient != nil {
+		if err := executor.StoreTopN(ctx, cacheClient, trimmed, top, bucketOther); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to store trimmed result: %v\n", err)
+		}
+	}
+	return trimmed
+}
+
+// printQueryResult shows the first rows of a result plus any metric aggregations
+func printQueryResult(result *query.QueryResult) {
+	if line := reportContext(result); line != "" {
+		fmt.Println(line)
+	}
+	if result.RowCount > 0 {
+		opts := results.DefaultDisplayOptions()
+		opts.MaxRows = 20
+		for _, line := range results.RenderTable(result, opts) {
+			fmt.Println(line)
+		}
+	}
+
+	// Show requested metric aggregations
+	printAggregateRows("📊 Totals", result.Totals, result.MetricHeaders)
+	printAggregateRows("📉 Minimums", result.Minimums, result.MetricHeaders)
+	printAggregateRows("📈 Maximums", result.Maximums, result.MetricHeaders)
+}
+
+func queryBuildCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	
+	fmt.Printf("🔧 Starting interactive query builder for property %s\n", propertyID)
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	ensurePropertyAccess(activePreset, propertyID)
+
+	// Create data client
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	// Create query builder
+	builder := query.NewQueryBuilder(dataClient, propertyID)
+
+	// Build query interactively
+	ctx, cancel := commandContext(300*time.Second) // 5 minutes
+	defer cancel()
+
+	config, err := builder.BuildInteractively(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query building failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Validate the query
+	if err := builder.ValidateQuery(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query validation failed: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	// Ask if user wants to execute now
+	fmt.Println("\n🎯 Query Configuration Complete!")
+	fmt.Printf("📊 Property: %s\n", config.PropertyID)
+	fmt.Printf("📏 Dimensions: %s\n", strings.Join(config.Dimensions, ", "))
+	fmt.Printf("📈 Metrics: %s\n", strings.Join(config.Metrics, ", "))
+	fmt.Printf("📅 Date Range: %s to %s\n", config.StartDate, config.EndDate)
+	fmt.Printf("🔢 Limit: %d rows\n", config.Limit)
+	if len(config.Filters) > 0 {
+		fmt.Printf("🔍 Filters: %d applied\n", len(config.Filters))
+	}
+
+	fmt.Print("\nExecute this query now? (y/N): ")
+	var execute string
+	fmt.Scanln(&execute)
+
+	if strings.ToLower(strings.TrimSpace(execute)) == "y" {
+		fmt.Println("\n🚀 Executing query...")
+		
+		executor := newQueryExecutor(dataClient)
+		result, err := executor.Execute(ctx, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+
+		fmt.Printf("✅ Query completed! Returned %d rows in %s\n", result.RowCount, result.ExecutionTime)
+		fmt.Printf("💡 Query ID: %s\n", result.QueryID)
+	} else {
+		fmt.Println("Query configuration saved but not executed.")
+	}
+}
+
+func queryStatsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	days, _ := cmd.Flags().GetInt("days")
+	top, _ := cmd.Flags().GetInt("top")
+
+	if days <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --days must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -days)
+	entries, err := cacheClient.ListQueryLog(ctx, propertyID, since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read query log: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	stats := query.BuildQueryStats(propertyID, entries, since, top)
+
+	fmt.Printf("📊 Query Statistics for property %s (last %d days)\n", propertyID, days)
+	if stats.TotalQueries == 0 {
+		fmt.Println("📭 No queries recorded - run 'ga4admin query run' to start collecting statistics")
+		return
+	}
+
+	fmt.Printf("🔢 Queries: %d (%d failed)\n", stats.TotalQueries, stats.FailedQueries)
+	fmt.Printf("⚡ Cache Hit Rate: %.1f%%\n", stats.CacheHitRate)
+	fmt.Printf("⏱️  Latency: avg %.0fms, P95 %dms, max %dms\n", stats.AverageExecutionMs, stats.P95ExecutionMs, stats.MaxExecutionMs)
+	fmt.Printf("📏 Average Rows: %.1f\n", stats.AverageRowCount)
+	if stats.TotalTokens > 0 {
+		fmt.Printf("🪙 Quota Tokens: %d\n", stats.TotalTokens)
+	}
+
+	printFieldUsage("📐 Most-Used Dimensions", stats.PopularDimensions, stats.TotalQueries)
+	printFieldUsage("📈 Most-Used Metrics", stats.PopularMetrics, stats.TotalQueries)
+}
+
+func quotaForecastCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	planFile, _ := cmd.Flags().GetString("plan")
+	days, _ := cmd.Flags().GetInt("days")
+	analytics360, _ := cmd.Flags().GetBool("analytics360")
+
+	if days <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --days must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	var planned []query.ScheduledQuery
+	if planFile != "" {
+		plan, err := query.LoadSchedulePlan(planFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		for i := range plan.Queries {
+			scheduled := &plan.Queries[i]
+			if scheduled.Config, err = resolveMatrixTemplate(scheduled.Template, filepath.Dir(planFile)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: query '%s': %v\n", scheduled.Name, err)
+				os.Exit(exitcode.For(err))
+			}
+		}
+		planned = plan.Queries
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	entries, err := cacheClient.ListQueryLog(ctx, propertyID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read query log: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	limits := query.QuotaLimitsFor(analytics360)
+	forecast := query.ForecastQuota(propertyID, entries, days, planned, limits)
+
+	fmt.Printf("🪙 Quota Forecast for property %s\n", propertyID)
+	if forecast.LoggedCalls == 0 {
+		fmt.Printf("📭 No token counts logged in the last %d days - planned queries are estimated at the default cost\n", days)
+	} else {
+		fmt.Printf("📜 History (last %d days): %d API calls, ~%.0f tokens/day, peak hour %d tokens\n",
+			days, forecast.LoggedCalls, forecast.BaselineDaily, forecast.BaselinePeakHour)
+	}
+
+	if len(forecast.Planned) > 0 {
+		fmt.Println()
+		fmt.Println("📅 Planned Queries:")
+		for _, item := range forecast.Planned {
+			fmt.Printf("  • %s: ~%.0f tokens/run (%s), %d call(s)/day, %d call(s)/hour → ~%.0f tokens/day\n",
+				item.Name, item.TokensPerRun, item.Basis, item.CallsPerDay, item.CallsPerHour, item.DailyTokens)
+		}
+	}
+
+	fmt.Println()
+	exceeded := printQuotaUsage("Daily", forecast.DailyTotal(), limits.TokensPerDay)
+	if printQuotaUsage("Hourly", forecast.HourlyTotal(), limits.TokensPerHour) {
+		exceeded = true
+	}
+
+	if len(forecast.Suggestions) > 0 {
+		fmt.Println()
+		fmt.Println("💡 Suggestions:")
+		for _, suggestion := range forecast.Suggestions {
+			fmt.Printf("  • %s\n", suggestion)
+		}
+	}
+
+	if exceeded {
+		os.Exit(exitcode.Quota)
+	}
+}
+
+// printQuotaUsage prints one forecast line against its limit and reports
+// whether the limit would be exceeded
+func printQuotaUsage(label string, tokens float64, limit int) bool {
+	share := tokens / float64(limit) * 100
+	switch {
+	case tokens > float64(limit):
+		fmt.Printf("❌ %s: ~%.0f of %d tokens (%.0f%%) - quota would be exceeded\n", label, tokens, limit, share)
+		return true
+	case share >= query.QuotaWarnShare*100:
+		fmt.Printf("⚠️  %s: ~%.0f of %d tokens (%.0f%%)\n", label, tokens, limit, share)
+	default:
+		fmt.Printf("✅ %s: ~%.0f of %d tokens (%.0f%%)\n", label, tokens, limit, share)
+	}
+	return false
+}
+
+func printFieldUsage(title string, usage []query.FieldUsage, totalQueries int) {
+	if len(usage) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s:\n", title)
+	for i, field := range usage {
+		fmt.Printf("  %2d. %-35s %5d queries (%.0f%%)\n", i+1, field.Name, field.Count, float64(field.Count)/float64(totalQueries)*100)
+	}
+}
+
+func queryRunMatrixCmd(cmd *cobra.Command, args []string) {
+	matrixFile, _ := cmd.Flags().GetString("file")
+	outputPattern, _ := cmd.Flags().GetString("output")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	startDate, _ := cmd.Flags().GetString("start-date")
+	endDate, _ := cmd.Flags().GetString("end-date")
+	limit, _ := cmd.Flags().GetInt64("limit")
+	notifierName, _ := cmd.Flags().GetString("notify")
+	strictPreset, _ := cmd.Flags().GetBool("strict-preset")
+
+	// Fail before running anything if the notifier is misconfigured
+	if notifierName != "" {
+		if _, err := config.GetNotifier(notifierName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+	}
+
+	rows, err := query.LoadMatrixFile(matrixFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Resolve every row before running anything so a typo on the last line
+	// doesn't surface after an hour of queries
+	matrixDir := filepath.Dir(matrixFile)
+	jobs := make([]query.MatrixJob, 0, len(rows))
+	outputLines := make(map[string]int)
+	propertyLines := make(map[string]int)
+	var propertyIDs []string
+	var problems []string
+	for i, row := range rows {
+		propertyID, err := resolvePropertyReference(row.PropertyID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", row.Line, err))
+			continue
+		}
+		row.PropertyID = propertyID
+
+		queryConfig, err := resolveMatrixTemplate(row.Template, matrixDir)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", row.Line, err))
+			continue
+		}
+
+		// Row dates win, then dates saved in a query file, then the flags
+		if row.StartDate == "" {
+			row.StartDate = queryConfig.StartDate
+		}
+		if row.StartDate == "" {
+			row.StartDate = startDate
+		}
+		if row.EndDate == "" {
+			row.EndDate = queryConfig.EndDate
+		}
+		if row.EndDate == "" {
+			row.EndDate = endDate
+		}
+		queryConfig.PropertyID = row.PropertyID
+		queryConfig.StartDate = row.StartDate
+		queryConfig.EndDate = row.EndDate
+		if limit > 0 {
+			queryConfig.Limit = limit
+		}
+
+		pattern := outputPattern
+		if row.Output != "" {
+			pattern = row.Output
+		}
+		output := query.ExpandOutputPath(pattern, row, i)
+		// Paths still holding result tokens like {query} are only known after the run
+		if !results.HasPathTokens(output) {
+			if line, ok := outputLines[output]; ok {
+				problems = append(problems, fmt.Sprintf("line %d: output %s is also written by line %d", row.Line, output, line))
+				continue
+			}
+			outputLines[output] = row.Line
+		}
+		if _, ok := propertyLines[row.PropertyID]; !ok {
+			propertyLines[row.PropertyID] = row.Line
+			propertyIDs = append(propertyIDs, row.PropertyID)
+		}
+
+		jobs = append(jobs, query.MatrixJob{Row: row, Config: queryConfig, Output: output})
+	}
+
+	// Route each property to a preset that can reach it, the active one first
+	router := access.NewRouter(activePreset)
+	routes := make(map[string]string, len(propertyIDs))
+	var routed []string
+	accessCtx, accessCancel := commandContext(60*time.Second)
+	for _, propertyID := range propertyIDs {
+		var presetName string
+		if strictPreset {
+			err = access.CheckPropertyAccess(accessCtx, activePreset, propertyID)
+			presetName = activePreset.Name
+		} else {
+			presetName, err = router.Route(accessCtx, propertyID)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", propertyLines[propertyID], err))
+			continue
+		}
+		routes[propertyID] = presetName
+		if router.Routed(propertyID) {
+			routed = append(routed, propertyID)
+		}
+	}
+	accessCancel()
+
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid matrix file %s:\n", matrixFile)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "   • %s\n", problem)
+		}
+		os.Exit(exitcode.Validation)
+	}
+
+	// Jobs are run a preset at a time so each preset's client and cache are
+	// opened once
+	var presetNames []string
+	presetJobs := make(map[string][]query.MatrixJob)
+	for _, job := range jobs {
+		presetName := routes[job.Row.PropertyID]
+		if _, ok := presetJobs[presetName]; !ok {
+			presetNames = append(presetNames, presetName)
+		}
+		presetJobs[presetName] = append(presetJobs[presetName], job)
+	}
+
+	fmt.Printf("🧮 Running %d matrix quer(y/ies) with concurrency %d\n", len(jobs), concurrency)
+	if len(routed) > 0 {
+		fmt.Printf("🔀 Routing %d propert(y/ies) the active preset '%s' can't access:\n", len(routed), activePreset.Name)
+		for _, propertyID := range routed {
+			fmt.Printf("   • %s → preset '%s'\n", propertyID, routes[propertyID])
+		}
+	}
+	fmt.Println()
+
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	done := 0
+	writeExport := func(job query.MatrixJob, result *query.QueryResult) (string, error) {
+		output, err := results.ExpandPath(job.Output, result)
+		if err != nil {
+			return "", err
+		}
+		return output, results.WriteCSV(result, output)
+	}
+	printMatrixResult := func(result query.MatrixResult) {
+		done++
+		row := result.Job.Row
+		prefix := fmt.Sprintf("[%d/%d] line %d: %s for %s (%s → %s)", done, len(jobs), row.Line, row.Template, row.PropertyID, row.StartDate, row.EndDate)
+		if router.Routed(row.PropertyID) {
+			prefix += fmt.Sprintf(" via preset '%s'", routes[row.PropertyID])
+		}
+		if result.Err != nil {
+			fmt.Printf("%s ❌ %v\n", prefix, result.Err)
+			return
+		}
+		fmt.Printf("%s ✅ %d rows → %s in %s\n", prefix, result.Result.RowCount, result.Output, result.Duration.Round(time.Millisecond))
+	}
+
+	var matrixResults []query.MatrixResult
+	for _, presetName := range presetNames {
+		dataClient, err := createPresetDataClient(presetName)
+		if err != nil {
+			for _, job := range presetJobs[presetName] {
+				result := query.MatrixResult{Job: job, Err: fmt.Errorf("preset '%s': %w", presetName, err)}
+				printMatrixResult(result)
+				matrixResults = append(matrixResults, result)
+			}
+			continue
+		}
+
+		executor := newQueryExecutor(dataClient)
+		matrixResults = append(matrixResults, executor.ExecuteMatrix(ctx, presetJobs[presetName], concurrency, writeExport, printMatrixResult)...)
+		dataClient.Close()
+	}
+
+	// Summarize
+	failed := 0
+	var outputs []string
+	for _, result := range matrixResults {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		outputs = append(outputs, result.Output)
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 Matrix complete: %d exported, %d failed\n", len(outputs), failed)
+
+	if notifierName != "" && len(outputs) > 0 {
+		subject := fmt.Sprintf("GA4 exports from %s", filepath.Base(matrixFile))
+		summary := fmt.Sprintf("%d of %d queries from %s were exported.", len(outputs), len(matrixResults), filepath.Base(matrixFile))
+		if failed > 0 {
+			summary += fmt.Sprintf(" %d failed; see the command output for details.", failed)
+		}
+		if err := sendExportNotification(notifierName, subject, summary, outputs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("📧 Sent %d export(s) through notifier '%s'\n", len(outputs), notifierName)
+	}
+
+	if failed > 0 {
+		os.Exit(exitcode.Failure)
+	}
+}
+
+// resolveMatrixTemplate returns a fresh query for a run-matrix template: a
+// query file path (relative to the matrix file) or a built-in report name
+func resolveMatrixTemplate(name, matrixDir string) (*query.QueryConfig, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(matrixDir, path)
+		}
+		return query.LoadQueryFile(path)
+	}
+
+	template, err := report.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return template.Query, nil
+}
+
+func backfillPlanCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	templateName, _ := cmd.Flags().GetString("query")
+	fromFlag, _ := cmd.Flags().GetString("from")
+	toFlag, _ := cmd.Flags().GetString("to")
+	execute, _ := cmd.Flags().GetBool("execute")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
+	opts := backfill.Options{Template: templateName}
+	opts.ChunkBy, _ = cmd.Flags().GetString("chunk")
+	opts.Prefix, _ = cmd.Flags().GetString("prefix")
+
+	format = strings.ToLower(format)
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: table, csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if format == "table" && outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Error: --output needs --format csv or json\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	var err error
+	if opts.From, err = time.Parse("2006-01-02", fromFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --from '%s': use YYYY-MM-DD\n", fromFlag)
+		os.Exit(exitcode.Validation)
+	}
+	if toFlag == "" {
+		now := time.Now()
+		opts.To = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	} else if opts.To, err = time.Parse("2006-01-02", toFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --to '%s': use YYYY-MM-DD\n", toFlag)
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	queryConfig, err := resolveMatrixTemplate(templateName, ".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	queryConfig.PropertyID = propertyID
+	queryConfig.StartDate = opts.From.Format("2006-01-02")
+	queryConfig.EndDate = opts.To.Format("2006-01-02")
+
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	cacheClient, ok := dataClient.CacheClient().(*cache.CacheClient)
+	if !ok || cacheClient == nil {
+		fmt.Fprintf(os.Stderr, "Error: Backfill plans need the query cache, which is unavailable\n")
+		os.Exit(exitcode.Failure)
+	}
+
+	executor := newQueryExecutor(dataClient)
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	request, err := executor.Request(ctx, queryConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	tables, err := cacheClient.ListNamedTableQueries(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list named tables: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	plan, err := backfill.Build(request, tables, opts, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	pending := plan.Pending()
+
+	if format != "table" {
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("backfill_%s_%s.%s", plan.Prefix, propertyID, format)
+		}
+		if format == "json" {
+			err = backfill.WritePlanJSON(plan, outputFile)
+		} else {
+			err = backfill.WritePlanCSV(plan, outputFile)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ %d of %d %s chunk(s) need a query\n", len(pending), len(plan.Chunks), plan.ChunkBy)
+		fmt.Printf("📁 File: %s\n", outputFile)
+	} else {
+		fmt.Printf("🧩 Backfill plan for %s on property %s (%s → %s, by %s)\n\n", templateName, propertyID, plan.From, plan.To, plan.ChunkBy)
+		fmt.Printf("   %-10s  %-10s  %-9s  %s\n", "Start", "End", "Status", "Table")
+		for _, chunk := range plan.Chunks {
+			table := chunk.Table
+			if chunk.CoveredBy != "" && chunk.CoveredBy != chunk.Table {
+				table += fmt.Sprintf(" (in %s)", chunk.CoveredBy)
+			}
+			fmt.Printf("   %-10s  %-10s  %-9s  %s\n", chunk.StartDate, chunk.EndDate, chunk.Status, table)
+		}
+		fmt.Printf("\n📊 %d of %d chunk(s) need a query\n", len(pending), len(plan.Chunks))
+	}
+	for _, chunk := range pending {
+		if chunk.Status == backfill.StatusTruncated {
+			fmt.Println("⚠️  Truncated chunks hit the query's row limit; raise the limit or use a smaller --chunk before rerunning them")
+			break
+		}
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("✅ Every chunk is saved in a complete named table")
+		return
+	}
+	if !execute {
+		fmt.Println("💡 Rerun with --execute to run them and save each as a named table")
+		return
+	}
+
+	ensurePropertyAccess(activePreset, propertyID)
+
+	fmt.Println()
+	failed := 0
+	for i, chunk := range pending {
+		chunkConfig := *queryConfig
+		chunkConfig.StartDate = chunk.StartDate
+		chunkConfig.EndDate = chunk.EndDate
+
+		// Saved results of this exact request are the incomplete ones being replaced
+		runCtx := ctx
+		if chunk.Status != backfill.StatusMissing {
+			runCtx = api.WithoutCache(ctx)
+		}
+
+		fmt.Printf("[%d/%d] %s → %s: ", i+1, len(pending), chunk.StartDate, chunk.EndDate)
+		result, err := executor.Execute(runCtx, &chunkConfig)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %d rows\n", result.RowCount)
+		description := fmt.Sprintf("Backfill of %s, %s to %s", templateName, chunk.StartDate, chunk.EndDate)
+		nameQueryResult(dataClient, result, chunk.Table, description, cache.NameConflictOverwrite)
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 Backfill complete: %d saved, %d failed\n", len(pending)-failed, failed)
+	if failed > 0 {
+		os.Exit(exitcode.Failure)
+	}
+}
+
+func pipelineAddCmd(cmd *cobra.Command, args []string) {
+	p := config.PipelineConfig{Name: args[0]}
+	p.PropertyID, _ = cmd.Flags().GetString("property")
+	p.Query, _ = cmd.Flags().GetString("query")
+	p.Database, _ = cmd.Flags().GetString("database")
+	p.Table, _ = cmd.Flags().GetString("table")
+	p.StartDate, _ = cmd.Flags().GetString("start")
+	p.ChunkBy, _ = cmd.Flags().GetString("chunk")
+	if p.Table == "" {
+		p.Table = strings.ReplaceAll(p.Name, "-", "_")
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	p.Preset = activePreset.Name
+
+	queryConfig, err := resolveMatrixTemplate(p.Query, ".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	checkPipelineQuery(p.Query, queryConfig)
+
+	// Scheduled runs start in another directory, so files are stored by absolute path
+	if ext := strings.ToLower(filepath.Ext(p.Query)); ext == ".yaml" || ext == ".yml" || ext == ".json" {
+		p.Query, _ = filepath.Abs(p.Query)
+	}
+	p.Database, _ = filepath.Abs(p.Database)
+
+	if err := config.SetPipeline(p); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("✅ Pipeline '%s' appends %s for property %s to %s in %s\n", p.Name, p.Query, p.PropertyID, p.Table, p.Database)
+	fmt.Printf("💡 Load it with 'ga4admin pipeline run %s', e.g. daily from cron\n", p.Name)
+}
+
+// checkPipelineQuery exits unless a pipeline's query has the date dimension
+func checkPipelineQuery(name string, queryConfig *query.QueryConfig) {
+	for _, dimension := range queryConfig.Dimensions {
+		if dimension == pipeline.DateColumn {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Error: Query '%s' has no '%s' dimension, so loaded days can't be told apart\n", name, pipeline.DateColumn)
+	os.Exit(exitcode.Validation)
+}
+
+func pipelineListCmd(cmd *cobra.Command, args []string) {
+	pipelines, err := config.ListPipelines()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(pipelines) == 0 {
+		fmt.Println("📭 No pipelines configured")
+		fmt.Println("💡 Add one with 'ga4admin pipeline add <name> --property <id> --query <template> --database <file> --start <date>'")
+		return
+	}
+
+	for _, p := range pipelines {
+		fmt.Printf("🚰 %s: %s for property %s (preset '%s')\n", p.Name, p.Query, p.PropertyID, p.Preset)
+		fmt.Printf("   → %s in %s, from %s\n", p.Table, p.Database, p.StartDate)
+	}
+}
+
+func pipelineRemoveCmd(cmd *cobra.Command, args []string) {
+	if err := config.RemovePipeline(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	fmt.Printf("✅ Removed pipeline '%s'; its table was kept\n", args[0])
+}
+
+func pipelineRunCmd(cmd *cobra.Command, args []string) {
+	toFlag, _ := cmd.Flags().GetString("to")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	p, err := config.GetPipeline(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.NotFound)
+	}
+	chunkBy := p.ChunkBy
+	if chunkBy == "" {
+		chunkBy = backfill.ChunkMonth
+	}
+
+	start, _ := time.Parse("2006-01-02", p.StartDate)
+	var end time.Time
+	if toFlag == "" {
+		now := time.Now()
+		end = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	} else if end, err = time.Parse("2006-01-02", toFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --to '%s': use YYYY-MM-DD\n", toFlag)
+		os.Exit(exitcode.Validation)
+	}
+
+	queryConfig, err := resolveMatrixTemplate(p.Query, ".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	checkPipelineQuery(p.Query, queryConfig)
+	queryConfig.PropertyID = p.PropertyID
+
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	target, err := pipeline.Open(ctx, p.Database, p.Table)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer target.Close()
+
+	loaded, err := target.LoadedDays(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	var chunks []pipeline.Range
+	for _, missing := range pipeline.MissingRanges(loaded, start, end) {
+		bounds, err := backfill.Chunks(missing.Start, missing.End, chunkBy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Validation)
+		}
+		for _, b := range bounds {
+			chunks = append(chunks, pipeline.Range{Start: b[0], End: b[1]})
+		}
+	}
+
+	fmt.Printf("🚰 Pipeline '%s': %s → %s in %s\n", p.Name, p.Query, p.Table, p.Database)
+	if len(chunks) == 0 {
+		fmt.Printf("✅ Up to date through %s\n", end.Format("2006-01-02"))
+		return
+	}
+	if dryRun {
+		fmt.Printf("🔍 %d request(s) would load the missing days:\n", len(chunks))
+		for _, chunk := range chunks {
+			fmt.Printf("   • %s\n", chunk)
+		}
+		return
+	}
+
+	pipelinePreset, err := preset.LoadPreset(p.Preset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	ensurePropertyAccess(pipelinePreset, p.PropertyID)
+
+	dataClient, err := createPresetDataClient(p.Preset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+	executor := newQueryExecutor(dataClient)
+
+	failed := 0
+	var appended int
+	for i, chunk := range chunks {
+		chunkConfig := *queryConfig
+		chunkConfig.StartDate = chunk.Start.Format("2006-01-02")
+		chunkConfig.EndDate = chunk.End.Format("2006-01-02")
+
+		fmt.Printf("[%d/%d] %s: ", i+1, len(chunks), chunk)
+		result, err := executor.Execute(ctx, &chunkConfig)
+		if err == nil && len(result.Rows) < result.RowCount {
+			err = fmt.Errorf("only %d of %d rows returned; raise the query's limit or use a smaller --chunk", len(result.Rows), result.RowCount)
+		}
+		if err == nil {
+			err = target.Append(ctx, result, chunk)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %d rows\n", len(result.Rows))
+		appended += len(result.Rows)
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 Appended %d rows to %s from %d of %d request(s)\n", appended, p.Table, len(chunks)-failed, len(chunks))
+	if failed > 0 {
+		fmt.Println("💡 Failed days are retried on the next run")
+		os.Exit(exitcode.Failure)
+	}
+}
+
+func queryListCmd(cmd *cobra.Command, args []string) {
+	propertyFilter, _ := cmd.Flags().GetString("property")
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Println("📋 Cached Queries:")
+	fmt.Println()
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	resultsList, err := resultsManager.ListResults(ctx, propertyFilter, "", since, limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list results: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(resultsList) == 0 {
+		fmt.Println("❌ No cached queries found")
+		fmt.Println("💡 Run 'ga4admin query run' to create your first query")
+		return
+	}
+
+	// Display results, grouped by property when listing all of them
+	for g, group := range groupResultsByProperty(resultsList, propertyFilter == "") {
+		if g > 0 {
+			fmt.Println()
+		}
+		if propertyFilter == "" {
+			fmt.Printf("🏠 %s • %d cached\n\n", describeResultProperty(activePreset, group[0].PropertyID), len(group))
+		}
+		for i, summary := range group {
+			fmt.Printf("🔍 %s\n", summary.QueryID)
+			fmt.Printf("   📊 %d rows • 📅 %s\n", summary.RowCount, summary.CreatedAt.Format("2006-01-02 15:04"))
+			if summary.TableName != "" {
+				fmt.Printf("   🏷️  %s\n", summary.TableName)
+			}
+			if summary.IsExpired {
+				fmt.Printf("   ⏰ Expired\n")
+			}
+
+			if i < len(group)-1 {
+				fmt.Println()
+			}
+		}
+	}
+
+	fmt.Printf("\n💡 Showing %d of cached queries\n", len(resultsList))
+	fmt.Printf("💡 Use 'ga4admin results show <query-id>' to see details\n")
+}
+
+// Results command handlers
+
+func resultsListCmd(cmd *cobra.Command, args []string) {
+	propertyFilter, _ := cmd.Flags().GetString("property")
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	limit, _ := cmd.Flags().GetInt("limit")
+	tag, _ := cmd.Flags().GetString("tag")
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	if tag != "" {
+		normalized, err := cache.NormalizeTag(tag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		tag = normalized
+	}
+
+	fmt.Println("📊 Cached Query Results:")
+	fmt.Println()
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	resultsList, err := resultsManager.ListResults(ctx, propertyFilter, tag, since, limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list results: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(resultsList) == 0 {
+		scope := "for property " + propertyFilter
+		if propertyFilter == "" {
+			scope = "in preset '" + activePreset.Name + "'"
+		}
+		if !since.IsZero() {
+			scope += " since " + since.Format("2006-01-02 15:04")
+		}
+		if tag != "" {
+			fmt.Printf("❌ No cached results tagged '%s' %s\n", tag, scope)
+			fmt.Println("💡 Tag results with 'ga4admin results tag <result-id> <tag>'")
+			return
+		}
+		fmt.Printf("❌ No cached results found %s\n", scope)
+		fmt.Println("💡 Run 'ga4admin query run' to create results")
+		return
+	}
+
+	// Display results, grouped by property when listing all of them
+	for g, group := range groupResultsByProperty(resultsList, propertyFilter == "") {
+		if g > 0 {
+			fmt.Println()
+		}
+		if propertyFilter == "" {
+			fmt.Printf("🏠 %s • %d cached\n\n", describeResultProperty(activePreset, group[0].PropertyID), len(group))
+		}
+		for i, summary := range group {
+			statusIcon := "✅"
+			if summary.IsExpired {
+				statusIcon = "⏰"
+			}
+
+			fmt.Printf("%s %s\n", statusIcon, summary.QueryID)
+			fmt.Printf("   📊 %d rows • 📅 %s • 🔄 %s\n",
+				summary.RowCount,
+				summary.CreatedAt.Format("2006-01-02 15:04"),
+				summary.LastAccessed.Format("2006-01-02 15:04"))
+
+			if summary.TableName != "" {
+				fmt.Printf("   🏷️  %s: %s\n", summary.TableName, summary.Description)
+			}
+			if len(summary.Tags) > 0 {
+				fmt.Printf("   🔖 %s\n", strings.Join(summary.Tags, ", "))
+			}
+			if summary.Note != "" {
+				fmt.Printf("   📝 %s\n", summary.Note)
+			}
+
+			if i < len(group)-1 {
+				fmt.Println()
+			}
+		}
+	}
+
+	fmt.Printf("\n💡 Total: %d cached results\n", len(resultsList))
+	// Saved views are listed per property
+	if propertyFilter != "" {
+		if views, err := cacheClient.ListResultViews(ctx, propertyFilter); err == nil && len(views) > 0 {
+			fmt.Printf("💡 %d saved views: 'ga4admin results view list --property %s'\n", len(views), propertyFilter)
+		}
+	}
+	fmt.Printf("💡 Use 'ga4admin results show <query-id>' for detailed view\n")
+}
+
+// groupResultsByProperty splits results into one group per property, in the
+// order each property first appears, or returns them as a single group
+func groupResultsByProperty(list []results.ResultSummary, byProperty bool) [][]results.ResultSummary {
+	if !byProperty {
+		return [][]results.ResultSummary{list}
+	}
+	var groups [][]results.ResultSummary
+	index := make(map[string]int)
+	for _, summary := range list {
+		i, ok := index[summary.PropertyID]
+		if !ok {
+			i = len(groups)
+			index[summary.PropertyID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], summary)
+	}
+	return groups
+}
+
+// describeResultProperty names a property by its ID and any aliases it has
+// in the preset
+func describeResultProperty(p *config.Preset, propertyID string) string {
+	var names []string
+	for _, alias := range preset.ListAliases(p) {
+		if alias.PropertyID == propertyID {
+			names = append(names, alias.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "Property " + propertyID
+	}
+	return fmt.Sprintf("Property %s (%s)", propertyID, strings.Join(names, ", "))
+}
+
+// parseSince turns a --since value into the earliest time to include: an
+// age in days such as 7d, a duration such as 12h, or a YYYY-MM-DD date,
+// taken as local midnight. Empty means no limit.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && strings.HasSuffix(value, "d") && days >= 0 {
+		return now.AddDate(0, 0, -days), nil
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s': use an age such as 7d or 12h, or a date (YYYY-MM-DD)", value)
+}
+
+func resultsTagCmd(cmd *cobra.Command, args []string) {
+	remove, _ := cmd.Flags().GetBool("remove")
+
+	tags := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		tag, err := cache.NormalizeTag(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		tags = append(tags, tag)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	queryID, err := results.NewManager(cacheClient).ResolveID(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if remove {
+		removed, err := cacheClient.UntagResult(ctx, queryID, tags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Removed %d tag(s) from result %s\n", removed, queryID)
+	} else {
+		added, err := cacheClient.TagResult(ctx, queryID, tags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Added %d tag(s) to result %s\n", added, queryID)
+	}
+
+	annotations, err := cacheClient.GetAnnotations(ctx, queryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if len(annotations.Tags) > 0 {
+		fmt.Printf("🔖 Tags: %s\n", strings.Join(annotations.Tags, ", "))
+	} else {
+		fmt.Printf("🔖 No tags left\n")
+	}
+}
+
+func resultsNoteCmd(cmd *cobra.Command, args []string) {
+	clearNote, _ := cmd.Flags().GetBool("clear")
+
+	note := ""
+	if len(args) == 2 {
+		note = strings.TrimSpace(args[1])
+		if note == "" {
+			fmt.Fprintf(os.Stderr, "Error: the note is empty - use --clear to remove a note\n")
+			os.Exit(exitcode.Validation)
+		}
+	}
+	if clearNote && note != "" {
+		fmt.Fprintf(os.Stderr, "Error: --clear cannot be combined with a note\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	queryID, err := results.NewManager(cacheClient).ResolveID(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if note == "" && !clearNote {
+		annotations, err := cacheClient.GetAnnotations(ctx, queryID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if annotations.Note == "" {
+			fmt.Printf("📝 Result %s has no note\n", queryID)
+			fmt.Printf("💡 Add one with 'ga4admin results note %s \"<note>\"'\n", args[0])
+			return
+		}
+		fmt.Printf("📝 %s\n", annotations.Note)
+		fmt.Printf("   Updated %s\n", annotations.NoteUpdatedAt.Local().Format("2006-01-02 15:04"))
+		return
+	}
+
+	if err := cacheClient.SetResultNote(ctx, queryID, note); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if clearNote {
+		fmt.Printf("✅ Note removed from result %s\n", queryID)
+	} else {
+		fmt.Printf("✅ Note saved on result %s\n", queryID)
+	}
+}
+
+func resultsShowCmd(cmd *cobra.Command, args []string) {
+	queryID := args[0]
+	maxRows, _ := cmd.Flags().GetInt("max-rows")
+	maxWidth, _ := cmd.Flags().GetInt("max-width")
+	showTotals, _ := cmd.Flags().GetBool("show-totals")
+	markdown, _ := cmd.Flags().GetBool("markdown")
+	plainNumbers, _ := cmd.Flags().GetBool("plain-numbers")
+	page, _ := cmd.Flags().GetInt("page")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+
+	if page < 0 || pageSize < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --page and --page-size must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+	if pageSize > 0 && page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = maxRows
+	}
+	if page > 0 && pageSize == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --page needs a page size; set --page-size\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("📊 Query Result: %s\n", queryID)
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	result, err := resultsManager.GetResult(ctx, queryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get result: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	result = reshapeResult(ctx, cmd, result)
+
+	// Show metadata
+	fmt.Printf("📈 Property: %s\n", result.PropertyID)
+	fmt.Printf("📅 Executed: %s (%s)\n", result.ExecutedAt.Format("2006-01-02 15:04:05"), result.ExecutionTime)
+	if result.ViewName != "" {
+		fmt.Printf("👁️  View of result %s\n", result.QueryID)
+	}
+	if result.RollupName != "" {
+		fmt.Printf("📦 Rollup of result %s\n", result.QueryID)
+	}
+	if annotations, err := cacheClient.GetAnnotations(ctx, result.QueryID); err == nil {
+		if len(annotations.Tags) > 0 {
+			fmt.Printf("🔖 Tags: %s\n", strings.Join(annotations.Tags, ", "))
+		}
+		if annotations.Note != "" {
+			fmt.Printf("📝 Note: %s\n", annotations.Note)
+		}
+	}
+	fmt.Printf("📊 Rows: %d\n", result.RowCount)
+	if result.FromCache {
+		fmt.Printf("⚡ From cache%s\n", cacheAgeNote(result))
+	}
+	if line := reportContext(result); line != "" {
+		fmt.Println(line)
+	}
+	
+	// Show query configuration
+	if result.QueryConfig != nil {
+		fmt.Printf("📏 Dimensions: %s\n", strings.Join(result.QueryConfig.Dimensions, ", "))
+		fmt.Printf("📈 Metrics: %s\n", strings.Join(result.QueryConfig.Metrics, ", "))
+		fmt.Printf("📅 Date range: %s to %s\n", result.QueryConfig.StartDate, result.QueryConfig.EndDate)
+	}
+	fmt.Println()
+
+	// Show data table
+	var pages int
+	if result.RowCount > 0 {
+		opts := results.DefaultDisplayOptions()
+		opts.MaxRows = maxRows
+		opts.MaxColWidth = maxWidth
+		opts.NumberFormat = !plainNumbers
+		opts.Markdown = markdown
+		if page > 0 {
+			pages = (len(result.Rows) + pageSize - 1) / pageSize
+			if page > pages {
+				fmt.Fprintf(os.Stderr, "Error: Page %d is past the last page (%d pages of %d rows)\n", page, pages, pageSize)
+				os.Exit(exitcode.Validation)
+			}
+			opts.Offset = (page - 1) * pageSize
+			opts.MaxRows = pageSize
+			fmt.Printf("📄 Page %d of %d\n", page, pages)
+		}
+		for _, line := range results.RenderTable(result, opts) {
+			fmt.Println(line)
+		}
+
+		// Show totals if requested and available
+		if showTotals && len(result.Totals) > 0 {
+			fmt.Println("\n📊 Totals:")
+			for _, total := range result.Totals {
+				fmt.Printf("   ")
+				for _, value := range total.MetricValues {
+					fmt.Printf("%-15s ", value.Value)
+				}
+				fmt.Println()
+			}
+		}
+	}
+
+	if page > 0 && page < pages {
+		fmt.Printf("\n💡 Next page: ga4admin results show %s --page %d --page-size %d\n", queryID, page+1, pageSize)
+	} else if page == 0 && maxRows > 0 && len(result.Rows) > maxRows {
+		fmt.Printf("\n💡 More rows: ga4admin results show %s --page 2 --page-size %d\n", queryID, maxRows)
+	}
+	fmt.Printf("\n💡 Export: ga4admin results export %s output.csv\n", queryID)
+}
+
+func resultsExportCmd(cmd *cobra.Command, args []string) {
+	queryID := args[0]
+	outputFile := args[1]
+	format, _ := cmd.Flags().GetString("format")
+	prettify, _ := cmd.Flags().GetBool("prettify")
+	title, _ := cmd.Flags().GetString("title")
+	notifierName, _ := cmd.Flags().GetString("notify")
+	includeQuery, _ := cmd.Flags().GetBool("include-query")
+
+	fmt.Printf("📤 Exporting result %s to %s (%s format)...\n", queryID, outputFile, format)
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	format = strings.ToLower(format)
+	if format != "csv" && format != "json" && format != "html" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: csv, json, html\n", format)
+		os.Exit(exitcode.Validation)
+	}
+
+	result, err := resultsManager.GetResult(ctx, queryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	result = reshapeResult(ctx, cmd, result)
+
+	// Paths like 'exports/{property}/{date}.csv' are filled from the result
+	outputFile, err = results.ExpandPath(outputFile, result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Export based on format
+	switch format {
+	case "csv":
+		err = results.WriteCSV(result, outputFile)
+	case "html":
+		err = htmlreport.Write(result, outputFile, title)
+	default:
+		err = results.WriteJSON(result, outputFile, prettify)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	attachments := []string{outputFile}
+	if includeQuery {
+		if err := results.WriteQuerySidecar(result, outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		attachments = append(attachments, results.QuerySidecarPath(outputFile))
+	}
+
+	fmt.Printf("✅ Export completed successfully!\n")
+	fmt.Printf("📁 File: %s\n", outputFile)
+	if includeQuery {
+		fmt.Printf("📄 Query: %s\n", results.QuerySidecarPath(outputFile))
+	}
+
+	if notifierName != "" {
+		subject := fmt.Sprintf("GA4 export for property %s", result.PropertyID)
+		summary := fmt.Sprintf("Result %s for property %s (%d rows, %s to %s).",
+			result.QueryID, result.PropertyID, result.RowCount, result.QueryConfig.StartDate, result.QueryConfig.EndDate)
+		if err := sendExportNotification(notifierName, subject, summary, attachments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("📧 Sent through notifier '%s'\n", notifierName)
+	}
+}
+
+func resultsTransformCmd(cmd *cobra.Command, args []string) {
+	queryID := args[0]
+	scriptPath, _ := cmd.Flags().GetString("script")
+	outputFile, _ := cmd.Flags().GetString("output")
+	maxRows, _ := cmd.Flags().GetInt("max-rows")
+	maxWidth, _ := cmd.Flags().GetInt("max-width")
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(outputFile), "."))
+	if outputFile != "" && format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --output must be a .csv or .json file\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(60 * time.Second)
+	defer cancel()
+
+	result, err := resultsManager.GetResult(ctx, queryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get result: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("🧪 Running %s over %d rows of result %s...\n", scriptPath, len(result.Rows), queryID)
+	transformed, err := results.Transform(ctx, result, scriptPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	fmt.Printf("✅ %d rows in, %d rows out (%d dimensions, %d metrics)\n",
+		len(result.Rows), transformed.RowCount, len(transformed.DimensionHeaders), len(transformed.MetricHeaders))
+
+	if outputFile != "" {
+		if format == "csv" {
+			err = results.WriteCSV(transformed, outputFile)
+		} else {
+			err = results.WriteJSON(transformed, outputFile, true)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("📁 File: %s\n", outputFile)
+		return
+	}
+
+	if transformed.RowCount > 0 {
+		fmt.Println()
+		opts := results.DefaultDisplayOptions()
+		opts.MaxRows = maxRows
+		opts.MaxColWidth = maxWidth
+		for _, line := range results.RenderTable(transformed, opts) {
+			fmt.Println(line)
+		}
+	}
+	if maxRows > 0 && transformed.RowCount > maxRows {
+		fmt.Printf("\n💡 Showing %d of %d rows; write them all with --output rows.csv\n", maxRows, transformed.RowCount)
+	}
+}
+
+func resultsChartCmd(cmd *cobra.Command, args []string) {
+	queryID := args[0]
+	x, _ := cmd.Flags().GetString("x")
+	ys, _ := cmd.Flags().GetStringSlice("y")
+	chartType, _ := cmd.Flags().GetString("type")
+	width, _ := cmd.Flags().GetInt("width")
+	limit, _ := cmd.Flags().GetInt("limit")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	result, err := resultsManager.GetResult(ctx, queryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get result: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	result = applyChannelMapping(cmd, result)
+
+	data, err := chart.FromResult(result, x, ys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+	chartType, err = chart.ResolveType(chartType, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	if chartType == chart.TypeBar {
+		total := len(data.Labels)
+		data.Truncate(limit)
+		if len(data.Labels) < total {
+			defer fmt.Printf("\n💡 Showing the first %d of %d %s values (--limit)\n", len(data.Labels), total, x)
+		}
+	}
+
+	fmt.Printf("📈 %s by %s (%s)\n\n", strings.Join(seriesNames(data), ", "), x, queryID)
+	for _, line := range chart.RenderTerminal(data, chartType, width) {
+		fmt.Println(line)
+	}
+
+	if outputFile != "" {
+		if chartType == chart.TypeBar && len(data.Series) > 1 {
+			fmt.Printf("\n⚠️  Bar chart files show one metric; writing %s only\n", data.Series[0].Name)
+		}
+		if err := chart.WriteImage(data, chartType, outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("\n✅ Chart written to %s\n", outputFile)
+	}
+}
+
+func seriesNames(data *chart.Data) []string {
+	names := make([]string, len(data.Series))
+	for i, series := range data.Series {
+		names[i] = series.Name
+	}
+	return names
+}
+
+// reshapeResult applies the --channels mapping, the --derive columns and then
+// the --pivot or --melt transform requested on cmd
+func reshapeResult(ctx context.Context, cmd *cobra.Command, result *query.QueryResult) *query.QueryResult {
+	pivot, _ := cmd.Flags().GetString("pivot")
+	melt, _ := cmd.Flags().GetBool("melt")
+	derive, _ := cmd.Flags().GetStringArray("derive")
+	if pivot != "" && melt {
+		fmt.Fprintf(os.Stderr, "Error: --pivot and --melt cannot be combined\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	result = applyChannelMapping(cmd, result)
+
+	var err error
+	if len(derive) > 0 {
+		derivations := make([]results.Derivation, len(derive))
+		for i, value := range derive {
+			if derivations[i], err = results.ParseDerivation(value); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitcode.Validation)
+			}
+		}
+		if result, err = results.Derive(ctx, result, derivations); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("🧮 Added %d derived column(s)\n", len(derivations))
+	}
+
+	switch {
+	case pivot != "":
+		result, err = results.Pivot(ctx, result, pivot)
+		if err == nil {
+			fmt.Printf("🔄 Pivoted on %s: %d rows, %d value columns\n", pivot, result.RowCount, len(result.MetricHeaders))
+		}
+	case melt:
+		result, err = results.Melt(ctx, result)
+		if err == nil {
+			fmt.Printf("🔄 Melted into %d metric/value rows\n", result.RowCount)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	return result
+}
+
+// applyChannelMapping adds the channel column of the --channels mapping, if
+// one was given on cmd
+func applyChannelMapping(cmd *cobra.Command, result *query.QueryResult) *query.QueryResult {
+	name, _ := cmd.Flags().GetString("channels")
+	if name == "" {
+		return result
+	}
+
+	mapping, err := config.GetChannelMapping(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.NotFound)
+	}
+	matcher, err := results.NewChannelMatcher(*mapping)
+	if err == nil {
+		result, err = results.ApplyChannels(result, matcher)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("🏷️  Added '%s' column from channel mapping '%s'\n", matcher.Column, name)
+	return result
+}
+
+func resultsViewCreateCmd(cmd *cobra.Command, args []string) {
+	queryID := args[0]
+	name, _ := cmd.Flags().GetString("name")
+	where, _ := cmd.Flags().GetString("where")
+	order, _ := cmd.Flags().GetString("order")
+	description, _ := cmd.Flags().GetString("description")
+
+	// Allow the unquoted form '--order sessions desc'
+	if len(args) == 2 {
+		direction := strings.ToLower(args[1])
+		if order == "" || (direction != "asc" && direction != "desc") {
+			fmt.Fprintf(os.Stderr, "Error: Unexpected argument '%s'\n", args[1])
+			fmt.Fprintf(os.Stderr, "💡 Quote the ordering, e.g. --order \"sessions desc\"\n")
+			os.Exit(exitcode.Validation)
+		}
+		order += " " + direction
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	view, err := resultsManager.CreateView(ctx, name, queryID, where, order, description)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create view: %v\n", err)
+		if errors.Is(err, cache.ErrResultViewExists) {
+			fmt.Fprintf(os.Stderr, "💡 Delete it first with 'ga4admin results view delete %s'\n", name)
+		}
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("👁️  Saved view '%s' of result %s\n", view.Name, view.QueryID)
+	printResultView(*view)
+	fmt.Printf("\n💡 Show: ga4admin results show %s\n", view.Name)
+	fmt.Printf("💡 Export: ga4admin results export %s output.csv\n", view.Name)
+}
+
+func resultsViewListCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	views, err := cacheClient.ListResultViews(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(views) == 0 {
+		fmt.Printf("❌ No saved views for property %s\n", propertyID)
+		fmt.Println("💡 Create one with 'ga4admin results view create <result-id> --where ... --name <name>'")
+		return
+	}
+
+	fmt.Printf("👁️  Saved Views (%d):\n\n", len(views))
+	for i, view := range views {
+		fmt.Printf("%s → %s\n", view.Name, view.QueryID)
+		printResultView(view)
+		if i < len(views)-1 {
+			fmt.Println()
+		}
+	}
+}
+
+func resultsViewDeleteCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	deleted, err := cacheClient.DeleteResultView(ctx, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !deleted {
+		fmt.Fprintf(os.Stderr, "Error: No view named '%s'\n", name)
+		os.Exit(exitcode.NotFound)
+	}
+	fmt.Printf("🗑️  Deleted view '%s'\n", name)
+}
+
+// printResultView prints a view's filter, ordering and description
+func printResultView(view config.ResultView) {
+	where, order := view.Where, view.OrderBy
+	if where == "" {
+		where = "(all rows)"
+	}
+	if order == "" {
+		order = "(cached order)"
+	}
+	fmt.Printf("   🔍 Where: %s\n", where)
+	fmt.Printf("   ↕️  Order: %s\n", order)
+	if view.Description != "" {
+		fmt.Printf("   📝 %s\n", view.Description)
+	}
+	fmt.Printf("   📅 Created: %s\n", view.CreatedAt.Local().Format("2006-01-02 15:04"))
+}
+
+func resultsRollupCreateCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+	source, _ := cmd.Flags().GetString("from")
+	groupBy, _ := cmd.Flags().GetStringSlice("group-by")
+	metricFlags, _ := cmd.Flags().GetStringSlice("metrics")
+	refreshEvery, _ := cmd.Flags().GetDuration("refresh-every")
+	description, _ := cmd.Flags().GetString("description")
+
+	metrics, err := results.ParseRollupMetrics(metricFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(2*time.Minute)
+	defer cancel()
+
+	rollup, err := resultsManager.CreateRollup(ctx, config.Rollup{
+		Name:         name,
+		Source:       source,
+		GroupBy:      groupBy,
+		Metrics:      metrics,
+		RefreshEvery: refreshEvery,
+		Description:  description,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create rollup: %v\n", err)
+		if errors.Is(err, cache.ErrRollupExists) {
+			fmt.Fprintf(os.Stderr, "💡 Delete it first with 'ga4admin results rollup delete %s'\n", name)
+		}
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📦 Saved rollup '%s' of %s (%d rows)\n", rollup.Name, rollup.Source, rollup.RowCount)
+	printRollup(*rollup, results.RollupFresh)
+	fmt.Printf("\n💡 Show: ga4admin results show %s\n", rollup.Name)
+	if path, err := cache.CachePath(activePreset.Name); err == nil {
+		fmt.Printf("💡 Query: SELECT * FROM %s in %s\n", cache.RollupTable(rollup.Name), path)
+	}
+}
+
+func resultsRollupListCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	rollups, err := cacheClient.ListRollups(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(rollups) == 0 {
+		fmt.Println("❌ No rollups")
+		fmt.Println("💡 Create one with 'ga4admin results rollup create <name> --from <table> --group-by ... --metrics ...'")
+		return
+	}
+
+	now := time.Now()
+	fmt.Printf("📦 Rollups (%d):\n\n", len(rollups))
+	for i, rollup := range rollups {
+		status, err := resultsManager.RollupStatus(ctx, &rollup, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("%s → %s\n", rollup.Name, rollup.Source)
+		printRollup(rollup, status)
+		if i < len(rollups)-1 {
+			fmt.Println()
+		}
+	}
+}
+
+func resultsRollupRefreshCmd(cmd *cobra.Command, args []string) {
+	force, _ := cmd.Flags().GetBool("force")
+	noFetch, _ := cmd.Flags().GetBool("no-fetch")
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	var rollups []config.Rollup
+	if len(args) == 0 {
+		all, err := cacheClient.ListRollups(ctx, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		rollups = all
+	}
+	for _, name := range args {
+		rollup, err := cacheClient.GetRollup(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if rollup == nil {
+			fmt.Fprintf(os.Stderr, "Error: No rollup named '%s'\n", name)
+			fmt.Fprintf(os.Stderr, "💡 List them with 'ga4admin results rollup list'\n")
+			os.Exit(exitcode.NotFound)
+		}
+		rollups = append(rollups, *rollup)
+	}
+	if len(rollups) == 0 {
+		fmt.Println("❌ No rollups to refresh")
+		return
+	}
+
+	// The data client is only created once a rollup needs fetching
+	var dataClient *api.DataClient
+	defer func() {
+		if dataClient != nil {
+			dataClient.Close()
+		}
+	}()
+
+	failed := 0
+	var lastErr error
+	for i := range rollups {
+		rollup := &rollups[i]
+		status, err := resultsManager.RollupStatus(ctx, rollup, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+
+		fetch := status == results.RollupStale || (force && status == results.RollupFresh)
+		switch {
+		case status == results.RollupBroken:
+			fmt.Printf("❌ %s: source '%s' no longer exists\n", rollup.Name, rollup.Source)
+			failed++
+			lastErr = fmt.Errorf("%w: %s", results.ErrNotFound, rollup.Source)
+			continue
+		case status == results.RollupFresh && !force:
+			fmt.Printf("✅ %s: fresh\n", rollup.Name)
+			continue
+		}
+
+		if fetch && !noFetch {
+			if queryID, _, _ := cacheClient.LookupNamedTable(ctx, rollup.Source); queryID == "" {
+				// Views and result IDs refer to one fixed result
+				fetch = false
+			}
+		}
+		if fetch && !noFetch {
+			if dataClient == nil {
+				dataClient, err = createDataClientWithCache()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+					os.Exit(exitcode.For(err))
+				}
+			}
+			fmt.Printf("🔄 %s: fetching '%s'...\n", rollup.Name, rollup.Source)
+			if err := resultsManager.RefetchRollupSource(ctx, dataClient, rollup); err != nil {
+				fmt.Printf("❌ %s: %v\n", rollup.Name, err)
+				failed++
+				lastErr = err
+				continue
+			}
+		}
+
+		if err := resultsManager.RebuildRollup(ctx, rollup); err != nil {
+			fmt.Printf("❌ %s: %v\n", rollup.Name, err)
+			failed++
+			lastErr = err
+			continue
+		}
+		note := ""
+		if status == results.RollupStale && (noFetch || !fetch) {
+			note = " — still stale, its source can't be re-fetched"
+			if noFetch {
+				note = " — still stale (--no-fetch)"
+			}
+		}
+		fmt.Printf("📦 %s: rebuilt from %s (%d rows)%s\n", rollup.Name, rollup.SourceQueryID, rollup.RowCount, note)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d of %d rollups failed to refresh\n", failed, len(rollups))
+		os.Exit(exitcode.For(lastErr))
+	}
+}
+
+func resultsRollupDeleteCmd(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	deleted, err := cacheClient.DeleteRollup(ctx, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if !deleted {
+		fmt.Fprintf(os.Stderr, "Error: No rollup named '%s'\n", name)
+		os.Exit(exitcode.NotFound)
+	}
+	fmt.Printf("🗑️  Deleted rollup '%s' and its table\n", name)
+}
+
+// printRollup prints a rollup's shape, status and when it was last built
+func printRollup(rollup config.Rollup, status string) {
+	groupBy := strings.Join(rollup.GroupBy, ", ")
+	if groupBy == "" {
+		groupBy = "(totals)"
+	}
+	fmt.Printf("   🧮 Group by: %s\n", groupBy)
+	fmt.Printf("   📊 Metrics: %s\n", results.FormatRollupMetrics(rollup.Metrics))
+	fmt.Printf("   🚦 Status: %s\n", status)
+	fmt.Printf("   🗄️  Table: %s (%d rows, data fetched %s)\n", cache.RollupTable(rollup.Name), rollup.RowCount, formatAge(time.Since(rollup.SourceFetchedAt)))
+	if rollup.RefreshEvery > 0 {
+		fmt.Printf("   ⏱️  Refresh every: %s\n", rollup.RefreshEvery)
+	}
+	if rollup.Description != "" {
+		fmt.Printf("   📝 %s\n", rollup.Description)
+	}
+	fmt.Printf("   📅 Built: %s\n", rollup.RefreshedAt.Local().Format("2006-01-02 15:04"))
+}
+
+func resultsStatsCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	
+	if propertyID == "" {
+		fmt.Fprintf(os.Stderr, "Error: --property flag is required\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	fmt.Printf("📈 Result Statistics for Property %s\n", propertyID)
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	stats, err := resultsManager.GetResultStats(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get stats: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display statistics
+	fmt.Printf("📊 Total Results: %d\n", stats.TotalResults)
+	fmt.Printf("✅ Active: %d • ⏰ Expired: %d\n", stats.ActiveResults, stats.ExpiredResults)
+	fmt.Printf("📈 Total Rows: %s\n", formatNumber(stats.TotalRows))
+	fmt.Printf("📊 Average Rows/Result: %.1f\n", stats.AvgRowsPerResult)
+	
+	if stats.OldestResult != nil {
+		fmt.Printf("⏰ Date Range: %s to %s\n", 
+			stats.OldestResult.Format("2006-01-02"),
+			stats.NewestResult.Format("2006-01-02"))
+	}
+	
+	fmt.Printf("📅 Generated: %s\n", stats.GeneratedAt.Format("2006-01-02 15:04:05"))
+}
+
+// Cache command handlers
+
+func cacheStatsCmd(cmd *cobra.Command, args []string) {
+	fmt.Println("💾 Cache Statistics:")
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	stats, err := cacheClient.GetCacheStats(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get cache stats: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	// Display cache statistics
+	fmt.Printf("🎯 Preset: %s\n", activePreset.Name)
+	fmt.Printf("🗄️  Backend: %s (schema v%d)\n", cache.Backend, stats.SchemaVersion)
+	fmt.Printf("✅ Cache Hits: %d\n", stats.TotalHits)
+	fmt.Printf("❌ Cache Misses: %d\n", stats.TotalMisses)
+	fmt.Printf("📊 Hit Rate: %.1f%%\n", stats.HitRate)
+	fmt.Printf("📁 Cache Entries: %d\n", stats.EntriesCount)
+	fmt.Printf("📅 Created: %s\n", stats.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("🔄 Last Updated: %s\n", stats.UpdatedAt.Format("2006-01-02 15:04:05"))
+	
+	if stats.LastCleanup != nil {
+		fmt.Printf("🧹 Last Cleanup: %s\n", stats.LastCleanup.Format("2006-01-02 15:04:05"))
+	}
+}
+
+func cachePolicyShowCmd(cmd *cobra.Command, args []string) {
+	mode, err := config.GetCacheMode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Println("💾 Cache Policy:")
+	fmt.Printf("🔧 Mode: %s\n", mode)
+	if mode == config.CacheModeSWR {
+		fmt.Println("⚡ Expired entries are served immediately and refreshed in the background")
+	} else {
+		fmt.Println("🔒 Expired entries are fetched again before results are returned")
+	}
+}
+
+func cachePolicySetCmd(cmd *cobra.Command, args []string) {
+	mode, _ := cmd.Flags().GetString("mode")
+	mode = strings.ToLower(strings.TrimSpace(mode))
+
+	if err := config.SetCacheMode(mode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Cache mode set to %s\n", mode)
+}
+
+func cacheCleanupCmd(cmd *cobra.Command, args []string) {
+	expiredOnly, _ := cmd.Flags().GetBool("expired")
+	cleanAll, _ := cmd.Flags().GetBool("all")
+
+	if cleanAll {
+		fmt.Print("⚠️  Are you sure you want to clear ALL cache entries? This cannot be undone. (y/N): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+			fmt.Println("❌ Cache cleanup cancelled")
+			return
+		}
+	}
+
+	fmt.Println("🧹 Cleaning up cache...")
+
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	// Create cache client
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	if expiredOnly || !cleanAll {
+		// Clean only expired entries
+		deleted, err := cacheClient.CleanupExpiredEntries(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cleanup failed: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("✅ Cleaned up %d expired cache entries\n", deleted)
+	} else {
+		// TODO: Implement full cache clearing if needed
+		fmt.Println("❌ Full cache clearing not yet implemented")
+		os.Exit(exitcode.Failure)
+	}
+}
+
+// Helper functions for query parsing
+
+// filterGrammarHelp documents the --filters syntax parseFilters accepts
+const filterGrammarHelp = `Filters (--filters) have the form field:type:operation:value and are
+combined with AND. Only the first three colons separate parts, so values
+may contain colons.
+
+  field:string:MATCH:value    MATCH is EXACT, CONTAINS, BEGINS_WITH,
+                              ENDS_WITH, FULL_REGEXP or PARTIAL_REGEXP
+                              (STARTS_WITH and REGEX also work); add /cs
+                              for a case-sensitive match, e.g. EXACT/cs
+  field:numeric:OP:number     OP is EQUAL, GREATER_THAN, LESS_THAN,
+                              GREATER_THAN_OR_EQUAL or LESS_THAN_OR_EQUAL
+  field:between:from:to       from <= field <= to, with from below to
+  field:in_list:CASE:a|b|c    field is one of the |-separated values;
+                              CASE is CS (case-sensitive) or CI
+
+Prefix the type with ! to negate a filter, e.g. country:!in_list:CI:US|CA.
+
+String filters also have a short form, case-insensitive unless /cs comes
+right before the operator:
+
+  field==value    exact match         field!=value    not an exact match
+  field=~regex    regex matches       field!~regex    regex doesn't match
+                  part of the value
+
+Examples:
+  sessionSource:string:CONTAINS:google
+  sessions:between:10:100
+  country:in_list:CI:US|CA|GB
+  deviceCategory!=mobile
+  pagePath/cs=~^/Blog/`
+
+// inListCaseSensitivity maps the in_list operation to case sensitivity.
+// CS_true and CI_false spell out the same choice.
+var inListCaseSensitivity = map[string]bool{
+	"CS":       true,
+	"CS_TRUE":  true,
+	"CI":       false,
+	"CI_FALSE": false,
+}
+
+// shortFilterOperators are the string filter short forms, mapped to their
+// match type and whether they negate it
+var shortFilterOperators = []struct {
+	operator  string
+	matchType string
+	negate    bool
+}{
+	{"==", "EXACT", false},
+	{"!=", "EXACT", true},
+	{"=~", "PARTIAL_REGEXP", false},
+	{"!~", "PARTIAL_REGEXP", true},
+}
+
+// caseSensitiveSuffix marks a case-sensitive string match
+const caseSensitiveSuffix = "/cs"
+
+// parseShortFilter parses the field==value short forms. ok is false if the
+// filter isn't one, e.g. because it uses the field:type:operation:value form.
+func parseShortFilter(filterStr string) (filter query.FilterConfig, ok bool) {
+	at := -1
+	for _, short := range shortFilterOperators {
+		i := strings.Index(filterStr, short.operator)
+		if i <= 0 || (at >= 0 && i >= at) {
+			continue
+		}
+		at = i
+		filter = query.FilterConfig{
+			FieldName:       strings.TrimSpace(filterStr[:i]),
+			Type:            "string",
+			StringMatchType: short.matchType,
+			StringValue:     filterStr[i+len(short.operator):],
+		}
+		if short.negate {
+			filter.LogicOperator = "NOT"
+		}
+	}
+	if at < 0 {
+		return query.FilterConfig{}, false
+	}
+	if field, found := cutSuffixFold(filter.FieldName, caseSensitiveSuffix); found {
+		filter.FieldName = strings.TrimSpace(field)
+		filter.StringCaseSensitive = true
+	}
+	// Field names have at most one colon, as in customEvent:plan
+	if filter.FieldName == "" || strings.Count(filter.FieldName, ":") > 1 || strings.ContainsAny(filter.FieldName, " /=!~") {
+		return query.FilterConfig{}, false
+	}
+
+	filter.StringValue = strings.TrimSpace(filter.StringValue)
+	return filter, true
+}
+
+// cutSuffixFold is strings.CutSuffix ignoring case
+func cutSuffixFold(s, suffix string) (string, bool) {
+	if len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix) {
+		return s[:len(s)-len(suffix)], true
+	}
+	return s, false
+}
+
+func parseFilters(filterStrings []string) ([]query.FilterConfig, error) {
+	filters := make([]query.FilterConfig, 0, len(filterStrings))
+	
+	for _, filterStr := range filterStrings {
+		if filter, ok := parseShortFilter(filterStr); ok {
+			if filter.StringValue == "" {
+				return nil, fmt.Errorf("filter needs a value, got: %s", filterStr)
+			}
+			filters = append(filters, filter)
+			continue
+		}
+
+		parts := strings.SplitN(filterStr, ":", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("filter must have format 'field:type:operation:value' or 'field==value', got: %s", filterStr)
+		}
+
+		filter := query.FilterConfig{
+			FieldName: strings.TrimSpace(parts[0]),
+			Type:      strings.ToLower(strings.TrimSpace(parts[1])),
+		}
+		if negated := strings.TrimPrefix(filter.Type, "!"); negated != filter.Type {
+			filter.Type = negated
+			filter.LogicOperator = "NOT"
+		}
+
+		operation := strings.TrimSpace(parts[2])
+		value := strings.TrimSpace(parts[3])
+
+		switch filter.Type {
+		case "string":
+			operation, filter.StringCaseSensitive = cutSuffixFold(operation, caseSensitiveSuffix)
+			filter.StringMatchType = strings.ToUpper(operation)
+			filter.StringValue = value
+		case "numeric":
+			filter.NumericOperation = operation
+			if numValue, err := strconv.ParseFloat(value, 64); err == nil {
+				filter.NumericValue = numValue
+			} else {
+				return nil, fmt.Errorf("invalid numeric value: %s", value)
+			}
+		case "between":
+			from, err := strconv.ParseFloat(operation, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid between lower bound: %s", operation)
+			}
+			to, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid between upper bound: %s", value)
+			}
+			filter.BetweenFrom = from
+			filter.BetweenTo = to
+		case "in_list":
+			caseSensitive, ok := inListCaseSensitivity[strings.ToUpper(operation)]
+			if !ok {
+				return nil, fmt.Errorf("in_list case must be CS or CI, got: %s", operation)
+			}
+			filter.InListCaseSensitive = caseSensitive
+			for _, listValue := range strings.Split(value, "|") {
+				if listValue = strings.TrimSpace(listValue); listValue != "" {
+					filter.InListValues = append(filter.InListValues, listValue)
+				}
+			}
+			if len(filter.InListValues) == 0 {
+				return nil, fmt.Errorf("in_list needs at least one value, got: %s", filterStr)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter type: %s", filter.Type)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// parseMetrics splits plain metric names from 'name=expression' calculated metrics
+func parseMetrics(metricStrings []string) ([]string, []query.CalculatedMetric, error) {
+	var metrics []string
+	var calculatedMetrics []query.CalculatedMetric
+
+	for _, metricStr := range metricStrings {
+		metricStr = strings.TrimSpace(metricStr)
+		if !strings.Contains(metricStr, "=") {
+			metrics = append(metrics, metricStr)
+			continue
+		}
+
+		calculated, err := query.ParseCalculatedMetric(metricStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		calculatedMetrics = append(calculatedMetrics, calculated)
+	}
+
+	return metrics, calculatedMetrics, nil
+}
+
+// validateQueryFields checks field names against property metadata and exits
+// with did-you-mean suggestions for unknown ones
+func validateQueryFields(dataClient *api.DataClient, config *query.QueryConfig) {
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	metadata, err := dataClient.GetMetadata(ctx, config.PropertyID)
+	if err != nil {
+		return
+	}
+
+	if err := query.ValidateFieldNames(config, metadata); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query validation failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin fields describe <field>' or 'ga4admin metadata dimensions --property %s' to browse fields\n", config.PropertyID)
+		os.Exit(exitcode.For(err))
+	}
+}
+
+// runQueryStream executes a query page by page straight into a CSV file
+func runQueryStream(executor *query.Executor, config *query.QueryConfig, outputFile string, pageSize, maxRows int64) {
+	// Streamed pages never land in the cache, so there is no {query} to fill
+	outputFile, err := results.ExpandPath(outputFile, &query.QueryResult{
+		PropertyID:  config.PropertyID,
+		QueryConfig: config,
+		ExecutedAt:  time.Now(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --export-stream: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	writer, err := results.NewStreamWriter(outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	summary, err := executor.ExecuteStream(ctx, config, pageSize, maxRows, func(page *api.RunReportResponse) error {
+		if err := writer.WritePage(page); err != nil {
+			return err
+		}
+		fmt.Printf("📥 %s / %s rows written\n", formatNumber(writer.Rows()), formatNumber(int64(page.RowCount)))
+		return nil
+	})
+	if err != nil {
+		writer.Abort()
+		fmt.Fprintf(os.Stderr, "Error: Streamed export failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "💡 %s rows were kept in %s.partial\n", formatNumber(writer.Rows()), outputFile)
+		os.Exit(exitcode.For(err))
+	}
+
+	checksum, err := writer.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Streamed %s rows in %d page(s) (%s)\n", formatNumber(summary.Rows), summary.Pages, time.Since(start).Round(time.Millisecond))
+	if maxRows > 0 && summary.Rows < summary.TotalRows {
+		fmt.Printf("⚠️  Stopped at the %s row limit; %s rows match the query\n", formatNumber(maxRows), formatNumber(summary.TotalRows))
+	}
+	fmt.Printf("📁 File: %s\n", outputFile)
+	fmt.Printf("🔒 SHA-256 (all lines above the footer): %s\n", checksum)
+}
+
+func parseAggregations(aggregationStrings []string) ([]string, error) {
+	aggregations := make([]string, 0, len(aggregationStrings))
+
+	for _, aggregationStr := range aggregationStrings {
+		aggregation := strings.ToUpper(strings.TrimSpace(aggregationStr))
+		if !query.IsValidMetricAggregation(aggregation) {
+			return nil, fmt.Errorf("unsupported aggregation '%s' (use total, minimum, maximum or count)", aggregationStr)
+		}
+		aggregations = append(aggregations, aggregation)
+	}
+
+	return aggregations, nil
+}
+
+// printAggregateRows prints totals/minimums/maximums rows returned for metric aggregations
+func printAggregateRows(label string, rows []api.Row, headers []api.MetricHeader) {
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s:\n", label)
+	for _, row := range rows {
+		for i, value := range row.MetricValues {
+			name := fmt.Sprintf("metric%d", i)
+			if i < len(headers) {
+				name = headers[i].Name
+			}
+			fmt.Printf("   %-25s %s\n", name, value.Value)
+		}
+	}
+}
+
+// parseOrderBys parses a comma-separated --order-by list. GA4 sorts by the
+// first field, then breaks ties with the next.
+func parseOrderBys(orderByStr string, config *query.QueryConfig) ([]query.OrderByConfig, error) {
+	var orderBys []query.OrderByConfig
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(orderByStr, ",") {
+		if strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("empty field in '%s'", orderByStr)
+		}
+		orderBy, err := parseOrderBy(strings.TrimSpace(field), config)
+		if err != nil {
+			return nil, err
+		}
+		if seen[orderBy.FieldName] {
+			return nil, fmt.Errorf("field '%s' is ordered by more than once", orderBy.FieldName)
+		}
+		seen[orderBy.FieldName] = true
+		orderBys = append(orderBys, *orderBy)
+	}
+	return orderBys, nil
+}
+
+func parseOrderBy(orderByStr string, config *query.QueryConfig) (*query.OrderByConfig, error) {
+	orderBy := &query.OrderByConfig{}
+	
+	// Check for descending order (prefix with -)
+	if strings.HasPrefix(orderByStr, "-") {
+		orderBy.Descending = true
+		orderByStr = orderByStr[1:]
+	}
+
+	orderBy.FieldName = strings.TrimSpace(orderByStr)
+
+	// Determine field type
+	for _, dim := range config.Dimensions {
+		if dim == orderBy.FieldName {
+			orderBy.FieldType = "dimension"
+			return orderBy, nil
+		}
+	}
+
+	for _, metric := range config.MetricNames() {
+		if metric == orderBy.FieldName {
+			orderBy.FieldType = "metric"
+			return orderBy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("field '%s' not found in dimensions or metrics", orderBy.FieldName)
+}
+
+func exportParseCmd(cmd *cobra.Command, args []string) {
+	inputDir, _ := cmd.Flags().GetString("input-dir")
+	outputDB, _ := cmd.Flags().GetString("output-db")
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+	label, _ := cmd.Flags().GetString("label")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	validateOnly, _ := cmd.Flags().GetBool("validate-only")
+
+	if dryRun || validateOnly {
+		scanParseInput(inputDir, outputDB, validateOnly)
+		return
+	}
+
+	fmt.Printf("📦 Parsing JSON files from %s into DuckDB\n", inputDir)
+	fmt.Printf("🎯 Output database: %s\n", outputDB)
+	fmt.Printf("⚡ Batch size: %d files\n", batchSize)
+
+	// Create parser
+	parser := export.NewJSONParser(outputDB, inputDir)
+	parser.SetBatchSize(batchSize)
+	parser.SetLabel(label)
+
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	// Start parsing
+	start := time.Now()
+	if err := parser.ParseAllJSON(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to parse JSON files: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	duration := time.Since(start)
+	fmt.Printf("\n✅ Parsing completed in %v\n", duration)
+	fmt.Printf("🗄️  Database ready for analysis: %s\n", outputDB)
+	fmt.Println("\n💡 Try these analysis queries:")
+	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM dimension_summary;\"")
+	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM property_analysis LIMIT 10;\"")
+	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM account_rollup;\"")
+	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM property_platforms WHERE platform_mix = 'app_only';\"")
+	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM dimension_changes;\"")
+}
+
+func exportRunsCmd(cmd *cobra.Command, args []string) {
+	dbPath, _ := cmd.Flags().GetString("db")
+
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	runs, err := export.ListRuns(ctx, dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list collection runs: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("📭 No collection runs in %s\n", dbPath)
+		return
+	}
+
+	fmt.Printf("🗂️  Collection runs in %s:\n\n", dbPath)
+	fmt.Printf("   %-4s %-19s %-10s %10s %10s %8s  %s\n", "RUN", "STARTED", "STATUS", "PROPERTIES", "DIMENSIONS", "STREAMS", "LABEL")
+	for _, run := range runs {
+		status := "complete"
+		if run.FinishedAt == nil {
+			status = "incomplete"
+		} else if run.FilesFailed > 0 {
+			status = fmt.Sprintf("%d failed", run.FilesFailed)
+		}
+		fmt.Printf("   %-4d %-19s %-10s %10d %10d %8d  %s\n",
+			run.RunID, run.StartedAt.Local().Format("2006-01-02 15:04:05"), status,
+			run.Properties, run.CustomDimensions, run.DataStreams, run.Label)
+	}
+
+	fmt.Printf("\n📊 %d run(s)\n", len(runs))
+	fmt.Println("\n💡 Compare runs:")
+	fmt.Println("   duckdb", dbPath, "-c \"SELECT * FROM run_summary;\"")
+	fmt.Println("   duckdb", dbPath, "-c \"SELECT * FROM dimension_changes WHERE run_id = <run>;\"")
+	fmt.Printf("   ga4admin export compare-runs --db %s --from <run> --to <run>\n", dbPath)
+}
+
+func exportCompareRunsCmd(cmd *cobra.Command, args []string) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	fromRun, _ := cmd.Flags().GetInt64("from")
+	toRun, _ := cmd.Flags().GetInt64("to")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --format must be text or json\n")
+		os.Exit(exitcode.Validation)
+	}
+	if fromRun != 0 && toRun != 0 && fromRun >= toRun {
+		fmt.Fprintf(os.Stderr, "Error: --from must be an earlier run than --to\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	comparison, err := export.CompareRuns(ctx, dbPath, fromRun, toRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to compare collection runs: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(comparison); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		return
+	}
+
+	describeRun := func(run export.CollectionRun) string {
+		description := fmt.Sprintf("run %d (%s", run.RunID, run.StartedAt.Local().Format("2006-01-02 15:04"))
+		if run.Label != "" {
+			description += ", " + run.Label
+		}
+		return description + ")"
+	}
+	describeProperty := func(property export.PropertyRef) string {
+		return fmt.Sprintf("%s %s (%s)", property.PropertyID, property.PropertyName, property.AccountName)
+	}
+
+	from, to := comparison.From, comparison.To
+	fmt.Printf("🔀 Comparing %s → %s\n", describeRun(from), describeRun(to))
+
+	fmt.Printf("\n🏢 Properties: %d → %d (+%d gained, -%d lost)\n",
+		from.Properties, to.Properties, len(comparison.Gained), len(comparison.Lost))
+	for _, property := range comparison.Gained {
+		fmt.Printf("   ➕ %s\n", describeProperty(property))
+	}
+	for _, property := range comparison.Lost {
+		fmt.Printf("   ➖ %s\n", describeProperty(property))
+	}
+
+	fmt.Printf("\n📐 Custom dimensions: %d → %d (%+d)\n",
+		from.CustomDimensions, to.CustomDimensions, to.CustomDimensions-from.CustomDimensions)
+	if len(comparison.DimensionDeltas) == 0 {
+		fmt.Println("   No changes in dimension counts for properties in both runs")
+	}
+	for _, delta := range comparison.DimensionDeltas {
+		fmt.Printf("   %-50s %4d → %-4d (%+d)\n", describeProperty(delta.PropertyRef), delta.FromCount, delta.ToCount, delta.Delta)
+	}
+
+	fmt.Println("\n🎯 Clarisights readiness:")
+	if len(comparison.ReadinessChanges) == 0 {
+		fmt.Println("   No changes for properties in both runs")
+	}
+	for _, change := range comparison.ReadinessChanges {
+		if change.Ready {
+			fmt.Printf("   ✅ %s now ready (channel group: %s)\n", describeProperty(change.PropertyRef), change.ToChannelGroup)
+		} else {
+			fmt.Printf("   ❌ %s no longer ready (was: %s)\n", describeProperty(change.PropertyRef), change.FromChannelGroup)
+		}
+	}
+
+	fmt.Printf("\n📊 %d gained, %d lost, %d with changed dimension counts, %d unchanged, %d readiness changes\n",
+		len(comparison.Gained), len(comparison.Lost), len(comparison.DimensionDeltas), comparison.Unchanged, len(comparison.ReadinessChanges))
+	fmt.Println("\n💡 Per-dimension detail:")
+	fmt.Println("   duckdb", dbPath, "-c \"SELECT * FROM dimension_changes;\"")
+}
+
+// scanParseInput reports what 'export parse-json' would do without writing the
+// database. With validateOnly it only lists malformed files, exiting non-zero
+// when there are any.
+func scanParseInput(inputDir, outputDB string, validateOnly bool) {
+	if validateOnly {
+		fmt.Printf("🔍 Validating JSON files in %s\n", inputDir)
+	} else {
+		fmt.Printf("🔍 Dry run: scanning JSON files in %s (nothing is written)\n", inputDir)
+	}
+
+	ctx, cancel := commandContext(30*time.Minute)
+	defer cancel()
+
+	scan, err := export.NewJSONParser(outputDB, inputDir).Scan(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if !validateOnly {
+		fmt.Printf("\n📁 %d JSON file(s): %d parseable, %d malformed\n", scan.Files, scan.Valid(), len(scan.Malformed))
+
+		if len(scan.Variants) > 0 {
+			fmt.Println("\n🧬 Schema variants:")
+			for _, variant := range scan.VariantNames() {
+				fmt.Printf("   %6d  %s\n", scan.Variants[variant], variant)
+			}
+		}
+
+		fmt.Println("\n📊 Estimated rows per table:")
+		for _, table := range export.ParsedTables {
+			fmt.Printf("   %-24s %8s\n", table, formatNumber(int64(scan.Rows[table])))
+		}
+	}
 
-	fmt.Printf("➕ Creating preset '%s'...\n", presetName)
+	if len(scan.Malformed) > 0 {
+		fmt.Println("\n❌ Malformed files (skipped when parsing):")
+		for _, malformed := range scan.Malformed {
+			fmt.Printf("   %s: %v\n", malformed.Path, malformed.Err)
+		}
+	}
 
-	// Validate OAuth credentials are configured
-	hasCredentials, err := config.HasClientCredentials()
+	fmt.Println()
+	if validateOnly {
+		if len(scan.Malformed) > 0 {
+			fmt.Printf("📊 %d of %d JSON file(s) malformed\n", len(scan.Malformed), scan.Files)
+			os.Exit(exitcode.Validation)
+		}
+		fmt.Printf("✅ All %d JSON file(s) can be parsed\n", scan.Files)
+		return
+	}
+	fmt.Printf("💡 Run without --dry-run to write %s\n", outputDB)
+}
+
+// fetchChannelGroups lists a property's channel groups, optionally narrowed to
+// one group ID or to custom groups only. Exits on error.
+func fetchChannelGroups(propertyID, groupID string, includeSystem bool) []api.ChannelGroup {
+	// Get active preset
+	activePreset, err := preset.GetActivePreset()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to check OAuth configuration: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	if !hasCredentials {
-		fmt.Fprintf(os.Stderr, "Error: OAuth client credentials not configured\n")
-		fmt.Fprintf(os.Stderr, "💡 Run 'ga4admin config set --client-id <id> --client-secret <secret>' first\n")
-		os.Exit(1)
+
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Validate refresh token (unless --no-validate is specified)
-	if !noValidate {
-		fmt.Println("🔍 Validating refresh token...")
-		
-		// Create auth client for validation
-		authClient, err := api.NewAuthClient()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to create auth client for validation: %v\n", err)
-			os.Exit(1)
-		}
+	ensurePropertyAccess(activePreset, propertyID)
 
-		// Test the refresh token
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 
-		if err := authClient.ValidateRefreshToken(ctx, refreshToken); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Refresh token validation failed: %v\n", err)
-			fmt.Fprintf(os.Stderr, "\n💡 Common issues:\n")
-			fmt.Fprintf(os.Stderr, "   - Token has expired or been revoked\n")
-			fmt.Fprintf(os.Stderr, "   - Token doesn't have required GA4 permissions\n")
-			fmt.Fprintf(os.Stderr, "   - Network connectivity issues\n")
-			fmt.Fprintf(os.Stderr, "\n🔧 To skip validation: add --no-validate flag\n")
-			os.Exit(1)
-		}
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
 
-		fmt.Println("✅ Refresh token is valid!")
-	} else {
-		fmt.Println("⚠️  Skipping token validation (--no-validate specified)")
+	groups, err := adminClient.ListChannelGroups(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list channel groups: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Create the preset
-	if err := preset.CreatePreset(presetName, refreshToken, userEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create preset: %v\n", err)
-		os.Exit(1)
+	var selected []api.ChannelGroup
+	for _, group := range groups {
+		if groupID != "" {
+			if group.ID() == groupID {
+				selected = append(selected, group)
+			}
+			continue
+		}
+		if group.SystemDefined && !includeSystem {
+			continue
+		}
+		selected = append(selected, group)
 	}
 
-	// Get preset path for display
-	presetPath, _ := preset.GetPresetPath(presetName)
-	fmt.Printf("✅ Preset '%s' created successfully\n", presetName)
-	fmt.Printf("📁 Preset file: %s\n", presetPath)
-	if userEmail != "" {
-		fmt.Printf("👤 User email: %s\n", userEmail)
-	}
-	
-	if noValidate {
-		fmt.Println("⚠️  Remember: Token was not validated - test with API commands")
+	if groupID != "" && len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: Channel group %s not found on property %s\n", groupID, propertyID)
+		os.Exit(exitcode.NotFound)
 	}
-	fmt.Println("🚀 You can now use 'ga4admin preset use " + presetName + "' to activate it")
+
+	return selected
 }
 
-func presetListCmdHandler(cmd *cobra.Command, args []string) {
-	fmt.Println("📝 Available GA4 Presets:")
-	fmt.Println()
+func customDimsApplyCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	specPath, _ := cmd.Flags().GetString("file")
+	keepUnlisted, _ := cmd.Flags().GetBool("keep-unlisted")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
 
-	// Get active preset name
-	activePresetName, err := config.GetActivePreset()
+	specs, err := customdims.LoadSpecFile(specPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get active preset: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Load all presets
-	presets, err := preset.ListPresets()
+	activePreset, err := preset.GetActivePreset()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to list presets: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
+	ensurePropertyAccess(activePreset, propertyID)
 
-	if len(presets) == 0 {
-		fmt.Println("❌ No presets found")
-		fmt.Println()
-		fmt.Println("💡 Create your first preset with:")
-		fmt.Println("   ga4admin preset create <name> --refresh-token <token>")
-		return
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	defer enableAuditLog(cmd, adminClient, activePreset.Name)()
 
-	// Display presets
-	for i, p := range presets {
-		// Active preset indicator
-		activeIndicator := "  "
-		if p.Name == activePresetName {
-			activeIndicator = "▶️ "
-		}
+	ctx, cancel := commandContext(300*time.Second)
+	defer cancel()
 
-		fmt.Printf("%s📋 %s\n", activeIndicator, p.Name)
-		
-		// User email if available
-		if p.UserEmail != "" {
-			fmt.Printf("   👤 %s\n", p.UserEmail)
-		}
+	existing, err := adminClient.ListCustomDimensions(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list custom dimensions: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 
-		// Account count
-		accountCount := len(p.Accounts)
-		if accountCount > 0 {
-			fmt.Printf("   🏢 %d account(s)\n", accountCount)
+	plan := customdims.BuildPlan(propertyID, specs, existing, keepUnlisted)
+
+	fmt.Printf("📋 Custom dimension plan for property %s (%d in spec, %d registered)\n\n", propertyID, len(specs), len(existing))
+	for _, change := range plan.Changes {
+		icon := "✔️ "
+		switch change.Action {
+		case customdims.ActionCreate:
+			icon = "➕"
+		case customdims.ActionArchive:
+			icon = "🗄️ "
 		}
+		fmt.Printf("   %s %-8s %-5s %-32s %s\n", icon, change.Action, change.Dimension.Scope, change.Dimension.ParameterName, change.Dimension.DisplayName)
+		if change.Note != "" {
+			fmt.Printf("      ⚠️  %s\n", change.Note)
+		}
+	}
 
-		// Timestamps
-		fmt.Printf("   📅 Created: %s\n", p.CreatedAt.Format("2006-01-02 15:04"))
-		fmt.Printf("   🔄 Last used: %s\n", p.LastUsed.Format("2006-01-02 15:04"))
+	creates := plan.Count(customdims.ActionCreate)
+	archives := plan.Count(customdims.ActionArchive)
+	fmt.Printf("\n📊 %d to create, %d to skip, %d to archive\n", creates, plan.Count(customdims.ActionSkip), archives)
+	for _, warning := range plan.LimitWarnings() {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
 
-		// Add spacing between presets
-		if i < len(presets)-1 {
-			fmt.Println()
+	if creates == 0 && archives == 0 {
+		fmt.Println("✅ Property already matches the spec")
+		return
+	}
+	if dryRun {
+		fmt.Println("💡 Dry run - nothing was changed")
+		return
+	}
+	ensureEditScope(activePreset)
+
+	if !skipConfirm {
+		if archives > 0 {
+			fmt.Printf("⚠️  Archived custom dimensions can't be restored.\n")
+		}
+		fmt.Printf("Apply these changes to property %s? (y/N): ", propertyID)
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("❌ No changes applied")
+			return
 		}
 	}
 
 	fmt.Println()
-	fmt.Println("💡 Use 'ga4admin preset use <name>' to set active preset")
+	scopeHint := false
+	failed := customdims.Apply(ctx, adminClient, plan, func(change customdims.Change, err error) {
+		if err != nil {
+			fmt.Printf("   ❌ %s %s: %v\n", change.Action, change.Dimension.ParameterName, err)
+			var apiErr *api.APIError
+			if errors.As(err, &apiErr) && apiErr.IsInsufficientScope() {
+				scopeHint = true
+			}
+			return
+		}
+		if change.Action == customdims.ActionArchive {
+			fmt.Printf("   🗄️  Archived %s (%s)\n", change.Dimension.ParameterName, change.Dimension.ID())
+		} else {
+			fmt.Printf("   ➕ Created %s (%s)\n", change.Dimension.ParameterName, change.Dimension.ID())
+		}
+	})
+
+	fmt.Println()
+	if scopeHint {
+		fmt.Fprintf(os.Stderr, "💡 The preset's refresh token lacks the %s scope - run 'ga4admin preset reauth %s --edit'\n", api.ScopeName(api.AnalyticsEditScope), activePreset.Name)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d of %d changes failed\n", failed, creates+archives)
+		os.Exit(exitcode.Failure)
+	}
+	fmt.Printf("✅ Applied %d changes to property %s\n", creates+archives, propertyID)
 }
 
-func presetDeleteCmdHandler(cmd *cobra.Command, args []string) {
-	presetName := args[0]
+func applyCmdHandler(cmd *cobra.Command, args []string) {
+	filePath, _ := cmd.Flags().GetString("file")
+	propertyID, _ := cmd.Flags().GetString("property")
+	keepUnlisted, _ := cmd.Flags().GetBool("keep-unlisted")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
 
-	// Check if preset exists
-	exists, err := preset.PresetExists(presetName)
+	file, err := propertyspec.Load(filePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to check preset: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
 	}
-	if !exists {
-		fmt.Fprintf(os.Stderr, "Error: Preset '%s' does not exist\n", presetName)
-		os.Exit(1)
+
+	if propertyID == "" {
+		if file.Property == "" {
+			fmt.Fprintf(os.Stderr, "Error: %s doesn't name a property - add 'property:' to the file or pass --property\n", filePath)
+			os.Exit(exitcode.Validation)
+		}
+		if propertyID, err = resolvePropertyReference(file.Property); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: property: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
 	}
 
-	// Confirmation prompt
-	fmt.Printf("⚠️  Are you sure you want to delete preset '%s'? (y/N): ", presetName)
-	var response string
-	fmt.Scanln(&response)
-	
-	response = strings.ToLower(strings.TrimSpace(response))
-	if response != "y" && response != "yes" {
-		fmt.Println("❌ Deletion cancelled")
-		return
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
+	ensurePropertyAccess(activePreset, propertyID)
 
-	// Delete the preset
-	if err := preset.DeletePreset(presetName); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to delete preset: %v\n", err)
-		os.Exit(1)
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	defer enableAuditLog(cmd, adminClient, activePreset.Name)()
 
-	fmt.Printf("✅ Preset '%s' deleted successfully\n", presetName)
-}
+	ctx, cancel := commandContext(300*time.Second)
+	defer cancel()
 
-func presetUseCmdHandler(cmd *cobra.Command, args []string) {
-	presetName := args[0]
+	state, err := propertyspec.FetchState(ctx, adminClient, propertyID, file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 
-	// Set active preset
-	if err := preset.SetActivePreset(presetName); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to set active preset: %v\n", err)
-		os.Exit(1)
+	plan := propertyspec.BuildPlan(propertyID, file, state, keepUnlisted)
+
+	fmt.Printf("📋 Plan for property %s from %s\n\n", propertyID, filePath)
+	for _, change := range plan.Changes {
+		fmt.Printf("   %s %s\n", change.Action.Symbol(), change)
+	}
+	if len(plan.Changes) > 0 {
+		fmt.Println()
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
 	}
 
-	fmt.Printf("✅ Activated preset '%s'\n", presetName)
-	fmt.Println("🚀 You can now use GA4 API commands")
+	if len(plan.Changes) == 0 {
+		fmt.Println("✅ Property already matches the file")
+		return
+	}
+	fmt.Printf("📊 Plan: %s\n", plan.Summary())
+	if dryRun {
+		fmt.Println("💡 Dry run - nothing was changed")
+		return
+	}
+	ensureEditScope(activePreset)
+
+	if !skipConfirm {
+		if plan.Count(propertyspec.ActionArchive) > 0 {
+			fmt.Printf("⚠️  Archived custom dimensions can't be restored.\n")
+		}
+		fmt.Printf("Apply these changes to property %s? (y/N): ", propertyID)
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("❌ No changes applied")
+			return
+		}
+	}
+
+	fmt.Println()
+	done := map[propertyspec.Action]string{
+		propertyspec.ActionCreate:  "Created",
+		propertyspec.ActionUpdate:  "Updated",
+		propertyspec.ActionDelete:  "Deleted",
+		propertyspec.ActionArchive: "Archived",
+	}
+	scopeHint := false
+	failed := plan.Apply(ctx, adminClient, func(change propertyspec.Change, err error) {
+		if err != nil {
+			fmt.Printf("   ❌ %s %s %s: %v\n", change.Action, change.Resource, change.Name, err)
+			var apiErr *api.APIError
+			if errors.As(err, &apiErr) && apiErr.IsInsufficientScope() {
+				scopeHint = true
+			}
+			return
+		}
+		fmt.Printf("   ✅ %s %s %s\n", done[change.Action], change.Resource, change.Name)
+	})
+
+	fmt.Println()
+	if scopeHint {
+		fmt.Fprintf(os.Stderr, "💡 The preset's refresh token lacks the %s scope - run 'ga4admin preset reauth %s --edit'\n", api.ScopeName(api.AnalyticsEditScope), activePreset.Name)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d of %d changes failed\n", failed, len(plan.Changes))
+		os.Exit(exitcode.Failure)
+	}
+	fmt.Printf("✅ Applied %d changes to property %s\n", len(plan.Changes), propertyID)
 }
 
-func accountsListCmd(cmd *cobra.Command, args []string) {
-	fmt.Println("🏢 Listing GA4 accounts...")
+func auditListCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	days, _ := cmd.Flags().GetInt("days")
+	limit, _ := cmd.Flags().GetInt("limit")
+	details, _ := cmd.Flags().GetBool("details")
+
+	if days <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --days must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
 
-	accounts, err := getAccountsWithClient()
+	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	if len(accounts) == 0 {
-		fmt.Println("❌ No GA4 accounts found")
-		fmt.Println("💡 Ensure the refresh token has GA4 read permissions")
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
+
+	ctx, cancel := commandContext(30*time.Second)
+	defer cancel()
+
+	records, err := cacheClient.ListAuditLog(ctx, propertyID, time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to read audit log: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	scope := "all properties"
+	if propertyID != "" {
+		scope = "property " + propertyID
+	}
+	fmt.Printf("📜 Configuration changes for %s (last %d days, preset %s)\n\n", scope, days, activePreset.Name)
+	if len(records) == 0 {
+		fmt.Println("📭 No changes recorded - write commands such as 'ga4admin apply' record here")
 		return
 	}
 
-	// Display accounts
-	fmt.Printf("📊 Found %d account(s):\n\n", len(accounts))
-	for i, account := range accounts {
-		fmt.Printf("🏢 %s (ID: %s)\n", account.DisplayName, account.ID)
-		fmt.Printf("   🌍 Region: %s\n", account.RegionCode)
-		fmt.Printf("   📅 Created: %s\n", account.CreateTime.Format("2006-01-02"))
-		
-		if i < len(accounts)-1 {
-			fmt.Println()
+	for _, record := range records {
+		status := "✅"
+		if record.Error != "" {
+			status = "❌"
+		}
+		fmt.Printf("%s %s  %-6s %s\n", status, record.RecordedAt.Local().Format("2006-01-02 15:04:05"), record.Method, record.Resource)
+		fmt.Printf("   by %s via '%s'\n", record.User, record.Command)
+		if record.Error != "" {
+			fmt.Printf("   error: %s\n", record.Error)
+		}
+		if details {
+			if record.Request != "" {
+				fmt.Printf("   request: %s\n", record.Request)
+			}
+			if record.Before != "" {
+				fmt.Printf("   before:  %s\n", record.Before)
+			}
+			if record.After != "" {
+				fmt.Printf("   after:   %s\n", record.After)
+			}
 		}
 	}
-
-	fmt.Println("\n💡 Use 'ga4admin accounts tree' for hierarchical view")
-	fmt.Println("💡 Use 'ga4admin properties list --account <id>' to see properties")
+
+	fmt.Printf("\n📊 %d changes listed\n", len(records))
+	if !details {
+		fmt.Println("💡 Use --details to see the before and after state")
+	}
 }
 
-func accountsTreeCmd(cmd *cobra.Command, args []string) {
-	fmt.Println("🌳 GA4 Account & Property Tree:")
-	fmt.Println()
+func propertiesChangelogCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	days, _ := cmd.Flags().GetInt("days")
+	propertyID, _ := cmd.Flags().GetString("property")
+	resourceTypes, _ := cmd.Flags().GetStringSlice("resource-type")
+	actions, _ := cmd.Flags().GetStringSlice("action")
+	actors, _ := cmd.Flags().GetStringSlice("actor")
+	details, _ := cmd.Flags().GetBool("details")
 
-	// Get accounts
-	accounts, err := getAccountsWithClient()
+	if days <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --days must be positive\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
-
-	if len(accounts) == 0 {
-		fmt.Println("❌ No GA4 accounts found")
-		fmt.Println("💡 Ensure the refresh token has GA4 read permissions")
-		return
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
+	// Change history is only served to tokens with the edit scope
+	ensureEditScope(activePreset)
 
-	// Create Admin API client
 	adminClient, err := api.NewAdminClient()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := commandContext(60*time.Second)
 	defer cancel()
 
-	// Display accounts with properties in tree format
-	for accountIndex, account := range accounts {
-		// Account level
-		isLastAccount := accountIndex == len(accounts)-1
-		accountPrefix := "├── "
-		childPrefix := "│   "
-		if isLastAccount {
-			accountPrefix = "└── "
-			childPrefix = "    "
+	events, err := adminClient.SearchChangeHistory(ctx, accountID, api.ChangeHistoryQuery{
+		PropertyID:    propertyID,
+		Since:         time.Now().AddDate(0, 0, -days),
+		ResourceTypes: resourceTypes,
+		Actions:       actions,
+		ActorEmails:   actors,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to search change history: %v\n", err)
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.IsInsufficientScope() {
+			fmt.Fprintf(os.Stderr, "💡 The preset's refresh token lacks the %s scope - run 'ga4admin preset reauth %s --edit'\n", api.ScopeName(api.AnalyticsEditScope), activePreset.Name)
 		}
+		os.Exit(exitcode.For(err))
+	}
 
-		fmt.Printf("%s🏢 %s (ID: %s)\n", accountPrefix, account.DisplayName, account.ID)
-		fmt.Printf("%s   🌍 %s • 📅 %s\n", childPrefix, account.RegionCode, account.CreateTime.Format("2006-01-02"))
-		
-		// Get properties for this account
-		fmt.Printf("%s   🔍 Loading properties...\n", childPrefix)
-		properties, err := adminClient.ListProperties(ctx, account.ID)
-		if err != nil {
-			fmt.Printf("%s   ❌ Error loading properties: %v\n", childPrefix, err)
-			continue
-		}
+	scope := "account " + accountID
+	if propertyID != "" {
+		scope = "property " + propertyID
+	}
+	fmt.Printf("📜 Change history for %s (last %d days)\n\n", scope, days)
+	if len(events) == 0 {
+		fmt.Println("📭 No changes found")
+		return
+	}
 
-		if len(properties) == 0 {
-			fmt.Printf("%s   📭 No properties found\n", childPrefix)
-		} else {
-			fmt.Printf("%s   📊 %d propert(y/ies):\n", childPrefix, len(properties))
-			
-			// Display properties in simple list
-			for propIndex, property := range properties {
-				isLastProp := propIndex == len(properties)-1
-				propPrefix := "├── "
-				if isLastProp {
-					propPrefix = "└── "
+	changes := 0
+	for _, event := range events {
+		fmt.Printf("🕒 %s  by %s\n", event.ChangeTime.Local().Format("2006-01-02 15:04:05"), event.Actor())
+		for _, change := range event.Changes {
+			changes++
+			icon := "✏️ "
+			switch change.Action {
+			case "CREATED":
+				icon = "➕"
+			case "DELETED":
+				icon = "🗑️ "
+			}
+			label := change.ResourceType()
+			if name := change.DisplayName(); name != "" {
+				label += fmt.Sprintf(" %q", name)
+			}
+			fmt.Printf("   %s %-7s %s (%s)\n", icon, change.Action, label, change.Resource)
+			if change.Action == "UPDATED" {
+				if fields := change.ChangedFields(); len(fields) > 0 {
+					fmt.Printf("      changed: %s\n", strings.Join(fields, ", "))
 				}
-				
-				// Service level indicator
-				serviceIcon := "📊"
-				if property.ServiceLevel == "GOOGLE_ANALYTICS_360" {
-					serviceIcon = "🎯" // Premium/360
+			}
+			if details {
+				if len(change.ResourceBeforeChange) > 0 {
+					fmt.Printf("      before: %s\n", change.ResourceBeforeChange)
+				}
+				if len(change.ResourceAfterChange) > 0 {
+					fmt.Printf("      after:  %s\n", change.ResourceAfterChange)
 				}
-				
-				fmt.Printf("%s   %s%s %s (ID: %s)\n", 
-					childPrefix, propPrefix, serviceIcon, property.DisplayName, property.ID)
-				fmt.Printf("%s      💰 %s • 🌍 %s • 📅 %s\n", 
-					childPrefix, property.CurrencyCode, property.TimeZone, property.CreateTime.Format("2006-01-02"))
 			}
 		}
-		
-		if !isLastAccount {
-			fmt.Println()
+		if event.ChangesFiltered {
+			fmt.Println("   ℹ️  Some changes in this event were filtered out")
 		}
 	}
-	
-	fmt.Println()
-	fmt.Printf("🎯 Total: %d account(s) discovered\n", len(accounts))
-	fmt.Println("💡 Use 'ga4admin properties show <property-id>' for detailed property information")
+
+	fmt.Printf("\n📊 %d events, %d changes listed\n", len(events), changes)
+	if !details {
+		fmt.Println("💡 Use --details to see the before and after state")
+	}
 }
 
-// Helper function to get accounts with proper error handling
-func getAccountsWithClient() ([]config.Account, error) {
-	// Get active preset
+func streamsListCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
-		return nil, err
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-
 	if activePreset == nil {
-		return nil, fmt.Errorf("no active preset - run 'ga4admin preset use <name>' first")
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create Admin API client
+	ensurePropertyAccess(activePreset, propertyID)
+
 	adminClient, err := api.NewAdminClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Admin API client: %w", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// List accounts
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := commandContext(30*time.Second)
 	defer cancel()
 
-	accounts, err := adminClient.ListAccounts(ctx)
+	streams, err := adminClient.ListDataStreams(ctx, propertyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list accounts: %w", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to list data streams: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📡 Data streams for property %s\n\n", propertyID)
+	if len(streams) == 0 {
+		fmt.Println("📭 No data streams")
+		return
+	}
+
+	for _, stream := range streams {
+		icon := "🌐"
+		if stream.Type != api.DataStreamTypeWeb {
+			icon = "📱"
+		}
+		fmt.Printf("%s %s (ID: %s)\n", icon, stream.DisplayName, stream.ID())
+		if stream.WebStreamData != nil {
+			fmt.Printf("   %s  %s\n", stream.WebStreamData.MeasurementID, stream.Target())
+		} else {
+			fmt.Printf("   %s\n", stream.Target())
+		}
 	}
 
-	return accounts, nil
+	fmt.Printf("\n📊 %d data stream(s)\n", len(streams))
+	fmt.Printf("💡 Use 'ga4admin streams settings <stream-id> --property %s' to see a stream's tag settings\n", propertyID)
 }
 
-func propertiesListCmd(cmd *cobra.Command, args []string) {
-	accountID, _ := cmd.Flags().GetString("account")
-	fmt.Printf("🏠 Listing GA4 properties for account %s...\n", accountID)
+func streamsSettingsCmd(cmd *cobra.Command, args []string) {
+	streamID := args[0]
+	propertyID, _ := cmd.Flags().GetString("property")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --format must be text or json\n")
+		os.Exit(exitcode.Validation)
+	}
 
-	// Get active preset
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
-
 	if activePreset == nil {
 		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create Admin API client
+	ensurePropertyAccess(activePreset, propertyID)
+
 	adminClient, err := api.NewAdminClient()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 
-	// List properties
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := commandContext(30*time.Second)
 	defer cancel()
 
-	properties, err := adminClient.ListProperties(ctx, accountID)
+	settings, err := audit.FetchStreamSettings(ctx, adminClient, propertyID, streamID)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to list properties: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	if len(properties) == 0 {
-		fmt.Printf("❌ No properties found for account %s\n", accountID)
-		fmt.Println("💡 Ensure the account ID is correct and accessible")
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if len(settings.Errors) > 0 {
+			os.Exit(exitcode.Failure)
+		}
 		return
 	}
 
-	// Display properties
-	fmt.Printf("🏠 Found %d propert(y/ies):\n\n", len(properties))
-	for i, property := range properties {
-		fmt.Printf("📊 %s (ID: %s)\n", property.DisplayName, property.ID)
-		fmt.Printf("   💰 Currency: %s\n", property.CurrencyCode)
-		fmt.Printf("   🌍 Timezone: %s\n", property.TimeZone)
-		fmt.Printf("   🏭 Industry: %s\n", property.IndustryCategory)
-		fmt.Printf("   📈 Service Level: %s\n", property.ServiceLevel)
-		fmt.Printf("   📅 Created: %s\n", property.CreateTime.Format("2006-01-02"))
-		
-		if i < len(properties)-1 {
+	stream := settings.Stream
+	fmt.Printf("📡 %s (ID: %s)\n", stream.DisplayName, stream.ID())
+	if stream.WebStreamData != nil {
+		fmt.Printf("   🏷️  Measurement ID: %s\n", stream.WebStreamData.MeasurementID)
+		fmt.Printf("   🌐 URL: %s\n", stream.WebStreamData.DefaultURI)
+	} else {
+		fmt.Printf("   📱 App: %s\n", stream.Target())
+		fmt.Println("\nℹ️  Enhanced measurement and data redaction only apply to web streams")
+		return
+	}
+
+	onOff := func(enabled bool) string {
+		if enabled {
+			return "✅ on"
+		}
+		return "⬜ off"
+	}
+
+	fmt.Println("\n⚡ Enhanced measurement:")
+	if em := settings.EnhancedMeasurement; em != nil {
+		fmt.Printf("   %-20s %s\n", "Enabled", onOff(em.StreamEnabled))
+		if em.StreamEnabled {
+			fmt.Printf("   %-20s %s\n", "Page changes", onOff(em.PageChangesEnabled))
+			fmt.Printf("   %-20s %s\n", "Scrolls", onOff(em.ScrollsEnabled))
+			fmt.Printf("   %-20s %s\n", "Outbound clicks", onOff(em.OutboundClicksEnabled))
+			fmt.Printf("   %-20s %s", "Site search", onOff(em.SiteSearchEnabled))
+			if em.SiteSearchEnabled && em.SearchQueryParameter != "" {
+				fmt.Printf("  (parameters: %s)", em.SearchQueryParameter)
+			}
 			fmt.Println()
+			fmt.Printf("   %-20s %s\n", "Form interactions", onOff(em.FormInteractionsEnabled))
+			fmt.Printf("   %-20s %s\n", "Video engagement", onOff(em.VideoEngagementEnabled))
+			fmt.Printf("   %-20s %s\n", "File downloads", onOff(em.FileDownloadsEnabled))
 		}
+	} else {
+		fmt.Printf("   ❌ %s\n", settings.Errors["enhanced_measurement"])
 	}
 
-	fmt.Println("\n💡 Use 'ga4admin properties show <property-id>' for detailed information")
+	fmt.Println("\n🔒 Data redaction:")
+	if redaction := settings.DataRedaction; redaction != nil {
+		fmt.Printf("   %-20s %s\n", "Email addresses", onOff(redaction.EmailRedactionEnabled))
+		fmt.Printf("   %-20s %s", "Query parameters", onOff(redaction.QueryParameterRedactionEnabled))
+		if redaction.QueryParameterRedactionEnabled && len(redaction.QueryParameterKeys) > 0 {
+			fmt.Printf("  (%s)", strings.Join(redaction.QueryParameterKeys, ", "))
+		}
+		fmt.Println()
+	} else {
+		fmt.Printf("   ❌ %s\n", settings.Errors["data_redaction"])
+	}
+
+	fmt.Printf("\nℹ️  The Admin API doesn't expose %s - check them under Configure tag settings in GA4\n", strings.Join(settings.Unavailable, " or "))
+	if len(settings.Errors) > 0 {
+		os.Exit(exitcode.Failure)
+	}
 }
 
-func propertiesShowCmd(cmd *cobra.Command, args []string) {
-	propertyID := args[0]
-	fmt.Printf("📊 Property details for %s...\n", propertyID)
+func linksListCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	typeNames, _ := cmd.Flags().GetStringSlice("type")
 
-	// Get active preset
-	activePreset, err := preset.GetActivePreset()
+	types, err := audit.ParseLinkTypes(typeNames)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Validation)
 	}
 
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 	if activePreset == nil {
 		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create Admin API client
+	ensurePropertyAccess(activePreset, propertyID)
+
 	adminClient, err := api.NewAdminClient()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Get property details
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := commandContext(60*time.Second)
 	defer cancel()
 
-	property, err := adminClient.GetProperty(ctx, propertyID)
+	fmt.Printf("🔗 Product links for property %s\n\n", propertyID)
+	links := audit.FetchLinks(ctx, adminClient, propertyID, types)
+
+	for _, linkType := range types {
+		if err := links.Errors[linkType]; err != nil {
+			fmt.Printf("❌ %s: %v\n\n", linkType.Label(), err)
+			continue
+		}
+		fmt.Printf("%s (%d)\n", linkType.Label(), links.Count(linkType))
+		if links.Count(linkType) == 0 {
+			fmt.Println("   (none)")
+		}
+		switch linkType {
+		case audit.LinkBigQuery:
+			for _, link := range links.BigQuery {
+				fmt.Printf("   📦 %s  export: %s", link.Project, link.ExportModes())
+				if link.DatasetLocation != "" {
+					fmt.Printf("  location: %s", link.DatasetLocation)
+				}
+				fmt.Println()
+				if len(link.ExportStreams) > 0 {
+					fmt.Printf("      streams: %s\n", strings.Join(link.ExportStreams, ", "))
+				}
+				if len(link.ExcludedEvents) > 0 {
+					fmt.Printf("      excluded events: %s\n", strings.Join(link.ExcludedEvents, ", "))
+				}
+			}
+		case audit.LinkGoogleAds:
+			for _, link := range links.GoogleAds {
+				fmt.Printf("   📣 Customer %s", link.CustomerID)
+				if link.CanManageClients {
+					fmt.Print(" (manager)")
+				}
+				if link.AdsPersonalizationEnabled != nil && !*link.AdsPersonalizationEnabled {
+					fmt.Print("  ads personalization off")
+				}
+				if link.CreatorEmailAddress != "" {
+					fmt.Printf("  linked by %s", link.CreatorEmailAddress)
+				}
+				fmt.Println()
+			}
+		case audit.LinkFirebase:
+			for _, link := range links.Firebase {
+				fmt.Printf("   🔥 %s  linked %s\n", link.Project, link.CreateTime.Local().Format("2006-01-02"))
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("ℹ️  Search Console links aren't exposed by the Admin API - check Admin > Product links in GA4")
+	if len(links.Errors) > 0 {
+		os.Exit(exitcode.Failure)
+	}
+}
+
+func linksAuditCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	expectNames, _ := cmd.Flags().GetStringSlice("expect")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+	if len(expectNames) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --expect needs at least one link type\n")
+		os.Exit(exitcode.Validation)
+	}
+	expected, err := audit.ParseLinkTypes(expectNames)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get property details: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Validation)
 	}
 
-	// Display property details
-	fmt.Printf("📊 %s (ID: %s)\n\n", property.DisplayName, property.ID)
-	
-	fmt.Println("🔧 Configuration:")
-	fmt.Printf("   💰 Currency Code: %s\n", property.CurrencyCode)
-	fmt.Printf("   🌍 Timezone: %s\n", property.TimeZone)
-	fmt.Printf("   🏭 Industry Category: %s\n", property.IndustryCategory)
-	fmt.Printf("   📈 Service Level: %s\n", property.ServiceLevel)
+	activePreset, err := preset.GetActivePreset()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+
+	adminClient, err := api.NewAdminClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	listCtx, listCancel := commandContext(30*time.Second)
+	properties, err := adminClient.ListProperties(listCtx, accountID)
+	listCancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list properties: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if len(properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		return
+	}
+
+	labels := make([]string, len(expected))
+	for i, linkType := range expected {
+		labels[i] = linkType.Label()
+	}
+	fmt.Printf("🔗 Checking %d propert(y/ies) in account %s for %s links\n\n", len(properties), accountID, strings.Join(labels, ", "))
+
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	done := 0
+	results := audit.AuditLinks(ctx, adminClient, properties, expected, concurrency, func(links *audit.PropertyLinks) {
+		done++
+		prefix := fmt.Sprintf("[%d/%d]", done, len(properties))
+		var parts []string
+		for _, linkType := range expected {
+			if err := links.Errors[linkType]; err != nil {
+				parts = append(parts, fmt.Sprintf("%s ❌ %v", linkType.Label(), err))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s %d", linkType.Label(), links.Count(linkType)))
+			}
+		}
+		fmt.Printf("%s %s (ID: %s): %s\n", prefix, links.DisplayName, links.PropertyID, strings.Join(parts, " · "))
+	})
+
+	// Roll up properties missing links, per link type
 	fmt.Println()
-	
-	fmt.Println("📅 Timeline:")
-	fmt.Printf("   🆕 Created: %s\n", property.CreateTime.Format("2006-01-02 15:04:05"))
-	fmt.Printf("   🔄 Last Accessed: %s\n", property.LastAccessed.Format("2006-01-02 15:04:05"))
+	missingCount, failed := 0, 0
+	for _, links := range results {
+		if len(links.Errors) > 0 {
+			failed++
+		}
+		if len(links.Missing(expected)) > 0 {
+			missingCount++
+		}
+	}
+
+	if missingCount == 0 {
+		fmt.Printf("✅ Every property has the expected links\n")
+	} else {
+		fmt.Printf("⚠️  %d of %d propert(y/ies) are missing expected links:\n", missingCount, len(results))
+		for _, linkType := range expected {
+			var names []string
+			for _, links := range results {
+				for _, missing := range links.Missing(expected) {
+					if missing == linkType {
+						names = append(names, fmt.Sprintf("%s (%s)", links.DisplayName, links.PropertyID))
+					}
+				}
+			}
+			if len(names) == 0 {
+				continue
+			}
+			fmt.Printf("\n   No %s link (%d):\n", linkType.Label(), len(names))
+			for _, name := range names {
+				fmt.Printf("      • %s\n", name)
+			}
+		}
+	}
+
 	fmt.Println()
-	
-	fmt.Println("💡 Next steps:")
-	fmt.Printf("   • ga4admin metadata dimensions --property %s\n", propertyID)
-	fmt.Printf("   • ga4admin metadata metrics --property %s\n", propertyID)
-	fmt.Printf("   • ga4admin metadata events --property %s\n", propertyID)
+	fmt.Printf("📊 Audited %d propert(y/ies): %d missing links, %d could not be fully checked\n", len(results), missingCount, failed)
+	if failed > 0 {
+		os.Exit(exitcode.Failure)
+	}
 }
 
-func metadataDimensionsCmd(cmd *cobra.Command, args []string) {
-	propertyID, _ := cmd.Flags().GetString("property")
-	customOnly, _ := cmd.Flags().GetBool("custom-only")
-	category, _ := cmd.Flags().GetString("category")
+func workspaceAddCmd(cmd *cobra.Command, args []string) {
+	name, presetName, propertyRef := args[0], args[1], args[2]
+	label, _ := cmd.Flags().GetString("label")
 
-	fmt.Printf("📏 Discovering dimensions for property %s...\n", propertyID)
+	if !preset.IsValidPresetName(name) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid workspace name '%s' - use letters, digits, hyphens and underscores\n", name)
+		os.Exit(exitcode.Validation)
+	}
 
-	// Get active preset
-	activePreset, err := preset.GetActivePreset()
+	memberPreset, err := preset.LoadPreset(presetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	// Aliases are looked up in the member's preset, not the active one
+	propertyID, err := preset.ResolveProperty(memberPreset, propertyRef)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
+	}
+
+	member := config.WorkspaceMember{Preset: presetName, PropertyID: propertyID, Label: label}
+	if err := config.AddWorkspaceMember(name, member); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+	fmt.Printf("✅ Added property %s (preset '%s') to workspace '%s' as %s\n", propertyID, presetName, name, workspace.Label(member))
+	if !memberPreset.SyncedAt.IsZero() && findSyncedProperty(memberPreset, propertyID) == nil {
+		fmt.Printf("⚠️  Property %s was not found in the last sync of preset '%s'\n", propertyID, presetName)
 	}
+}
 
-	// Create Data API client with cache
-	dataClient, err := createDataClientWithCache()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
-		os.Exit(1)
-	}
-	defer dataClient.Close()
+func workspaceRemoveCmd(cmd *cobra.Command, args []string) {
+	name, presetName, propertyRef := args[0], args[1], args[2]
 
-	// Get metadata
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	propertyID := propertyRef
+	if memberPreset, err := preset.LoadPreset(presetName); err == nil {
+		if resolved, err := preset.ResolveProperty(memberPreset, propertyRef); err == nil {
+			propertyID = resolved
+		}
+	}
 
-	metadata, err := dataClient.GetMetadata(ctx, propertyID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
-		os.Exit(1)
+	if err := config.RemoveWorkspaceMember(name, presetName, propertyID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	fmt.Printf("✅ Removed property %s (preset '%s') from workspace '%s'\n", propertyID, presetName, name)
+}
 
-	// Filter and display dimensions
-	filteredDimensions := make([]api.DimensionMetadata, 0)
-	for _, dim := range metadata.Dimensions {
-		// Apply filters
-		if customOnly && !dim.CustomDefinition {
-			continue
-		}
-		if category != "" && dim.Category != category {
-			continue
-		}
-		filteredDimensions = append(filteredDimensions, dim)
+func workspaceListCmd(cmd *cobra.Command, args []string) {
+	workspaces, err := config.ListWorkspaces()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	if len(filteredDimensions) == 0 {
-		fmt.Println("❌ No dimensions found matching your criteria")
+	if len(workspaces) == 0 {
+		fmt.Println("📭 No workspaces configured")
+		fmt.Println("💡 Add one with 'ga4admin workspace add <workspace> <preset> <property>'")
 		return
 	}
 
-	// Display results
-	fmt.Printf("📊 Found %d dimension(s):\n\n", len(filteredDimensions))
-	
-	// Group by category
-	categories := make(map[string][]api.DimensionMetadata)
-	for _, dim := range filteredDimensions {
-		cat := dim.Category
-		if cat == "" {
-			cat = "Other"
+	for _, ws := range workspaces {
+		fmt.Printf("🗂️  %s (%d properties)\n", ws.Name, len(ws.Members))
+		for _, member := range ws.Members {
+			fmt.Printf("   • %-28s preset %-20s property %s\n", workspace.Label(member), member.Preset, member.PropertyID)
 		}
-		categories[cat] = append(categories[cat], dim)
 	}
+}
 
-	for category, dims := range categories {
-		fmt.Printf("🏷️  %s (%d)\n", category, len(dims))
-		for _, dim := range dims {
-			customIndicator := ""
-			if dim.CustomDefinition {
-				customIndicator = " 🔧"
-			}
-			
-			fmt.Printf("   • %s%s\n", dim.APIName, customIndicator)
-			fmt.Printf("     UI Name: %s\n", dim.UIName)
-			if dim.Description != "" {
-				fmt.Printf("     %s\n", dim.Description)
-			}
-		}
-		fmt.Println()
+func workspaceDeleteCmd(cmd *cobra.Command, args []string) {
+	if err := config.RemoveWorkspace(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-
-	fmt.Printf("💡 Total: %d dimensions (%d custom)\n", 
-		len(metadata.Dimensions), countCustom(metadata.Dimensions))
-	fmt.Printf("💡 Use 'ga4admin metadata metrics --property %s' to see available metrics\n", propertyID)
+	fmt.Printf("✅ Deleted workspace '%s'\n", args[0])
 }
 
-func metadataMetricsCmd(cmd *cobra.Command, args []string) {
-	propertyID, _ := cmd.Flags().GetString("property")
-	customOnly, _ := cmd.Flags().GetBool("custom-only")
-	category, _ := cmd.Flags().GetString("category")
-	metricType, _ := cmd.Flags().GetString("type")
-
-	fmt.Printf("📈 Discovering metrics for property %s...\n", propertyID)
+func workspaceRunCmd(cmd *cobra.Command, args []string) {
+	name, template := args[0], args[1]
+	outputPath, _ := cmd.Flags().GetString("output")
+	startDate, _ := cmd.Flags().GetString("start-date")
+	endDate, _ := cmd.Flags().GetString("end-date")
+	limit, _ := cmd.Flags().GetInt64("limit")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
 
-	// Get active preset
-	activePreset, err := preset.GetActivePreset()
+	ws, err := config.GetWorkspace(name)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
+	}
+	if len(ws.Members) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: Workspace '%s' has no properties\n", name)
+		os.Exit(exitcode.Failure)
+	}
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("%s_%s.csv", name, strings.TrimSuffix(filepath.Base(template), filepath.Ext(template)))
 	}
 
-	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+	// Check every member up front: a missing preset or revoked access only
+	// drops that member, so one lapsed client doesn't block the rest
+	accessCtx, accessCancel := commandContext(120*time.Second)
+	var jobs []workspace.Job
+	var skipped []workspace.MemberResult
+	for _, member := range ws.Members {
+		memberPreset, err := preset.LoadPreset(member.Preset)
+		if err == nil {
+			err = access.CheckPropertyAccess(accessCtx, memberPreset, member.PropertyID)
+		}
+		var queryConfig *query.QueryConfig
+		if err == nil {
+			queryConfig, err = resolveMatrixTemplate(template, ".")
+		}
+		if err != nil {
+			skipped = append(skipped, workspace.MemberResult{Member: member, MatrixResult: query.MatrixResult{Err: err}})
+			continue
+		}
+
+		// Flags win, then dates saved in a query file, then the usual defaults
+		if startDate != "" {
+			queryConfig.StartDate = startDate
+		} else if queryConfig.StartDate == "" {
+			queryConfig.StartDate = "30daysAgo"
+		}
+		if endDate != "" {
+			queryConfig.EndDate = endDate
+		} else if queryConfig.EndDate == "" {
+			queryConfig.EndDate = "yesterday"
+		}
+		if limit > 0 {
+			queryConfig.Limit = limit
+		}
+		queryConfig.PropertyID = member.PropertyID
+		jobs = append(jobs, workspace.Job{Member: member, Config: queryConfig})
 	}
+	accessCancel()
 
-	// Create Data API client with cache
-	dataClient, err := createDataClientWithCache()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
-		os.Exit(1)
+	fmt.Printf("🗂️  Running %s across %d properties in workspace '%s'\n\n", template, len(ws.Members), name)
+	for _, result := range skipped {
+		fmt.Printf("   ❌ %s: %v\n", workspace.Label(result.Member), result.Err)
 	}
-	defer dataClient.Close()
 
-	// Get metadata
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := commandContext(30*time.Minute)
 	defer cancel()
 
-	metadata, err := dataClient.GetMetadata(ctx, propertyID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
-		os.Exit(1)
+	newExecutor := func(presetName string) (*query.Executor, func(), error) {
+		dataClient, err := createPresetDataClient(presetName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return newQueryExecutor(dataClient), func() { dataClient.Close() }, nil
 	}
-
-	// Filter and display metrics
-	filteredMetrics := make([]api.MetricMetadata, 0)
-	for _, metric := range metadata.Metrics {
-		// Apply filters
-		if customOnly && !metric.CustomDefinition {
-			continue
+	memberResults := workspace.Run(ctx, jobs, concurrency, newExecutor, func(result workspace.MemberResult) {
+		label := workspace.Label(result.Member)
+		if result.Err != nil {
+			fmt.Printf("   ❌ %s: %v\n", label, result.Err)
+			return
 		}
-		if category != "" && metric.Category != category {
-			continue
+		cached := ""
+		if result.Result.FromCache {
+			cached = " (cached)"
 		}
-		if metricType != "" && metric.Type != metricType {
-			continue
+		fmt.Printf("   ✅ %s: %d rows in %s%s\n", label, result.Result.RowCount, result.Duration.Round(time.Millisecond), cached)
+	})
+
+	failed := len(skipped)
+	for _, result := range memberResults {
+		if result.Err != nil {
+			failed++
 		}
-		filteredMetrics = append(filteredMetrics, metric)
 	}
 
-	if len(filteredMetrics) == 0 {
-		fmt.Println("❌ No metrics found matching your criteria")
+	fmt.Println()
+	merged := workspace.Merge(memberResults)
+	if merged == nil {
+		fmt.Fprintf(os.Stderr, "Error: No property in workspace '%s' returned results\n", name)
+		os.Exit(exitcode.Failure)
+	}
+	if err := results.WriteCSV(merged, outputPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to write merged results: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("📊 Merged %d rows from %d of %d properties → %s\n", merged.RowCount, len(ws.Members)-failed, len(ws.Members), outputPath)
+	if currencies := workspace.Currencies(memberResults); len(currencies) > 1 {
+		fmt.Printf("⚠️  Revenue is in different currencies (%s) - set currency_code in the template to convert every property to one\n", strings.Join(currencies, ", "))
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d properties failed and are missing from the merged output\n", failed)
+		os.Exit(exitcode.Failure)
+	}
+}
+
+func pluginListCmdHandler(cmd *cobra.Command, args []string) {
+	plugins := plugin.List()
+	if len(plugins) == 0 {
+		fmt.Println("❌ No plugins found on PATH")
+		fmt.Printf("💡 Put an executable named %s<name> on PATH to add 'ga4admin <name>'\n", plugin.Prefix)
 		return
 	}
 
-	// Display results
-	fmt.Printf("📊 Found %d metric(s):\n\n", len(filteredMetrics))
-	
-	// Group by category
-	categories := make(map[string][]api.MetricMetadata)
-	for _, metric := range filteredMetrics {
-		cat := metric.Category
-		if cat == "" {
-			cat = "Other"
+	fmt.Printf("🔌 Plugins (%d):\n", len(plugins))
+	for _, p := range plugins {
+		fmt.Printf("  ga4admin %-20s %s\n", p.Name, p.Path)
+		if builtin, _, err := rootCmd.Find([]string{p.Name}); err == nil && builtin != rootCmd {
+			fmt.Printf("    ⚠️  Never runs: '%s' is a built-in command\n", p.Name)
+		}
+		for _, shadowed := range p.Shadowed {
+			fmt.Printf("    ⚠️  Hides %s (later on PATH)\n", shadowed)
 		}
-		categories[cat] = append(categories[cat], metric)
 	}
+}
 
-	for category, metrics := range categories {
-		fmt.Printf("🏷️  %s (%d)\n", category, len(metrics))
-		for _, metric := range metrics {
-			customIndicator := ""
-			if metric.CustomDefinition {
-				customIndicator = " 🔧"
+func doctorCmdHandler(cmd *cobra.Command, args []string) {
+	skipNetwork, _ := cmd.Flags().GetBool("skip-network")
+
+	ctx, cancel := commandContext(2*time.Minute)
+	defer cancel()
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	fmt.Printf("🩺 Checking %s\n", configDir)
+
+	checks := doctor.Run(ctx, doctor.Options{Network: !skipNetwork})
+
+	counts := make(map[doctor.Status]int)
+	for _, section := range doctor.Sections {
+		printed := false
+		for _, check := range checks {
+			if check.Section != section {
+				continue
 			}
-			
-			typeIndicator := ""
-			if metric.Type != "" {
-				typeIndicator = fmt.Sprintf(" [%s]", metric.Type)
+			if !printed {
+				fmt.Printf("\n%s\n", section)
+				printed = true
 			}
-			
-			fmt.Printf("   • %s%s%s\n", metric.APIName, typeIndicator, customIndicator)
-			fmt.Printf("     UI Name: %s\n", metric.UIName)
-			if metric.Description != "" {
-				fmt.Printf("     %s\n", metric.Description)
+			counts[check.Status]++
+
+			icon := "✅"
+			switch check.Status {
+			case doctor.Warn:
+				icon = "⚠️ "
+			case doctor.Fail:
+				icon = "❌"
+			}
+			line := fmt.Sprintf("  %s %s", icon, check.Name)
+			if check.Detail != "" {
+				line += ": " + check.Detail
+			}
+			fmt.Println(line)
+			if check.Fix != "" && check.Status != doctor.Pass {
+				fmt.Printf("     🔧 %s\n", check.Fix)
 			}
 		}
-		fmt.Println()
 	}
 
-	fmt.Printf("💡 Total: %d metrics (%d custom)\n", 
-		len(metadata.Metrics), countCustomMetrics(metadata.Metrics))
-	fmt.Printf("💡 Use 'ga4admin metadata events --property %s' to analyze event volumes\n", propertyID)
+	fmt.Printf("\n📊 %d passed, %d warning(s), %d failed\n", counts[doctor.Pass], counts[doctor.Warn], counts[doctor.Fail])
+	if skipNetwork {
+		fmt.Println("💡 Network checks were skipped")
+	}
+	if doctor.Failed(checks) {
+		os.Exit(exitcode.Failure)
+	}
 }
 
-func metadataEventsCmd(cmd *cobra.Command, args []string) {
-	propertyID, _ := cmd.Flags().GetString("property")
-	days, _ := cmd.Flags().GetInt("days")
-	limit, _ := cmd.Flags().GetInt("limit")
-
-	fmt.Printf("📅 Analyzing events for property %s (%d days)...\n", propertyID, days)
-
-	// Get active preset
-	activePreset, err := preset.GetActivePreset()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+func versionCmdHandler(cmd *cobra.Command, args []string) {
+	check, _ := cmd.Flags().GetBool("check")
+	channel, _ := cmd.Flags().GetString("channel")
+	if channel == "" {
+		channel = selfupdate.ChannelFor(version)
 	}
 
-	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+	method := installMethod()
+	fmt.Printf("ga4admin %s (%s/%s, %s install)\n", version, runtime.GOOS, runtime.GOARCH, method)
+	if !check {
+		return
 	}
 
-	// Create Data API client with cache
-	dataClient, err := createDataClientWithCache()
+	httpClient, err := api.NewHTTPClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer dataClient.Close()
 
-	// Analyze events
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := commandContext(30*time.Second)
 	defer cancel()
 
-	analysis, err := dataClient.AnalyzeEvents(ctx, propertyID, days)
+	release, err := selfupdate.Latest(ctx, httpClient, selfupdate.FeedURL(), channel)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to analyze events: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Display results
-	if analysis.TotalEvents == 0 {
-		fmt.Printf("❌ No events found in the last %d days\n", days)
-		fmt.Println("💡 This might indicate no data collection or a very new property")
+	if selfupdate.CompareVersions(release.Version(), version) <= 0 {
+		fmt.Printf("✅ Up to date (latest %s release: %s)\n", channel, release.Version())
 		return
 	}
 
-	fmt.Printf("📊 Event Analysis Results:\n\n")
-	fmt.Printf("📈 Total Events: %d unique event types\n", analysis.TotalEvents)
-	fmt.Printf("🔢 Total Event Count: %s\n", formatNumber(analysis.TotalEventCount))
-	fmt.Printf("👥 Total Active Users: %s\n", formatNumber(analysis.TotalActiveUsers))
-	fmt.Printf("🎯 Events per User: %.1f\n", float64(analysis.TotalEventCount)/float64(analysis.TotalActiveUsers))
-	fmt.Println()
+	fmt.Printf("📦 ga4admin %s is available (installed: %s)\n", release.Version(), version)
+	if release.URL != "" {
+		fmt.Printf("   Release notes: %s\n", release.URL)
+	}
+	fmt.Printf("💡 Upgrade with: %s\n", selfupdate.UpgradeCommand(method, channel))
+}
 
-	// Show top events (limited by user preference)
-	displayLimit := limit
-	if displayLimit > len(analysis.Events) {
-		displayLimit = len(analysis.Events)
+// installMethod reports how the running binary was installed, e.g. homebrew
+func installMethod() string {
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		return selfupdate.InstallBinary
 	}
+	return selfupdate.DetectInstall(executable)
+}
 
-	fmt.Printf("🔥 Top %d Events:\n\n", displayLimit)
-	for i, event := range analysis.Events[:displayLimit] {
-		rank := i + 1
-		percentage := (float64(event.EventCount) / float64(analysis.TotalEventCount)) * 100
-		
-		fmt.Printf("%2d. %s\n", rank, event.EventName)
-		fmt.Printf("    📊 %s events (%.1f%% of total)\n", formatNumber(event.EventCount), percentage)
-		fmt.Printf("    👥 %s users (%.1f events/user)\n", formatNumber(event.ActiveUsers), event.EventsPerUser)
-		
-		// Identify potential conversion events
-		if isLikelyConversionEvent(event.EventName) {
-			fmt.Printf("    🎯 Likely conversion event\n")
-		}
-		fmt.Println()
+// updateCheckDone is closed when the background update check started for
+// this command finishes
+var updateCheckDone chan struct{}
+
+// startUpdateCheck prints the notice about a newer release an earlier check
+// found, at most once a day, and queries the release feed in the background
+// when the last check is a day old. Only interactive runs are checked, so
+// scripts and cron jobs never see the notice or wait on the feed.
+func startUpdateCheck(cmd *cobra.Command) {
+	if os.Getenv(selfupdate.NoticeEnvVar) != "" || api.ReplayEnabled() || cmd == versionCmd || cmd == selfUpdateCmd {
+		return
+	}
+	if stat, _ := os.Stderr.Stat(); stat == nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return
 	}
 
-	fmt.Printf("💡 Analyzed %d days of data (updated %s)\n", days, analysis.AnalyzedAt.Format("2006-01-02 15:04"))
-	fmt.Printf("💡 Use 'ga4admin metadata dimensions --property %s' to see available dimensions\n", propertyID)
-}
+	if latest := selfupdate.PendingNotice(version); latest != "" {
+		fmt.Fprintf(os.Stderr, "💡 ga4admin %s is available (installed: %s) - upgrade with '%s'\n",
+			latest, version, selfupdate.UpgradeCommand(installMethod(), selfupdate.ChannelFor(version)))
+	}
 
-// Helper functions
-func countCustom(dimensions []api.DimensionMetadata) int {
-	count := 0
-	for _, dim := range dimensions {
-		if dim.CustomDefinition {
-			count++
-		}
+	if !selfupdate.CheckDue() {
+		return
 	}
-	return count
+	httpClient, err := api.NewHTTPClient()
+	if err != nil {
+		return
+	}
+	done := make(chan struct{})
+	updateCheckDone = done
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		selfupdate.RefreshCheck(ctx, httpClient, selfupdate.FeedURL(), version)
+	}()
 }
 
-func countCustomMetrics(metrics []api.MetricMetadata) int {
-	count := 0
-	for _, metric := range metrics {
-		if metric.CustomDefinition {
-			count++
-		}
+// waitForUpdateCheck gives a background update check a moment to finish
+// before the process exits; an unfinished check is retried next run
+func waitForUpdateCheck() {
+	if updateCheckDone == nil {
+		return
+	}
+	select {
+	case <-updateCheckDone:
+	case <-time.After(time.Second):
 	}
-	return count
 }
 
-func formatNumber(n int64) string {
-	if n < 1000 {
-		return fmt.Sprintf("%d", n)
-	} else if n < 1000000 {
-		return fmt.Sprintf("%.1fK", float64(n)/1000)
-	} else if n < 1000000000 {
-		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+func selfUpdateCmdHandler(cmd *cobra.Command, args []string) {
+	channel, _ := cmd.Flags().GetString("channel")
+	checkOnly, _ := cmd.Flags().GetBool("check")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
+	checksumOnly, _ := cmd.Flags().GetBool("checksum-only")
+	force, _ := cmd.Flags().GetBool("force")
+
+	httpClient, err := api.NewHTTPClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	fmt.Printf("🔍 Checking the %s channel for updates...\n", channel)
+	release, err := selfupdate.Latest(ctx, httpClient, selfupdate.FeedURL(), channel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	if selfupdate.CompareVersions(release.Version(), version) <= 0 {
+		fmt.Printf("✅ ga4admin %s is up to date (latest %s release: %s)\n", version, channel, release.Version())
+		return
 	}
-	return fmt.Sprintf("%.1fB", float64(n)/1000000000)
-}
 
-func isLikelyConversionEvent(eventName string) bool {
-	conversionKeywords := []string{
-		"purchase", "conversion", "complete", "submit", "signup", "register", 
-		"subscribe", "download", "checkout", "payment", "order", "buy",
-		"generate_lead", "sign_up", "login", "add_payment_info",
+	fmt.Printf("📦 ga4admin %s is available (installed: %s)\n", release.Version(), version)
+	if release.URL != "" {
+		fmt.Printf("   Release notes: %s\n", release.URL)
 	}
-	
-	eventLower := strings.ToLower(eventName)
-	for _, keyword := range conversionKeywords {
-		if strings.Contains(eventLower, keyword) {
-			return true
+	if checkOnly {
+		if method := installMethod(); method != selfupdate.InstallBinary {
+			fmt.Printf("💡 Run '%s' to install it\n", selfupdate.UpgradeCommand(method, channel))
+		} else {
+			fmt.Printf("💡 Run 'ga4admin self-update --channel %s' to install it\n", channel)
 		}
+		return
 	}
-	return false
-}
 
-func testAuthCmdHandler(cmd *cobra.Command, args []string) {
-	fmt.Println("🔐 Testing OAuth2 Authentication...")
-	
-	// Create auth client
-	authClient, err := api.NewAuthClient()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create auth client: %v\n", err)
-		os.Exit(1)
+	if selfupdate.PublicKey == "" && !checksumOnly {
+		fmt.Fprintf(os.Stderr, "Error: this build has no release signing key, so the download can't be verified\n")
+		fmt.Fprintf(os.Stderr, "💡 Reinstall from a release build, or pass --checksum-only to rely on checksums alone\n")
+		os.Exit(exitcode.Failure)
 	}
-	
-	// Get active preset info
-	activePreset, err := preset.GetActivePreset()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
 	}
-	
-	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
-		os.Exit(1)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to locate the ga4admin binary: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	
-	fmt.Printf("📋 Active Preset: %s\n", activePreset.Name)
-	if activePreset.UserEmail != "" {
-		fmt.Printf("👤 User: %s\n", activePreset.UserEmail)
+
+	// Replacing a package manager's file leaves it believing the old version is installed
+	if method := selfupdate.DetectInstall(executable); method != selfupdate.InstallBinary && !force {
+		fmt.Fprintf(os.Stderr, "Error: ga4admin was installed with %s, which tracks the files it installs\n", method)
+		fmt.Fprintf(os.Stderr, "💡 Run '%s' instead, or pass --force to replace %s anyway\n", selfupdate.UpgradeCommand(method, channel), executable)
+		os.Exit(exitcode.Validation)
 	}
-	
-	// Test token refresh
-	fmt.Println("🔄 Testing token refresh...")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	
-	token, err := authClient.GetAccessToken(ctx)
+
+	assetName := selfupdate.BinaryAssetName(runtime.GOOS, runtime.GOARCH)
+	binaryAsset, err := release.Asset(assetName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Token refresh failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v (no build for %s/%s)\n", err, runtime.GOOS, runtime.GOARCH)
+		os.Exit(exitcode.For(err))
 	}
-	
-	fmt.Printf("✅ Token refresh successful!\n")
-	fmt.Printf("🎯 Access Token: %s...%s\n", token.AccessToken[:20], token.AccessToken[len(token.AccessToken)-4:])
-	fmt.Printf("⏰ Expires: %s\n", token.Expiry.Format("2006-01-02 15:04:05"))
-	fmt.Printf("⏳ Valid for: %s\n", time.Until(token.Expiry).Round(time.Second))
-	
-	// Test HTTP client
-	fmt.Println("🌐 Testing authenticated HTTP client...")
-	httpClient, err := authClient.AuthenticatedHTTPClient(ctx)
+	checksumsAsset, err := release.Asset(selfupdate.ChecksumsAsset)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create HTTP client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	
-	// Make a test request to GA4 Admin API accounts endpoint
-	resp, err := httpClient.Get("https://analyticsadmin.googleapis.com/v1alpha/accounts")
+
+	if !skipConfirm {
+		fmt.Printf("Replace %s with ga4admin %s? (y/N): ", executable, release.Version())
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" && response != "yes" {
+			fmt.Println("Update cancelled")
+			return
+		}
+	}
+
+	checksums, err := selfupdate.Download(ctx, httpClient, checksumsAsset)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Test API call failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer resp.Body.Close()
-	
-	fmt.Printf("🚀 Test API call successful!\n")
-	fmt.Printf("📊 Status: %s\n", resp.Status)
-	
-	if resp.StatusCode == 200 {
-		fmt.Println("✨ OAuth2 authentication is working correctly!")
-		fmt.Println("🎉 Ready for GA4 API integration")
+
+	if selfupdate.PublicKey != "" {
+		signatureAsset, err := release.Asset(selfupdate.SignatureAsset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v - refusing to install an unsigned release\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		signature, err := selfupdate.Download(ctx, httpClient, signatureAsset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		if err := selfupdate.VerifySignature(checksums, signature, selfupdate.PublicKey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v - refusing to install\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Println("🔏 Release signature verified")
 	} else {
-		fmt.Printf("⚠️  Unexpected status code: %d\n", resp.StatusCode)
-		fmt.Println("💡 This might indicate permission issues")
-	}
-	
-	// Show token cache info
-	tokenInfo := authClient.GetTokenInfo()
-	fmt.Println("\n📈 Token Cache Info:")
-	for key, value := range tokenInfo {
-		fmt.Printf("  %s: %v\n", key, value)
+		fmt.Println("⚠️  Skipping signature check (--checksum-only)")
 	}
-}
 
-// Helper function to create a cache-enabled data client
-func createDataClientWithCache() (*api.DataClient, error) {
-	// Get active preset name for cache
-	activePreset, err := preset.GetActivePreset()
+	fmt.Printf("⬇️  Downloading %s...\n", assetName)
+	binary, err := selfupdate.Download(ctx, httpClient, binaryAsset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active preset: %w", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	if activePreset == nil {
-		return nil, fmt.Errorf("no active preset - run 'ga4admin preset use <name>' first")
+	if err := selfupdate.VerifyChecksum(checksums, assetName, binary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v - refusing to install\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	fmt.Println("✅ Checksum verified")
 
-	// Create cache client
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
-	if err != nil {
-		// Fall back to non-cached client if cache fails
-		fmt.Fprintf(os.Stderr, "Warning: Failed to create cache client, using non-cached mode: %v\n", err)
-		return api.NewDataClient()
+	if err := selfupdate.ReplaceExecutable(executable, binary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Create data client with cache
-	return api.NewDataClientWithCache(cacheClient)
+	fmt.Printf("✅ Updated ga4admin %s → %s\n", version, release.Version())
 }
 
-// Query command handlers
+func channelGroupsShowCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	groupID, _ := cmd.Flags().GetString("group")
+	includeSystem, _ := cmd.Flags().GetBool("system")
 
-func queryRunCmd(cmd *cobra.Command, args []string) {
+	fmt.Printf("🔀 Channel groups for property %s...\n\n", propertyID)
+
+	groups := fetchChannelGroups(propertyID, groupID, includeSystem)
+	if len(groups) == 0 {
+		fmt.Println("📭 No custom channel groups found")
+		fmt.Println("💡 Use --system to include the system-defined default channel group")
+		return
+	}
+
+	for _, group := range groups {
+		labels := ""
+		if group.SystemDefined {
+			labels += " [system]"
+		}
+		if group.Primary {
+			labels += " [primary]"
+		}
+		fmt.Printf("📁 %s (%s)%s\n", group.DisplayName, group.ID(), labels)
+		if group.Description != "" {
+			fmt.Printf("   %s\n", group.Description)
+		}
+
+		for i, rule := range group.GroupingRules {
+			condition, err := channelgroup.ParseExpression(rule.Expression)
+			if err != nil {
+				fmt.Printf("   %2d. %-28s ❌ %v\n", i+1, rule.DisplayName, err)
+				continue
+			}
+			fmt.Printf("   %2d. %-28s %s\n", i+1, rule.DisplayName, condition)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("💡 Validate rules against traffic: ga4admin channelgroups lint --property %s\n", propertyID)
+}
+
+func channelGroupsLintCmd(cmd *cobra.Command, args []string) {
 	propertyID, _ := cmd.Flags().GetString("property")
-	dimensions, _ := cmd.Flags().GetStringSlice("dimensions")
-	metrics, _ := cmd.Flags().GetStringSlice("metrics")
-	startDate, _ := cmd.Flags().GetString("start-date")
-	endDate, _ := cmd.Flags().GetString("end-date")
-	limit, _ := cmd.Flags().GetInt64("limit")
-	filterStrings, _ := cmd.Flags().GetStringSlice("filters")
-	orderBy, _ := cmd.Flags().GetString("order-by")
-	queryName, _ := cmd.Flags().GetString("name")
-	// noCache, _ := cmd.Flags().GetBool("no-cache") // TODO: Implement cache skipping
+	groupID, _ := cmd.Flags().GetString("group")
+	days, _ := cmd.Flags().GetInt("days")
 
-	fmt.Printf("🚀 Executing GA4 query for property %s...\n", propertyID)
+	fmt.Printf("🔍 Linting channel groups for property %s (%d days of traffic)...\n\n", propertyID, days)
 
-	// Validate basic requirements
-	if len(dimensions) == 0 && len(metrics) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: At least one dimension or metric is required\n")
-		fmt.Fprintf(os.Stderr, "Example: --dimensions sessionSource,sessionMedium --metrics activeUsers,sessions\n")
-		os.Exit(1)
+	groups := fetchChannelGroups(propertyID, groupID, false)
+	if len(groups) == 0 {
+		fmt.Println("📭 No custom channel groups to lint")
+		return
 	}
 
-	// Create data client
 	dataClient, err := createDataClientWithCache()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 	defer dataClient.Close()
 
-	// Build query configuration
-	config := &query.QueryConfig{
-		PropertyID: propertyID,
-		Name:       queryName,
-		Dimensions: dimensions,
-		Metrics:    metrics,
-		StartDate:  startDate,
-		EndDate:    endDate,
-		Limit:      limit,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
+	ctx, cancel := commandContext(120*time.Second)
+	defer cancel()
 
-	// Parse filters if provided
-	if len(filterStrings) > 0 {
-		filters, err := parseFilters(filterStrings)
+	failed := false
+	for _, group := range groups {
+		result, err := channelgroup.Lint(ctx, dataClient, propertyID, group, days)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid filter format: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Filter format: field:type:operation:value\n")
-			fmt.Fprintf(os.Stderr, "Example: sessionSource:string:EXACT:google\n")
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: Failed to lint channel group %s: %v\n", group.ID(), err)
+			os.Exit(exitcode.For(err))
 		}
-		config.Filters = filters
-	}
 
-	// Parse order by if provided
-	if orderBy != "" {
-		orderConfig, err := parseOrderBy(orderBy, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid order-by format: %v\n", err)
-			os.Exit(1)
+		fmt.Printf("📁 %s (%s)\n", result.GroupName, result.GroupID)
+		for _, coverage := range result.Coverage {
+			if !coverage.Checked {
+				fmt.Printf("   • %-28s %12s  (not checked - field not in Data API)\n", coverage.Rule, "-")
+				continue
+			}
+			fmt.Printf("   • %-28s %12s  (%.1f%%)\n", coverage.Rule, formatNumber(coverage.Sessions), coverage.Share)
+		}
+		if result.TotalSessions > 0 {
+			unassignedShare := float64(result.UnassignedSessions) / float64(result.TotalSessions) * 100
+			fmt.Printf("   • %-28s %12s  (%.1f%%)\n", "(Unassigned)", formatNumber(result.UnassignedSessions), unassignedShare)
 		}
-		config.OrderBy = []query.OrderByConfig{*orderConfig}
-	}
 
-	// Execute query
-	executor := query.NewExecutor(dataClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+		if len(result.Issues) == 0 {
+			fmt.Println("   ✅ No issues found")
+		}
+		for _, issue := range result.Issues {
+			icon := "⚠️ "
+			if issue.Severity == channelgroup.SeverityError {
+				icon = "❌"
+			}
+			if issue.Rule != "" {
+				fmt.Printf("   %s %s: %s\n", icon, issue.Rule, issue.Message)
+			} else {
+				fmt.Printf("   %s %s\n", icon, issue.Message)
+			}
+		}
+		fmt.Println()
 
-	result, err := executor.Execute(ctx, config)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
-		os.Exit(1)
+		failed = failed || result.HasErrors()
 	}
 
-	// Display results
-	fmt.Printf("✅ Query completed successfully!\n")
-	fmt.Printf("📊 Returned %d rows in %s\n", result.RowCount, result.ExecutionTime)
-	if result.FromCache {
-		fmt.Printf("⚡ Results served from cache\n")
+	if failed {
+		os.Exit(exitcode.Failure)
 	}
-	fmt.Println()
+}
 
-	// Show result table
-	if result.RowCount > 0 {
-		// Create results manager for formatting
-		cacheClient, _ := cache.NewCacheClient("temp") // For formatting only
-		resultsManager := results.NewManager(cacheClient)
-		
-		lines, err := resultsManager.FormatResultTable(result, 20, 30)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error formatting results: %v\n", err)
-		} else {
-			for _, line := range lines {
-				fmt.Println(line)
-			}
-		}
-		cacheClient.Close()
+func exportLookerStudioCmd(cmd *cobra.Command, args []string) {
+	queryID, _ := cmd.Flags().GetString("result")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	if outputDir == "" {
+		outputDir = "lookerstudio_" + queryID
 	}
 
-	fmt.Println()
-	fmt.Printf("💡 Query ID: %s\n", result.QueryID)
-	fmt.Printf("💡 Use 'ga4admin results show %s' to see full results\n", result.QueryID)
-	fmt.Printf("💡 Use 'ga4admin results export %s output.csv' to export data\n", result.QueryID)
-}
-
-func queryBuildCmd(cmd *cobra.Command, args []string) {
-	propertyID, _ := cmd.Flags().GetString("property")
-	
-	fmt.Printf("🔧 Starting interactive query builder for property %s\n", propertyID)
+	fmt.Printf("📊 Exporting result %s for Looker Studio...\n", queryID)
 
-	// Create data client
-	dataClient, err := createDataClientWithCache()
+	// Get active preset for cache access
+	activePreset, err := preset.GetActivePreset()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if activePreset == nil {
+		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
+		os.Exit(exitcode.Auth)
 	}
-	defer dataClient.Close()
 
-	// Create query builder
-	builder := query.NewQueryBuilder(dataClient, propertyID)
+	// Create cache client and results manager
+	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer cacheClient.Close()
 
-	// Build query interactively
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second) // 5 minutes
+	resultsManager := results.NewManager(cacheClient)
+	ctx, cancel := commandContext(60*time.Second)
 	defer cancel()
 
-	config, err := builder.BuildInteractively(ctx)
+	result, err := resultsManager.GetResult(ctx, queryID)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Query building failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to get result: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Validate the query
-	if err := builder.ValidateQuery(config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Query validation failed: %v\n", err)
-		os.Exit(1)
+	files, err := export.WriteLookerStudioExport(result, outputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Ask if user wants to execute now
-	fmt.Println("\n🎯 Query Configuration Complete!")
-	fmt.Printf("📊 Property: %s\n", config.PropertyID)
-	fmt.Printf("📏 Dimensions: %s\n", strings.Join(config.Dimensions, ", "))
-	fmt.Printf("📈 Metrics: %s\n", strings.Join(config.Metrics, ", "))
-	fmt.Printf("📅 Date Range: %s to %s\n", config.StartDate, config.EndDate)
-	fmt.Printf("🔢 Limit: %d rows\n", config.Limit)
-	if len(config.Filters) > 0 {
-		fmt.Printf("🔍 Filters: %d applied\n", len(config.Filters))
-	}
+	fmt.Printf("✅ Exported %d fields and %d rows\n", len(result.DimensionHeaders)+len(result.MetricHeaders), len(result.Rows))
+	fmt.Printf("📁 Connector schema (getSchema): %s\n", files.Schema)
+	fmt.Printf("📁 Data extract (getData):      %s\n", files.Data)
+	fmt.Printf("📁 CSV for file upload:          %s\n", files.CSV)
+	fmt.Println("💡 Quickest path: upload the CSV via Looker Studio's File Upload connector")
+}
 
-	fmt.Print("\nExecute this query now? (y/N): ")
-	var execute string
-	fmt.Scanln(&execute)
+func exportDBTCmd(cmd *cobra.Command, args []string) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	sourceName, _ := cmd.Flags().GetString("source-name")
 
-	if strings.ToLower(strings.TrimSpace(execute)) == "y" {
-		fmt.Println("\n🚀 Executing query...")
-		
-		executor := query.NewExecutor(dataClient)
-		result, err := executor.Execute(ctx, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
-			os.Exit(1)
-		}
+	fmt.Printf("🧱 Generating dbt sources from %s...\n", dbPath)
 
-		fmt.Printf("✅ Query completed! Returned %d rows in %s\n", result.RowCount, result.ExecutionTime)
-		fmt.Printf("💡 Query ID: %s\n", result.QueryID)
-	} else {
-		fmt.Println("Query configuration saved but not executed.")
+	ctx, cancel := commandContext(60*time.Second)
+	defer cancel()
+
+	result, err := export.GenerateDBTProject(ctx, dbPath, outputDir, sourceName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to generate dbt project files: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-}
 
-func queryListCmd(cmd *cobra.Command, args []string) {
-	propertyFilter, _ := cmd.Flags().GetString("property")
-	limit, _ := cmd.Flags().GetInt("limit")
+	for _, table := range result.Tables {
+		kind := "table"
+		if table.IsView {
+			kind = "view"
+		}
+		fmt.Printf("   • %-28s %-5s %d columns\n", table.Name, kind, len(table.Columns))
+	}
 
-	fmt.Println("📋 Cached Queries:")
+	fmt.Printf("\n✅ Sources: %s\n", result.SourcesFile)
+	fmt.Printf("✅ Staging models written: %d", len(result.Models))
+	if skipped := len(result.Tables) - len(result.Models); skipped > 0 {
+		fmt.Printf(" (%d existing models left unchanged)", skipped)
+	}
 	fmt.Println()
+	fmt.Printf("💡 Point a dbt-duckdb profile at %s and run 'dbt build --select staging'\n", dbPath)
+}
+
+func exportWorkbookCmd(cmd *cobra.Command, args []string) {
+	outputFile := args[0]
+	accountID, _ := cmd.Flags().GetString("account")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	fmt.Printf("📖 Building field workbook for account %s...\n", accountID)
 
-	// Get active preset for cache access
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create cache client and results manager
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	adminClient, err := api.NewAdminClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer cacheClient.Close()
 
-	resultsManager := results.NewManager(cacheClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	listCtx, listCancel := commandContext(30*time.Second)
+	properties, err := adminClient.ListProperties(listCtx, accountID)
+	listCancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list properties: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if len(properties) == 0 {
+		fmt.Printf("❌ No properties found for account %s\n", accountID)
+		return
+	}
 
-	var resultsList []results.ResultSummary
-	if propertyFilter != "" {
-		resultsList, err = resultsManager.ListResults(ctx, propertyFilter, limit)
-	} else {
-		// TODO: List results for all properties
-		fmt.Fprintf(os.Stderr, "Error: Property filter is required for now\n")
-		os.Exit(1)
+	propertyIDs := make([]string, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID
 	}
 
+	dataClient, err := createDataClientWithCache()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to list results: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	defer dataClient.Close()
 
-	if len(resultsList) == 0 {
-		fmt.Println("❌ No cached queries found")
-		fmt.Println("💡 Run 'ga4admin query run' to create your first query")
-		return
-	}
+	fmt.Printf("🏠 Found %d propert(y/ies), fetching metadata with concurrency %d\n", len(propertyIDs), concurrency)
 
-	// Display results
-	for i, summary := range resultsList {
-		fmt.Printf("🔍 %s\n", summary.QueryID)
-		fmt.Printf("   📊 %d rows • 📅 %s\n", summary.RowCount, summary.CreatedAt.Format("2006-01-02 15:04"))
-		if summary.TableName != "" {
-			fmt.Printf("   🏷️  %s\n", summary.TableName)
+	ctx, cancel := commandContext(10*time.Minute)
+	defer cancel()
+
+	// Warming fetches in parallel; the loop below then reads from the cache
+	warmResults := dataClient.WarmMetadata(ctx, propertyIDs, concurrency, refresh, nil)
+
+	entries := make([]dictionary.AccountProperty, len(properties))
+	failed := 0
+	for i, property := range properties {
+		entry := dictionary.AccountProperty{ID: property.ID, Name: property.DisplayName}
+		entry.Err = warmResults[i].Err
+
+		var metadata *api.MetadataResponse
+		if entry.Err == nil {
+			metadata, entry.Err = dataClient.GetMetadata(ctx, property.ID)
 		}
-		if summary.IsExpired {
-			fmt.Printf("   ⏰ Expired\n")
+		if entry.Err != nil {
+			failed++
+			fmt.Printf("   ❌ %s (ID: %s): %v\n", property.DisplayName, property.ID, entry.Err)
+			entries[i] = entry
+			continue
 		}
-		
-		if i < len(resultsList)-1 {
-			fmt.Println()
+
+		// Display names and descriptions of custom dimensions come from the Admin API
+		customDimensions, err := adminClient.ListCustomDimensions(ctx, property.ID)
+		if err != nil {
+			fmt.Printf("   ⚠️  %s (ID: %s): custom dimension details unavailable: %v\n", property.DisplayName, property.ID, err)
 		}
+		entry.Dictionary = dictionary.Build(property.ID, metadata, customDimensions)
+		entries[i] = entry
+		fmt.Printf("   ✅ %s (ID: %s): %d dimensions, %d metrics (%d custom definitions)\n",
+			property.DisplayName, property.ID, len(entry.Dictionary.Dimensions), len(entry.Dictionary.Metrics), len(entry.Dictionary.Custom()))
 	}
 
-	fmt.Printf("\n💡 Showing %d of cached queries\n", len(resultsList))
-	fmt.Printf("💡 Use 'ga4admin results show <query-id>' to see details\n")
-}
-
-// Results command handlers
-
-func resultsListCmd(cmd *cobra.Command, args []string) {
-	propertyFilter, _ := cmd.Flags().GetString("property")
-	limit, _ := cmd.Flags().GetInt("limit")
+	if err := dictionary.WriteAccountXLSX(entries, outputFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 
-	fmt.Println("📊 Cached Query Results:")
 	fmt.Println()
-
-	if propertyFilter == "" {
-		fmt.Fprintf(os.Stderr, "Error: --property flag is required\n")
-		os.Exit(1)
+	fmt.Printf("✅ Documented %d of %d propert(y/ies)\n", len(properties)-failed, len(properties))
+	fmt.Printf("📁 File: %s\n", outputFile)
+	if failed > 0 {
+		fmt.Printf("⚠️  %d propert(y/ies) failed and are only listed on the Summary sheet\n", failed)
+		os.Exit(exitcode.Failure)
 	}
+}
+
+func serveCmdHandler(cmd *cobra.Command, args []string) {
+	listen, _ := cmd.Flags().GetString("listen")
+	propertyID, _ := cmd.Flags().GetString("property")
+	maxRows, _ := cmd.Flags().GetInt("max-rows")
+	queryTimeout, _ := cmd.Flags().GetDuration("query-timeout")
 
-	// Get active preset for cache access
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
-
-	// Create cache client and results manager
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+	if propertyID != "" {
+		ensurePropertyAccess(activePreset, propertyID)
 	}
-	defer cacheClient.Close()
 
-	resultsManager := results.NewManager(cacheClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	token := os.Getenv(serve.TokenEnv)
+	generated := token == ""
+	if generated {
+		token, err = serve.GenerateToken()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Failure)
+		}
+	}
 
-	resultsList, err := resultsManager.ListResults(ctx, propertyFilter, limit)
+	server, err := serve.New(serve.Options{
+		Preset:       activePreset.Name,
+		PropertyID:   propertyID,
+		Token:        token,
+		MaxRows:      maxRows,
+		QueryTimeout: queryTimeout,
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to list results: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	defer server.Close()
 
-	if len(resultsList) == 0 {
-		fmt.Printf("❌ No cached results found for property %s\n", propertyFilter)
-		fmt.Println("💡 Run 'ga4admin query run' to create results")
-		return
+	// Runs until interrupted, or until --timeout if one was given
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
 	}
 
-	// Display results
-	for i, summary := range resultsList {
-		statusIcon := "✅"
-		if summary.IsExpired {
-			statusIcon = "⏰"
-		}
+	if err := server.Sync(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
 
-		fmt.Printf("%s %s\n", statusIcon, summary.QueryID)
-		fmt.Printf("   📊 %d rows • 📅 %s • 🔄 %s\n", 
-			summary.RowCount, 
-			summary.CreatedAt.Format("2006-01-02 15:04"),
-			summary.LastAccessed.Format("2006-01-02 15:04"))
-		
-		if summary.TableName != "" {
-			fmt.Printf("   🏷️  %s: %s\n", summary.TableName, summary.Description)
-		}
-		
-		if i < len(resultsList)-1 {
-			fmt.Println()
-		}
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failure)
 	}
+	httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
 
-	fmt.Printf("\n💡 Total: %d cached results\n", len(resultsList))
-	fmt.Printf("💡 Use 'ga4admin results show <query-id>' for detailed view\n")
-}
+	fmt.Printf("🗄️  Serving %d tables of preset '%s' at http://%s/sql\n", len(server.Tables()), activePreset.Name, listener.Addr())
+	if generated {
+		fmt.Printf("🔑 Token: %s\n", token)
+		fmt.Printf("💡 Set %s to keep the token across restarts\n", serve.TokenEnv)
+	}
+	if host, _, _ := net.SplitHostPort(listen); host != "127.0.0.1" && host != "localhost" && host != "::1" {
+		fmt.Println("💡 Requests are plain HTTP; put the server behind TLS when it is reachable beyond this machine")
+	}
+	fmt.Println("Press Ctrl+C to stop")
 
-func resultsShowCmd(cmd *cobra.Command, args []string) {
-	queryID := args[0]
-	maxRows, _ := cmd.Flags().GetInt("max-rows")
-	maxWidth, _ := cmd.Flags().GetInt("max-width")
-	showTotals, _ := cmd.Flags().GetBool("show-totals")
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failure)
+	}
+	fmt.Println("\n👋 Stopped serving")
+}
 
-	fmt.Printf("📊 Query Result: %s\n", queryID)
+func mcpCmdHandler(cmd *cobra.Command, args []string) {
+	maxRows, _ := cmd.Flags().GetInt64("max-rows")
 
-	// Get active preset for cache access
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create cache client and results manager
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	dataClient, err := createDataClientWithCache()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer cacheClient.Close()
+	defer dataClient.Close()
 
-	resultsManager := results.NewManager(cacheClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	result, err := resultsManager.GetResult(ctx, queryID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get result: %v\n", err)
-		os.Exit(1)
+	// stdout carries the protocol, so everything else goes to stderr
+	fmt.Fprintf(os.Stderr, "🤖 Serving MCP tools for preset '%s' on stdin/stdout\n", activePreset.Name)
+	server := mcp.NewServer("ga4admin", version, mcpTools(activePreset, dataClient, maxRows))
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failure)
 	}
+}
 
-	// Show metadata
-	fmt.Printf("📈 Property: %s\n", result.PropertyID)
-	fmt.Printf("📅 Executed: %s (%s)\n", result.ExecutedAt.Format("2006-01-02 15:04:05"), result.ExecutionTime)
-	fmt.Printf("📊 Rows: %d\n", result.RowCount)
-	if result.FromCache {
-		fmt.Printf("⚡ From cache\n")
-	}
-	
-	// Show query configuration
-	if result.QueryConfig != nil {
-		fmt.Printf("📏 Dimensions: %s\n", strings.Join(result.QueryConfig.Dimensions, ", "))
-		fmt.Printf("📈 Metrics: %s\n", strings.Join(result.QueryConfig.Metrics, ", "))
-		fmt.Printf("📅 Date range: %s to %s\n", result.QueryConfig.StartDate, result.QueryConfig.EndDate)
-	}
-	fmt.Println()
+// mcpTools are the tools 'ga4admin mcp' offers. They go through the same
+// cache, property access checks and quota tracking as the CLI.
+func mcpTools(activePreset *config.Preset, dataClient *api.DataClient, maxRows int64) []mcp.Tool {
+	executor := newQueryExecutor(dataClient)
 
-	// Show data table
-	if result.RowCount > 0 {
-		lines, err := resultsManager.FormatResultTable(result, maxRows, maxWidth)
+	// propertyArgument resolves a property ID or alias and checks the preset can read it
+	propertyArgument := func(ctx context.Context, value string) (string, error) {
+		if value == "" {
+			return "", fmt.Errorf("property_id is required")
+		}
+		propertyID, err := preset.ResolveProperty(activePreset, value)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error formatting table: %v\n", err)
-		} else {
-			for _, line := range lines {
-				fmt.Println(line)
-			}
+			return "", err
+		}
+		if err := access.CheckPropertyAccess(ctx, activePreset, propertyID); err != nil {
+			return "", err
 		}
+		return propertyID, nil
+	}
+
+	return []mcp.Tool{
+		{
+			Name:        "list_accounts",
+			Description: "List the Google Analytics accounts the credentials can access.",
+			InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			Handler: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+				cacheClient := openListingCache()
+				if cacheClient != nil {
+					defer cacheClient.Close()
+				}
+				accounts, _, err := getAccountsWithClient(cacheClient, false)
+				return accounts, err
+			},
+		},
+		{
+			Name:        "list_properties",
+			Description: "List the GA4 properties of an account, with their time zones and currencies.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_id": map[string]interface{}{"type": "string", "description": "Account ID, from list_accounts"},
+				},
+				"required": []string{"account_id"},
+			},
+			Handler: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+				var params struct {
+					AccountID string `json:"account_id"`
+				}
+				if err := json.Unmarshal(arguments, &params); err != nil {
+					return nil, err
+				}
+				if params.AccountID == "" {
+					return nil, fmt.Errorf("account_id is required")
+				}
+				cacheClient := openListingCache()
+				if cacheClient != nil {
+					defer cacheClient.Close()
+				}
+				properties, _, err := getPropertiesWithClient(cacheClient, params.AccountID, false)
+				return properties, err
+			},
+		},
+		{
+			Name:        "get_metadata",
+			Description: "List the dimensions and metrics a property can be queried by, including its custom definitions. Use search to narrow the list.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"property_id": map[string]interface{}{"type": "string", "description": "Property ID or alias"},
+					"type":        map[string]interface{}{"type": "string", "enum": []string{"dimensions", "metrics", "all"}, "description": "Which fields to list (default all)"},
+					"search":      map[string]interface{}{"type": "string", "description": "Only fields whose name, UI name or category contains this text; descriptions are included"},
+				},
+				"required": []string{"property_id"},
+			},
+			Handler: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+				var params struct {
+					PropertyID string `json:"property_id"`
+					Type       string `json:"type"`
+					Search     string `json:"search"`
+				}
+				if err := json.Unmarshal(arguments, &params); err != nil {
+					return nil, err
+				}
+				ctx, cancel := commandContext(time.Minute)
+				defer cancel()
+				propertyID, err := propertyArgument(ctx, params.PropertyID)
+				if err != nil {
+					return nil, err
+				}
+				metadata, err := dataClient.GetMetadata(ctx, propertyID)
+				if err != nil {
+					return nil, err
+				}
 
-		// Show totals if requested and available
-		if showTotals && len(result.Totals) > 0 {
-			fmt.Println("\n📊 Totals:")
-			for _, total := range result.Totals {
-				fmt.Printf("   ")
-				for _, value := range total.MetricValues {
-					fmt.Printf("%-15s ", value.Value)
+				type field struct {
+					APIName     string `json:"api_name"`
+					UIName      string `json:"ui_name"`
+					Category    string `json:"category,omitempty"`
+					Type        string `json:"type,omitempty"`
+					Custom      bool   `json:"custom,omitempty"`
+					Description string `json:"description,omitempty"`
+				}
+				search := strings.ToLower(params.Search)
+				matches := func(f field) bool {
+					return search == "" || strings.Contains(strings.ToLower(f.APIName+" "+f.UIName+" "+f.Category), search)
+				}
+				fields := map[string][]field{}
+				if params.Type != "metrics" {
+					fields["dimensions"] = []field{}
+					for _, d := range metadata.Dimensions {
+						f := field{APIName: d.APIName, UIName: d.UIName, Category: d.Category, Custom: d.CustomDefinition}
+						if search != "" {
+							f.Description = d.Description
+						}
+						if matches(f) {
+							fields["dimensions"] = append(fields["dimensions"], f)
+						}
+					}
+				}
+				if params.Type != "dimensions" {
+					fields["metrics"] = []field{}
+					for _, m := range metadata.Metrics {
+						f := field{APIName: m.APIName, UIName: m.UIName, Category: m.Category, Type: m.Type, Custom: m.CustomDefinition}
+						if search != "" {
+							f.Description = m.Description
+						}
+						if matches(f) {
+							fields["metrics"] = append(fields["metrics"], f)
+						}
+					}
+				}
+				return fields, nil
+			},
+		},
+		{
+			Name: "run_query",
+			Description: "Run a GA4 report and return its rows. Results are cached, so repeating a query is free. " +
+				"Dates are YYYY-MM-DD or relative, e.g. 30daysAgo, yesterday, today.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"property_id": map[string]interface{}{"type": "string", "description": "Property ID or alias"},
+					"dimensions":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Dimension API names, from get_metadata"},
+					"metrics":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Metric API names, from get_metadata"},
+					"start_date":  map[string]interface{}{"type": "string", "description": "First day, default 30daysAgo"},
+					"end_date":    map[string]interface{}{"type": "string", "description": "Last day, default yesterday"},
+					"limit":       map[string]interface{}{"type": "integer", "description": fmt.Sprintf("Rows to return (default and maximum %d)", maxRows)},
+					"order_by": map[string]interface{}{
+						"type":        "array",
+						"description": "Sort order, e.g. [{\"field_name\": \"sessions\", \"descending\": true}]",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"field_name": map[string]interface{}{"type": "string"},
+								"descending": map[string]interface{}{"type": "boolean"},
+							},
+							"required": []string{"field_name"},
+						},
+					},
+				},
+				"required": []string{"property_id", "metrics"},
+			},
+			Handler: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+				var config query.QueryConfig
+				if err := json.Unmarshal(arguments, &config); err != nil {
+					return nil, err
+				}
+				if config.StartDate == "" {
+					config.StartDate = "30daysAgo"
+				}
+				if config.EndDate == "" {
+					config.EndDate = "yesterday"
+				}
+				if config.Limit <= 0 || config.Limit > maxRows {
+					config.Limit = maxRows
+				}
+				for i, order := range config.OrderBy {
+					if order.FieldType != "" {
+						continue
+					}
+					config.OrderBy[i].FieldType = "dimension"
+					for _, metric := range config.Metrics {
+						if metric == order.FieldName {
+							config.OrderBy[i].FieldType = "metric"
+						}
+					}
 				}
-				fmt.Println()
-			}
-		}
-	}
 
-	fmt.Printf("\n💡 Export: ga4admin results export %s output.csv\n", queryID)
+				ctx, cancel := commandContext(5 * time.Minute)
+				defer cancel()
+				propertyID, err := propertyArgument(ctx, config.PropertyID)
+				if err != nil {
+					return nil, err
+				}
+				config.PropertyID = propertyID
+
+				result, err := executor.Execute(ctx, &config)
+				if err != nil {
+					return nil, err
+				}
+
+				var columns []string
+				for _, header := range result.DimensionHeaders {
+					columns = append(columns, header.Name)
+				}
+				for _, header := range result.MetricHeaders {
+					columns = append(columns, header.Name)
+				}
+				rows := make([][]string, len(result.Rows))
+				for i, row := range result.Rows {
+					for _, value := range row.DimensionValues {
+						rows[i] = append(rows[i], value.Value)
+					}
+					for _, value := range row.MetricValues {
+						rows[i] = append(rows[i], value.Value)
+					}
+				}
+				return map[string]interface{}{
+					"query_id":    result.QueryID,
+					"property_id": result.PropertyID,
+					"from_cache":  result.FromCache,
+					"row_count":   result.RowCount,
+					"returned":    len(rows),
+					"columns":     columns,
+					"rows":        rows,
+				}, nil
+			},
+		},
+	}
 }
 
-func resultsExportCmd(cmd *cobra.Command, args []string) {
-	queryID := args[0]
-	outputFile := args[1]
-	format, _ := cmd.Flags().GetString("format")
-	prettify, _ := cmd.Flags().GetBool("prettify")
+func askCmdHandler(cmd *cobra.Command, args []string) {
+	propertyRef, _ := cmd.Flags().GetString("property")
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	noAssistant, _ := cmd.Flags().GetBool("no-assistant")
 
-	fmt.Printf("📤 Exporting result %s to %s (%s format)...\n", queryID, outputFile, format)
+	question := strings.TrimSpace(strings.Join(args, " "))
+	if question == "" {
+		fmt.Fprintf(os.Stderr, "Error: the question is empty\n")
+		os.Exit(exitcode.Validation)
+	}
+	if propertyRef == "" {
+		propertyRef = ask.PropertyIn(question)
+	}
+	if propertyRef == "" {
+		fmt.Fprintf(os.Stderr, "Error: no property named - end the question with 'for property <id or alias>' or pass --property\n")
+		os.Exit(exitcode.Validation)
+	}
 
-	// Get active preset for cache access
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
+	}
+	propertyID, err := preset.ResolveProperty(activePreset, propertyRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	ensurePropertyAccess(activePreset, propertyID)
 
-	// Create cache client and results manager
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	fieldCatalog, err := catalog.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failure)
 	}
-	defer cacheClient.Close()
 
-	resultsManager := results.NewManager(cacheClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create data client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(120*time.Second)
 	defer cancel()
 
-	// Export based on format
-	switch strings.ToLower(format) {
-	case "csv":
-		err = resultsManager.ExportToCSV(ctx, queryID, outputFile)
-	case "json":
-		err = resultsManager.ExportToJSON(ctx, queryID, outputFile, prettify)
-	default:
-		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: csv, json\n", format)
-		os.Exit(1)
+	// Custom definitions only come from the property's metadata; without it,
+	// the standard fields still cover most questions
+	metadata, err := dataClient.GetMetadata(ctx, propertyID)
+	if err != nil {
+		fmt.Printf("⚠️  Custom dimensions and metrics not loaded: %v\n", err)
+		metadata = nil
 	}
+	fields := ask.Fields(fieldCatalog, metadata)
 
+	var interpretation *ask.Interpretation
+	appConfig, err := config.LoadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	if settings := appConfig.Assistant; settings.Endpoint != "" && !noAssistant {
+		httpClient, err := api.NewHTTPClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.For(err))
+		}
+		fmt.Printf("🧠 Asking %s...\n", settings.Model)
+		assistant := ask.NewAssistant(httpClient, settings.Endpoint, settings.Model)
+		interpretation, err = assistant.Interpret(ctx, question, fields, time.Now())
+		if err != nil {
+			fmt.Printf("⚠️  Assistant answer not used: %v\n", err)
+			fmt.Printf("💡 Falling back to the field catalog\n")
+		}
+	}
+	if interpretation == nil {
+		interpretation, err = ask.Interpret(question, fields, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "💡 Name fields as they appear in GA4, e.g. 'sessions by country', or browse them with 'ga4admin fields search <text>'\n")
+			os.Exit(exitcode.Validation)
+		}
+	}
+
+	queryConfig := interpretation.Config
+	queryConfig.PropertyID = propertyID
+	queryConfig.Description = question
+	queryConfig.CreatedAt = time.Now()
+	queryConfig.UpdatedAt = time.Now()
+
+	fmt.Printf("\n🎯 Query for \"%s\" (interpreted with %s)\n", question, interpretation.Source)
+	fmt.Printf("📊 Property: %s\n", queryConfig.PropertyID)
+	if len(queryConfig.Dimensions) > 0 {
+		fmt.Printf("📏 Dimensions: %s\n", strings.Join(queryConfig.Dimensions, ", "))
+	}
+	fmt.Printf("📈 Metrics: %s\n", strings.Join(queryConfig.Metrics, ", "))
+	fmt.Printf("📅 Date Range: %s to %s\n", queryConfig.StartDate, queryConfig.EndDate)
+	if queryConfig.Limit > 0 {
+		fmt.Printf("🔢 Limit: %d rows\n", queryConfig.Limit)
+	}
+	orderBy := askOrderBy(queryConfig)
+	if orderBy != "" {
+		fmt.Printf("↕️  Order: %s\n", orderBy)
+	}
+	for _, note := range interpretation.Notes {
+		fmt.Printf("💡 %s\n", note)
+	}
+
+	commandLine := fmt.Sprintf("ga4admin query run --property %s", queryConfig.PropertyID)
+	if len(queryConfig.Dimensions) > 0 {
+		commandLine += " --dimensions " + strings.Join(queryConfig.Dimensions, ",")
+	}
+	commandLine += fmt.Sprintf(" --metrics %s --start-date %s --end-date %s", strings.Join(queryConfig.Metrics, ","), queryConfig.StartDate, queryConfig.EndDate)
+	if queryConfig.Limit > 0 {
+		commandLine += fmt.Sprintf(" --limit %d", queryConfig.Limit)
+	}
+	if orderBy != "" {
+		commandLine += " --order-by " + orderBy
+	}
+	fmt.Printf("💡 Same as: %s\n", commandLine)
+
+	// Fields the assistant made up, or that don't exist for this property,
+	// are caught before anything runs
+	validateQueryFields(dataClient, queryConfig)
+
+	if dryRun {
+		return
+	}
+	if !yes {
+		fmt.Print("\nRun this query? (y/N): ")
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Query not run.")
+			return
+		}
+	}
+
+	fmt.Printf("\n🚀 Executing GA4 query for property %s...\n", queryConfig.PropertyID)
+	executor := newQueryExecutor(dataClient)
+	result, err := executor.Execute(ctx, queryConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Query execution failed: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Query completed successfully!\n")
+	fmt.Printf("📊 Returned %d rows in %s\n", result.RowCount, result.ExecutionTime)
+	if result.FromCache {
+		fmt.Printf("⚡ Results served from cache%s\n", cacheAgeNote(result))
 	}
+	fmt.Println()
 
-	fmt.Printf("✅ Export completed successfully!\n")
-	fmt.Printf("📁 File: %s\n", outputFile)
+	printQueryResult(result)
+	finishQueryRun(dataClient, result, "", "", cache.NameConflictFail)
 }
 
-func resultsStatsCmd(cmd *cobra.Command, args []string) {
-	propertyID, _ := cmd.Flags().GetString("property")
-	
-	if propertyID == "" {
-		fmt.Fprintf(os.Stderr, "Error: --property flag is required\n")
-		os.Exit(1)
+// askOrderBy formats a query's ordering the way --order-by takes it
+func askOrderBy(queryConfig *query.QueryConfig) string {
+	var fields []string
+	for _, orderBy := range queryConfig.OrderBy {
+		if orderBy.Descending {
+			fields = append(fields, "-"+orderBy.FieldName)
+		} else {
+			fields = append(fields, orderBy.FieldName)
+		}
 	}
+	return strings.Join(fields, ",")
+}
 
-	fmt.Printf("📈 Result Statistics for Property %s\n", propertyID)
+func watchCmdHandler(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	limit, _ := cmd.Flags().GetInt64("limit")
 
-	// Get active preset for cache access
+	// Realtime requests still draw on the property's quota
+	if interval < 10*time.Second {
+		fmt.Fprintf(os.Stderr, "Error: --interval must be at least 10s\n")
+		os.Exit(exitcode.Validation)
+	}
+
+	// Get active preset
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
+
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create cache client and results manager
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	ensurePropertyAccess(activePreset, propertyID)
+
+	// Realtime data is never cached
+	dataClient, err := api.NewDataClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer cacheClient.Close()
+	defer dataClient.Close()
 
-	resultsManager := results.NewManager(cacheClient)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Runs until interrupted, or until --timeout if one was given
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+	}
 
-	stats, err := resultsManager.GetResultStats(ctx, propertyID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get stats: %v\n", err)
-		os.Exit(1)
+	stat, _ := os.Stdout.Stat()
+	interactive := stat != nil && stat.Mode()&os.ModeCharDevice != 0
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		snapshot, err := dataClient.GetRealtimeSnapshot(pollCtx, propertyID, limit)
+		cancel()
+
+		if ctx.Err() != nil {
+			fmt.Println("\n👋 Stopped watching")
+			return
+		}
+
+		if interactive {
+			// Clear the screen and redraw from the top-left corner
+			fmt.Print("\033[H\033[2J")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: poll failed: %v (retrying in %s)\n", time.Now().Format("15:04:05"), err, interval)
+		} else {
+			printRealtimeSnapshot(snapshot, interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("\n👋 Stopped watching")
+			return
+		case <-ticker.C:
+		}
 	}
+}
 
-	// Display statistics
-	fmt.Printf("📊 Total Results: %d\n", stats.TotalResults)
-	fmt.Printf("✅ Active: %d • ⏰ Expired: %d\n", stats.ActiveResults, stats.ExpiredResults)
-	fmt.Printf("📈 Total Rows: %s\n", formatNumber(stats.TotalRows))
-	fmt.Printf("📊 Average Rows/Result: %.1f\n", stats.AvgRowsPerResult)
-	
-	if stats.OldestResult != nil {
-		fmt.Printf("⏰ Date Range: %s to %s\n", 
-			stats.OldestResult.Format("2006-01-02"),
-			stats.NewestResult.Format("2006-01-02"))
+func printRealtimeSnapshot(snapshot *api.RealtimeSnapshot, interval time.Duration) {
+	fmt.Printf("⚡ Realtime: property %s • %s • refreshing every %s (Ctrl+C to stop)\n\n",
+		snapshot.PropertyID, snapshot.FetchedAt.Format("15:04:05"), interval)
+	fmt.Printf("👥 Active users (last 30 min): %s\n", formatNumber(snapshot.ActiveUsers))
+	fmt.Printf("📈 Per minute: %s\n\n", chart.Sparkline(countSeries(snapshot.PerMinute)))
+
+	printRealtimeCounts("📄 Top pages/screens", snapshot.ByPage, snapshot.ActiveUsers)
+	printRealtimeCounts("🌍 Top countries", snapshot.ByCountry, snapshot.ActiveUsers)
+}
+
+func printRealtimeCounts(title string, counts []api.RealtimeCount, total int64) {
+	fmt.Printf("%s:\n", title)
+	if len(counts) == 0 {
+		fmt.Println("   (no activity)")
 	}
-	
-	fmt.Printf("📅 Generated: %s\n", stats.GeneratedAt.Format("2006-01-02 15:04:05"))
+	for _, count := range counts {
+		// Page titles are often non-ASCII, so truncate by rune
+		value := count.Value
+		if runes := []rune(value); len(runes) > 45 {
+			value = string(runes[:42]) + "..."
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(count.ActiveUsers) / float64(total) * 100
+		}
+		fmt.Printf("   %-45s %8s  %5.1f%%\n", value, formatNumber(count.ActiveUsers), share)
+	}
+	fmt.Println()
 }
 
-// Cache command handlers
+func exportMappingCmd(cmd *cobra.Command, args []string) {
+	accountID, _ := cmd.Flags().GetString("account")
+	format, _ := cmd.Flags().GetString("format")
+	outputFile, _ := cmd.Flags().GetString("output")
 
-func cacheStatsCmd(cmd *cobra.Command, args []string) {
-	fmt.Println("💾 Cache Statistics:")
+	format = strings.ToLower(format)
+	if format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported format '%s'. Supported: csv, json\n", format)
+		os.Exit(exitcode.Validation)
+	}
+	if outputFile == "" {
+		outputFile = fmt.Sprintf("clarisights_mapping_%s.%s", accountID, format)
+	}
 
-	// Get active preset for cache access
+	fmt.Printf("🗺️  Building Clarisights dimension mapping for account %s...\n", accountID)
+
+	// Get active preset
 	activePreset, err := preset.GetActivePreset()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.For(err))
 	}
+
 	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: No active preset - run 'ga4admin preset use <name>' first\n")
+		os.Exit(exitcode.Auth)
 	}
 
-	// Create cache client
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
+	adminClient, err := api.NewAdminClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Admin API client: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
-	defer cacheClient.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	dataClient, err := createDataClientWithCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
+
+	ctx, cancel := commandContext(10*time.Minute)
 	defer cancel()
 
-	stats, err := cacheClient.GetCacheStats(ctx)
+	mappings, err := export.BuildDimensionMapping(ctx, adminClient, dataClient, accountID, func(propertyID string, err error) {
+		fmt.Fprintf(os.Stderr, "⚠️  Skipping property %s: %v\n", propertyID, err)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get cache stats: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Failed to build mapping: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Display cache statistics
-	fmt.Printf("🎯 Preset: %s\n", activePreset.Name)
-	fmt.Printf("✅ Cache Hits: %d\n", stats.TotalHits)
-	fmt.Printf("❌ Cache Misses: %d\n", stats.TotalMisses)
-	fmt.Printf("📊 Hit Rate: %.1f%%\n", stats.HitRate)
-	fmt.Printf("📁 Cache Entries: %d\n", stats.EntriesCount)
-	fmt.Printf("📅 Created: %s\n", stats.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("🔄 Last Updated: %s\n", stats.UpdatedAt.Format("2006-01-02 15:04:05"))
-	
-	if stats.LastCleanup != nil {
-		fmt.Printf("🧹 Last Cleanup: %s\n", stats.LastCleanup.Format("2006-01-02 15:04:05"))
+	if len(mappings) == 0 {
+		fmt.Println("📭 No custom dimensions found in this account")
+		return
 	}
-}
-
-func cacheCleanupCmd(cmd *cobra.Command, args []string) {
-	expiredOnly, _ := cmd.Flags().GetBool("expired")
-	cleanAll, _ := cmd.Flags().GetBool("all")
 
-	if cleanAll {
-		fmt.Print("⚠️  Are you sure you want to clear ALL cache entries? This cannot be undone. (y/N): ")
-		var confirm string
-		fmt.Scanln(&confirm)
-		if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
-			fmt.Println("❌ Cache cleanup cancelled")
-			return
-		}
+	if format == "json" {
+		err = export.WriteMappingJSON(mappings, outputFile)
+	} else {
+		err = export.WriteMappingCSV(mappings, outputFile)
 	}
-
-	fmt.Println("🧹 Cleaning up cache...")
-
-	// Get active preset for cache access
-	activePreset, err := preset.GetActivePreset()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-	if activePreset == nil {
-		fmt.Fprintf(os.Stderr, "Error: No active preset\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: Export failed: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	// Create cache client
-	cacheClient, err := cache.NewCacheClient(activePreset.Name)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create cache client: %v\n", err)
-		os.Exit(1)
+	properties := make(map[string]bool)
+	fields := make(map[string]bool)
+	for _, mapping := range mappings {
+		properties[mapping.PropertyID] = true
+		fields[mapping.ClarisightsField] = true
 	}
-	defer cacheClient.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	fmt.Printf("✅ Mapped %d custom dimension(s) across %d properties to %d Clarisights field(s)\n", len(mappings), len(properties), len(fields))
+	fmt.Printf("📁 File: %s\n", outputFile)
+	fmt.Println("💡 Review the clarisights_group column before importing - groups are guessed from dimension names")
+}
 
-	if expiredOnly || !cleanAll {
-		// Clean only expired entries
-		deleted, err := cacheClient.CleanupExpiredEntries(ctx)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Cleanup failed: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("✅ Cleaned up %d expired cache entries\n", deleted)
-	} else {
-		// TODO: Implement full cache clearing if needed
-		fmt.Println("❌ Full cache clearing not yet implemented")
-		os.Exit(1)
+func loadFieldCatalog() *catalog.Catalog {
+	fieldCatalog, err := catalog.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
+	return fieldCatalog
 }
 
-// Helper functions for query parsing
-
-func parseFilters(filterStrings []string) ([]query.FilterConfig, error) {
-	filters := make([]query.FilterConfig, 0, len(filterStrings))
-	
-	for _, filterStr := range filterStrings {
-		parts := strings.Split(filterStr, ":")
-		if len(parts) != 4 {
-			return nil, fmt.Errorf("filter must have format 'field:type:operation:value', got: %s", filterStr)
-		}
+func fieldsDescribeCmd(cmd *cobra.Command, args []string) {
+	fieldCatalog := loadFieldCatalog()
 
-		filter := query.FilterConfig{
-			FieldName: strings.TrimSpace(parts[0]),
-			Type:      strings.ToLower(strings.TrimSpace(parts[1])),
+	field, found := fieldCatalog.Lookup(args[0])
+	if !found {
+		fmt.Fprintf(os.Stderr, "Error: '%s' is not a standard GA4 dimension or metric\n", args[0])
+		var names []string
+		for _, field := range fieldCatalog.Fields() {
+			names = append(names, field.APIName)
 		}
-
-		operation := strings.TrimSpace(parts[2])
-		value := strings.TrimSpace(parts[3])
-
-		switch filter.Type {
-		case "string":
-			filter.StringMatchType = operation
-			filter.StringValue = value
-		case "numeric":
-			filter.NumericOperation = operation
-			if numValue, err := strconv.ParseFloat(value, 64); err == nil {
-				filter.NumericValue = numValue
-			} else {
-				return nil, fmt.Errorf("invalid numeric value: %s", value)
+		if suggestions := query.SuggestFields(args[0], names); len(suggestions) > 0 {
+			fmt.Fprintf(os.Stderr, "💡 Did you mean: %s\n", strings.Join(suggestions, ", "))
+		} else if matches := fieldCatalog.Search(args[0]); len(matches) > 0 {
+			fmt.Fprintln(os.Stderr, "💡 Similar fields:")
+			for i, match := range matches {
+				if i == 5 {
+					break
+				}
+				fmt.Fprintf(os.Stderr, "   %s (%s)\n", match.APIName, match.Kind)
 			}
-		default:
-			return nil, fmt.Errorf("unsupported filter type: %s", filter.Type)
 		}
-
-		filters = append(filters, filter)
+		fmt.Fprintln(os.Stderr, "💡 Custom dimensions and metrics are listed by 'ga4admin metadata dimensions --custom-only'")
+		os.Exit(exitcode.NotFound)
 	}
 
-	return filters, nil
+	icon := "📏"
+	if field.Kind == catalog.KindMetric {
+		icon = "📈"
+	}
+	fmt.Printf("%s %s (%s)\n", icon, field.APIName, field.Kind)
+	if !strings.EqualFold(field.APIName, args[0]) {
+		fmt.Printf("⚠️  '%s' is deprecated - use '%s'\n", args[0], field.APIName)
+	}
+	fmt.Printf("🏷️  UI Name: %s\n", field.UIName)
+	fmt.Printf("📂 Category: %s\n", field.Category)
+	if field.Type != "" {
+		fmt.Printf("🔢 Type: %s\n", field.Type)
+	}
+	if field.Expression != "" {
+		fmt.Printf("🧮 Expression: %s\n", field.Expression)
+	}
+	fmt.Printf("📝 %s\n", field.Description)
+	fmt.Printf("🔗 Compatibility: %s\n", catalog.CompatibilityNote(field))
+	if len(field.DeprecatedAPINames) > 0 {
+		fmt.Printf("🕰️  Formerly: %s\n", strings.Join(field.DeprecatedAPINames, ", "))
+	}
 }
 
-func parseOrderBy(orderByStr string, config *query.QueryConfig) (*query.OrderByConfig, error) {
-	orderBy := &query.OrderByConfig{}
-	
-	// Check for descending order (prefix with -)
-	if strings.HasPrefix(orderByStr, "-") {
-		orderBy.Descending = true
-		orderByStr = orderByStr[1:]
+func fieldsListCmd(cmd *cobra.Command, args []string) {
+	kind, _ := cmd.Flags().GetString("kind")
+	category, _ := cmd.Flags().GetString("category")
+	search, _ := cmd.Flags().GetString("search")
+
+	kind = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(kind), "s"))
+	if kind != "" && kind != catalog.KindDimension && kind != catalog.KindMetric {
+		fmt.Fprintf(os.Stderr, "Error: --kind must be '%s' or '%s'\n", catalog.KindDimension, catalog.KindMetric)
+		os.Exit(exitcode.Validation)
 	}
 
-	orderBy.FieldName = strings.TrimSpace(orderByStr)
+	fieldCatalog := loadFieldCatalog()
+	fields := fieldCatalog.Fields()
+	if search != "" {
+		fields = fieldCatalog.Search(search)
+	}
 
-	// Determine field type
-	for _, dim := range config.Dimensions {
-		if dim == orderBy.FieldName {
-			orderBy.FieldType = "dimension"
-			return orderBy, nil
+	count := 0
+	currentKind := ""
+	for _, field := range fields {
+		if kind != "" && field.Kind != kind {
+			continue
+		}
+		if category != "" && !strings.EqualFold(field.Category, category) {
+			continue
 		}
-	}
 
-	for _, metric := range config.Metrics {
-		if metric == orderBy.FieldName {
-			orderBy.FieldType = "metric"
-			return orderBy, nil
+		if search == "" && field.Kind != currentKind {
+			currentKind = field.Kind
+			if count > 0 {
+				fmt.Println()
+			}
+			if field.Kind == catalog.KindDimension {
+				fmt.Println("📏 Dimensions:")
+			} else {
+				fmt.Println("📈 Metrics:")
+			}
+		}
+
+		group := ""
+		if field.CompatibilityGroup == catalog.GroupItem {
+			group = " [item-scoped]"
 		}
+		fmt.Printf("  %-35s %-20s %s%s\n", field.APIName, field.Category, field.UIName, group)
+		count++
 	}
 
-	return nil, fmt.Errorf("field '%s' not found in dimensions or metrics", orderBy.FieldName)
+	if count == 0 {
+		fmt.Println("📭 No fields match the filters")
+		return
+	}
+	fmt.Printf("\n📊 %d field(s) - run 'ga4admin fields describe <field>' for details\n", count)
 }
 
-func exportParseCmd(cmd *cobra.Command, args []string) {
-	inputDir, _ := cmd.Flags().GetString("input-dir")
-	outputDB, _ := cmd.Flags().GetString("output-db")
-	batchSize, _ := cmd.Flags().GetInt("batch-size")
+func fieldsGenerateCmd(cmd *cobra.Command, args []string) {
+	propertyID, _ := cmd.Flags().GetString("property")
+	outputPath, _ := cmd.Flags().GetString("output")
 
-	fmt.Printf("📦 Parsing JSON files from %s into DuckDB\n", inputDir)
-	fmt.Printf("🎯 Output database: %s\n", outputDB)
-	fmt.Printf("⚡ Batch size: %d files\n", batchSize)
+	fmt.Printf("📚 Fetching field metadata for property %s...\n", propertyID)
 
-	// Create parser
-	parser := export.NewJSONParser(outputDB, inputDir)
-	parser.SetBatchSize(batchSize)
+	dataClient, err := api.NewDataClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create Data API client: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+	defer dataClient.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	ctx, cancel := commandContext(60*time.Second)
 	defer cancel()
 
-	// Start parsing
-	start := time.Now()
-	if err := parser.ParseAllJSON(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to parse JSON files: %v\n", err)
-		os.Exit(1)
+	metadata, err := dataClient.GetMetadata(ctx, propertyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to get metadata: %v\n", err)
+		os.Exit(exitcode.For(err))
 	}
 
-	duration := time.Since(start)
-	fmt.Printf("\n✅ Parsing completed in %v\n", duration)
-	fmt.Printf("🗄️  Database ready for analysis: %s\n", outputDB)
-	fmt.Println("\n💡 Try these analysis queries:")
-	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM dimension_summary;\"")
-	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM property_analysis LIMIT 10;\"")
-	fmt.Println("   duckdb", outputDB, "-c \"SELECT * FROM account_rollup;\"")
-}
\ No newline at end of file
+	dimensions, metrics, err := catalog.WriteCatalog(outputPath, metadata)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+
+	fmt.Printf("✅ Wrote %d dimensions and %d metrics to %s\n", dimensions, metrics, outputPath)
+	fmt.Println("💡 Rebuild ga4admin to bundle the updated catalog")
+}
@@ -0,0 +1,57 @@
+// Command release-sign signs a release's checksums.txt for ga4admin self-update.
+//
+//	release-sign -genkey              print a new key pair
+//	release-sign checksums.txt        write checksums.txt.sig using $RELEASE_SIGNING_KEY
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	genKey := flag.Bool("genkey", false, "Generate a new signing key pair")
+	keyEnv := flag.String("key-env", "RELEASE_SIGNING_KEY", "Environment variable holding the base64 private key")
+	flag.Parse()
+
+	if *genKey {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Private key (RELEASE_SIGNING_KEY secret): %s\n", base64.StdEncoding.EncodeToString(private))
+		fmt.Printf("Public key (RELEASE_PUBLIC_KEY variable):  %s\n", base64.StdEncoding.EncodeToString(public))
+		return
+	}
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: release-sign [-key-env VAR] <checksums.txt>\n")
+		os.Exit(2)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(os.Getenv(*keyEnv)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		fmt.Fprintf(os.Stderr, "Error: $%s does not hold a base64 ed25519 private key\n", *keyEnv)
+		os.Exit(1)
+	}
+
+	path := flag.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	signature := ed25519.Sign(ed25519.PrivateKey(key), data)
+	if err := os.WriteFile(path+".sig", []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Signed %s\n", path)
+}
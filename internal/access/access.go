@@ -0,0 +1,143 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// AccessError reports that a preset cannot reach a property, along with the
+// presets whose synced account lists show they can
+type AccessError struct {
+	PropertyID        string
+	PresetName        string
+	PresetsWithAccess []string
+}
+
+func (e *AccessError) Error() string {
+	msg := fmt.Sprintf("preset '%s' does not have access to property %s", e.PresetName, e.PropertyID)
+	if len(e.PresetsWithAccess) > 0 {
+		msg += fmt.Sprintf("; presets with access: [%s]", strings.Join(e.PresetsWithAccess, ", "))
+	} else {
+		msg += "; no synced preset has access (run 'ga4admin preset sync' to refresh account lists)"
+	}
+	return msg
+}
+
+// Unwrap lets errors.Is match api.ErrNotAccessible, so a failed pre-flight
+// check is classified like the same answer from the API
+func (e *AccessError) Unwrap() error {
+	return api.ErrNotAccessible
+}
+
+// IsSynced reports whether the preset's account list has been synced
+func IsSynced(p *config.Preset) bool {
+	return !p.SyncedAt.IsZero()
+}
+
+// PresetHasProperty checks the preset's synced account list for a property
+func PresetHasProperty(p *config.Preset, propertyID string) bool {
+	for _, account := range p.Accounts {
+		for _, property := range account.Properties {
+			if property.ID == propertyID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PresetsWithProperty returns the names of all synced presets that list the property
+func PresetsWithProperty(propertyID string) ([]string, error) {
+	presets, err := preset.ListPresets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %w", err)
+	}
+
+	var names []string
+	for i := range presets {
+		if PresetHasProperty(&presets[i], propertyID) {
+			names = append(names, presets[i].Name)
+		}
+	}
+	return names, nil
+}
+
+// CheckPropertyAccess verifies that the preset can reach a property before any
+// expensive query runs. Properties in a synced preset's account list pass
+// locally; anything else, including properties granted since the last sync,
+// is confirmed with a single Admin API property lookup.
+func CheckPropertyAccess(ctx context.Context, p *config.Preset, propertyID string) error {
+	if IsSynced(p) && PresetHasProperty(p, propertyID) {
+		return nil
+	}
+
+	adminClient, err := api.NewAdminClientForPreset(p.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create Admin API client: %w", err)
+	}
+
+	if _, err := adminClient.GetProperty(ctx, propertyID); err != nil {
+		if errors.Is(err, api.ErrNotAccessible) {
+			return newAccessError(p.Name, propertyID)
+		}
+		return fmt.Errorf("failed to verify property access: %w", err)
+	}
+
+	return nil
+}
+
+// SyncPresetAccounts fetches all accounts and properties visible to the
+// preset and stores them on the preset for offline access checks. With etags
+// set, unchanged listings are confirmed with conditional requests.
+func SyncPresetAccounts(ctx context.Context, p *config.Preset, etags api.ETagStore) error {
+	adminClient, err := api.NewAdminClientForPreset(p.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create Admin API client: %w", err)
+	}
+	if etags != nil {
+		adminClient.SetETagStore(etags)
+	}
+
+	accounts, err := adminClient.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	for i := range accounts {
+		properties, err := adminClient.ListProperties(ctx, accounts[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to list properties for account %s: %w", accounts[i].ID, err)
+		}
+		accounts[i].Properties = properties
+	}
+
+	p.Accounts = accounts
+	p.SyncedAt = time.Now()
+
+	return preset.SavePreset(p)
+}
+
+func newAccessError(presetName, propertyID string) error {
+	accessErr := &AccessError{
+		PropertyID: propertyID,
+		PresetName: presetName,
+	}
+
+	// Other presets are a hint only; failing to load them must not mask the real error
+	if others, err := PresetsWithProperty(propertyID); err == nil {
+		for _, name := range others {
+			if name != presetName {
+				accessErr.PresetsWithAccess = append(accessErr.PresetsWithAccess, name)
+			}
+		}
+	}
+
+	return accessErr
+}
@@ -0,0 +1,86 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// PropertyLookup finds properties by ID for one preset, for their currency
+// and time zone. Synced presets answer locally; other properties are fetched
+// from the Admin API once per lookup. Safe for concurrent use.
+type PropertyLookup struct {
+	presetName string // Empty means the active preset
+
+	mu      sync.Mutex
+	preset  *config.Preset
+	admin   *api.AdminClient
+	fetched map[string]*config.Property
+}
+
+// NewPropertyLookup creates a lookup for the named preset, or the active
+// preset when presetName is empty
+func NewPropertyLookup(presetName string) *PropertyLookup {
+	return &PropertyLookup{
+		presetName: presetName,
+		fetched:    make(map[string]*config.Property),
+	}
+}
+
+// Property returns the property with the given ID
+func (l *PropertyLookup) Property(ctx context.Context, propertyID string) (*config.Property, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if property, ok := l.fetched[propertyID]; ok {
+		return property, nil
+	}
+
+	if l.preset == nil {
+		p, err := l.loadPreset()
+		if err != nil {
+			return nil, err
+		}
+		l.preset = p
+	}
+	for i := range l.preset.Accounts {
+		for j := range l.preset.Accounts[i].Properties {
+			if property := &l.preset.Accounts[i].Properties[j]; property.ID == propertyID {
+				l.fetched[propertyID] = property
+				return property, nil
+			}
+		}
+	}
+
+	if l.admin == nil {
+		admin, err := api.NewAdminClientForPreset(l.presetName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Admin API client: %w", err)
+		}
+		l.admin = admin
+	}
+	property, err := l.admin.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	l.fetched[propertyID] = property
+	return property, nil
+}
+
+func (l *PropertyLookup) loadPreset() (*config.Preset, error) {
+	if l.presetName != "" {
+		return preset.LoadPreset(l.presetName)
+	}
+	p, err := preset.GetActivePreset()
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, preset.ErrNoActivePreset
+	}
+	return p, nil
+}
@@ -0,0 +1,58 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// Router picks the preset to reach each property with during a batch run:
+// the default preset when it has access, otherwise another preset whose
+// synced account list includes the property. Routes are remembered, so each
+// property is checked once.
+type Router struct {
+	preset *config.Preset
+	routes map[string]string
+}
+
+// NewRouter creates a router that prefers the given preset
+func NewRouter(p *config.Preset) *Router {
+	return &Router{preset: p, routes: make(map[string]string)}
+}
+
+// Route returns the name of the preset to query a property with. Presets
+// waiting on re-authentication are passed over. When no preset can reach the
+// property, the default preset's *AccessError is returned.
+func (r *Router) Route(ctx context.Context, propertyID string) (string, error) {
+	if name, ok := r.routes[propertyID]; ok {
+		return name, nil
+	}
+
+	err := CheckPropertyAccess(ctx, r.preset, propertyID)
+	if err == nil {
+		r.routes[propertyID] = r.preset.Name
+		return r.preset.Name, nil
+	}
+
+	var accessErr *AccessError
+	if !errors.As(err, &accessErr) {
+		return "", err
+	}
+	for _, name := range accessErr.PresetsWithAccess {
+		candidate, loadErr := preset.LoadPreset(name)
+		if loadErr != nil || candidate.NeedsReauth {
+			continue
+		}
+		r.routes[propertyID] = name
+		return name, nil
+	}
+	return "", err
+}
+
+// Routed reports whether a property was routed away from the default preset
+func (r *Router) Routed(propertyID string) bool {
+	name, ok := r.routes[propertyID]
+	return ok && name != r.preset.Name
+}
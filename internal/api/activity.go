@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Activity summarizes the API calls of the running command, so progress
+// reports can tell a slow GA4 response from a command that is stuck
+type Activity struct {
+	Completed     int           // Calls that returned, successfully or not
+	InFlight      int           // Calls waiting for a response
+	OldestCall    string        // The longest-waiting call, e.g. "POST analyticsdata.googleapis.com/v1beta/properties/123:runReport"
+	OldestWaiting time.Duration // How long OldestCall has been waiting
+}
+
+var (
+	activityMutex     sync.Mutex
+	activityCompleted int
+	activityNextID    uint64
+	activityInFlight  = make(map[uint64]activityCall)
+)
+
+type activityCall struct {
+	description string
+	start       time.Time
+}
+
+// CurrentActivity reports the API calls made so far
+func CurrentActivity() Activity {
+	activityMutex.Lock()
+	defer activityMutex.Unlock()
+
+	activity := Activity{Completed: activityCompleted, InFlight: len(activityInFlight)}
+	for _, call := range activityInFlight {
+		if waiting := time.Since(call.start); activity.OldestCall == "" || waiting > activity.OldestWaiting {
+			activity.OldestCall = call.description
+			activity.OldestWaiting = waiting
+		}
+	}
+	return activity
+}
+
+// beginCall records a call as in flight; the returned function marks it done
+func beginCall(description string) func() {
+	activityMutex.Lock()
+	activityNextID++
+	id := activityNextID
+	activityInFlight[id] = activityCall{description: description, start: time.Now()}
+	activityMutex.Unlock()
+
+	return func() {
+		activityMutex.Lock()
+		delete(activityInFlight, id)
+		activityCompleted++
+		activityMutex.Unlock()
+	}
+}
+
+// activityTransport records REST calls in the command's activity
+type activityTransport struct {
+	base http.RoundTripper
+}
+
+func (t *activityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done := beginCall(req.Method + " " + req.URL.Host + req.URL.Path)
+	defer done()
+	return t.base.RoundTrip(req)
+}
+
+// activityUnaryInterceptor records gRPC calls in the command's activity
+func activityUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	done := beginCall("gRPC " + method)
+	defer done()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -3,29 +3,57 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"ga4admin/internal/config"
 )
 
+// ErrNotAccessible is returned when a resource does not exist or the current
+// credentials are not allowed to read it (GA4 answers 403 or 404 for both)
+var ErrNotAccessible = errors.New("not found or not accessible")
+
 // AdminClient handles GA4 Admin API operations
 type AdminClient struct {
 	authClient *AuthClient
 	baseURL    string
+	version    string // Preferred API version; "auto" negotiates per call
+	auditor    AuditRecorder
+	auditActor AuditActor
+	etags      ETagStore // Set to make GETs conditional
 }
 
 // NewAdminClient creates a new GA4 Admin API client
 func NewAdminClient() (*AdminClient, error) {
-	authClient, err := NewAuthClient()
+	return NewAdminClientForPreset("")
+}
+
+// NewAdminClientForPreset creates an Admin API client authenticated as the
+// named preset rather than the active one
+func NewAdminClientForPreset(presetName string) (*AdminClient, error) {
+	authClient, err := NewAuthClientForPreset(presetName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create auth client: %w", err)
 	}
 
+	version, err := config.GetAdminAPIVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Admin API version: %w", err)
+	}
+
+	endpoint, err := config.GetAdminAPIEndpoint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Admin API endpoint: %w", err)
+	}
+
 	return &AdminClient{
 		authClient: authClient,
-		baseURL:    "https://analyticsadmin.googleapis.com/v1alpha",
+		baseURL:    endpoint,
+		version:    version,
 	}, nil
 }
 
@@ -75,16 +103,10 @@ type propertyResponse struct {
 }
 
 // ListAccounts retrieves all GA4 accounts accessible by the current preset
-func (c *AdminClient) ListAccounts(ctx context.Context) ([]config.Account, error) {
-	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/accounts", c.baseURL)
-	resp, err := httpClient.Get(url)
+func (c *AdminClient) ListAccounts(ctx context.Context, opts ...AdminCallOption) ([]config.Account, error) {
+	resp, err := c.get(ctx, "/accounts", opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to GA4 Admin API: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -129,17 +151,11 @@ func (c *AdminClient) ListAccounts(ctx context.Context) ([]config.Account, error
 }
 
 // ListProperties retrieves all properties accessible to the current user for a given account
-func (c *AdminClient) ListProperties(ctx context.Context, accountID string) ([]config.Property, error) {
-	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
-	}
-
+func (c *AdminClient) ListProperties(ctx context.Context, accountID string, opts ...AdminCallOption) ([]config.Property, error) {
 	// GA4 Admin API requires a filter parameter for listing properties
-	url := fmt.Sprintf("%s/properties?filter=parent:accounts/%s", c.baseURL, accountID)
-	resp, err := httpClient.Get(url)
+	resp, err := c.get(ctx, fmt.Sprintf("/properties?filter=parent:accounts/%s", accountID), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to GA4 Admin API: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -191,21 +207,15 @@ func (c *AdminClient) ListProperties(ctx context.Context, accountID string) ([]c
 }
 
 // GetProperty retrieves detailed information for a specific property
-func (c *AdminClient) GetProperty(ctx context.Context, propertyID string) (*config.Property, error) {
-	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
+func (c *AdminClient) GetProperty(ctx context.Context, propertyID string, opts ...AdminCallOption) (*config.Property, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/properties/%s", propertyID), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/properties/%s", c.baseURL, propertyID)
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request to GA4 Admin API: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("property %s not found or not accessible", propertyID)
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -249,6 +259,168 @@ func (c *AdminClient) GetProperty(ctx context.Context, propertyID string) (*conf
 	return property, nil
 }
 
+// KeyEvent is an event marked as a key event (conversion) on a property
+type KeyEvent struct {
+	Name           string    `json:"name"`           // "properties/328687832/keyEvents/123"
+	EventName      string    `json:"eventName"`      // "purchase"
+	CreateTime     time.Time `json:"createTime"`
+	Deletable      bool      `json:"deletable"`      // false for built-in key events like purchase
+	Custom         bool      `json:"custom"`
+	CountingMethod string    `json:"countingMethod"` // "ONCE_PER_EVENT" or "ONCE_PER_SESSION"
+}
+
+type keyEventsResponse struct {
+	KeyEvents     []KeyEvent `json:"keyEvents"`
+	NextPageToken string     `json:"nextPageToken"`
+}
+
+// ListKeyEvents retrieves all key events (formerly conversion events) configured on a property
+func (c *AdminClient) ListKeyEvents(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]KeyEvent, error) {
+	var keyEvents []KeyEvent
+	pageToken := ""
+	for {
+		path := fmt.Sprintf("/properties/%s/keyEvents?pageSize=200", propertyID)
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.get(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError("Admin", resp)
+			resp.Body.Close()
+			return nil, apiErr
+		}
+
+		var apiResponse keyEventsResponse
+		err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key events response: %w", err)
+		}
+
+		keyEvents = append(keyEvents, apiResponse.KeyEvents...)
+
+		if apiResponse.NextPageToken == "" {
+			break
+		}
+		pageToken = apiResponse.NextPageToken
+	}
+
+	return keyEvents, nil
+}
+
+// ChannelGroup is a system-defined or custom channel group on a property
+type ChannelGroup struct {
+	Name          string         `json:"name"`          // "properties/328687832/channelGroups/456"
+	DisplayName   string         `json:"displayName"`
+	Description   string         `json:"description"`
+	GroupingRules []GroupingRule `json:"groupingRule"`
+	SystemDefined bool           `json:"systemDefined"`
+	Primary       bool           `json:"primary"`
+}
+
+// ID returns the numeric channel group ID from the resource name
+func (g ChannelGroup) ID() string {
+	if i := strings.LastIndex(g.Name, "/"); i >= 0 {
+		return g.Name[i+1:]
+	}
+	return g.Name
+}
+
+// GroupingRule assigns traffic matching Expression to the channel DisplayName.
+// Rules are evaluated in order and the first match wins.
+type GroupingRule struct {
+	DisplayName string                       `json:"displayName"`
+	Expression  ChannelGroupFilterExpression `json:"expression"`
+}
+
+// ChannelGroupFilterExpression is a boolean tree of channel group filters;
+// exactly one field is set
+type ChannelGroupFilterExpression struct {
+	AndGroup      *ChannelGroupFilterExpressionList `json:"andGroup,omitempty"`
+	OrGroup       *ChannelGroupFilterExpressionList `json:"orGroup,omitempty"`
+	NotExpression *ChannelGroupFilterExpression     `json:"notExpression,omitempty"`
+	Filter        *ChannelGroupFilter               `json:"filter,omitempty"`
+}
+
+type ChannelGroupFilterExpressionList struct {
+	FilterExpressions []ChannelGroupFilterExpression `json:"filterExpressions"`
+}
+
+// ChannelGroupFilter matches one field such as "eachScopeSource"
+type ChannelGroupFilter struct {
+	FieldName    string                    `json:"fieldName"`
+	StringFilter *ChannelGroupStringFilter `json:"stringFilter,omitempty"`
+	InListFilter *ChannelGroupInListFilter `json:"inListFilter,omitempty"`
+}
+
+type ChannelGroupStringFilter struct {
+	MatchType string `json:"matchType"` // EXACT, BEGINS_WITH, ENDS_WITH, CONTAINS, FULL_REGEXP, PARTIAL_REGEXP
+	Value     string `json:"value"`
+}
+
+type ChannelGroupInListFilter struct {
+	Values []string `json:"values"`
+}
+
+type channelGroupsResponse struct {
+	ChannelGroups []ChannelGroup `json:"channelGroups"`
+	NextPageToken string         `json:"nextPageToken"`
+}
+
+// ListChannelGroups retrieves the system-defined and custom channel groups on a property
+func (c *AdminClient) ListChannelGroups(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]ChannelGroup, error) {
+	var channelGroups []ChannelGroup
+	pageToken := ""
+	for {
+		path := fmt.Sprintf("/properties/%s/channelGroups?pageSize=200", propertyID)
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.get(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError("Admin", resp)
+			resp.Body.Close()
+			return nil, apiErr
+		}
+
+		var apiResponse channelGroupsResponse
+		err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode channel groups response: %w", err)
+		}
+
+		channelGroups = append(channelGroups, apiResponse.ChannelGroups...)
+
+		if apiResponse.NextPageToken == "" {
+			break
+		}
+		pageToken = apiResponse.NextPageToken
+	}
+
+	return channelGroups, nil
+}
+
 // Helper function to extract ID from GA4 resource names
 func extractIDFromResource(resourceName, prefix string) string {
 	if len(resourceName) <= len(prefix) {
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"ga4admin/internal/config"
+)
+
+var auditPropertyPattern = regexp.MustCompile(`properties/(\d+)`)
+
+// AuditRecorder stores a record of every Admin API write
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, record config.AuditRecord) error
+}
+
+// AuditActor identifies who is writing, for audit records
+type AuditActor struct {
+	Preset  string
+	User    string
+	Command string
+}
+
+// SetAuditRecorder makes the client record every write it sends. Updates,
+// deletes and archives first read the resource so its previous state can be
+// recorded.
+func (c *AdminClient) SetAuditRecorder(recorder AuditRecorder, actor AuditActor) {
+	c.auditor = recorder
+	c.auditActor = actor
+}
+
+// auditBefore reads the resource a write is about to change. Creates have no
+// previous state, and a failed read never blocks the write.
+func (c *AdminClient) auditBefore(ctx context.Context, method, path string, opts []AdminCallOption) string {
+	if c.auditor == nil || (method == http.MethodPost && !strings.HasSuffix(auditResource(path), ":archive")) {
+		return ""
+	}
+
+	resp, err := c.get(ctx, "/"+strings.TrimSuffix(auditResource(path), ":archive"), opts)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return compactJSON(resp.Body)
+}
+
+// recordWrite stores one write. Failures to record never fail the write.
+func (c *AdminClient) recordWrite(ctx context.Context, method, path string, request []byte, before string, response []byte, writeErr error) {
+	if c.auditor == nil {
+		return
+	}
+
+	resource := auditResource(path)
+	after := ""
+	if len(response) > 0 {
+		after = compactJSON(bytes.NewReader(response))
+		var created struct {
+			Name string `json:"name"`
+		}
+		// Creates are recorded under the new resource's name
+		if method == http.MethodPost && json.Unmarshal(response, &created) == nil && created.Name != "" {
+			resource = created.Name
+		}
+	}
+	if after == "{}" {
+		after = ""
+	}
+
+	record := config.AuditRecord{
+		RecordedAt: time.Now(),
+		Preset:     c.auditActor.Preset,
+		User:       c.auditActor.User,
+		Command:    c.auditActor.Command,
+		Method:     method,
+		Resource:   resource,
+		Request:    string(request),
+		Before:     before,
+		After:      after,
+	}
+	if match := auditPropertyPattern.FindStringSubmatch(resource); match != nil {
+		record.PropertyID = match[1]
+	}
+	if writeErr != nil {
+		record.Error = writeErr.Error()
+	}
+
+	// The write's own context may already be done; recording gets a short one
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	c.auditor.RecordAudit(recordCtx, record)
+}
+
+// auditResource strips the leading slash and query string from a request path
+func auditResource(path string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimPrefix(path, "/")
+}
+
+func compactJSON(r io.Reader) string {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
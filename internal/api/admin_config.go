@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Event data retention periods accepted by GA4
+const (
+	RetentionTwoMonths         = "TWO_MONTHS"
+	RetentionFourteenMonths    = "FOURTEEN_MONTHS"
+	RetentionTwentySixMonths   = "TWENTY_SIX_MONTHS"
+	RetentionThirtyEightMonths = "THIRTY_EIGHT_MONTHS"
+	RetentionFiftyMonths       = "FIFTY_MONTHS"
+)
+
+// DataRetentionSettings controls how long GA4 keeps event-level data
+type DataRetentionSettings struct {
+	Name                       string `json:"name,omitempty"`     // "properties/328687832/dataRetentionSettings"
+	EventDataRetention         string `json:"eventDataRetention"` // e.g. FOURTEEN_MONTHS
+	ResetUserDataOnNewActivity bool   `json:"resetUserDataOnNewActivity"`
+}
+
+// channelGroupBody is the writable part of a channel group
+type channelGroupBody struct {
+	DisplayName   string         `json:"displayName"`
+	Description   string         `json:"description,omitempty"`
+	GroupingRules []GroupingRule `json:"groupingRule"`
+}
+
+// sendJSON issues a write with a JSON body and decodes the response into
+// result when it is non-nil. Any non-200 answer becomes an *APIError. Writes
+// are recorded when an audit recorder is set.
+func (c *AdminClient) sendJSON(ctx context.Context, method, path string, body, result interface{}, opts []AdminCallOption) (err error) {
+	var payload []byte
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	before := c.auditBefore(ctx, method, path, opts)
+	var response []byte
+	defer func() {
+		c.recordWrite(ctx, method, path, payload, before, response, err)
+	}()
+
+	resp, err := c.do(ctx, method, path, payload, opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("Admin", resp)
+	}
+	if response, err = io.ReadAll(resp.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if result != nil {
+		if err := json.Unmarshal(response, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateKeyEvent marks an event as a key event on a property
+func (c *AdminClient) CreateKeyEvent(ctx context.Context, propertyID string, keyEvent KeyEvent, opts ...AdminCallOption) (*KeyEvent, error) {
+	body := map[string]string{"eventName": keyEvent.EventName}
+	if keyEvent.CountingMethod != "" {
+		body["countingMethod"] = keyEvent.CountingMethod
+	}
+
+	var created KeyEvent
+	if err := c.sendJSON(ctx, http.MethodPost, fmt.Sprintf("/properties/%s/keyEvents", propertyID), body, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateKeyEventCountingMethod changes how often a key event counts per session
+func (c *AdminClient) UpdateKeyEventCountingMethod(ctx context.Context, name, countingMethod string, opts ...AdminCallOption) error {
+	path := "/" + name + "?updateMask=countingMethod"
+	return c.sendJSON(ctx, http.MethodPatch, path, map[string]string{"countingMethod": countingMethod}, nil, opts)
+}
+
+// DeleteKeyEvent removes a key event by resource name. Built-in key events
+// such as purchase are not deletable.
+func (c *AdminClient) DeleteKeyEvent(ctx context.Context, name string, opts ...AdminCallOption) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/"+name, nil, nil, opts)
+}
+
+// CreateChannelGroup adds a custom channel group to a property
+func (c *AdminClient) CreateChannelGroup(ctx context.Context, propertyID string, group ChannelGroup, opts ...AdminCallOption) (*ChannelGroup, error) {
+	body := channelGroupBody{DisplayName: group.DisplayName, Description: group.Description, GroupingRules: group.GroupingRules}
+
+	var created ChannelGroup
+	if err := c.sendJSON(ctx, http.MethodPost, fmt.Sprintf("/properties/%s/channelGroups", propertyID), body, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateChannelGroup replaces a custom channel group's display name,
+// description and rules
+func (c *AdminClient) UpdateChannelGroup(ctx context.Context, group ChannelGroup, opts ...AdminCallOption) error {
+	body := channelGroupBody{DisplayName: group.DisplayName, Description: group.Description, GroupingRules: group.GroupingRules}
+	path := "/" + group.Name + "?updateMask=" + url.QueryEscape("displayName,description,groupingRule")
+	return c.sendJSON(ctx, http.MethodPatch, path, body, nil, opts)
+}
+
+// DeleteChannelGroup removes a custom channel group by resource name
+func (c *AdminClient) DeleteChannelGroup(ctx context.Context, name string, opts ...AdminCallOption) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/"+name, nil, nil, opts)
+}
+
+// GetDataRetentionSettings reads a property's data retention settings
+func (c *AdminClient) GetDataRetentionSettings(ctx context.Context, propertyID string, opts ...AdminCallOption) (*DataRetentionSettings, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/properties/%s/dataRetentionSettings", propertyID), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("Admin", resp)
+	}
+
+	var settings DataRetentionSettings
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return nil, fmt.Errorf("failed to decode data retention settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateDataRetentionSettings writes a property's data retention settings
+func (c *AdminClient) UpdateDataRetentionSettings(ctx context.Context, propertyID string, settings DataRetentionSettings, opts ...AdminCallOption) error {
+	settings.Name = ""
+	path := fmt.Sprintf("/properties/%s/dataRetentionSettings?updateMask=%s", propertyID, url.QueryEscape("eventDataRetention,resetUserDataOnNewActivity"))
+	return c.sendJSON(ctx, http.MethodPatch, path, settings, nil, opts)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ETagStore keeps Admin API GET responses together with their ETags, so
+// repeated reads can be sent as conditional requests
+type ETagStore interface {
+	GetETag(ctx context.Context, key string) (etag string, body []byte, found bool, err error)
+	StoreETag(ctx context.Context, key, etag string, body []byte) error
+}
+
+// SetETagStore makes GET requests conditional on the ETag stored for their
+// path. GA4 answers 304 Not Modified when the resource is unchanged, and the
+// stored body is returned instead. Responses without an ETag are not stored.
+func (c *AdminClient) SetETagStore(store ETagStore) {
+	c.etags = store
+}
+
+// withIfNoneMatch sends a call as a conditional request
+func withIfNoneMatch(etag string) AdminCallOption {
+	return func(s *adminCallSettings) {
+		s.ifNoneMatch = etag
+	}
+}
+
+// conditionalGet is get with If-None-Match. Callers always see a 200 with
+// the full body, whether it came from the API or the store.
+func (c *AdminClient) conditionalGet(ctx context.Context, path string, opts []AdminCallOption) (*http.Response, error) {
+	// Lookup failures only cost the conditional request
+	etag, stored, found, err := c.etags.GetETag(ctx, path)
+	if err == nil && found && etag != "" {
+		opts = append(opts[:len(opts):len(opts)], withIfNoneMatch(etag))
+	} else {
+		etag = ""
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK (not modified)"
+		resp.Body = io.NopCloser(bytes.NewReader(stored))
+		resp.ContentLength = int64(len(stored))
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		// A failed store only makes the next read unconditional
+		c.etags.StoreETag(ctx, path, resp.Header.Get("ETag"), body)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"ga4admin/internal/config"
+)
+
+// AdminCallOption adjusts a single Admin API call
+type AdminCallOption func(*adminCallSettings)
+
+type adminCallSettings struct {
+	version     string
+	ifNoneMatch string // ETag sent with conditional GETs
+}
+
+// WithAdminAPIVersion pins one call to a specific Admin API version
+// (config.AdminAPIV1Beta or config.AdminAPIV1Alpha), bypassing negotiation
+func WithAdminAPIVersion(version string) AdminCallOption {
+	return func(s *adminCallSettings) {
+		s.version = version
+	}
+}
+
+// callSettings applies opts over the client's defaults
+func (c *AdminClient) callSettings(opts []AdminCallOption) adminCallSettings {
+	settings := adminCallSettings{version: c.version}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return settings
+}
+
+// adminVersions returns the API versions to try, in order, for a call
+func (c *AdminClient) adminVersions(settings adminCallSettings) []string {
+	switch settings.version {
+	case config.AdminAPIV1Beta, config.AdminAPIV1Alpha:
+		return []string{settings.version}
+	default:
+		// Prefer the stable surface; alpha only serves what beta lacks
+		return []string{config.AdminAPIV1Beta, config.AdminAPIV1Alpha}
+	}
+}
+
+// get issues a GET for path (e.g. "/accounts") using version negotiation. When
+// a version doesn't serve the endpoint at all, the next version is tried.
+func (c *AdminClient) get(ctx context.Context, path string, opts []AdminCallOption) (*http.Response, error) {
+	if c.etags != nil {
+		return c.conditionalGet(ctx, path, opts)
+	}
+	return c.do(ctx, http.MethodGet, path, nil, opts)
+}
+
+// post sends a JSON body to path with the same version negotiation as get
+func (c *AdminClient) post(ctx context.Context, path string, body []byte, opts []AdminCallOption) (*http.Response, error) {
+	return c.do(ctx, http.MethodPost, path, body, opts)
+}
+
+func (c *AdminClient) do(ctx context.Context, method, path string, body []byte, opts []AdminCallOption) (*http.Response, error) {
+	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
+	}
+
+	settings := c.callSettings(opts)
+	versions := c.adminVersions(settings)
+	for i, version := range versions {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s%s", c.baseURL, version, path), reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GA4 Admin API request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if settings.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", settings.ifNoneMatch)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request to GA4 Admin API: %w", err)
+		}
+
+		if i < len(versions)-1 && endpointMissing(resp) {
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+
+	// Unreachable: the last version always returns
+	return nil, fmt.Errorf("no Admin API version available for %s", path)
+}
+
+// endpointMissing distinguishes "this API version has no such method" from a
+// missing resource: Google answers unknown paths with a non-JSON 404 page or
+// 501, while missing resources get a JSON NOT_FOUND error
+func endpointMissing(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusNotImplemented:
+		return true
+	case http.StatusNotFound:
+		return !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+	default:
+		return false
+	}
+}
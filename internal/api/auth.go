@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -16,11 +17,17 @@ import (
 const (
 	// OAuth2 scopes required for GA4 API access
 	AnalyticsReadOnlyScope = "https://www.googleapis.com/auth/analytics.readonly"
+	// Needed by commands that change property configuration
+	AnalyticsEditScope = "https://www.googleapis.com/auth/analytics.edit"
 	
 	// Token refresh buffer - refresh tokens 5 minutes before expiry
 	TokenRefreshBuffer = 5 * time.Minute
 )
 
+// ErrReauthRequired means Google rejected a preset's refresh token because it
+// expired or was revoked; 'preset reauth' replaces it
+var ErrReauthRequired = errors.New("refresh token has expired or been revoked")
+
 // AuthClient manages OAuth2 authentication for GA4 API calls
 type AuthClient struct {
 	clientID     string
@@ -32,17 +39,28 @@ type AuthClient struct {
 	cachedToken  *oauth2.Token
 	cacheExpiry  time.Time
 	lastRefreshToken string // Track which refresh token was used for cache
+
+	presetName string // Preset whose refresh token is used; empty means the active preset
 }
 
 // NewAuthClient creates a new authentication client using global OAuth credentials
+// and the active preset's refresh token
 func NewAuthClient() (*AuthClient, error) {
+	return NewAuthClientForPreset("")
+}
+
+// NewAuthClientForPreset creates an authentication client that always uses the
+// named preset's refresh token, whichever preset is active. An empty name
+// follows the active preset.
+func NewAuthClientForPreset(presetName string) (*AuthClient, error) {
 	// Get global OAuth credentials
 	clientID, clientSecret, err := config.GetClientCredentials()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get OAuth credentials: %w", err)
 	}
 
-	if clientID == "" || clientSecret == "" {
+	// Service accounts sign their own token requests and need no OAuth client
+	if (clientID == "" || clientSecret == "") && !ReplayEnabled() && !presetUsesServiceAccount(presetName) {
 		return nil, fmt.Errorf("OAuth credentials not configured - run 'ga4admin config set' first")
 	}
 
@@ -58,23 +76,38 @@ func NewAuthClient() (*AuthClient, error) {
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		config:       oauth2Config,
+		presetName:   presetName,
 	}, nil
 }
 
-// GetAccessToken gets a valid access token using the active preset's refresh token
+// GetAccessToken gets a valid access token using the client's preset's refresh token
 func (a *AuthClient) GetAccessToken(ctx context.Context) (*oauth2.Token, error) {
-	// Get active preset for refresh token
-	activePreset, err := preset.GetActivePreset()
-	if err != nil {
+	// Fixtures are replayed without talking to Google, so no real token is needed
+	if ReplayEnabled() {
+		return &oauth2.Token{AccessToken: "replay", Expiry: time.Now().Add(time.Hour)}, nil
+	}
+
+	// Get the bound (or active) preset for refresh token
+	var activePreset *config.Preset
+	var err error
+	if a.presetName != "" {
+		if activePreset, err = preset.LoadPreset(a.presetName); err != nil {
+			return nil, fmt.Errorf("failed to load preset '%s': %w", a.presetName, err)
+		}
+	} else if activePreset, err = preset.GetActivePreset(); err != nil {
 		return nil, fmt.Errorf("failed to get active preset: %w", err)
 	}
 	
 	if activePreset == nil {
-		return nil, fmt.Errorf("no active preset set - run 'ga4admin preset use <name>' first")
+		return nil, fmt.Errorf("%w - run 'ga4admin preset use <name>' first", preset.ErrNoActivePreset)
+	}
+
+	if UsesServiceAccount(activePreset) {
+		return a.serviceAccountToken(ctx, activePreset)
 	}
 
 	if activePreset.RefreshToken == "" {
-		return nil, fmt.Errorf("active preset '%s' has no refresh token", activePreset.Name)
+		return nil, fmt.Errorf("preset '%s' has no refresh token", activePreset.Name)
 	}
 
 	// Check if we have a cached valid token for this refresh token
@@ -88,8 +121,48 @@ func (a *AuthClient) GetAccessToken(ctx context.Context) (*oauth2.Token, error)
 	}
 	a.tokenMutex.RUnlock()
 
+	// A token saved by an earlier invocation spares the refresh round trip
+	if token := a.loadPersistedToken(activePreset.Name, activePreset.RefreshToken); token != nil {
+		return token, nil
+	}
+
 	// Need to refresh token
-	return a.refreshToken(ctx, activePreset.RefreshToken)
+	token, err := a.refreshToken(ctx, activePreset.RefreshToken)
+	if isInvalidGrant(err) {
+		// Remember it so 'preset list' shows which presets need attention
+		preset.SetNeedsReauth(activePreset.Name, true)
+		forgetPersistedToken(activePreset.Name)
+		return nil, fmt.Errorf("preset '%s': %w - run 'ga4admin preset reauth %s'", activePreset.Name, ErrReauthRequired, activePreset.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if activePreset.NeedsReauth {
+		// The token was replaced outside 'preset reauth'
+		preset.SetNeedsReauth(activePreset.Name, false)
+	}
+	// Failing to save only costs the next invocation a refresh
+	a.persistToken(activePreset.Name, activePreset.RefreshToken, token)
+	return token, nil
+}
+
+// presetUsesServiceAccount reports whether the named preset, or the active
+// preset when the name is empty, authenticates with a service account
+func presetUsesServiceAccount(presetName string) bool {
+	var p *config.Preset
+	if presetName != "" {
+		p, _ = preset.LoadPreset(presetName)
+	} else {
+		p, _ = preset.GetActivePreset()
+	}
+	return UsesServiceAccount(p)
+}
+
+// isInvalidGrant reports whether Google's token endpoint rejected the refresh
+// token itself, as opposed to a network or client configuration problem
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
 }
 
 // refreshToken exchanges a refresh token for a new access token
@@ -109,7 +182,12 @@ func (a *AuthClient) refreshToken(ctx context.Context, refreshToken string) (*oa
 		RefreshToken: refreshToken,
 	}
 
-	// Use OAuth2 client to refresh the token
+	// Use OAuth2 client to refresh the token (through the configured proxy/CA settings)
+	baseClient, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, baseClient)
 	tokenSource := a.config.TokenSource(ctx, token)
 	newToken, err := tokenSource.Token()
 	if err != nil {
@@ -143,6 +221,10 @@ func (a *AuthClient) refreshToken(ctx context.Context, refreshToken string) (*oa
 
 // AuthenticatedHTTPClient returns an HTTP client with automatic OAuth authentication
 func (a *AuthClient) AuthenticatedHTTPClient(ctx context.Context) (*http.Client, error) {
+	if ReplayEnabled() {
+		return baseHTTPClient()
+	}
+
 	// Get valid access token
 	token, err := a.GetAccessToken(ctx)
 	if err != nil {
@@ -155,11 +237,20 @@ func (a *AuthClient) AuthenticatedHTTPClient(ctx context.Context) (*http.Client,
 		ctx:        ctx,
 	})
 
+	baseClient, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
 	// Return HTTP client with automatic auth
-	return oauth2.NewClient(ctx, tokenSource), nil
+	client := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, baseClient), tokenSource)
+	client.Timeout = baseClient.Timeout
+	return client, nil
 }
 
-// ClearTokenCache clears the cached access token (useful for testing or forcing refresh)
+// ClearTokenCache clears the cached access token, in memory and the one saved
+// for the preset, so the next call refreshes it (useful for testing or
+// forcing refresh)
 func (a *AuthClient) ClearTokenCache() {
 	a.tokenMutex.Lock()
 	defer a.tokenMutex.Unlock()
@@ -167,6 +258,16 @@ func (a *AuthClient) ClearTokenCache() {
 	a.cachedToken = nil
 	a.cacheExpiry = time.Time{}
 	a.lastRefreshToken = ""
+
+	presetName := a.presetName
+	if presetName == "" {
+		if activePreset, err := preset.GetActivePreset(); err == nil && activePreset != nil {
+			presetName = activePreset.Name
+		}
+	}
+	if presetName != "" {
+		forgetPersistedToken(presetName)
+	}
 }
 
 // ValidateRefreshToken tests if a refresh token is valid by attempting to refresh it
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// QueryAgeCache is implemented by caches that record when report results
+// were fetched, so served responses can be dated and held to a maximum age
+type QueryAgeCache interface {
+	QueryCachedAt(ctx context.Context, queryID string) (cachedAt time.Time, found bool, err error)
+}
+
+// SetMaxCacheAge makes cached reports fetched longer than maxAge ago count as
+// misses, even when they have not expired, so they are fetched again. Zero
+// accepts any unexpired entry.
+func (c *DataClient) SetMaxCacheAge(maxAge time.Duration) {
+	c.maxCacheAge = maxAge
+}
+
+// withinMaxAge dates a cached response and reports whether it is recent
+// enough to serve. Responses the cache can't date are only served without a
+// maximum age.
+func (c *DataClient) withinMaxAge(ctx context.Context, response *RunReportResponse) bool {
+	ages, ok := c.cacheClient.(QueryAgeCache)
+	if !ok {
+		return c.maxCacheAge == 0
+	}
+	cachedAt, found, err := ages.QueryCachedAt(ctx, response.QueryID)
+	if err != nil || !found {
+		return c.maxCacheAge == 0
+	}
+	response.CachedAt = cachedAt
+	return !c.tooOld(cachedAt)
+}
+
+// tooOld reports whether results cached at cachedAt exceed the maximum age
+func (c *DataClient) tooOld(cachedAt time.Time) bool {
+	return c.maxCacheAge > 0 && time.Since(cachedAt) > c.maxCacheAge
+}
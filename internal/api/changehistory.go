@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// changeHistoryPageSize is the most events searchChangeHistoryEvents returns
+// per page
+const changeHistoryPageSize = 200
+
+// ChangeHistoryQuery narrows a change history search. Zero values match
+// everything.
+type ChangeHistoryQuery struct {
+	PropertyID    string    // Only changes to this property
+	Since         time.Time // Earliest change time
+	Until         time.Time // Latest change time
+	ResourceTypes []string  // e.g. CUSTOM_DIMENSION or custom-dimension
+	Actions       []string  // CREATED, UPDATED or DELETED
+	ActorEmails   []string
+}
+
+// ChangeHistoryEvent is a set of changes made together, e.g. by one save in
+// the GA4 UI
+type ChangeHistoryEvent struct {
+	ID              string                `json:"id"`
+	ChangeTime      time.Time             `json:"changeTime"`
+	ActorType       string                `json:"actorType"`                // USER, SYSTEM or SUPPORT
+	UserActorEmail  string                `json:"userActorEmail,omitempty"` // Set for USER actors
+	ChangesFiltered bool                  `json:"changesFiltered,omitempty"`
+	Changes         []ChangeHistoryChange `json:"changes"`
+}
+
+// Actor names who made the changes
+func (e ChangeHistoryEvent) Actor() string {
+	if e.UserActorEmail != "" {
+		return e.UserActorEmail
+	}
+	if e.ActorType == "SUPPORT" {
+		return "Google support"
+	}
+	return "Google Analytics (system)"
+}
+
+// ChangeHistoryChange is one resource created, updated or deleted. The
+// before and after states hold a single field named for the resource type,
+// e.g. {"customDimension": {...}}.
+type ChangeHistoryChange struct {
+	Resource             string          `json:"resource"` // e.g. "properties/123/customDimensions/456"
+	Action               string          `json:"action"`   // CREATED, UPDATED or DELETED
+	ResourceBeforeChange json.RawMessage `json:"resourceBeforeChange,omitempty"`
+	ResourceAfterChange  json.RawMessage `json:"resourceAfterChange,omitempty"`
+}
+
+// ResourceType returns the changed resource's type as GA4 names it in the
+// change, e.g. "customDimension"
+func (c ChangeHistoryChange) ResourceType() string {
+	kind, _ := changedResource(c.ResourceAfterChange)
+	if kind == "" {
+		kind, _ = changedResource(c.ResourceBeforeChange)
+	}
+	return kind
+}
+
+// DisplayName returns the resource's display name after the change, or
+// before it for deletions
+func (c ChangeHistoryChange) DisplayName() string {
+	for _, state := range []json.RawMessage{c.ResourceAfterChange, c.ResourceBeforeChange} {
+		if _, fields := changedResource(state); fields != nil {
+			if name, ok := fields["displayName"].(string); ok && name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// ChangedFields lists the fields an update changed, sorted
+func (c ChangeHistoryChange) ChangedFields() []string {
+	_, before := changedResource(c.ResourceBeforeChange)
+	_, after := changedResource(c.ResourceAfterChange)
+	if before == nil || after == nil {
+		return nil
+	}
+
+	var fields []string
+	for name, value := range after {
+		if !reflect.DeepEqual(before[name], value) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// changedResource unwraps a ChangeHistoryResource into its type and fields
+func changedResource(state json.RawMessage) (string, map[string]interface{}) {
+	if len(state) == 0 {
+		return "", nil
+	}
+	var wrapper map[string]map[string]interface{}
+	if err := json.Unmarshal(state, &wrapper); err != nil {
+		return "", nil
+	}
+	for kind, fields := range wrapper {
+		return kind, fields
+	}
+	return "", nil
+}
+
+type changeHistoryRequest struct {
+	Property           string   `json:"property,omitempty"`
+	ResourceType       []string `json:"resourceType,omitempty"`
+	Action             []string `json:"action,omitempty"`
+	ActorEmail         []string `json:"actorEmail,omitempty"`
+	EarliestChangeTime string   `json:"earliestChangeTime,omitempty"`
+	LatestChangeTime   string   `json:"latestChangeTime,omitempty"`
+	PageSize           int      `json:"pageSize"`
+	PageToken          string   `json:"pageToken,omitempty"`
+}
+
+type changeHistoryResponse struct {
+	ChangeHistoryEvents []ChangeHistoryEvent `json:"changeHistoryEvents"`
+	NextPageToken       string               `json:"nextPageToken"`
+}
+
+// SearchChangeHistory returns the change history of an account's properties,
+// newest first. GA4 keeps about two years of history.
+func (c *AdminClient) SearchChangeHistory(ctx context.Context, accountID string, query ChangeHistoryQuery, opts ...AdminCallOption) ([]ChangeHistoryEvent, error) {
+	request := changeHistoryRequest{
+		ResourceType: enumValues(query.ResourceTypes),
+		Action:       enumValues(query.Actions),
+		ActorEmail:   query.ActorEmails,
+		PageSize:     changeHistoryPageSize,
+	}
+	if query.PropertyID != "" {
+		request.Property = "properties/" + query.PropertyID
+	}
+	if !query.Since.IsZero() {
+		request.EarliestChangeTime = query.Since.UTC().Format(time.RFC3339)
+	}
+	if !query.Until.IsZero() {
+		request.LatestChangeTime = query.Until.UTC().Format(time.RFC3339)
+	}
+
+	var events []ChangeHistoryEvent
+	for {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode change history request: %w", err)
+		}
+
+		resp, err := c.post(ctx, fmt.Sprintf("/accounts/%s:searchChangeHistoryEvents", accountID), body, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError("Admin", resp)
+			resp.Body.Close()
+			// Change history needs the edit scope, which read-only tokens
+			// lack; keep that error so callers can say so
+			if apiErr.IsInsufficientScope() {
+				return nil, apiErr
+			}
+			if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+				return nil, fmt.Errorf("account %s %w", accountID, ErrNotAccessible)
+			}
+			return nil, apiErr
+		}
+
+		var apiResponse changeHistoryResponse
+		err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode change history response: %w", err)
+		}
+
+		events = append(events, apiResponse.ChangeHistoryEvents...)
+
+		if apiResponse.NextPageToken == "" {
+			break
+		}
+		request.PageToken = apiResponse.NextPageToken
+	}
+
+	return events, nil
+}
+
+// enumValues spells values the way the API's enums are, so "custom-dimension"
+// becomes CUSTOM_DIMENSION
+func enumValues(values []string) []string {
+	var enums []string
+	for _, value := range values {
+		enums = append(enums, strings.ToUpper(strings.ReplaceAll(value, "-", "_")))
+	}
+	return enums
+}
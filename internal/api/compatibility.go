@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+)
+
+// Compatibility values returned by checkCompatibility
+const (
+	CompatibilityCompatible   = "COMPATIBLE"
+	CompatibilityIncompatible = "INCOMPATIBLE"
+)
+
+// CheckCompatibilityRequest asks whether a report's dimensions, metrics and
+// filters can be queried together
+type CheckCompatibilityRequest struct {
+	Property            string            `json:"-"` // Property ID (not in JSON body)
+	Dimensions          []Dimension       `json:"dimensions,omitempty"`
+	Metrics             []Metric          `json:"metrics,omitempty"`
+	DimensionFilter     *FilterExpression `json:"dimensionFilter,omitempty"`
+	MetricFilter        *FilterExpression `json:"metricFilter,omitempty"`
+	CompatibilityFilter string            `json:"compatibilityFilter,omitempty"` // Only return fields with this compatibility
+}
+
+// CheckCompatibilityResponse lists the property's fields with their
+// compatibility with the requested ones
+type CheckCompatibilityResponse struct {
+	DimensionCompatibilities []DimensionCompatibility `json:"dimensionCompatibilities"`
+	MetricCompatibilities    []MetricCompatibility    `json:"metricCompatibilities"`
+}
+
+type DimensionCompatibility struct {
+	DimensionMetadata DimensionMetadata `json:"dimensionMetadata"`
+	Compatibility     string            `json:"compatibility"`
+}
+
+type MetricCompatibility struct {
+	MetricMetadata MetricMetadata `json:"metricMetadata"`
+	Compatibility  string         `json:"compatibility"`
+}
+
+// CheckCompatibility asks the Data API whether a report could run. Responses
+// are not cached since they are cheap and depend on the whole field set.
+func (c *DataClient) CheckCompatibility(ctx context.Context, request *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error) {
+	return c.transport.checkCompatibility(ctx, request)
+}
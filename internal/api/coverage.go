@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ga4admin/internal/config"
+)
+
+// CoverageCache is implemented by caches that can list stored requests, so a
+// report can be assembled from results cached for other date ranges
+type CoverageCache interface {
+	ListFreshQueryParams(ctx context.Context, propertyID string) ([]config.CachedQueryParams, error)
+	GetQuery(ctx context.Context, queryID string, queryParams, resultData interface{}) (*config.CachedQuery, error)
+}
+
+// coverageDimension must be in a request for cached results to be split by
+// day. Other date-range differences change every row's aggregation.
+const coverageDimension = "date"
+
+const coverageDateLayout = "2006-01-02"
+
+// ReportSegment is a slice of a request's date range and where it comes from
+type ReportSegment struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	QueryID   string `json:"query_id,omitempty"` // Cached result covering the slice; empty when the API must fetch it
+}
+
+// ReportPlan describes how RunReport would answer a request
+type ReportPlan struct {
+	ExactQueryID string          `json:"exact_query_id,omitempty"` // Set when this exact request is cached
+	Segments     []ReportSegment `json:"segments,omitempty"`       // Coverage by results cached for other date ranges
+	Reason       string          `json:"reason,omitempty"`         // Why other cached results can't be reused
+}
+
+// FromCache reports whether the request would be answered without calling the API
+func (p *ReportPlan) FromCache() bool {
+	if p.ExactQueryID != "" {
+		return true
+	}
+	if len(p.Segments) == 0 {
+		return false
+	}
+	for _, segment := range p.Segments {
+		if segment.QueryID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// coverageCandidate is a cached result with the request's shape
+type coverageCandidate struct {
+	queryID    string
+	start, end time.Time
+}
+
+// PlanReport shows how a report request would be answered: from the exact
+// cached request, assembled from results cached for other date ranges of the
+// same query, or from the API. It reads the cache without touching hit
+// counts or access times, and skips results older than the maximum age.
+func (c *DataClient) PlanReport(ctx context.Context, request *RunReportRequest) (*ReportPlan, error) {
+	coverageCache, ok := c.cacheClient.(CoverageCache)
+	if !ok {
+		return nil, fmt.Errorf("query plans need the query cache")
+	}
+
+	// Mirror RunReport's defaulting so the request hashes the same way
+	planned := *request
+	if planned.Limit == 0 {
+		planned.Limit = 10000
+	}
+	requestHash := hashRequest(&planned)
+
+	fresh, err := coverageCache.ListFreshQueryParams(ctx, planned.Property)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query cache: %w", err)
+	}
+	var entries []config.CachedQueryParams
+	for _, entry := range fresh {
+		if !c.tooOld(entry.CreatedAt) {
+			entries = append(entries, entry)
+		}
+	}
+
+	plan := &ReportPlan{}
+	for _, entry := range entries {
+		// Stored params are the request the query hash was computed from
+		var cached RunReportRequest
+		if err := json.Unmarshal([]byte(entry.Params), &cached); err != nil {
+			continue
+		}
+		cached.Property = planned.Property
+		if hashRequest(&cached) == requestHash {
+			plan.ExactQueryID = entry.QueryID
+			return plan, nil
+		}
+	}
+
+	start, end, reason := coverageRange(&planned)
+	if reason != "" {
+		plan.Reason = reason
+		return plan, nil
+	}
+
+	shape := coverageShape(&planned)
+	var candidates []coverageCandidate
+	for _, entry := range entries {
+		var cached RunReportRequest
+		if err := json.Unmarshal([]byte(entry.Params), &cached); err != nil {
+			continue
+		}
+		cached.Property = planned.Property
+		// Truncated results are missing rows for some days
+		if int64(entry.RowCount) > cached.Limit || coverageShape(&cached) != shape {
+			continue
+		}
+		cachedStart, cachedEnd, reason := coverageRange(&cached)
+		if reason != "" {
+			continue
+		}
+		candidates = append(candidates, coverageCandidate{queryID: entry.QueryID, start: cachedStart, end: cachedEnd})
+	}
+
+	plan.Segments = coverSegments(start, end, candidates)
+	return plan, nil
+}
+
+// assembleReport answers a request from results cached for other date ranges
+// when together they cover every day. The assembled response is cached under
+// the request's own hash so it gets a query ID like any other result.
+func (c *DataClient) assembleReport(ctx context.Context, queryHash string, request *RunReportRequest) (*RunReportResponse, bool) {
+	coverageCache, ok := c.cacheClient.(CoverageCache)
+	if !ok || cacheBypassed(ctx) {
+		return nil, false
+	}
+
+	plan, err := c.PlanReport(ctx, request)
+	if err != nil || plan.ExactQueryID != "" || !plan.FromCache() {
+		return nil, false
+	}
+
+	assembled := &RunReportResponse{}
+	for i, segment := range plan.Segments {
+		var cachedRequest RunReportRequest
+		var cached RunReportResponse
+		entry, err := coverageCache.GetQuery(ctx, segment.QueryID, &cachedRequest, &cached)
+		if err != nil || entry == nil {
+			return nil, false
+		}
+		alignColumns(&cached, request)
+		// The assembled response is as old as its oldest segment
+		if assembled.CachedAt.IsZero() || entry.CreatedAt.Before(assembled.CachedAt) {
+			assembled.CachedAt = entry.CreatedAt
+		}
+
+		dateIndex := -1
+		for j, header := range cached.DimensionHeaders {
+			if header.Name == coverageDimension {
+				dateIndex = j
+			}
+		}
+		if dateIndex < 0 {
+			return nil, false
+		}
+
+		if i == 0 {
+			assembled.DimensionHeaders = cached.DimensionHeaders
+			assembled.MetricHeaders = cached.MetricHeaders
+			assembled.Metadata = cached.Metadata
+			assembled.Metadata.SamplingMetadatas = nil
+			assembled.Kind = cached.Kind
+		}
+		assembled.Metadata.SamplingMetadatas = append(assembled.Metadata.SamplingMetadatas, cached.Metadata.SamplingMetadatas...)
+
+		// GA4 returns dates as YYYYMMDD, which compares in date order
+		from := strings.ReplaceAll(segment.StartDate, "-", "")
+		to := strings.ReplaceAll(segment.EndDate, "-", "")
+		for _, row := range cached.Rows {
+			if dateIndex >= len(row.DimensionValues) {
+				continue
+			}
+			if date := row.DimensionValues[dateIndex].Value; date >= from && date <= to {
+				assembled.Rows = append(assembled.Rows, row)
+			}
+		}
+	}
+
+	// The API would have truncated the rows; let it decide which to return
+	if int64(len(assembled.Rows)) > request.Limit {
+		return nil, false
+	}
+	assembled.RowCount = len(assembled.Rows)
+
+	queryID := c.newQueryID(ctx, request)
+	ttl := queryCacheTTLHours
+	if err := c.cacheClient.CacheQuery(ctx, queryID, request.Property, queryHash, request, *assembled, assembled.RowCount, &ttl); err == nil {
+		assembled.QueryID = queryID
+	}
+	assembled.FromCache = true
+	return assembled, true
+}
+
+// coverageRange returns a request's date range, or why its cached results
+// can't be split into other ranges
+func coverageRange(request *RunReportRequest) (start, end time.Time, reason string) {
+	if len(request.DateRanges) != 1 {
+		return start, end, "requests with several date ranges are only served from an exact cache match"
+	}
+	hasDate := false
+	for _, dimension := range request.Dimensions {
+		if dimension.Name == coverageDimension {
+			hasDate = true
+		}
+	}
+	switch {
+	case !hasDate:
+		return start, end, fmt.Sprintf("add the '%s' dimension so cached results for other ranges can be split by day", coverageDimension)
+	case len(request.OrderBys) > 0:
+		return start, end, "ordered results can't be stitched from several cached results"
+	case len(request.MetricAggregations) > 0:
+		return start, end, "totals, minimums and maximums can't be stitched from several cached results"
+	case request.Offset > 0:
+		return start, end, "paged requests can't be stitched from several cached results"
+	}
+
+	var err error
+	if start, err = time.Parse(coverageDateLayout, request.DateRanges[0].StartDate); err != nil {
+		return start, end, "relative dates like '30daysAgo' are only served from an exact cache match"
+	}
+	if end, err = time.Parse(coverageDateLayout, request.DateRanges[0].EndDate); err != nil {
+		return start, end, "relative dates like '30daysAgo' are only served from an exact cache match"
+	}
+	return start, end, ""
+}
+
+// coverageShape identifies requests that differ only in date range, limit,
+// quota reporting or the order of their fields
+func coverageShape(request *RunReportRequest) string {
+	shape := *canonicalRequest(request)
+	shape.DateRanges = nil
+	shape.Limit = 0
+	shape.ReturnPropertyQuota = false
+	data, _ := json.Marshal(&shape)
+	return shape.Property + ":" + string(data)
+}
+
+// SameReportShape reports whether two requests differ only in date range,
+// limit or quota reporting, so their results hold the same rows for the
+// days they share
+func SameReportShape(a, b *RunReportRequest) bool {
+	return coverageShape(a) == coverageShape(b)
+}
+
+// coverSegments splits [start, end] into runs covered by cached candidates
+// and gaps left for the API. Each day comes from the candidate reaching
+// furthest past it; ties go to the newest, which candidates are ordered by.
+func coverSegments(start, end time.Time, candidates []coverageCandidate) []ReportSegment {
+	var segments []ReportSegment
+	for day := start; !day.After(end); {
+		var best *coverageCandidate
+		for i := range candidates {
+			candidate := &candidates[i]
+			if candidate.start.After(day) || candidate.end.Before(day) {
+				continue
+			}
+			if best == nil || candidate.end.After(best.end) {
+				best = candidate
+			}
+		}
+
+		segmentEnd := end
+		queryID := ""
+		if best != nil {
+			queryID = best.queryID
+			if best.end.Before(segmentEnd) {
+				segmentEnd = best.end
+			}
+		} else {
+			// The gap runs until the next candidate starts
+			for _, candidate := range candidates {
+				if candidate.start.After(day) && !candidate.start.After(segmentEnd) {
+					segmentEnd = candidate.start.AddDate(0, 0, -1)
+				}
+			}
+		}
+
+		segments = append(segments, ReportSegment{
+			StartDate: day.Format(coverageDateLayout),
+			EndDate:   segmentEnd.Format(coverageDateLayout),
+			QueryID:   queryID,
+		})
+		day = segmentEnd.AddDate(0, 0, 1)
+	}
+	return segments
+}
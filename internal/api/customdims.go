@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Custom dimension scopes
+const (
+	CustomDimensionScopeEvent = "EVENT"
+	CustomDimensionScopeUser  = "USER"
+	CustomDimensionScopeItem  = "ITEM"
+)
+
+// CustomDimension is a custom dimension registered on a property
+type CustomDimension struct {
+	Name                       string `json:"name,omitempty"` // "properties/328687832/customDimensions/789"
+	ParameterName              string `json:"parameterName"`  // Event parameter or user property name
+	DisplayName                string `json:"displayName"`
+	Description                string `json:"description,omitempty"`
+	Scope                      string `json:"scope"`                                // EVENT, USER or ITEM
+	DisallowAdsPersonalization bool   `json:"disallowAdsPersonalization,omitempty"` // USER scope only
+}
+
+// ID returns the numeric custom dimension ID from the resource name
+func (d CustomDimension) ID() string {
+	if i := strings.LastIndex(d.Name, "/"); i >= 0 {
+		return d.Name[i+1:]
+	}
+	return d.Name
+}
+
+type customDimensionsResponse struct {
+	CustomDimensions []CustomDimension `json:"customDimensions"`
+	NextPageToken    string            `json:"nextPageToken"`
+}
+
+// ListCustomDimensions retrieves the active custom dimensions on a property.
+// Archived dimensions are not returned.
+func (c *AdminClient) ListCustomDimensions(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]CustomDimension, error) {
+	var dimensions []CustomDimension
+	pageToken := ""
+	for {
+		path := fmt.Sprintf("/properties/%s/customDimensions?pageSize=200", propertyID)
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.get(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError("Admin", resp)
+			resp.Body.Close()
+			return nil, apiErr
+		}
+
+		var apiResponse customDimensionsResponse
+		err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode custom dimensions response: %w", err)
+		}
+
+		dimensions = append(dimensions, apiResponse.CustomDimensions...)
+
+		if apiResponse.NextPageToken == "" {
+			break
+		}
+		pageToken = apiResponse.NextPageToken
+	}
+
+	return dimensions, nil
+}
+
+// CreateCustomDimension registers a custom dimension on a property. This
+// needs a refresh token granted the analytics.edit scope.
+func (c *AdminClient) CreateCustomDimension(ctx context.Context, propertyID string, dimension CustomDimension, opts ...AdminCallOption) (*CustomDimension, error) {
+	dimension.Name = ""
+
+	var created CustomDimension
+	if err := c.sendJSON(ctx, http.MethodPost, fmt.Sprintf("/properties/%s/customDimensions", propertyID), dimension, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ArchiveCustomDimension archives a custom dimension by resource name. GA4
+// can't restore archived dimensions; the parameter can only be registered
+// again as a new dimension.
+func (c *AdminClient) ArchiveCustomDimension(ctx context.Context, name string, opts ...AdminCallOption) error {
+	return c.sendJSON(ctx, http.MethodPost, "/"+name+":archive", struct{}{}, nil, opts)
+}
+
+// UpdateCustomDimension changes a custom dimension's display name,
+// description and ads personalization setting. Scope and parameter name
+// can't be changed.
+func (c *AdminClient) UpdateCustomDimension(ctx context.Context, dimension CustomDimension, opts ...AdminCallOption) error {
+	name := dimension.Name
+	dimension.Name = ""
+	path := "/" + name + "?updateMask=" + url.QueryEscape("displayName,description,disallowAdsPersonalization")
+	return c.sendJSON(ctx, http.MethodPatch, path, dimension, nil, opts)
+}
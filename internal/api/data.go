@@ -5,57 +5,148 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strconv"
-	"strings"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"ga4admin/internal/config"
 )
 
 // DataClient handles GA4 Data API operations
 type DataClient struct {
 	authClient *AuthClient
-	baseURL    string
+	transport   dataTransport  // REST or gRPC wire protocol
 	cacheClient CacheInterface // Interface for pluggable caching
+	staleCache  StaleCache     // Set in stale-while-revalidate mode
+	revalidator revalidator    // Background cache refreshes
+	metadataFlight singleflight.Group // Collapses concurrent metadata fetches per property
+	maxCacheAge    time.Duration      // Cached reports older than this are misses; zero for no limit
+}
+
+// Cache TTLs in hours
+const (
+	metadataCacheTTLHours = 24
+	eventsCacheTTLHours   = 1 // Analyses of up to eventsShortWindowDays
+	eventsLongTTLHours    = 6 // Longer analyses, which recent revisions barely move
+	queryCacheTTLHours    = 1
+)
+
+// eventsShortWindowDays is the longest event analysis whose totals GA4's
+// revisions of the last few days noticeably change
+const eventsShortWindowDays = 7
+
+// maxReportRows is the most rows GA4 returns in one report
+const maxReportRows = 250000
+
+// eventBreakdownRowsPerEvent is the --by breakdown's row budget per analyzed
+// event; only the top values are shown, the rest are counted
+const eventBreakdownRowsPerEvent = 1000
+
+// dataTransport performs raw Data API calls; caching stays in DataClient
+type dataTransport interface {
+	getMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error)
+	runReport(ctx context.Context, request *RunReportRequest) (*RunReportResponse, error)
+	runRealtimeReport(ctx context.Context, request *RunRealtimeReportRequest) (*RunReportResponse, error)
+	checkCompatibility(ctx context.Context, request *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error)
+	close() error
 }
 
 // CacheInterface defines the caching contract
 type CacheInterface interface {
 	GetCachedMetadata(ctx context.Context, propertyID, cacheType string, result interface{}) (bool, error)
 	CacheMetadata(ctx context.Context, propertyID, cacheType string, data interface{}, ttlHours int) error
-	GetCachedQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (bool, error)
+	GetCachedQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (string, bool, error)
 	CacheQuery(ctx context.Context, queryID, propertyID, queryHash string, queryParams, resultData interface{}, rowCount int, ttlHours *int) error
 	Close() error
 }
 
+// AnalysisCache is implemented by caches that store analysis results apart
+// from metadata, keyed by a hash of every parameter that shapes the result
+type AnalysisCache interface {
+	GetCachedAnalysis(ctx context.Context, analysisHash string, result interface{}) (bool, error)
+	GetStaleAnalysis(ctx context.Context, analysisHash string, result interface{}) (found, stale bool, err error)
+	CacheAnalysis(ctx context.Context, analysisHash, propertyID, analysisType string, params, data interface{}, expiresAt time.Time) error
+}
+
 // NewDataClient creates a new GA4 Data API client
 func NewDataClient() (*DataClient, error) {
-	authClient, err := NewAuthClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create auth client: %w", err)
-	}
-
-	return &DataClient{
-		authClient: authClient,
-		baseURL:    "https://analyticsdata.googleapis.com/v1beta",
-	}, nil
+	return NewDataClientWithCache(nil)
 }
 
 // NewDataClientWithCache creates a new GA4 Data API client with caching
 func NewDataClientWithCache(cacheClient CacheInterface) (*DataClient, error) {
-	authClient, err := NewAuthClient()
+	return NewDataClientForPreset("", cacheClient)
+}
+
+// NewDataClientForPreset creates a Data API client authenticated as the named
+// preset rather than the active one, with optional caching
+func NewDataClientForPreset(presetName string, cacheClient CacheInterface) (*DataClient, error) {
+	authClient, err := NewAuthClientForPreset(presetName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create auth client: %w", err)
 	}
 
+	transport, err := newDataTransport(authClient)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DataClient{
 		authClient:  authClient,
-		baseURL:     "https://analyticsdata.googleapis.com/v1beta",
+		transport:   transport,
 		cacheClient: cacheClient,
 	}, nil
 }
 
-// Close closes any resources (like cache connections)
+// newDataTransport selects the wire protocol configured in the global config
+func newDataTransport(authClient *AuthClient) (dataTransport, error) {
+	transportName, err := config.GetDataAPITransport()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transport configuration: %w", err)
+	}
+
+	// Fixtures are recorded at the HTTP layer, so record/replay always uses REST
+	if currentFixtureDir() != "" {
+		transportName = config.TransportREST
+	}
+
+	endpoint, err := config.GetDataAPIEndpoint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Data API endpoint: %w", err)
+	}
+
+	switch transportName {
+	case config.TransportREST:
+		return &restTransport{
+			authClient: authClient,
+			baseURL:    endpoint + "/v1beta",
+		}, nil
+	case config.TransportGRPC:
+		return newGRPCTransport(authClient, endpoint)
+	default:
+		return nil, fmt.Errorf("unsupported Data API transport: %s", transportName)
+	}
+}
+
+// PresetName returns the preset the client authenticates as; empty means the
+// active preset
+func (c *DataClient) PresetName() string {
+	return c.authClient.presetName
+}
+
+// CacheClient returns the client's cache, or nil when caching is off
+func (c *DataClient) CacheClient() CacheInterface {
+	return c.cacheClient
+}
+
+// Close closes any resources (like cache connections), first waiting for
+// background cache refreshes to finish
 func (c *DataClient) Close() error {
+	c.WaitForRevalidation()
+	if c.transport != nil {
+		c.transport.close()
+	}
 	if c.cacheClient != nil {
 		return c.cacheClient.Close()
 	}
@@ -114,6 +205,24 @@ type RunReportRequest struct {
 	ReturnPropertyQuota  bool                 `json:"returnPropertyQuota,omitempty"`
 }
 
+// RunRealtimeReportRequest queries activity from the last 30 minutes
+// (60 for GA4 360 properties). Realtime reports are never cached.
+type RunRealtimeReportRequest struct {
+	Property     string        `json:"-"` // Property ID (not in JSON body)
+	Dimensions   []Dimension   `json:"dimensions,omitempty"`
+	Metrics      []Metric      `json:"metrics,omitempty"`
+	Limit        int64         `json:"limit,omitempty"`
+	OrderBys     []OrderBy     `json:"orderBys,omitempty"`
+	MinuteRanges []MinuteRange `json:"minuteRanges,omitempty"`
+}
+
+// MinuteRange is a window of minutes before now, e.g. 0-4 for the last 5 minutes
+type MinuteRange struct {
+	StartMinutesAgo int    `json:"startMinutesAgo"`
+	EndMinutesAgo   int    `json:"endMinutesAgo"`
+	Name            string `json:"name,omitempty"`
+}
+
 type RunReportResponse struct {
 	DimensionHeaders []DimensionHeader `json:"dimensionHeaders"`
 	MetricHeaders    []MetricHeader    `json:"metricHeaders"`
@@ -125,6 +234,14 @@ type RunReportResponse struct {
 	Metadata         ResponseMetadata  `json:"metadata"`
 	PropertyQuota    *PropertyQuota    `json:"propertyQuota"`
 	Kind             string            `json:"kind"`
+
+	// QueryID is the cache entry holding this response; empty when caching is off
+	QueryID string `json:"-"`
+	// FromCache is set when the response was served from the cache
+	FromCache bool `json:"-"`
+	// CachedAt is when a response served from the cache was fetched from the
+	// API; zero when unknown
+	CachedAt time.Time `json:"-"`
 }
 
 type Dimension struct {
@@ -163,7 +280,7 @@ type Filter struct {
 }
 
 type StringFilter struct {
-	MatchType     string `json:"matchType"`     // EXACT, CONTAINS, STARTS_WITH, ENDS_WITH, REGEX
+	MatchType     string `json:"matchType"`     // EXACT, BEGINS_WITH, ENDS_WITH, CONTAINS, FULL_REGEXP, PARTIAL_REGEXP
 	Value         string `json:"value"`
 	CaseSensitive bool   `json:"caseSensitive"`
 }
@@ -230,6 +347,13 @@ type ResponseMetadata struct {
 	TimeZone                    string `json:"timeZone"`
 	EmptyReason                 string `json:"emptyReason,omitempty"`
 	DataLossFromOtherRow       bool   `json:"dataLossFromOtherRow,omitempty"`
+	SamplingMetadatas          []SamplingMetadata `json:"samplingMetadatas,omitempty"` // Present when GA4 sampled the data
+}
+
+// SamplingMetadata describes how much of the data a sampled report read
+type SamplingMetadata struct {
+	SamplesReadCount   int64 `json:"samplesReadCount,string"`
+	SamplingSpaceSize  int64 `json:"samplingSpaceSize,string"`
 }
 
 type PropertyQuota struct {
@@ -244,47 +368,49 @@ type QuotaStatus struct {
 	Remaining  int    `json:"remaining,omitempty"`
 }
 
-// GetMetadata retrieves all dimensions and metrics available for a GA4 property
+// GetMetadata retrieves all dimensions and metrics available for a GA4
+// property. Concurrent calls for the same property that miss the cache share
+// one API request and its result, which callers must not modify.
 func (c *DataClient) GetMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error) {
 	// Try cache first if available
-	if c.cacheClient != nil {
-		var cached MetadataResponse
-		if found, err := c.cacheClient.GetCachedMetadata(ctx, propertyID, "metadata", &cached); err == nil && found {
-			return &cached, nil
+	var cached MetadataResponse
+	refresh := func(ctx context.Context) error {
+		_, err := c.GetMetadata(ctx, propertyID)
+		return err
+	}
+	if c.cachedMetadata(ctx, propertyID, "metadata", &cached, refresh) {
+		return &cached, nil
+	}
+	return c.fetchMetadata(ctx, propertyID)
+}
+
+// fetchMetadata gets a property's metadata from GA4, bypassing the cache
+// lookup, and caches it. Concurrent fetches of one property share a request.
+func (c *DataClient) fetchMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error) {
+	// The first caller's context drives the shared request; the others stop
+	// waiting when their own context ends
+	flight := c.metadataFlight.DoChan(propertyID, func() (interface{}, error) {
+		metadata, err := c.transport.getMetadata(ctx, propertyID)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
-	}
 
-	url := fmt.Sprintf("%s/properties/%s/metadata", c.baseURL, propertyID)
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request to GA4 Data API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("property %s not found or not accessible", propertyID)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GA4 Data API returned status %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	var metadata MetadataResponse
-	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
-		return nil, fmt.Errorf("failed to decode metadata response: %w", err)
-	}
+		// Cache the result for 24 hours if caching is available
+		if c.cacheClient != nil {
+			c.cacheClient.CacheMetadata(ctx, propertyID, "metadata", *metadata, metadataCacheTTLHours)
+		}
+		return metadata, nil
+	})
 
-	// Cache the result for 24 hours if caching is available
-	if c.cacheClient != nil {
-		c.cacheClient.CacheMetadata(ctx, propertyID, "metadata", metadata, 24)
+	select {
+	case result := <-flight:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*MetadataResponse), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-
-	return &metadata, nil
 }
 
 // RunReport executes a GA4 report query
@@ -303,7 +429,7 @@ func (c *DataClient) RunReport(ctx context.Context, request *RunReportRequest) (
 	}
 
 	// Validate limit
-	if request.Limit > 250000 {
+	if request.Limit > maxReportRows {
 		return nil, fmt.Errorf("limit cannot exceed 250,000 rows")
 	}
 
@@ -311,77 +437,148 @@ func (c *DataClient) RunReport(ctx context.Context, request *RunReportRequest) (
 	var queryHash string
 	if c.cacheClient != nil {
 		queryHash = c.generateQueryHash(request)
-		var cached RunReportResponse
-		if found, err := c.cacheClient.GetCachedQuery(ctx, queryHash, request, &cached); err == nil && found {
-			return &cached, nil
+		if cached, found := c.cachedReport(ctx, queryHash, request); found {
+			return cached, nil
+		}
+		if assembled, found := c.assembleReport(ctx, queryHash, request); found {
+			return assembled, nil
 		}
 	}
 
-	httpClient, err := c.authClient.AuthenticatedHTTPClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/properties/%s:runReport", c.baseURL, request.Property)
-	
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	// Requests GA4 just rejected as invalid would only be rejected again
+	if err := c.rejectedReport(ctx, queryHash); err != nil {
+		return nil, err
 	}
 
-	resp, err := httpClient.Post(url, "application/json", 
-		strings.NewReader(string(jsonData)))
+	// Quota usage is free to ask for and feeds 'quota forecast'. Set on a
+	// copy so the cache key above doesn't depend on it.
+	withQuota := *request
+	withQuota.ReturnPropertyQuota = true
+	reportResponse, err := c.transport.runReport(ctx, &withQuota)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to GA4 Data API: %w", err)
+		c.rememberRejection(ctx, queryHash, request, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("property %s not found or not accessible", request.Property)
+	// Cache the result for 1 hour if caching is available
+	if c.cacheClient != nil && queryHash != "" {
+		queryID := c.newQueryID(ctx, request)
+		ttl := queryCacheTTLHours
+		if err := c.cacheClient.CacheQuery(ctx, queryID, request.Property, queryHash, request, *reportResponse, reportResponse.RowCount, &ttl); err == nil {
+			reportResponse.QueryID = queryID
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GA4 Data API returned status %d: %s", resp.StatusCode, resp.Status)
-	}
+	return reportResponse, nil
+}
 
-	var reportResponse RunReportResponse
-	if err := json.NewDecoder(resp.Body).Decode(&reportResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode report response: %w", err)
+// RunRealtimeReport executes a GA4 realtime report. Realtime responses share
+// the RunReportResponse shape.
+func (c *DataClient) RunRealtimeReport(ctx context.Context, request *RunRealtimeReportRequest) (*RunReportResponse, error) {
+	if request.Property == "" {
+		return nil, fmt.Errorf("property ID is required")
 	}
+	return c.transport.runRealtimeReport(ctx, request)
+}
 
-	// Cache the result for 1 hour if caching is available
-	if c.cacheClient != nil && queryHash != "" {
-		queryID := fmt.Sprintf("query_%d", time.Now().Unix())
-		ttl := 1 // 1 hour for query results
-		c.cacheClient.CacheQuery(ctx, queryID, request.Property, queryHash, request, reportResponse, reportResponse.RowCount, &ttl)
-	}
+// generateQueryHash creates the cache key of a query request, the same for
+// requests that differ only in field order or date spelling
+func (c *DataClient) generateQueryHash(request *RunReportRequest) string {
+	return hashRequest(request)
+}
 
-	return &reportResponse, nil
+// EventAnalysisOptions controls optional extras computed by AnalyzeEventsWithOptions
+type EventAnalysisOptions struct {
+	Limit              int    // Events to fetch; 0 for the top 100
+	OrderBy            string // "eventCount" (default) or "activeUsers", descending
+	BreakdownDimension string // Secondary dimension to split each event by (e.g. "platform", "country")
+	Trend              bool   // Compute per-event daily counts
 }
 
-// generateQueryHash creates a unique hash for a query request
-func (c *DataClient) generateQueryHash(request *RunReportRequest) string {
-	// Create a deterministic JSON representation
-	jsonData, _ := json.Marshal(request)
-	hash := sha256.Sum256(jsonData)
+// Default event analysis parameters
+const (
+	defaultEventLimit   = 100
+	defaultEventOrderBy = "eventCount"
+)
+
+// eventAnalysisParams are all the inputs that shape an event analysis; their
+// hash is the cache key, so a new option must be added here too
+type eventAnalysisParams struct {
+	PropertyID         string `json:"property_id"`
+	Days               int    `json:"days"`
+	Limit              int    `json:"limit"`
+	OrderBy            string `json:"order_by"`
+	BreakdownDimension string `json:"breakdown_dimension,omitempty"`
+	Trend              bool   `json:"trend,omitempty"`
+}
+
+// analysisHash creates the cache key for an analysis of the given type
+func analysisHash(analysisType string, params interface{}) string {
+	jsonData, _ := json.Marshal(params)
+	hash := sha256.Sum256(append([]byte(analysisType+"\n"), jsonData...))
 	return fmt.Sprintf("%x", hash)
 }
 
+// eventAnalysisExpiry is when a cached event analysis stops being served.
+// Analyses end yesterday, so every one is outdated at midnight; before that
+// only GA4's revisions of recent days change them, which matters more the
+// shorter the window.
+func eventAnalysisExpiry(now time.Time, days int) time.Time {
+	ttl := eventsCacheTTLHours
+	if days > eventsShortWindowDays {
+		ttl = eventsLongTTLHours
+	}
+	expiry := now.Add(time.Duration(ttl) * time.Hour)
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	if midnight.Before(expiry) {
+		return midnight
+	}
+	return expiry
+}
+
 // AnalyzeEvents performs event volume analysis for a property
 func (c *DataClient) AnalyzeEvents(ctx context.Context, propertyID string, days int) (*EventAnalysis, error) {
+	return c.AnalyzeEventsWithOptions(ctx, propertyID, days, EventAnalysisOptions{})
+}
+
+// AnalyzeEventsWithOptions performs event volume analysis with an optional
+// secondary-dimension breakdown and daily trend per event
+func (c *DataClient) AnalyzeEventsWithOptions(ctx context.Context, propertyID string, days int, options EventAnalysisOptions) (*EventAnalysis, error) {
 	// Validate parameters
 	if days <= 0 || days > 365 {
 		return nil, fmt.Errorf("days must be between 1 and 365")
 	}
+	if options.Limit < 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	if options.Limit == 0 {
+		options.Limit = defaultEventLimit
+	}
+	if options.OrderBy == "" {
+		options.OrderBy = defaultEventOrderBy
+	}
+	if options.OrderBy != "eventCount" && options.OrderBy != "activeUsers" {
+		return nil, fmt.Errorf("order by must be eventCount or activeUsers, got %q", options.OrderBy)
+	}
 
-	cacheKey := fmt.Sprintf("events_%d", days)
-	
-	// Try cache first if available (1 hour TTL for events)
-	if c.cacheClient != nil {
-		var cached EventAnalysis
-		if found, err := c.cacheClient.GetCachedMetadata(ctx, propertyID, cacheKey, &cached); err == nil && found {
-			return &cached, nil
-		}
+	params := eventAnalysisParams{
+		PropertyID:         propertyID,
+		Days:               days,
+		Limit:              options.Limit,
+		OrderBy:            options.OrderBy,
+		BreakdownDimension: options.BreakdownDimension,
+		Trend:              options.Trend,
+	}
+	cacheKey := analysisHash("events", params)
+
+	// Try cache first if available
+	var cached EventAnalysis
+	refresh := func(ctx context.Context) error {
+		_, err := c.AnalyzeEventsWithOptions(ctx, propertyID, days, options)
+		return err
+	}
+	if c.cachedAnalysis(ctx, cacheKey, &cached, refresh) {
+		return &cached, nil
 	}
 
 	// Build report request for event analysis
@@ -404,11 +601,13 @@ func (c *DataClient) AnalyzeEvents(ctx context.Context, propertyID string, days
 			{
 				Desc: true,
 				Metric: &MetricOrderBy{
-					MetricName: "eventCount",
+					MetricName: options.OrderBy,
 				},
 			},
 		},
-		Limit: 100, // Top 100 events
+		// Totals cover every event, not just the fetched ones
+		MetricAggregations: []string{"TOTAL"},
+		Limit:              int64(options.Limit),
 	}
 
 	reportResponse, err := c.RunReport(ctx, request)
@@ -420,10 +619,14 @@ func (c *DataClient) AnalyzeEvents(ctx context.Context, propertyID string, days
 	analysis := &EventAnalysis{
 		PropertyID:    propertyID,
 		DateRange:     fmt.Sprintf("%d days", days),
+		OrderBy:       options.OrderBy,
 		TotalEvents:   len(reportResponse.Rows),
 		AnalyzedAt:    time.Now(),
 		Events:        make([]EventSummary, 0, len(reportResponse.Rows)),
 	}
+	if reportResponse.RowCount > analysis.TotalEvents {
+		analysis.TotalEvents = reportResponse.RowCount
+	}
 
 	var totalEventCount int64
 	var totalUsers int64
@@ -448,31 +651,208 @@ func (c *DataClient) AnalyzeEvents(ctx context.Context, propertyID string, days
 
 	analysis.TotalEventCount = totalEventCount
 	analysis.TotalActiveUsers = totalUsers
+	if len(reportResponse.Totals) > 0 && len(reportResponse.Totals[0].MetricValues) >= 2 {
+		totals := reportResponse.Totals[0].MetricValues
+		analysis.TotalEventCount, _ = strconv.ParseInt(totals[0].Value, 10, 64)
+		analysis.TotalActiveUsers, _ = strconv.ParseInt(totals[1].Value, 10, 64)
+	}
 
-	// Cache the result for 1 hour if caching is available
-	if c.cacheClient != nil {
-		c.cacheClient.CacheMetadata(ctx, propertyID, cacheKey, *analysis, 1)
+	if options.BreakdownDimension != "" {
+		if err := c.addEventBreakdown(ctx, analysis, days, options.BreakdownDimension); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Trend {
+		if err := c.addEventTrend(ctx, analysis, days); err != nil {
+			return nil, err
+		}
+	}
+
+	if analysisCache, ok := c.cacheClient.(AnalysisCache); ok {
+		analysisCache.CacheAnalysis(ctx, cacheKey, propertyID, "events", params, *analysis, eventAnalysisExpiry(time.Now(), days))
 	}
 
 	return analysis, nil
 }
 
+// addEventBreakdown splits each analyzed event by a secondary dimension
+func (c *DataClient) addEventBreakdown(ctx context.Context, analysis *EventAnalysis, days int, dimension string) error {
+	if len(analysis.Events) == 0 {
+		return nil
+	}
+	request := &RunReportRequest{
+		Property: analysis.PropertyID,
+		Dimensions: []Dimension{
+			{Name: "eventName"},
+			{Name: dimension},
+		},
+		Metrics: []Metric{
+			{Name: "eventCount"},
+		},
+		DateRanges: []DateRange{
+			{
+				StartDate: fmt.Sprintf("%ddaysAgo", days),
+				EndDate:   "yesterday",
+			},
+		},
+		DimensionFilter: analyzedEventsFilter(analysis.Events),
+		OrderBys: []OrderBy{
+			{
+				Desc: true,
+				Metric: &MetricOrderBy{
+					MetricName: "eventCount",
+				},
+			},
+		},
+		Limit: reportLimit(len(analysis.Events) * eventBreakdownRowsPerEvent),
+	}
+
+	reportResponse, err := c.RunReport(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to run event breakdown report: %w", err)
+	}
+
+	index := make(map[string]int, len(analysis.Events))
+	for i, event := range analysis.Events {
+		index[event.EventName] = i
+	}
+
+	// Rows arrive sorted by count, so each event's breakdown is already ordered
+	for _, row := range reportResponse.Rows {
+		if len(row.DimensionValues) < 2 || len(row.MetricValues) < 1 {
+			continue
+		}
+		i, ok := index[row.DimensionValues[0].Value]
+		if !ok {
+			continue
+		}
+		eventCount, _ := strconv.ParseInt(row.MetricValues[0].Value, 10, 64)
+		analysis.Events[i].Breakdown = append(analysis.Events[i].Breakdown, EventBreakdown{
+			Value:      row.DimensionValues[1].Value,
+			EventCount: eventCount,
+		})
+	}
+
+	analysis.BreakdownDimension = dimension
+	return nil
+}
+
+// addEventTrend fills in a daily event count series for each analyzed event
+func (c *DataClient) addEventTrend(ctx context.Context, analysis *EventAnalysis, days int) error {
+	if len(analysis.Events) == 0 {
+		return nil
+	}
+	request := &RunReportRequest{
+		Property: analysis.PropertyID,
+		Dimensions: []Dimension{
+			{Name: "eventName"},
+			{Name: "date"},
+		},
+		Metrics: []Metric{
+			{Name: "eventCount"},
+		},
+		DateRanges: []DateRange{
+			{
+				StartDate: fmt.Sprintf("%ddaysAgo", days),
+				EndDate:   "yesterday",
+			},
+		},
+		DimensionFilter: analyzedEventsFilter(analysis.Events),
+		// One row per event and day
+		Limit: reportLimit(len(analysis.Events) * days),
+	}
+
+	reportResponse, err := c.RunReport(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to run event trend report: %w", err)
+	}
+
+	index := make(map[string]int, len(analysis.Events))
+	for i, event := range analysis.Events {
+		index[event.EventName] = i
+		analysis.Events[i].DailyCounts = make([]int64, days)
+	}
+
+	// Day 0 is the oldest day in the range, day days-1 is yesterday
+	now := time.Now()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+
+	for _, row := range reportResponse.Rows {
+		if len(row.DimensionValues) < 2 || len(row.MetricValues) < 1 {
+			continue
+		}
+		i, ok := index[row.DimensionValues[0].Value]
+		if !ok {
+			continue
+		}
+		date, err := time.Parse("20060102", row.DimensionValues[1].Value)
+		if err != nil {
+			continue
+		}
+		day := days - 1 - int(yesterday.Sub(date).Hours()/24)
+		if day < 0 || day >= days {
+			continue
+		}
+		eventCount, _ := strconv.ParseInt(row.MetricValues[0].Value, 10, 64)
+		analysis.Events[i].DailyCounts[day] += eventCount
+	}
+
+	return nil
+}
+
+// analyzedEventsFilter limits a follow-up report to the analyzed events, so
+// other events don't use up its rows
+func analyzedEventsFilter(events []EventSummary) *FilterExpression {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.EventName
+	}
+	return &FilterExpression{
+		Filter: &Filter{
+			FieldName: "eventName",
+			InListFilter: &InListFilter{
+				Values:        names,
+				CaseSensitive: true,
+			},
+		},
+	}
+}
+
+// reportLimit caps a row count at the most GA4 returns in one report
+func reportLimit(rows int) int64 {
+	if rows > maxReportRows {
+		return maxReportRows
+	}
+	return int64(rows)
+}
+
 // EventAnalysis represents the results of event volume analysis
 type EventAnalysis struct {
-	PropertyID       string         `json:"property_id"`
-	DateRange        string         `json:"date_range"`
-	TotalEvents      int            `json:"total_events"`
-	TotalEventCount  int64          `json:"total_event_count"`
-	TotalActiveUsers int64          `json:"total_active_users"`
-	AnalyzedAt       time.Time      `json:"analyzed_at"`
-	Events           []EventSummary `json:"events"`
+	PropertyID         string         `json:"property_id"`
+	DateRange          string         `json:"date_range"`
+	OrderBy            string         `json:"order_by,omitempty"`
+	BreakdownDimension string         `json:"breakdown_dimension,omitempty"`
+	TotalEvents        int            `json:"total_events"`
+	TotalEventCount    int64          `json:"total_event_count"`
+	TotalActiveUsers   int64          `json:"total_active_users"`
+	AnalyzedAt         time.Time      `json:"analyzed_at"`
+	Events             []EventSummary `json:"events"`
 }
 
 type EventSummary struct {
-	EventName     string  `json:"event_name"`
-	EventCount    int64   `json:"event_count"`
-	ActiveUsers   int64   `json:"active_users"`
-	EventsPerUser float64 `json:"events_per_user"`
+	EventName     string           `json:"event_name"`
+	EventCount    int64            `json:"event_count"`
+	ActiveUsers   int64            `json:"active_users"`
+	EventsPerUser float64          `json:"events_per_user"`
+	Breakdown     []EventBreakdown `json:"breakdown,omitempty"`    // Counts per secondary dimension value
+	DailyCounts   []int64          `json:"daily_counts,omitempty"` // Oldest day first
+}
+
+// EventBreakdown is an event's count for one value of the breakdown dimension
+type EventBreakdown struct {
+	Value      string `json:"value"`
+	EventCount int64  `json:"event_count"`
 }
 
 // Helper method to create common date ranges
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	datapb "google.golang.org/genproto/googleapis/analytics/data/v1beta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Retry transient failures inside the gRPC client instead of in our code
+	grpcServiceConfig = `{
+		"methodConfig": [{
+			"name": [{"service": "google.analytics.data.v1beta.BetaAnalyticsData"}],
+			"retryPolicy": {
+				"maxAttempts": 4,
+				"initialBackoff": "0.5s",
+				"maxBackoff": "8s",
+				"backoffMultiplier": 2,
+				"retryableStatusCodes": ["UNAVAILABLE"]
+			}
+		}]
+	}`
+)
+
+// grpcTransport talks to the Data API through the official generated gRPC client.
+// Requests and responses are bridged through protojson, since the REST structs in
+// this package mirror the same schema as the protos.
+type grpcTransport struct {
+	conn   *grpc.ClientConn
+	client datapb.BetaAnalyticsDataClient
+}
+
+func newGRPCTransport(authClient *AuthClient, endpoint string) (*grpcTransport, error) {
+	target, err := grpcTarget(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource := oauth2.ReuseTokenSource(nil, &refreshTokenSource{
+		authClient: authClient,
+		ctx:        context.Background(),
+	})
+
+	// gRPC honors HTTPS_PROXY from the environment; the CA bundle applies here too
+	tlsConfig, err := networkTLSConfig(currentNetworkOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokenSource}),
+		grpc.WithDefaultServiceConfig(grpcServiceConfig),
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)),
+	}
+	interceptors := []grpc.UnaryClientInterceptor{activityUnaryInterceptor}
+	if tracingEnabled() {
+		interceptors = append(interceptors, traceUnaryInterceptor)
+	}
+	dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(interceptors...))
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	return &grpcTransport{
+		conn:   conn,
+		client: datapb.NewBetaAnalyticsDataClient(conn),
+	}, nil
+}
+
+func (t *grpcTransport) getMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error) {
+	ctx, cancel := grpcRequestContext(ctx)
+	defer cancel()
+
+	resp, err := t.client.GetMetadata(ctx, &datapb.GetMetadataRequest{
+		Name: fmt.Sprintf("properties/%s/metadata", propertyID),
+	})
+	if err != nil {
+		return nil, grpcError(err, propertyID)
+	}
+
+	var metadata MetadataResponse
+	if err := fromProto(resp, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata response: %w", err)
+	}
+
+	return &metadata, nil
+}
+
+func (t *grpcTransport) runReport(ctx context.Context, request *RunReportRequest) (*RunReportResponse, error) {
+	var pbRequest datapb.RunReportRequest
+	if err := toProto(request, &pbRequest); err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	pbRequest.Property = "properties/" + request.Property
+
+	ctx, cancel := grpcRequestContext(ctx)
+	defer cancel()
+
+	resp, err := t.client.RunReport(ctx, &pbRequest)
+	if err != nil {
+		return nil, grpcError(err, request.Property)
+	}
+
+	var reportResponse RunReportResponse
+	if err := fromProto(resp, &reportResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode report response: %w", err)
+	}
+
+	return &reportResponse, nil
+}
+
+func (t *grpcTransport) runRealtimeReport(ctx context.Context, request *RunRealtimeReportRequest) (*RunReportResponse, error) {
+	var pbRequest datapb.RunRealtimeReportRequest
+	if err := toProto(request, &pbRequest); err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	pbRequest.Property = "properties/" + request.Property
+
+	ctx, cancel := grpcRequestContext(ctx)
+	defer cancel()
+
+	resp, err := t.client.RunRealtimeReport(ctx, &pbRequest)
+	if err != nil {
+		return nil, grpcError(err, request.Property)
+	}
+
+	var reportResponse RunReportResponse
+	if err := fromProto(resp, &reportResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode realtime report response: %w", err)
+	}
+
+	return &reportResponse, nil
+}
+
+func (t *grpcTransport) checkCompatibility(ctx context.Context, request *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error) {
+	var pbRequest datapb.CheckCompatibilityRequest
+	if err := toProto(request, &pbRequest); err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	pbRequest.Property = "properties/" + request.Property
+
+	ctx, cancel := grpcRequestContext(ctx)
+	defer cancel()
+
+	resp, err := t.client.CheckCompatibility(ctx, &pbRequest)
+	if err != nil {
+		return nil, grpcError(err, request.Property)
+	}
+
+	var compatibility CheckCompatibilityResponse
+	if err := fromProto(resp, &compatibility); err != nil {
+		return nil, fmt.Errorf("failed to decode compatibility response: %w", err)
+	}
+
+	return &compatibility, nil
+}
+
+func (t *grpcTransport) close() error {
+	return t.conn.Close()
+}
+
+// grpcTarget turns the Data API endpoint URL into a host:port dial target
+func grpcTarget(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid Data API endpoint '%s'", endpoint)
+	}
+	if parsed.Port() != "" {
+		return parsed.Host, nil
+	}
+	return parsed.Hostname() + ":443", nil
+}
+
+// grpcRequestContext applies the configured per-request timeout, mirroring
+// http.Client.Timeout on the REST transport
+func grpcRequestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := currentNetworkOptions().RequestTimeout; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// toProto converts a REST request struct into its protobuf equivalent
+func toProto(v interface{}, msg proto.Message) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, msg)
+}
+
+// fromProto converts a protobuf response into its REST struct equivalent
+func fromProto(msg proto.Message, v interface{}) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// grpcStatuses pairs each gRPC code with the canonical status name and HTTP
+// status the REST API reports for it, so errors from either transport are
+// classified alike
+var grpcStatuses = map[codes.Code]struct {
+	name       string
+	httpStatus int
+}{
+	codes.OK:                 {"OK", http.StatusOK},
+	codes.Canceled:           {"CANCELLED", 499},
+	codes.Unknown:            {"UNKNOWN", http.StatusInternalServerError},
+	codes.InvalidArgument:    {"INVALID_ARGUMENT", http.StatusBadRequest},
+	codes.DeadlineExceeded:   {"DEADLINE_EXCEEDED", http.StatusGatewayTimeout},
+	codes.NotFound:           {"NOT_FOUND", http.StatusNotFound},
+	codes.AlreadyExists:      {"ALREADY_EXISTS", http.StatusConflict},
+	codes.PermissionDenied:   {"PERMISSION_DENIED", http.StatusForbidden},
+	codes.ResourceExhausted:  {"RESOURCE_EXHAUSTED", http.StatusTooManyRequests},
+	codes.FailedPrecondition: {"FAILED_PRECONDITION", http.StatusBadRequest},
+	codes.Aborted:            {"ABORTED", http.StatusConflict},
+	codes.OutOfRange:         {"OUT_OF_RANGE", http.StatusBadRequest},
+	codes.Unimplemented:      {"UNIMPLEMENTED", http.StatusNotImplemented},
+	codes.Internal:           {"INTERNAL", http.StatusInternalServerError},
+	codes.Unavailable:        {"UNAVAILABLE", http.StatusServiceUnavailable},
+	codes.DataLoss:           {"DATA_LOSS", http.StatusInternalServerError},
+	codes.Unauthenticated:    {"UNAUTHENTICATED", http.StatusUnauthorized},
+}
+
+// grpcStatus returns the REST status name and HTTP status for a gRPC code;
+// codes gRPC may add later are reported as UNKNOWN
+func grpcStatus(code codes.Code) (string, int) {
+	if s, ok := grpcStatuses[code]; ok {
+		return s.name, s.httpStatus
+	}
+	return "UNKNOWN", http.StatusInternalServerError
+}
+
+// grpcError maps gRPC status codes onto the same messages the REST transport produces
+func grpcError(err error, propertyID string) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("failed to make request to GA4 Data API: %w", err)
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("failed to make request to GA4 Data API: %w", err)
+	default:
+		name, httpStatus := grpcStatus(st.Code())
+		return &APIError{
+			API:        "Data",
+			StatusCode: httpStatus,
+			Status:     name,
+			Message:    st.Message(),
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// restTransport talks to the Data API over JSON/HTTP
+type restTransport struct {
+	authClient *AuthClient
+	baseURL    string
+}
+
+func (t *restTransport) getMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error) {
+	httpClient, err := t.authClient.AuthenticatedHTTPClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated HTTP client: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/properties/%s/metadata", t.baseURL, propertyID)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to GA4 Data API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("Data", resp)
+	}
+
+	var metadata MetadataResponse
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata response: %w", err)
+	}
+
+	return &metadata, nil
+}
+
+func (t *restTransport) runReport(ctx context.Context, request *RunReportRequest) (*RunReportResponse, error) {
+	return t.postReport(ctx, request.Property, "runReport", request)
+}
+
+func (t *restTransport) runRealtimeReport(ctx context.Context, request *RunRealtimeReportRequest) (*RunReportResponse, error) {
+	return t.postReport(ctx, request.Property, "runRealtimeReport", request)
+}
+
+func (t *restTransport) checkCompatibility(ctx context.Context, request *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error) {
+	var compatibility CheckCompatibilityResponse
+	if err := t.postMethod(ctx, request.Property, "checkCompatibility", request, &compatibility); err != nil {
+		return nil, err
+	}
+	return &compatibility, nil
+}
+
+// postReport calls a report method such as "runReport" on a property
+func (t *restTransport) postReport(ctx context.Context, propertyID, method string, request interface{}) (*RunReportResponse, error) {
+	var reportResponse RunReportResponse
+	if err := t.postMethod(ctx, propertyID, method, request, &reportResponse); err != nil {
+		return nil, err
+	}
+	return &reportResponse, nil
+}
+
+// postMethod calls a property method and decodes its response into result
+func (t *restTransport) postMethod(ctx context.Context, propertyID, method string, request, result interface{}) error {
+	httpClient, err := t.authClient.AuthenticatedHTTPClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get authenticated HTTP client: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/properties/%s:%s", t.baseURL, propertyID, method)
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to make request to GA4 Data API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("Data", resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+
+	return nil
+}
+
+func (t *restTransport) close() error {
+	return nil
+}
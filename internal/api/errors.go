@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// APIError carries the error details GA4 returns alongside a failed request,
+// so callers can show Google's explanation instead of a bare status code
+type APIError struct {
+	API        string // "Data" or "Admin"
+	StatusCode int    // HTTP status; gRPC codes are mapped to the REST equivalent
+	Status     string // Canonical status, e.g. INVALID_ARGUMENT
+	Message    string // Human readable explanation from GA4
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GA4 %s API returned status %d: %s", e.API, e.StatusCode, e.Status)
+	}
+	return fmt.Sprintf("GA4 %s API returned status %d (%s): %s", e.API, e.StatusCode, e.Status, e.Message)
+}
+
+// IsInvalidArgument reports whether GA4 rejected the request itself,
+// e.g. an unknown field or a malformed metric expression
+func (e *APIError) IsInvalidArgument() bool {
+	return e.Status == "INVALID_ARGUMENT" || e.StatusCode == http.StatusBadRequest
+}
+
+// IsInsufficientScope reports whether the access token lacks a scope the call
+// needs, e.g. a read-only refresh token used for an Admin API write
+func (e *APIError) IsInsufficientScope() bool {
+	return e.Status == "PERMISSION_DENIED" && strings.Contains(strings.ToLower(e.Message), "insufficient authentication scopes")
+}
+
+// IsQuotaExhausted reports whether GA4 refused the request because a quota
+// (tokens or concurrent requests) ran out
+func (e *APIError) IsQuotaExhausted() bool {
+	return e.Status == "RESOURCE_EXHAUSTED" || e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsUnauthenticated reports whether GA4 rejected the credentials themselves
+func (e *APIError) IsUnauthenticated() bool {
+	return e.Status == "UNAUTHENTICATED" || e.StatusCode == http.StatusUnauthorized
+}
+
+// IsNetworkError reports whether a request failed before GA4 answered: a
+// dropped connection, DNS failure, timeout or unreachable gRPC endpoint
+func IsNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		code := grpcErr.GRPCStatus().Code()
+		return code == codes.Unavailable || code == codes.DeadlineExceeded
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a non-200 response, reading Google's
+// standard {"error": {...}} body when present
+func newAPIError(apiName string, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		API:        apiName,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return apiErr
+	}
+
+	var envelope struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		apiErr.Message = envelope.Error.Message
+		if envelope.Error.Status != "" {
+			apiErr.Status = envelope.Error.Status
+		}
+	}
+
+	return apiErr
+}
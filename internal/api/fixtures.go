@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ReplayEnvVar names the environment variable that switches the API layer to
+// replaying recorded fixtures instead of calling Google
+const ReplayEnvVar = "GA4ADMIN_REPLAY"
+
+// Token exchanges are never recorded or replayed; replay mode skips OAuth entirely
+const oauthTokenHost = "oauth2.googleapis.com"
+
+var (
+	fixtureMutex  sync.RWMutex
+	fixtureDir    string
+	fixtureReplay bool
+)
+
+func init() {
+	if dir := os.Getenv(ReplayEnvVar); dir != "" {
+		fixtureDir = dir
+		fixtureReplay = true
+	}
+}
+
+// fixture is one recorded API exchange
+type fixture struct {
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	RequestBody  string `json:"request_body,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	ContentType  string `json:"content_type,omitempty"`
+	ResponseBody string `json:"response_body"`
+}
+
+// EnableFixtureRecording saves every API response under dir for later replay
+func EnableFixtureRecording(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	fixtureMutex.Lock()
+	defer fixtureMutex.Unlock()
+	fixtureDir = dir
+	fixtureReplay = false
+	return nil
+}
+
+// EnableFixtureReplay serves API responses from fixtures recorded under dir
+// without network access or credentials
+func EnableFixtureReplay(dir string) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("fixture directory %s not found", dir)
+	}
+
+	fixtureMutex.Lock()
+	defer fixtureMutex.Unlock()
+	fixtureDir = dir
+	fixtureReplay = true
+	return nil
+}
+
+// ReplayEnabled reports whether API calls are served from fixtures
+func ReplayEnabled() bool {
+	fixtureMutex.RLock()
+	defer fixtureMutex.RUnlock()
+	return fixtureReplay
+}
+
+func currentFixtureDir() string {
+	fixtureMutex.RLock()
+	defer fixtureMutex.RUnlock()
+	return fixtureDir
+}
+
+// fixtureTransport records responses to, or replays them from, the fixture directory
+type fixtureTransport struct {
+	base   http.RoundTripper
+	dir    string
+	replay bool
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == oauthTokenHost {
+		if t.replay {
+			return nil, fmt.Errorf("unexpected OAuth token request in replay mode")
+		}
+		return t.base.RoundTrip(req)
+	}
+
+	var requestBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	path := filepath.Join(t.dir, fixtureName(req.Method, req.URL.String(), requestBody))
+
+	if t.replay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("no fixture recorded for %s %s (%s)", req.Method, req.URL, filepath.Base(path))
+		}
+		var recorded fixture
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+		return recorded.response(req), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+	if err != nil {
+		return resp, err
+	}
+
+	recorded := fixture{
+		Method:       req.Method,
+		URL:          req.URL.String(),
+		RequestBody:  sanitizeBody(string(requestBody)),
+		StatusCode:   resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ResponseBody: sanitizeBody(string(responseBody)),
+	}
+	if data, err := json.MarshalIndent(recorded, "", "  "); err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+
+	return resp, nil
+}
+
+func (f *fixture) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if f.ContentType != "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.ResponseBody)),
+		ContentLength: int64(len(f.ResponseBody)),
+		Request:       req,
+	}
+}
+
+// fixtureName derives a stable file name from the request, so the same call
+// always maps to the same fixture
+func fixtureName(method, url string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + url + "\n"))
+	hash.Write(body)
+
+	// Keep the resource path visible to make fixture directories browsable
+	resource := url
+	if i := strings.Index(resource, "googleapis.com/"); i >= 0 {
+		resource = resource[i+len("googleapis.com/"):]
+	}
+	if i := strings.IndexAny(resource, "?"); i >= 0 {
+		resource = resource[:i]
+	}
+	resource = strings.NewReplacer("/", "_", ":", "_").Replace(resource)
+	if len(resource) > 80 {
+		resource = resource[:80]
+	}
+
+	return fmt.Sprintf("%s_%s_%x.json", strings.ToLower(method), resource, hash.Sum(nil)[:8])
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+
+	"ga4admin/internal/config"
+)
+
+// AdminService is the subset of the GA4 Admin API the tool relies on.
+// *AdminClient implements it; tests and demos can substitute fakes.
+type AdminService interface {
+	ListAccounts(ctx context.Context, opts ...AdminCallOption) ([]config.Account, error)
+	ListProperties(ctx context.Context, accountID string, opts ...AdminCallOption) ([]config.Property, error)
+	GetProperty(ctx context.Context, propertyID string, opts ...AdminCallOption) (*config.Property, error)
+	ListKeyEvents(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]KeyEvent, error)
+	ListChannelGroups(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]ChannelGroup, error)
+	ListBigQueryLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]BigQueryLink, error)
+	ListGoogleAdsLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]GoogleAdsLink, error)
+	ListFirebaseLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]FirebaseLink, error)
+	ListDataStreams(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]DataStream, error)
+	GetDataStream(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*DataStream, error)
+	GetEnhancedMeasurementSettings(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*EnhancedMeasurementSettings, error)
+	GetDataRedactionSettings(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*DataRedactionSettings, error)
+	ListCustomDimensions(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]CustomDimension, error)
+	CreateCustomDimension(ctx context.Context, propertyID string, dimension CustomDimension, opts ...AdminCallOption) (*CustomDimension, error)
+	UpdateCustomDimension(ctx context.Context, dimension CustomDimension, opts ...AdminCallOption) error
+	ArchiveCustomDimension(ctx context.Context, name string, opts ...AdminCallOption) error
+	CreateKeyEvent(ctx context.Context, propertyID string, keyEvent KeyEvent, opts ...AdminCallOption) (*KeyEvent, error)
+	UpdateKeyEventCountingMethod(ctx context.Context, name, countingMethod string, opts ...AdminCallOption) error
+	DeleteKeyEvent(ctx context.Context, name string, opts ...AdminCallOption) error
+	CreateChannelGroup(ctx context.Context, propertyID string, group ChannelGroup, opts ...AdminCallOption) (*ChannelGroup, error)
+	UpdateChannelGroup(ctx context.Context, group ChannelGroup, opts ...AdminCallOption) error
+	DeleteChannelGroup(ctx context.Context, name string, opts ...AdminCallOption) error
+	GetDataRetentionSettings(ctx context.Context, propertyID string, opts ...AdminCallOption) (*DataRetentionSettings, error)
+	UpdateDataRetentionSettings(ctx context.Context, propertyID string, settings DataRetentionSettings, opts ...AdminCallOption) error
+}
+
+// DataService is the subset of the GA4 Data API the tool relies on.
+// *DataClient implements it; tests and demos can substitute fakes.
+type DataService interface {
+	GetMetadata(ctx context.Context, propertyID string) (*MetadataResponse, error)
+	RunReport(ctx context.Context, request *RunReportRequest) (*RunReportResponse, error)
+	RunRealtimeReport(ctx context.Context, request *RunRealtimeReportRequest) (*RunReportResponse, error)
+	Close() error
+}
+
+// ReportPlanner is implemented by data services that can explain how a
+// report request would be answered
+type ReportPlanner interface {
+	PlanReport(ctx context.Context, request *RunReportRequest) (*ReportPlan, error)
+}
+
+// CompatibilityChecker is implemented by data services that can ask GA4
+// whether a request's fields can be queried together
+type CompatibilityChecker interface {
+	CheckCompatibility(ctx context.Context, request *CheckCompatibilityRequest) (*CheckCompatibilityResponse, error)
+}
+
+var (
+	_ AdminService         = (*AdminClient)(nil)
+	_ DataService          = (*DataClient)(nil)
+	_ ReportPlanner        = (*DataClient)(nil)
+	_ CompatibilityChecker = (*DataClient)(nil)
+)
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BigQueryLink exports a property's events to a BigQuery project
+type BigQueryLink struct {
+	Name                    string    `json:"name"`    // "properties/328687832/bigQueryLinks/abc"
+	Project                 string    `json:"project"` // "projects/123456789"
+	CreateTime              time.Time `json:"createTime"`
+	DailyExportEnabled      bool      `json:"dailyExportEnabled"`
+	StreamingExportEnabled  bool      `json:"streamingExportEnabled"`
+	FreshDailyExportEnabled bool      `json:"freshDailyExportEnabled"` // GA4 360 only
+	IncludeAdvertisingID    bool      `json:"includeAdvertisingId"`
+	ExportStreams           []string  `json:"exportStreams,omitempty"` // Empty means every stream
+	ExcludedEvents          []string  `json:"excludedEvents,omitempty"`
+	DatasetLocation         string    `json:"datasetLocation"`
+}
+
+// ExportModes lists the enabled export modes, e.g. "daily, streaming"
+func (l BigQueryLink) ExportModes() string {
+	var modes []string
+	if l.DailyExportEnabled {
+		modes = append(modes, "daily")
+	}
+	if l.FreshDailyExportEnabled {
+		modes = append(modes, "fresh daily")
+	}
+	if l.StreamingExportEnabled {
+		modes = append(modes, "streaming")
+	}
+	if len(modes) == 0 {
+		return "none"
+	}
+	return strings.Join(modes, ", ")
+}
+
+// GoogleAdsLink connects a property to a Google Ads account
+type GoogleAdsLink struct {
+	Name                      string    `json:"name"`             // "properties/328687832/googleAdsLinks/456"
+	CustomerID                string    `json:"customerId"`       // Google Ads customer ID without dashes
+	CanManageClients          bool      `json:"canManageClients"` // Set for manager accounts
+	AdsPersonalizationEnabled *bool     `json:"adsPersonalizationEnabled,omitempty"`
+	CreatorEmailAddress       string    `json:"creatorEmailAddress,omitempty"`
+	CreateTime                time.Time `json:"createTime"`
+	UpdateTime                time.Time `json:"updateTime"`
+}
+
+// FirebaseLink connects a property to a Firebase project. A property has at
+// most one.
+type FirebaseLink struct {
+	Name       string    `json:"name"`    // "properties/328687832/firebaseLinks/789"
+	Project    string    `json:"project"` // "projects/my-app" or "projects/123456789"
+	CreateTime time.Time `json:"createTime"`
+}
+
+// ListBigQueryLinks retrieves a property's BigQuery export links
+func (c *AdminClient) ListBigQueryLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]BigQueryLink, error) {
+	var links []BigQueryLink
+	err := c.listPropertyCollection(ctx, propertyID, "bigQueryLinks", opts, func(items json.RawMessage) error {
+		var page []BigQueryLink
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+		links = append(links, page...)
+		return nil
+	})
+	return links, err
+}
+
+// ListGoogleAdsLinks retrieves a property's Google Ads links
+func (c *AdminClient) ListGoogleAdsLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]GoogleAdsLink, error) {
+	var links []GoogleAdsLink
+	err := c.listPropertyCollection(ctx, propertyID, "googleAdsLinks", opts, func(items json.RawMessage) error {
+		var page []GoogleAdsLink
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+		links = append(links, page...)
+		return nil
+	})
+	return links, err
+}
+
+// ListFirebaseLinks retrieves a property's Firebase link, if any
+func (c *AdminClient) ListFirebaseLinks(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]FirebaseLink, error) {
+	var links []FirebaseLink
+	err := c.listPropertyCollection(ctx, propertyID, "firebaseLinks", opts, func(items json.RawMessage) error {
+		var page []FirebaseLink
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+		links = append(links, page...)
+		return nil
+	})
+	return links, err
+}
+
+// listPropertyCollection pages through properties/{id}/{collection}, passing
+// each page's items (the response field named after the collection) to add
+func (c *AdminClient) listPropertyCollection(ctx context.Context, propertyID, collection string, opts []AdminCallOption, add func(items json.RawMessage) error) error {
+	pageToken := ""
+	for {
+		path := fmt.Sprintf("/properties/%s/%s?pageSize=200", propertyID, collection)
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		resp, err := c.get(ctx, path, opts)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return fmt.Errorf("property %s %w", propertyID, ErrNotAccessible)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError("Admin", resp)
+			resp.Body.Close()
+			return apiErr
+		}
+
+		var apiResponse map[string]json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&apiResponse)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", collection, err)
+		}
+
+		if items, ok := apiResponse[collection]; ok {
+			if err := add(items); err != nil {
+				return fmt.Errorf("failed to decode %s response: %w", collection, err)
+			}
+		}
+
+		var nextPageToken string
+		if raw, ok := apiResponse["nextPageToken"]; ok {
+			if err := json.Unmarshal(raw, &nextPageToken); err != nil {
+				return fmt.Errorf("failed to decode %s response: %w", collection, err)
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// NetworkOptions configures the connections used for all GA4 API calls
+type NetworkOptions struct {
+	RequestTimeout  time.Duration // Per HTTP request; 0 means no limit
+	ProxyURL        string        // Explicit proxy; empty falls back to HTTP(S)_PROXY
+	CABundle        string        // PEM file with extra trusted root certificates
+	MaxConnsPerHost int           // Open connections per host; 0 means DefaultMaxConnsPerHost
+}
+
+// DefaultMaxConnsPerHost bounds connections per API host. Over HTTP/2 each
+// connection carries many concurrent requests, so batch commands rarely need
+// more than one or two.
+const DefaultMaxConnsPerHost = 16
+
+// Connection pool tuning for the shared transport
+const (
+	idleConnTimeout = 90 * time.Second
+	http2PingAfter  = 30 * time.Second // Health-check a connection silent this long
+	http2PingWait   = 15 * time.Second
+)
+
+var (
+	networkMutex   sync.RWMutex
+	networkOptions NetworkOptions
+
+	// The pooled transport shared by every client, rebuilt when the options
+	// it was built from change
+	transportMutex    sync.Mutex
+	pooledTransport   *http.Transport
+	pooledTransportOf NetworkOptions
+)
+
+// SetNetworkOptions sets the options used by every client created afterwards
+func SetNetworkOptions(options NetworkOptions) {
+	networkMutex.Lock()
+	defer networkMutex.Unlock()
+	networkOptions = options
+}
+
+func currentNetworkOptions() NetworkOptions {
+	networkMutex.RLock()
+	defer networkMutex.RUnlock()
+	return networkOptions
+}
+
+// baseHTTPClient builds the unauthenticated HTTP client that OAuth and API
+// requests are layered on, honoring proxy, CA bundle and timeout settings
+func baseHTTPClient() (*http.Client, error) {
+	client, err := NewHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := currentFixtureDir(); dir != "" {
+		client.Transport = &fixtureTransport{base: client.Transport, dir: dir, replay: ReplayEnabled()}
+	}
+	if tracingEnabled() {
+		client.Transport = &tracingTransport{base: client.Transport}
+	}
+	client.Transport = &activityTransport{base: client.Transport}
+
+	return client, nil
+}
+
+// NewHTTPClient returns an HTTP client honoring the proxy, CA bundle and
+// timeout settings, for services outside Google such as notifiers. API
+// tracing and fixture recording are not applied. All clients share one
+// connection pool, so keep-alive connections are reused across the clients
+// a command creates.
+func NewHTTPClient() (*http.Client, error) {
+	options := currentNetworkOptions()
+
+	transport, err := sharedTransport(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   options.RequestTimeout,
+	}, nil
+}
+
+// sharedTransport returns the pooled transport for options, building it on
+// first use or when the connection settings changed
+func sharedTransport(options NetworkOptions) (*http.Transport, error) {
+	// The timeout is per client, so it doesn't need a pool of its own
+	options.RequestTimeout = 0
+
+	transportMutex.Lock()
+	defer transportMutex.Unlock()
+
+	if pooledTransport != nil && pooledTransportOf == options {
+		return pooledTransport, nil
+	}
+
+	transport, err := newPooledTransport(options)
+	if err != nil {
+		return nil, err
+	}
+	if pooledTransport != nil {
+		pooledTransport.CloseIdleConnections()
+	}
+	pooledTransport, pooledTransportOf = transport, options
+	return transport, nil
+}
+
+// newPooledTransport builds a keep-alive transport that prefers HTTP/2
+func newPooledTransport(options NetworkOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	maxConns := options.MaxConnsPerHost
+	if maxConns <= 0 {
+		maxConns = DefaultMaxConnsPerHost
+	}
+	transport.MaxConnsPerHost = maxConns
+	// The default of 2 idle connections per host makes concurrent batches
+	// over HTTP/1.1 (e.g. through a proxy) reconnect for most requests
+	transport.MaxIdleConnsPerHost = maxConns
+	transport.IdleConnTimeout = idleConnTimeout
+
+	// A custom TLS config turns off automatic HTTP/2 unless forced
+	transport.ForceAttemptHTTP2 = true
+	transport.HTTP2 = &http.HTTP2Config{
+		SendPingTimeout: http2PingAfter,
+		PingTimeout:     http2PingWait,
+	}
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL '%s': %w", options.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	} else {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	tlsConfig, err := networkTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// networkTLSConfig returns a TLS config trusting the system roots plus the
+// configured CA bundle (needed behind TLS-inspecting corporate proxies)
+func networkTLSConfig(options NetworkOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if options.CABundle == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(options.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", options.CABundle)
+	}
+
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// AuthorizeInBrowser runs Google's installed-app OAuth flow and returns a
+// token that includes a refresh token. A one-shot callback server listens on
+// a random loopback port; open is called with the consent URL and should show
+// it to the user or launch a browser. The flow ends when Google redirects
+// back or ctx is done.
+func (a *AuthClient) AuthorizeInBrowser(ctx context.Context, scopes []string, open func(authURL string)) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local callback server: %w", err)
+	}
+
+	flowConfig := *a.config
+	flowConfig.Scopes = scopes
+	flowConfig.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+	verifier := oauth2.GenerateVerifier()
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		var result callbackResult
+		switch {
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("OAuth callback state mismatch")
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization was not granted: %s", query.Get("error"))
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("OAuth callback carried no authorization code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if result.err != nil {
+			fmt.Fprintf(w, "<p>ga4admin: %s</p>", html.EscapeString(result.err.Error()))
+		} else {
+			fmt.Fprint(w, "<p>ga4admin: authorization complete. You can close this tab.</p>")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	open(flowConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(verifier)))
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for authorization: %w", ctx.Err())
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	// Exchange through the configured proxy/CA settings
+	baseClient, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	token, err := flowConfig.Exchange(context.WithValue(ctx, oauth2.HTTPClient, baseClient), result.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token was returned - revoke ga4admin's access in your Google account settings and try again")
+	}
+
+	return token, nil
+}
+
+// DeviceCode is what the user needs to approve a device authorization from
+// another device: the code to enter and the page to enter it on
+type DeviceCode struct {
+	UserCode        string
+	VerificationURL string
+	Expiry          time.Time
+}
+
+// AuthorizeOnDevice runs the OAuth device authorization flow (RFC 8628) for
+// machines without a browser. show is called with the code the user enters on
+// any other device; the flow then polls Google until the code is approved,
+// denied or expires, or ctx is done. The OAuth client must be a "TVs and
+// Limited Input devices" client, and Google only grants some scopes this way.
+func (a *AuthClient) AuthorizeOnDevice(ctx context.Context, scopes []string, show func(DeviceCode)) (*oauth2.Token, error) {
+	flowConfig := *a.config
+	flowConfig.Scopes = scopes
+
+	// Poll through the configured proxy/CA settings
+	baseClient, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, baseClient)
+
+	auth, err := flowConfig.DeviceAuth(ctx)
+	if err != nil {
+		return nil, deviceFlowError("failed to start device authorization", err)
+	}
+	show(DeviceCode{UserCode: auth.UserCode, VerificationURL: auth.VerificationURI, Expiry: auth.Expiry})
+
+	token, err := flowConfig.DeviceAccessToken(ctx, auth)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && !auth.Expiry.IsZero() && time.Now().After(auth.Expiry) {
+			return nil, fmt.Errorf("the code expired before it was approved - run the command again")
+		}
+		return nil, deviceFlowError("device authorization failed", err)
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token was returned - revoke ga4admin's access in your Google account settings and try again")
+	}
+	return token, nil
+}
+
+// deviceFlowError explains the OAuth errors the device flow commonly ends in
+func deviceFlowError(action string, err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+
+	switch retrieveErrorCode(retrieveErr) {
+	case "invalid_scope":
+		return fmt.Errorf("%s: Google doesn't grant the requested scopes through the device flow for this client (%w)", action, err)
+	case "invalid_client", "unauthorized_client":
+		return fmt.Errorf("%s: the OAuth client must be a \"TVs and Limited Input devices\" client to use the device flow (%w)", action, err)
+	case "access_denied":
+		return fmt.Errorf("authorization was not granted (%w)", err)
+	case "expired_token":
+		return fmt.Errorf("the code expired before it was approved - run the command again (%w)", err)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// retrieveErrorCode returns the OAuth error code of a failed token request.
+// oauth2 leaves ErrorCode empty for the device code and JWT endpoints, so the
+// body is read then.
+func retrieveErrorCode(err *oauth2.RetrieveError) string {
+	if err.ErrorCode != "" {
+		return err.ErrorCode
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(err.Body, &body) != nil {
+		return ""
+	}
+	return body.Error
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var daysAgoDate = regexp.MustCompile(`^(\d+)daysAgo$`)
+
+// NormalizeReportDate returns the canonical spelling of a report date, so
+// equivalent dates hash the same: 0daysAgo is today, 1daysAgo is yesterday
+// and leading zeros are dropped
+func NormalizeReportDate(date string) string {
+	date = strings.TrimSpace(date)
+	match := daysAgoDate.FindStringSubmatch(date)
+	if match == nil {
+		return date
+	}
+	days, err := strconv.Atoi(match[1])
+	if err != nil {
+		return date
+	}
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	}
+	return strconv.Itoa(days) + "daysAgo"
+}
+
+// canonicalRequest returns a copy of request that asks for the same data in
+// a fixed form: dimensions, metrics, aggregations, in-list values and the
+// filters of each group sorted, dates normalized and quota reporting off.
+// Date ranges and orderings keep their order, which changes the result.
+func canonicalRequest(request *RunReportRequest) *RunReportRequest {
+	canonical := *request
+	canonical.ReturnPropertyQuota = false
+
+	canonical.Dimensions = append([]Dimension(nil), request.Dimensions...)
+	sort.SliceStable(canonical.Dimensions, func(i, j int) bool {
+		a, b := canonical.Dimensions[i], canonical.Dimensions[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.DimensionExpression < b.DimensionExpression
+	})
+	canonical.Metrics = append([]Metric(nil), request.Metrics...)
+	sort.SliceStable(canonical.Metrics, func(i, j int) bool {
+		a, b := canonical.Metrics[i], canonical.Metrics[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Expression < b.Expression
+	})
+	canonical.MetricAggregations = append([]string(nil), request.MetricAggregations...)
+	sort.Strings(canonical.MetricAggregations)
+
+	canonical.DateRanges = make([]DateRange, len(request.DateRanges))
+	for i, dateRange := range request.DateRanges {
+		dateRange.StartDate = NormalizeReportDate(dateRange.StartDate)
+		dateRange.EndDate = NormalizeReportDate(dateRange.EndDate)
+		canonical.DateRanges[i] = dateRange
+	}
+
+	canonical.DimensionFilter = canonicalFilter(request.DimensionFilter)
+	canonical.MetricFilter = canonicalFilter(request.MetricFilter)
+	return &canonical
+}
+
+// canonicalFilter sorts the expressions of AND and OR groups and the values
+// of in-list filters, whose order doesn't matter to GA4
+func canonicalFilter(expression *FilterExpression) *FilterExpression {
+	if expression == nil {
+		return nil
+	}
+	canonical := *expression
+	for _, group := range []**FilterExpressionList{&canonical.AndGroup, &canonical.OrGroup} {
+		if *group == nil {
+			continue
+		}
+		type keyed struct {
+			key        string
+			expression FilterExpression
+		}
+		children := make([]keyed, len((*group).Expressions))
+		for i := range (*group).Expressions {
+			child := canonicalFilter(&(*group).Expressions[i])
+			key, _ := json.Marshal(child)
+			children[i] = keyed{string(key), *child}
+		}
+		sort.SliceStable(children, func(i, j int) bool { return children[i].key < children[j].key })
+		sorted := &FilterExpressionList{Expressions: make([]FilterExpression, len(children))}
+		for i, child := range children {
+			sorted.Expressions[i] = child.expression
+		}
+		*group = sorted
+	}
+	canonical.NotExpression = canonicalFilter(expression.NotExpression)
+	if expression.Filter != nil && expression.Filter.InListFilter != nil {
+		filter := *expression.Filter
+		inList := *filter.InListFilter
+		inList.Values = append([]string(nil), inList.Values...)
+		sort.Strings(inList.Values)
+		filter.InListFilter = &inList
+		canonical.Filter = &filter
+	}
+	return &canonical
+}
+
+// hashRequest returns the cache key of a report request. Requests that
+// differ only in the order of their fields or in how dates are spelled share
+// a key. Without an explicit ordering GA4 sorts by the first metric, so it
+// stays part of the key.
+func hashRequest(request *RunReportRequest) string {
+	key := struct {
+		Property     string            `json:"property"`
+		Request      *RunReportRequest `json:"request"`
+		DefaultOrder string            `json:"defaultOrder,omitempty"`
+	}{Property: request.Property, Request: canonicalRequest(request)}
+	if len(request.OrderBys) == 0 && len(request.Metrics) > 0 {
+		key.DefaultOrder = request.Metrics[0].Name
+	}
+	data, _ := json.Marshal(&key)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// alignColumns reorders a cached response's columns to the order request
+// asks for, since a response cached for the same fields in another order
+// shares its key. Responses whose headers don't match the request are left
+// as they are.
+func alignColumns(response *RunReportResponse, request *RunReportRequest) {
+	dimensionNames := make([]string, len(request.Dimensions))
+	for i, dimension := range request.Dimensions {
+		dimensionNames[i] = dimension.Name
+	}
+	metricNames := make([]string, len(request.Metrics))
+	for i, metric := range request.Metrics {
+		metricNames[i] = metric.Name
+	}
+
+	cachedDimensions := make([]string, len(response.DimensionHeaders))
+	for i, header := range response.DimensionHeaders {
+		cachedDimensions[i] = header.Name
+	}
+	cachedMetrics := make([]string, len(response.MetricHeaders))
+	for i, header := range response.MetricHeaders {
+		cachedMetrics[i] = header.Name
+	}
+
+	dimensionOrder, ok := columnOrder(cachedDimensions, dimensionNames)
+	if !ok {
+		return
+	}
+	metricOrder, ok := columnOrder(cachedMetrics, metricNames)
+	if !ok {
+		return
+	}
+	if dimensionOrder == nil && metricOrder == nil {
+		return
+	}
+
+	if dimensionOrder != nil {
+		headers := make([]DimensionHeader, len(dimensionOrder))
+		for i, from := range dimensionOrder {
+			headers[i] = response.DimensionHeaders[from]
+		}
+		response.DimensionHeaders = headers
+	}
+	if metricOrder != nil {
+		headers := make([]MetricHeader, len(metricOrder))
+		for i, from := range metricOrder {
+			headers[i] = response.MetricHeaders[from]
+		}
+		response.MetricHeaders = headers
+	}
+	for _, rows := range [][]Row{response.Rows, response.Totals, response.Maximums, response.Minimums} {
+		for i := range rows {
+			rows[i] = reorderRow(rows[i], dimensionOrder, metricOrder)
+		}
+	}
+}
+
+// columnOrder returns, for each wanted column, its index among cached; nil
+// when they are already in order. ok is false when the columns differ.
+func columnOrder(cached, wanted []string) (order []int, ok bool) {
+	if len(cached) != len(wanted) {
+		return nil, false
+	}
+	index := make(map[string]int, len(cached))
+	for i, name := range cached {
+		index[name] = i
+	}
+	inOrder := true
+	order = make([]int, len(wanted))
+	for i, name := range wanted {
+		from, found := index[name]
+		if !found {
+			return nil, false
+		}
+		order[i] = from
+		inOrder = inOrder && from == i
+	}
+	if inOrder {
+		return nil, true
+	}
+	return order, true
+}
+
+func reorderRow(row Row, dimensionOrder, metricOrder []int) Row {
+	if dimensionOrder != nil && len(row.DimensionValues) == len(dimensionOrder) {
+		values := make([]DimensionValue, len(dimensionOrder))
+		for i, from := range dimensionOrder {
+			values[i] = row.DimensionValues[from]
+		}
+		row.DimensionValues = values
+	}
+	if metricOrder != nil && len(row.MetricValues) == len(metricOrder) {
+		values := make([]MetricValue, len(metricOrder))
+		for i, from := range metricOrder {
+			values[i] = row.MetricValues[from]
+		}
+		row.MetricValues = values
+	}
+	return row
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// Query ID parts are kept short enough to read in 'results list'
+const (
+	maxQueryIDFields   = 2
+	maxQueryIDFieldLen = 40
+	queryIDHashBytes   = 2
+)
+
+var (
+	nonSlugChars  = regexp.MustCompile(`[^a-z0-9]+`)
+	relativeDays  = regexp.MustCompile(`^(\d+)daysAgo$`)
+	issuedIDs     = make(map[string]bool)
+	issuedIDsLock sync.Mutex
+)
+
+// NewQueryID returns an ID for caching request's result that people can
+// read, such as acme-prod_source-medium_2024-06-01_a1b2: the property's
+// alias in presetName (empty for the active preset) or its ID, the first
+// dimensions (or metrics), the start date and a random hash. variant, such
+// as "top10", tells derived results apart. IDs already issued by this
+// process or found in store, which may be nil, aren't reused.
+func NewQueryID(ctx context.Context, store CacheInterface, presetName string, request *RunReportRequest, variant string) string {
+	parts := []string{propertyLabel(presetName, request.Property)}
+	if fields := queryIDFields(request); fields != "" {
+		parts = append(parts, fields)
+	}
+	if len(request.DateRanges) > 0 {
+		parts = append(parts, queryIDDate(request.DateRanges[0].StartDate, time.Now()))
+	}
+	if variant = slug(variant); variant != "" {
+		parts = append(parts, variant)
+	}
+	prefix := strings.Join(parts, "_")
+
+	ages, _ := store.(QueryAgeCache)
+	issuedIDsLock.Lock()
+	defer issuedIDsLock.Unlock()
+	// Longer hashes after a collision make a further one unlikely
+	for size := queryIDHashBytes; ; size++ {
+		hash := make([]byte, size)
+		if _, err := rand.Read(hash); err != nil {
+			hash = []byte(strconv.FormatInt(time.Now().UnixNano(), 16))
+		}
+		id := prefix + "_" + hex.EncodeToString(hash)
+		if issuedIDs[id] {
+			continue
+		}
+		if ages != nil {
+			if _, found, err := ages.QueryCachedAt(ctx, id); err == nil && found {
+				continue
+			}
+		}
+		issuedIDs[id] = true
+		return id
+	}
+}
+
+// propertyLabel returns the property's alias in presetName, the shortest
+// when there are several, or its ID
+func propertyLabel(presetName, propertyID string) string {
+	propertyID = strings.TrimPrefix(propertyID, "properties/")
+	var p *config.Preset
+	if presetName != "" {
+		p, _ = preset.LoadPreset(presetName)
+	} else {
+		p, _ = preset.GetActivePreset()
+	}
+	if p != nil {
+		var aliases []string
+		for alias, id := range p.Aliases {
+			if id == propertyID {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Slice(aliases, func(i, j int) bool {
+			if len(aliases[i]) != len(aliases[j]) {
+				return len(aliases[i]) < len(aliases[j])
+			}
+			return aliases[i] < aliases[j]
+		})
+		if len(aliases) > 0 {
+			if label := slug(aliases[0]); label != "" {
+				return label
+			}
+		}
+	}
+	if label := slug(propertyID); label != "" {
+		return label
+	}
+	return "query"
+}
+
+// queryIDFields names the request's first dimensions, or its first metrics
+// when it has none: sessionSourceMedium becomes source-medium
+func queryIDFields(request *RunReportRequest) string {
+	var names []string
+	for _, d := range request.Dimensions {
+		names = append(names, d.Name)
+	}
+	if len(names) == 0 {
+		for _, m := range request.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+	if len(names) > maxQueryIDFields {
+		names = names[:maxQueryIDFields]
+	}
+
+	var words []string
+	for _, name := range names {
+		// customEvent:plan is named by its parameter
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			name = name[i+1:]
+		}
+		if rest := strings.TrimPrefix(name, "session"); rest != name && rest != "" && unicode.IsUpper(rune(rest[0])) {
+			name = rest
+		}
+		if word := slug(kebab(name)); word != "" {
+			words = append(words, word)
+		}
+	}
+	fields := strings.Join(words, "-")
+	if len(fields) > maxQueryIDFieldLen {
+		fields = strings.TrimRight(fields[:maxQueryIDFieldLen], "-")
+	}
+	return fields
+}
+
+// queryIDDate resolves today, yesterday and NdaysAgo to a date, so IDs say
+// which data they hold
+func queryIDDate(date string, now time.Time) string {
+	switch date {
+	case "today":
+		return now.Format("2006-01-02")
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	if match := relativeDays.FindStringSubmatch(date); match != nil {
+		days, _ := strconv.Atoi(match[1])
+		return now.AddDate(0, 0, -days).Format("2006-01-02")
+	}
+	return slug(date)
+}
+
+// kebab splits camelCase words with hyphens
+func kebab(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// slug lowercases value and replaces anything but letters and digits with
+// single hyphens
+func slug(value string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+// newQueryID returns an ID for caching request's result in the client's cache
+func (c *DataClient) newQueryID(ctx context.Context, request *RunReportRequest) string {
+	return NewQueryID(ctx, c.cacheClient, c.authClient.presetName, request, "")
+}
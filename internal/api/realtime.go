@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RealtimeCount is the active users for one dimension value
+type RealtimeCount struct {
+	Value       string `json:"value"`
+	ActiveUsers int64  `json:"active_users"`
+}
+
+// RealtimeSnapshot is one poll of a property's realtime activity
+type RealtimeSnapshot struct {
+	PropertyID  string          `json:"property_id"`
+	ActiveUsers int64           `json:"active_users"` // Last 30 minutes
+	PerMinute   []int64         `json:"per_minute"`   // Oldest first, one entry per minute
+	ByPage      []RealtimeCount `json:"by_page"`
+	ByCountry   []RealtimeCount `json:"by_country"`
+	FetchedAt   time.Time       `json:"fetched_at"`
+}
+
+// Realtime reports cover the last 30 minutes for standard properties
+const realtimeWindowMinutes = 30
+
+// GetRealtimeSnapshot fetches active users overall, per minute, and for the
+// top `limit` pages/screens and countries. Active users are distinct counts,
+// so each breakdown needs its own report rather than summing one.
+func (c *DataClient) GetRealtimeSnapshot(ctx context.Context, propertyID string, limit int64) (*RealtimeSnapshot, error) {
+	snapshot := &RealtimeSnapshot{
+		PropertyID: propertyID,
+		PerMinute:  make([]int64, realtimeWindowMinutes),
+		FetchedAt:  time.Now(),
+	}
+
+	total, err := c.realtimeCounts(ctx, propertyID, "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(total) > 0 {
+		snapshot.ActiveUsers = total[0].ActiveUsers
+	}
+
+	minutes, err := c.realtimeCounts(ctx, propertyID, "minutesAgo", realtimeWindowMinutes)
+	if err != nil {
+		return nil, err
+	}
+	for _, minute := range minutes {
+		ago, err := strconv.Atoi(minute.Value)
+		if err != nil || ago < 0 || ago >= realtimeWindowMinutes {
+			continue
+		}
+		snapshot.PerMinute[realtimeWindowMinutes-1-ago] = minute.ActiveUsers
+	}
+
+	if snapshot.ByPage, err = c.realtimeCounts(ctx, propertyID, "unifiedScreenName", limit); err != nil {
+		return nil, err
+	}
+	if snapshot.ByCountry, err = c.realtimeCounts(ctx, propertyID, "country", limit); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// realtimeCounts returns active users per value of dimension (or the total
+// when dimension is empty), highest first
+func (c *DataClient) realtimeCounts(ctx context.Context, propertyID, dimension string, limit int64) ([]RealtimeCount, error) {
+	request := &RunRealtimeReportRequest{
+		Property: propertyID,
+		Metrics:  []Metric{{Name: "activeUsers"}},
+		Limit:    limit,
+		OrderBys: []OrderBy{{Metric: &MetricOrderBy{MetricName: "activeUsers"}, Desc: true}},
+	}
+	if dimension != "" {
+		request.Dimensions = []Dimension{{Name: dimension}}
+	}
+
+	response, err := c.RunRealtimeReport(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run realtime report: %w", err)
+	}
+
+	counts := make([]RealtimeCount, 0, len(response.Rows))
+	for _, row := range response.Rows {
+		count := RealtimeCount{}
+		if len(row.DimensionValues) > 0 {
+			count.Value = row.DimensionValues[0].Value
+		}
+		if len(row.MetricValues) > 0 {
+			count.ActiveUsers, _ = strconv.ParseInt(row.MetricValues[0].Value, 10, 64)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, nil
+}
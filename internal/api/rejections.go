@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ga4admin/internal/config"
+)
+
+// rejectedQueryTTL is how long a request GA4 rejected as invalid fails
+// locally. Invalid fields or combinations stay invalid, but a custom
+// definition created meanwhile could make the request valid.
+const rejectedQueryTTL = 15 * time.Minute
+
+// RejectionCache is implemented by caches that remember report requests GA4
+// rejected, so identical requests in a batch fail fast without spending quota
+type RejectionCache interface {
+	RecordRejectedQuery(ctx context.Context, rejection config.RejectedQuery) error
+	GetRejectedQuery(ctx context.Context, queryHash string) (*config.RejectedQuery, error)
+}
+
+// rejectedReport returns GA4's original error for a request it rejected as
+// invalid within rejectedQueryTTL, or nil
+func (c *DataClient) rejectedReport(ctx context.Context, queryHash string) error {
+	rejections, ok := c.cacheClient.(RejectionCache)
+	if !ok || queryHash == "" || cacheBypassed(ctx) {
+		return nil
+	}
+	rejection, err := rejections.GetRejectedQuery(ctx, queryHash)
+	if err != nil || rejection == nil {
+		return nil
+	}
+	apiErr := &APIError{
+		API:        rejection.API,
+		StatusCode: rejection.StatusCode,
+		Status:     rejection.Status,
+		Message:    rejection.Message,
+	}
+	return fmt.Errorf("%w (rejected %s ago; not sent again until %s, or run it with --no-cache)",
+		apiErr, time.Since(rejection.RejectedAt).Round(time.Second), rejection.ExpiresAt.Local().Format("15:04"))
+}
+
+// rememberRejection records a request GA4 rejected as invalid. Other
+// failures, such as quota or network errors, may pass on a retry.
+func (c *DataClient) rememberRejection(ctx context.Context, queryHash string, request *RunReportRequest, err error) {
+	rejections, ok := c.cacheClient.(RejectionCache)
+	var apiErr *APIError
+	if !ok || queryHash == "" || !errors.As(err, &apiErr) || !apiErr.IsInvalidArgument() {
+		return
+	}
+	now := time.Now()
+	rejections.RecordRejectedQuery(ctx, config.RejectedQuery{
+		QueryHash:  queryHash,
+		PropertyID: request.Property,
+		API:        apiErr.API,
+		StatusCode: apiErr.StatusCode,
+		Status:     apiErr.Status,
+		Message:    apiErr.Message,
+		RejectedAt: now,
+		ExpiresAt:  now.Add(rejectedQueryTTL),
+	})
+}
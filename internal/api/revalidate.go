@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StaleCache is implemented by caches that can return expired entries for
+// stale-while-revalidate mode
+type StaleCache interface {
+	GetStaleMetadata(ctx context.Context, propertyID, cacheType string, result interface{}) (found, stale bool, err error)
+	GetStaleQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (queryID string, found, stale bool, err error)
+}
+
+// revalidateTimeout bounds each background refresh
+const revalidateTimeout = 2 * time.Minute
+
+// revalidator tracks background refreshes so each cache entry is refreshed
+// at most once at a time and Close can wait for them to land
+type revalidator struct {
+	mu       sync.Mutex
+	inFlight map[string]bool
+	wg       sync.WaitGroup
+}
+
+type cacheBypassKey struct{}
+
+// withCacheBypass marks a context whose calls must skip cache lookups, so a
+// refresh never reads back the stale entries it is replacing
+func withCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// WithoutCache returns a context whose reports are fetched from the API even
+// when cached; the fresh results are still cached
+func WithoutCache(ctx context.Context) context.Context {
+	return withCacheBypass(ctx)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// SetStaleWhileRevalidate switches the client to serving expired cache
+// entries immediately while refreshing them in the background. It returns
+// false if the client's cache cannot serve stale entries.
+func (c *DataClient) SetStaleWhileRevalidate(enabled bool) bool {
+	c.staleCache = nil
+	if enabled {
+		if staleCache, ok := c.cacheClient.(StaleCache); ok {
+			c.staleCache = staleCache
+		}
+	}
+	return c.staleCache != nil
+}
+
+// WaitForRevalidation blocks until pending background refreshes finish
+func (c *DataClient) WaitForRevalidation() {
+	c.revalidator.wg.Wait()
+}
+
+// revalidate runs refresh in the background unless key is already being
+// refreshed. Failures keep the stale entry; the next read retries.
+func (c *DataClient) revalidate(key string, refresh func(ctx context.Context) error) {
+	r := &c.revalidator
+	r.mu.Lock()
+	if r.inFlight == nil {
+		r.inFlight = make(map[string]bool)
+	}
+	if r.inFlight[key] {
+		r.mu.Unlock()
+		return
+	}
+	r.inFlight[key] = true
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.inFlight, key)
+			r.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(withCacheBypass(context.Background()), revalidateTimeout)
+		defer cancel()
+		refresh(ctx)
+	}()
+}
+
+// cachedMetadata looks up a metadata cache entry. In stale-while-revalidate
+// mode expired entries are returned too and refresh runs in the background.
+func (c *DataClient) cachedMetadata(ctx context.Context, propertyID, cacheType string, result interface{}, refresh func(ctx context.Context) error) bool {
+	if c.cacheClient == nil || cacheBypassed(ctx) {
+		return false
+	}
+
+	if c.staleCache == nil {
+		found, err := c.cacheClient.GetCachedMetadata(ctx, propertyID, cacheType, result)
+		return err == nil && found
+	}
+
+	found, stale, err := c.staleCache.GetStaleMetadata(ctx, propertyID, cacheType, result)
+	if err != nil || !found {
+		return false
+	}
+	if stale {
+		c.revalidate("metadata/"+propertyID+"/"+cacheType, refresh)
+	}
+	return true
+}
+
+// cachedAnalysis looks up a cached analysis result, with the same
+// stale-while-revalidate handling as cachedMetadata
+func (c *DataClient) cachedAnalysis(ctx context.Context, analysisHash string, result interface{}, refresh func(ctx context.Context) error) bool {
+	analysisCache, ok := c.cacheClient.(AnalysisCache)
+	if !ok || cacheBypassed(ctx) {
+		return false
+	}
+
+	if c.staleCache == nil {
+		found, err := analysisCache.GetCachedAnalysis(ctx, analysisHash, result)
+		return err == nil && found
+	}
+
+	found, stale, err := analysisCache.GetStaleAnalysis(ctx, analysisHash, result)
+	if err != nil || !found {
+		return false
+	}
+	if stale {
+		c.revalidate("analysis/"+analysisHash, refresh)
+	}
+	return true
+}
+
+// cachedReport looks up cached report results. Stale results are refreshed
+// in place under the same query ID so named tables keep pointing at them.
+func (c *DataClient) cachedReport(ctx context.Context, queryHash string, request *RunReportRequest) (*RunReportResponse, bool) {
+	if c.cacheClient == nil || cacheBypassed(ctx) {
+		return nil, false
+	}
+
+	var cached RunReportResponse
+	if c.staleCache == nil {
+		queryID, found, err := c.cacheClient.GetCachedQuery(ctx, queryHash, request, &cached)
+		if err != nil || !found {
+			return nil, false
+		}
+		cached.QueryID = queryID
+		cached.FromCache = true
+		if !c.withinMaxAge(ctx, &cached) {
+			return nil, false
+		}
+		alignColumns(&cached, request)
+		return &cached, true
+	}
+
+	queryID, found, stale, err := c.staleCache.GetStaleQuery(ctx, queryHash, request, &cached)
+	if err != nil || !found {
+		return nil, false
+	}
+	// Entries past the maximum age are fetched now rather than in the background
+	cached.QueryID = queryID
+	if !c.withinMaxAge(ctx, &cached) {
+		return nil, false
+	}
+	if stale {
+		refreshRequest := *request
+		c.revalidate("query/"+queryHash, func(ctx context.Context) error {
+			response, err := c.transport.runReport(ctx, &refreshRequest)
+			if err != nil {
+				return err
+			}
+			ttl := queryCacheTTLHours
+			return c.cacheClient.CacheQuery(ctx, queryID, refreshRequest.Property, queryHash, &refreshRequest, *response, response.RowCount, &ttl)
+		})
+	}
+	cached.FromCache = true
+	alignColumns(&cached, request)
+	return &cached, true
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// ErrMissingScope means a preset's refresh token wasn't granted a scope the
+// command needs, e.g. analytics.edit for a command that changes configuration
+var ErrMissingScope = errors.New("refresh token lacks a required OAuth scope")
+
+// scopePrefix is dropped from scopes shown to users
+const scopePrefix = "https://www.googleapis.com/auth/"
+
+// ParseScopes turns scope names given on the command line into OAuth
+// scopes. readonly, analytics.readonly and the full scope URL all name the
+// same scope. analytics.readonly is always included, since every command
+// reads.
+func ParseScopes(names []string) ([]string, error) {
+	scopes := []string{AnalyticsReadOnlyScope}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), scopePrefix))
+		var scope string
+		switch strings.TrimPrefix(name, "analytics.") {
+		case "readonly":
+			scope = AnalyticsReadOnlyScope
+		case "edit":
+			scope = AnalyticsEditScope
+		default:
+			return nil, fmt.Errorf("unknown scope '%s' (expected readonly or edit)", name)
+		}
+		if !HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// ScopeName shortens an OAuth scope for display, e.g. analytics.edit
+func ScopeName(scope string) string {
+	return strings.TrimPrefix(scope, scopePrefix)
+}
+
+// ScopeNames returns the short names of scopes, sorted
+func ScopeNames(scopes []string) []string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = ScopeName(scope)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasScope reports whether scope is among scopes
+func HasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenScopes returns the scopes Google granted with a token, read from the
+// token response. It is nil when the response didn't list them.
+func TokenScopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	return strings.Fields(scope)
+}
+
+// GrantedScopes exchanges a refresh token for an access token and returns
+// the scopes Google granted it. Users can untick scopes on the consent
+// screen, so this can be narrower than what was requested.
+func (a *AuthClient) GrantedScopes(ctx context.Context, refreshToken string) ([]string, error) {
+	if ReplayEnabled() {
+		return nil, nil
+	}
+	token, err := a.refreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return TokenScopes(token), nil
+}
+
+// RequireScope verifies that the preset's refresh token was granted scope
+// before a command relies on it. Presets created before scopes were recorded
+// are checked with Google once and the grant is saved to the preset.
+func RequireScope(ctx context.Context, p *config.Preset, scope string) error {
+	scopes := p.Scopes
+	if len(scopes) == 0 && UsesServiceAccount(p) {
+		// Service accounts request only analytics.readonly unless told otherwise
+		scopes = []string{AnalyticsReadOnlyScope}
+	}
+	if len(scopes) == 0 {
+		authClient, err := NewAuthClientForPreset(p.Name)
+		if err != nil {
+			return err
+		}
+		granted, err := authClient.GrantedScopes(ctx, p.RefreshToken)
+		if isInvalidGrant(err) {
+			preset.SetNeedsReauth(p.Name, true)
+			return fmt.Errorf("preset '%s': %w - run 'ga4admin preset reauth %s'", p.Name, ErrReauthRequired, p.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check the preset's OAuth scopes: %w", err)
+		}
+		if len(granted) == 0 {
+			// Google didn't say; the API will refuse the call if the scope is missing
+			return nil
+		}
+		// Failing to save only costs the next command another check
+		preset.SetScopes(p.Name, granted)
+		p.Scopes = granted
+		scopes = granted
+	}
+
+	if HasScope(scopes, scope) {
+		return nil
+	}
+	return fmt.Errorf("preset '%s': %w: granted %s, needs %s", p.Name, ErrMissingScope,
+		strings.Join(ScopeNames(scopes), ", "), ScopeName(scope))
+}
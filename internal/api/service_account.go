@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"ga4admin/internal/config"
+)
+
+// ServiceAccountKey is the part of a service account JSON key shown to users
+type ServiceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	ClientID    string `json:"client_id"`
+	ProjectID   string `json:"project_id"`
+}
+
+// LoadServiceAccountKey reads and checks a service account JSON key file
+func LoadServiceAccountKey(path string) (*ServiceAccountKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+	var key ServiceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse service account key %s: %w", path, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" {
+		return nil, fmt.Errorf("%s is not a service account key (download one from the Google Cloud console under IAM > Service Accounts > Keys)", path)
+	}
+	return &key, nil
+}
+
+// ServiceAccountToken gets an access token for a service account. With a
+// subject, the service account acts as that user through domain-wide
+// delegation, which must grant it every one of scopes.
+func ServiceAccountToken(ctx context.Context, keyPath, subject string, scopes []string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+	if len(scopes) == 0 {
+		scopes = []string{AnalyticsReadOnlyScope}
+	}
+	jwtConfig, err := google.JWTConfigFromJSON(data, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service account key %s: %w", keyPath, err)
+	}
+	jwtConfig.Subject = subject
+
+	// Exchange through the configured proxy/CA settings
+	baseClient, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwtConfig.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, baseClient)).Token()
+	if err != nil {
+		return nil, serviceAccountError(jwtConfig.Email, subject, err)
+	}
+	return token, nil
+}
+
+// serviceAccountError explains the token errors a misconfigured delegation
+// ends in
+func serviceAccountError(email, subject string, err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if subject == "" || !errors.As(err, &retrieveErr) {
+		return fmt.Errorf("failed to get a token for service account %s: %w", email, err)
+	}
+
+	switch retrieveErrorCode(retrieveErr) {
+	case "unauthorized_client":
+		return fmt.Errorf("service account %s may not impersonate users with these scopes - grant its client ID the scopes under domain-wide delegation in the Google Workspace admin console (%w)", email, err)
+	case "invalid_grant":
+		return fmt.Errorf("service account %s can't act as %s - check that the user exists in the Workspace domain the delegation was set up for (%w)", email, subject, err)
+	}
+	return fmt.Errorf("failed to get a token for service account %s as %s: %w", email, subject, err)
+}
+
+// serviceAccountToken gets a service account preset's access token, from the
+// in-memory cache when still valid
+func (a *AuthClient) serviceAccountToken(ctx context.Context, p *config.Preset) (*oauth2.Token, error) {
+	// Reuses the refresh token bookkeeping; the key path and subject identify the credential
+	cacheKey := "service-account\x00" + p.ServiceAccountKey + "\x00" + p.Impersonate
+
+	a.tokenMutex.Lock()
+	defer a.tokenMutex.Unlock()
+
+	if a.cachedToken != nil && a.lastRefreshToken == cacheKey && time.Now().Before(a.cacheExpiry) {
+		return a.cachedToken, nil
+	}
+
+	token, err := ServiceAccountToken(ctx, p.ServiceAccountKey, p.Impersonate, p.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("preset '%s': %w", p.Name, err)
+	}
+
+	a.cachedToken = token
+	a.cacheExpiry = token.Expiry.Add(-TokenRefreshBuffer)
+	a.lastRefreshToken = cacheKey
+	return token, nil
+}
+
+// UsesServiceAccount reports whether a preset authenticates with a service
+// account key instead of a user's refresh token
+func UsesServiceAccount(p *config.Preset) bool {
+	return p != nil && p.ServiceAccountKey != ""
+}
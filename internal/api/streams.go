@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Data stream types
+const (
+	DataStreamTypeWeb     = "WEB_DATA_STREAM"
+	DataStreamTypeAndroid = "ANDROID_APP_DATA_STREAM"
+	DataStreamTypeIOS     = "IOS_APP_DATA_STREAM"
+)
+
+// DataStream is a website or app sending data to a property. Exactly one of
+// the *StreamData fields is set, matching Type.
+type DataStream struct {
+	Name                 string                `json:"name"` // "properties/328687832/dataStreams/1234567"
+	Type                 string                `json:"type"` // WEB_DATA_STREAM, ANDROID_APP_DATA_STREAM or IOS_APP_DATA_STREAM
+	DisplayName          string                `json:"displayName"`
+	CreateTime           time.Time             `json:"createTime"`
+	UpdateTime           time.Time             `json:"updateTime"`
+	WebStreamData        *WebStreamData        `json:"webStreamData,omitempty"`
+	AndroidAppStreamData *AndroidAppStreamData `json:"androidAppStreamData,omitempty"`
+	IOSAppStreamData     *IOSAppStreamData     `json:"iosAppStreamData,omitempty"`
+}
+
+type WebStreamData struct {
+	MeasurementID string `json:"measurementId"` // "G-XXXXXXXXXX"
+	FirebaseAppID string `json:"firebaseAppId,omitempty"`
+	DefaultURI    string `json:"defaultUri"`
+}
+
+type AndroidAppStreamData struct {
+	FirebaseAppID string `json:"firebaseAppId"`
+	PackageName   string `json:"packageName"`
+}
+
+type IOSAppStreamData struct {
+	FirebaseAppID string `json:"firebaseAppId"`
+	BundleID      string `json:"bundleId"`
+}
+
+// ID returns the numeric stream ID from the resource name
+func (s DataStream) ID() string {
+	if i := strings.LastIndex(s.Name, "/"); i >= 0 {
+		return s.Name[i+1:]
+	}
+	return s.Name
+}
+
+// Target names what the stream measures: the site URI, package name or
+// bundle ID
+func (s DataStream) Target() string {
+	switch {
+	case s.WebStreamData != nil:
+		return s.WebStreamData.DefaultURI
+	case s.AndroidAppStreamData != nil:
+		return s.AndroidAppStreamData.PackageName
+	case s.IOSAppStreamData != nil:
+		return s.IOSAppStreamData.BundleID
+	}
+	return ""
+}
+
+// EnhancedMeasurementSettings are the automatic event toggles of a web stream
+type EnhancedMeasurementSettings struct {
+	Name                    string `json:"name,omitempty"`
+	StreamEnabled           bool   `json:"streamEnabled"` // Master switch; the others only apply when set
+	ScrollsEnabled          bool   `json:"scrollsEnabled"`
+	OutboundClicksEnabled   bool   `json:"outboundClicksEnabled"`
+	SiteSearchEnabled       bool   `json:"siteSearchEnabled"`
+	VideoEngagementEnabled  bool   `json:"videoEngagementEnabled"`
+	FileDownloadsEnabled    bool   `json:"fileDownloadsEnabled"`
+	PageChangesEnabled      bool   `json:"pageChangesEnabled"` // Page views on browser history changes
+	FormInteractionsEnabled bool   `json:"formInteractionsEnabled"`
+	SearchQueryParameter    string `json:"searchQueryParameter,omitempty"` // Comma-separated, e.g. "q,s,search"
+	URIQueryParameter       string `json:"uriQueryParameter,omitempty"`
+}
+
+// DataRedactionSettings controls what a web stream strips from collected URLs
+type DataRedactionSettings struct {
+	Name                           string   `json:"name,omitempty"`
+	EmailRedactionEnabled          bool     `json:"emailRedactionEnabled"`
+	QueryParameterRedactionEnabled bool     `json:"queryParameterRedactionEnabled"`
+	QueryParameterKeys             []string `json:"queryParameterKeys,omitempty"`
+}
+
+// ListDataStreams retrieves a property's web and app data streams
+func (c *AdminClient) ListDataStreams(ctx context.Context, propertyID string, opts ...AdminCallOption) ([]DataStream, error) {
+	var streams []DataStream
+	err := c.listPropertyCollection(ctx, propertyID, "dataStreams", opts, func(items json.RawMessage) error {
+		var page []DataStream
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+		streams = append(streams, page...)
+		return nil
+	})
+	return streams, err
+}
+
+// GetDataStream retrieves one data stream
+func (c *AdminClient) GetDataStream(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*DataStream, error) {
+	var stream DataStream
+	if err := c.getStreamResource(ctx, propertyID, streamID, "", "data stream", &stream, opts); err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// GetEnhancedMeasurementSettings reads a web stream's enhanced measurement
+// settings. App streams have none.
+func (c *AdminClient) GetEnhancedMeasurementSettings(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*EnhancedMeasurementSettings, error) {
+	var settings EnhancedMeasurementSettings
+	if err := c.getStreamResource(ctx, propertyID, streamID, "/enhancedMeasurementSettings", "enhanced measurement settings", &settings, opts); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetDataRedactionSettings reads a web stream's data redaction settings
+func (c *AdminClient) GetDataRedactionSettings(ctx context.Context, propertyID, streamID string, opts ...AdminCallOption) (*DataRedactionSettings, error) {
+	var settings DataRedactionSettings
+	if err := c.getStreamResource(ctx, propertyID, streamID, "/dataRedactionSettings", "data redaction settings", &settings, opts); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// getStreamResource decodes properties/{id}/dataStreams/{stream}{suffix} into
+// result; what names the resource in errors
+func (c *AdminClient) getStreamResource(ctx context.Context, propertyID, streamID, suffix, what string, result interface{}, opts []AdminCallOption) error {
+	resp, err := c.get(ctx, fmt.Sprintf("/properties/%s/dataStreams/%s%s", propertyID, streamID, suffix), opts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("data stream %s of property %s %w", streamID, propertyID, ErrNotAccessible)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("Admin", resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"ga4admin/internal/preset"
+)
+
+// persistedToken is the plaintext of a token cache file
+type persistedToken struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// tokenCacheKey derives the encryption key for a preset's token file from
+// the OAuth client secret and the preset's refresh token. Neither is stored
+// with the token, and a reauth or new client secret changes the key, which
+// retires the old file.
+func (a *AuthClient) tokenCacheKey(refreshToken string) []byte {
+	key := sha256.Sum256([]byte("ga4admin access token\x00" + a.clientSecret + "\x00" + refreshToken))
+	return key[:]
+}
+
+// loadPersistedToken returns the access token an earlier invocation saved for
+// the preset, if it is still valid, and keeps it in the in-memory cache.
+// Unreadable, foreign or expired files are ignored.
+func (a *AuthClient) loadPersistedToken(presetName, refreshToken string) *oauth2.Token {
+	path, err := preset.GetTokenCachePath(presetName)
+	if err != nil {
+		return nil
+	}
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil
+	}
+
+	gcm, err := newTokenCipher(a.tokenCacheKey(refreshToken))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(presetName))
+	if err != nil {
+		return nil
+	}
+
+	var saved persistedToken
+	if err := json.Unmarshal(plaintext, &saved); err != nil || saved.AccessToken == "" {
+		return nil
+	}
+	cacheExpiry := saved.Expiry.Add(-TokenRefreshBuffer)
+	if !time.Now().Before(cacheExpiry) {
+		return nil
+	}
+
+	token := &oauth2.Token{AccessToken: saved.AccessToken, TokenType: saved.TokenType, Expiry: saved.Expiry}
+
+	a.tokenMutex.Lock()
+	defer a.tokenMutex.Unlock()
+	a.cachedToken = token
+	a.cacheExpiry = cacheExpiry
+	a.lastRefreshToken = refreshToken
+	return token
+}
+
+// persistToken saves an access token for later invocations. Tokens without
+// an expiry are not saved. The file is replaced atomically, so concurrent
+// commands never read a partial one.
+func (a *AuthClient) persistToken(presetName, refreshToken string, token *oauth2.Token) error {
+	if token.Expiry.IsZero() {
+		return nil
+	}
+	path, err := preset.GetTokenCachePath(presetName)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(persistedToken{AccessToken: token.AccessToken, TokenType: token.TokenType, Expiry: token.Expiry})
+	if err != nil {
+		return err
+	}
+	gcm, err := newTokenCipher(a.tokenCacheKey(refreshToken))
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// The preset name is authenticated, so files can't be swapped between presets
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(presetName))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+presetName+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save access token: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(base64.StdEncoding.EncodeToString(sealed)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save access token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save access token: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// forgetPersistedToken removes a preset's saved access token
+func forgetPersistedToken(presetName string) {
+	if path, err := preset.GetTokenCachePath(presetName); err == nil {
+		os.Remove(path)
+	}
+}
+
+func newTokenCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
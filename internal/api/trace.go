@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Bodies larger than this are truncated in the trace
+const maxTraceBodyBytes = 64 * 1024
+
+var (
+	traceMutex   sync.Mutex
+	traceFile    *os.File
+	traceCounter uint64
+
+	// JSON and form-encoded credentials that must never reach a trace file
+	secretJSONPattern = regexp.MustCompile(`("(?:access_token|refresh_token|id_token|client_secret|private_key)"\s*:\s*)"[^"]*"`)
+	secretFormPattern = regexp.MustCompile(`((?:^|&)(?:access_token|refresh_token|client_secret|code|assertion)=)[^&]*`)
+)
+
+// EnableTrace appends sanitized request/response logs for every API call to path
+func EnableTrace(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	if traceFile != nil {
+		traceFile.Close()
+	}
+	traceFile = file
+	return nil
+}
+
+func tracingEnabled() bool {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	return traceFile != nil
+}
+
+// writeTrace writes one complete entry; entries are unbuffered so nothing is
+// lost when a command exits early
+func writeTrace(entry string) {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	if traceFile != nil {
+		traceFile.WriteString(entry)
+	}
+}
+
+func nextTraceID() string {
+	return fmt.Sprintf("ga4admin-%d-%d", os.Getpid(), atomic.AddUint64(&traceCounter, 1))
+}
+
+// tracingTransport logs sanitized HTTP exchanges
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	traceID := nextTraceID()
+	start := time.Now()
+
+	var requestBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(body, maxTraceBodyBytes))
+			body.Close()
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s\n", traceID, start.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, sanitizeURL(req.URL.String()))
+	writeTraceHeaders(&b, req.Header)
+	if len(requestBody) > 0 {
+		fmt.Fprintf(&b, "%s\n", sanitizeBody(string(requestBody)))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+	if err != nil {
+		fmt.Fprintf(&b, "<-- error after %s: %v\n\n", duration, err)
+		writeTrace(b.String())
+		return nil, err
+	}
+
+	// Read the body so it can be logged, then hand the caller an identical copy
+	responseBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	fmt.Fprintf(&b, "<-- %s (%s)\n", resp.Status, duration)
+	writeTraceHeaders(&b, resp.Header)
+	if readErr != nil {
+		fmt.Fprintf(&b, "[body read error: %v]\n", readErr)
+	}
+	if len(responseBody) > 0 {
+		fmt.Fprintf(&b, "%s\n", sanitizeBody(truncateTraceBody(string(responseBody))))
+	}
+	b.WriteString("\n")
+	writeTrace(b.String())
+
+	return resp, readErr
+}
+
+// traceUnaryInterceptor logs sanitized gRPC calls in the same format as HTTP
+func traceUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	traceID := nextTraceID()
+	start := time.Now()
+
+	var header metadata.MD
+	opts = append(opts, grpc.Header(&header))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s\n", traceID, start.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "--> gRPC %s\n", method)
+	if msg, ok := req.(proto.Message); ok {
+		fmt.Fprintf(&b, "%s\n", sanitizeBody(protojson.Format(msg)))
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	duration := time.Since(start)
+
+	if err != nil {
+		fmt.Fprintf(&b, "<-- error after %s: %v\n", duration, err)
+	} else {
+		fmt.Fprintf(&b, "<-- OK (%s)\n", duration)
+	}
+	for key, values := range header {
+		fmt.Fprintf(&b, "%s: %s\n", key, strings.Join(values, ", "))
+	}
+	if msg, ok := reply.(proto.Message); ok && err == nil {
+		fmt.Fprintf(&b, "%s\n", sanitizeBody(truncateTraceBody(protojson.Format(msg))))
+	}
+	b.WriteString("\n")
+	writeTrace(b.String())
+
+	return err
+}
+
+func writeTraceHeaders(b *strings.Builder, header http.Header) {
+	for key, values := range header {
+		value := strings.Join(values, ", ")
+		switch strings.ToLower(key) {
+		case "authorization", "cookie", "set-cookie", "proxy-authorization":
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(b, "%s: %s\n", key, value)
+	}
+}
+
+func sanitizeURL(rawURL string) string {
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		return rawURL[:i+1] + secretFormPattern.ReplaceAllString(rawURL[i+1:], "${1}[REDACTED]")
+	}
+	return rawURL
+}
+
+func sanitizeBody(body string) string {
+	body = secretJSONPattern.ReplaceAllString(body, `${1}"[REDACTED]"`)
+	return secretFormPattern.ReplaceAllString(body, "${1}[REDACTED]")
+}
+
+func truncateTraceBody(body string) string {
+	if len(body) > maxTraceBodyBytes {
+		return body[:maxTraceBodyBytes] + fmt.Sprintf("\n[truncated %d bytes]", len(body)-maxTraceBodyBytes)
+	}
+	return body
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MetadataWarmResult reports the outcome of warming one property's metadata
+type MetadataWarmResult struct {
+	PropertyID string
+	FromCache  bool // Metadata was already cached and still fresh
+	Dimensions int
+	Metrics    int
+	Duration   time.Duration
+	Err        error
+}
+
+// WarmMetadata fetches and caches metadata for many properties with at most
+// `concurrency` requests in flight. progress is called once per property as
+// each one finishes; calls are serialized so callers don't need locking.
+func (c *DataClient) WarmMetadata(ctx context.Context, propertyIDs []string, concurrency int, force bool, progress func(MetadataWarmResult)) []MetadataWarmResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]MetadataWarmResult, len(propertyIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+
+	for i, propertyID := range propertyIDs {
+		wg.Add(1)
+		go func(i int, propertyID string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = MetadataWarmResult{PropertyID: propertyID, Err: ctx.Err()}
+				return
+			}
+
+			results[i] = c.warmProperty(ctx, propertyID, force)
+
+			if progress != nil {
+				progressMu.Lock()
+				progress(results[i])
+				progressMu.Unlock()
+			}
+		}(i, propertyID)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (c *DataClient) warmProperty(ctx context.Context, propertyID string, force bool) MetadataWarmResult {
+	start := time.Now()
+	result := MetadataWarmResult{PropertyID: propertyID}
+
+	// Report cache hits separately so the summary shows what was actually fetched
+	if !force && c.cacheClient != nil {
+		var cached MetadataResponse
+		if found, err := c.cacheClient.GetCachedMetadata(ctx, propertyID, "metadata", &cached); err == nil && found {
+			result.FromCache = true
+			result.Dimensions = len(cached.Dimensions)
+			result.Metrics = len(cached.Metrics)
+			result.Duration = time.Since(start)
+			return result
+		}
+	}
+
+	metadata, err := c.fetchMetadata(ctx, propertyID)
+	if err != nil {
+		result.Err = err
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	result.Dimensions = len(metadata.Dimensions)
+	result.Metrics = len(metadata.Metrics)
+	result.Duration = time.Since(start)
+	return result
+}
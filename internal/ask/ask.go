@@ -0,0 +1,470 @@
+// Package ask turns plain-language questions such as "top landing pages by
+// sessions last month" into query configurations. Questions are matched
+// against the field catalog and a property's custom definitions locally; an
+// OpenAI-compatible assistant endpoint may interpret them instead.
+package ask
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/catalog"
+	"ga4admin/internal/query"
+)
+
+// APIKeyEnv names the environment variable holding the assistant's API key
+const APIKeyEnv = "GA4ADMIN_ASSISTANT_API_KEY"
+
+// DefaultMetric is used when a question names no metric
+const DefaultMetric = "sessions"
+
+// ErrNotUnderstood is returned when a question names no field at all
+var ErrNotUnderstood = errors.New("no dimensions or metrics recognized in the question")
+
+// Interpretation is a question mapped to a query
+type Interpretation struct {
+	Config *query.QueryConfig
+	Notes  []string // Assumptions made along the way, e.g. a default date range
+	Source string   // "catalog" or the assistant model that produced it
+}
+
+// Fields lists what a question may refer to: the bundled catalog plus the
+// property's custom dimensions and metrics, when metadata is given
+func Fields(cat *catalog.Catalog, metadata *api.MetadataResponse) []catalog.Field {
+	fields := cat.Fields()
+	if metadata == nil {
+		return fields
+	}
+	for _, dimension := range metadata.Dimensions {
+		if dimension.CustomDefinition {
+			fields = append(fields, catalog.Field{
+				Kind:        catalog.KindDimension,
+				APIName:     dimension.APIName,
+				UIName:      dimension.UIName,
+				Description: dimension.Description,
+				Category:    dimension.Category,
+			})
+		}
+	}
+	for _, metric := range metadata.Metrics {
+		if metric.CustomDefinition {
+			fields = append(fields, catalog.Field{
+				Kind:        catalog.KindMetric,
+				APIName:     metric.APIName,
+				UIName:      metric.UIName,
+				Description: metric.Description,
+				Category:    metric.Category,
+				Type:        metric.Type,
+			})
+		}
+	}
+	return fields
+}
+
+// synonyms are everyday words for fields whose UI names differ. Entries
+// whose field isn't offered are skipped.
+var synonyms = map[string]string{
+	"users":             "totalUsers",
+	"visitors":          "totalUsers",
+	"people":            "totalUsers",
+	"new visitors":      "newUsers",
+	"visits":            "sessions",
+	"pageviews":         "screenPageViews",
+	"page views":        "screenPageViews",
+	"views":             "screenPageViews",
+	"conversions":       "keyEvents",
+	"goals":             "keyEvents",
+	"revenue":           "totalRevenue",
+	"sales":             "totalRevenue",
+	"purchases":         "ecommercePurchases",
+	"orders":            "transactions",
+	"bounce rate":       "bounceRate",
+	"engagement rate":   "engagementRate",
+	"events":            "eventCount",
+	"landing pages":     "landingPage",
+	"landing page":      "landingPage",
+	"entry pages":       "landingPage",
+	"pages":             "pagePath",
+	"page":              "pagePath",
+	"urls":              "pagePath",
+	"page titles":       "pageTitle",
+	"titles":            "pageTitle",
+	"source/medium":     "sessionSourceMedium",
+	"source medium":     "sessionSourceMedium",
+	"sources":           "sessionSource",
+	"source":            "sessionSource",
+	"traffic sources":   "sessionSource",
+	"referrers":         "sessionSource",
+	"mediums":           "sessionMedium",
+	"medium":            "sessionMedium",
+	"campaigns":         "sessionCampaignName",
+	"campaign":          "sessionCampaignName",
+	"channels":          "sessionDefaultChannelGroup",
+	"channel":           "sessionDefaultChannelGroup",
+	"channel groups":    "sessionDefaultChannelGroup",
+	"devices":           "deviceCategory",
+	"device":            "deviceCategory",
+	"device types":      "deviceCategory",
+	"browsers":          "browser",
+	"countries":         "country",
+	"cities":            "city",
+	"regions":           "region",
+	"languages":         "language",
+	"operating systems": "operatingSystem",
+	"os":                "operatingSystem",
+	"platforms":         "platform",
+	"event names":       "eventName",
+	"products":          "itemName",
+	"items":             "itemName",
+	"search terms":      "searchTerm",
+	"keywords":          "sessionManualTerm",
+	"hostnames":         "hostName",
+	"domains":           "hostName",
+	"screens":           "unifiedScreenName",
+	"new vs returning":  "newVsReturning",
+	"new or returning":  "newVsReturning",
+	"age":               "userAgeBracket",
+	"age groups":        "userAgeBracket",
+	"genders":           "userGender",
+	"days of week":      "dayOfWeekName",
+	"day of week":       "dayOfWeekName",
+	"weekdays":          "dayOfWeekName",
+	"hours of day":      "hour",
+	"hour of day":       "hour",
+}
+
+// granularity words add a time dimension
+var granularity = map[string]string{
+	"daily":      "date",
+	"per day":    "date",
+	"by day":     "date",
+	"each day":   "date",
+	"day by day": "date",
+	"over time":  "date",
+	"trend":      "date",
+	"weekly":     "yearWeek",
+	"per week":   "yearWeek",
+	"by week":    "yearWeek",
+	"each week":  "yearWeek",
+	"monthly":    "yearMonth",
+	"per month":  "yearMonth",
+	"by month":   "yearMonth",
+	"each month": "yearMonth",
+	"hourly":     "dateHour",
+	"per hour":   "dateHour",
+	"by hour":    "dateHour",
+}
+
+// stopwords carry no meaning for the query and aren't reported as ignored
+var stopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "by": true, "for": true, "of": true,
+	"in": true, "on": true, "per": true, "with": true, "and": true, "to": true,
+	"from": true, "me": true, "show": true, "give": true, "list": true,
+	"what": true, "which": true, "were": true, "are": true, "is": true,
+	"was": true, "how": true, "many": true, "much": true, "my": true,
+	"our": true, "each": true, "all": true, "get": true, "find": true,
+	"data": true, "report": true, "breakdown": true, "split": true,
+	"number": true, "count": true, "total": true, "most": true, "best": true,
+	"highest": true, "least": true, "lowest": true, "fewest": true,
+	"worst": true, "top": true, "bottom": true, "did": true, "do": true,
+	"we": true, "have": true, "had": true, "got": true, "please": true,
+	"broken": true, "down": true, "over": true, "during": true, "at": true,
+	"as": true, "vs": true, "versus": true, "their": true, "its": true,
+	"there": true, "that": true, "this": true, "than": true, "where": true,
+	"who": true, "when": true, "into": true, "compare": true,
+}
+
+var (
+	propertyPattern = regexp.MustCompile(`(?i)\b(?:for |in |on |of )?(?:ga4 )?property (properties/)?([a-z0-9_.-]+)`)
+	topPattern      = regexp.MustCompile(`\b(top|bottom|worst) (\d+)\b`)
+	rankPattern     = regexp.MustCompile(`\b(top|most|highest|best|bottom|least|lowest|fewest|worst)\b`)
+	datePattern     = `(\d{4}-\d{2}-\d{2})`
+	betweenPattern  = regexp.MustCompile(`\b(?:between|from) ` + datePattern + ` (?:and|to|until|through) ` + datePattern)
+	sincePattern    = regexp.MustCompile(`\b(?:since|after|from) ` + datePattern)
+	onPattern       = regexp.MustCompile(`\b(?:on )?` + datePattern)
+	lastNPattern    = regexp.MustCompile(`\b(?:last|past|previous) (\d+) (days?|weeks?|months?)\b`)
+	punctuation     = strings.NewReplacer(",", " ", "?", " ", "!", " ", ";", " ", "(", " ", ")", " ", "\"", " ", "'s", "", "'", " ", " / ", "/")
+)
+
+// normalize lowercases text and reduces punctuation and spacing, keeping a
+// space at each end so phrases can be matched as whole words
+func normalize(text string) string {
+	text = " " + strings.Join(strings.Fields(punctuation.Replace(strings.ToLower(text))), " ") + " "
+	return strings.ReplaceAll(text, ". ", " ")
+}
+
+// PropertyIn returns the property ID or alias a question names, as in "for
+// property 123456789", or an empty string. Aliases keep their case.
+func PropertyIn(question string) string {
+	text := strings.Join(strings.Fields(punctuation.Replace(question)), " ")
+	if match := propertyPattern.FindStringSubmatch(text); match != nil {
+		return match[2]
+	}
+	return ""
+}
+
+// Interpret maps a question to a query using the given fields, resolving
+// relative dates against now. It fails with ErrNotUnderstood when nothing in
+// the question matches a field.
+func Interpret(question string, fields []catalog.Field, now time.Time) (*Interpretation, error) {
+	text := normalize(question)
+	interpretation := &Interpretation{
+		Config: &query.QueryConfig{},
+		Source: "catalog",
+	}
+	config := interpretation.Config
+
+	if match := propertyPattern.FindStringIndex(text); match != nil {
+		text = cut(text, match[0], match[1])
+	}
+
+	// Ranking comes before dates, so "last 5 pages" isn't read as a date range
+	descending := true
+	ranked := false
+	if match := topPattern.FindStringSubmatchIndex(text); match != nil && !isDateUnit(text[match[1]:]) {
+		word := text[match[2]:match[3]]
+		limit, _ := strconv.ParseInt(text[match[4]:match[5]], 10, 64)
+		config.Limit = limit
+		descending = word == "top"
+		ranked = true
+		text = cut(text, match[0], match[1])
+	} else if match := rankPattern.FindStringSubmatch(text); match != nil {
+		switch match[1] {
+		case "bottom", "least", "lowest", "fewest", "worst":
+			descending = false
+		}
+		ranked = true
+		if match[1] == "top" || match[1] == "bottom" {
+			config.Limit = 10
+		}
+	}
+
+	text = interpretDates(interpretation, text, now)
+
+	var timeDimension string
+	for _, phrase := range longestFirst(granularity) {
+		if index := strings.Index(text, " "+phrase+" "); index >= 0 {
+			timeDimension = granularity[phrase]
+			text = cut(text, index, index+len(phrase)+1)
+			break
+		}
+	}
+
+	dimensions, metrics, ignored := matchFields(text, fields)
+	if timeDimension != "" && !contains(dimensions, timeDimension) {
+		dimensions = append([]string{timeDimension}, dimensions...)
+	}
+	if len(dimensions) == 0 && len(metrics) == 0 {
+		return nil, ErrNotUnderstood
+	}
+	if len(metrics) == 0 {
+		metrics = []string{DefaultMetric}
+		interpretation.Notes = append(interpretation.Notes, fmt.Sprintf("No metric named, so %s is used", DefaultMetric))
+	}
+	config.Dimensions = dimensions
+	config.Metrics = metrics
+
+	switch {
+	case timeDimension != "" && !ranked:
+		config.OrderBy = []query.OrderByConfig{{FieldName: timeDimension, FieldType: "dimension"}}
+	case len(dimensions) > 0:
+		config.OrderBy = []query.OrderByConfig{{FieldName: metrics[0], FieldType: "metric", Descending: descending}}
+	}
+
+	if len(ignored) > 0 {
+		interpretation.Notes = append(interpretation.Notes, fmt.Sprintf("Ignored: %s", strings.Join(ignored, ", ")))
+	}
+	return interpretation, nil
+}
+
+// interpretDates sets the date range from the first date phrase in text and
+// returns text without it. Without one, the last 30 complete days are used.
+func interpretDates(interpretation *Interpretation, text string, now time.Time) string {
+	config := interpretation.Config
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	setRange := func(start, end time.Time) {
+		config.StartDate = start.Format("2006-01-02")
+		config.EndDate = end.Format("2006-01-02")
+	}
+
+	if match := betweenPattern.FindStringSubmatchIndex(text); match != nil {
+		config.StartDate, config.EndDate = text[match[2]:match[3]], text[match[4]:match[5]]
+		return cut(text, match[0], match[1])
+	}
+	if match := sincePattern.FindStringSubmatchIndex(text); match != nil {
+		config.StartDate, config.EndDate = text[match[2]:match[3]], "today"
+		return cut(text, match[0], match[1])
+	}
+	if match := lastNPattern.FindStringSubmatchIndex(text); match != nil {
+		n, _ := strconv.Atoi(text[match[2]:match[3]])
+		var start time.Time
+		switch unit := text[match[4]:match[5]]; {
+		case strings.HasPrefix(unit, "day"):
+			start = today.AddDate(0, 0, -n)
+		case strings.HasPrefix(unit, "week"):
+			start = today.AddDate(0, 0, -7*n)
+		default:
+			start = today.AddDate(0, -n, 0)
+		}
+		setRange(start, yesterday)
+		return cut(text, match[0], match[1])
+	}
+
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Monday
+	yearStart := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, today.Location())
+	quarterStart := time.Date(today.Year(), time.Month((int(today.Month())-1)/3*3+1), 1, 0, 0, 0, 0, today.Location())
+
+	phrases := []struct {
+		words      []string
+		start, end time.Time
+	}{
+		{[]string{"last month", "previous month", "past month"}, monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1)},
+		{[]string{"this month", "month to date", "mtd"}, monthStart, today},
+		{[]string{"last week", "previous week", "past week"}, weekStart.AddDate(0, 0, -7), weekStart.AddDate(0, 0, -1)},
+		{[]string{"this week", "week to date"}, weekStart, today},
+		{[]string{"last quarter", "previous quarter"}, quarterStart.AddDate(0, -3, 0), quarterStart.AddDate(0, 0, -1)},
+		{[]string{"this quarter", "quarter to date"}, quarterStart, today},
+		{[]string{"last year", "previous year"}, yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1)},
+		{[]string{"this year", "year to date", "ytd"}, yearStart, today},
+		{[]string{"yesterday"}, yesterday, yesterday},
+		{[]string{"today"}, today, today},
+	}
+	for _, phrase := range phrases {
+		for _, words := range phrase.words {
+			if index := strings.Index(text, " "+words+" "); index >= 0 {
+				setRange(phrase.start, phrase.end)
+				return cut(text, index, index+len(words)+1)
+			}
+		}
+	}
+
+	if match := onPattern.FindStringSubmatchIndex(text); match != nil {
+		config.StartDate = text[match[2]:match[3]]
+		config.EndDate = config.StartDate
+		return cut(text, match[0], match[1])
+	}
+
+	setRange(today.AddDate(0, 0, -30), yesterday)
+	interpretation.Notes = append(interpretation.Notes, "No date range named, so the last 30 days are used")
+	return text
+}
+
+// matchFields finds fields in text, longest phrase first, and returns the
+// words that matched nothing
+func matchFields(text string, fields []catalog.Field) (dimensions, metrics, ignored []string) {
+	phrases := make(map[string]catalog.Field)
+	byAPIName := make(map[string]catalog.Field)
+	for _, field := range fields {
+		byAPIName[strings.ToLower(field.APIName)] = field
+	}
+	for _, field := range fields {
+		for _, phrase := range fieldPhrases(field) {
+			if _, taken := phrases[phrase]; !taken {
+				phrases[phrase] = field
+			}
+		}
+	}
+	// Synonyms take precedence over UI names, which are sometimes ambiguous
+	for phrase, apiName := range synonyms {
+		if field, ok := byAPIName[strings.ToLower(apiName)]; ok {
+			phrases[phrase] = field
+		}
+	}
+
+	maxWords := 0
+	for phrase := range phrases {
+		if n := len(strings.Fields(phrase)); n > maxWords {
+			maxWords = n
+		}
+	}
+
+	words := strings.Fields(text)
+	for i := 0; i < len(words); {
+		matched := false
+		for n := min(maxWords, len(words)-i); n > 0; n-- {
+			field, ok := phrases[strings.Join(words[i:i+n], " ")]
+			if !ok {
+				continue
+			}
+			if field.Kind == catalog.KindMetric {
+				if !contains(metrics, field.APIName) {
+					metrics = append(metrics, field.APIName)
+				}
+			} else if !contains(dimensions, field.APIName) {
+				dimensions = append(dimensions, field.APIName)
+			}
+			i += n
+			matched = true
+			break
+		}
+		if !matched {
+			if word := strings.Trim(words[i], "."); word != "" && !stopwords[word] && !contains(ignored, word) {
+				ignored = append(ignored, word)
+			}
+			i++
+		}
+	}
+	return dimensions, metrics, ignored
+}
+
+// fieldPhrases are the lowercase ways a question may name a field: its API
+// name and UI name, singular and plural
+func fieldPhrases(field catalog.Field) []string {
+	phrases := []string{strings.ToLower(field.APIName)}
+	if field.UIName != "" {
+		name := strings.Join(strings.Fields(punctuation.Replace(strings.ToLower(field.UIName))), " ")
+		phrases = append(phrases, name)
+		if !strings.HasSuffix(name, "s") {
+			phrases = append(phrases, name+"s")
+		}
+	}
+	return phrases
+}
+
+// longestFirst returns the keys of phrases, longer ones first
+func longestFirst(phrases map[string]string) []string {
+	keys := make([]string, 0, len(phrases))
+	for key := range phrases {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// isDateUnit reports whether rest starts with a unit of time, as in the
+// "days" of "last 7 days"
+func isDateUnit(rest string) bool {
+	for _, unit := range []string{" day", " week", " month"} {
+		if strings.HasPrefix(rest, unit) {
+			return true
+		}
+	}
+	return false
+}
+
+// cut removes text[start:end], keeping the words around it apart
+func cut(text string, start, end int) string {
+	return text[:start] + " " + text[end:]
+}
+
+func contains(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
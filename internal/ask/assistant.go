@@ -0,0 +1,221 @@
+package ask
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ga4admin/internal/catalog"
+	"ga4admin/internal/query"
+)
+
+// maxReplyBytes bounds the assistant's response body
+const maxReplyBytes = 1 << 20
+
+// Assistant interprets questions with an OpenAI-compatible chat completions
+// endpoint
+type Assistant struct {
+	Endpoint string
+	Model    string
+	APIKey   string
+	Client   *http.Client
+}
+
+// NewAssistant returns an assistant for endpoint and model, reading the API
+// key from APIKeyEnv
+func NewAssistant(client *http.Client, endpoint, model string) *Assistant {
+	return &Assistant{
+		Endpoint: endpoint,
+		Model:    model,
+		APIKey:   os.Getenv(APIKeyEnv),
+		Client:   client,
+	}
+}
+
+// reply is the JSON the assistant is asked to answer with
+type reply struct {
+	Dimensions []string `json:"dimensions"`
+	Metrics    []string `json:"metrics"`
+	StartDate  string   `json:"start_date"`
+	EndDate    string   `json:"end_date"`
+	Limit      int64    `json:"limit"`
+	OrderBy    []struct {
+		FieldName  string `json:"field_name"`
+		Descending bool   `json:"descending"`
+	} `json:"order_by"`
+	Notes []string `json:"notes"`
+}
+
+// Interpret asks the assistant to map question to a query. Every field it
+// suggests must be one of fields; anything else is an error, so callers can
+// fall back to the local Interpret.
+func (a *Assistant) Interpret(ctx context.Context, question string, fields []catalog.Field, now time.Time) (*Interpretation, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":       a.Model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "system", "content": systemPrompt(fields, now)},
+			{"role": "user", "content": question},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid assistant endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.APIKey)
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("assistant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReplyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assistant response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("assistant returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("invalid assistant response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("assistant returned no answer")
+	}
+
+	// Models sometimes wrap the object in prose or a code fence
+	content := completion.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("assistant answer has no JSON object")
+	}
+	var answer reply
+	if err := json.Unmarshal([]byte(content[start:end+1]), &answer); err != nil {
+		return nil, fmt.Errorf("invalid assistant answer: %w", err)
+	}
+	return a.toInterpretation(&answer, fields)
+}
+
+// toInterpretation checks the assistant's answer against fields, restoring
+// the canonical spelling of each name
+func (a *Assistant) toInterpretation(answer *reply, fields []catalog.Field) (*Interpretation, error) {
+	byName := make(map[string]catalog.Field)
+	for _, field := range fields {
+		byName[strings.ToLower(field.APIName)] = field
+		for _, deprecated := range field.DeprecatedAPINames {
+			if _, taken := byName[strings.ToLower(deprecated)]; !taken {
+				byName[strings.ToLower(deprecated)] = field
+			}
+		}
+	}
+	resolve := func(name, kind string) (string, error) {
+		field, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return "", fmt.Errorf("assistant suggested unknown field '%s'", name)
+		}
+		if kind != "" && field.Kind != kind {
+			return "", fmt.Errorf("assistant suggested %s '%s' as a %s", field.Kind, field.APIName, kind)
+		}
+		return field.APIName, nil
+	}
+
+	config := &query.QueryConfig{
+		StartDate: answer.StartDate,
+		EndDate:   answer.EndDate,
+		Limit:     answer.Limit,
+	}
+	for _, name := range answer.Dimensions {
+		apiName, err := resolve(name, catalog.KindDimension)
+		if err != nil {
+			return nil, err
+		}
+		config.Dimensions = append(config.Dimensions, apiName)
+	}
+	for _, name := range answer.Metrics {
+		apiName, err := resolve(name, catalog.KindMetric)
+		if err != nil {
+			return nil, err
+		}
+		config.Metrics = append(config.Metrics, apiName)
+	}
+	if len(config.Dimensions) == 0 && len(config.Metrics) == 0 {
+		return nil, ErrNotUnderstood
+	}
+	for _, orderBy := range answer.OrderBy {
+		apiName, err := resolve(orderBy.FieldName, "")
+		if err != nil {
+			return nil, err
+		}
+		fieldType := "metric"
+		if contains(config.Dimensions, apiName) {
+			fieldType = "dimension"
+		} else if !contains(config.Metrics, apiName) {
+			return nil, fmt.Errorf("assistant ordered by '%s', which isn't in the query", apiName)
+		}
+		config.OrderBy = append(config.OrderBy, query.OrderByConfig{
+			FieldName:  apiName,
+			FieldType:  fieldType,
+			Descending: orderBy.Descending,
+		})
+	}
+	if config.StartDate == "" || config.EndDate == "" {
+		return nil, fmt.Errorf("assistant answer has no date range")
+	}
+	if config.Limit < 0 {
+		config.Limit = 0
+	}
+
+	return &Interpretation{
+		Config: config,
+		Notes:  answer.Notes,
+		Source: a.Model,
+	}, nil
+}
+
+// systemPrompt describes the answer format and lists every field the query
+// may use
+func systemPrompt(fields []catalog.Field, now time.Time) string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, `You translate questions about Google Analytics 4 data into Data API report requests.
+Today is %s (%s).
+Answer with a single JSON object and nothing else:
+{"dimensions": ["<dimension API name>"],
+ "metrics": ["<metric API name>"],
+ "start_date": "YYYY-MM-DD", "end_date": "YYYY-MM-DD",
+ "limit": <rows, 0 for all>,
+ "order_by": [{"field_name": "<a dimension or metric of the query>", "descending": true}],
+ "notes": ["<assumptions you made>"]}
+Only use the API names listed below. When no date range is named, use the last 30 days ending yesterday.
+`, now.Format("2006-01-02"), now.Weekday())
+
+	for _, kind := range []string{catalog.KindDimension, catalog.KindMetric} {
+		fmt.Fprintf(&prompt, "\n%ss:\n", strings.ToUpper(kind[:1])+kind[1:])
+		for _, field := range fields {
+			if field.Kind == kind {
+				fmt.Fprintf(&prompt, "%s (%s)\n", field.APIName, field.UIName)
+			}
+		}
+	}
+	return prompt.String()
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// Events GA4 collects automatically; they are high volume by nature and are
+// never useful conversion candidates
+var automaticEvents = map[string]bool{
+	"page_view":           true,
+	"session_start":       true,
+	"first_visit":         true,
+	"user_engagement":     true,
+	"scroll":              true,
+	"click":               true,
+	"screen_view":         true,
+	"first_open":          true,
+	"app_remove":          true,
+	"os_update":           true,
+	"app_update":          true,
+	"view_search_results": true,
+	"video_start":         true,
+	"video_progress":      true,
+	"form_start":          true,
+}
+
+// ConversionEvent is one event's key-event status and volume
+type ConversionEvent struct {
+	EventName  string  `json:"event_name"`
+	EventCount int64   `json:"event_count"`
+	Share      float64 `json:"share"` // Percent of all events in the range
+	IsKeyEvent bool    `json:"is_key_event"`
+}
+
+// ConversionCoverage compares configured key events with actual event volumes
+type ConversionCoverage struct {
+	PropertyID         string            `json:"property_id"`
+	Days               int               `json:"days"`
+	TotalEventCount    int64             `json:"total_event_count"`
+	KeyEvents          []ConversionEvent `json:"key_events"`           // All configured key events with their volume
+	ZeroVolume         []ConversionEvent `json:"zero_volume"`          // Key events that never fired
+	UnmarkedHighVolume []ConversionEvent `json:"unmarked_high_volume"` // Busy events that look like conversions but aren't key events
+	AnalyzedAt         time.Time         `json:"analyzed_at"`
+}
+
+// AnalyzeConversionCoverage joins the property's key events (Admin API) with
+// event volumes over the last `days` days (Data API). Events that are not key
+// events are reported when they account for at least minShare percent of all
+// events, or look like a conversion by name.
+func AnalyzeConversionCoverage(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, propertyID string, days int, minShare float64) (*ConversionCoverage, error) {
+	if days <= 0 || days > 365 {
+		return nil, fmt.Errorf("days must be between 1 and 365")
+	}
+
+	keyEvents, err := adminClient.ListKeyEvents(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key events: %w", err)
+	}
+
+	volumes, total, err := eventVolumes(ctx, dataClient, propertyID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &ConversionCoverage{
+		PropertyID:      propertyID,
+		Days:            days,
+		TotalEventCount: total,
+		AnalyzedAt:      time.Now(),
+	}
+
+	isKeyEvent := make(map[string]bool, len(keyEvents))
+	for _, keyEvent := range keyEvents {
+		isKeyEvent[keyEvent.EventName] = true
+
+		event := ConversionEvent{
+			EventName:  keyEvent.EventName,
+			EventCount: volumes[keyEvent.EventName],
+			Share:      share(volumes[keyEvent.EventName], total),
+			IsKeyEvent: true,
+		}
+		coverage.KeyEvents = append(coverage.KeyEvents, event)
+		if event.EventCount == 0 {
+			coverage.ZeroVolume = append(coverage.ZeroVolume, event)
+		}
+	}
+
+	for eventName, count := range volumes {
+		if isKeyEvent[eventName] || automaticEvents[eventName] {
+			continue
+		}
+		eventShare := share(count, total)
+		if eventShare >= minShare || IsLikelyConversionEvent(eventName) {
+			coverage.UnmarkedHighVolume = append(coverage.UnmarkedHighVolume, ConversionEvent{
+				EventName:  eventName,
+				EventCount: count,
+				Share:      eventShare,
+			})
+		}
+	}
+
+	sortByCount(coverage.KeyEvents)
+	sortByCount(coverage.UnmarkedHighVolume)
+
+	return coverage, nil
+}
+
+// IsLikelyConversionEvent guesses from the event name whether it represents a conversion
+func IsLikelyConversionEvent(eventName string) bool {
+	conversionKeywords := []string{
+		"purchase", "conversion", "complete", "submit", "signup", "register",
+		"subscribe", "download", "checkout", "payment", "order", "buy",
+		"generate_lead", "sign_up", "login", "add_payment_info",
+	}
+
+	eventLower := strings.ToLower(eventName)
+	for _, keyword := range conversionKeywords {
+		if strings.Contains(eventLower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventVolumes returns the count of every event in the range, not only the top ones
+func eventVolumes(ctx context.Context, dataClient api.DataService, propertyID string, days int) (map[string]int64, int64, error) {
+	request := &api.RunReportRequest{
+		Property:   propertyID,
+		Dimensions: []api.Dimension{{Name: "eventName"}},
+		Metrics:    []api.Metric{{Name: "eventCount"}},
+		DateRanges: []api.DateRange{
+			{
+				StartDate: fmt.Sprintf("%ddaysAgo", days),
+				EndDate:   "yesterday",
+			},
+		},
+		Limit: 10000,
+	}
+
+	response, err := dataClient.RunReport(ctx, request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to run event volume report: %w", err)
+	}
+
+	volumes := make(map[string]int64, len(response.Rows))
+	var total int64
+	for _, row := range response.Rows {
+		if len(row.DimensionValues) < 1 || len(row.MetricValues) < 1 {
+			continue
+		}
+		count, _ := strconv.ParseInt(row.MetricValues[0].Value, 10, 64)
+		volumes[row.DimensionValues[0].Value] = count
+		total += count
+	}
+
+	return volumes, total, nil
+}
+
+func share(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+func sortByCount(events []ConversionEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].EventCount != events[j].EventCount {
+			return events[i].EventCount > events[j].EventCount
+		}
+		return events[i].EventName < events[j].EventName
+	})
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+)
+
+// LinkType is a kind of product link a property can have
+type LinkType string
+
+const (
+	LinkBigQuery  LinkType = "bigquery"
+	LinkGoogleAds LinkType = "google-ads"
+	LinkFirebase  LinkType = "firebase"
+)
+
+// LinkTypes are the link types the Admin API lists, in display order
+var LinkTypes = []LinkType{LinkBigQuery, LinkGoogleAds, LinkFirebase}
+
+// DefaultExpectedLinks are the links most web properties are expected to
+// have. Firebase links only apply to app properties, so they must be asked for.
+var DefaultExpectedLinks = []LinkType{LinkBigQuery, LinkGoogleAds}
+
+var linkTypeLabels = map[LinkType]string{
+	LinkBigQuery:  "BigQuery",
+	LinkGoogleAds: "Google Ads",
+	LinkFirebase:  "Firebase",
+}
+
+// Label is the link type's product name, e.g. "Google Ads"
+func (t LinkType) Label() string {
+	if label, ok := linkTypeLabels[t]; ok {
+		return label
+	}
+	return string(t)
+}
+
+// ParseLinkTypes validates link type names such as "bigquery" or
+// "google-ads". Empty input means every type.
+func ParseLinkTypes(values []string) ([]LinkType, error) {
+	if len(values) == 0 {
+		return LinkTypes, nil
+	}
+
+	var types []LinkType
+	seen := make(map[LinkType]bool)
+	for _, value := range values {
+		linkType := LinkType(strings.ToLower(strings.TrimSpace(value)))
+		switch linkType {
+		case LinkBigQuery, LinkGoogleAds, LinkFirebase:
+		case "search-console":
+			return nil, fmt.Errorf("Search Console links are not exposed by the GA4 Admin API - check them under Admin > Product links in GA4")
+		default:
+			return nil, fmt.Errorf("unknown link type %q (valid: bigquery, google-ads, firebase)", value)
+		}
+		if !seen[linkType] {
+			seen[linkType] = true
+			types = append(types, linkType)
+		}
+	}
+	return types, nil
+}
+
+// PropertyLinks holds the product links found on one property
+type PropertyLinks struct {
+	PropertyID  string              `json:"property_id"`
+	DisplayName string              `json:"display_name,omitempty"`
+	BigQuery    []api.BigQueryLink  `json:"bigquery_links,omitempty"`
+	GoogleAds   []api.GoogleAdsLink `json:"google_ads_links,omitempty"`
+	Firebase    []api.FirebaseLink  `json:"firebase_links,omitempty"`
+	Errors      map[LinkType]error  `json:"-"` // Link types that couldn't be listed
+}
+
+// Count returns how many links of a type the property has
+func (p *PropertyLinks) Count(linkType LinkType) int {
+	switch linkType {
+	case LinkBigQuery:
+		return len(p.BigQuery)
+	case LinkGoogleAds:
+		return len(p.GoogleAds)
+	case LinkFirebase:
+		return len(p.Firebase)
+	}
+	return 0
+}
+
+// Missing lists the expected link types the property has none of. Types that
+// couldn't be listed are left out since their state is unknown.
+func (p *PropertyLinks) Missing(expected []LinkType) []LinkType {
+	var missing []LinkType
+	for _, linkType := range expected {
+		if p.Errors[linkType] == nil && p.Count(linkType) == 0 {
+			missing = append(missing, linkType)
+		}
+	}
+	return missing
+}
+
+// FetchLinks lists a property's links of the given types. A type that fails
+// to list is recorded in Errors rather than failing the others.
+func FetchLinks(ctx context.Context, client api.AdminService, propertyID string, types []LinkType) *PropertyLinks {
+	links := &PropertyLinks{PropertyID: propertyID, Errors: make(map[LinkType]error)}
+	for _, linkType := range types {
+		var err error
+		switch linkType {
+		case LinkBigQuery:
+			links.BigQuery, err = client.ListBigQueryLinks(ctx, propertyID)
+		case LinkGoogleAds:
+			links.GoogleAds, err = client.ListGoogleAdsLinks(ctx, propertyID)
+		case LinkFirebase:
+			links.Firebase, err = client.ListFirebaseLinks(ctx, propertyID)
+		}
+		if err != nil {
+			links.Errors[linkType] = err
+		}
+	}
+	return links
+}
+
+// AuditLinks fetches the expected link types for each property with at most
+// `concurrency` properties in flight. Results keep the order of properties;
+// progress is called once per property as each one finishes, serialized.
+func AuditLinks(ctx context.Context, client api.AdminService, properties []config.Property, expected []LinkType, concurrency int, progress func(*PropertyLinks)) []*PropertyLinks {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*PropertyLinks, len(properties))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+
+	for i, property := range properties {
+		wg.Add(1)
+		go func(i int, property config.Property) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				results[i] = FetchLinks(ctx, client, property.ID, expected)
+			case <-ctx.Done():
+				results[i] = &PropertyLinks{PropertyID: property.ID, Errors: make(map[LinkType]error)}
+				for _, linkType := range expected {
+					results[i].Errors[linkType] = ctx.Err()
+				}
+			}
+			results[i].DisplayName = property.DisplayName
+
+			if progress != nil {
+				progressMu.Lock()
+				progress(results[i])
+				progressMu.Unlock()
+			}
+		}(i, property)
+	}
+
+	wg.Wait()
+	return results
+}
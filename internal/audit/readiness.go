@@ -0,0 +1,301 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+)
+
+// Points of each Clarisights readiness check; they add up to 100
+const (
+	ChannelGroupPoints = 40
+	DimensionsPoints   = 40
+	CurrencyPoints     = 20
+)
+
+// Readiness check names
+const (
+	CheckChannelGroup = "channel_group"
+	CheckDimensions   = "dimensions"
+	CheckCurrency     = "currency"
+)
+
+// ReadinessCheck is the outcome of one check on one property
+type ReadinessCheck struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	Max    int    `json:"max"`
+	Detail string `json:"detail"`
+	Error  string `json:"error,omitempty"` // The check couldn't run; it scores 0
+}
+
+// Passed reports whether the check earned all its points
+func (c ReadinessCheck) Passed() bool {
+	return c.Error == "" && c.Points == c.Max
+}
+
+// PropertyReadiness is a property's Clarisights readiness score
+type PropertyReadiness struct {
+	Rank              int              `json:"rank"` // Tied scores share a rank
+	PropertyID        string           `json:"property_id"`
+	DisplayName       string           `json:"display_name"`
+	Score             int              `json:"score"` // Percent of the points of the checks run
+	Currency          string           `json:"currency"`
+	ChannelGroups     []string         `json:"channel_groups"` // Custom channel groups
+	MissingDimensions []string         `json:"missing_dimensions"`
+	Checks            []ReadinessCheck `json:"checks"`
+}
+
+// Check returns the named check, or nil if it wasn't run
+func (r *PropertyReadiness) Check(name string) *ReadinessCheck {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+// Incomplete reports whether any check couldn't run
+func (r *PropertyReadiness) Incomplete() bool {
+	for _, check := range r.Checks {
+		if check.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadinessOptions are the expectations properties are scored against
+type ReadinessOptions struct {
+	// RequiredDimensions are dimension API names every property must offer;
+	// the dimensions check is skipped when there are none
+	RequiredDimensions []string
+	// Currency every property should report in; empty means the currency
+	// most of the properties use
+	Currency    string
+	Concurrency int
+}
+
+// MajorityCurrency returns the currency most properties use, the
+// alphabetically first on a tie
+func MajorityCurrency(properties []config.Property) string {
+	counts := make(map[string]int)
+	for _, property := range properties {
+		if property.CurrencyCode != "" {
+			counts[property.CurrencyCode]++
+		}
+	}
+	majority := ""
+	for currency, count := range counts {
+		if count > counts[majority] || (count == counts[majority] && currency < majority) {
+			majority = currency
+		}
+	}
+	return majority
+}
+
+// ScoreReadiness scores each property with at most opts.Concurrency in
+// flight and returns them ranked, best first. progress is called once per
+// property as each one finishes, serialized.
+func ScoreReadiness(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, properties []config.Property, opts ReadinessOptions, progress func(*PropertyReadiness)) []*PropertyReadiness {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Currency == "" {
+		opts.Currency = MajorityCurrency(properties)
+	}
+
+	results := make([]*PropertyReadiness, len(properties))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+
+	for i, property := range properties {
+		wg.Add(1)
+		go func(i int, property config.Property) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				results[i] = scoreProperty(ctx, adminClient, dataClient, property, opts)
+				<-sem
+			case <-ctx.Done():
+				results[i] = &PropertyReadiness{PropertyID: property.ID, DisplayName: property.DisplayName}
+				results[i].Checks = []ReadinessCheck{{Name: CheckChannelGroup, Max: ChannelGroupPoints, Error: ctx.Err().Error()}}
+			}
+
+			if progress != nil {
+				progressMu.Lock()
+				progress(results[i])
+				progressMu.Unlock()
+			}
+		}(i, property)
+	}
+	wg.Wait()
+
+	rankReadiness(results)
+	return results
+}
+
+func scoreProperty(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, property config.Property, opts ReadinessOptions) *PropertyReadiness {
+	r := &PropertyReadiness{
+		PropertyID:  property.ID,
+		DisplayName: property.DisplayName,
+		Currency:    property.CurrencyCode,
+	}
+
+	channelGroup := ReadinessCheck{Name: CheckChannelGroup, Max: ChannelGroupPoints}
+	groups, err := adminClient.ListChannelGroups(ctx, property.ID)
+	if err != nil {
+		channelGroup.Error = err.Error()
+	} else {
+		for _, group := range groups {
+			if !group.SystemDefined {
+				r.ChannelGroups = append(r.ChannelGroups, group.DisplayName)
+			}
+		}
+		if len(r.ChannelGroups) > 0 {
+			channelGroup.Points = channelGroup.Max
+			channelGroup.Detail = strings.Join(r.ChannelGroups, ", ")
+		} else {
+			channelGroup.Detail = "only the default channel group"
+		}
+	}
+	r.Checks = append(r.Checks, channelGroup)
+
+	if len(opts.RequiredDimensions) > 0 {
+		dimensions := ReadinessCheck{Name: CheckDimensions, Max: DimensionsPoints}
+		metadata, err := dataClient.GetMetadata(ctx, property.ID)
+		if err != nil {
+			dimensions.Error = err.Error()
+		} else {
+			available := make(map[string]bool, len(metadata.Dimensions))
+			for _, dim := range metadata.Dimensions {
+				available[dim.APIName] = true
+			}
+			for _, name := range opts.RequiredDimensions {
+				if !available[name] {
+					r.MissingDimensions = append(r.MissingDimensions, name)
+				}
+			}
+			present := len(opts.RequiredDimensions) - len(r.MissingDimensions)
+			dimensions.Points = dimensions.Max * present / len(opts.RequiredDimensions)
+			dimensions.Detail = fmt.Sprintf("%d/%d", present, len(opts.RequiredDimensions))
+		}
+		r.Checks = append(r.Checks, dimensions)
+	}
+
+	currency := ReadinessCheck{Name: CheckCurrency, Max: CurrencyPoints, Detail: property.CurrencyCode}
+	if opts.Currency == "" || property.CurrencyCode == opts.Currency {
+		currency.Points = currency.Max
+	} else {
+		currency.Detail = fmt.Sprintf("%s, expected %s", property.CurrencyCode, opts.Currency)
+	}
+	r.Checks = append(r.Checks, currency)
+
+	points, possible := 0, 0
+	for _, check := range r.Checks {
+		points += check.Points
+		possible += check.Max
+	}
+	r.Score = points * 100 / possible
+	return r
+}
+
+// rankReadiness sorts by score, best first, then name; tied scores share a rank
+func rankReadiness(results []*PropertyReadiness) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].DisplayName < results[j].DisplayName
+	})
+	for i, r := range results {
+		r.Rank = i + 1
+		if i > 0 && r.Score == results[i-1].Score {
+			r.Rank = results[i-1].Rank
+		}
+	}
+}
+
+// ReadinessHeaders are the CSV columns written by WriteReadinessCSV
+var ReadinessHeaders = []string{
+	"rank", "property_id", "property_name", "score", "channel_groups",
+	"dimensions", "missing_dimensions", "currency", "currency_ok", "errors",
+}
+
+// WriteReadinessCSV writes the ranked report, one row per property
+func WriteReadinessCSV(results []*PropertyReadiness, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(ReadinessHeaders); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+	for _, r := range results {
+		dimensions, currencyOK := "", ""
+		if check := r.Check(CheckDimensions); check != nil {
+			dimensions = check.Detail
+		}
+		if check := r.Check(CheckCurrency); check != nil {
+			currencyOK = fmt.Sprintf("%t", check.Passed())
+		}
+		var errors []string
+		for _, check := range r.Checks {
+			if check.Error != "" {
+				errors = append(errors, check.Name+": "+check.Error)
+			}
+		}
+		record := []string{
+			fmt.Sprintf("%d", r.Rank), r.PropertyID, r.DisplayName, fmt.Sprintf("%d", r.Score),
+			strings.Join(r.ChannelGroups, "; "), dimensions, strings.Join(r.MissingDimensions, "; "),
+			r.Currency, currencyOK, strings.Join(errors, "; "),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteReadinessJSON writes the ranked report as indented JSON
+func WriteReadinessJSON(results []*PropertyReadiness, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func createOutputFile(outputPath string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,420 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+//go:embed spam_referrers.txt
+var builtinSpamList string
+
+// Signals that make a referrer or hostname suspicious
+const (
+	SpamSignalBlocklist    = "blocklist"     // Listed as a known spam domain
+	SpamSignalNoEngagement = "no-engagement" // Almost no engaged sessions
+	SpamSignalAllNew       = "all-new-users" // Practically every user is new
+	SpamSignalBurst        = "burst"         // Most sessions arrived on a single day
+	SpamSignalForeignHost  = "foreign-host"  // Hit a hostname outside the property's sites
+	SpamSignalDevHost      = "development"   // Hit a local or development hostname
+)
+
+// Thresholds of the volume signals
+const (
+	allNewUsersShare = 98.0 // Percent of users that are new
+	burstDayShare    = 80.0 // Percent of a source's sessions on its busiest day
+	burstMinDays     = 7    // Ranges shorter than this can't show a burst
+)
+
+// benignHosts serve copies of a site, so their hits are real visitors
+var benignHosts = []string{"translate.goog", "googleusercontent.com"}
+
+// SpamOptions tune AnalyzeReferralSpam
+type SpamOptions struct {
+	Days          int
+	MinSessions   int64    // Volume signals ignore sources with fewer sessions
+	MaxEngagement float64  // Engagement rate in percent at or below which a source counts as unengaged
+	Hostnames     []string // The property's own hostnames, besides its web streams' URLs
+	Blocklists    []string // Files with more spam domains, one per line
+}
+
+// SpamCandidate is a referrer or hostname with its traffic and the signals
+// that make it suspicious
+type SpamCandidate struct {
+	Value          string   `json:"value"`
+	Sessions       int64    `json:"sessions"`
+	EngagementRate float64  `json:"engagement_rate"` // Percent of sessions that were engaged
+	NewUserShare   float64  `json:"new_user_share,omitempty"`
+	Signals        []string `json:"signals"`
+}
+
+// SpamReport lists suspicious referrers and hostnames with filters that keep
+// them out of reports
+type SpamReport struct {
+	PropertyID      string          `json:"property_id"`
+	Days            int             `json:"days"`
+	TotalSessions   int64           `json:"total_sessions"`
+	Hostnames       []string        `json:"hostnames"` // Hostnames considered the property's own
+	Referrers       []SpamCandidate `json:"referrers"`
+	ForeignHosts    []SpamCandidate `json:"foreign_hosts"`
+	SpamSessions    int64           `json:"spam_sessions"` // Sessions of flagged referrers
+	SourceFilter    string          `json:"source_filter,omitempty"`
+	HostnameFilter  string          `json:"hostname_filter,omitempty"`
+	UnwantedDomains []string        `json:"unwanted_domains,omitempty"` // Domains for the web streams' unwanted referrals
+	AnalyzedAt      time.Time       `json:"analyzed_at"`
+}
+
+// SpamShare is the percent of sessions that came from flagged referrers
+func (r *SpamReport) SpamShare() float64 {
+	return share(r.SpamSessions, r.TotalSessions)
+}
+
+// AnalyzeReferralSpam checks the property's referrers against the built-in
+// blocklist and for volume anomalies, and its hostnames against the sites of
+// its web streams. A referrer is flagged when it is listed, or when it has
+// no engagement and either only new users or a one-day burst of sessions.
+func AnalyzeReferralSpam(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, propertyID string, opts SpamOptions) (*SpamReport, error) {
+	if opts.Days <= 0 || opts.Days > 365 {
+		return nil, fmt.Errorf("days must be between 1 and 365")
+	}
+
+	blocklist, err := loadSpamBlocklist(opts.Blocklists)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SpamReport{PropertyID: propertyID, Days: opts.Days, AnalyzedAt: time.Now()}
+	for _, hostname := range opts.Hostnames {
+		report.Hostnames = append(report.Hostnames, normalizeHost(hostname))
+	}
+	streams, err := adminClient.ListDataStreams(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data streams: %w", err)
+	}
+	for _, stream := range streams {
+		if stream.WebStreamData == nil || stream.WebStreamData.DefaultURI == "" {
+			continue
+		}
+		if parsed, err := url.Parse(stream.WebStreamData.DefaultURI); err == nil && parsed.Hostname() != "" {
+			report.Hostnames = append(report.Hostnames, normalizeHost(parsed.Hostname()))
+		}
+	}
+	report.Hostnames = uniqueStrings(report.Hostnames)
+
+	dateRanges := []api.DateRange{{StartDate: fmt.Sprintf("%ddaysAgo", opts.Days), EndDate: "yesterday"}}
+
+	sources, err := dataClient.RunReport(ctx, &api.RunReportRequest{
+		Property:   propertyID,
+		Dimensions: []api.Dimension{{Name: "sessionSource"}, {Name: "sessionMedium"}},
+		Metrics:    []api.Metric{{Name: "sessions"}, {Name: "engagedSessions"}, {Name: "newUsers"}, {Name: "totalUsers"}},
+		DateRanges: dateRanges,
+		Limit:      100000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run referrer report: %w", err)
+	}
+
+	bursts, err := referrerBursts(ctx, dataClient, propertyID, dateRanges, opts.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range sources.Rows {
+		if len(row.DimensionValues) < 2 || len(row.MetricValues) < 4 {
+			continue
+		}
+		source, medium := row.DimensionValues[0].Value, row.DimensionValues[1].Value
+		sessions, engaged, newUsers, users := metricInt(row, 0), metricInt(row, 1), metricInt(row, 2), metricInt(row, 3)
+		report.TotalSessions += sessions
+
+		candidate := SpamCandidate{
+			Value:          source,
+			Sessions:       sessions,
+			EngagementRate: share(engaged, sessions),
+			NewUserShare:   share(newUsers, users),
+		}
+		if matchesDomain(source, blocklist) {
+			candidate.Signals = append(candidate.Signals, SpamSignalBlocklist)
+		}
+		if medium == "referral" && sessions >= opts.MinSessions {
+			if candidate.EngagementRate <= opts.MaxEngagement {
+				candidate.Signals = append(candidate.Signals, SpamSignalNoEngagement)
+			}
+			if candidate.NewUserShare >= allNewUsersShare {
+				candidate.Signals = append(candidate.Signals, SpamSignalAllNew)
+			}
+			if bursts[source] {
+				candidate.Signals = append(candidate.Signals, SpamSignalBurst)
+			}
+		}
+		if !isSpamReferrer(candidate.Signals) {
+			continue
+		}
+		report.Referrers = append(report.Referrers, candidate)
+		report.SpamSessions += sessions
+	}
+
+	if len(report.Hostnames) > 0 {
+		hosts, err := dataClient.RunReport(ctx, &api.RunReportRequest{
+			Property:   propertyID,
+			Dimensions: []api.Dimension{{Name: "hostName"}},
+			Metrics:    []api.Metric{{Name: "sessions"}, {Name: "engagedSessions"}},
+			DateRanges: dateRanges,
+			Limit:      10000,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to run hostname report: %w", err)
+		}
+		for _, row := range hosts.Rows {
+			if len(row.DimensionValues) < 1 || len(row.MetricValues) < 2 {
+				continue
+			}
+			host := normalizeHost(row.DimensionValues[0].Value)
+			if host == "" || isPlaceholder(host) || matchesDomain(host, report.Hostnames) || matchesDomain(host, benignHosts) {
+				continue
+			}
+			sessions := metricInt(row, 0)
+			candidate := SpamCandidate{
+				Value:          row.DimensionValues[0].Value,
+				Sessions:       sessions,
+				EngagementRate: share(metricInt(row, 1), sessions),
+				Signals:        []string{SpamSignalForeignHost},
+			}
+			if isDevelopmentHost(host) {
+				candidate.Signals = []string{SpamSignalDevHost}
+			}
+			if matchesDomain(host, blocklist) {
+				candidate.Signals = append(candidate.Signals, SpamSignalBlocklist)
+			}
+			report.ForeignHosts = append(report.ForeignHosts, candidate)
+		}
+	}
+
+	sortSpamCandidates(report.Referrers)
+	sortSpamCandidates(report.ForeignHosts)
+	report.recommendFilters()
+	return report, nil
+}
+
+// referrerBursts returns the referral sources with most of their sessions
+// on a single day
+func referrerBursts(ctx context.Context, dataClient api.DataService, propertyID string, dateRanges []api.DateRange, days int) (map[string]bool, error) {
+	bursts := make(map[string]bool)
+	if days < burstMinDays {
+		return bursts, nil
+	}
+
+	response, err := dataClient.RunReport(ctx, &api.RunReportRequest{
+		Property:        propertyID,
+		Dimensions:      []api.Dimension{{Name: "sessionSource"}, {Name: "date"}},
+		Metrics:         []api.Metric{{Name: "sessions"}},
+		DateRanges:      dateRanges,
+		DimensionFilter: api.NewDimensionFilter("sessionMedium", "referral"),
+		Limit:           100000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run daily referrer report: %w", err)
+	}
+
+	total := make(map[string]int64)
+	busiest := make(map[string]int64)
+	for _, row := range response.Rows {
+		if len(row.DimensionValues) < 2 || len(row.MetricValues) < 1 {
+			continue
+		}
+		source, sessions := row.DimensionValues[0].Value, metricInt(row, 0)
+		total[source] += sessions
+		if sessions > busiest[source] {
+			busiest[source] = sessions
+		}
+	}
+	for source, sessions := range total {
+		if share(busiest[source], sessions) >= burstDayShare {
+			bursts[source] = true
+		}
+	}
+	return bursts, nil
+}
+
+// isSpamReferrer decides from a referrer's signals whether to flag it. Low
+// engagement alone also fits a badly built landing page, so it needs
+// another volume signal.
+func isSpamReferrer(signals []string) bool {
+	has := make(map[string]bool, len(signals))
+	for _, signal := range signals {
+		has[signal] = true
+	}
+	return has[SpamSignalBlocklist] || (has[SpamSignalNoEngagement] && (has[SpamSignalAllNew] || has[SpamSignalBurst]))
+}
+
+// recommendFilters builds report filters that exclude the flagged referrers
+// and keep only the property's own hostnames
+func (r *SpamReport) recommendFilters() {
+	var domains []string
+	for _, referrer := range r.Referrers {
+		domains = append(domains, normalizeHost(referrer.Value))
+	}
+	domains = uniqueStrings(domains)
+	if len(domains) > 0 {
+		r.SourceFilter = "sessionSource!~" + domainRegexp(domains)
+		r.UnwantedDomains = domains
+	}
+	if len(r.ForeignHosts) > 0 && len(r.Hostnames) > 0 {
+		r.HostnameFilter = "hostName=~" + domainRegexp(r.Hostnames)
+	}
+}
+
+// domainRegexp matches the domains and their subdomains
+func domainRegexp(domains []string) string {
+	quoted := make([]string, len(domains))
+	for i, domain := range domains {
+		quoted[i] = regexp.QuoteMeta(domain)
+	}
+	return "(^|\\.)(" + strings.Join(quoted, "|") + ")$"
+}
+
+// loadSpamBlocklist reads the built-in blocklist and any extra files
+func loadSpamBlocklist(paths []string) ([]string, error) {
+	domains := parseDomainList(builtinSpamList)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blocklist: %w", err)
+		}
+		domains = append(domains, parseDomainList(string(data))...)
+	}
+	return uniqueStrings(domains), nil
+}
+
+func parseDomainList(list string) []string {
+	var domains []string
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, normalizeHost(line))
+	}
+	return domains
+}
+
+// normalizeHost lowercases a hostname or domain and drops a scheme, path and
+// leading www.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/:"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+// matchesDomain reports whether host is one of domains or a subdomain of one
+func matchesDomain(host string, domains []string) bool {
+	host = normalizeHost(host)
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDevelopmentHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".test")
+}
+
+func metricInt(row api.Row, i int) int64 {
+	value, _ := strconv.ParseInt(row.MetricValues[i].Value, 10, 64)
+	return value
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func sortSpamCandidates(candidates []SpamCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Sessions != candidates[j].Sessions {
+			return candidates[i].Sessions > candidates[j].Sessions
+		}
+		return candidates[i].Value < candidates[j].Value
+	})
+}
+
+// SpamHeaders are the CSV columns written by WriteSpamCSV
+var SpamHeaders = []string{"type", "value", "sessions", "engagement_rate", "new_user_share", "signals"}
+
+// WriteSpamCSV writes the flagged referrers and hostnames, one per row
+func WriteSpamCSV(report *SpamReport, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(SpamHeaders); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+	write := func(kind string, candidates []SpamCandidate) error {
+		for _, candidate := range candidates {
+			record := []string{
+				kind, candidate.Value, strconv.FormatInt(candidate.Sessions, 10),
+				fmt.Sprintf("%.2f", candidate.EngagementRate), fmt.Sprintf("%.2f", candidate.NewUserShare),
+				strings.Join(candidate.Signals, "; "),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := write("referrer", report.Referrers); err != nil {
+		return err
+	}
+	if err := write("hostname", report.ForeignHosts); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteSpamJSON writes the full report as indented JSON
+func WriteSpamJSON(report *SpamReport, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
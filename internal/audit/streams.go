@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"ga4admin/internal/api"
+)
+
+// UnexposedTagSettings are Google tag settings the Admin API has no resource
+// for, so tagging audits must check them in the GA4 UI (Admin > Data streams
+// > Configure tag settings)
+var UnexposedTagSettings = []string{"cross-domain linking", "internal traffic rules"}
+
+// StreamSettings gathers what a tagging audit checks on one data stream.
+// Enhanced measurement and data redaction only exist for web streams.
+type StreamSettings struct {
+	PropertyID          string                           `json:"property_id"`
+	Stream              api.DataStream                   `json:"stream"`
+	EnhancedMeasurement *api.EnhancedMeasurementSettings `json:"enhanced_measurement,omitempty"`
+	DataRedaction       *api.DataRedactionSettings       `json:"data_redaction,omitempty"`
+	Unavailable         []string                         `json:"unavailable"`      // Settings the Admin API doesn't expose
+	Errors              map[string]string                `json:"errors,omitempty"` // Settings that failed to load, by name
+}
+
+// FetchStreamSettings reads a stream and, for web streams, its enhanced
+// measurement and redaction settings. Failing to read the stream itself is an
+// error; the other settings' failures are recorded in Errors.
+func FetchStreamSettings(ctx context.Context, client api.AdminService, propertyID, streamID string) (*StreamSettings, error) {
+	stream, err := client.GetDataStream(ctx, propertyID, streamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data stream: %w", err)
+	}
+
+	settings := &StreamSettings{
+		PropertyID:  propertyID,
+		Stream:      *stream,
+		Unavailable: UnexposedTagSettings,
+	}
+	if stream.Type != api.DataStreamTypeWeb {
+		return settings, nil
+	}
+
+	settings.EnhancedMeasurement, err = client.GetEnhancedMeasurementSettings(ctx, propertyID, streamID)
+	if err != nil {
+		settings.addError("enhanced_measurement", err)
+	}
+	settings.DataRedaction, err = client.GetDataRedactionSettings(ctx, propertyID, streamID)
+	if err != nil {
+		settings.addError("data_redaction", err)
+	}
+	return settings, nil
+}
+
+func (s *StreamSettings) addError(name string, err error) {
+	if s.Errors == nil {
+		s.Errors = make(map[string]string)
+	}
+	s.Errors[name] = err.Error()
+}
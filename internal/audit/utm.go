@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// UTM fields checked by AnalyzeUTM
+const (
+	UTMSource   = "source"
+	UTMMedium   = "medium"
+	UTMCampaign = "campaign"
+)
+
+// Kinds of UTM inconsistency
+const (
+	UTMIssueCasing     = "casing"     // Variants differ only in letter case
+	UTMIssueWhitespace = "whitespace" // Leading, trailing or repeated spaces
+	UTMIssueSeparators = "separators" // Spaces, '_' and '-' used interchangeably
+)
+
+// utmPageSize is the number of source/medium/campaign rows read per request
+const utmPageSize = 100000
+
+// maxUTMRows caps the combinations read, for properties with runaway
+// campaign names
+const maxUTMRows = 500000
+
+var (
+	repeatedSpace = regexp.MustCompile(`\s+`)
+	utmSeparators = regexp.MustCompile(`[\s_-]+`)
+)
+
+// UTMValue is a value and the sessions it received
+type UTMValue struct {
+	Value    string `json:"value"`
+	Sessions int64  `json:"sessions"`
+}
+
+// UTMIssue is a group of values of one field that probably mean the same
+// thing, or a single value with stray whitespace
+type UTMIssue struct {
+	Field     string     `json:"field"`
+	Kinds     []string   `json:"kinds"`
+	Suggested string     `json:"suggested"` // Tidied form of the busiest variant
+	Variants  []UTMValue `json:"variants"`  // Busiest first
+	Sessions  int64      `json:"sessions"`
+}
+
+// UTMReport summarizes how consistently a property's traffic is tagged
+type UTMReport struct {
+	PropertyID       string     `json:"property_id"`
+	Days             int        `json:"days"`
+	TotalSessions    int64      `json:"total_sessions"`
+	Combinations     int        `json:"combinations"`      // Distinct source/medium/campaign combinations
+	Truncated        bool       `json:"truncated"`         // More combinations exist than were read
+	UntaggedSessions int64      `json:"untagged_sessions"` // Sessions without a campaign
+	Untagged         []UTMValue `json:"untagged"`          // Untagged sessions by "source / medium", busiest first
+	Issues           []UTMIssue `json:"issues"`            // Busiest first
+	AnalyzedAt       time.Time  `json:"analyzed_at"`
+}
+
+// UntaggedShare is the percent of sessions without a campaign
+func (r *UTMReport) UntaggedShare() float64 {
+	return share(r.UntaggedSessions, r.TotalSessions)
+}
+
+// AnalyzeUTM reads the session source, medium and campaign of the last
+// `days` days and groups values of each field that differ only in case,
+// whitespace or separators. Traffic whose campaign is one of GA4's
+// placeholders, such as (direct) or (referral), counts as untagged.
+func AnalyzeUTM(ctx context.Context, dataClient api.DataService, propertyID string, days int) (*UTMReport, error) {
+	if days <= 0 || days > 365 {
+		return nil, fmt.Errorf("days must be between 1 and 365")
+	}
+
+	report := &UTMReport{PropertyID: propertyID, Days: days, AnalyzedAt: time.Now()}
+	values := map[string]map[string]int64{
+		UTMSource:   {},
+		UTMMedium:   {},
+		UTMCampaign: {},
+	}
+	untagged := make(map[string]int64)
+
+	for offset := int64(0); ; offset += utmPageSize {
+		request := &api.RunReportRequest{
+			Property:   propertyID,
+			Dimensions: []api.Dimension{{Name: "sessionSource"}, {Name: "sessionMedium"}, {Name: "sessionCampaignName"}},
+			Metrics:    []api.Metric{{Name: "sessions"}},
+			DateRanges: []api.DateRange{
+				{
+					StartDate: fmt.Sprintf("%ddaysAgo", days),
+					EndDate:   "yesterday",
+				},
+			},
+			Offset: offset,
+			Limit:  utmPageSize,
+		}
+
+		response, err := dataClient.RunReport(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run source/medium/campaign report: %w", err)
+		}
+
+		for _, row := range response.Rows {
+			if len(row.DimensionValues) < 3 || len(row.MetricValues) < 1 {
+				continue
+			}
+			source, medium, campaign := row.DimensionValues[0].Value, row.DimensionValues[1].Value, row.DimensionValues[2].Value
+			sessions, _ := strconv.ParseInt(row.MetricValues[0].Value, 10, 64)
+
+			report.Combinations++
+			report.TotalSessions += sessions
+			values[UTMSource][source] += sessions
+			values[UTMMedium][medium] += sessions
+			values[UTMCampaign][campaign] += sessions
+			if isPlaceholder(campaign) {
+				report.UntaggedSessions += sessions
+				untagged[source+" / "+medium] += sessions
+			}
+		}
+
+		read := offset + int64(len(response.Rows))
+		if len(response.Rows) == 0 || read >= int64(response.RowCount) {
+			break
+		}
+		if read >= maxUTMRows {
+			report.Truncated = true
+			break
+		}
+	}
+
+	for value, sessions := range untagged {
+		report.Untagged = append(report.Untagged, UTMValue{Value: value, Sessions: sessions})
+	}
+	sortUTMValues(report.Untagged)
+
+	for _, field := range []string{UTMSource, UTMMedium, UTMCampaign} {
+		report.Issues = append(report.Issues, utmIssues(field, values[field])...)
+	}
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Suggested < b.Suggested
+	})
+
+	return report, nil
+}
+
+// utmIssues groups the values of one field that are equal once case,
+// whitespace and separators are ignored
+func utmIssues(field string, sessions map[string]int64) []UTMIssue {
+	groups := make(map[string][]UTMValue)
+	for value, count := range sessions {
+		if isPlaceholder(value) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		key := utmSeparators.ReplaceAllString(strings.ToLower(strings.TrimSpace(value)), "-")
+		groups[key] = append(groups[key], UTMValue{Value: value, Sessions: count})
+	}
+
+	var issues []UTMIssue
+	for _, variants := range groups {
+		// Spellings with the separators unified tell case differences apart
+		// from separator differences
+		tidied := make(map[string]bool)
+		spellings := make(map[string]bool)
+		whitespace := false
+		for _, variant := range variants {
+			tidy := tidyUTMValue(variant.Value)
+			tidied[strings.ToLower(tidy)] = true
+			spellings[utmSeparators.ReplaceAllString(tidy, "-")] = true
+			whitespace = whitespace || tidy != variant.Value
+		}
+		casing := len(spellings) > 1
+
+		var kinds []string
+		if casing {
+			kinds = append(kinds, UTMIssueCasing)
+		}
+		if whitespace {
+			kinds = append(kinds, UTMIssueWhitespace)
+		}
+		if len(tidied) > 1 {
+			kinds = append(kinds, UTMIssueSeparators)
+		}
+		if len(kinds) == 0 {
+			continue
+		}
+
+		sortUTMValues(variants)
+		suggested := tidyUTMValue(variants[0].Value)
+		if casing {
+			// Lowercase is the usual convention, and what most variants agree on
+			suggested = strings.ToLower(suggested)
+		}
+
+		issue := UTMIssue{Field: field, Kinds: kinds, Suggested: suggested, Variants: variants}
+		for _, variant := range variants {
+			issue.Sessions += variant.Sessions
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// tidyUTMValue trims a value and collapses repeated whitespace
+func tidyUTMValue(value string) string {
+	return repeatedSpace.ReplaceAllString(strings.TrimSpace(value), " ")
+}
+
+// isPlaceholder reports whether GA4 filled the value in itself, as with
+// (not set), (direct), (organic) or (referral)
+func isPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+}
+
+func sortUTMValues(values []UTMValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Sessions != values[j].Sessions {
+			return values[i].Sessions > values[j].Sessions
+		}
+		return values[i].Value < values[j].Value
+	})
+}
+
+// UTMWorksheetHeaders are the CSV columns written by WriteUTMWorksheetCSV
+var UTMWorksheetHeaders = []string{"field", "value", "replace_with", "issue", "sessions", "share"}
+
+// WriteUTMWorksheetCSV writes a cleanup worksheet: one row per value that
+// should be retagged, with its suggested replacement
+func WriteUTMWorksheetCSV(report *UTMReport, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(UTMWorksheetHeaders); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+	for _, issue := range report.Issues {
+		for _, variant := range issue.Variants {
+			if variant.Value == issue.Suggested {
+				continue
+			}
+			record := []string{
+				issue.Field, variant.Value, issue.Suggested, strings.Join(issue.Kinds, "; "),
+				strconv.FormatInt(variant.Sessions, 10), fmt.Sprintf("%.2f", share(variant.Sessions, report.TotalSessions)),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteUTMReportJSON writes the full report as indented JSON
+func WriteUTMReportJSON(report *UTMReport, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+// Package backfill plans the queries needed to complete a series of named
+// result tables: it splits a date range into chunks, finds the chunks
+// already saved as named tables of the same query, and lists the rest.
+package backfill
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+)
+
+// Chunk sizes
+const (
+	ChunkDay   = "day"
+	ChunkWeek  = "week"
+	ChunkMonth = "month"
+)
+
+// Chunk statuses. Every status but covered needs a query.
+const (
+	StatusCovered   = "covered"   // A complete named table holds the chunk
+	StatusMissing   = "missing"   // No named table holds the chunk
+	StatusTruncated = "truncated" // The table holding it hit its row limit
+	StatusUnsettled = "unsettled" // The table was fetched before GA4 finished processing the chunk
+)
+
+// SettleTime is how long after a day ends GA4 keeps processing its data.
+// Tables fetched sooner may be missing events.
+const SettleTime = 48 * time.Hour
+
+const dateLayout = "2006-01-02"
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Chunk is one date range of the plan
+type Chunk struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Status    string `json:"status"`
+	Table     string `json:"table"`                // Named table a query for the chunk is saved as
+	CoveredBy string `json:"covered_by,omitempty"` // Existing table holding the chunk, if any
+}
+
+// Pending reports whether the chunk needs a query
+func (c Chunk) Pending() bool {
+	return c.Status != StatusCovered
+}
+
+// Plan lists a template's chunks and which of them need a query
+type Plan struct {
+	PropertyID string    `json:"property_id"`
+	Template   string    `json:"template"`
+	Prefix     string    `json:"prefix"`
+	ChunkBy    string    `json:"chunk_by"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Chunks     []Chunk   `json:"chunks"`
+	PlannedAt  time.Time `json:"planned_at"`
+}
+
+// Pending returns the chunks that need a query
+func (p *Plan) Pending() []Chunk {
+	var pending []Chunk
+	for _, chunk := range p.Chunks {
+		if chunk.Pending() {
+			pending = append(pending, chunk)
+		}
+	}
+	return pending
+}
+
+// Options configure a plan
+type Options struct {
+	Template string // Query file or built-in report the chunks run
+	Prefix   string // Named table prefix; default: the template's name
+	ChunkBy  string // day, week or month
+	From, To time.Time
+}
+
+// DefaultPrefix derives a named table prefix from a template name, e.g.
+// "reports/landing-pages.yaml" becomes "landing_pages"
+func DefaultPrefix(template string) string {
+	name := strings.TrimSuffix(filepath.Base(template), filepath.Ext(template))
+	return strings.Trim(unsafeNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// TableName is the named table a chunk of the series is saved as
+func TableName(prefix string, start, end time.Time) string {
+	return fmt.Sprintf("%s_%s_%s", prefix, start.Format("20060102"), end.Format("20060102"))
+}
+
+// Chunks splits [from, to] into calendar days, ISO weeks or months. The
+// first and last chunks are cut short at the range's ends.
+func Chunks(from, to time.Time, by string) ([][2]time.Time, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("end date %s is before start date %s", to.Format(dateLayout), from.Format(dateLayout))
+	}
+
+	var next func(time.Time) time.Time
+	switch by {
+	case ChunkDay:
+		next = func(day time.Time) time.Time { return day.AddDate(0, 0, 1) }
+	case ChunkWeek:
+		next = func(day time.Time) time.Time {
+			// Weeks start on Monday
+			return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+		}
+	case ChunkMonth:
+		next = func(day time.Time) time.Time {
+			return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, day.Location())
+		}
+	default:
+		return nil, fmt.Errorf("invalid chunk size '%s' (use day, week or month)", by)
+	}
+
+	var chunks [][2]time.Time
+	for start := from; !start.After(to); start = next(start) {
+		end := next(start).AddDate(0, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+		chunks = append(chunks, [2]time.Time{start, end})
+	}
+	return chunks, nil
+}
+
+// savedTable is a named table whose request has the template's shape
+type savedTable struct {
+	name       string
+	start, end time.Time
+	status     string
+}
+
+// Build plans the chunks of request between the options' dates. A named
+// table covers a chunk when its request differs from the template's only in
+// date range and limit, and its range is the chunk's own, or contains the
+// chunk and the query has the date dimension, so the chunk's rows can be
+// told apart. Tables are listed newest first; the first complete one wins.
+func Build(request *api.RunReportRequest, tables []config.NamedTableQuery, opts Options, now time.Time) (*Plan, error) {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix(opts.Template)
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("cannot derive a table prefix from '%s'; set one with --prefix", opts.Template)
+	}
+
+	chunks, err := Chunks(opts.From, opts.To, opts.ChunkBy)
+	if err != nil {
+		return nil, err
+	}
+
+	hasDate := false
+	for _, dimension := range request.Dimensions {
+		if dimension.Name == "date" {
+			hasDate = true
+		}
+	}
+
+	var saved []savedTable
+	for _, table := range tables {
+		var cached api.RunReportRequest
+		if err := json.Unmarshal([]byte(table.Params), &cached); err != nil {
+			continue
+		}
+		cached.Property = request.Property
+		if len(cached.DateRanges) != 1 || !api.SameReportShape(&cached, request) {
+			continue
+		}
+		start, err := time.Parse(dateLayout, cached.DateRanges[0].StartDate)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(dateLayout, cached.DateRanges[0].EndDate)
+		if err != nil {
+			continue
+		}
+
+		status := StatusCovered
+		switch {
+		case cached.Limit > 0 && int64(table.RowCount) >= cached.Limit:
+			status = StatusTruncated
+		case table.CreatedAt.Before(end.AddDate(0, 0, 1).Add(SettleTime)):
+			status = StatusUnsettled
+		}
+		saved = append(saved, savedTable{name: table.Name, start: start, end: end, status: status})
+	}
+
+	plan := &Plan{
+		PropertyID: request.Property,
+		Template:   opts.Template,
+		Prefix:     prefix,
+		ChunkBy:    opts.ChunkBy,
+		From:       opts.From.Format(dateLayout),
+		To:         opts.To.Format(dateLayout),
+		PlannedAt:  now,
+	}
+	for _, bounds := range chunks {
+		start, end := bounds[0], bounds[1]
+		chunk := Chunk{
+			StartDate: start.Format(dateLayout),
+			EndDate:   end.Format(dateLayout),
+			Status:    StatusMissing,
+			Table:     TableName(prefix, start, end),
+		}
+		for _, table := range saved {
+			exact := table.start.Equal(start) && table.end.Equal(end)
+			contains := !table.start.After(start) && !table.end.Before(end)
+			if !exact && !(hasDate && contains) {
+				continue
+			}
+			if chunk.CoveredBy == "" || table.status == StatusCovered {
+				chunk.Status, chunk.CoveredBy = table.status, table.name
+			}
+			if table.status == StatusCovered {
+				break
+			}
+		}
+		plan.Chunks = append(plan.Chunks, chunk)
+	}
+	return plan, nil
+}
+
+// PlanHeaders are the CSV columns written by WritePlanCSV
+var PlanHeaders = []string{"property_id", "template", "start_date", "end_date", "status", "table", "covered_by"}
+
+// WritePlanCSV writes one row per chunk
+func WritePlanCSV(plan *Plan, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(PlanHeaders); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+	for _, chunk := range plan.Chunks {
+		record := []string{plan.PropertyID, plan.Template, chunk.StartDate, chunk.EndDate, chunk.Status, chunk.Table, chunk.CoveredBy}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WritePlanJSON writes the plan as indented JSON
+func WritePlanJSON(plan *Plan, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(plan); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func createOutputFile(outputPath string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTag is returned for tags that aren't short lowercase words
+var ErrInvalidTag = errors.New("invalid tag")
+
+// tagPattern allows tags such as campaign-launch, q3_2025 or client:acme
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]{0,63}$`)
+
+// Annotations are the tags and note attached to a result
+type Annotations struct {
+	Tags          []string
+	Note          string
+	NoteUpdatedAt time.Time
+}
+
+// NormalizeTag lowercases a tag and checks it is a single word of letters,
+// digits and . _ : -
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w '%s': use up to 64 letters, digits and . _ : -, starting with a letter or digit", ErrInvalidTag, tag)
+	}
+	return normalized, nil
+}
+
+// TagResult adds tags to a result, returning how many it didn't have yet.
+// Tagged results are kept when the cache is cleaned up.
+func (c *CacheClient) TagResult(ctx context.Context, queryID string, tags []string) (int, error) {
+	added := 0
+	err := c.transact(ctx, func(tx *sql.Tx) error {
+		added = 0
+		for _, tag := range tags {
+			var exists int
+			err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM result_tags WHERE query_id = ? AND tag = ?`, queryID, tag).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if exists > 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO result_tags (query_id, tag, created_at) VALUES (?, ?, ?)
+			`, queryID, tag, dbNow()); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to tag result: %w", err)
+	}
+	return added, nil
+}
+
+// UntagResult removes tags from a result, returning how many it had
+func (c *CacheClient) UntagResult(ctx context.Context, queryID string, tags []string) (int, error) {
+	removed := 0
+	err := c.transact(ctx, func(tx *sql.Tx) error {
+		removed = 0
+		for _, tag := range tags {
+			result, err := tx.ExecContext(ctx, `DELETE FROM result_tags WHERE query_id = ? AND tag = ?`, queryID, tag)
+			if err != nil {
+				return err
+			}
+			affected, _ := result.RowsAffected()
+			removed += int(affected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to untag result: %w", err)
+	}
+	return removed, nil
+}
+
+// SetResultNote replaces a result's note; an empty note removes it.
+// Annotated results are kept when the cache is cleaned up.
+func (c *CacheClient) SetResultNote(ctx context.Context, queryID, note string) error {
+	// DuckDB can't delete and re-insert a primary key in one transaction,
+	// so an existing note is updated in place
+	err := c.transact(ctx, func(tx *sql.Tx) error {
+		if note == "" {
+			_, err := tx.ExecContext(ctx, `DELETE FROM result_notes WHERE query_id = ?`, queryID)
+			return err
+		}
+		result, err := tx.ExecContext(ctx, `UPDATE result_notes SET note = ?, updated_at = ? WHERE query_id = ?`, note, dbNow(), queryID)
+		if err != nil {
+			return err
+		}
+		if updated, _ := result.RowsAffected(); updated > 0 {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO result_notes (query_id, note, updated_at) VALUES (?, ?, ?)`, queryID, note, dbNow())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+	return nil
+}
+
+// GetAnnotations returns a result's tags, in alphabetical order, and note
+func (c *CacheClient) GetAnnotations(ctx context.Context, queryID string) (*Annotations, error) {
+	annotations, err := c.listAnnotations(ctx, `WHERE query_id = ?`, queryID)
+	if err != nil {
+		return nil, err
+	}
+	if found, ok := annotations[queryID]; ok {
+		return found, nil
+	}
+	return &Annotations{}, nil
+}
+
+// listAnnotations returns the annotations of the results matching where,
+// a condition on query_id, by query ID
+func (c *CacheClient) listAnnotations(ctx context.Context, where string, args ...interface{}) (map[string]*Annotations, error) {
+	annotations := make(map[string]*Annotations)
+	get := func(queryID string) *Annotations {
+		if annotations[queryID] == nil {
+			annotations[queryID] = &Annotations{}
+		}
+		return annotations[queryID]
+	}
+
+	rows, err := c.db.QueryContext(ctx, `SELECT query_id, tag FROM result_tags `+where+` ORDER BY query_id, tag`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var queryID, tag string
+		if err := rows.Scan(&queryID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to read result tags: %w", err)
+		}
+		entry := get(queryID)
+		entry.Tags = append(entry.Tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	noteRows, err := c.db.QueryContext(ctx, `SELECT query_id, note, updated_at FROM result_notes `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result notes: %w", err)
+	}
+	defer noteRows.Close()
+	for noteRows.Next() {
+		var queryID, note string
+		var updatedAt time.Time
+		if err := noteRows.Scan(&queryID, &note, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read result notes: %w", err)
+		}
+		entry := get(queryID)
+		entry.Note, entry.NoteUpdatedAt = note, updatedAt
+	}
+	return annotations, noteRows.Err()
+}
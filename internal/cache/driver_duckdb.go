@@ -0,0 +1,19 @@
+//go:build cgo && !sqlite
+
+package cache
+
+import (
+	"database/sql"
+
+	"ga4admin/internal/duckdb"
+)
+
+// Backend names the database the cache is stored in
+const Backend = "DuckDB"
+
+// cacheFileExt is the extension of a preset's cache file
+const cacheFileExt = ".db"
+
+func openCacheDB(path string) (*sql.DB, error) {
+	return duckdb.Open(path)
+}
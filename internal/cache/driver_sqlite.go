@@ -0,0 +1,27 @@
+//go:build !cgo || sqlite
+
+package cache
+
+import (
+	"database/sql"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// Backend names the database the cache is stored in. Builds without CGO, or
+// with the sqlite tag, use the pure-Go SQLite driver instead of DuckDB.
+const Backend = "SQLite"
+
+// cacheFileExt keeps SQLite caches apart from DuckDB ones, whose file format
+// differs, when both kinds of build share a data directory
+const cacheFileExt = ".sqlite"
+
+// openCacheDB opens the cache in WAL mode with a busy timeout, so separate
+// ga4admin processes sharing a preset wait for each other's writes instead of
+// failing with SQLITE_BUSY
+func openCacheDB(path string) (*sql.DB, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	return sql.Open("sqlite", dsn)
+}
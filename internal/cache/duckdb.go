@@ -4,43 +4,58 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
-	_ "github.com/marcboeker/go-duckdb"
-	
 	"ga4admin/internal/config"
+	"ga4admin/internal/migrate"
 )
 
-// CacheClient handles DuckDB-based caching operations
+// CacheClient handles caching operations. The cache is stored in DuckDB, or
+// in SQLite for builds without CGO (see driver_duckdb.go and driver_sqlite.go),
+// so its SQL sticks to what both accept.
 type CacheClient struct {
 	db         *sql.DB
 	presetName string
 	cachePath  string
+
+	// All writes go through a single writer goroutine (see writer.go)
+	writes     chan writeRequest
+	writerDone chan struct{}
+	writeMu    sync.RWMutex
+	closed     bool
+}
+
+// CachePath returns the file holding a preset's cache
+// (~/.ga4admin/cache/<name>.db, or .sqlite in SQLite builds)
+func CachePath(presetName string) (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "cache", presetName+cacheFileExt), nil
 }
 
 // NewCacheClient creates a new cache client for a specific preset
 func NewCacheClient(presetName string) (*CacheClient, error) {
-	// Create cache directory if it doesn't exist
-	homeDir, err := os.UserHomeDir()
+	// Preset-specific database file
+	cachePath, err := CachePath(presetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return nil, err
 	}
 
-	cacheDir := filepath.Join(homeDir, ".ga4admin", "cache")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	// Create cache directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
-
-	// Create preset-specific database file
-	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%s.db", presetName))
 	
-	// Connect to DuckDB
-	db, err := sql.Open("duckdb", cachePath)
+	db, err := openCacheDB(cachePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open DuckDB connection: %w", err)
+		return nil, fmt.Errorf("failed to open %s cache: %w", Backend, err)
 	}
 
 	client := &CacheClient{
@@ -49,78 +64,38 @@ func NewCacheClient(presetName string) (*CacheClient, error) {
 		cachePath:  cachePath,
 	}
 
+	if err := client.startWriter(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Initialize cache tables
 	if err := client.initializeTables(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to initialize cache tables: %w", err)
 	}
 
 	return client, nil
 }
 
-// Close closes the database connection
+// Close flushes pending writes and closes the database connection
 func (c *CacheClient) Close() error {
+	c.stopWriter()
 	if c.db != nil {
 		return c.db.Close()
 	}
 	return nil
 }
 
-// initializeTables creates the necessary cache tables
+// initializeTables migrates the cache schema and creates this preset's
+// stats row
 func (c *CacheClient) initializeTables() error {
-	queries := []string{
-		// Metadata cache table
-		`CREATE TABLE IF NOT EXISTS metadata_cache (
-			property_id VARCHAR PRIMARY KEY,
-			cache_type VARCHAR NOT NULL,  -- 'dimensions', 'metrics', 'events'
-			data TEXT NOT NULL,           -- JSON-encoded metadata
-			created_at TIMESTAMP DEFAULT NOW(),
-			expires_at TIMESTAMP NOT NULL,
-			last_accessed TIMESTAMP DEFAULT NOW()
-		)`,
-		
-		// Query results cache table
-		`CREATE TABLE IF NOT EXISTS query_cache (
-			query_id VARCHAR PRIMARY KEY,
-			property_id VARCHAR NOT NULL,
-			query_hash VARCHAR NOT NULL,     -- Hash of query parameters
-			query_params TEXT NOT NULL,     -- JSON-encoded query parameters
-			result_data TEXT NOT NULL,      -- JSON-encoded query results
-			row_count INTEGER NOT NULL,
-			created_at TIMESTAMP DEFAULT NOW(),
-			expires_at TIMESTAMP,           -- NULL = never expires
-			last_accessed TIMESTAMP DEFAULT NOW()
-		)`,
-		
-		// Named tables for query results
-		`CREATE TABLE IF NOT EXISTS named_tables (
-			table_name VARCHAR PRIMARY KEY,
-			property_id VARCHAR NOT NULL,
-			query_id VARCHAR NOT NULL,
-			description TEXT,
-			created_at TIMESTAMP DEFAULT NOW(),
-			last_accessed TIMESTAMP DEFAULT NOW(),
-			FOREIGN KEY (query_id) REFERENCES query_cache(query_id)
-		)`,
-		
-		// Cache statistics table
-		`CREATE TABLE IF NOT EXISTS cache_stats (
-			preset_name VARCHAR PRIMARY KEY,
-			total_hits INTEGER DEFAULT 0,
-			total_misses INTEGER DEFAULT 0,
-			last_cleanup TIMESTAMP,
-			created_at TIMESTAMP DEFAULT NOW(),
-			updated_at TIMESTAMP DEFAULT NOW()
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := c.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
+	if _, err := migrate.Apply(context.Background(), c.db, migrations); err != nil {
+		return err
 	}
 
 	// Initialize cache stats for this preset
-	_, err := c.db.Exec(`
+	_, err := c.exec(context.Background(), `
 		INSERT OR IGNORE INTO cache_stats (preset_name) 
 		VALUES (?)
 	`, c.presetName)
@@ -135,9 +110,9 @@ func (c *CacheClient) CacheMetadata(ctx context.Context, propertyID, cacheType s
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	expiresAt := time.Now().Add(time.Duration(ttlHours) * time.Hour)
+	expiresAt := dbNow().Add(time.Duration(ttlHours) * time.Hour)
 	
-	_, err = c.db.ExecContext(ctx, `
+	_, err = c.exec(ctx, `
 		INSERT OR REPLACE INTO metadata_cache 
 		(property_id, cache_type, data, expires_at) 
 		VALUES (?, ?, ?, ?)
@@ -148,10 +123,21 @@ func (c *CacheClient) CacheMetadata(ctx context.Context, propertyID, cacheType s
 
 // GetCachedMetadata retrieves cached metadata if valid
 func (c *CacheClient) GetCachedMetadata(ctx context.Context, propertyID, cacheType string, result interface{}) (bool, error) {
+	found, _, err := c.lookupMetadata(ctx, propertyID, cacheType, result, false)
+	return found, err
+}
+
+// GetStaleMetadata retrieves cached metadata even if it has expired, reporting
+// whether it is stale. Expired entries are kept so they can be refreshed.
+func (c *CacheClient) GetStaleMetadata(ctx context.Context, propertyID, cacheType string, result interface{}) (bool, bool, error) {
+	return c.lookupMetadata(ctx, propertyID, cacheType, result, true)
+}
+
+func (c *CacheClient) lookupMetadata(ctx context.Context, propertyID, cacheType string, result interface{}, keepStale bool) (found, stale bool, err error) {
 	var data string
 	var expiresAt time.Time
 
-	err := c.db.QueryRowContext(ctx, `
+	err = c.db.QueryRowContext(ctx, `
 		SELECT data, expires_at 
 		FROM metadata_cache 
 		WHERE property_id = ? AND cache_type = ?
@@ -160,38 +146,166 @@ func (c *CacheClient) GetCachedMetadata(ctx context.Context, propertyID, cacheTy
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.incrementMisses()
-			return false, nil // Cache miss
+			return false, false, nil // Cache miss
 		}
-		return false, fmt.Errorf("failed to query cache: %w", err)
+		return false, false, fmt.Errorf("failed to query cache: %w", err)
 	}
 
 	// Check if cache has expired
-	if time.Now().After(expiresAt) {
+	stale = time.Now().After(expiresAt)
+	if stale && !keepStale {
 		c.incrementMisses()
 		// Clean up expired entry
-		c.db.ExecContext(ctx, `
+		c.exec(ctx, `
 			DELETE FROM metadata_cache 
 			WHERE property_id = ? AND cache_type = ?
 		`, propertyID, cacheType)
-		return false, nil
+		return false, false, nil
 	}
 
 	// Update last accessed time
-	c.db.ExecContext(ctx, `
+	c.exec(ctx, `
 		UPDATE metadata_cache 
-		SET last_accessed = NOW() 
+		SET last_accessed = ? 
 		WHERE property_id = ? AND cache_type = ?
-	`, propertyID, cacheType)
+	`, dbNow(), propertyID, cacheType)
 
 	// Unmarshal and return
 	if err := json.Unmarshal([]byte(data), result); err != nil {
-		return false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		return false, false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+	}
+
+	c.incrementHits()
+	return true, stale, nil
+}
+
+// CacheAnalysis stores an analysis result until expiresAt. The hash must
+// cover every parameter that shapes the result; params are stored alongside
+// for inspection.
+func (c *CacheClient) CacheAnalysis(ctx context.Context, analysisHash, propertyID, analysisType string, params, data interface{}, expiresAt time.Time) error {
+	jsonParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis params: %w", err)
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+
+	_, err = c.exec(ctx, `
+		INSERT OR REPLACE INTO analysis_cache
+		(analysis_hash, property_id, analysis_type, params, data, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, analysisHash, propertyID, analysisType, string(jsonParams), string(jsonData), dbNow(), expiresAt.UTC())
+
+	return err
+}
+
+// GetCachedAnalysis retrieves a cached analysis result if valid
+func (c *CacheClient) GetCachedAnalysis(ctx context.Context, analysisHash string, result interface{}) (bool, error) {
+	found, _, err := c.lookupAnalysis(ctx, analysisHash, result, false)
+	return found, err
+}
+
+// GetStaleAnalysis retrieves a cached analysis result even if it has
+// expired, reporting whether it is stale
+func (c *CacheClient) GetStaleAnalysis(ctx context.Context, analysisHash string, result interface{}) (bool, bool, error) {
+	return c.lookupAnalysis(ctx, analysisHash, result, true)
+}
+
+func (c *CacheClient) lookupAnalysis(ctx context.Context, analysisHash string, result interface{}, keepStale bool) (found, stale bool, err error) {
+	var data string
+	var expiresAt time.Time
+
+	err = c.db.QueryRowContext(ctx, `
+		SELECT data, expires_at
+		FROM analysis_cache
+		WHERE analysis_hash = ?
+	`, analysisHash).Scan(&data, &expiresAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.incrementMisses()
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	stale = time.Now().After(expiresAt)
+	if stale && !keepStale {
+		c.incrementMisses()
+		c.exec(ctx, `DELETE FROM analysis_cache WHERE analysis_hash = ?`, analysisHash)
+		return false, false, nil
+	}
+
+	c.exec(ctx, `
+		UPDATE analysis_cache
+		SET last_accessed = ?
+		WHERE analysis_hash = ?
+	`, dbNow(), analysisHash)
+
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return false, false, fmt.Errorf("failed to unmarshal cached analysis: %w", err)
+	}
+
+	c.incrementHits()
+	return true, stale, nil
+}
+
+// CacheListing stores an Admin API listing with TTL
+func (c *CacheClient) CacheListing(ctx context.Context, key string, data interface{}, ttlHours int) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal listing: %w", err)
+	}
+
+	now := dbNow()
+	_, err = c.exec(ctx, `
+		INSERT OR REPLACE INTO listing_cache
+		(cache_key, data, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, key, string(jsonData), now, now.Add(time.Duration(ttlHours)*time.Hour))
+
+	return err
+}
+
+// GetCachedListing retrieves an unexpired Admin API listing and reports when
+// it was cached
+func (c *CacheClient) GetCachedListing(ctx context.Context, key string, result interface{}) (cachedAt time.Time, found bool, err error) {
+	var data string
+	var expiresAt time.Time
+	err = c.db.QueryRowContext(ctx, `
+		SELECT data, created_at, expires_at
+		FROM listing_cache
+		WHERE cache_key = ?
+	`, key).Scan(&data, &cachedAt, &expiresAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.incrementMisses()
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	if time.Now().After(expiresAt) {
+		c.incrementMisses()
+		return time.Time{}, false, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to unmarshal cached listing: %w", err)
 	}
 
 	c.incrementHits()
-	return true, nil
+	return cachedAt, true, nil
 }
 
+// keptResults selects the results that are never cleaned up or replaced by
+// a newer fetch: those named, viewed, tagged or annotated
+const keptResults = `SELECT query_id FROM named_tables UNION SELECT query_id FROM result_views
+	UNION SELECT query_id FROM result_tags UNION SELECT query_id FROM result_notes`
+
 // CacheQuery stores query results with optional TTL
 func (c *CacheClient) CacheQuery(ctx context.Context, queryID, propertyID, queryHash string, queryParams, resultData interface{}, rowCount int, ttlHours *int) error {
 	jsonParams, err := json.Marshal(queryParams)
@@ -206,72 +320,300 @@ func (c *CacheClient) CacheQuery(ctx context.Context, queryID, propertyID, query
 
 	var expiresAt *time.Time
 	if ttlHours != nil {
-		expires := time.Now().Add(time.Duration(*ttlHours) * time.Hour)
+		expires := dbNow().Add(time.Duration(*ttlHours) * time.Hour)
 		expiresAt = &expires
 	}
 
-	_, err = c.db.ExecContext(ctx, `
+	// created_at is when the results were fetched; it dates them for --max-age
+	_, err = c.exec(ctx, `
 		INSERT OR REPLACE INTO query_cache 
-		(query_id, property_id, query_hash, query_params, result_data, row_count, expires_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, queryID, propertyID, queryHash, string(jsonParams), string(jsonData), rowCount, expiresAt)
+		(query_id, property_id, query_hash, query_params, result_data, row_count, created_at, expires_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, queryID, propertyID, queryHash, string(jsonParams), string(jsonData), rowCount, dbNow(), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// Drop older results for the same query unless they are kept
+	_, err = c.exec(ctx, `
+		DELETE FROM query_cache
+		WHERE query_hash = ? AND query_id <> ?
+		  AND query_id NOT IN (` + keptResults + `)
+	`, queryHash, queryID)
 
 	return err
 }
 
-// GetCachedQuery retrieves cached query results if valid
-func (c *CacheClient) GetCachedQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (bool, error) {
+// GetCachedQuery retrieves cached query results if valid, along with the
+// query ID they were stored under
+func (c *CacheClient) GetCachedQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (string, bool, error) {
+	queryID, found, _, err := c.lookupQuery(ctx, queryHash, resultData, false)
+	return queryID, found, err
+}
+
+// GetStaleQuery retrieves cached query results even if they have expired,
+// reporting whether they are stale. Expired entries are kept so they can be
+// refreshed under the same query ID.
+func (c *CacheClient) GetStaleQuery(ctx context.Context, queryHash string, queryParams, resultData interface{}) (string, bool, bool, error) {
+	return c.lookupQuery(ctx, queryHash, resultData, true)
+}
+
+func (c *CacheClient) lookupQuery(ctx context.Context, queryHash string, resultData interface{}, keepStale bool) (queryID string, found, stale bool, err error) {
 	var data string
 	var expiresAt *time.Time
 	var rowCount int
 
-	err := c.db.QueryRowContext(ctx, `
-		SELECT result_data, row_count, expires_at
+	err = c.db.QueryRowContext(ctx, `
+		SELECT query_id, result_data, row_count, expires_at
 		FROM query_cache 
 		WHERE query_hash = ?
-	`, queryHash).Scan(&data, &rowCount, &expiresAt)
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1
+	`, queryHash).Scan(&queryID, &data, &rowCount, &expiresAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.incrementMisses()
-			return false, nil
+			return "", false, false, nil
 		}
-		return false, fmt.Errorf("failed to query cache: %w", err)
+		return "", false, false, fmt.Errorf("failed to query cache: %w", err)
 	}
 
 	// Check expiration
-	if expiresAt != nil && time.Now().After(*expiresAt) {
+	stale = expiresAt != nil && time.Now().After(*expiresAt)
+	if stale && !keepStale {
 		c.incrementMisses()
 		// Clean up expired entry
-		c.db.ExecContext(ctx, `DELETE FROM query_cache WHERE query_hash = ?`, queryHash)
-		return false, nil
+		c.exec(ctx, `
+			DELETE FROM query_cache
+			WHERE query_hash = ? AND query_id NOT IN (` + keptResults + `)
+		`, queryHash)
+		return "", false, false, nil
 	}
 
 	// Update last accessed
-	c.db.ExecContext(ctx, `
+	c.exec(ctx, `
 		UPDATE query_cache 
-		SET last_accessed = NOW() 
+		SET last_accessed = ? 
 		WHERE query_hash = ?
-	`, queryHash)
+	`, dbNow(), queryHash)
 
 	// Unmarshal result
 	if err := json.Unmarshal([]byte(data), resultData); err != nil {
-		return false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+		return "", false, false, fmt.Errorf("failed to unmarshal cached data: %w", err)
 	}
 
 	c.incrementHits()
-	return true, nil
+	return queryID, true, stale, nil
+}
+
+// QueryCachedAt returns when a stored query's results were cached
+func (c *CacheClient) QueryCachedAt(ctx context.Context, queryID string) (time.Time, bool, error) {
+	var createdAt time.Time
+	err := c.db.QueryRowContext(ctx, `
+		SELECT created_at
+		FROM query_cache
+		WHERE query_id = ?
+	`, queryID).Scan(&createdAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to query cache: %w", err)
+	}
+	return createdAt, true, nil
+}
+
+// GetQuery loads a stored query by ID regardless of expiry, decoding its
+// parameters and results. Returns nil when the query ID is unknown.
+func (c *CacheClient) GetQuery(ctx context.Context, queryID string, queryParams, resultData interface{}) (*config.CachedQuery, error) {
+	var entry config.CachedQuery
+	var params, data string
+
+	err := c.db.QueryRowContext(ctx, `
+		SELECT query_id, property_id, query_hash, query_params, result_data, row_count,
+		       created_at, expires_at, last_accessed
+		FROM query_cache
+		WHERE query_id = ?
+	`, queryID).Scan(&entry.QueryID, &entry.PropertyID, &entry.QueryHash, &params, &data, &entry.RowCount,
+		&entry.CreatedAt, &entry.ExpiresAt, &entry.LastAccessed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(params), queryParams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached query params: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), resultData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached data: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// ListQueries returns stored queries for a property, or every property when
+// propertyID is empty, newest first, with their tags and notes. A non-empty
+// tag lists only results carrying it, and a non-zero since only those
+// fetched since then.
+func (c *CacheClient) ListQueries(ctx context.Context, propertyID, tag string, since time.Time, limit int) ([]config.CachedQuery, error) {
+	query := `
+		SELECT qc.query_id, qc.property_id, qc.query_hash, qc.row_count,
+		       qc.created_at, qc.expires_at, qc.last_accessed,
+		       COALESCE(nt.table_name, ''), COALESCE(nt.description, '')
+		FROM query_cache qc
+		LEFT JOIN named_tables nt ON nt.query_id = qc.query_id
+		WHERE (? = '' OR qc.property_id = ?)
+	`
+	args := []interface{}{propertyID, propertyID}
+	if !since.IsZero() {
+		query += " AND qc.created_at >= ?"
+		args = append(args, since.UTC())
+	}
+	if tag != "" {
+		query += " AND qc.query_id IN (SELECT query_id FROM result_tags WHERE tag = ?)"
+		args = append(args, tag)
+	}
+	query += " ORDER BY qc.created_at DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []config.CachedQuery
+	for rows.Next() {
+		var entry config.CachedQuery
+		err := rows.Scan(
+			&entry.QueryID, &entry.PropertyID, &entry.QueryHash, &entry.RowCount,
+			&entry.CreatedAt, &entry.ExpiresAt, &entry.LastAccessed,
+			&entry.TableName, &entry.Description,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	annotations, err := c.listAnnotations(ctx, `WHERE query_id IN (SELECT query_id FROM query_cache WHERE ? = '' OR property_id = ?)`, propertyID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if found, ok := annotations[entries[i].QueryID]; ok {
+			entries[i].Tags, entries[i].Note = found.Tags, found.Note
+		}
+	}
+	return entries, nil
 }
 
-// CreateNamedTable creates a named reference to query results
-func (c *CacheClient) CreateNamedTable(ctx context.Context, tableName, propertyID, queryID, description string) error {
-	_, err := c.db.ExecContext(ctx, `
+// ListFreshQueryParams returns the stored requests of a property's unexpired
+// queries, newest first
+func (c *CacheClient) ListFreshQueryParams(ctx context.Context, propertyID string) ([]config.CachedQueryParams, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT query_id, query_params, row_count, created_at, expires_at
+		FROM query_cache
+		WHERE property_id = ?
+		ORDER BY created_at DESC
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []config.CachedQueryParams
+	for rows.Next() {
+		var entry config.CachedQueryParams
+		var expiresAt *time.Time
+		if err := rows.Scan(&entry.QueryID, &entry.Params, &entry.RowCount, &entry.CreatedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		// Expiry is checked here like lookupQuery does
+		if expiresAt != nil && time.Now().After(*expiresAt) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ErrNamedTableExists is returned by CreateNamedTable when the name already
+// refers to another result
+var ErrNamedTableExists = errors.New("named table already exists")
+
+// NameConflict says what CreateNamedTable does when the name is taken
+type NameConflict int
+
+const (
+	NameConflictFail      NameConflict = iota // Return ErrNamedTableExists
+	NameConflictOverwrite                     // Point the name at the new result
+	NameConflictKeep                          // Leave the name pointing at its current result
+)
+
+// CreateNamedTable names a query result and returns the query ID the name
+// referred to before, if any. Naming the result a name already refers to is
+// a no-op, so reruns served from cache don't conflict. When an overwrite
+// leaves the previous result unnamed and a newer result of the same query
+// exists, the previous result is deleted, as it was only kept for the name.
+func (c *CacheClient) CreateNamedTable(ctx context.Context, tableName, propertyID, queryID, description string, onConflict NameConflict) (string, error) {
+	var previous string
+	err := c.db.QueryRowContext(ctx, `
+		SELECT query_id FROM named_tables WHERE table_name = ?
+	`, tableName).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to look up named table: %w", err)
+	}
+
+	if previous != "" && previous != queryID {
+		switch onConflict {
+		case NameConflictKeep:
+			return previous, nil
+		case NameConflictFail:
+			return previous, fmt.Errorf("%w: '%s' refers to result %s", ErrNamedTableExists, tableName, previous)
+		}
+	}
+
+	replaced := previous != "" && previous != queryID
+	if replaced {
+		// DuckDB's INSERT OR REPLACE leaves foreign key columns unchanged, so
+		// the old mapping is deleted first
+		if _, err := c.exec(ctx, `DELETE FROM named_tables WHERE table_name = ?`, tableName); err != nil {
+			return previous, fmt.Errorf("failed to replace named table: %w", err)
+		}
+	}
+	_, err = c.exec(ctx, `
 		INSERT OR REPLACE INTO named_tables 
-		(table_name, property_id, query_id, description) 
-		VALUES (?, ?, ?, ?)
-	`, tableName, propertyID, queryID, description)
+		(table_name, property_id, query_id, description, created_at, last_accessed) 
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, tableName, propertyID, queryID, description, dbNow(), dbNow())
+	if err != nil {
+		return previous, err
+	}
 
-	return err
+	if replaced {
+		_, err = c.exec(ctx, `
+			DELETE FROM query_cache
+			WHERE query_id = ?
+			  AND query_id NOT IN (` + keptResults + `)
+			  AND EXISTS (
+				SELECT 1 FROM query_cache newer
+				WHERE newer.query_hash = query_cache.query_hash AND newer.created_at > query_cache.created_at
+			  )
+		`, previous)
+	}
+	return previous, err
 }
 
 // ListNamedTables returns all named tables for a property
@@ -305,6 +647,138 @@ func (c *CacheClient) ListNamedTables(ctx context.Context, propertyID string) ([
 	return tables, nil
 }
 
+// ListNamedTableQueries returns a property's named tables with the requests
+// their results answer, newest result first
+func (c *CacheClient) ListNamedTableQueries(ctx context.Context, propertyID string) ([]config.NamedTableQuery, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT nt.table_name, nt.query_id, qc.query_params, qc.row_count, qc.created_at
+		FROM named_tables nt
+		JOIN query_cache qc ON nt.query_id = qc.query_id
+		WHERE nt.property_id = ?
+		ORDER BY qc.created_at DESC
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []config.NamedTableQuery
+	for rows.Next() {
+		var table config.NamedTableQuery
+		if err := rows.Scan(&table.Name, &table.QueryID, &table.Params, &table.RowCount, &table.CreatedAt); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+// NamedTableResults maps the names of a property's named tables, or of every
+// property's when propertyID is empty, to the cached results they refer to
+func (c *CacheClient) NamedTableResults(ctx context.Context, propertyID string) (map[string]string, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT nt.table_name, nt.query_id
+		FROM named_tables nt
+		JOIN query_cache qc ON nt.query_id = qc.query_id
+		WHERE ? = '' OR nt.property_id = ?
+	`, propertyID, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make(map[string]string)
+	for rows.Next() {
+		var name, queryID string
+		if err := rows.Scan(&name, &queryID); err != nil {
+			return nil, err
+		}
+		results[name] = queryID
+	}
+
+	return results, rows.Err()
+}
+
+// ErrResultViewExists is returned by CreateResultView when the name is taken
+var ErrResultViewExists = errors.New("result view already exists")
+
+// CreateResultView saves a view over a cached result. The source result is
+// kept by cleanup for as long as the view exists.
+func (c *CacheClient) CreateResultView(ctx context.Context, view *config.ResultView) error {
+	existing, err := c.GetResultView(ctx, view.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: '%s' is a view of result %s", ErrResultViewExists, view.Name, existing.QueryID)
+	}
+
+	view.CreatedAt = dbNow()
+
+	_, err = c.exec(ctx, `
+		INSERT INTO result_views 
+		(view_name, property_id, query_id, where_clause, order_by, description, created_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, view.Name, view.PropertyID, view.QueryID, view.Where, view.OrderBy, view.Description, view.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save result view: %w", err)
+	}
+	return nil
+}
+
+// GetResultView returns the view with this name, or nil if there is none
+func (c *CacheClient) GetResultView(ctx context.Context, name string) (*config.ResultView, error) {
+	var view config.ResultView
+	err := c.db.QueryRowContext(ctx, `
+		SELECT view_name, property_id, query_id, where_clause, order_by, description, created_at
+		FROM result_views 
+		WHERE view_name = ?
+	`, name).Scan(&view.Name, &view.PropertyID, &view.QueryID, &view.Where, &view.OrderBy, &view.Description, &view.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up result view: %w", err)
+	}
+	return &view, nil
+}
+
+// ListResultViews returns the views of a property's results, newest first
+func (c *CacheClient) ListResultViews(ctx context.Context, propertyID string) ([]config.ResultView, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT view_name, property_id, query_id, where_clause, order_by, description, created_at
+		FROM result_views 
+		WHERE property_id = ?
+		ORDER BY created_at DESC
+	`, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list result views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []config.ResultView
+	for rows.Next() {
+		var view config.ResultView
+		if err := rows.Scan(&view.Name, &view.PropertyID, &view.QueryID, &view.Where, &view.OrderBy, &view.Description, &view.CreatedAt); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+// DeleteResultView removes a view, reporting whether it existed. The source
+// result is left for cleanup to expire as usual.
+func (c *CacheClient) DeleteResultView(ctx context.Context, name string) (bool, error) {
+	result, err := c.exec(ctx, `DELETE FROM result_views WHERE view_name = ?`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete result view: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
 // GetCacheStats returns cache performance statistics
 func (c *CacheClient) GetCacheStats(ctx context.Context) (*config.CacheStats, error) {
 	var stats config.CacheStats
@@ -335,57 +809,242 @@ func (c *CacheClient) GetCacheStats(ctx context.Context) (*config.CacheStats, er
 	`).Scan(&dbSize)
 
 	stats.EntriesCount = int(dbSize)
+
+	stats.SchemaVersion, err = migrate.CurrentVersion(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
 	
 	return &stats, nil
 }
 
 // CleanupExpiredEntries removes expired cache entries
 func (c *CacheClient) CleanupExpiredEntries(ctx context.Context) (int, error) {
+	now := dbNow()
+
 	// Clean metadata cache
-	result1, err := c.db.ExecContext(ctx, `
+	result1, err := c.exec(ctx, `
 		DELETE FROM metadata_cache 
-		WHERE expires_at < NOW()
-	`)
+		WHERE expires_at < ?
+	`, now)
 	if err != nil {
 		return 0, err
 	}
 
 	deleted1, _ := result1.RowsAffected()
 
-	// Clean query cache
-	result2, err := c.db.ExecContext(ctx, `
+	// Clean query cache; named, viewed, tagged and annotated results are kept
+	result2, err := c.exec(ctx, `
 		DELETE FROM query_cache 
-		WHERE expires_at IS NOT NULL AND expires_at < NOW()
-	`)
+		WHERE expires_at IS NOT NULL AND expires_at < ?
+		  AND query_id NOT IN (` + keptResults + `)
+	`, now)
 	if err != nil {
 		return int(deleted1), err
 	}
 
 	deleted2, _ := result2.RowsAffected()
 
+	// Clean Admin API listings
+	result3, err := c.exec(ctx, `
+		DELETE FROM listing_cache 
+		WHERE expires_at < ?
+	`, now)
+	if err != nil {
+		return int(deleted1 + deleted2), err
+	}
+
+	deleted3, _ := result3.RowsAffected()
+
+	// Clean analysis results
+	result4, err := c.exec(ctx, `
+		DELETE FROM analysis_cache 
+		WHERE expires_at < ?
+	`, now)
+	if err != nil {
+		return int(deleted1 + deleted2 + deleted3), err
+	}
+
+	deleted4, _ := result4.RowsAffected()
+
+	// Clean remembered rejections
+	result5, err := c.exec(ctx, `
+		DELETE FROM rejected_queries
+		WHERE expires_at < ?
+	`, now)
+	if err != nil {
+		return int(deleted1 + deleted2 + deleted3 + deleted4), err
+	}
+
+	deleted5, _ := result5.RowsAffected()
+
 	// Update cleanup timestamp
-	_, err = c.db.ExecContext(ctx, `
+	_, err = c.exec(ctx, `
 		UPDATE cache_stats 
-		SET last_cleanup = NOW(), updated_at = NOW() 
+		SET last_cleanup = ?, updated_at = ? 
 		WHERE preset_name = ?
-	`, c.presetName)
+	`, now, now, c.presetName)
+
+	return int(deleted1 + deleted2 + deleted3 + deleted4 + deleted5), err
+}
+
+// GetETag returns the stored ETag and response body for an Admin API path
+func (c *CacheClient) GetETag(ctx context.Context, key string) (etag string, body []byte, found bool, err error) {
+	var data string
+	err = c.db.QueryRowContext(ctx, `
+		SELECT etag, body
+		FROM etag_cache
+		WHERE cache_key = ?
+	`, key).Scan(&etag, &data)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, false, nil
+		}
+		return "", nil, false, fmt.Errorf("failed to query cache: %w", err)
+	}
+	return etag, []byte(data), true, nil
+}
+
+// StoreETag keeps an Admin API response body under its ETag
+func (c *CacheClient) StoreETag(ctx context.Context, key, etag string, body []byte) error {
+	_, err := c.exec(ctx, `
+		INSERT OR REPLACE INTO etag_cache
+		(cache_key, etag, body, stored_at)
+		VALUES (?, ?, ?, ?)
+	`, key, etag, string(body), dbNow())
+
+	return err
+}
+
+// RecordQueryExecution appends one query execution to the query log
+func (c *CacheClient) RecordQueryExecution(ctx context.Context, entry config.QueryLogEntry) error {
+	dimensions, err := json.Marshal(entry.Dimensions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dimensions: %w", err)
+	}
+	metrics, err := json.Marshal(entry.Metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	_, err = c.exec(ctx, `
+		INSERT INTO query_log
+		(property_id, query_hash, executed_at, execution_ms, row_count, from_cache, tokens_consumed, dimensions, metrics, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.PropertyID, entry.QueryHash, entry.ExecutedAt.UTC(), entry.ExecutionMs, entry.RowCount,
+		entry.FromCache, entry.TokensConsumed, string(dimensions), string(metrics), entry.Error)
+
+	return err
+}
+
+// ListQueryLog returns logged query executions for a property since the given
+// time, oldest first
+func (c *CacheClient) ListQueryLog(ctx context.Context, propertyID string, since time.Time) ([]config.QueryLogEntry, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT property_id, query_hash, executed_at, execution_ms, row_count, from_cache,
+		       tokens_consumed, dimensions, metrics, COALESCE(error, '')
+		FROM query_log
+		WHERE property_id = ? AND executed_at >= ?
+		ORDER BY executed_at
+	`, propertyID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return int(deleted1 + deleted2), err
+	var entries []config.QueryLogEntry
+	for rows.Next() {
+		var entry config.QueryLogEntry
+		var dimensions, metrics string
+		err := rows.Scan(
+			&entry.PropertyID, &entry.QueryHash, &entry.ExecutedAt, &entry.ExecutionMs, &entry.RowCount,
+			&entry.FromCache, &entry.TokensConsumed, &dimensions, &metrics, &entry.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(dimensions), &entry.Dimensions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal logged dimensions: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metrics), &entry.Metrics); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal logged metrics: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// RecordAudit appends one Admin API write to the audit log
+func (c *CacheClient) RecordAudit(ctx context.Context, record config.AuditRecord) error {
+	_, err := c.exec(ctx, `
+		INSERT INTO audit_log
+		(recorded_at, preset_name, user_name, command, property_id, method, resource, request, before_state, after_state, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, record.RecordedAt.UTC(), record.Preset, record.User, record.Command, record.PropertyID, record.Method,
+		record.Resource, record.Request, record.Before, record.After, record.Error)
+
+	return err
+}
+
+// ListAuditLog returns audit records since the given time, newest first. An
+// empty propertyID lists every property; limit 0 means no limit.
+func (c *CacheClient) ListAuditLog(ctx context.Context, propertyID string, since time.Time, limit int) ([]config.AuditRecord, error) {
+	query := `
+		SELECT recorded_at, preset_name, user_name, command, property_id, method, resource,
+		       COALESCE(request, ''), COALESCE(before_state, ''), COALESCE(after_state, ''), COALESCE(error, '')
+		FROM audit_log
+		WHERE recorded_at >= ? AND (? = '' OR property_id = ?)
+		ORDER BY recorded_at DESC`
+	args := []interface{}{since.UTC(), propertyID, propertyID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []config.AuditRecord
+	for rows.Next() {
+		var record config.AuditRecord
+		err := rows.Scan(
+			&record.RecordedAt, &record.Preset, &record.User, &record.Command, &record.PropertyID, &record.Method,
+			&record.Resource, &record.Request, &record.Before, &record.After, &record.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
 }
 
 // Helper methods for cache statistics
 func (c *CacheClient) incrementHits() {
-	c.db.Exec(`
+	c.exec(context.Background(), `
 		UPDATE cache_stats 
-		SET total_hits = total_hits + 1, updated_at = NOW() 
+		SET total_hits = total_hits + 1, updated_at = ? 
 		WHERE preset_name = ?
-	`, c.presetName)
+	`, dbNow(), c.presetName)
 }
 
 func (c *CacheClient) incrementMisses() {
-	c.db.Exec(`
+	c.exec(context.Background(), `
 		UPDATE cache_stats 
-		SET total_misses = total_misses + 1, updated_at = NOW() 
+		SET total_misses = total_misses + 1, updated_at = ? 
 		WHERE preset_name = ?
-	`, c.presetName)
-}
\ No newline at end of file
+	`, dbNow(), c.presetName)
+}
+
+// dbNow is the current time as stored in the cache. Timestamps are bound in
+// UTC: SQLite compares them as text, which only orders correctly when they
+// share an offset.
+func dbNow() time.Time {
+	return time.Now().UTC()
+}
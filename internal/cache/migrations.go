@@ -0,0 +1,199 @@
+package cache
+
+import "ga4admin/internal/migrate"
+
+// migrations are the cache schema changes, oldest first. Append new ones;
+// never edit a released migration, since existing caches have already run it.
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "initial cache tables",
+		Statements: []string{
+			// Metadata cache table
+			`CREATE TABLE IF NOT EXISTS metadata_cache (
+				property_id VARCHAR PRIMARY KEY,
+				cache_type VARCHAR NOT NULL,  -- 'dimensions', 'metrics', 'events'
+				data TEXT NOT NULL,           -- JSON-encoded metadata
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				expires_at TIMESTAMP NOT NULL,
+				last_accessed TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+
+			// Query results cache table
+			`CREATE TABLE IF NOT EXISTS query_cache (
+				query_id VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				query_hash VARCHAR NOT NULL,     -- Hash of query parameters
+				query_params TEXT NOT NULL,     -- JSON-encoded query parameters
+				result_data TEXT NOT NULL,      -- JSON-encoded query results
+				row_count INTEGER NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				expires_at TIMESTAMP,           -- NULL = never expires
+				last_accessed TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+
+			// Named tables for query results
+			`CREATE TABLE IF NOT EXISTS named_tables (
+				table_name VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				query_id VARCHAR NOT NULL,
+				description TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				last_accessed TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (query_id) REFERENCES query_cache(query_id)
+			)`,
+
+			// Admin API listings (accounts, properties per account)
+			`CREATE TABLE IF NOT EXISTS listing_cache (
+				cache_key VARCHAR PRIMARY KEY,  -- 'accounts' or 'properties/<account-id>'
+				data TEXT NOT NULL,             -- JSON-encoded listing
+				created_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+
+			// Admin API GET responses with their ETags, for conditional requests
+			`CREATE TABLE IF NOT EXISTS etag_cache (
+				cache_key VARCHAR PRIMARY KEY,  -- request path, e.g. '/accounts'
+				etag VARCHAR NOT NULL,
+				body TEXT NOT NULL,             -- response body as returned
+				stored_at TIMESTAMP NOT NULL
+			)`,
+
+			// Query execution log for 'query stats'
+			`CREATE TABLE IF NOT EXISTS query_log (
+				property_id VARCHAR NOT NULL,
+				query_hash VARCHAR NOT NULL,
+				executed_at TIMESTAMP NOT NULL,
+				execution_ms BIGINT NOT NULL,
+				row_count INTEGER NOT NULL,
+				from_cache BOOLEAN NOT NULL,
+				tokens_consumed INTEGER NOT NULL DEFAULT 0,
+				dimensions TEXT NOT NULL,       -- JSON-encoded field names
+				metrics TEXT NOT NULL,          -- JSON-encoded field names
+				error TEXT
+			)`,
+
+			// Admin API writes for 'audit list'; never cleared with the cache
+			`CREATE TABLE IF NOT EXISTS audit_log (
+				recorded_at TIMESTAMP NOT NULL,
+				preset_name VARCHAR NOT NULL,
+				user_name VARCHAR NOT NULL,
+				command VARCHAR NOT NULL,
+				property_id VARCHAR NOT NULL,
+				method VARCHAR NOT NULL,
+				resource VARCHAR NOT NULL,
+				request TEXT,                   -- JSON body sent
+				before_state TEXT,              -- JSON resource before the write
+				after_state TEXT,               -- JSON resource GA4 returned
+				error TEXT
+			)`,
+
+			// Cache statistics table
+			`CREATE TABLE IF NOT EXISTS cache_stats (
+				preset_name VARCHAR PRIMARY KEY,
+				total_hits INTEGER DEFAULT 0,
+				total_misses INTEGER DEFAULT 0,
+				last_cleanup TIMESTAMP,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "analysis cache",
+		Statements: []string{
+			// Analysis results, keyed by a hash of the analysis type and
+			// every parameter that shapes the result
+			`CREATE TABLE IF NOT EXISTS analysis_cache (
+				analysis_hash VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				analysis_type VARCHAR NOT NULL,  -- e.g. 'events'
+				params TEXT NOT NULL,            -- JSON-encoded analysis parameters
+				data TEXT NOT NULL,              -- JSON-encoded result
+				created_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				last_accessed TIMESTAMP
+			)`,
+
+			// Event analyses used to share metadata_cache, whose key is the
+			// property alone, so they replaced the property's metadata
+			`DELETE FROM metadata_cache WHERE cache_type LIKE 'events%'`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "result views",
+		Statements: []string{
+			// Saved filters and orderings over a cached result, applied
+			// when the view is read
+			`CREATE TABLE IF NOT EXISTS result_views (
+				view_name VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				query_id VARCHAR NOT NULL,
+				where_clause TEXT NOT NULL,      -- DuckDB expression, '' for all rows
+				order_by TEXT NOT NULL,          -- e.g. 'sessions DESC, country'
+				description TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "rollups",
+		Statements: []string{
+			// Aggregates of cached results, each materialized as a table
+			// named rollup_<name>
+			`CREATE TABLE IF NOT EXISTS rollups (
+				rollup_name VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				source VARCHAR NOT NULL,            -- named table, view or result ID
+				group_by TEXT NOT NULL,             -- JSON list of dimensions
+				metrics TEXT NOT NULL,              -- JSON list of metrics and aggregations
+				refresh_every_seconds BIGINT NOT NULL,  -- 0 = never re-fetch the source
+				description TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				refreshed_at TIMESTAMP NOT NULL,
+				source_query_id VARCHAR NOT NULL,   -- result the table was built from
+				source_fetched_at TIMESTAMP NOT NULL,
+				row_count INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "result tags and notes",
+		Statements: []string{
+			// Labels that keep results findable and out of cleanup
+			`CREATE TABLE IF NOT EXISTS result_tags (
+				query_id VARCHAR NOT NULL,
+				tag VARCHAR NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (query_id, tag)
+			)`,
+			`CREATE TABLE IF NOT EXISTS result_notes (
+				query_id VARCHAR PRIMARY KEY,
+				note TEXT NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     6,
+		Description: "rejected queries",
+		Statements: []string{
+			// Report requests GA4 rejected with INVALID_ARGUMENT, briefly
+			// remembered so repeats fail without calling the API
+			`CREATE TABLE IF NOT EXISTS rejected_queries (
+				query_hash VARCHAR PRIMARY KEY,
+				property_id VARCHAR NOT NULL,
+				api VARCHAR NOT NULL,
+				status_code INTEGER NOT NULL,
+				status VARCHAR NOT NULL,
+				message TEXT NOT NULL,
+				rejected_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+		},
+	},
+}
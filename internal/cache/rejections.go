@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ga4admin/internal/config"
+)
+
+// RecordRejectedQuery remembers that GA4 rejected a report request until
+// rejection.ExpiresAt, replacing any earlier rejection of it
+func (c *CacheClient) RecordRejectedQuery(ctx context.Context, rejection config.RejectedQuery) error {
+	// DuckDB can't delete and re-insert a primary key in one transaction,
+	// so an earlier rejection is updated in place
+	err := c.transact(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE rejected_queries
+			SET property_id = ?, api = ?, status_code = ?, status = ?, message = ?, rejected_at = ?, expires_at = ?
+			WHERE query_hash = ?
+		`, rejection.PropertyID, rejection.API, rejection.StatusCode, rejection.Status, rejection.Message,
+			rejection.RejectedAt.UTC(), rejection.ExpiresAt.UTC(), rejection.QueryHash)
+		if err != nil {
+			return err
+		}
+		if updated, _ := result.RowsAffected(); updated > 0 {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO rejected_queries
+			(query_hash, property_id, api, status_code, status, message, rejected_at, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, rejection.QueryHash, rejection.PropertyID, rejection.API, rejection.StatusCode, rejection.Status,
+			rejection.Message, rejection.RejectedAt.UTC(), rejection.ExpiresAt.UTC())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record rejected query: %w", err)
+	}
+	return nil
+}
+
+// GetRejectedQuery returns the unexpired rejection of a report request, or
+// nil when GA4 hasn't rejected it recently
+func (c *CacheClient) GetRejectedQuery(ctx context.Context, queryHash string) (*config.RejectedQuery, error) {
+	var rejection config.RejectedQuery
+	err := c.db.QueryRowContext(ctx, `
+		SELECT query_hash, property_id, api, status_code, status, message, rejected_at, expires_at
+		FROM rejected_queries
+		WHERE query_hash = ? AND expires_at > ?
+	`, queryHash, dbNow()).Scan(&rejection.QueryHash, &rejection.PropertyID, &rejection.API, &rejection.StatusCode,
+		&rejection.Status, &rejection.Message, &rejection.RejectedAt, &rejection.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rejected queries: %w", err)
+	}
+	return &rejection, nil
+}
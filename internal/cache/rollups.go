@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ga4admin/internal/config"
+)
+
+// ErrRollupExists is returned by CreateRollup when the name is taken
+var ErrRollupExists = errors.New("rollup already exists")
+
+// RollupColumn is a column of a rollup table and its SQL type
+type RollupColumn struct {
+	Name string
+	Type string // VARCHAR, DATE, BIGINT or DOUBLE
+}
+
+// RollupTable is the table a rollup is materialized as
+func RollupTable(name string) string {
+	return "rollup_" + name
+}
+
+// CreateRollup saves a new rollup together with its first build
+func (c *CacheClient) CreateRollup(ctx context.Context, rollup *config.Rollup, columns []RollupColumn, rows [][]interface{}) error {
+	existing, err := c.GetRollup(ctx, rollup.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: '%s' rolls up %s", ErrRollupExists, rollup.Name, existing.Source)
+	}
+
+	rollup.CreatedAt = dbNow()
+	return c.StoreRollup(ctx, rollup, columns, rows)
+}
+
+// StoreRollup saves a rollup's definition and replaces its table in one transaction,
+// so readers see either the previous build or the new one
+func (c *CacheClient) StoreRollup(ctx context.Context, rollup *config.Rollup, columns []RollupColumn, rows [][]interface{}) error {
+	groupBy, err := json.Marshal(rollup.GroupBy)
+	if err != nil {
+		return err
+	}
+	metrics, err := json.Marshal(rollup.Metrics)
+	if err != nil {
+		return err
+	}
+	rollup.RefreshedAt = dbNow()
+	rollup.RowCount = len(rows)
+
+	table := quoteIdent(RollupTable(rollup.Name))
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = quoteIdent(column.Name) + " " + column.Type
+	}
+
+	err = c.transact(ctx, func(tx *sql.Tx) error {
+		// DuckDB rejects deleting and re-inserting a key in one transaction,
+		// so existing definitions are updated in place
+		updated, err := tx.ExecContext(ctx, `
+			UPDATE rollups
+			SET property_id = ?, source = ?, group_by = ?, metrics = ?, refresh_every_seconds = ?, description = ?,
+			    refreshed_at = ?, source_query_id = ?, source_fetched_at = ?, row_count = ?
+			WHERE rollup_name = ?
+		`, rollup.PropertyID, rollup.Source, string(groupBy), string(metrics), int64(rollup.RefreshEvery/time.Second), rollup.Description,
+			rollup.RefreshedAt, rollup.SourceQueryID, rollup.SourceFetchedAt, rollup.RowCount, rollup.Name)
+		if err != nil {
+			return err
+		}
+		if n, _ := updated.RowsAffected(); n == 0 {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO rollups
+				(rollup_name, property_id, source, group_by, metrics, refresh_every_seconds, description,
+				 created_at, refreshed_at, source_query_id, source_fetched_at, row_count)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, rollup.Name, rollup.PropertyID, rollup.Source, string(groupBy), string(metrics), int64(rollup.RefreshEvery/time.Second),
+				rollup.Description, rollup.CreatedAt, rollup.RefreshedAt, rollup.SourceQueryID, rollup.SourceFetchedAt, rollup.RowCount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+table); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s (%s)`, table, strings.Join(definitions, ", "))); err != nil {
+			return err
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		insert, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s VALUES (%s)`, table, placeholders))
+		if err != nil {
+			return err
+		}
+		defer insert.Close()
+		for _, row := range rows {
+			if _, err := insert.ExecContext(ctx, row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rollup '%s': %w", rollup.Name, err)
+	}
+	return nil
+}
+
+// GetRollup returns the rollup with this name, or nil if there is none
+func (c *CacheClient) GetRollup(ctx context.Context, name string) (*config.Rollup, error) {
+	rollups, err := c.queryRollups(ctx, `WHERE rollup_name = ?`, name)
+	if err != nil || len(rollups) == 0 {
+		return nil, err
+	}
+	return &rollups[0], nil
+}
+
+// ListRollups returns the rollups of a property's results, or of every
+// property when propertyID is empty, by name
+func (c *CacheClient) ListRollups(ctx context.Context, propertyID string) ([]config.Rollup, error) {
+	if propertyID == "" {
+		return c.queryRollups(ctx, `ORDER BY rollup_name`)
+	}
+	return c.queryRollups(ctx, `WHERE property_id = ? ORDER BY rollup_name`, propertyID)
+}
+
+func (c *CacheClient) queryRollups(ctx context.Context, clause string, args ...interface{}) ([]config.Rollup, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT rollup_name, property_id, source, group_by, metrics, refresh_every_seconds, description,
+		       created_at, refreshed_at, source_query_id, source_fetched_at, row_count
+		FROM rollups `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []config.Rollup
+	for rows.Next() {
+		var rollup config.Rollup
+		var groupBy, metrics string
+		var refreshEvery int64
+		err := rows.Scan(&rollup.Name, &rollup.PropertyID, &rollup.Source, &groupBy, &metrics, &refreshEvery, &rollup.Description,
+			&rollup.CreatedAt, &rollup.RefreshedAt, &rollup.SourceQueryID, &rollup.SourceFetchedAt, &rollup.RowCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rollups: %w", err)
+		}
+		if err := json.Unmarshal([]byte(groupBy), &rollup.GroupBy); err != nil {
+			return nil, fmt.Errorf("failed to read rollup '%s': %w", rollup.Name, err)
+		}
+		if err := json.Unmarshal([]byte(metrics), &rollup.Metrics); err != nil {
+			return nil, fmt.Errorf("failed to read rollup '%s': %w", rollup.Name, err)
+		}
+		rollup.RefreshEvery = time.Duration(refreshEvery) * time.Second
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+// ReadRollupTable returns a rollup table's columns and its rows as text,
+// in the order they were built
+func (c *CacheClient) ReadRollupTable(ctx context.Context, rollup *config.Rollup) ([]string, [][]string, error) {
+	columns := append(append([]string(nil), rollup.GroupBy...), rollupMetricNames(rollup)...)
+	selects := make([]string, len(columns))
+	for i, column := range columns {
+		selects[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", quoteIdent(column))
+	}
+
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %s ORDER BY rowid`, strings.Join(selects, ", "), quoteIdent(RollupTable(rollup.Name))))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read rollup '%s': %w", rollup.Name, err)
+	}
+	defer rows.Close()
+
+	var values [][]string
+	for rows.Next() {
+		cells := make([]sql.NullString, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range cells {
+			targets[i] = &cells[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, nil, fmt.Errorf("failed to read rollup '%s': %w", rollup.Name, err)
+		}
+		row := make([]string, len(columns))
+		for i, cell := range cells {
+			row[i] = cell.String
+		}
+		values = append(values, row)
+	}
+	return columns, values, rows.Err()
+}
+
+// DeleteRollup removes a rollup and its table, reporting whether it existed
+func (c *CacheClient) DeleteRollup(ctx context.Context, name string) (bool, error) {
+	var deleted int64
+	err := c.transact(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM rollups WHERE rollup_name = ?`, name)
+		if err != nil {
+			return err
+		}
+		deleted, _ = result.RowsAffected()
+		_, err = tx.ExecContext(ctx, `DROP TABLE IF EXISTS `+quoteIdent(RollupTable(name)))
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete rollup: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// LookupNamedTable returns the result a named table refers to and its
+// description; the query ID is empty if there is no such table
+func (c *CacheClient) LookupNamedTable(ctx context.Context, name string) (queryID, description string, err error) {
+	var desc sql.NullString
+	err = c.db.QueryRowContext(ctx, `SELECT query_id, description FROM named_tables WHERE table_name = ?`, name).Scan(&queryID, &desc)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up named table: %w", err)
+	}
+	return queryID, desc.String, nil
+}
+
+func rollupMetricNames(rollup *config.Rollup) []string {
+	names := make([]string, len(rollup.Metrics))
+	for i, metric := range rollup.Metrics {
+		names[i] = metric.Name
+	}
+	return names
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
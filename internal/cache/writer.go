@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// errClientClosed is returned for writes issued after Close
+var errClientClosed = errors.New("cache client is closed")
+
+// writeRequest is one statement queued for the writer goroutine
+type writeRequest struct {
+	ctx    context.Context
+	query  string
+	args   []interface{}
+	tx     func(*sql.Tx) error // Set for transactions queued by transact
+	result chan writeResult
+}
+
+type writeResult struct {
+	res sql.Result
+	err error
+}
+
+// startWriter pins one connection for writes and starts the goroutine that
+// applies them in order. DuckDB aborts concurrent updates to the same row
+// with a transaction conflict, so concurrent executors sharing a preset
+// cache would otherwise silently lose cache writes and stats increments.
+// Reads keep using the connection pool.
+func (c *CacheClient) startWriter() error {
+	conn, err := c.db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to open writer connection: %w", err)
+	}
+
+	c.writes = make(chan writeRequest)
+	c.writerDone = make(chan struct{})
+	go c.runWriter(conn)
+	return nil
+}
+
+func (c *CacheClient) runWriter(conn *sql.Conn) {
+	defer close(c.writerDone)
+	defer conn.Close()
+
+	for req := range c.writes {
+		if err := req.ctx.Err(); err != nil {
+			req.result <- writeResult{err: err}
+			continue
+		}
+		if req.tx != nil {
+			req.result <- writeResult{err: runTx(req.ctx, conn, req.tx)}
+			continue
+		}
+		res, err := conn.ExecContext(req.ctx, req.query, req.args...)
+		req.result <- writeResult{res: res, err: err}
+	}
+}
+
+func runTx(ctx context.Context, conn *sql.Conn, fn func(*sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// exec queues a write statement and waits for it to be applied
+func (c *CacheClient) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.write(ctx, writeRequest{query: query, args: args})
+}
+
+// transact queues fn to run in a transaction on the writer connection and
+// waits for it to commit or roll back
+func (c *CacheClient) transact(ctx context.Context, fn func(*sql.Tx) error) error {
+	_, err := c.write(ctx, writeRequest{tx: fn})
+	return err
+}
+
+func (c *CacheClient) write(ctx context.Context, req writeRequest) (sql.Result, error) {
+	// Holding the read lock until the result arrives lets Close wait for
+	// in-flight writes before stopping the writer
+	c.writeMu.RLock()
+	defer c.writeMu.RUnlock()
+	if c.closed {
+		return nil, errClientClosed
+	}
+
+	req.ctx = ctx
+	req.result = make(chan writeResult, 1)
+	select {
+	case c.writes <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	result := <-req.result
+	return result.res, result.err
+}
+
+// stopWriter waits for queued writes to finish and stops the writer goroutine
+func (c *CacheClient) stopWriter() {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed || c.writes == nil {
+		c.closed = true
+		return
+	}
+
+	c.closed = true
+	close(c.writes)
+	<-c.writerDone
+}
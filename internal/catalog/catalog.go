@@ -0,0 +1,215 @@
+package catalog
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/query"
+)
+
+// catalogJSON is the standard (non-custom) field list from the Data API's
+// universal metadata endpoint, properties/0/metadata. Regenerate it with
+// 'ga4admin fields generate --output internal/catalog/catalog.json'.
+//
+//go:embed catalog.json
+var catalogJSON []byte
+
+// Field kinds
+const (
+	KindDimension = "dimension"
+	KindMetric    = "metric"
+)
+
+// Compatibility groups. Item-scoped dimensions only combine with item-scoped
+// metrics; standard (event, session and user scoped) fields combine freely.
+const (
+	GroupStandard = "standard"
+	GroupItem     = "item"
+)
+
+// Field is one standard GA4 dimension or metric
+type Field struct {
+	Kind               string   `json:"kind"`
+	APIName            string   `json:"api_name"`
+	UIName             string   `json:"ui_name"`
+	Description        string   `json:"description"`
+	Category           string   `json:"category"`
+	Type               string   `json:"type,omitempty"`       // Metrics only, e.g. TYPE_INTEGER
+	Expression         string   `json:"expression,omitempty"` // Metrics derived from other metrics
+	DeprecatedAPINames []string `json:"deprecated_api_names,omitempty"`
+	CompatibilityGroup string   `json:"compatibility_group"`
+}
+
+// Catalog indexes fields by API name, case-insensitively, including
+// deprecated names
+type Catalog struct {
+	fields []Field
+	byName map[string]int
+}
+
+var (
+	bundled     *Catalog
+	bundledErr  error
+	bundledOnce sync.Once
+)
+
+// Load returns the catalog bundled into the binary
+func Load() (*Catalog, error) {
+	bundledOnce.Do(func() {
+		var metadata api.MetadataResponse
+		if err := json.Unmarshal(catalogJSON, &metadata); err != nil {
+			bundledErr = fmt.Errorf("failed to parse bundled field catalog: %w", err)
+			return
+		}
+		bundled = FromMetadata(&metadata)
+	})
+	return bundled, bundledErr
+}
+
+// FromMetadata builds a catalog from Data API metadata, skipping custom definitions
+func FromMetadata(metadata *api.MetadataResponse) *Catalog {
+	c := &Catalog{byName: make(map[string]int)}
+
+	for _, dimension := range metadata.Dimensions {
+		if dimension.CustomDefinition {
+			continue
+		}
+		c.add(Field{
+			Kind:               KindDimension,
+			APIName:            dimension.APIName,
+			UIName:             dimension.UIName,
+			Description:        dimension.Description,
+			Category:           dimension.Category,
+			DeprecatedAPINames: dimension.DeprecatedAPINames,
+		})
+	}
+	for _, metric := range metadata.Metrics {
+		if metric.CustomDefinition {
+			continue
+		}
+		c.add(Field{
+			Kind:               KindMetric,
+			APIName:            metric.APIName,
+			UIName:             metric.UIName,
+			Description:        metric.Description,
+			Category:           metric.Category,
+			Type:               metric.Type,
+			Expression:         metric.Expression,
+			DeprecatedAPINames: metric.DeprecatedAPINames,
+		})
+	}
+
+	return c
+}
+
+func (c *Catalog) add(field Field) {
+	field.CompatibilityGroup = CompatibilityGroup(field.Kind, field.APIName)
+	c.fields = append(c.fields, field)
+	index := len(c.fields) - 1
+
+	c.byName[strings.ToLower(field.APIName)] = index
+	for _, deprecated := range field.DeprecatedAPINames {
+		if _, exists := c.byName[strings.ToLower(deprecated)]; !exists {
+			c.byName[strings.ToLower(deprecated)] = index
+		}
+	}
+}
+
+// Lookup finds a field by API name or deprecated API name, ignoring case
+func (c *Catalog) Lookup(name string) (Field, bool) {
+	index, found := c.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !found {
+		return Field{}, false
+	}
+	return c.fields[index], true
+}
+
+// Fields returns every field, dimensions first
+func (c *Catalog) Fields() []Field {
+	return append([]Field(nil), c.fields...)
+}
+
+// Search returns fields whose API name, UI name or category contains text,
+// ignoring case. API-name matches come first.
+func (c *Catalog) Search(text string) []Field {
+	needle := strings.ToLower(strings.TrimSpace(text))
+	var byAPIName, other []Field
+	for _, field := range c.fields {
+		switch {
+		case strings.Contains(strings.ToLower(field.APIName), needle):
+			byAPIName = append(byAPIName, field)
+		case strings.Contains(strings.ToLower(field.UIName), needle),
+			strings.Contains(strings.ToLower(field.Category), needle):
+			other = append(other, field)
+		}
+	}
+	return append(byAPIName, other...)
+}
+
+// CompatibilityGroup classifies a field using the same item-scope rules the
+// query validator applies
+func CompatibilityGroup(kind, apiName string) string {
+	if kind == KindDimension && query.IsItemScopedDimension(apiName) {
+		return GroupItem
+	}
+	if kind == KindMetric && query.IsItemScopedMetric(apiName) {
+		return GroupItem
+	}
+	return GroupStandard
+}
+
+// CompatibilityNote explains which fields a field can be queried with
+func CompatibilityNote(field Field) string {
+	switch {
+	case field.Kind == KindDimension && field.CompatibilityGroup == GroupItem:
+		return "Item-scoped: combine only with item-scoped metrics such as itemRevenue, itemsPurchased or itemsViewed"
+	case field.Kind == KindDimension:
+		return "Standard: combines with standard and item-scoped metrics"
+	case field.CompatibilityGroup == GroupItem:
+		return "Item-scoped: combines with item-scoped and standard dimensions"
+	default:
+		return "Standard: combines with standard dimensions, not with item-scoped dimensions"
+	}
+}
+
+// WriteCatalog saves the standard fields from metadata in the bundled
+// catalog format, sorted by API name. It returns the dimension and metric counts.
+func WriteCatalog(outputPath string, metadata *api.MetadataResponse) (int, int, error) {
+	standard := api.MetadataResponse{Name: metadata.Name}
+	for _, dimension := range metadata.Dimensions {
+		if !dimension.CustomDefinition {
+			standard.Dimensions = append(standard.Dimensions, dimension)
+		}
+	}
+	for _, metric := range metadata.Metrics {
+		if !metric.CustomDefinition {
+			standard.Metrics = append(standard.Metrics, metric)
+		}
+	}
+	sort.Slice(standard.Dimensions, func(i, j int) bool {
+		return standard.Dimensions[i].APIName < standard.Dimensions[j].APIName
+	})
+	sort.Slice(standard.Metrics, func(i, j int) bool {
+		return standard.Metrics[i].APIName < standard.Metrics[j].APIName
+	})
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(standard); err != nil {
+		return 0, 0, fmt.Errorf("failed to marshal field catalog: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data.Bytes(), 0644); err != nil {
+		return 0, 0, fmt.Errorf("failed to write field catalog: %w", err)
+	}
+
+	return len(standard.Dimensions), len(standard.Metrics), nil
+}
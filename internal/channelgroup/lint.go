@@ -0,0 +1,243 @@
+package channelgroup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// The Data API accepts at most 9 dimensions per report
+const maxReportDimensions = 9
+
+// Severity of a lint issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The rule is broken and GA4 can't apply it as intended
+	SeverityWarning Severity = "warning" // The rule works but is probably not what was meant
+)
+
+// Issue is one problem found in a channel group
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule,omitempty"` // Channel display name; empty for group-level issues
+	Message  string   `json:"message"`
+}
+
+// RuleCoverage is the traffic a rule receives when rules are applied in order
+type RuleCoverage struct {
+	Rule          string  `json:"rule"`
+	Condition     string  `json:"condition"`
+	Sessions      int64   `json:"sessions"`       // Sessions assigned to this channel
+	MatchSessions int64   `json:"match_sessions"` // Sessions the condition matches, ignoring earlier rules
+	Share         float64 `json:"share"`          // Percent of all sessions assigned to this channel
+	Checked       bool    `json:"checked"`        // False when the rule reads fields the Data API doesn't expose
+}
+
+// LintResult summarizes a channel group's rule problems and traffic coverage
+type LintResult struct {
+	PropertyID         string         `json:"property_id"`
+	GroupID            string         `json:"group_id"`
+	GroupName          string         `json:"group_name"`
+	Days               int            `json:"days"`
+	Issues             []Issue        `json:"issues"`
+	Coverage           []RuleCoverage `json:"coverage"`
+	TotalSessions      int64          `json:"total_sessions"`
+	UnassignedSessions int64          `json:"unassigned_sessions"`
+	LintedAt           time.Time      `json:"linted_at"`
+}
+
+// HasErrors reports whether any issue has error severity
+func (r *LintResult) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type parsedRule struct {
+	name      string
+	condition Condition
+	checked   bool
+}
+
+// Lint parses every rule in the group, reports structural problems, and then
+// replays the rules against session source/medium/campaign values from the
+// last `days` days to find rules that match nothing or are always shadowed.
+// Reports go through the data client, so repeated lints reuse cached values.
+func Lint(ctx context.Context, dataClient api.DataService, propertyID string, group api.ChannelGroup, days int) (*LintResult, error) {
+	if days <= 0 || days > 365 {
+		return nil, fmt.Errorf("days must be between 1 and 365")
+	}
+
+	result := &LintResult{
+		PropertyID: propertyID,
+		GroupID:    group.ID(),
+		GroupName:  group.DisplayName,
+		Days:       days,
+		LintedAt:   time.Now(),
+	}
+
+	if len(group.GroupingRules) == 0 {
+		result.addIssue(SeverityError, "", "channel group has no rules")
+		return result, nil
+	}
+
+	// Parse rules and check their structure
+	var rules []parsedRule
+	seen := make(map[string]bool)
+	dimensions := make(map[string]bool)
+	for i, rule := range group.GroupingRules {
+		name := rule.DisplayName
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			result.addIssue(SeverityError, name, "rule has no channel name")
+		}
+		if seen[strings.ToLower(name)] {
+			result.addIssue(SeverityWarning, name, "channel name is used by more than one rule; only the first can receive traffic")
+		}
+		seen[strings.ToLower(name)] = true
+
+		condition, err := ParseExpression(rule.Expression)
+		if err != nil {
+			result.addIssue(SeverityError, name, err.Error())
+			continue
+		}
+
+		checked := true
+		for _, field := range condition.Fields() {
+			switch {
+			case !KnownField(field):
+				result.addIssue(SeverityWarning, name, fmt.Sprintf("unknown field %q", field))
+				checked = false
+			case FieldDimension(field) == "":
+				checked = false
+			default:
+				dimensions[FieldDimension(field)] = true
+			}
+		}
+		rules = append(rules, parsedRule{name: name, condition: condition, checked: checked})
+	}
+
+	if len(dimensions) > maxReportDimensions {
+		result.addIssue(SeverityWarning, "", fmt.Sprintf("rules read %d different fields; traffic check skipped (Data API limit is %d)", len(dimensions), maxReportDimensions))
+		return result, nil
+	}
+
+	rows, err := fieldValues(ctx, dataClient, propertyID, dimensions, days)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay rules in order: the first matching rule claims each row
+	coverage := make([]RuleCoverage, len(rules))
+	for i, rule := range rules {
+		coverage[i] = RuleCoverage{Rule: rule.name, Condition: rule.condition.String(), Checked: rule.checked}
+	}
+	for _, row := range rows {
+		result.TotalSessions += row.sessions
+		assigned := false
+		for i, rule := range rules {
+			if !rule.checked || !rule.condition.Matches(row.values) {
+				continue
+			}
+			coverage[i].MatchSessions += row.sessions
+			if !assigned {
+				coverage[i].Sessions += row.sessions
+				assigned = true
+			}
+		}
+		if !assigned {
+			result.UnassignedSessions += row.sessions
+		}
+	}
+
+	for i := range coverage {
+		if result.TotalSessions > 0 {
+			coverage[i].Share = float64(coverage[i].Sessions) / float64(result.TotalSessions) * 100
+		}
+		switch {
+		case !coverage[i].Checked:
+		case coverage[i].MatchSessions == 0:
+			result.addIssue(SeverityWarning, coverage[i].Rule, fmt.Sprintf("matches no sessions in the last %d days", days))
+		case coverage[i].Sessions == 0:
+			result.addIssue(SeverityWarning, coverage[i].Rule, fmt.Sprintf("never receives traffic: all %d matching sessions are claimed by earlier rules", coverage[i].MatchSessions))
+		}
+	}
+	result.Coverage = coverage
+
+	return result, nil
+}
+
+func (r *LintResult) addIssue(severity Severity, rule, message string) {
+	r.Issues = append(r.Issues, Issue{Severity: severity, Rule: rule, Message: message})
+}
+
+type valueRow struct {
+	values   map[string]string // Keyed by channel group field name
+	sessions int64
+}
+
+// fieldValues fetches session counts for every combination of the given dimensions
+func fieldValues(ctx context.Context, dataClient api.DataService, propertyID string, dimensions map[string]bool, days int) ([]valueRow, error) {
+	if len(dimensions) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	request := &api.RunReportRequest{
+		Property: propertyID,
+		Metrics:  []api.Metric{{Name: "sessions"}},
+		DateRanges: []api.DateRange{
+			{
+				StartDate: fmt.Sprintf("%ddaysAgo", days),
+				EndDate:   "today",
+			},
+		},
+		Limit: 100000,
+	}
+	for _, name := range names {
+		request.Dimensions = append(request.Dimensions, api.Dimension{Name: name})
+	}
+
+	response, err := dataClient.RunReport(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dimension values: %w", err)
+	}
+
+	// Map report columns back to the channel group fields that read them
+	fieldsByDimension := make(map[string][]string)
+	for field, dimension := range fieldDimensions {
+		if dimension != "" {
+			fieldsByDimension[dimension] = append(fieldsByDimension[dimension], field)
+		}
+	}
+
+	rows := make([]valueRow, 0, len(response.Rows))
+	for _, reportRow := range response.Rows {
+		row := valueRow{values: make(map[string]string)}
+		for i, value := range reportRow.DimensionValues {
+			for _, field := range fieldsByDimension[names[i]] {
+				row.values[field] = value.Value
+			}
+		}
+		if len(reportRow.MetricValues) > 0 {
+			row.sessions, _ = strconv.ParseInt(reportRow.MetricValues[0].Value, 10, 64)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,275 @@
+package channelgroup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ga4admin/internal/api"
+)
+
+// Channel group filter fields and the Data API session dimensions holding
+// the same values. Fields without a dimension can't be checked against traffic.
+var fieldDimensions = map[string]string{
+	"eachScopeDefaultChannelGroup": "sessionDefaultChannelGroup",
+	"eachScopeSource":              "sessionSource",
+	"eachScopeMedium":              "sessionMedium",
+	"eachScopeSourcePlatform":      "sessionSourcePlatform",
+	"eachScopeCampaignName":        "sessionCampaignName",
+	"eachScopeCampaignId":          "sessionCampaignId",
+	"eachScopeSourceCategory":      "",
+	"eachScopeCountryId":           "countryId",
+	"eachScopeRegionId":            "regionId",
+	"eachScopeDeviceCategory":      "deviceCategory",
+}
+
+// Human-readable labels for field names in rendered conditions
+var fieldLabels = map[string]string{
+	"eachScopeDefaultChannelGroup": "default channel group",
+	"eachScopeSource":              "source",
+	"eachScopeMedium":              "medium",
+	"eachScopeSourcePlatform":      "source platform",
+	"eachScopeCampaignName":        "campaign",
+	"eachScopeCampaignId":          "campaign ID",
+	"eachScopeSourceCategory":      "source category",
+	"eachScopeCountryId":           "country ID",
+	"eachScopeRegionId":            "region ID",
+	"eachScopeDeviceCategory":      "device category",
+}
+
+// Condition is a parsed channel group rule expression
+type Condition interface {
+	// Matches evaluates the condition against field values keyed by field name
+	Matches(values map[string]string) bool
+	// String renders the condition as a readable expression
+	String() string
+	// Fields lists every field name the condition reads
+	Fields() []string
+}
+
+type andCondition []Condition
+type orCondition []Condition
+type notCondition struct{ inner Condition }
+
+type filterCondition struct {
+	field     string
+	matchType string
+	value     string
+	values    []string // Set for in-list filters
+	regex     *regexp.Regexp
+}
+
+// ParseExpression turns an Admin API filter expression into a Condition.
+// Matching is case-insensitive, as it is in GA4.
+func ParseExpression(expr api.ChannelGroupFilterExpression) (Condition, error) {
+	switch {
+	case expr.AndGroup != nil:
+		children, err := parseList(expr.AndGroup.FilterExpressions)
+		if err != nil {
+			return nil, err
+		}
+		return andCondition(children), nil
+	case expr.OrGroup != nil:
+		children, err := parseList(expr.OrGroup.FilterExpressions)
+		if err != nil {
+			return nil, err
+		}
+		return orCondition(children), nil
+	case expr.NotExpression != nil:
+		inner, err := ParseExpression(*expr.NotExpression)
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner: inner}, nil
+	case expr.Filter != nil:
+		return parseFilter(*expr.Filter)
+	default:
+		return nil, fmt.Errorf("empty filter expression")
+	}
+}
+
+func parseList(exprs []api.ChannelGroupFilterExpression) ([]Condition, error) {
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("empty filter group")
+	}
+	children := make([]Condition, 0, len(exprs))
+	for _, expr := range exprs {
+		child, err := ParseExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+func parseFilter(filter api.ChannelGroupFilter) (Condition, error) {
+	if filter.FieldName == "" {
+		return nil, fmt.Errorf("filter is missing a field name")
+	}
+
+	if filter.InListFilter != nil {
+		if len(filter.InListFilter.Values) == 0 {
+			return nil, fmt.Errorf("in-list filter on %s has no values", fieldLabel(filter.FieldName))
+		}
+		return &filterCondition{field: filter.FieldName, matchType: "IN_LIST", values: filter.InListFilter.Values}, nil
+	}
+
+	if filter.StringFilter == nil {
+		return nil, fmt.Errorf("filter on %s has neither a string nor an in-list filter", fieldLabel(filter.FieldName))
+	}
+
+	condition := &filterCondition{
+		field:     filter.FieldName,
+		matchType: filter.StringFilter.MatchType,
+		value:     filter.StringFilter.Value,
+	}
+	if condition.value == "" {
+		return nil, fmt.Errorf("filter on %s has an empty value", fieldLabel(filter.FieldName))
+	}
+
+	switch condition.matchType {
+	case "EXACT", "BEGINS_WITH", "ENDS_WITH", "CONTAINS":
+	case "FULL_REGEXP", "PARTIAL_REGEXP":
+		pattern := condition.value
+		if condition.matchType == "FULL_REGEXP" {
+			pattern = "^(?:" + pattern + ")$"
+		}
+		regex, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q on %s: %w", condition.value, fieldLabel(filter.FieldName), err)
+		}
+		condition.regex = regex
+	default:
+		return nil, fmt.Errorf("unsupported match type %q on %s", condition.matchType, fieldLabel(filter.FieldName))
+	}
+
+	return condition, nil
+}
+
+// KnownField reports whether a filter field name is one the tool understands
+func KnownField(field string) bool {
+	_, ok := fieldDimensions[field]
+	return ok
+}
+
+// FieldDimension returns the Data API dimension holding a field's values, if any
+func FieldDimension(field string) string {
+	return fieldDimensions[field]
+}
+
+func fieldLabel(field string) string {
+	if label, ok := fieldLabels[field]; ok {
+		return label
+	}
+	return field
+}
+
+func (c andCondition) Matches(values map[string]string) bool {
+	for _, child := range c {
+		if !child.Matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c andCondition) String() string { return joinConditions(c, " AND ") }
+
+func (c andCondition) Fields() []string { return collectFields(c) }
+
+func (c orCondition) Matches(values map[string]string) bool {
+	for _, child := range c {
+		if child.Matches(values) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c orCondition) String() string { return joinConditions(c, " OR ") }
+
+func (c orCondition) Fields() []string { return collectFields(c) }
+
+func (c notCondition) Matches(values map[string]string) bool { return !c.inner.Matches(values) }
+
+func (c notCondition) String() string { return "NOT " + wrap(c.inner) }
+
+func (c notCondition) Fields() []string { return c.inner.Fields() }
+
+func (c *filterCondition) Matches(values map[string]string) bool {
+	actual := strings.ToLower(values[c.field])
+	expected := strings.ToLower(c.value)
+
+	switch c.matchType {
+	case "EXACT":
+		return actual == expected
+	case "BEGINS_WITH":
+		return strings.HasPrefix(actual, expected)
+	case "ENDS_WITH":
+		return strings.HasSuffix(actual, expected)
+	case "CONTAINS":
+		return strings.Contains(actual, expected)
+	case "FULL_REGEXP", "PARTIAL_REGEXP":
+		return c.regex.MatchString(values[c.field])
+	case "IN_LIST":
+		for _, value := range c.values {
+			if strings.ToLower(value) == actual {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *filterCondition) String() string {
+	label := fieldLabel(c.field)
+	switch c.matchType {
+	case "EXACT":
+		return fmt.Sprintf("%s = %q", label, c.value)
+	case "BEGINS_WITH":
+		return fmt.Sprintf("%s begins with %q", label, c.value)
+	case "ENDS_WITH":
+		return fmt.Sprintf("%s ends with %q", label, c.value)
+	case "CONTAINS":
+		return fmt.Sprintf("%s contains %q", label, c.value)
+	case "FULL_REGEXP":
+		return fmt.Sprintf("%s matches /%s/", label, c.value)
+	case "PARTIAL_REGEXP":
+		return fmt.Sprintf("%s contains /%s/", label, c.value)
+	default:
+		quoted := make([]string, len(c.values))
+		for i, value := range c.values {
+			quoted[i] = fmt.Sprintf("%q", value)
+		}
+		return fmt.Sprintf("%s in [%s]", label, strings.Join(quoted, ", "))
+	}
+}
+
+func (c *filterCondition) Fields() []string { return []string{c.field} }
+
+func joinConditions(children []Condition, separator string) string {
+	parts := make([]string, len(children))
+	for i, child := range children {
+		parts[i] = wrap(child)
+	}
+	return strings.Join(parts, separator)
+}
+
+// wrap parenthesizes nested groups so operator precedence stays unambiguous
+func wrap(c Condition) string {
+	switch c.(type) {
+	case andCondition, orCondition:
+		return "(" + c.String() + ")"
+	default:
+		return c.String()
+	}
+}
+
+func collectFields(children []Condition) []string {
+	var fields []string
+	for _, child := range children {
+		fields = append(fields, child.Fields()...)
+	}
+	return fields
+}
@@ -0,0 +1,200 @@
+package chart
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/query"
+)
+
+// Chart types
+const (
+	TypeAuto = "auto" // Line for time dimensions, bar otherwise
+	TypeLine = "line"
+	TypeBar  = "bar"
+)
+
+// timeLayouts parses GA4 time dimensions that map onto a calendar axis
+var timeLayouts = map[string]string{
+	"date":           "20060102",
+	"dateHour":       "2006010215",
+	"dateHourMinute": "200601021504",
+	"yearMonth":      "200601",
+	"year":           "2006",
+}
+
+// orderedDimensions have values that sort in time order as strings
+var orderedDimensions = map[string]bool{
+	"date": true, "dateHour": true, "dateHourMinute": true, "yearMonth": true, "year": true,
+	"yearWeek": true, "isoYearIsoWeek": true, "isoYear": true, "isoWeek": true,
+	"month": true, "week": true, "day": true, "dayOfWeek": true, "hour": true, "minute": true,
+	"nthDay": true, "nthWeek": true, "nthMonth": true, "nthYear": true, "nthHour": true, "nthMinute": true,
+}
+
+// Series is one metric's values, aligned with Data.Labels
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// Data is a result reduced to one dimension and one or more metrics
+type Data struct {
+	X       string
+	Labels  []string
+	Times   []time.Time // Set when X is a calendar dimension
+	Series  []Series
+	Ordered bool // X values are in time order
+}
+
+// FromResult takes the x dimension and y metrics (the first metric when ys is
+// empty) from a result. Rows that share an x value, because the result has
+// other dimensions, are summed. Time dimensions are sorted; other dimensions
+// keep the result's order.
+func FromResult(result *query.QueryResult, x string, ys []string) (*Data, error) {
+	xIndex := -1
+	var dimensions []string
+	for i, header := range result.DimensionHeaders {
+		dimensions = append(dimensions, header.Name)
+		if header.Name == x {
+			xIndex = i
+		}
+	}
+	if xIndex < 0 {
+		return nil, fmt.Errorf("result has no dimension '%s' (available: %s)", x, strings.Join(dimensions, ", "))
+	}
+
+	if len(result.MetricHeaders) == 0 {
+		return nil, fmt.Errorf("result has no metrics to chart")
+	}
+	if len(ys) == 0 {
+		ys = []string{result.MetricHeaders[0].Name}
+	}
+
+	var metrics []string
+	for _, header := range result.MetricHeaders {
+		metrics = append(metrics, header.Name)
+	}
+	yIndexes := make([]int, len(ys))
+	for i, y := range ys {
+		yIndexes[i] = -1
+		for j, name := range metrics {
+			if name == y {
+				yIndexes[i] = j
+			}
+		}
+		if yIndexes[i] < 0 {
+			return nil, fmt.Errorf("result has no metric '%s' (available: %s)", y, strings.Join(metrics, ", "))
+		}
+	}
+
+	data := &Data{X: x, Ordered: orderedDimensions[x]}
+	positions := make(map[string]int)
+	sums := make([][]float64, len(ys))
+	for _, row := range result.Rows {
+		if xIndex >= len(row.DimensionValues) {
+			continue
+		}
+		label := row.DimensionValues[xIndex].Value
+		position, ok := positions[label]
+		if !ok {
+			position = len(data.Labels)
+			positions[label] = position
+			data.Labels = append(data.Labels, label)
+			for i := range sums {
+				sums[i] = append(sums[i], 0)
+			}
+		}
+		for i, index := range yIndexes {
+			if index < len(row.MetricValues) {
+				value, _ := strconv.ParseFloat(row.MetricValues[index].Value, 64)
+				sums[i][position] += value
+			}
+		}
+	}
+	if len(data.Labels) == 0 {
+		return nil, fmt.Errorf("result has no rows to chart")
+	}
+
+	order := make([]int, len(data.Labels))
+	for i := range order {
+		order[i] = i
+	}
+	if data.Ordered {
+		labels := data.Labels
+		sort.SliceStable(order, func(a, b int) bool { return labels[order[a]] < labels[order[b]] })
+	}
+
+	sorted := make([]string, len(order))
+	for i, position := range order {
+		sorted[i] = data.Labels[position]
+	}
+	data.Labels = sorted
+	for i, y := range ys {
+		values := make([]float64, len(order))
+		for j, position := range order {
+			values[j] = sums[i][position]
+		}
+		data.Series = append(data.Series, Series{Name: y, Values: values})
+	}
+
+	if layout, ok := timeLayouts[x]; ok {
+		for _, label := range data.Labels {
+			t, err := time.Parse(layout, label)
+			if err != nil {
+				// e.g. "(other)"; fall back to an evenly spaced axis
+				data.Times = nil
+				break
+			}
+			data.Times = append(data.Times, t)
+		}
+	}
+
+	return data, nil
+}
+
+// DefaultX picks the dimension to chart a result by: the first time
+// dimension, else the first dimension. It returns "" for results without
+// dimensions.
+func DefaultX(result *query.QueryResult) string {
+	for _, header := range result.DimensionHeaders {
+		if orderedDimensions[header.Name] {
+			return header.Name
+		}
+	}
+	if len(result.DimensionHeaders) > 0 {
+		return result.DimensionHeaders[0].Name
+	}
+	return ""
+}
+
+// ResolveType turns TypeAuto into a concrete chart type for data
+func ResolveType(chartType string, data *Data) (string, error) {
+	switch chartType {
+	case TypeLine, TypeBar:
+		return chartType, nil
+	case TypeAuto, "":
+		if data.Ordered {
+			return TypeLine, nil
+		}
+		return TypeBar, nil
+	default:
+		return "", fmt.Errorf("unknown chart type '%s' (use %s, %s or %s)", chartType, TypeAuto, TypeLine, TypeBar)
+	}
+}
+
+// Truncate keeps the first n labels, e.g. the top categories of a bar chart
+func (d *Data) Truncate(n int) {
+	if n <= 0 || len(d.Labels) <= n {
+		return
+	}
+	d.Labels = d.Labels[:n]
+	if d.Times != nil {
+		d.Times = d.Times[:n]
+	}
+	for i := range d.Series {
+		d.Series[i].Values = d.Series[i].Values[:n]
+	}
+}
@@ -0,0 +1,194 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gochart "github.com/wcharczuk/go-chart/v2"
+)
+
+// Image size in pixels
+const (
+	imageWidth  = 1200
+	imageHeight = 500
+)
+
+// Image formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// WriteImage renders data to a PNG or SVG file, picked by the path's extension
+func WriteImage(data *Data, chartType, path string) error {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if format != FormatPNG && format != FormatSVG {
+		return fmt.Errorf("unsupported chart file '%s' (use .png or .svg)", path)
+	}
+
+	var image bytes.Buffer
+	if err := Render(data, chartType, format, &image); err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, image.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write chart file: %w", err)
+	}
+	return nil
+}
+
+// Render writes data to w as a PNG or SVG image
+func Render(data *Data, chartType, format string, w io.Writer) error {
+	var provider gochart.RendererProvider
+	switch format {
+	case FormatPNG:
+		provider = gochart.PNG
+	case FormatSVG:
+		provider = gochart.SVG
+	default:
+		return fmt.Errorf("unsupported image format '%s' (use %s or %s)", format, FormatPNG, FormatSVG)
+	}
+
+	for _, series := range data.Series {
+		if min, max, _ := summarize(series.Values); min == 0 && max == 0 {
+			return fmt.Errorf("%s is zero throughout; nothing to draw", series.Name)
+		}
+	}
+
+	// go-chart writes SVG text verbatim
+	if format == FormatSVG {
+		data = escapeText(data)
+	}
+
+	var err error
+	if chartType == TypeBar {
+		err = barChart(data).Render(provider, w)
+	} else {
+		err = lineChart(data).Render(provider, w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render chart: %w", err)
+	}
+	return nil
+}
+
+// escapeText copies data with labels and names escaped for XML
+func escapeText(data *Data) *Data {
+	escaped := *data
+	escaped.X = html.EscapeString(data.X)
+	escaped.Labels = make([]string, len(data.Labels))
+	for i, label := range data.Labels {
+		escaped.Labels[i] = html.EscapeString(label)
+	}
+	escaped.Series = make([]Series, len(data.Series))
+	for i, series := range data.Series {
+		escaped.Series[i] = Series{Name: html.EscapeString(series.Name), Values: series.Values}
+	}
+	return &escaped
+}
+
+func lineChart(data *Data) *gochart.Chart {
+	graph := &gochart.Chart{
+		Width:  imageWidth,
+		Height: imageHeight,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 40, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: gochart.XAxis{Name: data.X},
+		YAxis: gochart.YAxis{ValueFormatter: axisValue},
+	}
+
+	if data.Times != nil {
+		graph.XAxis.ValueFormatter = gochart.TimeDateValueFormatter
+		for _, series := range data.Series {
+			graph.Series = append(graph.Series, gochart.TimeSeries{
+				Name:    series.Name,
+				XValues: data.Times,
+				YValues: series.Values,
+			})
+		}
+	} else {
+		// Evenly spaced points labelled with the dimension values
+		xs := make([]float64, len(data.Labels))
+		for i := range xs {
+			xs[i] = float64(i)
+		}
+		labels := data.Labels
+		graph.XAxis.ValueFormatter = func(v interface{}) string {
+			if value, ok := v.(float64); ok {
+				if i := int(value); float64(i) == value && i >= 0 && i < len(labels) {
+					return labels[i]
+				}
+			}
+			return ""
+		}
+		for _, series := range data.Series {
+			graph.Series = append(graph.Series, gochart.ContinuousSeries{
+				Name:    series.Name,
+				XValues: xs,
+				YValues: series.Values,
+			})
+		}
+	}
+
+	// go-chart can't scale a flat line; anchor the axis at zero instead
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, series := range data.Series {
+		low, high, _ := summarize(series.Values)
+		min, max = math.Min(min, low), math.Max(max, high)
+	}
+	if min == max {
+		graph.YAxis.Range = &gochart.ContinuousRange{Min: math.Min(0, min), Max: math.Max(1, max*1.1)}
+	}
+
+	if len(data.Series) > 1 {
+		graph.Elements = []gochart.Renderable{gochart.Legend(graph)}
+	}
+	return graph
+}
+
+// barChart draws the first metric; go-chart bar charts have a single series
+func barChart(data *Data) *gochart.BarChart {
+	series := data.Series[0]
+	bars := make([]gochart.Value, len(series.Values))
+	for i, value := range series.Values {
+		bars[i] = gochart.Value{Label: data.Labels[i], Value: value}
+	}
+	low, high, _ := summarize(series.Values)
+
+	return &gochart.BarChart{
+		Title:    series.Name,
+		Width:    imageWidth,
+		Height:   imageHeight,
+		BarWidth: max(10, (imageWidth-100)/len(bars)-10),
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 60},
+		},
+		// Bars start at zero, which also gives equal bars a range to scale to
+		UseBaseValue: true,
+		BaseValue:    0,
+		YAxis: gochart.YAxis{
+			Range:          &gochart.ContinuousRange{Min: math.Min(0, low), Max: math.Max(0, high)},
+			ValueFormatter: axisValue,
+		},
+		Bars: bars,
+	}
+}
+
+func axisValue(v interface{}) string {
+	if value, ok := v.(float64); ok {
+		return formatValue(value)
+	}
+	return ""
+}
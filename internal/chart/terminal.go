@@ -0,0 +1,140 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ga4admin/internal/results"
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// RenderTerminal draws data as text lines no wider than about width columns:
+// a sparkline per metric for line charts, horizontal bars for bar charts
+func RenderTerminal(data *Data, chartType string, width int) []string {
+	if width < 20 {
+		width = 20
+	}
+	if chartType == TypeBar {
+		return renderBars(data, width)
+	}
+	return renderSparklines(data, width)
+}
+
+func renderSparklines(data *Data, width int) []string {
+	var lines []string
+	for i, series := range data.Series {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+
+		values := bucket(series.Values, width)
+		lines = append(lines, series.Name)
+		lines = append(lines, "  "+Sparkline(values))
+
+		// Axis labels under the first and last points
+		first, last := data.Labels[0], data.Labels[len(data.Labels)-1]
+		gap := utf8.RuneCountInString(Sparkline(values)) - utf8.RuneCountInString(first) - utf8.RuneCountInString(last)
+		if gap < 1 {
+			gap = 1
+		}
+		lines = append(lines, "  "+first+strings.Repeat(" ", gap)+last)
+
+		min, max, total := summarize(series.Values)
+		lines = append(lines, fmt.Sprintf("  min %s • max %s • last %s • total %s",
+			formatValue(min), formatValue(max), formatValue(series.Values[len(series.Values)-1]), formatValue(total)))
+	}
+	if len(data.Labels) > width {
+		lines = append(lines, "", fmt.Sprintf("%d %s values averaged into %d columns", len(data.Labels), data.X, width))
+	}
+	return lines
+}
+
+func renderBars(data *Data, width int) []string {
+	labelWidth := 0
+	for _, label := range data.Labels {
+		if n := results.DisplayWidth(label); n > labelWidth {
+			labelWidth = n
+		}
+	}
+	if labelWidth > 24 {
+		labelWidth = 24
+	}
+
+	var lines []string
+	for i, series := range data.Series {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, series.Name)
+
+		_, max, _ := summarize(series.Values)
+		barWidth := width - labelWidth - 3
+		for j, value := range series.Values {
+			length := 0
+			if max > 0 && value > 0 {
+				length = int(math.Round(value / max * float64(barWidth)))
+			}
+			lines = append(lines, fmt.Sprintf("  %s │%s %s", padLabel(data.Labels[j], labelWidth), strings.Repeat("█", length), formatValue(value)))
+		}
+	}
+	return lines
+}
+
+// Sparkline maps values onto block characters scaled between their min and max
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	min, max, _ := summarize(values)
+
+	var b strings.Builder
+	for _, value := range values {
+		level := len(sparkBlocks) / 2 // A flat series sits mid-height
+		if max > min {
+			level = int((value - min) / (max - min) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// bucket averages values down to at most width points
+func bucket(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+	buckets := make([]float64, width)
+	for i := range buckets {
+		start := i * len(values) / width
+		end := (i + 1) * len(values) / width
+		sum := 0.0
+		for _, value := range values[start:end] {
+			sum += value
+		}
+		buckets[i] = sum / float64(end-start)
+	}
+	return buckets
+}
+
+func summarize(values []float64) (min, max, total float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, value := range values {
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+		total += value
+	}
+	return min, max, total
+}
+
+func padLabel(label string, width int) string {
+	label = results.TruncateToWidth(label, width)
+	return label + strings.Repeat(" ", width-results.DisplayWidth(label))
+}
+
+func formatValue(value float64) string {
+	return results.FormatMetricValue(strconv.FormatFloat(value, 'f', -1, 64), true)
+}
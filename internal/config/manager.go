@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,10 +15,44 @@ import (
 const (
 	ConfigDirName  = ".ga4admin"
 	ConfigFileName = "config.yaml"
+
+	// HomeEnvVar moves the whole data directory (config, presets, tokens and
+	// caches), e.g. to keep work and personal setups or tests apart
+	HomeEnvVar = "GA4ADMIN_HOME"
 )
 
-// GetConfigDir returns the path to the config directory (~/.ga4admin)
+// homeOverride is set from the --home flag and wins over HomeEnvVar
+var homeOverride string
+
+// SetHomeDir makes the data directory dir instead of ~/.ga4admin for the
+// rest of the process. An empty dir restores the default.
+func SetHomeDir(dir string) error {
+	if dir == "" {
+		homeOverride = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid home directory '%s': %w", dir, err)
+	}
+	homeOverride = abs
+	return nil
+}
+
+// GetConfigDir returns the path to the data directory: the --home flag, else
+// $GA4ADMIN_HOME, else ~/.ga4admin
 func GetConfigDir() (string, error) {
+	if homeOverride != "" {
+		return homeOverride, nil
+	}
+	if dir := os.Getenv(HomeEnvVar); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s '%s': %w", HomeEnvVar, dir, err)
+		}
+		return abs, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
@@ -142,6 +179,247 @@ func HasClientCredentials() (bool, error) {
 	return clientID != "" && clientSecret != "", nil
 }
 
+// SetDataAPITransport sets the wire protocol used for GA4 Data API calls
+func SetDataAPITransport(transport string) error {
+	if transport != TransportREST && transport != TransportGRPC {
+		return fmt.Errorf("invalid transport '%s' (must be '%s' or '%s')", transport, TransportREST, TransportGRPC)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.DataAPITransport = transport
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// GetDataAPITransport returns the configured Data API transport, defaulting to REST
+func GetDataAPITransport() (string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.DataAPITransport == "" {
+		return TransportREST, nil
+	}
+
+	return config.DataAPITransport, nil
+}
+
+// SetCacheMode sets how expired cache entries are handled
+func SetCacheMode(mode string) error {
+	if mode != CacheModeStrict && mode != CacheModeSWR {
+		return fmt.Errorf("invalid cache mode '%s' (must be '%s' or '%s')", mode, CacheModeStrict, CacheModeSWR)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.CacheMode = mode
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// GetCacheMode returns the configured cache mode, defaulting to strict
+func GetCacheMode() (string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.CacheMode == "" {
+		return CacheModeStrict, nil
+	}
+
+	return config.CacheMode, nil
+}
+
+// SetAdminAPIVersion sets the preferred Admin API version
+func SetAdminAPIVersion(version string) error {
+	if version != AdminAPIAuto && version != AdminAPIV1Beta && version != AdminAPIV1Alpha {
+		return fmt.Errorf("invalid Admin API version '%s' (must be '%s', '%s' or '%s')", version, AdminAPIAuto, AdminAPIV1Beta, AdminAPIV1Alpha)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.AdminAPIVersion = version
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// GetAdminAPIVersion returns the preferred Admin API version, defaulting to auto
+func GetAdminAPIVersion() (string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if config.AdminAPIVersion == "" {
+		return AdminAPIAuto, nil
+	}
+
+	return config.AdminAPIVersion, nil
+}
+
+// SetAPIEndpoints sets the Admin and Data API endpoint overrides; empty
+// values restore the defaults
+func SetAPIEndpoints(adminEndpoint, dataEndpoint string) error {
+	for _, endpoint := range []string{adminEndpoint, dataEndpoint} {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.AdminAPIEndpoint = strings.TrimRight(adminEndpoint, "/")
+	config.DataAPIEndpoint = strings.TrimRight(dataEndpoint, "/")
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// GetAdminAPIEndpoint returns the Admin API base URL (scheme and host, no version)
+func GetAdminAPIEndpoint() (string, error) {
+	return resolveEndpoint(AdminAPIEndpointEnvVar, func(c *AppConfig) string { return c.AdminAPIEndpoint }, DefaultAdminAPIEndpoint)
+}
+
+// GetDataAPIEndpoint returns the Data API base URL (scheme and host, no version)
+func GetDataAPIEndpoint() (string, error) {
+	return resolveEndpoint(DataAPIEndpointEnvVar, func(c *AppConfig) string { return c.DataAPIEndpoint }, DefaultDataAPIEndpoint)
+}
+
+func resolveEndpoint(envVar string, fromConfig func(*AppConfig) string, defaultEndpoint string) (string, error) {
+	if endpoint := os.Getenv(envVar); endpoint != "" {
+		if err := validateEndpoint(endpoint); err != nil {
+			return "", fmt.Errorf("%s: %w", envVar, err)
+		}
+		return strings.TrimRight(endpoint, "/"), nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if endpoint := fromConfig(config); endpoint != "" {
+		return endpoint, nil
+	}
+
+	return defaultEndpoint, nil
+}
+
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid endpoint '%s' (expected e.g. https://analyticsdata.googleapis.com)", endpoint)
+	}
+
+	return nil
+}
+
+// SetAssistantSettings replaces the LLM endpoint settings in global config.
+// An empty endpoint turns the assistant off.
+func SetAssistantSettings(settings AssistantSettings) error {
+	if err := validateEndpoint(settings.Endpoint); err != nil {
+		return err
+	}
+	if settings.Endpoint != "" && settings.Model == "" {
+		return fmt.Errorf("an assistant endpoint needs a model")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.Assistant = settings
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetNetworkSettings replaces the network settings in global config
+func SetNetworkSettings(settings NetworkSettings) error {
+	for name, value := range map[string]string{
+		"request timeout": settings.RequestTimeout,
+		"command timeout": settings.CommandTimeout,
+	} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s '%s' (use a duration like 30s or 2m)", name, value)
+		}
+	}
+
+	if settings.MaxConnsPerHost < 0 {
+		return fmt.Errorf("invalid max connections per host %d (use 0 for the default)", settings.MaxConnsPerHost)
+	}
+
+	if settings.CABundle != "" {
+		if _, err := os.Stat(settings.CABundle); err != nil {
+			return fmt.Errorf("CA bundle not readable: %w", err)
+		}
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	config.Network = settings
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// GetNetworkSettings returns the configured network settings
+func GetNetworkSettings() (NetworkSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return NetworkSettings{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.Network, nil
+}
+
 // SetActivePreset sets the active preset name
 func SetActivePreset(presetName string) error {
 	config, err := LoadConfig()
@@ -166,4 +444,464 @@ func GetActivePreset() (string, error) {
 	}
 
 	return config.ActivePreset, nil
-}
\ No newline at end of file
+}
+// SetNotifier adds a notifier to global config, replacing any notifier with
+// the same name
+func SetNotifier(notifier NotifierConfig) error {
+	if err := validateNotifier(&notifier); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	replaced := false
+	for i := range config.Notifiers {
+		if config.Notifiers[i].Name == notifier.Name {
+			config.Notifiers[i] = notifier
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.Notifiers = append(config.Notifiers, notifier)
+	}
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveNotifier deletes a notifier from global config
+func RemoveNotifier(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Notifiers {
+		if config.Notifiers[i].Name == name {
+			config.Notifiers = append(config.Notifiers[:i], config.Notifiers[i+1:]...)
+			if err := SaveConfig(config); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("notifier '%s' does not exist", name)
+}
+
+// GetNotifier returns the notifier with the given name
+func GetNotifier(name string) (*NotifierConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Notifiers {
+		if config.Notifiers[i].Name == name {
+			return &config.Notifiers[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("notifier '%s' does not exist (see 'ga4admin config notifier list')", name)
+}
+
+// ListNotifiers returns all configured notifiers
+func ListNotifiers() ([]NotifierConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.Notifiers, nil
+}
+
+// validateNotifier checks a notifier and fills in defaults
+func validateNotifier(notifier *NotifierConfig) error {
+	if notifier.Name == "" || strings.ContainsAny(notifier.Name, " \t/") {
+		return fmt.Errorf("invalid notifier name '%s'", notifier.Name)
+	}
+	if notifier.From == "" {
+		return fmt.Errorf("notifier '%s' needs a sender address", notifier.Name)
+	}
+	if len(notifier.To) == 0 {
+		return fmt.Errorf("notifier '%s' needs at least one recipient", notifier.Name)
+	}
+
+	if notifier.Delivery == "" {
+		notifier.Delivery = DeliveryAttachment
+	}
+	switch notifier.Delivery {
+	case DeliveryAttachment:
+	case DeliveryLink:
+		if _, err := url.ParseRequestURI(notifier.LinkBaseURL); err != nil {
+			return fmt.Errorf("link delivery needs a link base URL where exports are served, e.g. https://files.example.com/exports")
+		}
+	default:
+		return fmt.Errorf("invalid delivery '%s' (must be '%s' or '%s')", notifier.Delivery, DeliveryAttachment, DeliveryLink)
+	}
+
+	switch notifier.Type {
+	case NotifierSMTP:
+		if notifier.SMTPHost == "" {
+			return fmt.Errorf("SMTP notifier '%s' needs a host", notifier.Name)
+		}
+		if notifier.SMTPPort == 0 {
+			notifier.SMTPPort = 587
+		}
+	case NotifierSendGrid:
+		if notifier.SendGridAPIKey == "" && notifier.SendGridAPIKeyEnv == "" {
+			return fmt.Errorf("SendGrid notifier '%s' needs an API key or the variable holding one", notifier.Name)
+		}
+	default:
+		return fmt.Errorf("invalid notifier type '%s' (must be '%s' or '%s')", notifier.Type, NotifierSMTP, NotifierSendGrid)
+	}
+
+	return nil
+}
+
+// GetWorkspace returns the workspace with the given name
+func GetWorkspace(name string) (*WorkspaceConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Workspaces {
+		if config.Workspaces[i].Name == name {
+			return &config.Workspaces[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("workspace '%s' does not exist (see 'ga4admin workspace list')", name)
+}
+
+// ListWorkspaces returns all configured workspaces
+func ListWorkspaces() ([]WorkspaceConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.Workspaces, nil
+}
+
+// AddWorkspaceMember adds a property to a workspace, creating the workspace
+// if needed. Adding a member that is already there updates its label.
+func AddWorkspaceMember(name string, member WorkspaceMember) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var workspace *WorkspaceConfig
+	for i := range config.Workspaces {
+		if config.Workspaces[i].Name == name {
+			workspace = &config.Workspaces[i]
+			break
+		}
+	}
+	if workspace == nil {
+		config.Workspaces = append(config.Workspaces, WorkspaceConfig{Name: name})
+		workspace = &config.Workspaces[len(config.Workspaces)-1]
+	}
+
+	replaced := false
+	for i := range workspace.Members {
+		if workspace.Members[i].Preset == member.Preset && workspace.Members[i].PropertyID == member.PropertyID {
+			workspace.Members[i] = member
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		workspace.Members = append(workspace.Members, member)
+	}
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveWorkspaceMember drops a property from a workspace
+func RemoveWorkspaceMember(name, presetName, propertyID string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Workspaces {
+		if config.Workspaces[i].Name != name {
+			continue
+		}
+		members := config.Workspaces[i].Members
+		for j := range members {
+			if members[j].Preset == presetName && members[j].PropertyID == propertyID {
+				config.Workspaces[i].Members = append(members[:j], members[j+1:]...)
+				if err := SaveConfig(config); err != nil {
+					return fmt.Errorf("failed to save config: %w", err)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("property %s via preset '%s' is not in workspace '%s'", propertyID, presetName, name)
+	}
+
+	return fmt.Errorf("workspace '%s' does not exist", name)
+}
+
+// RemoveWorkspace deletes a workspace from global config
+func RemoveWorkspace(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Workspaces {
+		if config.Workspaces[i].Name == name {
+			config.Workspaces = append(config.Workspaces[:i], config.Workspaces[i+1:]...)
+			if err := SaveConfig(config); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("workspace '%s' does not exist", name)
+}
+
+// SetPipeline adds a pipeline to global config, replacing any pipeline with
+// the same name
+func SetPipeline(pipeline PipelineConfig) error {
+	if err := validatePipeline(&pipeline); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	replaced := false
+	for i := range config.Pipelines {
+		if config.Pipelines[i].Name == pipeline.Name {
+			config.Pipelines[i] = pipeline
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.Pipelines = append(config.Pipelines, pipeline)
+	}
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePipeline deletes a pipeline from global config. Its table is kept.
+func RemovePipeline(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Pipelines {
+		if config.Pipelines[i].Name == name {
+			config.Pipelines = append(config.Pipelines[:i], config.Pipelines[i+1:]...)
+			if err := SaveConfig(config); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("pipeline '%s' does not exist", name)
+}
+
+// GetPipeline returns the pipeline with the given name
+func GetPipeline(name string) (*PipelineConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.Pipelines {
+		if config.Pipelines[i].Name == name {
+			return &config.Pipelines[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("pipeline '%s' does not exist (see 'ga4admin pipeline list')", name)
+}
+
+// ListPipelines returns all configured pipelines
+func ListPipelines() ([]PipelineConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.Pipelines, nil
+}
+
+// tableNamePattern matches pipeline table names, which need no quoting in SQL
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validatePipeline checks a pipeline's name, table and start date and that
+// nothing it needs is missing
+func validatePipeline(pipeline *PipelineConfig) error {
+	if pipeline.Name == "" || strings.ContainsAny(pipeline.Name, " \t/") {
+		return fmt.Errorf("invalid pipeline name '%s'", pipeline.Name)
+	}
+	switch {
+	case pipeline.Preset == "":
+		return fmt.Errorf("pipeline '%s' needs a preset", pipeline.Name)
+	case pipeline.PropertyID == "":
+		return fmt.Errorf("pipeline '%s' needs a property", pipeline.Name)
+	case pipeline.Query == "":
+		return fmt.Errorf("pipeline '%s' needs a query file or report name", pipeline.Name)
+	case pipeline.Database == "":
+		return fmt.Errorf("pipeline '%s' needs a DuckDB database file", pipeline.Name)
+	}
+	if !tableNamePattern.MatchString(pipeline.Table) {
+		return fmt.Errorf("invalid table name '%s' for pipeline '%s' - use letters, digits and underscores, not starting with a digit", pipeline.Table, pipeline.Name)
+	}
+	if _, err := time.Parse("2006-01-02", pipeline.StartDate); err != nil {
+		return fmt.Errorf("pipeline '%s' needs a start date as YYYY-MM-DD, got '%s'", pipeline.Name, pipeline.StartDate)
+	}
+	switch pipeline.ChunkBy {
+	case "", "day", "week", "month":
+	default:
+		return fmt.Errorf("invalid chunk size '%s' for pipeline '%s' (use day, week or month)", pipeline.ChunkBy, pipeline.Name)
+	}
+	return nil
+}
+
+// SetChannelMapping adds a channel mapping to global config, replacing any
+// mapping with the same name
+func SetChannelMapping(mapping ChannelMapping) error {
+	if err := validateChannelMapping(&mapping); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	replaced := false
+	for i := range config.ChannelMappings {
+		if config.ChannelMappings[i].Name == mapping.Name {
+			config.ChannelMappings[i] = mapping
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.ChannelMappings = append(config.ChannelMappings, mapping)
+	}
+
+	if err := SaveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveChannelMapping deletes a channel mapping from global config
+func RemoveChannelMapping(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.ChannelMappings {
+		if config.ChannelMappings[i].Name == name {
+			config.ChannelMappings = append(config.ChannelMappings[:i], config.ChannelMappings[i+1:]...)
+			if err := SaveConfig(config); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("channel mapping '%s' does not exist", name)
+}
+
+// GetChannelMapping returns the channel mapping with the given name
+func GetChannelMapping(name string) (*ChannelMapping, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for i := range config.ChannelMappings {
+		if config.ChannelMappings[i].Name == name {
+			return &config.ChannelMappings[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("channel mapping '%s' does not exist (see 'ga4admin config channels list')", name)
+}
+
+// ListChannelMappings returns all configured channel mappings
+func ListChannelMappings() ([]ChannelMapping, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.ChannelMappings, nil
+}
+
+// CompileChannelPattern compiles a channel rule pattern the way rules match:
+// case-insensitively, against the whole value
+func CompileChannelPattern(pattern string) (*regexp.Regexp, error) {
+	// Compile the pattern alone first, so errors quote what the user wrote
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return regexp.Compile("(?i)^(?:" + pattern + ")$")
+}
+
+// validateChannelMapping checks a mapping's name and that every rule has a
+// channel and valid patterns
+func validateChannelMapping(mapping *ChannelMapping) error {
+	if mapping.Name == "" || strings.ContainsAny(mapping.Name, " \t/") {
+		return fmt.Errorf("invalid channel mapping name '%s'", mapping.Name)
+	}
+	if len(mapping.Rules) == 0 {
+		return fmt.Errorf("channel mapping '%s' has no rules", mapping.Name)
+	}
+
+	for i, rule := range mapping.Rules {
+		if rule.Channel == "" {
+			return fmt.Errorf("rule %d of channel mapping '%s' has no channel", i+1, mapping.Name)
+		}
+		if rule.Source == "" && rule.Medium == "" && rule.Campaign == "" {
+			return fmt.Errorf("rule %d of channel mapping '%s' needs a source, medium or campaign pattern", i+1, mapping.Name)
+		}
+		fields := []string{"source", "medium", "campaign"}
+		for j, pattern := range []string{rule.Source, rule.Medium, rule.Campaign} {
+			if pattern == "" {
+				continue
+			}
+			if _, err := CompileChannelPattern(pattern); err != nil {
+				return fmt.Errorf("rule %d of channel mapping '%s' has an invalid %s pattern: %w", i+1, mapping.Name, fields[j], err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -7,10 +7,152 @@ type AppConfig struct {
 	ClientID     string `json:"client_id" yaml:"client_id"`                           // Global OAuth client ID
 	ClientSecret string `json:"client_secret" yaml:"client_secret"`                   // Global OAuth client secret
 	ActivePreset string `json:"active_preset,omitempty" yaml:"active_preset,omitempty"` // Current active preset
+	DataAPITransport string `json:"data_api_transport,omitempty" yaml:"data_api_transport,omitempty"` // "rest" (default) or "grpc"
+	AdminAPIVersion string `json:"admin_api_version,omitempty" yaml:"admin_api_version,omitempty"` // "auto" (default), "v1beta" or "v1alpha"
+	Network      NetworkSettings `json:"network,omitempty" yaml:"network,omitempty"` // Timeouts and proxy settings
+	AdminAPIEndpoint string `json:"admin_api_endpoint,omitempty" yaml:"admin_api_endpoint,omitempty"` // Overrides the Admin API host
+	DataAPIEndpoint  string `json:"data_api_endpoint,omitempty" yaml:"data_api_endpoint,omitempty"`   // Overrides the Data API host
+	CacheMode    string `json:"cache_mode,omitempty" yaml:"cache_mode,omitempty"` // "strict" (default) or "swr"
+	Notifiers    []NotifierConfig `json:"notifiers,omitempty" yaml:"notifiers,omitempty"` // Named export delivery targets
+	Workspaces   []WorkspaceConfig `json:"workspaces,omitempty" yaml:"workspaces,omitempty"` // Property groups spanning presets
+	ChannelMappings []ChannelMapping `json:"channel_mappings,omitempty" yaml:"channel_mappings,omitempty"` // Custom channel taxonomies applied to results
+	Pipelines    []PipelineConfig `json:"pipelines,omitempty" yaml:"pipelines,omitempty"` // Daily appends of a query into DuckDB tables
+	Assistant    AssistantSettings `json:"assistant,omitempty" yaml:"assistant,omitempty"` // LLM endpoint 'ask' may use
 	CreatedAt    time.Time `json:"created_at" yaml:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" yaml:"updated_at"`
 }
 
+// NetworkSettings holds connection options for corporate networks and slow links.
+// Durations use Go syntax ("45s", "2m"); empty values keep the built-in defaults.
+type NetworkSettings struct {
+	RequestTimeout  string `json:"request_timeout,omitempty" yaml:"request_timeout,omitempty"`       // Per HTTP request
+	CommandTimeout  string `json:"command_timeout,omitempty" yaml:"command_timeout,omitempty"`       // Whole command, overrides per-command defaults
+	ProxyURL        string `json:"proxy_url,omitempty" yaml:"proxy_url,omitempty"`                   // Falls back to HTTP(S)_PROXY when empty
+	CABundle        string `json:"ca_bundle,omitempty" yaml:"ca_bundle,omitempty"`                   // PEM file with extra trusted root CAs
+	MaxConnsPerHost int    `json:"max_conns_per_host,omitempty" yaml:"max_conns_per_host,omitempty"` // Open connections per API host; 0 for the default
+}
+
+// AssistantSettings point 'ask' at an OpenAI-compatible chat completions
+// endpoint. The API key is read from GA4ADMIN_ASSISTANT_API_KEY, never stored.
+type AssistantSettings struct {
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"` // e.g. https://api.openai.com/v1/chat/completions
+	Model    string `json:"model,omitempty" yaml:"model,omitempty"`       // e.g. gpt-4o-mini
+}
+
+// WorkspaceConfig groups properties reached through different presets, so one
+// report can run across client accounts, e.g. 'workspace run <name> <template>'
+type WorkspaceConfig struct {
+	Name    string            `json:"name" yaml:"name"`
+	Members []WorkspaceMember `json:"members" yaml:"members"`
+}
+
+// WorkspaceMember is one property and the preset whose credentials reach it
+type WorkspaceMember struct {
+	Preset     string `json:"preset" yaml:"preset"`
+	PropertyID string `json:"property_id" yaml:"property_id"`
+	Label      string `json:"label,omitempty" yaml:"label,omitempty"` // e.g. the client's name
+}
+
+// ChannelMapping is a team's own channel taxonomy, added to results as a
+// column, e.g. 'results show <id> --channels <name>'. Rules are tried in
+// order and the first match wins.
+type ChannelMapping struct {
+	Name    string        `json:"name" yaml:"name"`
+	Column  string        `json:"column,omitempty" yaml:"column,omitempty"`   // Name of the added column, default "channel"
+	Default string        `json:"default,omitempty" yaml:"default,omitempty"` // Channel of rows no rule matches, default "Unassigned"
+	Rules   []ChannelRule `json:"rules" yaml:"rules"`
+}
+
+// ChannelRule assigns Channel to rows whose source, medium and campaign match
+// every pattern given. Patterns are case-insensitive regular expressions that
+// must match the whole value.
+type ChannelRule struct {
+	Channel  string `json:"channel" yaml:"channel"`
+	Source   string `json:"source,omitempty" yaml:"source,omitempty"`
+	Medium   string `json:"medium,omitempty" yaml:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty" yaml:"campaign,omitempty"`
+}
+
+// Channel mapping defaults
+const (
+	DefaultChannelColumn = "channel"
+	DefaultChannel       = "Unassigned"
+)
+
+// PipelineConfig appends a query's rows for days not yet loaded to a table in
+// a DuckDB database, e.g. 'pipeline run <name>' from cron. The query must
+// have the date dimension so loaded days can be told apart.
+type PipelineConfig struct {
+	Name       string `json:"name" yaml:"name"`
+	Preset     string `json:"preset" yaml:"preset"`         // Credentials the pipeline runs with
+	PropertyID string `json:"property_id" yaml:"property_id"`
+	Query      string `json:"query" yaml:"query"`           // Query file or built-in report name
+	Database   string `json:"database" yaml:"database"`     // DuckDB database file
+	Table      string `json:"table" yaml:"table"`
+	StartDate  string `json:"start_date" yaml:"start_date"` // First day loaded, YYYY-MM-DD
+	ChunkBy    string `json:"chunk_by,omitempty" yaml:"chunk_by,omitempty"` // Request size for catching up: day, week or month (default)
+}
+
+// NotifierConfig describes where exported results are delivered. Notifiers are
+// referenced by name, e.g. 'results export --notify <name>'. Secrets may be
+// given directly or, preferably, through environment variables.
+type NotifierConfig struct {
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type" yaml:"type"`                                       // "smtp" or "sendgrid"
+	From        string   `json:"from" yaml:"from"`
+	To          []string `json:"to" yaml:"to"`
+	Delivery    string   `json:"delivery,omitempty" yaml:"delivery,omitempty"`           // "attachment" (default) or "link"
+	LinkBaseURL string   `json:"link_base_url,omitempty" yaml:"link_base_url,omitempty"` // Where export files are served, for link delivery
+
+	// SMTP
+	SMTPHost        string `json:"smtp_host,omitempty" yaml:"smtp_host,omitempty"`
+	SMTPPort        int    `json:"smtp_port,omitempty" yaml:"smtp_port,omitempty"` // Default 587
+	SMTPUsername    string `json:"smtp_username,omitempty" yaml:"smtp_username,omitempty"`
+	SMTPPassword    string `json:"smtp_password,omitempty" yaml:"smtp_password,omitempty"`
+	SMTPPasswordEnv string `json:"smtp_password_env,omitempty" yaml:"smtp_password_env,omitempty"` // Read the password from this variable
+
+	// SendGrid
+	SendGridAPIKey    string `json:"sendgrid_api_key,omitempty" yaml:"sendgrid_api_key,omitempty"`
+	SendGridAPIKeyEnv string `json:"sendgrid_api_key_env,omitempty" yaml:"sendgrid_api_key_env,omitempty"` // Read the API key from this variable
+}
+
+// Notifier types and delivery modes
+const (
+	NotifierSMTP       = "smtp"
+	NotifierSendGrid   = "sendgrid"
+	DeliveryAttachment = "attachment"
+	DeliveryLink       = "link"
+)
+
+// API endpoints. Overrides point at mock servers in tests or at regional
+// endpoints required for data residency; environment variables win over config.
+const (
+	DefaultAdminAPIEndpoint = "https://analyticsadmin.googleapis.com"
+	DefaultDataAPIEndpoint  = "https://analyticsdata.googleapis.com"
+	AdminAPIEndpointEnvVar  = "GA4ADMIN_ADMIN_API_ENDPOINT"
+	DataAPIEndpointEnvVar   = "GA4ADMIN_DATA_API_ENDPOINT"
+)
+
+// Admin API versions
+const (
+	AdminAPIAuto    = "auto"
+	AdminAPIV1Beta  = "v1beta"
+	AdminAPIV1Alpha = "v1alpha"
+)
+
+// Cache modes. In stale-while-revalidate mode expired entries are served
+// immediately and refreshed in the background.
+const (
+	CacheModeStrict = "strict"
+	CacheModeSWR    = "swr"
+)
+
+// Data API transports
+const (
+	TransportREST = "rest"
+	TransportGRPC = "grpc"
+)
+
 // Preset represents a saved GA4 configuration with user credentials
 type Preset struct {
 	Name         string    `json:"name" yaml:"name"`
@@ -19,6 +161,12 @@ type Preset struct {
 	CreatedAt    time.Time `json:"created_at" yaml:"created_at"`
 	LastUsed     time.Time `json:"last_used" yaml:"last_used"`
 	Accounts     []Account `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	SyncedAt     time.Time `json:"synced_at,omitempty" yaml:"synced_at,omitempty"` // Last account/property sync
+	Aliases      map[string]string `json:"aliases,omitempty" yaml:"aliases,omitempty"` // Alias name -> property ID
+	NeedsReauth  bool      `json:"needs_reauth,omitempty" yaml:"needs_reauth,omitempty"` // Google rejected the refresh token (invalid_grant)
+	Scopes       []string  `json:"scopes,omitempty" yaml:"scopes,omitempty"` // OAuth scopes granted to the refresh token; empty if unknown
+	ServiceAccountKey string `json:"service_account_key,omitempty" yaml:"service_account_key,omitempty"` // Path to a service account JSON key, used instead of the refresh token
+	Impersonate  string    `json:"impersonate,omitempty" yaml:"impersonate,omitempty"` // User the service account acts as through domain-wide delegation
 }
 
 // Account represents a GA4 account
@@ -115,6 +263,120 @@ type CacheStats struct {
 	LastCleanup   *time.Time `json:"last_cleanup"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
+	SchemaVersion int        `json:"schema_version"`
+}
+
+// CachedQuery describes a stored query result in the cache
+type CachedQuery struct {
+	QueryID      string     `json:"query_id"`
+	PropertyID   string     `json:"property_id"`
+	QueryHash    string     `json:"query_hash"`
+	RowCount     int        `json:"row_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	LastAccessed time.Time  `json:"last_accessed"`
+	TableName    string     `json:"table_name,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Note         string     `json:"note,omitempty"`
+}
+
+// CachedQueryParams is an unexpired cache entry's stored request, used to
+// find results that can be reused for other date ranges
+type CachedQueryParams struct {
+	QueryID   string    `json:"query_id"`
+	Params    string    `json:"params"` // JSON-encoded request
+	RowCount  int       `json:"row_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RejectedQuery remembers a report request GA4 rejected as invalid, so
+// repeating it fails without spending quota until it expires
+type RejectedQuery struct {
+	QueryHash  string    `json:"query_hash"`
+	PropertyID string    `json:"property_id"`
+	API        string    `json:"api"`
+	StatusCode int       `json:"status_code"`
+	Status     string    `json:"status"`
+	Message    string    `json:"message"`
+	RejectedAt time.Time `json:"rejected_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// QueryLogEntry records one query execution for 'query stats'
+type QueryLogEntry struct {
+	PropertyID     string    `json:"property_id"`
+	QueryHash      string    `json:"query_hash"`
+	ExecutedAt     time.Time `json:"executed_at"`
+	ExecutionMs    int64     `json:"execution_ms"`
+	RowCount       int       `json:"row_count"`
+	FromCache      bool      `json:"from_cache"`
+	TokensConsumed int       `json:"tokens_consumed,omitempty"` // Zero for cache hits
+	Dimensions     []string  `json:"dimensions"`
+	Metrics        []string  `json:"metrics"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// AuditRecord is one Admin API write, kept for 'audit list'. Before and
+// After hold the resource's JSON as read just before the write and as GA4
+// returned it.
+type AuditRecord struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	Preset     string    `json:"preset"`
+	User       string    `json:"user"`              // Local OS user
+	Command    string    `json:"command"`           // e.g. "ga4admin apply"
+	PropertyID string    `json:"property_id"`
+	Method     string    `json:"method"`            // POST, PATCH or DELETE
+	Resource   string    `json:"resource"`          // e.g. "properties/123/keyEvents/4"
+	Request    string    `json:"request,omitempty"` // JSON body sent
+	Before     string    `json:"before,omitempty"`
+	After      string    `json:"after,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// ResultView is a saved filter and ordering over a cached query result
+type ResultView struct {
+	Name        string    `json:"name"`
+	PropertyID  string    `json:"property_id"`
+	QueryID     string    `json:"query_id"`
+	Where       string    `json:"where,omitempty"`
+	OrderBy     string    `json:"order_by,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Rollup aggregates a cached result into a table of the preset's cache, e.g.
+// daily sessions by source/medium, so dashboards can read it directly
+type Rollup struct {
+	Name            string         `json:"name"`
+	PropertyID      string         `json:"property_id"`
+	Source          string         `json:"source"` // Named table, view or result ID aggregated
+	GroupBy         []string       `json:"group_by"`
+	Metrics         []RollupMetric `json:"metrics"`
+	RefreshEvery    time.Duration  `json:"refresh_every,omitempty"` // Re-fetch the source once its data is older; 0 never re-fetches
+	Description     string         `json:"description,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	RefreshedAt     time.Time      `json:"refreshed_at"`      // When the table was last built
+	SourceQueryID   string         `json:"source_query_id"`   // Result the table was built from
+	SourceFetchedAt time.Time      `json:"source_fetched_at"` // When that result was fetched from GA4
+	RowCount        int            `json:"row_count"`
+}
+
+// RollupMetric is a metric of a rollup and how its rows are combined
+type RollupMetric struct {
+	Name        string `json:"name"`
+	Aggregation string `json:"aggregation"` // sum, avg, min or max
+	Type        string `json:"type,omitempty"` // GA4 type of the aggregated values
+}
+
+// NamedTableQuery is a named table with the stored request of its result,
+// used to find which date ranges of a query are already saved
+type NamedTableQuery struct {
+	Name      string    `json:"name"`
+	QueryID   string    `json:"query_id"`
+	Params    string    `json:"params"` // JSON-encoded request
+	RowCount  int       `json:"row_count"`
+	CreatedAt time.Time `json:"created_at"` // When the result was fetched
 }
 
 // NamedTable represents a named query result table
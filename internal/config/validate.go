@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintConfigFile parses the config file strictly, returning the misspelled
+// or leftover keys that LoadConfig silently ignores, and checks every value
+// with Validate. A missing file lints clean.
+func LintConfigFile() (unknownKeys []string, problems []error, err error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var config AppConfig
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		// Unknown keys fail strict decoding; the lenient parse still has to work
+		config = AppConfig{}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		unknownKeys = typeErr.Errors
+	}
+
+	return unknownKeys, Validate(&config), nil
+}
+
+// Validate checks the values of a loaded config the way the 'config set'
+// commands would have, for files that were edited by hand
+func Validate(config *AppConfig) []error {
+	var problems []error
+
+	if transport := config.DataAPITransport; transport != "" && transport != TransportREST && transport != TransportGRPC {
+		problems = append(problems, fmt.Errorf("invalid data_api_transport '%s' (must be '%s' or '%s')", transport, TransportREST, TransportGRPC))
+	}
+	if version := config.AdminAPIVersion; version != "" && version != AdminAPIAuto && version != AdminAPIV1Beta && version != AdminAPIV1Alpha {
+		problems = append(problems, fmt.Errorf("invalid admin_api_version '%s' (must be '%s', '%s' or '%s')", version, AdminAPIAuto, AdminAPIV1Beta, AdminAPIV1Alpha))
+	}
+	if mode := config.CacheMode; mode != "" && mode != CacheModeStrict && mode != CacheModeSWR {
+		problems = append(problems, fmt.Errorf("invalid cache_mode '%s' (must be '%s' or '%s')", mode, CacheModeStrict, CacheModeSWR))
+	}
+	for _, endpoint := range []string{config.AdminAPIEndpoint, config.DataAPIEndpoint} {
+		if err := validateEndpoint(endpoint); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	network := config.Network
+	for name, value := range map[string]string{
+		"request_timeout": network.RequestTimeout,
+		"command_timeout": network.CommandTimeout,
+	} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			problems = append(problems, fmt.Errorf("invalid network.%s '%s' (use a duration like 30s or 2m)", name, value))
+		}
+	}
+	if network.ProxyURL != "" {
+		if parsed, err := url.Parse(network.ProxyURL); err != nil || parsed.Host == "" {
+			problems = append(problems, fmt.Errorf("invalid network.proxy_url '%s' (expected e.g. http://proxy.example.com:3128)", network.ProxyURL))
+		}
+	}
+	if network.CABundle != "" {
+		if _, err := os.Stat(network.CABundle); err != nil {
+			problems = append(problems, fmt.Errorf("network.ca_bundle not readable: %w", err))
+		}
+	}
+	if network.MaxConnsPerHost < 0 {
+		problems = append(problems, fmt.Errorf("invalid network.max_conns_per_host %d (use 0 for the default)", network.MaxConnsPerHost))
+	}
+
+	for _, notifier := range config.Notifiers {
+		// notifier is a copy, so the defaults validateNotifier fills in are dropped
+		if err := validateNotifier(&notifier); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, mapping := range config.ChannelMappings {
+		if err := validateChannelMapping(&mapping); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, pipeline := range config.Pipelines {
+		if err := validatePipeline(&pipeline); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,384 @@
+package customdims
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/api"
+)
+
+// Spec file CSV columns. parameter_name and display_name are required; scope
+// defaults to EVENT.
+const (
+	ColumnParameterName              = "parameter_name"
+	ColumnDisplayName                = "display_name"
+	ColumnScope                      = "scope"
+	ColumnDescription                = "description"
+	ColumnDisallowAdsPersonalization = "disallow_ads_personalization"
+)
+
+// StandardLimits are the custom dimension quotas of standard properties per
+// scope. GA4 360 properties allow more, so exceeding them only warns.
+var StandardLimits = map[string]int{
+	api.CustomDimensionScopeEvent: 50,
+	api.CustomDimensionScopeUser:  25,
+	api.CustomDimensionScopeItem:  10,
+}
+
+var (
+	parameterNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	displayNamePattern   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*$`)
+)
+
+// Spec declares one custom dimension a property should have
+type Spec struct {
+	ParameterName              string `json:"parameter_name" yaml:"parameter_name"`
+	DisplayName                string `json:"display_name" yaml:"display_name"`
+	Scope                      string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Description                string `json:"description,omitempty" yaml:"description,omitempty"`
+	DisallowAdsPersonalization bool   `json:"disallow_ads_personalization,omitempty" yaml:"disallow_ads_personalization,omitempty"`
+}
+
+// specFile is the YAML/JSON layout of a spec file
+type specFile struct {
+	CustomDimensions []Spec `json:"custom_dimensions" yaml:"custom_dimensions"`
+}
+
+// Action is what applying a plan does with one custom dimension
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionSkip    Action = "skip"    // Already registered
+	ActionArchive Action = "archive" // Registered but not in the spec
+)
+
+// Change is one planned action
+type Change struct {
+	Action    Action              `json:"action"`
+	Dimension api.CustomDimension `json:"dimension"`
+	Note      string              `json:"note,omitempty"` // Differences GA4 won't be asked to fix
+}
+
+// Plan lists the changes that make a property match a spec
+type Plan struct {
+	PropertyID string   `json:"property_id"`
+	Changes    []Change `json:"changes"`
+}
+
+// LoadSpecFile reads a spec from a CSV file with a header row, or from a YAML
+// or JSON file with a custom_dimensions list. Entries are validated and
+// normalized; scope defaults to EVENT.
+func LoadSpecFile(path string) ([]Spec, error) {
+	var specs []Spec
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		specs, err = loadCSV(path)
+	case ".json", ".yaml", ".yml":
+		specs, err = loadStructured(path)
+	default:
+		return nil, fmt.Errorf("unsupported spec file '%s' (use .csv, .yaml, .yml or .json)", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("spec file declares no custom dimensions")
+	}
+	if err := ValidateSpecs(specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// ValidateSpecs checks specs against GA4's naming rules and for duplicates,
+// normalizing them in place
+func ValidateSpecs(specs []Spec) error {
+	seen := make(map[string]int, len(specs))
+	for i := range specs {
+		spec := &specs[i]
+		if err := normalizeSpec(spec); err != nil {
+			return fmt.Errorf("entry %d (%s): %w", i+1, spec.ParameterName, err)
+		}
+		key := spec.Scope + "/" + spec.ParameterName
+		if first, ok := seen[key]; ok {
+			return fmt.Errorf("entry %d: %s-scoped '%s' is already declared by entry %d", i+1, spec.Scope, spec.ParameterName, first)
+		}
+		seen[key] = i + 1
+	}
+	return nil
+}
+
+func loadCSV(path string) ([]Spec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("spec file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case ColumnParameterName, ColumnDisplayName, ColumnScope, ColumnDescription, ColumnDisallowAdsPersonalization:
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown spec column '%s' (expected %s, %s, %s, %s, %s)", name,
+				ColumnParameterName, ColumnDisplayName, ColumnScope, ColumnDescription, ColumnDisallowAdsPersonalization)
+		}
+	}
+	for _, required := range []string{ColumnParameterName, ColumnDisplayName} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("spec file is missing the '%s' column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var specs []Spec
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse spec file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		spec := Spec{
+			ParameterName: field(record, ColumnParameterName),
+			DisplayName:   field(record, ColumnDisplayName),
+			Scope:         field(record, ColumnScope),
+			Description:   field(record, ColumnDescription),
+		}
+		if value := field(record, ColumnDisallowAdsPersonalization); value != "" {
+			disallow, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s must be true or false", line, ColumnDisallowAdsPersonalization)
+			}
+			spec.DisallowAdsPersonalization = disallow
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func loadStructured(path string) ([]Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var file specFile
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec file: %w", err)
+	}
+
+	return file.CustomDimensions, nil
+}
+
+// normalizeSpec applies GA4's naming rules and fills the default scope
+func normalizeSpec(spec *Spec) error {
+	spec.ParameterName = strings.TrimSpace(spec.ParameterName)
+	spec.DisplayName = strings.TrimSpace(spec.DisplayName)
+	spec.Scope = strings.ToUpper(strings.TrimSpace(spec.Scope))
+	if spec.Scope == "" {
+		spec.Scope = api.CustomDimensionScopeEvent
+	}
+
+	if _, ok := StandardLimits[spec.Scope]; !ok {
+		return fmt.Errorf("invalid scope '%s' (must be EVENT, USER or ITEM)", spec.Scope)
+	}
+
+	// User properties have a shorter name limit than event parameters
+	maxParameterLength := 40
+	if spec.Scope == api.CustomDimensionScopeUser {
+		maxParameterLength = 24
+	}
+	switch {
+	case spec.ParameterName == "":
+		return fmt.Errorf("%s is required", ColumnParameterName)
+	case !parameterNamePattern.MatchString(spec.ParameterName):
+		return fmt.Errorf("parameter name must start with a letter and use only letters, digits and underscores")
+	case len(spec.ParameterName) > maxParameterLength:
+		return fmt.Errorf("parameter name is longer than %d characters", maxParameterLength)
+	}
+
+	switch {
+	case spec.DisplayName == "":
+		return fmt.Errorf("%s is required", ColumnDisplayName)
+	case !displayNamePattern.MatchString(spec.DisplayName):
+		return fmt.Errorf("display name must start with a letter and use only letters, digits, underscores and spaces")
+	case len(spec.DisplayName) > 82:
+		return fmt.Errorf("display name is longer than 82 characters")
+	}
+
+	if len(spec.Description) > 150 {
+		return fmt.Errorf("description is longer than 150 characters")
+	}
+	if spec.DisallowAdsPersonalization && spec.Scope != api.CustomDimensionScopeUser {
+		return fmt.Errorf("%s only applies to USER scope", ColumnDisallowAdsPersonalization)
+	}
+	return nil
+}
+
+// BuildPlan compares a spec with a property's registered custom dimensions.
+// Dimensions match on scope and parameter name. Unlisted dimensions are
+// archived unless keepUnlisted is set.
+func BuildPlan(propertyID string, specs []Spec, existing []api.CustomDimension, keepUnlisted bool) *Plan {
+	plan := &Plan{PropertyID: propertyID}
+
+	registered := make(map[string]api.CustomDimension, len(existing))
+	for _, dimension := range existing {
+		registered[dimension.Scope+"/"+dimension.ParameterName] = dimension
+	}
+
+	declared := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		key := spec.Scope + "/" + spec.ParameterName
+		declared[key] = true
+
+		current, ok := registered[key]
+		if !ok {
+			plan.Changes = append(plan.Changes, Change{
+				Action: ActionCreate,
+				Dimension: api.CustomDimension{
+					ParameterName:              spec.ParameterName,
+					DisplayName:                spec.DisplayName,
+					Description:                spec.Description,
+					Scope:                      spec.Scope,
+					DisallowAdsPersonalization: spec.DisallowAdsPersonalization,
+				},
+			})
+			continue
+		}
+
+		var differences []string
+		if current.DisplayName != spec.DisplayName {
+			differences = append(differences, fmt.Sprintf("display name on the property is '%s'", current.DisplayName))
+		}
+		if current.Description != spec.Description {
+			differences = append(differences, "description differs")
+		}
+		if current.DisallowAdsPersonalization != spec.DisallowAdsPersonalization {
+			differences = append(differences, "ads personalization setting differs")
+		}
+		plan.Changes = append(plan.Changes, Change{
+			Action:    ActionSkip,
+			Dimension: current,
+			Note:      strings.Join(differences, "; "),
+		})
+	}
+
+	for _, dimension := range existing {
+		if declared[dimension.Scope+"/"+dimension.ParameterName] {
+			continue
+		}
+		change := Change{Action: ActionArchive, Dimension: dimension}
+		if keepUnlisted {
+			change.Action = ActionSkip
+			change.Note = "not in spec; kept"
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	return plan
+}
+
+// Count returns the number of changes with the given action
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// LimitWarnings describes scopes that would exceed StandardLimits once the
+// plan is applied
+func (p *Plan) LimitWarnings() []string {
+	counts := make(map[string]int)
+	for _, change := range p.Changes {
+		if change.Action != ActionArchive {
+			counts[change.Dimension.Scope]++
+		}
+	}
+
+	var warnings []string
+	for _, scope := range []string{api.CustomDimensionScopeEvent, api.CustomDimensionScopeUser, api.CustomDimensionScopeItem} {
+		if counts[scope] > StandardLimits[scope] {
+			warnings = append(warnings, fmt.Sprintf("%d %s-scoped custom dimensions exceed the standard property limit of %d (GA4 360 allows more)",
+				counts[scope], scope, StandardLimits[scope]))
+		}
+	}
+	return warnings
+}
+
+// Apply archives, then creates, the plan's custom dimensions so archived
+// slots are free for new ones. Every change is attempted; progress is called
+// after each with its error, and the number of failures is returned.
+func Apply(ctx context.Context, client api.AdminService, plan *Plan, progress func(change Change, err error)) int {
+	failed := 0
+	for _, action := range []Action{ActionArchive, ActionCreate} {
+		for _, change := range plan.Changes {
+			if change.Action != action {
+				continue
+			}
+
+			var err error
+			if action == ActionArchive {
+				err = client.ArchiveCustomDimension(ctx, change.Dimension.Name)
+			} else {
+				var created *api.CustomDimension
+				if created, err = client.CreateCustomDimension(ctx, plan.PropertyID, change.Dimension); err == nil {
+					change.Dimension = *created
+				}
+			}
+			if err != nil {
+				failed++
+			}
+			if progress != nil {
+				progress(change, err)
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,140 @@
+// Package dictionary builds a human-readable data dictionary of a property's
+// dimensions and metrics, written as markdown or an Excel workbook.
+package dictionary
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// Entry kinds
+const (
+	KindDimension = "Dimension"
+	KindMetric    = "Metric"
+)
+
+// Entry is one dimension or metric of a property
+type Entry struct {
+	Kind        string // KindDimension or KindMetric
+	APIName     string
+	UIName      string
+	Category    string
+	Description string
+	Type        string // Metric type, e.g. TYPE_INTEGER
+	Expression  string // Formula of calculated metrics
+	Deprecated  []string
+
+	// Custom definitions only
+	Custom    bool
+	Scope     string // EVENT, USER or ITEM
+	Parameter string // Event parameter or user property name
+}
+
+// Dictionary is the data dictionary of a property
+type Dictionary struct {
+	PropertyID  string
+	GeneratedAt time.Time
+	Dimensions  []Entry
+	Metrics     []Entry
+}
+
+// customScopes maps the API name prefixes of custom definitions to their scope
+var customScopes = map[string]string{
+	"customEvent:": api.CustomDimensionScopeEvent,
+	"customUser:":  api.CustomDimensionScopeUser,
+	"customItem:":  api.CustomDimensionScopeItem,
+}
+
+// Build assembles the dictionary of a property from its Data API metadata.
+// customDimensions, from the Admin API, supply the display names and
+// descriptions of custom dimensions, which the metadata only describes
+// generically; it may be nil.
+func Build(propertyID string, metadata *api.MetadataResponse, customDimensions []api.CustomDimension) *Dictionary {
+	defined := make(map[string]api.CustomDimension, len(customDimensions))
+	for _, dimension := range customDimensions {
+		defined[dimension.Scope+"/"+dimension.ParameterName] = dimension
+	}
+
+	d := &Dictionary{PropertyID: propertyID, GeneratedAt: time.Now()}
+	for _, dimension := range metadata.Dimensions {
+		entry := Entry{
+			Kind:        KindDimension,
+			APIName:     dimension.APIName,
+			UIName:      dimension.UIName,
+			Category:    dimension.Category,
+			Description: dimension.Description,
+			Deprecated:  dimension.DeprecatedAPINames,
+			Custom:      dimension.CustomDefinition,
+		}
+		if entry.Custom {
+			entry.Scope, entry.Parameter = customScope(entry.APIName)
+			if definition, ok := defined[entry.Scope+"/"+entry.Parameter]; ok {
+				entry.UIName = definition.DisplayName
+				if definition.Description != "" {
+					entry.Description = definition.Description
+				}
+			}
+		}
+		d.Dimensions = append(d.Dimensions, entry)
+	}
+
+	for _, metric := range metadata.Metrics {
+		entry := Entry{
+			Kind:        KindMetric,
+			APIName:     metric.APIName,
+			UIName:      metric.UIName,
+			Category:    metric.Category,
+			Description: metric.Description,
+			Type:        metric.Type,
+			Expression:  metric.Expression,
+			Deprecated:  metric.DeprecatedAPINames,
+			Custom:      metric.CustomDefinition,
+		}
+		if entry.Custom {
+			entry.Scope, entry.Parameter = customScope(entry.APIName)
+		}
+		d.Metrics = append(d.Metrics, entry)
+	}
+
+	sortEntries(d.Dimensions)
+	sortEntries(d.Metrics)
+	return d
+}
+
+// Custom returns the custom dimensions and metrics, dimensions first
+func (d *Dictionary) Custom() []Entry {
+	var custom []Entry
+	for _, entries := range [][]Entry{d.Dimensions, d.Metrics} {
+		for _, entry := range entries {
+			if entry.Custom {
+				custom = append(custom, entry)
+			}
+		}
+	}
+	return custom
+}
+
+// customScope splits a custom definition's API name, e.g. customUser:plan,
+// into its scope and parameter name
+func customScope(apiName string) (scope, parameter string) {
+	for prefix, scope := range customScopes {
+		if strings.HasPrefix(apiName, prefix) {
+			return scope, strings.TrimPrefix(apiName, prefix)
+		}
+	}
+	return "", ""
+}
+
+// sortEntries orders entries by category, then API name, so related fields
+// sit together
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Category != entries[j].Category {
+			return entries[i].Category < entries[j].Category
+		}
+		return entries[i].APIName < entries[j].APIName
+	})
+}
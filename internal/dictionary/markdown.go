@@ -0,0 +1,114 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteMarkdown writes the dictionary as a markdown document
+func WriteMarkdown(d *Dictionary, outputPath string) error {
+	var buf bytes.Buffer
+	RenderMarkdown(d, &buf)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write data dictionary: %w", err)
+	}
+	return nil
+}
+
+// RenderMarkdown writes the dictionary as markdown to w: a summary, the
+// custom definitions, then dimensions and metrics grouped by category
+func RenderMarkdown(d *Dictionary, w io.Writer) {
+	custom := d.Custom()
+	fmt.Fprintf(w, "# Data Dictionary: Property %s\n\n", d.PropertyID)
+	fmt.Fprintf(w, "Generated %s. %d dimensions and %d metrics, %d of them custom definitions.\n\n",
+		d.GeneratedAt.Format("2006-01-02 15:04 MST"), len(d.Dimensions), len(d.Metrics), len(custom))
+
+	if len(custom) > 0 {
+		fmt.Fprintf(w, "## Custom Definitions\n\n")
+		writeTable(w, []string{"API Name", "Name", "Kind", "Scope", "Parameter", "Description"}, custom, func(e Entry) []string {
+			return []string{code(e.APIName), e.UIName, e.Kind, e.Scope, code(e.Parameter), e.Description}
+		})
+	}
+
+	fmt.Fprintf(w, "## Dimensions\n\n")
+	for _, group := range byCategory(d.Dimensions) {
+		fmt.Fprintf(w, "### %s\n\n", group[0].categoryName())
+		writeTable(w, []string{"API Name", "Name", "Description"}, group, func(e Entry) []string {
+			return []string{code(e.APIName), e.UIName, describe(e)}
+		})
+	}
+
+	fmt.Fprintf(w, "## Metrics\n\n")
+	for _, group := range byCategory(d.Metrics) {
+		fmt.Fprintf(w, "### %s\n\n", group[0].categoryName())
+		writeTable(w, []string{"API Name", "Name", "Type", "Description"}, group, func(e Entry) []string {
+			return []string{code(e.APIName), e.UIName, strings.TrimPrefix(e.Type, "TYPE_"), describe(e)}
+		})
+	}
+}
+
+func writeTable(w io.Writer, headers []string, entries []Entry, cells func(Entry) []string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat("---|", len(headers)))
+	for _, entry := range entries {
+		row := cells(entry)
+		for i, cell := range row {
+			row[i] = escapeCell(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+	}
+	fmt.Fprintln(w)
+}
+
+// describe adds a calculated metric's formula and any deprecated API names
+// to its description
+func describe(e Entry) string {
+	description := e.Description
+	if e.Expression != "" && e.Expression != e.APIName {
+		description += " Formula: " + code(e.Expression) + "."
+	}
+	if len(e.Deprecated) > 0 {
+		description += " Formerly " + code(strings.Join(e.Deprecated, "`, `")) + "."
+	}
+	return strings.TrimSpace(description)
+}
+
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + value + "`"
+}
+
+// escapeCell keeps a value inside its table cell and out of HTML tags
+func escapeCell(value string) string {
+	value = strings.NewReplacer("|", `\|`, "<", `\<`).Replace(value)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// byCategory splits sorted entries into runs of the same category
+func byCategory(entries []Entry) [][]Entry {
+	var groups [][]Entry
+	for i, entry := range entries {
+		if i == 0 || entry.Category != entries[i-1].Category {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], entry)
+	}
+	return groups
+}
+
+func (e Entry) categoryName() string {
+	if e.Category == "" {
+		return "Other"
+	}
+	return e.Category
+}
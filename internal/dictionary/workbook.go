@@ -0,0 +1,132 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxSheetName is the longest worksheet name Excel accepts
+const maxSheetName = 31
+
+// AccountProperty is one property of an account workbook. Dictionary is nil
+// when the property's metadata couldn't be loaded; Err says why.
+type AccountProperty struct {
+	ID         string
+	Name       string
+	Dictionary *Dictionary
+	Err        error
+}
+
+// WriteAccountXLSX writes the dictionaries of an account's properties as one
+// Excel workbook: a Summary sheet listing every property with its field
+// counts, then one sheet per property with its dimensions, metrics and
+// custom definitions. Properties that failed appear only in the summary.
+func WriteAccountXLSX(properties []AccountProperty, outputPath string) error {
+	summary := sheet{
+		Name: "Summary",
+		Columns: []column{{"Property ID", 14}, {"Property Name", 32}, {"Sheet", 32}, {"Dimensions", 12},
+			{"Metrics", 10}, {"Custom Dimensions", 18}, {"Custom Metrics", 15}, {"Status", 40}},
+	}
+	var propertySheets []sheet
+	used := map[string]bool{strings.ToLower(summary.Name): true}
+
+	for _, property := range properties {
+		if property.Dictionary == nil {
+			status := "Not loaded"
+			if property.Err != nil {
+				status = "Not loaded: " + property.Err.Error()
+			}
+			summary.Rows = append(summary.Rows, []string{property.ID, property.Name, "", "", "", "", "", status})
+			continue
+		}
+
+		d := property.Dictionary
+		name := sheetName(property.Name, property.ID, used)
+		customDimensions, customMetrics := 0, 0
+		for _, entry := range d.Custom() {
+			if entry.Kind == KindDimension {
+				customDimensions++
+			} else {
+				customMetrics++
+			}
+		}
+		summary.Rows = append(summary.Rows, []string{property.ID, property.Name, name,
+			strconv.Itoa(len(d.Dimensions)), strconv.Itoa(len(d.Metrics)),
+			strconv.Itoa(customDimensions), strconv.Itoa(customMetrics), "OK"})
+
+		var rows [][]string
+		for _, entries := range [][]Entry{d.Dimensions, d.Metrics} {
+			for _, e := range entries {
+				custom := ""
+				if e.Custom {
+					custom = "Yes"
+				}
+				rows = append(rows, []string{e.Kind, e.APIName, e.UIName, e.categoryName(),
+					strings.TrimPrefix(e.Type, "TYPE_"), custom, e.Scope, e.Parameter, e.Description, e.Expression})
+			}
+		}
+		propertySheets = append(propertySheets, sheet{
+			Name: name,
+			Columns: []column{{"Kind", 10}, {"API Name", 32}, {"Name", 28}, {"Category", 20}, {"Type", 14},
+				{"Custom", 8}, {"Scope", 8}, {"Parameter", 20}, {"Description", 80}, {"Expression", 32}},
+			Rows: rows,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := writeWorkbook(&buf, append([]sheet{summary}, propertySheets...)); err != nil {
+		return fmt.Errorf("failed to build workbook: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+// sheetName returns a worksheet name for a property, e.g. "Web Shop
+// (123456789)", within Excel's length limit, without the characters Excel
+// forbids and different from the names in used, which it is added to
+func sheetName(propertyName, propertyID string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, propertyName)
+	name = strings.Trim(strings.Join(strings.Fields(name), " "), "'")
+
+	suffix := " (" + propertyID + ")"
+	if name == "" {
+		suffix = propertyID
+	}
+	// The attempt number is kept whole and the suffix cut before it, so every
+	// attempt gives a different name even when the suffix alone fills the limit
+	for attempt := 1; ; attempt++ {
+		number := ""
+		if attempt > 1 {
+			number = fmt.Sprintf(" %d", attempt)
+		}
+		base := []rune(suffix)
+		if len(base)+len(number) > maxSheetName {
+			base = base[:maxSheetName-len(number)]
+		}
+		room := maxSheetName - len(base) - len(number)
+		runes := []rune(name)
+		if len(runes) > room {
+			runes = runes[:room]
+		}
+		candidate := strings.TrimSpace(string(runes) + string(base) + number)
+		if !used[strings.ToLower(candidate)] {
+			used[strings.ToLower(candidate)] = true
+			return candidate
+		}
+	}
+}
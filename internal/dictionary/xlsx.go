@@ -0,0 +1,202 @@
+package dictionary
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sheet is one worksheet of a workbook: a bold, frozen header row over
+// plain text rows
+type sheet struct {
+	Name    string
+	Columns []column
+	Rows    [][]string
+}
+
+type column struct {
+	Header string
+	Width  int // In characters
+}
+
+// WriteXLSX writes the dictionary as an Excel workbook with Dimensions,
+// Metrics and Custom Definitions sheets
+func WriteXLSX(d *Dictionary, outputPath string) error {
+	fields := func(entries []Entry, metrics bool) [][]string {
+		rows := make([][]string, 0, len(entries))
+		for _, e := range entries {
+			row := []string{e.APIName, e.UIName, e.categoryName()}
+			if metrics {
+				row = append(row, strings.TrimPrefix(e.Type, "TYPE_"))
+			}
+			custom := ""
+			if e.Custom {
+				custom = "Yes"
+			}
+			row = append(row, custom, e.Scope, e.Parameter, e.Description)
+			if metrics {
+				row = append(row, e.Expression)
+			}
+			rows = append(rows, append(row, strings.Join(e.Deprecated, ", ")))
+		}
+		return rows
+	}
+
+	var customRows [][]string
+	for _, e := range d.Custom() {
+		customRows = append(customRows, []string{e.APIName, e.UIName, e.Kind, e.Scope, e.Parameter, e.Description})
+	}
+
+	sheets := []sheet{
+		{
+			Name: "Dimensions",
+			Columns: []column{{"API Name", 32}, {"Name", 28}, {"Category", 20}, {"Custom", 8},
+				{"Scope", 8}, {"Parameter", 20}, {"Description", 80}, {"Deprecated API Names", 24}},
+			Rows: fields(d.Dimensions, false),
+		},
+		{
+			Name: "Metrics",
+			Columns: []column{{"API Name", 32}, {"Name", 28}, {"Category", 20}, {"Type", 14}, {"Custom", 8},
+				{"Scope", 8}, {"Parameter", 20}, {"Description", 80}, {"Expression", 32}, {"Deprecated API Names", 24}},
+			Rows: fields(d.Metrics, true),
+		},
+		{
+			Name: "Custom Definitions",
+			Columns: []column{{"API Name", 32}, {"Name", 28}, {"Kind", 10}, {"Scope", 8},
+				{"Parameter", 20}, {"Description", 80}},
+			Rows: customRows,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeWorkbook(&buf, sheets); err != nil {
+		return fmt.Errorf("failed to build workbook: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write data dictionary: %w", err)
+	}
+	return nil
+}
+
+// writeWorkbook writes a minimal SpreadsheetML package. Cells are inline
+// strings, so no shared string table is needed; style 1 is the bold header.
+func writeWorkbook(w io.Writer, sheets []sheet) error {
+	z := zip.NewWriter(w)
+
+	var overrides, workbookSheets, relationships strings.Builder
+	for i, s := range sheets {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbookSheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(s.Name), i+1, i+1)
+		fmt.Fprintf(&relationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&relationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbookSheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			relationships.String() + `</Relationships>`},
+		{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(s)})
+	}
+
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func worksheet(s sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	b.WriteString(`<cols>`)
+	for i, c := range s.Columns {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, c.Width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+
+	headers := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		headers[i] = c.Header
+	}
+	writeRow(&b, 1, headers, 1)
+	for i, row := range s.Rows {
+		writeRow(&b, i+2, row, 0)
+	}
+	b.WriteString(`</sheetData>`)
+
+	fmt.Fprintf(&b, `<autoFilter ref="A1:%s%d"/>`, columnName(len(s.Columns)-1), len(s.Rows)+1)
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, number int, cells []string, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, number)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		ref := fmt.Sprintf("%s%d", columnName(i), number)
+		if style > 0 {
+			fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr">`, ref, style)
+		} else {
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr">`, ref)
+		}
+		fmt.Fprintf(b, `<is><t xml:space="preserve">%s</t></is></c>`, escapeXML(cell))
+	}
+	b.WriteString(`</row>`)
+}
+
+// columnName returns the letters of a zero-based column index: A, B, ... AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escapeXML escapes text for an element or attribute; characters XML can't
+// hold are replaced
+func escapeXML(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,419 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/cache"
+	"ga4admin/internal/config"
+	"ga4admin/internal/preset"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn" // Works, but something should be looked at
+	Fail Status = "fail"
+)
+
+// Sections checks are grouped under, in the order they run
+const (
+	SectionConfig      = "Config"
+	SectionPresets     = "Presets"
+	SectionPermissions = "Permissions"
+	SectionCache       = "Cache"
+	SectionNetwork     = "Network"
+)
+
+// Sections lists the sections in checklist order
+var Sections = []string{SectionConfig, SectionPresets, SectionPermissions, SectionCache, SectionNetwork}
+
+// Check is one line of the checklist
+type Check struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Fix     string `json:"fix,omitempty"` // What to run or change when the check didn't pass
+}
+
+// Options select which checks run
+type Options struct {
+	// Network checks that the Google endpoints are reachable
+	Network bool
+	// NetworkTimeout bounds each endpoint check; 0 means 10 seconds
+	NetworkTimeout time.Duration
+}
+
+// Run checks the data directory and returns the checklist. It only reads,
+// except that opening an existing cache applies pending schema migrations.
+func Run(ctx context.Context, opts Options) []Check {
+	var checks []Check
+	add := func(check Check) { checks = append(checks, check) }
+
+	appConfig := checkConfig(add)
+	presets := checkPresets(add, appConfig)
+	checkPermissions(add, presets)
+	checkCaches(add, presets)
+	if opts.Network {
+		checkNetwork(ctx, add, opts.NetworkTimeout)
+	}
+	return checks
+}
+
+// Failed reports whether any check failed
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfig lints config.yaml and returns it, or nil when it can't be read
+func checkConfig(add func(Check)) *config.AppConfig {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		add(Check{Section: SectionConfig, Name: "Data directory", Status: Fail, Detail: err.Error()})
+		return nil
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		add(Check{Section: SectionConfig, Name: "config.yaml", Status: Warn, Detail: configPath + " doesn't exist yet",
+			Fix: "ga4admin config set --client-id <id> --client-secret <secret>"})
+		return &config.AppConfig{}
+	}
+
+	unknownKeys, problems, err := config.LintConfigFile()
+	if err != nil {
+		add(Check{Section: SectionConfig, Name: "config.yaml", Status: Fail, Detail: err.Error(),
+			Fix: "fix the YAML syntax in " + configPath + " or remove it and run 'ga4admin config set' again"})
+		return nil
+	}
+	if len(unknownKeys) == 0 && len(problems) == 0 {
+		add(Check{Section: SectionConfig, Name: "config.yaml", Status: Pass, Detail: configPath})
+	}
+	if len(unknownKeys) > 0 {
+		// Ignored when loading, so a misspelled setting silently keeps its default
+		add(Check{Section: SectionConfig, Name: "config.yaml", Status: Warn, Detail: strings.Join(unknownKeys, "; "),
+			Fix: "remove or correct the unknown keys in " + configPath})
+	}
+	for _, problem := range problems {
+		add(Check{Section: SectionConfig, Name: "config.yaml", Status: Fail, Detail: problem.Error(),
+			Fix: "edit " + configPath + " or set the value with 'ga4admin config set'"})
+	}
+
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	return appConfig
+}
+
+// checkPresets checks that each preset file parses and has usable
+// credentials, and returns the names of those that parsed
+func checkPresets(add func(Check), appConfig *config.AppConfig) []string {
+	presetsDir, err := preset.GetPresetsDir()
+	if err != nil {
+		add(Check{Section: SectionPresets, Name: "Presets", Status: Fail, Detail: err.Error()})
+		return nil
+	}
+	entries, err := os.ReadDir(presetsDir)
+	if err != nil && !os.IsNotExist(err) {
+		add(Check{Section: SectionPresets, Name: "Presets", Status: Fail, Detail: err.Error()})
+		return nil
+	}
+
+	var names []string
+	needsOAuthClient := false
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), preset.PresetFileExt) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), preset.PresetFileExt)
+		path := filepath.Join(presetsDir, entry.Name())
+
+		// Read directly: preset.LoadPreset rewrites the file to record its use
+		p, problem := parsePresetFile(path, name)
+		if p == nil {
+			add(Check{Section: SectionPresets, Name: name, Status: Fail, Detail: problem,
+				Fix: "fix or delete " + path + ", then recreate it with 'ga4admin preset create'"})
+			continue
+		}
+		names = append(names, name)
+		if !api.UsesServiceAccount(p) {
+			needsOAuthClient = true
+		}
+
+		check := Check{Section: SectionPresets, Name: name, Status: Pass, Detail: describePreset(p)}
+		switch {
+		case api.UsesServiceAccount(p):
+			if _, err := api.LoadServiceAccountKey(p.ServiceAccountKey); err != nil {
+				check.Status, check.Detail = Fail, err.Error()
+				check.Fix = fmt.Sprintf("ga4admin preset delete %s && ga4admin preset create %s --service-account <key.json>", name, name)
+			}
+		case p.RefreshToken == "":
+			check.Status, check.Detail = Fail, "no refresh token or service account key"
+			check.Fix = fmt.Sprintf("ga4admin preset reauth %s", name)
+		case p.NeedsReauth:
+			check.Status, check.Detail = Fail, "Google rejected the refresh token"
+			check.Fix = fmt.Sprintf("ga4admin preset reauth %s", name)
+		case problem != "":
+			check.Status, check.Detail = Warn, problem
+			check.Fix = "remove the unknown keys from " + path
+		case p.SyncedAt.IsZero():
+			check.Status, check.Detail = Warn, "accounts and properties were never synced"
+			check.Fix = fmt.Sprintf("ga4admin preset use %s && ga4admin preset sync", name)
+		}
+		add(check)
+	}
+
+	if len(names) == 0 && len(entries) == 0 {
+		add(Check{Section: SectionPresets, Name: "Presets", Status: Warn, Detail: "no presets yet",
+			Fix: "ga4admin preset create <name> --refresh-token <token>"})
+	}
+
+	if appConfig != nil {
+		switch {
+		case appConfig.ActivePreset == "":
+			if len(names) > 0 {
+				add(Check{Section: SectionPresets, Name: "Active preset", Status: Warn, Detail: "none selected",
+					Fix: "ga4admin preset use <name>"})
+			}
+		case !contains(names, appConfig.ActivePreset):
+			add(Check{Section: SectionPresets, Name: "Active preset", Status: Fail,
+				Detail: fmt.Sprintf("'%s' doesn't exist", appConfig.ActivePreset), Fix: "ga4admin preset use <name>"})
+		default:
+			add(Check{Section: SectionPresets, Name: "Active preset", Status: Pass, Detail: appConfig.ActivePreset})
+		}
+
+		// Refresh tokens are exchanged with the OAuth client they were issued to
+		if needsOAuthClient && (appConfig.ClientID == "" || appConfig.ClientSecret == "") {
+			add(Check{Section: SectionConfig, Name: "OAuth client", Status: Fail, Detail: "client ID and secret are not set",
+				Fix: "ga4admin config set --client-id <id> --client-secret <secret>"})
+		}
+
+		for _, workspace := range appConfig.Workspaces {
+			for _, member := range workspace.Members {
+				if !contains(names, member.Preset) {
+					add(Check{Section: SectionPresets, Name: "Workspace " + workspace.Name, Status: Fail,
+						Detail: fmt.Sprintf("member %s uses missing preset '%s'", member.PropertyID, member.Preset),
+						Fix:    fmt.Sprintf("ga4admin workspace remove %s %s %s", workspace.Name, member.Preset, member.PropertyID)})
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// parsePresetFile parses a preset strictly. It returns nil and the reason
+// when the file can't be used, or the preset and a warning about unknown keys.
+func parsePresetFile(path, name string) (*config.Preset, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	var p config.Preset
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Sprintf("invalid YAML: %v", err)
+	}
+	if p.Name != name {
+		return nil, fmt.Sprintf("file is named %s but holds preset '%s'", filepath.Base(path), p.Name)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config.Preset{}); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return &p, strings.Join(typeErr.Errors, "; ")
+		}
+		return &p, err.Error()
+	}
+	return &p, ""
+}
+
+func describePreset(p *config.Preset) string {
+	parts := []string{}
+	if api.UsesServiceAccount(p) {
+		parts = append(parts, "service account")
+	} else if p.UserEmail != "" {
+		parts = append(parts, p.UserEmail)
+	}
+	properties := 0
+	for _, account := range p.Accounts {
+		properties += len(account.Properties)
+	}
+	parts = append(parts, fmt.Sprintf("%d account(s), %d property(ies)", len(p.Accounts), properties))
+	return strings.Join(parts, ", ")
+}
+
+// checkPermissions checks that credentials aren't readable by other users:
+// the data, presets and tokens directories 0700, their files 0600
+func checkPermissions(add func(Check), presets []string) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return
+	}
+
+	checkMode(add, configDir, 0700)
+	checkMode(add, filepath.Join(configDir, config.ConfigFileName), 0600)
+
+	presetsDir := filepath.Join(configDir, preset.PresetsDirName)
+	checkMode(add, presetsDir, 0700)
+	for _, name := range presets {
+		checkMode(add, filepath.Join(presetsDir, name+preset.PresetFileExt), 0600)
+	}
+
+	tokensDir := filepath.Join(configDir, preset.TokensDirName)
+	checkMode(add, tokensDir, 0700)
+	tokens, _ := filepath.Glob(filepath.Join(tokensDir, "*"+preset.TokenFileExt))
+	for _, path := range tokens {
+		checkMode(add, path, 0600)
+	}
+}
+
+// checkMode flags a path that grants its group or other users any access.
+// Paths that don't exist yet are skipped.
+func checkMode(add func(Check), path string, want os.FileMode) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		add(Check{Section: SectionPermissions, Name: path, Status: Fail, Detail: err.Error()})
+		return
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0077 == 0 {
+		add(Check{Section: SectionPermissions, Name: path, Status: Pass, Detail: fmt.Sprintf("%04o", mode)})
+		return
+	}
+	add(Check{Section: SectionPermissions, Name: path, Status: Fail,
+		Detail: fmt.Sprintf("%04o, should be %04o", mode, want),
+		Fix:    fmt.Sprintf("chmod %o %s", want, path)})
+}
+
+// checkCaches opens each preset's cache that exists. A cache that can't be
+// opened is usually locked by another ga4admin process or corrupted.
+func checkCaches(add func(Check), presets []string) {
+	for _, name := range presets {
+		path, err := cache.CachePath(name)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			add(Check{Section: SectionCache, Name: name, Status: Pass, Detail: "not created yet"})
+			continue
+		}
+
+		client, err := cache.NewCacheClient(name)
+		if err != nil {
+			add(Check{Section: SectionCache, Name: name, Status: Fail, Detail: err.Error(),
+				Fix: fmt.Sprintf("close other ga4admin processes using preset '%s'; if it still fails, delete %s (it is rebuilt on demand)", name, path)})
+			continue
+		}
+		client.Close()
+		add(Check{Section: SectionCache, Name: name, Status: Pass, Detail: fmt.Sprintf("%s (%s)", path, cache.Backend)})
+	}
+}
+
+// checkNetwork connects to the Google endpoints through the configured
+// proxy and CA bundle. Any HTTP response counts as reachable.
+func checkNetwork(ctx context.Context, add func(Check), timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	client, err := api.NewHTTPClient()
+	if err != nil {
+		add(Check{Section: SectionNetwork, Name: "HTTP client", Status: Fail, Detail: err.Error(),
+			Fix: "check the proxy and CA bundle with 'ga4admin config show'"})
+		return
+	}
+
+	endpoints := map[string]string{"OAuth": "https://oauth2.googleapis.com"}
+	if endpoint, err := config.GetAdminAPIEndpoint(); err == nil {
+		endpoints["Admin API"] = endpoint
+	}
+	if endpoint, err := config.GetDataAPIEndpoint(); err == nil {
+		endpoints["Data API"] = endpoint
+	}
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		endpoint := endpoints[name]
+		start := time.Now()
+		err := ping(ctx, client, endpoint, timeout)
+		if err == nil {
+			add(Check{Section: SectionNetwork, Name: name, Status: Pass,
+				Detail: fmt.Sprintf("%s (%dms)", endpoint, time.Since(start).Milliseconds())})
+			continue
+		}
+		add(Check{Section: SectionNetwork, Name: name, Status: Fail, Detail: fmt.Sprintf("%s: %v", endpoint, err), Fix: networkFix(err)})
+	}
+}
+
+func ping(ctx context.Context, client *http.Client, endpoint string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// networkFix suggests a fix for the common ways corporate networks break
+// connections
+func networkFix(err error) string {
+	var dnsErr *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	switch {
+	case errors.As(err, &dnsErr):
+		return "check DNS, or set a proxy with 'ga4admin config set --proxy <url>'"
+	case errors.As(err, &unknownAuthority):
+		return "a proxy is intercepting TLS - trust its CA with 'ga4admin config set --ca-bundle <pem>'"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the connection timed out - check the firewall or proxy settings"
+	}
+	return "check the firewall, proxy (--proxy) and CA bundle (--ca-bundle) settings"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
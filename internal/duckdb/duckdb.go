@@ -0,0 +1,11 @@
+// Package duckdb opens DuckDB databases. The driver needs CGO; builds without
+// it compile but report ErrUnavailable when a database is opened.
+package duckdb
+
+import "errors"
+
+// ErrUnavailable is returned by Open in builds without CGO
+var ErrUnavailable = errors.New("DuckDB is not available in this build (built with CGO_ENABLED=0)")
+
+// ErrNotSelect is returned by CheckSelect for anything but a single SELECT
+var ErrNotSelect = errors.New("only a single SELECT statement is allowed")
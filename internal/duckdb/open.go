@@ -0,0 +1,57 @@
+//go:build cgo
+
+package duckdb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/marcboeker/go-duckdb"
+)
+
+// Available reports whether this build can open DuckDB databases
+const Available = true
+
+// Open opens a DuckDB database. An empty dsn opens an in-memory database.
+func Open(dsn string) (*sql.DB, error) {
+	return sql.Open("duckdb", dsn)
+}
+
+// CheckSelect parses query with DuckDB's own parser and returns ErrNotSelect
+// unless it is exactly one SELECT statement. Nothing is executed.
+func CheckSelect(conn *sql.Conn, query string) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*duckdb.Conn)
+		if !ok {
+			return fmt.Errorf("not a DuckDB connection")
+		}
+		// Unlike PrepareContext, Prepare refuses queries of several
+		// statements instead of running all but the last
+		stmt, err := c.Prepare(query)
+		if err != nil && strings.Contains(err.Error(), "multi-statement") {
+			return ErrNotSelect
+		}
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		statementType, err := stmt.(*duckdb.Stmt).StatementType()
+		if err != nil {
+			return err
+		}
+		if statementType != duckdb.STATEMENT_TYPE_SELECT {
+			return ErrNotSelect
+		}
+		return nil
+	})
+}
+
+// PlainValue converts values of DuckDB's own types, as scanned into an
+// interface{}, to plain Go values: DECIMALs become float64
+func PlainValue(value interface{}) interface{} {
+	if decimal, ok := value.(duckdb.Decimal); ok {
+		return decimal.Float64()
+	}
+	return value
+}
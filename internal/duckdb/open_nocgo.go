@@ -0,0 +1,23 @@
+//go:build !cgo
+
+package duckdb
+
+import "database/sql"
+
+// Available reports whether this build can open DuckDB databases
+const Available = false
+
+// Open always fails: go-duckdb can't be built without CGO
+func Open(dsn string) (*sql.DB, error) {
+	return nil, ErrUnavailable
+}
+
+// CheckSelect always fails: go-duckdb can't be built without CGO
+func CheckSelect(conn *sql.Conn, query string) error {
+	return ErrUnavailable
+}
+
+// PlainValue returns value unchanged
+func PlainValue(value interface{}) interface{} {
+	return value
+}
@@ -0,0 +1,72 @@
+// Package exitcode defines the process exit codes ga4admin commands use, so
+// wrapper scripts can branch on the kind of failure instead of matching
+// error text
+package exitcode
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/oauth2"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/cache"
+	"ga4admin/internal/export"
+	"ga4admin/internal/preset"
+	"ga4admin/internal/query"
+	"ga4admin/internal/results"
+)
+
+// Exit codes. The values are part of the CLI's interface; don't renumber them.
+const (
+	OK         = 0
+	Failure    = 1 // Anything not covered below
+	Auth       = 2 // No active preset, expired or revoked token, missing OAuth scope
+	Quota      = 3 // GA4 quota exhausted
+	Validation = 4 // Invalid flags, arguments, query files or requests GA4 rejected as invalid
+	Network    = 5 // GA4 unreachable: connection, DNS or timeout failures
+	NotFound   = 6 // Property, resource, preset, result or collection run doesn't exist or isn't accessible
+)
+
+// For classifies an error into an exit code. Unrecognized errors are Failure.
+func For(err error) int {
+	if err == nil {
+		return OK
+	}
+
+	if errors.Is(err, api.ErrReauthRequired) || errors.Is(err, api.ErrMissingScope) || errors.Is(err, preset.ErrNoActivePreset) {
+		return Auth
+	}
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return Auth
+	}
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.IsUnauthenticated(), apiErr.IsInsufficientScope():
+			return Auth
+		case apiErr.IsQuotaExhausted():
+			return Quota
+		case apiErr.IsInvalidArgument():
+			return Validation
+		case apiErr.Status == "NOT_FOUND", apiErr.Status == "PERMISSION_DENIED":
+			return NotFound
+		}
+	}
+
+	switch {
+	case errors.Is(err, api.ErrNotAccessible), errors.Is(err, preset.ErrNotFound), errors.Is(err, results.ErrNotFound),
+		errors.Is(err, export.ErrRunNotFound):
+		return NotFound
+	case errors.Is(err, query.ErrInvalidQuery), errors.Is(err, cache.ErrNamedTableExists),
+		errors.Is(err, cache.ErrResultViewExists), errors.Is(err, results.ErrInvalidView),
+		errors.Is(err, cache.ErrRollupExists), errors.Is(err, results.ErrInvalidRollup),
+		errors.Is(err, cache.ErrInvalidTag):
+		return Validation
+	case errors.Is(err, context.DeadlineExceeded), api.IsNetworkError(err):
+		return Network
+	}
+	return Failure
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"ga4admin/internal/duckdb"
+)
+
+// RunComparison is what changed between two collection runs, read from the
+// property_changes, dimension_count_deltas and clarisights_changes views
+type RunComparison struct {
+	From             CollectionRun     `json:"from"`
+	To               CollectionRun     `json:"to"`
+	Gained           []PropertyRef     `json:"gained"`
+	Lost             []PropertyRef     `json:"lost"`
+	DimensionDeltas  []DimensionDelta  `json:"dimension_deltas"` // Properties whose count changed
+	Unchanged        int               `json:"unchanged"`        // Properties in both runs with the same count
+	ReadinessChanges []ReadinessChange `json:"readiness_changes"`
+}
+
+// PropertyRef identifies a property in a run comparison
+type PropertyRef struct {
+	PropertyID   string `json:"property_id"`
+	PropertyName string `json:"property_name"`
+	AccountName  string `json:"account_name"`
+}
+
+// DimensionDelta is a property's custom dimension count in both runs
+type DimensionDelta struct {
+	PropertyRef
+	FromCount int `json:"from_count"`
+	ToCount   int `json:"to_count"`
+	Delta     int `json:"delta"`
+}
+
+// ReadinessChange is a property whose Clarisights readiness (a custom
+// channel group) changed between the runs
+type ReadinessChange struct {
+	PropertyRef
+	Ready            bool   `json:"ready"` // Readiness in the later run
+	FromChannelGroup string `json:"from_channel_group,omitempty"`
+	ToChannelGroup   string `json:"to_channel_group,omitempty"`
+}
+
+// CompareRuns compares two collection runs. A zero toRun means the latest run
+// and a zero fromRun the run before toRun.
+func CompareRuns(ctx context.Context, dbPath string, fromRun, toRun int64) (*RunComparison, error) {
+	runs, err := ListRuns(ctx, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) < 2 {
+		return nil, fmt.Errorf("%w: comparing needs two runs, %s has %d", ErrRunNotFound, dbPath, len(runs))
+	}
+
+	toIndex := len(runs) - 1
+	if toRun != 0 {
+		if toIndex = runIndex(runs, toRun); toIndex < 0 {
+			return nil, fmt.Errorf("%w: run %d", ErrRunNotFound, toRun)
+		}
+	}
+	fromIndex := toIndex - 1
+	if fromRun != 0 {
+		if fromIndex = runIndex(runs, fromRun); fromIndex < 0 {
+			return nil, fmt.Errorf("%w: run %d", ErrRunNotFound, fromRun)
+		}
+	}
+	if fromIndex < 0 {
+		return nil, fmt.Errorf("%w: no run before run %d", ErrRunNotFound, runs[toIndex].RunID)
+	}
+	if fromIndex >= toIndex {
+		return nil, fmt.Errorf("--from run %d must be earlier than --to run %d", runs[fromIndex].RunID, runs[toIndex].RunID)
+	}
+
+	comparison := &RunComparison{From: runs[fromIndex], To: runs[toIndex]}
+	from, to := comparison.From.RunID, comparison.To.RunID
+
+	db, err := duckdb.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT change, property_id, property_name, account_name
+		FROM property_changes
+		WHERE from_run_id = ? AND to_run_id = ?
+		ORDER BY account_name, property_name, property_id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare properties: %w", err)
+	}
+	for rows.Next() {
+		var change string
+		var property PropertyRef
+		if err := rows.Scan(&change, &property.PropertyID, &property.PropertyName, &property.AccountName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if change == "gained" {
+			comparison.Gained = append(comparison.Gained, property)
+		} else {
+			comparison.Lost = append(comparison.Lost, property)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT property_id, property_name, account_name, from_count, to_count, delta
+		FROM dimension_count_deltas
+		WHERE from_run_id = ? AND to_run_id = ?
+		ORDER BY abs(delta) DESC, account_name, property_name, property_id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare dimension counts: %w", err)
+	}
+	for rows.Next() {
+		var delta DimensionDelta
+		if err := rows.Scan(&delta.PropertyID, &delta.PropertyName, &delta.AccountName,
+			&delta.FromCount, &delta.ToCount, &delta.Delta); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if delta.Delta == 0 {
+			comparison.Unchanged++
+			continue
+		}
+		comparison.DimensionDeltas = append(comparison.DimensionDeltas, delta)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT property_id, property_name, account_name, change,
+		       COALESCE(from_channel_group, ''), COALESCE(to_channel_group, '')
+		FROM clarisights_changes
+		WHERE from_run_id = ? AND to_run_id = ?
+		ORDER BY change, account_name, property_name, property_id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare Clarisights readiness: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change ReadinessChange
+		var kind string
+		if err := rows.Scan(&change.PropertyID, &change.PropertyName, &change.AccountName, &kind,
+			&change.FromChannelGroup, &change.ToChannelGroup); err != nil {
+			return nil, err
+		}
+		change.Ready = kind == "became_ready"
+		comparison.ReadinessChanges = append(comparison.ReadinessChanges, change)
+	}
+	return comparison, rows.Err()
+}
+
+func runIndex(runs []CollectionRun, runID int64) int {
+	for i, run := range runs {
+		if run.RunID == runID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,281 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/duckdb"
+)
+
+// DBTTable is one table or view introspected from a DuckDB database
+type DBTTable struct {
+	Name       string
+	IsView     bool
+	Columns    []DBTColumn
+	PrimaryKey []string
+}
+
+// DBTColumn is one column of an introspected table
+type DBTColumn struct {
+	Name     string
+	DataType string
+	Nullable bool
+}
+
+// DBTGenerateResult lists what GenerateDBTProject wrote
+type DBTGenerateResult struct {
+	SourcesFile string
+	Models      []string
+	Tables      []DBTTable
+}
+
+// sources.yml structure (dbt schema version 2)
+type dbtSourcesFile struct {
+	Version int         `yaml:"version"`
+	Sources []dbtSource `yaml:"sources"`
+}
+
+type dbtSource struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Schema      string           `yaml:"schema"`
+	Tables      []dbtSourceTable `yaml:"tables"`
+}
+
+type dbtSourceTable struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Columns     []dbtSourceColumn `yaml:"columns"`
+}
+
+type dbtSourceColumn struct {
+	Name     string   `yaml:"name"`
+	DataType string   `yaml:"data_type"`
+	Tests    []string `yaml:"tests,omitempty"`
+}
+
+// IntrospectDuckDB lists the tables and views in the database's main schema,
+// leaving out the migration bookkeeping table
+func IntrospectDuckDB(ctx context.Context, dbPath string) ([]DBTTable, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	db, err := duckdb.Open(dbPath + "?access_mode=read_only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, table_type
+		FROM information_schema.tables
+		WHERE table_schema = 'main' AND table_name <> 'schema_version'
+		ORDER BY table_type, table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []DBTTable
+	for rows.Next() {
+		var table DBTTable
+		var tableType string
+		if err := rows.Scan(&table.Name, &tableType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		table.IsView = tableType == "VIEW"
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		if err := loadColumns(ctx, db, &tables[i]); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", tables[i].Name, err)
+		}
+		if err := loadPrimaryKey(ctx, db, &tables[i]); err != nil {
+			return nil, fmt.Errorf("failed to read primary key of %s: %w", tables[i].Name, err)
+		}
+	}
+
+	return tables, nil
+}
+
+func loadColumns(ctx context.Context, db *sql.DB, table *DBTTable) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT column_name, data_type, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = 'main' AND table_name = ?
+		ORDER BY ordinal_position
+	`, table.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column DBTColumn
+		var nullable string
+		if err := rows.Scan(&column.Name, &column.DataType, &nullable); err != nil {
+			return err
+		}
+		column.Nullable = nullable == "YES"
+		table.Columns = append(table.Columns, column)
+	}
+	return rows.Err()
+}
+
+func loadPrimaryKey(ctx context.Context, db *sql.DB, table *DBTTable) error {
+	if table.IsView {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT unnest(constraint_column_names)
+		FROM duckdb_constraints()
+		WHERE schema_name = 'main' AND table_name = ? AND constraint_type = 'PRIMARY KEY'
+	`, table.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return err
+		}
+		table.PrimaryKey = append(table.PrimaryKey, column)
+	}
+	return rows.Err()
+}
+
+// GenerateDBTProject writes models/staging/sources.yml and one
+// stg_<source>__<table>.sql starter model per table or view into outputDir.
+// Existing staging models are left untouched so hand edits survive reruns.
+func GenerateDBTProject(ctx context.Context, dbPath, outputDir, sourceName string) (*DBTGenerateResult, error) {
+	tables, err := IntrospectDuckDB(ctx, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables found in %s", dbPath)
+	}
+
+	stagingDir := filepath.Join(outputDir, "models", "staging")
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	result := &DBTGenerateResult{
+		SourcesFile: filepath.Join(stagingDir, "sources.yml"),
+		Tables:      tables,
+	}
+
+	sources := dbtSourcesFile{
+		Version: 2,
+		Sources: []dbtSource{{
+			Name:        sourceName,
+			Description: fmt.Sprintf("GA4 property exports parsed by 'ga4admin export parse-json' (%s)", filepath.Base(dbPath)),
+			Schema:      "main",
+		}},
+	}
+	for _, table := range tables {
+		sources.Sources[0].Tables = append(sources.Sources[0].Tables, sourceTable(table))
+	}
+
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(sources); err != nil {
+		return nil, fmt.Errorf("failed to marshal sources.yml: %w", err)
+	}
+	encoder.Close()
+	if err := os.WriteFile(result.SourcesFile, data.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write sources.yml: %w", err)
+	}
+
+	for _, table := range tables {
+		modelPath := filepath.Join(stagingDir, fmt.Sprintf("stg_%s__%s.sql", sourceName, table.Name))
+		if _, err := os.Stat(modelPath); err == nil {
+			continue
+		}
+		if err := os.WriteFile(modelPath, []byte(stagingModel(sourceName, table)), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write staging model: %w", err)
+		}
+		result.Models = append(result.Models, modelPath)
+	}
+
+	return result, nil
+}
+
+func sourceTable(table DBTTable) dbtSourceTable {
+	kind := "Table"
+	if table.IsView {
+		kind = "Analysis view"
+	}
+
+	sourceTable := dbtSourceTable{
+		Name:        table.Name,
+		Description: fmt.Sprintf("%s created by ga4admin", kind),
+	}
+
+	isKey := make(map[string]bool)
+	for _, column := range table.PrimaryKey {
+		isKey[column] = true
+	}
+
+	for _, column := range table.Columns {
+		sourceColumn := dbtSourceColumn{
+			Name:     column.Name,
+			DataType: strings.ToLower(column.DataType),
+		}
+		switch {
+		case isKey[column.Name] && len(table.PrimaryKey) == 1:
+			sourceColumn.Tests = []string{"unique", "not_null"}
+		case isKey[column.Name] || !column.Nullable:
+			sourceColumn.Tests = []string{"not_null"}
+		}
+		sourceTable.Columns = append(sourceTable.Columns, sourceColumn)
+	}
+
+	return sourceTable
+}
+
+// stagingModel renders the conventional source -> renamed CTE starter model
+func stagingModel(sourceName string, table DBTTable) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "with source as (\n\n    select * from {{ source('%s', '%s') }}\n\n),\n\n", sourceName, table.Name)
+	b.WriteString("renamed as (\n\n    select\n")
+	for i, column := range table.Columns {
+		separator := ","
+		if i == len(table.Columns)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&b, "        %s%s\n", quoteIdentifier(column.Name), separator)
+	}
+	b.WriteString("\n    from source\n\n)\n\nselect * from renamed\n")
+	return b.String()
+}
+
+// quoteIdentifier quotes column names that aren't plain lower-case SQL identifiers
+func quoteIdentifier(name string) string {
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	return name
+}
@@ -0,0 +1,295 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/duckdb"
+)
+
+// DimensionMatrix shows which custom dimensions each property of an account
+// defines, with the inconsistencies between properties
+type DimensionMatrix struct {
+	AccountID  string            `json:"account_id"`
+	Source     string            `json:"source"` // "metadata" or the parsed database path
+	Properties []PropertyRef     `json:"properties"`
+	Dimensions []MatrixDimension `json:"dimensions"`
+}
+
+// MatrixDimension is one row of the matrix: a custom dimension API name and
+// its display name on each property that defines it
+type MatrixDimension struct {
+	APIName   string            `json:"api_name"`
+	Scope     string            `json:"scope"`
+	Parameter string            `json:"parameter"`
+	Names     map[string]string `json:"names"` // Property ID to display name; absent where undefined
+	Issues    []string          `json:"issues,omitempty"`
+}
+
+// matrixDefinition is a custom dimension defined on one property
+type matrixDefinition struct {
+	PropertyID string
+	APIName    string
+	UIName     string
+}
+
+// BuildDimensionMatrix builds the matrix of an account from each property's
+// Data API metadata, which the data client serves from cache when it can.
+// Properties whose metadata cannot be fetched are reported through warn and
+// left out.
+func BuildDimensionMatrix(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, accountID string, warn func(propertyID string, err error)) (*DimensionMatrix, error) {
+	properties, err := adminClient.ListProperties(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list properties: %w", err)
+	}
+
+	var refs []PropertyRef
+	var definitions []matrixDefinition
+	for _, property := range properties {
+		metadata, err := dataClient.GetMetadata(ctx, property.ID)
+		if err != nil {
+			if warn != nil {
+				warn(property.ID, err)
+			}
+			continue
+		}
+
+		refs = append(refs, PropertyRef{PropertyID: property.ID, PropertyName: property.DisplayName})
+		for _, dim := range metadata.Dimensions {
+			if dim.CustomDefinition {
+				definitions = append(definitions, matrixDefinition{property.ID, dim.APIName, dim.UIName})
+			}
+		}
+	}
+
+	return newDimensionMatrix(accountID, "metadata", refs, definitions), nil
+}
+
+// LoadDimensionMatrix builds the matrix of an account from a database written
+// by 'export parse-json', as of each property's latest collection run
+func LoadDimensionMatrix(ctx context.Context, dbPath, accountID string) (*DimensionMatrix, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	parser := NewJSONParser(dbPath, "")
+	if err := parser.initializeDatabase(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	db, err := duckdb.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.property_id, p.property_name, p.account_name
+		FROM properties p
+		JOIN latest_property_runs USING (run_id, property_id)
+		WHERE p.account_id = ?
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read properties: %w", err)
+	}
+	var refs []PropertyRef
+	for rows.Next() {
+		var ref PropertyRef
+		if err := rows.Scan(&ref.PropertyID, &ref.PropertyName, &ref.AccountName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT cd.property_id, cd.api_name, COALESCE(cd.ui_name, '')
+		FROM custom_dimensions cd
+		JOIN latest_property_runs USING (run_id, property_id)
+		JOIN properties p USING (run_id, property_id)
+		WHERE p.account_id = ? AND (cd.custom_definition OR cd.api_name LIKE 'custom%:%')
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom dimensions: %w", err)
+	}
+	defer rows.Close()
+
+	var definitions []matrixDefinition
+	for rows.Next() {
+		var definition matrixDefinition
+		if err := rows.Scan(&definition.PropertyID, &definition.APIName, &definition.UIName); err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return newDimensionMatrix(accountID, dbPath, refs, definitions), nil
+}
+
+func newDimensionMatrix(accountID, source string, properties []PropertyRef, definitions []matrixDefinition) *DimensionMatrix {
+	sort.Slice(properties, func(i, j int) bool {
+		if properties[i].PropertyName != properties[j].PropertyName {
+			return properties[i].PropertyName < properties[j].PropertyName
+		}
+		return properties[i].PropertyID < properties[j].PropertyID
+	})
+
+	rows := make(map[string]*MatrixDimension)
+	for _, definition := range definitions {
+		row, ok := rows[definition.APIName]
+		if !ok {
+			_, parameter, _ := strings.Cut(definition.APIName, ":")
+			row = &MatrixDimension{
+				APIName:   definition.APIName,
+				Scope:     DimensionScope(definition.APIName),
+				Parameter: parameter,
+				Names:     make(map[string]string),
+			}
+			rows[definition.APIName] = row
+		}
+		row.Names[definition.PropertyID] = definition.UIName
+	}
+
+	matrix := &DimensionMatrix{AccountID: accountID, Source: source, Properties: properties}
+	for _, row := range rows {
+		matrix.Dimensions = append(matrix.Dimensions, *row)
+	}
+
+	// Spelling variants of a parameter sort next to each other
+	sort.Slice(matrix.Dimensions, func(i, j int) bool {
+		a, b := matrix.Dimensions[i], matrix.Dimensions[j]
+		if ka, kb := normalizeParameter(a.Parameter), normalizeParameter(b.Parameter); ka != kb {
+			return ka < kb
+		}
+		return a.APIName < b.APIName
+	})
+
+	findInconsistencies(matrix.Dimensions)
+	return matrix
+}
+
+// findInconsistencies flags dimensions whose display name differs between
+// properties, parameters registered with more than one scope, and parameter
+// names that differ only in case or separators
+func findInconsistencies(dimensions []MatrixDimension) {
+	for i := range dimensions {
+		row := &dimensions[i]
+
+		counts := make(map[string]int)
+		for _, name := range row.Names {
+			counts[name]++
+		}
+		if len(counts) > 1 {
+			names := make([]string, 0, len(counts))
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(a, b int) bool {
+				if counts[names[a]] != counts[names[b]] {
+					return counts[names[a]] > counts[names[b]]
+				}
+				return names[a] < names[b]
+			})
+			for j, name := range names {
+				names[j] = fmt.Sprintf("'%s' (%d)", name, counts[name])
+			}
+			row.Issues = append(row.Issues, "display names differ: "+strings.Join(names, ", "))
+		}
+
+		for _, other := range dimensions {
+			if other.APIName == row.APIName {
+				continue
+			}
+			switch {
+			case other.Parameter == row.Parameter:
+				row.Issues = append(row.Issues, fmt.Sprintf("also %s-scoped as %s on %d propert%s", other.Scope, other.APIName, len(other.Names), plural(len(other.Names))))
+			case normalizeParameter(other.Parameter) == normalizeParameter(row.Parameter):
+				row.Issues = append(row.Issues, fmt.Sprintf("naming variant of %s (%d propert%s)", other.APIName, len(other.Names), plural(len(other.Names))))
+			}
+		}
+	}
+}
+
+// normalizeParameter folds case and drops separators, so article_author,
+// articleAuthor and Article-Author compare equal
+func normalizeParameter(parameter string) string {
+	return nonFieldChars.ReplaceAllString(strings.ToLower(parameter), "")
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+// Inconsistent returns the dimensions with at least one issue
+func (m *DimensionMatrix) Inconsistent() []MatrixDimension {
+	var inconsistent []MatrixDimension
+	for _, row := range m.Dimensions {
+		if len(row.Issues) > 0 {
+			inconsistent = append(inconsistent, row)
+		}
+	}
+	return inconsistent
+}
+
+// WriteDimensionMatrixCSV writes one row per dimension with a column per
+// property holding the dimension's display name there
+func WriteDimensionMatrixCSV(matrix *DimensionMatrix, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	headers := []string{"api_name", "scope", "parameter", "property_count", "issues"}
+	for _, property := range matrix.Properties {
+		headers = append(headers, fmt.Sprintf("%s (%s)", property.PropertyName, property.PropertyID))
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+
+	for _, row := range matrix.Dimensions {
+		record := []string{row.APIName, row.Scope, row.Parameter, fmt.Sprintf("%d", len(row.Names)), strings.Join(row.Issues, "; ")}
+		for _, property := range matrix.Properties {
+			record = append(record, row.Names[property.PropertyID])
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteDimensionMatrixJSON writes the matrix as indented JSON
+func WriteDimensionMatrixJSON(matrix *DimensionMatrix, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(matrix); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Decoder turns one export file into a PropertyExport
+type Decoder func(data []byte) (*PropertyExport, error)
+
+// Format is a JSON export layout ParseAllJSON can read. Files are matched to
+// a format by a top-level marker field, so collectors that emit their own
+// layout can be parsed next to standard exports. A collector's format lives
+// in its own file in this package and registers itself:
+//
+//	func init() {
+//		RegisterFormat(Format{
+//			Name:        "collector-v3",
+//			Marker:      "collector_schema",
+//			MarkerValue: "v3",
+//			Decode:      decodeCollectorV3,
+//		})
+//	}
+type Format struct {
+	Name        string  // Shown in dry runs and errors, e.g. "collector-v3"
+	Marker      string  // Top-level key that identifies the layout, e.g. "collector_schema"
+	MarkerValue string  // If set, the marker must be this JSON string
+	Decode      Decoder // Converts a matching file
+}
+
+// DefaultFormatName names the PropertyExport layout, used for files that
+// carry no registered marker
+const DefaultFormatName = "property_export"
+
+var defaultFormat = Format{
+	Name:   DefaultFormatName,
+	Decode: decodePropertyExport,
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   []Format
+)
+
+// RegisterFormat adds a layout to the parser, typically from an init
+// function. Markers are checked in registration order and the first match
+// wins. Like database/sql.Register, it panics on an invalid or duplicate
+// format, since that is a programming error.
+func RegisterFormat(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if format.Name == "" || format.Marker == "" || format.Decode == nil {
+		panic("export: RegisterFormat needs a name, marker and decoder")
+	}
+	if format.Name == DefaultFormatName {
+		panic("export: RegisterFormat called for the default format " + DefaultFormatName)
+	}
+	for _, registered := range formats {
+		if registered.Name == format.Name {
+			panic("export: RegisterFormat called twice for " + format.Name)
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Formats returns the registered layouts, default format first
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	return append([]Format{defaultFormat}, formats...)
+}
+
+// detectFormat parses the file's top level and returns the format whose
+// marker it carries, or the default format
+func detectFormat(data []byte) (Format, map[string]json.RawMessage, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := offsetPosition(data, syntaxErr.Offset)
+			return Format{}, nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Format{}, nil, fmt.Errorf("expected a JSON object, got %s", typeErr.Value)
+		}
+		return Format{}, nil, err
+	}
+	if sections == nil {
+		return Format{}, nil, fmt.Errorf("expected a JSON object, got null")
+	}
+
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for _, format := range formats {
+		if format.matches(sections) {
+			return format, sections, nil
+		}
+	}
+	return defaultFormat, sections, nil
+}
+
+func (f Format) matches(sections map[string]json.RawMessage) bool {
+	raw, ok := sections[f.Marker]
+	if !ok {
+		return false
+	}
+	if f.MarkerValue == "" {
+		return true
+	}
+	var value string
+	return json.Unmarshal(raw, &value) == nil && value == f.MarkerValue
+}
+
+// decode runs the format's decoder and rejects exports ParseAllJSON can't
+// store
+func (f Format) decode(data []byte) (*PropertyExport, error) {
+	export, err := f.Decode(data)
+	if err == nil && (export == nil || export.PropertyInfo.PropertyID == "") {
+		err = fmt.Errorf("missing property_info.property_id")
+	}
+	if err != nil {
+		if f.Name != DefaultFormatName {
+			return nil, fmt.Errorf("%s format: %w", f.Name, err)
+		}
+		if markers := formatMarkers(); len(markers) > 0 {
+			return nil, fmt.Errorf("%w (no registered format's marker matched: %s)", err, strings.Join(markers, ", "))
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+// formatMarkers lists the registered markers for error messages, e.g.
+// "collector_schema=v3"
+func formatMarkers() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	var markers []string
+	for _, format := range formats {
+		marker := format.Marker
+		if format.MarkerValue != "" {
+			marker += "=" + format.MarkerValue
+		}
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
+// decodeExport parses one export file in whichever registered format it uses
+func decodeExport(data []byte) (*PropertyExport, error) {
+	format, _, err := detectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	return format.decode(data)
+}
+
+// decodePropertyExport decodes the standard PropertyExport layout
+func decodePropertyExport(data []byte) (*PropertyExport, error) {
+	var export PropertyExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ga4admin/internal/query"
+)
+
+// LookerStudioField is one field in a community connector getSchema() response
+type LookerStudioField struct {
+	Name                   string                `json:"name"`
+	Label                  string                `json:"label"`
+	DataType               string                `json:"dataType"` // STRING, NUMBER or BOOLEAN
+	Semantics              LookerStudioSemantics `json:"semantics"`
+	DefaultAggregationType string                `json:"defaultAggregationType,omitempty"`
+}
+
+// LookerStudioSemantics describes how Looker Studio treats a field
+type LookerStudioSemantics struct {
+	ConceptType      string `json:"conceptType"` // DIMENSION or METRIC
+	SemanticType     string `json:"semanticType,omitempty"`
+	IsReaggregatable bool   `json:"isReaggregatable"`
+}
+
+// LookerStudioSchema is the getSchema() response body
+type LookerStudioSchema struct {
+	Schema []LookerStudioField `json:"schema"`
+}
+
+// LookerStudioRow is one row in a getData() response
+type LookerStudioRow struct {
+	Values []interface{} `json:"values"`
+}
+
+// LookerStudioData is the getData() response body
+type LookerStudioData struct {
+	Schema []LookerStudioField `json:"schema"`
+	Rows   []LookerStudioRow   `json:"rows"`
+}
+
+// LookerStudioFiles lists the files written by WriteLookerStudioExport
+type LookerStudioFiles struct {
+	Schema string
+	Data   string
+	CSV    string
+}
+
+var nonFieldIDChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Dimension semantic types Looker Studio understands natively. GA4 date
+// values (YYYYMMDD etc.) already match the Looker Studio formats.
+var dimensionSemanticTypes = map[string]string{
+	"date":           "YEAR_MONTH_DAY",
+	"dateHour":       "YEAR_MONTH_DAY_HOUR",
+	"yearMonth":      "YEAR_MONTH",
+	"yearWeek":       "YEAR_WEEK",
+	"isoYearIsoWeek": "YEAR_WEEK",
+	"year":           "YEAR",
+	"month":          "MONTH",
+	"week":           "WEEK",
+	"hour":           "HOUR",
+	"day":            "DAY",
+	"country":        "COUNTRY",
+	"countryId":      "COUNTRY_CODE",
+	"region":         "REGION",
+	"city":           "CITY",
+	"continent":      "CONTINENT",
+	"pageLocation":   "URL",
+	"fullPageUrl":    "URL",
+	"pageReferrer":   "URL",
+}
+
+// Currencies Looker Studio has a CURRENCY_<code> semantic type for
+var lookerStudioCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "AUD": true, "CAD": true,
+	"CHF": true, "CNY": true, "INR": true, "BRL": true, "MXN": true, "SEK": true,
+	"NOK": true, "DKK": true, "PLN": true, "SGD": true, "HKD": true, "NZD": true,
+	"KRW": true, "ZAR": true, "TRY": true, "RUB": true, "IDR": true, "THB": true,
+}
+
+// BuildLookerStudioSchema maps query result headers to connector fields
+func BuildLookerStudioSchema(result *query.QueryResult) []LookerStudioField {
+	currency := ""
+	if result.ResponseMetadata != nil {
+		currency = result.ResponseMetadata.CurrencyCode
+	}
+
+	// Calculated metrics are usually ratios, so never sum them
+	calculated := make(map[string]bool)
+	if result.QueryConfig != nil {
+		for _, metric := range result.QueryConfig.CalculatedMetrics {
+			calculated[metric.Name] = true
+		}
+	}
+
+	fields := make([]LookerStudioField, 0, len(result.DimensionHeaders)+len(result.MetricHeaders))
+	for _, header := range result.DimensionHeaders {
+		semanticType := dimensionSemanticTypes[header.Name]
+		if semanticType == "" {
+			semanticType = "TEXT"
+		}
+		fields = append(fields, LookerStudioField{
+			Name:     lookerStudioFieldID(header.Name),
+			Label:    header.Name,
+			DataType: "STRING",
+			Semantics: LookerStudioSemantics{
+				ConceptType:  "DIMENSION",
+				SemanticType: semanticType,
+			},
+		})
+	}
+
+	for _, header := range result.MetricHeaders {
+		field := LookerStudioField{
+			Name:     lookerStudioFieldID(header.Name),
+			Label:    header.Name,
+			DataType: "NUMBER",
+			Semantics: LookerStudioSemantics{
+				ConceptType:      "METRIC",
+				SemanticType:     metricSemanticType(header.Name, header.Type, currency),
+				IsReaggregatable: !calculated[header.Name] && query.IsReaggregatable(header.Name),
+			},
+		}
+		if field.Semantics.IsReaggregatable {
+			field.DefaultAggregationType = "SUM"
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// BuildLookerStudioData converts result rows into getData() rows, typing metric values as numbers
+func BuildLookerStudioData(result *query.QueryResult) *LookerStudioData {
+	data := &LookerStudioData{
+		Schema: BuildLookerStudioSchema(result),
+		Rows:   make([]LookerStudioRow, 0, len(result.Rows)),
+	}
+
+	for _, row := range result.Rows {
+		values := make([]interface{}, 0, len(row.DimensionValues)+len(row.MetricValues))
+		for _, value := range row.DimensionValues {
+			values = append(values, value.Value)
+		}
+		for _, value := range row.MetricValues {
+			if number, err := strconv.ParseFloat(value.Value, 64); err == nil {
+				values = append(values, number)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		data.Rows = append(data.Rows, LookerStudioRow{Values: values})
+	}
+
+	return data
+}
+
+// WriteLookerStudioExport writes schema.json (getSchema response), data.json
+// (getData response) and data.csv (for the file upload connector) to outputDir
+func WriteLookerStudioExport(result *query.QueryResult, outputDir string) (*LookerStudioFiles, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	files := &LookerStudioFiles{
+		Schema: filepath.Join(outputDir, "schema.json"),
+		Data:   filepath.Join(outputDir, "data.json"),
+		CSV:    filepath.Join(outputDir, "data.csv"),
+	}
+
+	data := BuildLookerStudioData(result)
+	if err := writeJSONFile(files.Schema, LookerStudioSchema{Schema: data.Schema}); err != nil {
+		return nil, err
+	}
+	if err := writeJSONFile(files.Data, data); err != nil {
+		return nil, err
+	}
+	if err := writeLookerStudioCSV(files.CSV, data); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeLookerStudioCSV uses field IDs as headers so uploaded CSVs and the
+// connector schema agree on names
+func writeLookerStudioCSV(path string, data *LookerStudioData) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	headers := make([]string, len(data.Schema))
+	for i, field := range data.Schema {
+		headers[i] = field.Name
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+
+	for _, row := range data.Rows {
+		record := make([]string, len(row.Values))
+		for i, value := range row.Values {
+			switch v := value.(type) {
+			case nil:
+				record[i] = ""
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// lookerStudioFieldID makes a GA4 name such as "customEvent:plan" a valid field ID
+func lookerStudioFieldID(name string) string {
+	return strings.Trim(nonFieldIDChars.ReplaceAllString(name, "_"), "_")
+}
+
+func metricSemanticType(name, metricType, currency string) string {
+	switch metricType {
+	case "TYPE_CURRENCY":
+		if lookerStudioCurrencies[currency] {
+			return "CURRENCY_" + currency
+		}
+		return "NUMBER"
+	case "TYPE_SECONDS":
+		return "DURATION"
+	}
+
+	// GA4 reports rates as fractions, which is what PERCENT expects
+	if strings.HasSuffix(name, "Rate") {
+		return "PERCENT"
+	}
+	return "NUMBER"
+}
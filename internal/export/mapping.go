@@ -0,0 +1,202 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ga4admin/internal/api"
+)
+
+// FieldMapping maps one GA4 custom dimension on one property to the field
+// name it should be imported under in Clarisights
+type FieldMapping struct {
+	AccountID        string `json:"account_id"`
+	PropertyID       string `json:"property_id"`
+	PropertyName     string `json:"property_name"`
+	APIName          string `json:"api_name"`
+	UIName           string `json:"ui_name"`
+	Scope            string `json:"scope"`
+	Category         string `json:"category"`
+	ClarisightsField string `json:"clarisights_field"`
+	ClarisightsGroup string `json:"clarisights_group"`
+	PropertyCount    int    `json:"property_count"` // Properties in the account defining the same API name
+}
+
+// MappingHeaders are the CSV columns written by WriteMappingCSV
+var MappingHeaders = []string{
+	"account_id", "property_id", "property_name", "api_name", "ui_name",
+	"scope", "category", "clarisights_field", "clarisights_group", "property_count",
+}
+
+var nonFieldChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Name fragments that suggest which Clarisights field group a dimension belongs to.
+// Checked in order; the first match wins.
+var groupHeuristics = []struct {
+	group     string
+	fragments []string
+}{
+	{"Attribution", []string{"campaign", "utm", "source", "medium", "channel", "gclid", "referr"}},
+	{"Product", []string{"product", "item", "sku", "brand", "variant", "price"}},
+	{"Audience", []string{"user", "customer", "member", "login", "segment", "tier", "cohort"}},
+	{"Content", []string{"page", "content", "article", "author", "section", "genre", "artist", "album"}},
+}
+
+// BuildDimensionMapping collects custom dimensions for every property in an
+// account and proposes Clarisights field names. Properties whose metadata
+// cannot be fetched are reported through warn and skipped.
+func BuildDimensionMapping(ctx context.Context, adminClient api.AdminService, dataClient api.DataService, accountID string, warn func(propertyID string, err error)) ([]FieldMapping, error) {
+	properties, err := adminClient.ListProperties(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list properties: %w", err)
+	}
+
+	var mappings []FieldMapping
+	for _, property := range properties {
+		metadata, err := dataClient.GetMetadata(ctx, property.ID)
+		if err != nil {
+			if warn != nil {
+				warn(property.ID, err)
+			}
+			continue
+		}
+
+		for _, dim := range metadata.Dimensions {
+			if !dim.CustomDefinition {
+				continue
+			}
+			scope := DimensionScope(dim.APIName)
+			mappings = append(mappings, FieldMapping{
+				AccountID:        accountID,
+				PropertyID:       property.ID,
+				PropertyName:     property.DisplayName,
+				APIName:          dim.APIName,
+				UIName:           dim.UIName,
+				Scope:            scope,
+				Category:         dim.Category,
+				ClarisightsField: ClarisightsFieldName(dim.APIName),
+				ClarisightsGroup: ClarisightsGroup(dim.APIName, dim.UIName),
+			})
+		}
+	}
+
+	// Dimensions shared across properties import as one Clarisights field
+	counts := make(map[string]int)
+	for _, mapping := range mappings {
+		counts[mapping.APIName]++
+	}
+	for i := range mappings {
+		mappings[i].PropertyCount = counts[mappings[i].APIName]
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].ClarisightsField != mappings[j].ClarisightsField {
+			return mappings[i].ClarisightsField < mappings[j].ClarisightsField
+		}
+		return mappings[i].PropertyID < mappings[j].PropertyID
+	})
+
+	return mappings, nil
+}
+
+// DimensionScope derives the custom dimension scope from its API name prefix
+func DimensionScope(apiName string) string {
+	switch {
+	case strings.HasPrefix(apiName, "customEvent:"):
+		return "event"
+	case strings.HasPrefix(apiName, "customUser:"):
+		return "user"
+	case strings.HasPrefix(apiName, "customItem:"):
+		return "item"
+	case strings.Contains(apiName, "ChannelGroup"):
+		return "session"
+	default:
+		return "unknown"
+	}
+}
+
+// ClarisightsFieldName turns a GA4 API name such as "customEvent:article_author"
+// into a snake_case Clarisights field name such as "ga4_event_article_author"
+func ClarisightsFieldName(apiName string) string {
+	name := apiName
+	if _, suffix, found := strings.Cut(apiName, ":"); found {
+		name = suffix
+	}
+	name = strings.Trim(nonFieldChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	return fmt.Sprintf("ga4_%s_%s", DimensionScope(apiName), name)
+}
+
+// ClarisightsGroup guesses the Clarisights field group from the dimension's names
+func ClarisightsGroup(apiName, uiName string) string {
+	haystack := strings.ToLower(apiName + " " + uiName)
+	for _, heuristic := range groupHeuristics {
+		for _, fragment := range heuristic.fragments {
+			if strings.Contains(haystack, fragment) {
+				return heuristic.group
+			}
+		}
+	}
+	if DimensionScope(apiName) == "item" {
+		return "Product"
+	}
+	return "Custom"
+}
+
+// WriteMappingCSV writes mappings in the column order Clarisights bulk import expects
+func WriteMappingCSV(mappings []FieldMapping, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(MappingHeaders); err != nil {
+		return fmt.Errorf("failed to write CSV headers: %w", err)
+	}
+	for _, m := range mappings {
+		record := []string{
+			m.AccountID, m.PropertyID, m.PropertyName, m.APIName, m.UIName,
+			m.Scope, m.Category, m.ClarisightsField, m.ClarisightsGroup, fmt.Sprintf("%d", m.PropertyCount),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMappingJSON writes mappings as an indented JSON array
+func WriteMappingJSON(mappings []FieldMapping, outputPath string) error {
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(mappings); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func createOutputFile(outputPath string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
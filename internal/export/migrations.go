@@ -0,0 +1,178 @@
+package export
+
+import "ga4admin/internal/migrate"
+
+// parserMigrations are the schema changes of databases written by
+// ParseAllJSON, oldest first. Append new ones; never edit a released one.
+// The analysis views aren't migrated: they are recreated after migrating.
+var parserMigrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "initial export tables",
+		Statements: []string{
+			// Properties table
+			`CREATE TABLE IF NOT EXISTS properties (
+				property_id VARCHAR PRIMARY KEY,
+				property_name VARCHAR NOT NULL,
+				account_id VARCHAR NOT NULL,
+				account_name VARCHAR NOT NULL,
+				currency VARCHAR,
+				timezone VARCHAR,
+				industry VARCHAR,
+				service_level VARCHAR,
+				created_date TIMESTAMP,
+				last_accessed TIMESTAMP,
+				collection_timestamp TIMESTAMP,
+				total_dimensions INTEGER,
+				custom_dimensions_count INTEGER,
+				collector_version VARCHAR,
+				preset_used VARCHAR,
+				collection_duration VARCHAR,
+				api_call_count INTEGER
+			)`,
+
+			// Custom dimensions table - DuckDB auto-increment sequence
+			`CREATE SEQUENCE IF NOT EXISTS custom_dimensions_id_seq START 1`,
+			`CREATE TABLE IF NOT EXISTS custom_dimensions (
+				id INTEGER PRIMARY KEY DEFAULT nextval('custom_dimensions_id_seq'),
+				property_id VARCHAR NOT NULL,
+				api_name VARCHAR NOT NULL,
+				ui_name VARCHAR,
+				description TEXT,
+				scope VARCHAR NOT NULL,
+				category VARCHAR,
+				custom_definition BOOLEAN
+			)`,
+
+			// Clarisights integration tracking
+			`CREATE TABLE IF NOT EXISTS clarisights_integration (
+				property_id VARCHAR PRIMARY KEY,
+				has_custom_channel_groups BOOLEAN,
+				channel_group_id VARCHAR,
+				channel_group_name VARCHAR
+			)`,
+
+			// Web and app data streams
+			`CREATE TABLE IF NOT EXISTS data_streams (
+				property_id VARCHAR NOT NULL,
+				stream_id VARCHAR NOT NULL,
+				stream_type VARCHAR NOT NULL,
+				platform VARCHAR NOT NULL,
+				display_name VARCHAR,
+				measurement_id VARCHAR,
+				default_uri VARCHAR,
+				package_name VARCHAR,
+				bundle_id VARCHAR,
+				firebase_app_id VARCHAR,
+				created_date TIMESTAMP,
+				PRIMARY KEY (property_id, stream_id)
+			)`,
+		},
+	},
+	{
+		// Every parse becomes a collection run, and parsed rows are keyed by
+		// run so repeated collections coexist instead of duplicating
+		// custom_dimensions. DuckDB can't change primary keys in place, so
+		// the tables are rebuilt. Rows parsed before runs were tracked move
+		// into one run, keeping the newest copy of duplicated dimensions.
+		Version:     2,
+		Description: "collection runs",
+		Statements: []string{
+			`CREATE SEQUENCE IF NOT EXISTS collection_run_id_seq START 1`,
+			`CREATE TABLE collection_run (
+				run_id INTEGER PRIMARY KEY DEFAULT nextval('collection_run_id_seq'),
+				started_at TIMESTAMP NOT NULL,
+				finished_at TIMESTAMP,          -- NULL if the parse didn't complete
+				label VARCHAR,
+				input_dir VARCHAR,
+				files_parsed INTEGER,
+				files_failed INTEGER
+			)`,
+			`INSERT INTO collection_run (started_at, finished_at, label, files_parsed)
+			SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'before run tracking', COUNT(*)
+			FROM properties
+			HAVING COUNT(*) > 0`,
+
+			`CREATE TABLE properties_runs (
+				run_id INTEGER NOT NULL REFERENCES collection_run(run_id),
+				property_id VARCHAR NOT NULL,
+				property_name VARCHAR NOT NULL,
+				account_id VARCHAR NOT NULL,
+				account_name VARCHAR NOT NULL,
+				currency VARCHAR,
+				timezone VARCHAR,
+				industry VARCHAR,
+				service_level VARCHAR,
+				created_date TIMESTAMP,
+				last_accessed TIMESTAMP,
+				collection_timestamp TIMESTAMP,
+				total_dimensions INTEGER,
+				custom_dimensions_count INTEGER,
+				collector_version VARCHAR,
+				preset_used VARCHAR,
+				collection_duration VARCHAR,
+				api_call_count INTEGER,
+				PRIMARY KEY (run_id, property_id)
+			)`,
+			`INSERT INTO properties_runs
+			SELECT (SELECT MAX(run_id) FROM collection_run), *
+			FROM properties`,
+			`DROP TABLE properties`,
+			`ALTER TABLE properties_runs RENAME TO properties`,
+
+			`CREATE TABLE custom_dimensions_runs (
+				id INTEGER PRIMARY KEY DEFAULT nextval('custom_dimensions_id_seq'),
+				run_id INTEGER NOT NULL REFERENCES collection_run(run_id),
+				property_id VARCHAR NOT NULL,
+				api_name VARCHAR NOT NULL,
+				ui_name VARCHAR,
+				description TEXT,
+				scope VARCHAR NOT NULL,
+				category VARCHAR,
+				custom_definition BOOLEAN
+			)`,
+			`INSERT INTO custom_dimensions_runs
+			SELECT id, (SELECT MAX(run_id) FROM collection_run), property_id, api_name,
+				ui_name, description, scope, category, custom_definition
+			FROM custom_dimensions
+			QUALIFY row_number() OVER (PARTITION BY property_id, api_name, scope ORDER BY id DESC) = 1`,
+			`DROP TABLE custom_dimensions`,
+			`ALTER TABLE custom_dimensions_runs RENAME TO custom_dimensions`,
+
+			`CREATE TABLE clarisights_integration_runs (
+				run_id INTEGER NOT NULL REFERENCES collection_run(run_id),
+				property_id VARCHAR NOT NULL,
+				has_custom_channel_groups BOOLEAN,
+				channel_group_id VARCHAR,
+				channel_group_name VARCHAR,
+				PRIMARY KEY (run_id, property_id)
+			)`,
+			`INSERT INTO clarisights_integration_runs
+			SELECT (SELECT MAX(run_id) FROM collection_run), *
+			FROM clarisights_integration`,
+			`DROP TABLE clarisights_integration`,
+			`ALTER TABLE clarisights_integration_runs RENAME TO clarisights_integration`,
+
+			`CREATE TABLE data_streams_runs (
+				run_id INTEGER NOT NULL REFERENCES collection_run(run_id),
+				property_id VARCHAR NOT NULL,
+				stream_id VARCHAR NOT NULL,
+				stream_type VARCHAR NOT NULL,
+				platform VARCHAR NOT NULL,
+				display_name VARCHAR,
+				measurement_id VARCHAR,
+				default_uri VARCHAR,
+				package_name VARCHAR,
+				bundle_id VARCHAR,
+				firebase_app_id VARCHAR,
+				created_date TIMESTAMP,
+				PRIMARY KEY (run_id, property_id, stream_id)
+			)`,
+			`INSERT INTO data_streams_runs
+			SELECT (SELECT MAX(run_id) FROM collection_run), *
+			FROM data_streams`,
+			`DROP TABLE data_streams`,
+			`ALTER TABLE data_streams_runs RENAME TO data_streams`,
+		},
+	},
+}
@@ -10,6 +10,7 @@ type PropertyExport struct {
 	CollectionMetadata     CollectionMetadata                 `json:"collection_metadata"`
 	CustomDimensions       map[string][]CustomDimensionInfo   `json:"custom_dimensions"`
 	ClarisightsIntegration ClarisightsIntegration             `json:"clarisights_integration"`
+	DataStreams            []DataStreamInfo                   `json:"data_streams,omitempty"` // Missing in exports from older collectors
 }
 
 // PropertyInfo contains basic property information
@@ -47,6 +48,20 @@ type CustomDimensionInfo struct {
 	CustomDefinition bool   `json:"custom_definition"`
 }
 
+// DataStreamInfo is one web or app data stream of a property. Only the
+// fields of the stream's platform are set.
+type DataStreamInfo struct {
+	StreamID      string     `json:"stream_id"`
+	StreamType    string     `json:"stream_type"` // WEB_DATA_STREAM, ANDROID_APP_DATA_STREAM or IOS_APP_DATA_STREAM
+	DisplayName   string     `json:"display_name"`
+	MeasurementID string     `json:"measurement_id,omitempty"`  // Web: G-XXXXXXXXXX
+	DefaultURI    string     `json:"default_uri,omitempty"`     // Web
+	PackageName   string     `json:"package_name,omitempty"`    // Android
+	BundleID      string     `json:"bundle_id,omitempty"`       // iOS
+	FirebaseAppID string     `json:"firebase_app_id,omitempty"` // Apps, and web streams linked to Firebase
+	CreatedDate   *time.Time `json:"created_date,omitempty"`
+}
+
 // ClarisightsIntegration tracks Clarisights-specific integration status
 type ClarisightsIntegration struct {
 	HasCustomChannelGroups bool   `json:"has_custom_channel_groups"`
@@ -3,7 +3,6 @@ package export
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,7 +10,8 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/marcboeker/go-duckdb"
+	"ga4admin/internal/duckdb"
+	"ga4admin/internal/migrate"
 )
 
 // JSONParser handles streaming JSON files into DuckDB tables
@@ -19,6 +19,8 @@ type JSONParser struct {
 	dbPath    string
 	inputDir  string
 	batchSize int
+	label     string
+	runID     int64 // Collection run being parsed
 }
 
 // NewJSONParser creates a new parser instance
@@ -37,7 +39,13 @@ func (p *JSONParser) SetBatchSize(size int) {
 	}
 }
 
-// ParseAllJSON streams all JSON files into DuckDB tables
+// SetLabel names the collection run, e.g. "2026-10 audit"
+func (p *JSONParser) SetLabel(label string) {
+	p.label = label
+}
+
+// ParseAllJSON streams all JSON files into DuckDB tables as a new collection
+// run. Earlier runs are kept so collections can be compared over time.
 func (p *JSONParser) ParseAllJSON(ctx context.Context) error {
 	// Initialize database and schema
 	if err := p.initializeDatabase(ctx); err != nil {
@@ -50,9 +58,14 @@ func (p *JSONParser) ParseAllJSON(ctx context.Context) error {
 		return fmt.Errorf("failed to get JSON files: %w", err)
 	}
 
-	fmt.Printf("Found %d JSON files to process\n", len(jsonFiles))
+	if err := p.startRun(ctx); err != nil {
+		return fmt.Errorf("failed to start collection run: %w", err)
+	}
+
+	fmt.Printf("Found %d JSON files to process (collection run %d)\n", len(jsonFiles), p.runID)
 
 	// Process files in batches for memory efficiency
+	failed := 0
 	for i := 0; i < len(jsonFiles); i += p.batchSize {
 		end := i + p.batchSize
 		if end > len(jsonFiles) {
@@ -60,81 +73,78 @@ func (p *JSONParser) ParseAllJSON(ctx context.Context) error {
 		}
 
 		batch := jsonFiles[i:end]
-		if err := p.processBatch(ctx, batch, i+1); err != nil {
+		batchFailed, err := p.processBatch(ctx, batch, i+1)
+		if err != nil {
 			return fmt.Errorf("failed to process batch %d-%d: %w", i+1, end, err)
 		}
+		failed += batchFailed
 
 		fmt.Printf("Processed files %d-%d of %d\n", i+1, end, len(jsonFiles))
 	}
 
-	// Create analysis views
-	if err := p.createAnalysisViews(ctx); err != nil {
-		return fmt.Errorf("failed to create analysis views: %w", err)
+	if err := p.finishRun(ctx, len(jsonFiles)-failed, failed); err != nil {
+		return fmt.Errorf("failed to finish collection run: %w", err)
 	}
 
-	fmt.Println("✅ JSON parsing completed successfully")
+	fmt.Printf("✅ JSON parsing completed successfully (collection run %d)\n", p.runID)
 	return nil
 }
 
-// initializeDatabase creates the database schema
+// initializeDatabase creates or migrates the database schema and recreates
+// the analysis views, which DuckDB can't use after their tables are rebuilt
 func (p *JSONParser) initializeDatabase(ctx context.Context) error {
-	db, err := sql.Open("duckdb", p.dbPath)
+	db, err := duckdb.Open(p.dbPath)
 	if err != nil {
 		return err
 	}
 	defer db.Close()
 
-	schemas := []string{
-		// Properties table
-		`CREATE TABLE IF NOT EXISTS properties (
-			property_id VARCHAR PRIMARY KEY,
-			property_name VARCHAR NOT NULL,
-			account_id VARCHAR NOT NULL,
-			account_name VARCHAR NOT NULL,
-			currency VARCHAR,
-			timezone VARCHAR,
-			industry VARCHAR,
-			service_level VARCHAR,
-			created_date TIMESTAMP,
-			last_accessed TIMESTAMP,
-			collection_timestamp TIMESTAMP,
-			total_dimensions INTEGER,
-			custom_dimensions_count INTEGER,
-			collector_version VARCHAR,
-			preset_used VARCHAR,
-			collection_duration VARCHAR,
-			api_call_count INTEGER
-		)`,
+	if _, err := migrate.Apply(ctx, db, parserMigrations); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	if err := createAnalysisViews(ctx, db); err != nil {
+		return fmt.Errorf("failed to create analysis views: %w", err)
+	}
+	return nil
+}
 
-		// Custom dimensions table - DuckDB auto-increment sequence
-		`CREATE SEQUENCE IF NOT EXISTS custom_dimensions_id_seq START 1`,
-		`CREATE TABLE IF NOT EXISTS custom_dimensions (
-			id INTEGER PRIMARY KEY DEFAULT nextval('custom_dimensions_id_seq'),
-			property_id VARCHAR NOT NULL,
-			api_name VARCHAR NOT NULL,
-			ui_name VARCHAR,
-			description TEXT,
-			scope VARCHAR NOT NULL,
-			category VARCHAR,
-			custom_definition BOOLEAN
-		)`,
+// startRun records the collection run the parsed files belong to
+func (p *JSONParser) startRun(ctx context.Context) error {
+	db, err := duckdb.Open(p.dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
 
-		// Clarisights integration tracking
-		`CREATE TABLE IF NOT EXISTS clarisights_integration (
-			property_id VARCHAR PRIMARY KEY,
-			has_custom_channel_groups BOOLEAN,
-			channel_group_id VARCHAR,
-			channel_group_name VARCHAR
-		)`,
+	var label, inputDir interface{}
+	if p.label != "" {
+		label = p.label
+	}
+	if p.inputDir != "" {
+		inputDir = p.inputDir
 	}
+	return db.QueryRowContext(ctx, `
+		INSERT INTO collection_run (started_at, label, input_dir)
+		VALUES (?, ?, ?)
+		RETURNING run_id
+	`, time.Now().UTC(), label, inputDir).Scan(&p.runID)
+}
 
-	for _, schema := range schemas {
-		if _, err := db.ExecContext(ctx, schema); err != nil {
-			return fmt.Errorf("failed to create schema: %w", err)
-		}
+// finishRun marks the collection run complete. Runs without finished_at were
+// interrupted and may hold only some of their properties.
+func (p *JSONParser) finishRun(ctx context.Context, parsed, failed int) error {
+	db, err := duckdb.Open(p.dbPath)
+	if err != nil {
+		return err
 	}
+	defer db.Close()
 
-	return nil
+	_, err = db.ExecContext(ctx, `
+		UPDATE collection_run
+		SET finished_at = ?, files_parsed = ?, files_failed = ?
+		WHERE run_id = ?
+	`, time.Now().UTC(), parsed, failed, p.runID)
+	return err
 }
 
 // getJSONFiles returns all JSON files in the input directory
@@ -156,69 +166,142 @@ func (p *JSONParser) getJSONFiles() ([]string, error) {
 	return files, err
 }
 
-// processBatch processes a batch of JSON files
-func (p *JSONParser) processBatch(ctx context.Context, files []string, startNum int) error {
-	db, err := sql.Open("duckdb", p.dbPath)
+// processBatch processes a batch of JSON files, returning how many failed
+func (p *JSONParser) processBatch(ctx context.Context, files []string, startNum int) (int, error) {
+	db, err := duckdb.Open(p.dbPath)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer db.Close()
 
 	// Begin transaction for batch
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
 	// Prepare statements
 	propStmt, err := tx.PrepareContext(ctx, `
 		INSERT OR REPLACE INTO properties (
-			property_id, property_name, account_id, account_name, currency, timezone,
+			run_id, property_id, property_name, account_id, account_name, currency, timezone,
 			industry, service_level, created_date, last_accessed, collection_timestamp,
 			total_dimensions, custom_dimensions_count, collector_version, preset_used,
 			collection_duration, api_call_count
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer propStmt.Close()
 
 	dimStmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO custom_dimensions (
-			property_id, api_name, ui_name, description, scope, category, custom_definition
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			run_id, property_id, api_name, ui_name, description, scope, category, custom_definition
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer dimStmt.Close()
 
+	// A property exported twice in one run keeps only its last file's
+	// dimensions
+	staleDimsStmt, err := tx.PrepareContext(ctx, `
+		DELETE FROM custom_dimensions WHERE run_id = ? AND property_id = ?
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer staleDimsStmt.Close()
+
 	clarisightsStmt, err := tx.PrepareContext(ctx, `
 		INSERT OR REPLACE INTO clarisights_integration (
-			property_id, has_custom_channel_groups, channel_group_id, channel_group_name
-		) VALUES (?, ?, ?, ?)
+			run_id, property_id, has_custom_channel_groups, channel_group_id, channel_group_name
+		) VALUES (?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer clarisightsStmt.Close()
 
+	streamStmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO data_streams (
+			run_id, property_id, stream_id, stream_type, platform, display_name, measurement_id,
+			default_uri, package_name, bundle_id, firebase_app_id, created_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer streamStmt.Close()
+
+	// Streams no longer in a property's export are removed when it is
+	// exported twice in one run. DuckDB rejects deleting and reinserting a
+	// key in one transaction, so the current streams are passed as a
+	// ",id1,id2," list and only the others deleted.
+	staleStreamsStmt, err := tx.PrepareContext(ctx, `
+		DELETE FROM data_streams
+		WHERE run_id = ? AND property_id = ? AND NOT contains(?, ',' || stream_id || ',')
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer staleStreamsStmt.Close()
+
+	// Exports from collectors that predate data_streams carry over the
+	// property's streams from its previous run
+	carryStreamsStmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO data_streams
+		SELECT ?, property_id, stream_id, stream_type, platform, display_name, measurement_id,
+			default_uri, package_name, bundle_id, firebase_app_id, created_date
+		FROM data_streams
+		WHERE property_id = ? AND run_id = (
+			SELECT MAX(run_id) FROM data_streams WHERE property_id = ? AND run_id < ?
+		)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer carryStreamsStmt.Close()
+
+	stmts := &parseStatements{
+		property:     propStmt,
+		dimension:    dimStmt,
+		staleDims:    staleDimsStmt,
+		clarisights:  clarisightsStmt,
+		stream:       streamStmt,
+		staleStreams: staleStreamsStmt,
+		carryStreams: carryStreamsStmt,
+	}
+
 	// Process each file in the batch
+	failed := 0
 	for _, file := range files {
-		if err := p.processFile(ctx, file, propStmt, dimStmt, clarisightsStmt); err != nil {
+		if err := p.processFile(ctx, file, stmts); err != nil {
 			fmt.Printf("Warning: Failed to process %s: %v\n", filepath.Base(file), err)
+			failed++
 			continue // Continue with other files
 		}
 	}
 
 	// Commit batch
-	return tx.Commit()
+	return failed, tx.Commit()
+}
+
+// parseStatements are the prepared statements of one batch transaction
+type parseStatements struct {
+	property     *sql.Stmt
+	dimension    *sql.Stmt
+	staleDims    *sql.Stmt
+	clarisights  *sql.Stmt
+	stream       *sql.Stmt
+	staleStreams *sql.Stmt
+	carryStreams *sql.Stmt
 }
 
 // processFile processes a single JSON file
-func (p *JSONParser) processFile(ctx context.Context, filePath string, propStmt, dimStmt, clarisightsStmt *sql.Stmt) error {
+func (p *JSONParser) processFile(ctx context.Context, filePath string, stmts *parseStatements) error {
 	// Read JSON file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -226,8 +309,8 @@ func (p *JSONParser) processFile(ctx context.Context, filePath string, propStmt,
 	}
 
 	// Parse JSON
-	var export PropertyExport
-	if err := json.Unmarshal(data, &export); err != nil {
+	export, err := decodeExport(data)
+	if err != nil {
 		return err
 	}
 
@@ -241,8 +324,10 @@ func (p *JSONParser) processFile(ctx context.Context, filePath string, propStmt,
 	}
 	collectionTime := export.CollectionMetadata.Timestamp
 
-	_, err = propStmt.ExecContext(ctx,
-		export.PropertyInfo.PropertyID,
+	propertyID := export.PropertyInfo.PropertyID
+	_, err = stmts.property.ExecContext(ctx,
+		p.runID,
+		propertyID,
 		export.PropertyInfo.PropertyName,
 		export.PropertyInfo.AccountID,
 		export.PropertyInfo.AccountName,
@@ -264,23 +349,22 @@ func (p *JSONParser) processFile(ctx context.Context, filePath string, propStmt,
 		return err
 	}
 
+	if _, err := stmts.staleDims.ExecContext(ctx, p.runID, propertyID); err != nil {
+		return err
+	}
+
 	// Insert custom dimensions (flattened from all scopes)
 	for scope, dimensions := range export.CustomDimensions {
 		for _, dim := range dimensions {
 			// Determine actual scope from API name if different from map key
 			actualScope := scope
-			if strings.HasPrefix(dim.APIName, "customEvent:") {
-				actualScope = "event"
-			} else if strings.HasPrefix(dim.APIName, "customUser:") {
-				actualScope = "user"
-			} else if strings.HasPrefix(dim.APIName, "customItem:") {
-				actualScope = "item"
-			} else if strings.Contains(dim.APIName, "ChannelGroup") {
-				actualScope = "session"
+			if derived := DimensionScope(dim.APIName); derived != "unknown" {
+				actualScope = derived
 			}
 
-			_, err = dimStmt.ExecContext(ctx,
-				export.PropertyInfo.PropertyID,
+			_, err = stmts.dimension.ExecContext(ctx,
+				p.runID,
+				propertyID,
 				dim.APIName,
 				dim.UIName,
 				dim.Description,
@@ -295,25 +379,62 @@ func (p *JSONParser) processFile(ctx context.Context, filePath string, propStmt,
 	}
 
 	// Insert Clarisights integration info
-	_, err = clarisightsStmt.ExecContext(ctx,
-		export.PropertyInfo.PropertyID,
+	_, err = stmts.clarisights.ExecContext(ctx,
+		p.runID,
+		propertyID,
 		export.ClarisightsIntegration.HasCustomChannelGroups,
 		export.ClarisightsIntegration.ChannelGroupID,
 		export.ClarisightsIntegration.ChannelGroupName,
 	)
-
-	return err
-}
-
-// createAnalysisViews creates useful views for data analysis
-func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
-	db, err := sql.Open("duckdb", p.dbPath)
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
+	if export.DataStreams == nil {
+		_, err = stmts.carryStreams.ExecContext(ctx, p.runID, propertyID, propertyID, p.runID)
+		return err
+	}
+	current := ","
+	for _, stream := range export.DataStreams {
+		current += stream.StreamID + ","
+		var createdDate interface{}
+		if stream.CreatedDate != nil {
+			createdDate = *stream.CreatedDate
+		}
+		_, err = stmts.stream.ExecContext(ctx,
+			p.runID,
+			propertyID,
+			stream.StreamID,
+			stream.StreamType,
+			stream.Platform(),
+			stream.DisplayName,
+			stream.MeasurementID,
+			stream.DefaultURI,
+			stream.PackageName,
+			stream.BundleID,
+			stream.FirebaseAppID,
+			createdDate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = stmts.staleStreams.ExecContext(ctx, p.runID, propertyID, current)
+	return err
+}
+
+// createAnalysisViews creates useful views for data analysis. The analysis
+// views describe each property as of its latest collection run; the rest
+// compare runs, either each property's consecutive runs or any two runs.
+func createAnalysisViews(ctx context.Context, db *sql.DB) error {
 	views := []string{
+		// Each property's most recent run
+		`CREATE OR REPLACE VIEW latest_property_runs AS
+		SELECT property_id, MAX(run_id) as run_id
+		FROM properties
+		GROUP BY property_id`,
+
 		// Dimension summary by scope
 		`CREATE OR REPLACE VIEW dimension_summary AS
 		SELECT 
@@ -322,6 +443,7 @@ func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
 			COUNT(DISTINCT property_id) as properties_using,
 			COUNT(DISTINCT category) as unique_categories
 		FROM custom_dimensions 
+		JOIN latest_property_runs USING (run_id, property_id)
 		GROUP BY scope
 		ORDER BY dimension_count DESC`,
 
@@ -337,8 +459,9 @@ func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
 			c.has_custom_channel_groups,
 			c.channel_group_name
 		FROM properties p
-		LEFT JOIN custom_dimensions cd ON p.property_id = cd.property_id
-		LEFT JOIN clarisights_integration c ON p.property_id = c.property_id
+		JOIN latest_property_runs l ON p.run_id = l.run_id AND p.property_id = l.property_id
+		LEFT JOIN custom_dimensions cd ON p.run_id = cd.run_id AND p.property_id = cd.property_id
+		LEFT JOIN clarisights_integration c ON p.run_id = c.run_id AND p.property_id = c.property_id
 		GROUP BY p.property_id, p.property_name, p.account_name, p.service_level, 
 				 p.custom_dimensions_count, c.has_custom_channel_groups, c.channel_group_name
 		ORDER BY p.custom_dimensions_count DESC`,
@@ -353,7 +476,8 @@ func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
 			COUNT(CASE WHEN service_level = 'GOOGLE_ANALYTICS_360' THEN 1 END) as ga360_properties,
 			SUM(CASE WHEN c.has_custom_channel_groups THEN 1 ELSE 0 END) as clarisights_ready_properties
 		FROM properties p
-		LEFT JOIN clarisights_integration c ON p.property_id = c.property_id
+		JOIN latest_property_runs l ON p.run_id = l.run_id AND p.property_id = l.property_id
+		LEFT JOIN clarisights_integration c ON p.run_id = c.run_id AND p.property_id = c.property_id
 		GROUP BY account_name
 		ORDER BY total_custom_dimensions DESC`,
 
@@ -366,9 +490,138 @@ func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
 			COUNT(DISTINCT property_id) as properties_using,
 			ROUND(COUNT(*) * 100.0 / SUM(COUNT(*)) OVER (), 2) as percentage
 		FROM custom_dimensions 
+		JOIN latest_property_runs USING (run_id, property_id)
 		WHERE category IS NOT NULL
 		GROUP BY category, scope
 		ORDER BY usage_count DESC`,
+
+		// Web/app mix per property; unknown when the export had no streams
+		`CREATE OR REPLACE VIEW property_platforms AS
+		SELECT 
+			p.property_id,
+			p.property_name,
+			p.account_name,
+			COUNT(s.stream_id) as stream_count,
+			COUNT(CASE WHEN s.platform = 'web' THEN 1 END) as web_streams,
+			COUNT(CASE WHEN s.platform = 'android' THEN 1 END) as android_streams,
+			COUNT(CASE WHEN s.platform = 'ios' THEN 1 END) as ios_streams,
+			CASE
+				WHEN COUNT(s.stream_id) = 0 THEN 'unknown'
+				WHEN COUNT(CASE WHEN s.platform = 'web' THEN 1 END) = 0 THEN 'app_only'
+				WHEN COUNT(CASE WHEN s.platform = 'web' THEN 1 END) = COUNT(s.stream_id) THEN 'web_only'
+				ELSE 'web_and_app'
+			END as platform_mix
+		FROM properties p
+		JOIN latest_property_runs l ON p.run_id = l.run_id AND p.property_id = l.property_id
+		LEFT JOIN data_streams s ON p.run_id = s.run_id AND p.property_id = s.property_id
+		GROUP BY p.property_id, p.property_name, p.account_name
+		ORDER BY stream_count DESC`,
+
+		// One row per collection run with what it parsed
+		`CREATE OR REPLACE VIEW run_summary AS
+		SELECT 
+			r.run_id,
+			r.label,
+			r.started_at,
+			r.finished_at,
+			r.input_dir,
+			r.files_parsed,
+			r.files_failed,
+			(SELECT COUNT(*) FROM properties p WHERE p.run_id = r.run_id) as properties,
+			(SELECT COUNT(*) FROM custom_dimensions cd WHERE cd.run_id = r.run_id) as custom_dimensions,
+			(SELECT COUNT(*) FROM data_streams s WHERE s.run_id = r.run_id) as data_streams
+		FROM collection_run r
+		ORDER BY r.run_id`,
+
+		// Custom dimensions added or removed since each property's previous run
+		`CREATE OR REPLACE VIEW dimension_changes AS
+		WITH property_runs AS (
+			SELECT 
+				property_id,
+				run_id,
+				LAG(run_id) OVER (PARTITION BY property_id ORDER BY run_id) as previous_run_id
+			FROM properties
+		)
+		SELECT pr.property_id, pr.previous_run_id, pr.run_id, 'added' as change, cd.api_name, cd.scope
+		FROM property_runs pr
+		JOIN custom_dimensions cd ON cd.run_id = pr.run_id AND cd.property_id = pr.property_id
+		WHERE pr.previous_run_id IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM custom_dimensions prev
+			WHERE prev.run_id = pr.previous_run_id AND prev.property_id = pr.property_id
+				AND prev.api_name = cd.api_name AND prev.scope = cd.scope
+		)
+		UNION ALL
+		SELECT pr.property_id, pr.previous_run_id, pr.run_id, 'removed' as change, prev.api_name, prev.scope
+		FROM property_runs pr
+		JOIN custom_dimensions prev ON prev.run_id = pr.previous_run_id AND prev.property_id = pr.property_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM custom_dimensions cd
+			WHERE cd.run_id = pr.run_id AND cd.property_id = pr.property_id
+				AND cd.api_name = prev.api_name AND cd.scope = prev.scope
+		)
+		ORDER BY 3, 1, 4, 5`,
+
+		// Every pair of runs, earlier first, for comparing any two collections
+		`CREATE OR REPLACE VIEW run_pairs AS
+		SELECT a.run_id as from_run_id, b.run_id as to_run_id
+		FROM collection_run a
+		JOIN collection_run b ON a.run_id < b.run_id`,
+
+		// Properties gained or lost between two runs
+		`CREATE OR REPLACE VIEW property_changes AS
+		SELECT rp.from_run_id, rp.to_run_id, 'gained' as change, p.property_id, p.property_name, p.account_name
+		FROM run_pairs rp
+		JOIN properties p ON p.run_id = rp.to_run_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM properties f WHERE f.run_id = rp.from_run_id AND f.property_id = p.property_id
+		)
+		UNION ALL
+		SELECT rp.from_run_id, rp.to_run_id, 'lost' as change, p.property_id, p.property_name, p.account_name
+		FROM run_pairs rp
+		JOIN properties p ON p.run_id = rp.from_run_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM properties t WHERE t.run_id = rp.to_run_id AND t.property_id = p.property_id
+		)`,
+
+		// Custom dimension counts of properties present in both runs
+		`CREATE OR REPLACE VIEW dimension_count_deltas AS
+		WITH counts AS (
+			SELECT run_id, property_id, COUNT(*) as dimension_count
+			FROM custom_dimensions
+			GROUP BY run_id, property_id
+		)
+		SELECT 
+			rp.from_run_id,
+			rp.to_run_id,
+			t.property_id,
+			t.property_name,
+			t.account_name,
+			COALESCE(fc.dimension_count, 0) as from_count,
+			COALESCE(tc.dimension_count, 0) as to_count,
+			COALESCE(tc.dimension_count, 0) - COALESCE(fc.dimension_count, 0) as delta
+		FROM run_pairs rp
+		JOIN properties f ON f.run_id = rp.from_run_id
+		JOIN properties t ON t.run_id = rp.to_run_id AND t.property_id = f.property_id
+		LEFT JOIN counts fc ON fc.run_id = f.run_id AND fc.property_id = f.property_id
+		LEFT JOIN counts tc ON tc.run_id = t.run_id AND tc.property_id = t.property_id`,
+
+		// Properties present in both runs whose Clarisights readiness changed
+		`CREATE OR REPLACE VIEW clarisights_changes AS
+		SELECT 
+			rp.from_run_id,
+			rp.to_run_id,
+			t.property_id,
+			t.property_name,
+			t.account_name,
+			CASE WHEN COALESCE(tc.has_custom_channel_groups, false) THEN 'became_ready' ELSE 'no_longer_ready' END as change,
+			fc.channel_group_name as from_channel_group,
+			tc.channel_group_name as to_channel_group
+		FROM run_pairs rp
+		JOIN properties f ON f.run_id = rp.from_run_id
+		JOIN properties t ON t.run_id = rp.to_run_id AND t.property_id = f.property_id
+		LEFT JOIN clarisights_integration fc ON fc.run_id = f.run_id AND fc.property_id = f.property_id
+		LEFT JOIN clarisights_integration tc ON tc.run_id = t.run_id AND tc.property_id = t.property_id
+		WHERE COALESCE(fc.has_custom_channel_groups, false) <> COALESCE(tc.has_custom_channel_groups, false)`,
 	}
 
 	for _, view := range views {
@@ -378,4 +631,4 @@ func (p *JSONParser) createAnalysisViews(ctx context.Context) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
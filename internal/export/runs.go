@@ -0,0 +1,75 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"ga4admin/internal/duckdb"
+)
+
+// ErrRunNotFound is returned for a run ID the database doesn't have
+var ErrRunNotFound = errors.New("collection run not found")
+
+// CollectionRun is one 'export parse-json' run stored in a parsed database
+type CollectionRun struct {
+	RunID            int64      `json:"run_id"`
+	Label            string     `json:"label,omitempty"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"` // Nil if the parse was interrupted
+	InputDir         string     `json:"input_dir,omitempty"`
+	FilesParsed      int        `json:"files_parsed"`
+	FilesFailed      int        `json:"files_failed"`
+	Properties       int        `json:"properties"`
+	CustomDimensions int        `json:"custom_dimensions"`
+	DataStreams      int        `json:"data_streams"`
+}
+
+// ListRuns returns the collection runs in a parsed database, oldest first.
+// Databases parsed before runs were tracked are migrated first.
+func ListRuns(ctx context.Context, dbPath string) ([]CollectionRun, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	parser := NewJSONParser(dbPath, "")
+	if err := parser.initializeDatabase(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	db, err := duckdb.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT run_id, COALESCE(label, ''), started_at, finished_at, COALESCE(input_dir, ''),
+		       COALESCE(files_parsed, 0), COALESCE(files_failed, 0),
+		       properties, custom_dimensions, data_streams
+		FROM run_summary
+		ORDER BY run_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []CollectionRun
+	for rows.Next() {
+		var run CollectionRun
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&run.RunID, &run.Label, &run.StartedAt, &finishedAt, &run.InputDir,
+			&run.FilesParsed, &run.FilesFailed, &run.Properties, &run.CustomDimensions, &run.DataStreams); err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
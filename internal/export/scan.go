@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// exportSections are the top-level keys of a property export, in file order
+var exportSections = []string{"property_info", "collection_metadata", "custom_dimensions", "clarisights_integration", "data_streams"}
+
+// ParsedTables are the tables ParseAllJSON writes, in creation order
+var ParsedTables = []string{"properties", "custom_dimensions", "clarisights_integration", "data_streams"}
+
+// ScanResult describes what ParseAllJSON would do with the input directory
+type ScanResult struct {
+	Files     int
+	Malformed []MalformedFile
+	Variants  map[string]int // Schema variant or registered format to file count
+	Rows      map[string]int // Table to estimated rows written
+}
+
+// MalformedFile is an export ParseAllJSON would skip
+type MalformedFile struct {
+	Path string
+	Err  error
+}
+
+// Valid returns the number of files that would be parsed
+func (r *ScanResult) Valid() int {
+	return r.Files - len(r.Malformed)
+}
+
+// VariantNames returns the schema variants, most common first
+func (r *ScanResult) VariantNames() []string {
+	names := make([]string, 0, len(r.Variants))
+	for name := range r.Variants {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.Variants[names[i]] != r.Variants[names[j]] {
+			return r.Variants[names[i]] > r.Variants[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Scan reads every JSON file in the input directory without touching the
+// database. Row estimates count what the files would add as one collection
+// run; earlier runs in the database are kept.
+func (p *JSONParser) Scan(ctx context.Context) (*ScanResult, error) {
+	files, err := p.getJSONFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JSON files: %w", err)
+	}
+
+	result := &ScanResult{
+		Files:    len(files),
+		Variants: make(map[string]int),
+		Rows:     make(map[string]int),
+	}
+	properties := make(map[string]bool)
+	streams := make(map[string]bool)
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			result.Malformed = append(result.Malformed, MalformedFile{Path: file, Err: err})
+			continue
+		}
+		format, sections, err := detectFormat(data)
+		if err != nil {
+			result.Malformed = append(result.Malformed, MalformedFile{Path: file, Err: err})
+			continue
+		}
+		export, err := format.decode(data)
+		if err != nil {
+			result.Malformed = append(result.Malformed, MalformedFile{Path: file, Err: err})
+			continue
+		}
+
+		if format.Name == DefaultFormatName {
+			result.Variants[schemaVariant(sections)]++
+		} else {
+			result.Variants[format.Name+" format"]++
+		}
+
+		properties[export.PropertyInfo.PropertyID] = true
+		for _, dimensions := range export.CustomDimensions {
+			result.Rows["custom_dimensions"] += len(dimensions)
+		}
+		for _, stream := range export.DataStreams {
+			streams[export.PropertyInfo.PropertyID+"/"+stream.StreamID] = true
+		}
+	}
+
+	result.Rows["properties"] = len(properties)
+	result.Rows["clarisights_integration"] = len(properties)
+	result.Rows["data_streams"] = len(streams)
+	return result, nil
+}
+
+// schemaVariant describes how a file's top-level keys differ from the full
+// export format, e.g. "without data_streams" for older collectors
+func schemaVariant(sections map[string]json.RawMessage) string {
+	var missing, unknown []string
+	for _, section := range exportSections {
+		if _, ok := sections[section]; !ok {
+			missing = append(missing, section)
+		}
+	}
+	for key := range sections {
+		if !contains(exportSections, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "without "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		parts = append(parts, "unknown keys "+strings.Join(unknown, ", "))
+	}
+	if len(parts) == 0 {
+		return "all sections"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"ga4admin/internal/api"
+)
+
+// Stream platforms stored in the parsed data_streams table
+const (
+	PlatformWeb     = "web"
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// NewDataStreamInfo converts an Admin API data stream into the export format
+// collectors write under data_streams
+func NewDataStreamInfo(stream api.DataStream) DataStreamInfo {
+	info := DataStreamInfo{
+		StreamID:    stream.ID(),
+		StreamType:  stream.Type,
+		DisplayName: stream.DisplayName,
+	}
+	if !stream.CreateTime.IsZero() {
+		created := stream.CreateTime
+		info.CreatedDate = &created
+	}
+	switch {
+	case stream.WebStreamData != nil:
+		info.MeasurementID = stream.WebStreamData.MeasurementID
+		info.DefaultURI = stream.WebStreamData.DefaultURI
+		info.FirebaseAppID = stream.WebStreamData.FirebaseAppID
+	case stream.AndroidAppStreamData != nil:
+		info.PackageName = stream.AndroidAppStreamData.PackageName
+		info.FirebaseAppID = stream.AndroidAppStreamData.FirebaseAppID
+	case stream.IOSAppStreamData != nil:
+		info.BundleID = stream.IOSAppStreamData.BundleID
+		info.FirebaseAppID = stream.IOSAppStreamData.FirebaseAppID
+	}
+	return info
+}
+
+// Platform returns web, android or ios for the stream's type
+func (s DataStreamInfo) Platform() string {
+	switch s.StreamType {
+	case api.DataStreamTypeAndroid:
+		return PlatformAndroid
+	case api.DataStreamTypeIOS:
+		return PlatformIOS
+	case api.DataStreamTypeWeb:
+		return PlatformWeb
+	}
+	// Fall back on the platform-specific fields for hand-written exports
+	switch {
+	case s.BundleID != "":
+		return PlatformIOS
+	case s.PackageName != "":
+		return PlatformAndroid
+	}
+	return PlatformWeb
+}
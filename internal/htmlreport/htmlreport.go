@@ -0,0 +1,202 @@
+// Package htmlreport renders a query result as a standalone HTML page with
+// its metadata, charts and data table, for sharing outside the CLI.
+package htmlreport
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/chart"
+	"ga4admin/internal/query"
+	"ga4admin/internal/results"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var page = template.Must(template.New("report").Parse(reportTemplate))
+
+// Limits that keep reports readable and reasonably small
+const (
+	maxCharts = 3  // Charts for the first metrics
+	maxBars   = 20 // Categories per bar chart
+)
+
+type pageData struct {
+	Title       string
+	Subtitle    string
+	Metadata    []metadataItem
+	Charts      []chartItem
+	Columns     []column
+	Rows        [][]cell
+	Totals      [][]cell
+	Note        string
+	GeneratedAt string
+}
+
+type metadataItem struct {
+	Label string
+	Value string
+}
+
+type chartItem struct {
+	SVG     template.HTML
+	Caption string
+}
+
+type column struct {
+	Name    string
+	Numeric bool
+}
+
+type cell struct {
+	Value   string
+	Numeric bool
+}
+
+// Write renders result to an HTML file at outputPath. title defaults to one
+// naming the property.
+func Write(result *query.QueryResult, outputPath, title string) error {
+	var buf bytes.Buffer
+	if err := Render(result, title, &buf); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+// Render writes result as an HTML page to w
+func Render(result *query.QueryResult, title string, w io.Writer) error {
+	if title == "" {
+		title = fmt.Sprintf("GA4 report for property %s", result.PropertyID)
+	}
+
+	data := pageData{
+		Title:       title,
+		Metadata:    metadata(result),
+		Charts:      charts(result),
+		GeneratedAt: time.Now().Format("2006-01-02 15:04 MST"),
+	}
+	if config := result.QueryConfig; config != nil && config.StartDate != "" {
+		data.Subtitle = fmt.Sprintf("%s to %s", config.StartDate, config.EndDate)
+	}
+
+	for _, header := range result.DimensionHeaders {
+		data.Columns = append(data.Columns, column{Name: header.Name})
+	}
+	for _, header := range result.MetricHeaders {
+		data.Columns = append(data.Columns, column{Name: header.Name, Numeric: true})
+	}
+
+	for _, row := range result.Rows {
+		var cells []cell
+		for _, value := range row.DimensionValues {
+			cells = append(cells, cell{Value: value.Value})
+		}
+		for _, value := range row.MetricValues {
+			cells = append(cells, cell{Value: results.FormatMetricValue(value.Value, true), Numeric: true})
+		}
+		data.Rows = append(data.Rows, cells)
+	}
+
+	for _, total := range result.Totals {
+		cells := make([]cell, len(result.DimensionHeaders))
+		if len(cells) > 0 {
+			cells[0].Value = "Total"
+		}
+		for _, value := range total.MetricValues {
+			cells = append(cells, cell{Value: results.FormatMetricValue(value.Value, true), Numeric: true})
+		}
+		data.Totals = append(data.Totals, cells)
+	}
+
+	if len(result.Rows) == 0 {
+		data.Note = "The query returned no rows."
+	} else if result.RowCount > len(result.Rows) {
+		data.Note = fmt.Sprintf("Showing %d of %d rows matched in GA4.", len(result.Rows), result.RowCount)
+	}
+
+	if err := page.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+func metadata(result *query.QueryResult) []metadataItem {
+	items := []metadataItem{
+		{"Property", result.PropertyID},
+		{"Query ID", result.QueryID},
+		{"Fetched", result.ExecutedAt.Format("2006-01-02 15:04:05")},
+	}
+	if config := result.QueryConfig; config != nil {
+		if config.StartDate != "" {
+			items = append(items, metadataItem{"Date range", config.StartDate + " to " + config.EndDate})
+		}
+		if len(config.Dimensions) > 0 {
+			items = append(items, metadataItem{"Dimensions", strings.Join(config.Dimensions, ", ")})
+		}
+		if len(config.Metrics) > 0 {
+			items = append(items, metadataItem{"Metrics", strings.Join(config.Metrics, ", ")})
+		}
+	}
+	items = append(items, metadataItem{"Rows", strconv.Itoa(len(result.Rows))})
+	if meta := result.ResponseMetadata; meta != nil {
+		if meta.CurrencyCode != "" {
+			items = append(items, metadataItem{"Currency", meta.CurrencyCode})
+		}
+		if meta.TimeZone != "" {
+			items = append(items, metadataItem{"Time zone", meta.TimeZone})
+		}
+	}
+	return items
+}
+
+// charts draws the first metrics against the result's time dimension, or
+// its first dimension. Metrics that can't be drawn are left out.
+func charts(result *query.QueryResult) []chartItem {
+	x := chart.DefaultX(result)
+	if x == "" || len(result.Rows) == 0 {
+		return nil
+	}
+
+	var items []chartItem
+	for i, header := range result.MetricHeaders {
+		if i == maxCharts {
+			break
+		}
+
+		data, err := chart.FromResult(result, x, []string{header.Name})
+		if err != nil {
+			continue
+		}
+		chartType, _ := chart.ResolveType(chart.TypeAuto, data)
+		caption := fmt.Sprintf("%s by %s", header.Name, x)
+		if chartType == chart.TypeBar && len(data.Labels) > maxBars {
+			caption += fmt.Sprintf(" (first %d of %d values)", maxBars, len(data.Labels))
+			data.Truncate(maxBars)
+		}
+
+		var svg bytes.Buffer
+		if err := chart.Render(data, chartType, chart.FormatSVG, &svg); err != nil {
+			continue
+		}
+		// The chart package escapes all text it puts in the SVG
+		items = append(items, chartItem{SVG: template.HTML(svg.String()), Caption: caption})
+	}
+	return items
+}
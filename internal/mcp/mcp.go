@@ -0,0 +1,207 @@
+// Package mcp serves tools over the Model Context Protocol: JSON-RPC 2.0
+// messages, one per line, on stdin and stdout. Only the tools capability is
+// offered; the tools themselves are supplied by the caller.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ProtocolVersion is the newest protocol revision the server speaks
+const ProtocolVersion = "2025-03-26"
+
+// supportedVersions are the revisions a client may ask for
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+}
+
+// maxMessageBytes bounds a single incoming message
+const maxMessageBytes = 4 << 20
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool is a function an assistant can call
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{} // JSON Schema of the arguments
+	// Handler returns a value that is sent as JSON, or an error the
+	// assistant is shown as the tool's failed result
+	Handler func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+}
+
+// Server answers MCP requests with its tools
+type Server struct {
+	name, version string
+	tools         []Tool
+}
+
+// NewServer creates a server that introduces itself with name and version
+func NewServer(name, version string, tools []Tool) *Server {
+	return &Server{name: name, version: version, tools: tools}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// content is a block of a tool's result
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Serve reads requests from r and writes responses to w until r ends or ctx
+// is done. Requests are answered in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	encoder := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := encoder.Encode(errorResponse(json.RawMessage("null"), codeParseError, "invalid JSON: "+err.Error())); err != nil {
+				return err
+			}
+			continue
+		}
+		resp := s.handle(ctx, &req)
+		// Notifications get no response
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handle(ctx context.Context, req *request) *response {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "not a JSON-RPC 2.0 request")
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := ProtocolVersion
+		if supportedVersions[params.ProtocolVersion] {
+			version = params.ProtocolVersion
+		}
+		return result(req.ID, map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+		})
+	case "ping":
+		return result(req.ID, map[string]interface{}{})
+	case "tools/list":
+		tools := make([]map[string]interface{}, len(s.tools))
+		for i, tool := range s.tools {
+			tools[i] = map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"inputSchema": tool.InputSchema,
+			}
+		}
+		return result(req.ID, map[string]interface{}{"tools": tools})
+	case "tools/call":
+		return s.call(ctx, req)
+	}
+
+	if len(req.ID) == 0 {
+		// Notifications such as notifications/initialized need no handling
+		return nil
+	}
+	return errorResponse(req.ID, codeMethodNotFound, fmt.Sprintf("unknown method '%s'", req.Method))
+}
+
+// call runs a tool. Failures of the tool itself are results with isError
+// set, so the assistant sees them; only malformed calls are protocol errors.
+func (s *Server) call(ctx context.Context, req *request) *response {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return errorResponse(req.ID, codeInvalidParams, "invalid tools/call parameters: "+err.Error())
+	}
+
+	var tool *Tool
+	for i := range s.tools {
+		if s.tools[i].Name == params.Name {
+			tool = &s.tools[i]
+		}
+	}
+	if tool == nil {
+		return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf("unknown tool '%s'", params.Name))
+	}
+	if len(params.Arguments) == 0 {
+		params.Arguments = json.RawMessage("{}")
+	}
+
+	value, err := tool.Handler(ctx, params.Arguments)
+	if err != nil {
+		return result(req.ID, map[string]interface{}{
+			"content": []content{{Type: "text", Text: "Error: " + err.Error()}},
+			"isError": true,
+		})
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return result(req.ID, map[string]interface{}{
+			"content": []content{{Type: "text", Text: "Error: failed to encode result: " + err.Error()}},
+			"isError": true,
+		})
+	}
+	return result(req.ID, map[string]interface{}{
+		"content": []content{{Type: "text", Text: string(text)}},
+	})
+}
+
+func result(id json.RawMessage, value interface{}) *response {
+	return &response{JSONRPC: "2.0", ID: id, Result: value}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
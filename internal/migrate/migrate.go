@@ -0,0 +1,131 @@
+// Package migrate versions ga4admin's databases. Each database keeps a
+// schema_version table listing the migrations applied to it, so upgrading
+// ga4admin alters existing tables in place instead of requiring users to
+// delete their cache or parsed exports when table shapes change.
+//
+// Migrations are compiled into the binary and must run on both DuckDB and
+// SQLite, since the preset cache can be stored in either.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNewerSchema is returned when a database was migrated by a newer
+// ga4admin than the one opening it
+var ErrNewerSchema = errors.New("database schema is newer than this ga4admin supports")
+
+// Migration is one schema change. Versions start at 1 and increase by one;
+// a released migration must never be edited, only followed by a new one.
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Latest returns the version a database has once every migration is applied
+func Latest(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion returns the highest migration applied to a database that
+// Apply has run on
+func CurrentVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Apply brings the database up to date, running each pending migration in its
+// own transaction, and returns how many were applied. Databases created before
+// versioning start at 0, so the first migration of each database only uses
+// IF NOT EXISTS statements and adopts their existing tables.
+func Apply(ctx context.Context, db *sql.DB, migrations []Migration) (int, error) {
+	if err := check(migrations); err != nil {
+		return 0, err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description VARCHAR NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	// Databases that predate versioning have an empty table, i.e. version 0
+	current, err := CurrentVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if latest := Latest(migrations); current > latest {
+		return 0, fmt.Errorf("%w: database is at version %d, this build knows up to %d - upgrade ga4admin", ErrNewerSchema, current, latest)
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		ran, err := applyOne(ctx, db, migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		if ran {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// applyOne runs a migration unless another process applied it first. The
+// version row is claimed before the schema changes, so with SQLite the
+// transaction takes the write lock up front and waits for a concurrent
+// ga4admin instead of failing part way through.
+func applyOne(ctx context.Context, db *sql.DB, migration Migration) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO schema_version (version, description)
+		SELECT ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM schema_version WHERE version = ?)
+	`, migration.Version, migration.Description, migration.Version)
+	if err != nil {
+		return false, err
+	}
+	if claimed, err := result.RowsAffected(); err == nil && claimed == 0 {
+		return false, nil
+	}
+
+	for _, statement := range migration.Statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// check catches migration lists that would leave databases in an ambiguous
+// state, e.g. two migrations sharing a version after a bad merge
+func check(migrations []Migration) error {
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return fmt.Errorf("migration %q has version %d, expected %d", migration.Description, migration.Version, i+1)
+		}
+	}
+	return nil
+}
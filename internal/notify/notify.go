@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ga4admin/internal/config"
+)
+
+// MaxAttachmentBytes caps the combined size of attached exports. Mail
+// providers reject larger messages (SendGrid allows 30MB in total, many SMTP
+// servers 25MB), so bigger exports should use link delivery.
+const MaxAttachmentBytes = 20 << 20
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Message is an email sent by a notifier
+type Message struct {
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Notifier delivers messages to a notifier's recipients
+type Notifier interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the notifier described by cfg
+func New(cfg *config.NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case config.NotifierSMTP:
+		return &smtpNotifier{cfg: cfg, password: secret(cfg.SMTPPassword, cfg.SMTPPasswordEnv)}, nil
+	case config.NotifierSendGrid:
+		apiKey := secret(cfg.SendGridAPIKey, cfg.SendGridAPIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("no SendGrid API key: set %s", cfg.SendGridAPIKeyEnv)
+		}
+		return &sendGridNotifier{cfg: cfg, apiKey: apiKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type '%s'", cfg.Type)
+	}
+}
+
+// secret prefers the environment variable when one is named
+func secret(value, envVar string) string {
+	if envVar != "" {
+		if fromEnv := os.Getenv(envVar); fromEnv != "" {
+			return fromEnv
+		}
+	}
+	return value
+}
+
+// ExportMessage builds the message announcing exported files. With
+// attachment delivery the files are attached; with link delivery the body
+// links to them under the notifier's link base URL.
+func ExportMessage(cfg *config.NotifierConfig, subject, summary string, paths []string) (*Message, error) {
+	msg := &Message{Subject: subject}
+
+	var body strings.Builder
+	body.WriteString(summary)
+	body.WriteString("\n\n")
+
+	if cfg.Delivery == config.DeliveryLink {
+		for _, path := range paths {
+			fmt.Fprintf(&body, "- %s\n", exportLink(cfg.LinkBaseURL, path))
+		}
+		msg.Body = body.String()
+		return msg, nil
+	}
+
+	var total int64
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export %s: %w", path, err)
+		}
+		total += int64(len(content))
+		if total > MaxAttachmentBytes {
+			return nil, fmt.Errorf("exports are larger than %dMB; use a notifier with link delivery instead", MaxAttachmentBytes>>20)
+		}
+
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename:    filepath.Base(path),
+			ContentType: contentType(path),
+			Content:     content,
+		})
+		fmt.Fprintf(&body, "- %s (attached)\n", filepath.Base(path))
+	}
+
+	msg.Body = body.String()
+	return msg, nil
+}
+
+// exportLink maps a local export path onto the URL it is served from
+func exportLink(baseURL, path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "../") {
+		path = filepath.Base(path)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "./"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.Join(segments, "/")
+}
+
+func contentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "text/csv"
+	case ".json":
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
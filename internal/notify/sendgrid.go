@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+)
+
+// SendGridEndpoint is the SendGrid v3 mail send API
+const SendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridNotifier sends mail through the SendGrid v3 API
+type sendGridNotifier struct {
+	cfg    *config.NotifierConfig
+	apiKey string
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"` // Base64-encoded
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+func (n *sendGridNotifier) Send(ctx context.Context, msg *Message) error {
+	var personalization sendGridPersonalization
+	for _, recipient := range n.cfg.To {
+		personalization.To = append(personalization.To, sendGridAddress{Email: recipient})
+	}
+
+	request := sendGridRequest{
+		Personalizations: []sendGridPersonalization{personalization},
+		From:             sendGridAddress{Email: n.cfg.From},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	}
+	for _, attachment := range msg.Attachments {
+		request.Attachments = append(request.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Content),
+			Type:        attachment.ContentType,
+			Filename:    attachment.Filename,
+			Disposition: "attachment",
+		})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, SendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+n.apiKey)
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	client, err := api.NewHTTPClient()
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("failed to reach SendGrid: %w", err)
+	}
+	defer response.Body.Close()
+
+	// SendGrid answers 202 Accepted with an empty body on success
+	if response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("SendGrid rejected the message (HTTP %d): %s", response.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package pipeline appends a query's daily rows to a table in a DuckDB
+// database. Each run loads only the days the table doesn't hold yet, so a
+// scheduled 'pipeline run' keeps the table current without an orchestrator.
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/duckdb"
+	"ga4admin/internal/query"
+)
+
+// DateColumn is the dimension pipeline queries must have. It is stored as a
+// DATE, so loaded days can be found with SQL.
+const DateColumn = "date"
+
+// LoadedAtColumn records when each row was appended
+const LoadedAtColumn = "_loaded_at"
+
+// loadsTable logs the date ranges appended to each pipeline table, so days
+// that returned no rows aren't queried again on every run
+const loadsTable = "ga4admin_pipeline_loads"
+
+const dateLayout = "2006-01-02"
+
+// Range is an inclusive range of days
+type Range struct {
+	Start, End time.Time
+}
+
+func (r Range) String() string {
+	return r.Start.Format(dateLayout) + " → " + r.End.Format(dateLayout)
+}
+
+// Target is a pipeline table in an open DuckDB database
+type Target struct {
+	db    *sql.DB
+	table string
+}
+
+// Open opens (or creates) the database holding a pipeline's table
+func Open(ctx context.Context, path, table string) (*Target, error) {
+	db, err := duckdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB database %s: %w", path, err)
+	}
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+loadsTable+` (
+			table_name VARCHAR NOT NULL,
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			row_count BIGINT NOT NULL,
+			loaded_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open DuckDB database %s: %w", path, err)
+	}
+	return &Target{db: db, table: table}, nil
+}
+
+// Close closes the database
+func (t *Target) Close() error {
+	return t.db.Close()
+}
+
+// LoadedDays returns the days, as YYYY-MM-DD, the table holds rows for or
+// that were loaded without any
+func (t *Target) LoadedDays(ctx context.Context) (map[string]bool, error) {
+	loaded := make(map[string]bool)
+
+	rows, err := t.db.QueryContext(ctx, `SELECT start_date, end_date FROM `+loadsTable+` WHERE table_name = ?`, t.table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read load log: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var start, end time.Time
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, fmt.Errorf("failed to read load log: %w", err)
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			loaded[day.Format(dateLayout)] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load log: %w", err)
+	}
+
+	columns, err := t.columns(ctx, t.db)
+	if err != nil || len(columns) == 0 {
+		return loaded, err
+	}
+	// Rows may also have been added outside the pipeline
+	days, err := t.db.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT CAST(%s AS VARCHAR) FROM %s WHERE %s IS NOT NULL`, quoteIdent(DateColumn), t.table, quoteIdent(DateColumn)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loaded days of %s: %w", t.table, err)
+	}
+	defer days.Close()
+	for days.Next() {
+		var day string
+		if err := days.Scan(&day); err != nil {
+			return nil, fmt.Errorf("failed to read loaded days of %s: %w", t.table, err)
+		}
+		loaded[day] = true
+	}
+	return loaded, days.Err()
+}
+
+// MissingRanges returns the runs of days in [from, to] that aren't loaded
+func MissingRanges(loaded map[string]bool, from, to time.Time) []Range {
+	var ranges []Range
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if loaded[day.Format(dateLayout)] {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].End.Equal(day.AddDate(0, 0, -1)) {
+			ranges[n-1].End = day
+			continue
+		}
+		ranges = append(ranges, Range{Start: day, End: day})
+	}
+	return ranges
+}
+
+// Append adds a query result covering r to the table, creating the table
+// from the result's columns on the first load. The rows and the load log
+// entry are written in one transaction.
+func (t *Target) Append(ctx context.Context, result *query.QueryResult, r Range) error {
+	var columns []string
+	dateIndex := -1
+	for i, header := range result.DimensionHeaders {
+		if header.Name == DateColumn {
+			dateIndex = i
+		}
+		columns = append(columns, header.Name)
+	}
+	if dateIndex < 0 {
+		return fmt.Errorf("the query has no '%s' dimension, so loaded days can't be told apart", DateColumn)
+	}
+	for _, header := range result.MetricHeaders {
+		columns = append(columns, header.Name)
+	}
+	columns = append(columns, LoadedAtColumn)
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start load: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := t.columns(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		var definitions []string
+		for _, header := range result.DimensionHeaders {
+			columnType := "VARCHAR"
+			if header.Name == DateColumn {
+				columnType = "DATE"
+			}
+			definitions = append(definitions, quoteIdent(header.Name)+" "+columnType)
+		}
+		for _, header := range result.MetricHeaders {
+			columnType := "DOUBLE"
+			if header.Type == "TYPE_INTEGER" {
+				columnType = "BIGINT"
+			}
+			definitions = append(definitions, quoteIdent(header.Name)+" "+columnType)
+		}
+		definitions = append(definitions, quoteIdent(LoadedAtColumn)+" TIMESTAMP")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", t.table, strings.Join(definitions, ", "))); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", t.table, err)
+		}
+	} else if strings.Join(existing, ",") != strings.Join(columns, ",") {
+		return fmt.Errorf("table %s has columns (%s), but the query returns (%s); load into a new table or drop it first",
+			t.table, strings.Join(existing, ", "), strings.Join(columns, ", "))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", t.table, placeholders))
+	if err != nil {
+		return fmt.Errorf("failed to load rows into %s: %w", t.table, err)
+	}
+	defer insert.Close()
+
+	loadedAt := time.Now().UTC()
+	for _, row := range result.Rows {
+		values := make([]interface{}, 0, len(columns))
+		for j := range result.DimensionHeaders {
+			value := ""
+			if j < len(row.DimensionValues) {
+				value = row.DimensionValues[j].Value
+			}
+			if j != dateIndex {
+				values = append(values, value)
+				continue
+			}
+			// GA4 returns dates as YYYYMMDD
+			day, err := time.Parse("20060102", value)
+			if err != nil {
+				return fmt.Errorf("unexpected date '%s' in the result", value)
+			}
+			values = append(values, day)
+		}
+		for j, header := range result.MetricHeaders {
+			var value interface{}
+			if j < len(row.MetricValues) {
+				if header.Type == "TYPE_INTEGER" {
+					if number, err := strconv.ParseInt(row.MetricValues[j].Value, 10, 64); err == nil {
+						value = number
+					}
+				} else if number, err := strconv.ParseFloat(row.MetricValues[j].Value, 64); err == nil {
+					value = number
+				}
+			}
+			values = append(values, value)
+		}
+		values = append(values, loadedAt)
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("failed to load rows into %s: %w", t.table, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO `+loadsTable+` VALUES (?, ?, ?, ?, ?)`, t.table, r.Start, r.End, len(result.Rows), loadedAt)
+	if err != nil {
+		return fmt.Errorf("failed to log load: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit load: %w", err)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// columns returns the table's column names in order, or none if it doesn't
+// exist yet
+func (t *Target) columns(ctx context.Context, q queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = 'main' AND table_name = ?
+		ORDER BY ordinal_position
+	`, t.table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", t.table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", t.table, err)
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
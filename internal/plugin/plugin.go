@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"ga4admin/internal/config"
+)
+
+// Prefix names plugin executables: 'ga4admin report-sla' runs ga4admin-report-sla
+const Prefix = "ga4admin-"
+
+// Environment variables plugins receive besides config.HomeEnvVar, which is
+// always set to the data directory in use
+const (
+	PresetEnvVar   = "GA4ADMIN_PRESET"   // Active preset, or the one given with --preset
+	PropertyEnvVar = "GA4ADMIN_PROPERTY" // Property ID from --property, aliases resolved
+	BinEnvVar      = "GA4ADMIN_BIN"      // Path of the ga4admin binary, for calling back
+	VersionEnvVar  = "GA4ADMIN_VERSION"
+)
+
+// Plugin is a ga4admin-<name> executable found on PATH
+type Plugin struct {
+	Name string // Command name, e.g. "report-sla"
+	Path string
+	// Shadowed are executables with the same name later on PATH, which never run
+	Shadowed []string
+}
+
+// List returns the plugins on PATH sorted by name. Like the shell, the first
+// executable of a name on PATH wins.
+func List() []Plugin {
+	byName := make(map[string]*Plugin)
+	seenDirs := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := commandName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			if existing, ok := byName[name]; ok {
+				existing.Shadowed = append(existing.Shadowed, path)
+				continue
+			}
+			byName[name] = &Plugin{Name: name, Path: path}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(byName))
+	for _, p := range byName {
+		plugins = append(plugins, *p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Find returns the plugin for a command line and the arguments left for it.
+// The longest match wins, so 'ga4admin audit sla --days 7' runs
+// ga4admin-audit-sla with '--days 7' when it exists, else ga4admin-audit with
+// 'sla --days 7'. Matching stops at the first flag.
+func Find(args []string) (path string, rest []string, ok bool) {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+
+	for n := len(words); n > 0; n-- {
+		path, err := exec.LookPath(Prefix + strings.Join(words[:n], "-"))
+		if err == nil {
+			return path, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+// Context is what a plugin is told about the invocation
+type Context struct {
+	Preset     string
+	PropertyID string
+	Binary     string
+	Version    string
+}
+
+// Run runs a plugin with the terminal attached and returns its exit code
+func Run(path string, args []string, pluginCtx Context) (int, error) {
+	home, err := config.GetConfigDir()
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		config.HomeEnvVar+"="+home,
+		PresetEnvVar+"="+pluginCtx.Preset,
+		PropertyEnvVar+"="+pluginCtx.PropertyID,
+		BinEnvVar+"="+pluginCtx.Binary,
+		VersionEnvVar+"="+pluginCtx.Version,
+	)
+
+	// Ctrl-C reaches the plugin too; it decides how to stop and its exit
+	// code is passed on
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() < 0 {
+			// Killed by a signal
+			return 1, nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to run plugin %s: %w", path, err)
+	}
+	return 0, nil
+}
+
+// commandName returns the command a plugin file provides, without the
+// prefix and, on Windows, the executable extension
+func commandName(file string) (string, bool) {
+	if !strings.HasPrefix(file, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, name != ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,95 @@
+package preset
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"ga4admin/internal/config"
+)
+
+var (
+	// GA4 property IDs are purely numeric
+	numericPropertyID = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// PropertyAlias maps a human-friendly name to a property ID
+type PropertyAlias struct {
+	Name       string
+	PropertyID string
+}
+
+// IsValidAliasName validates an alias name. Aliases share the preset name
+// charset and cannot be purely numeric, so they never shadow a property ID.
+func IsValidAliasName(name string) bool {
+	return IsValidPresetName(name) && !numericPropertyID.MatchString(name)
+}
+
+// IsPropertyID reports whether value looks like a numeric GA4 property ID
+func IsPropertyID(value string) bool {
+	return numericPropertyID.MatchString(value)
+}
+
+// SetAlias points an alias at a property ID in the given preset, replacing
+// any existing alias with the same name
+func SetAlias(presetName, alias, propertyID string) error {
+	if !IsValidAliasName(alias) {
+		return fmt.Errorf("invalid alias name '%s': use letters, numbers, underscores, and hyphens, and include at least one non-digit", alias)
+	}
+	if !IsPropertyID(propertyID) {
+		return fmt.Errorf("invalid property ID '%s': must be numeric", propertyID)
+	}
+
+	p, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	if p.Aliases == nil {
+		p.Aliases = make(map[string]string)
+	}
+	p.Aliases[alias] = propertyID
+	return SavePreset(p)
+}
+
+// RemoveAlias deletes an alias from the given preset
+func RemoveAlias(presetName, alias string) error {
+	p, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.Aliases[alias]; !ok {
+		return fmt.Errorf("alias '%s' does not exist in preset '%s'", alias, presetName)
+	}
+	delete(p.Aliases, alias)
+	return SavePreset(p)
+}
+
+// ListAliases returns the preset's aliases sorted by name
+func ListAliases(p *config.Preset) []PropertyAlias {
+	aliases := make([]PropertyAlias, 0, len(p.Aliases))
+	for name, propertyID := range p.Aliases {
+		aliases = append(aliases, PropertyAlias{Name: name, PropertyID: propertyID})
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases
+}
+
+// ResolveProperty turns a property reference into a property ID. Numeric
+// values are returned unchanged; anything else must be an alias defined in
+// the preset.
+func ResolveProperty(p *config.Preset, value string) (string, error) {
+	if value == "" || IsPropertyID(value) {
+		return value, nil
+	}
+	if p != nil {
+		if propertyID, ok := p.Aliases[value]; ok {
+			return propertyID, nil
+		}
+		return "", fmt.Errorf("'%s' is not a property ID or an alias in preset '%s' (see 'ga4admin alias list')", value, p.Name)
+	}
+	return "", fmt.Errorf("'%s' is not a property ID (aliases require an active preset)", value)
+}
@@ -1,6 +1,7 @@
 package preset
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +16,8 @@ import (
 const (
 	PresetsDirName = "presets"
 	PresetFileExt  = ".yaml"
+	TokensDirName  = "tokens"
+	TokenFileExt   = ".token"
 )
 
 var (
@@ -22,6 +25,12 @@ var (
 	validPresetName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 )
 
+// ErrNotFound means no preset has the requested name
+var ErrNotFound = errors.New("does not exist")
+
+// ErrNoActivePreset means no preset has been selected with 'preset use'
+var ErrNoActivePreset = errors.New("no active preset")
+
 // GetPresetsDir returns the path to the presets directory (~/.ga4admin/presets)
 func GetPresetsDir() (string, error) {
 	configDir, err := config.GetConfigDir()
@@ -44,6 +53,20 @@ func GetPresetPath(presetName string) (string, error) {
 	return filepath.Join(presetsDir, presetName+PresetFileExt), nil
 }
 
+// GetTokenCachePath returns the file holding a preset's encrypted access
+// token between invocations (~/.ga4admin/tokens/<name>.token)
+func GetTokenCachePath(presetName string) (string, error) {
+	if !IsValidPresetName(presetName) {
+		return "", fmt.Errorf("invalid preset name: must contain only letters, numbers, underscores, and hyphens")
+	}
+
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, TokensDirName, presetName+TokenFileExt), nil
+}
+
 // EnsurePresetsDir creates the presets directory if it doesn't exist
 func EnsurePresetsDir() error {
 	presetsDir, err := GetPresetsDir()
@@ -86,7 +109,7 @@ func LoadPreset(presetName string) (*config.Preset, error) {
 
 	// Check if preset file exists
 	if _, err := os.Stat(presetPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("preset '%s' does not exist", presetName)
+		return nil, fmt.Errorf("preset '%s' %w", presetName, ErrNotFound)
 	}
 
 	// Read preset file
@@ -162,7 +185,7 @@ func DeletePreset(presetName string) error {
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("preset '%s' does not exist", presetName)
+		return fmt.Errorf("preset '%s' %w", presetName, ErrNotFound)
 	}
 
 	// Remove the file
@@ -170,6 +193,11 @@ func DeletePreset(presetName string) error {
 		return fmt.Errorf("failed to delete preset file: %w", err)
 	}
 
+	// The saved access token is useless without the preset
+	if tokenPath, err := GetTokenCachePath(presetName); err == nil {
+		os.Remove(tokenPath)
+	}
+
 	// If this was the active preset, clear it from global config
 	activePreset, err := config.GetActivePreset()
 	if err == nil && activePreset == presetName {
@@ -219,34 +247,58 @@ func ListPresets() ([]config.Preset, error) {
 	return presets, nil
 }
 
-// CreatePreset creates a new preset with validation
-func CreatePreset(name, refreshToken, userEmail string) error {
-	if !IsValidPresetName(name) {
-		return fmt.Errorf("invalid preset name: must contain only letters, numbers, underscores, and hyphens (max 50 chars)")
-	}
-
+// CreatePreset creates a new preset with validation. scopes are the OAuth
+// scopes granted to the refresh token; nil means unknown.
+func CreatePreset(name, refreshToken, userEmail string, scopes []string) error {
 	if strings.TrimSpace(refreshToken) == "" {
 		return fmt.Errorf("refresh token is required")
 	}
 
+	return createPreset(&config.Preset{
+		Name:         name,
+		RefreshToken: strings.TrimSpace(refreshToken),
+		UserEmail:    strings.TrimSpace(userEmail),
+		Scopes:       scopes,
+	})
+}
+
+// CreateServiceAccountPreset creates a preset that authenticates with a
+// service account key, acting as the impersonate user through domain-wide
+// delegation when one is given. scopes are requested with every token.
+func CreateServiceAccountPreset(name, keyPath, impersonate string, scopes []string) error {
+	if strings.TrimSpace(keyPath) == "" {
+		return fmt.Errorf("service account key is required")
+	}
+	keyPath, err := filepath.Abs(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve service account key path: %w", err)
+	}
+
+	return createPreset(&config.Preset{
+		Name:              name,
+		ServiceAccountKey: keyPath,
+		Impersonate:       strings.TrimSpace(impersonate),
+		Scopes:            scopes,
+	})
+}
+
+func createPreset(preset *config.Preset) error {
+	if !IsValidPresetName(preset.Name) {
+		return fmt.Errorf("invalid preset name: must contain only letters, numbers, underscores, and hyphens (max 50 chars)")
+	}
+
 	// Check if preset already exists
-	exists, err := PresetExists(name)
+	exists, err := PresetExists(preset.Name)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("preset '%s' already exists", name)
+		return fmt.Errorf("preset '%s' already exists", preset.Name)
 	}
 
-	// Create new preset
-	preset := &config.Preset{
-		Name:         name,
-		RefreshToken: strings.TrimSpace(refreshToken),
-		UserEmail:    strings.TrimSpace(userEmail),
-		CreatedAt:    time.Now(),
-		LastUsed:     time.Now(),
-		Accounts:     []config.Account{}, // Initialize empty accounts slice
-	}
+	preset.CreatedAt = time.Now()
+	preset.LastUsed = time.Now()
+	preset.Accounts = []config.Account{} // Initialize empty accounts slice
 
 	// Save preset
 	if err := SavePreset(preset); err != nil {
@@ -265,7 +317,7 @@ func SetActivePreset(presetName string) error {
 			return err
 		}
 		if !exists {
-			return fmt.Errorf("preset '%s' does not exist", presetName)
+			return fmt.Errorf("preset '%s' %w", presetName, ErrNotFound)
 		}
 	}
 
@@ -286,4 +338,60 @@ func GetActivePreset() (*config.Preset, error) {
 
 	// Load and return the active preset
 	return LoadPreset(activePresetName)
-}
\ No newline at end of file
+}
+// SetNeedsReauth records whether Google has rejected a preset's refresh token,
+// so 'preset list' can point at 'preset reauth'
+func SetNeedsReauth(presetName string, needsReauth bool) error {
+	preset, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+	if preset.NeedsReauth == needsReauth {
+		return nil
+	}
+
+	preset.NeedsReauth = needsReauth
+	return SavePreset(preset)
+}
+
+// SetImpersonate changes the user a service account preset acts as; an empty
+// subject makes it act as the service account itself
+func SetImpersonate(presetName, subject string) error {
+	preset, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+	if preset.ServiceAccountKey == "" {
+		return fmt.Errorf("preset '%s' signs in with a refresh token; only service account presets can impersonate users", presetName)
+	}
+
+	preset.Impersonate = strings.TrimSpace(subject)
+	return SavePreset(preset)
+}
+
+// SetScopes records the OAuth scopes granted to a preset's refresh token
+func SetScopes(presetName string, scopes []string) error {
+	preset, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	preset.Scopes = scopes
+	return SavePreset(preset)
+}
+
+// ReplaceRefreshToken swaps in a new refresh token for an existing preset,
+// with the scopes granted to it (nil means unknown). Accounts, aliases and the
+// preset's cache are kept.
+func ReplaceRefreshToken(presetName, refreshToken string, scopes []string) error {
+	preset, err := LoadPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	preset.RefreshToken = refreshToken
+	preset.Scopes = scopes
+	preset.NeedsReauth = false
+	preset.LastUsed = time.Now()
+	return SavePreset(preset)
+}
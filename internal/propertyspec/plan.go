@@ -0,0 +1,432 @@
+package propertyspec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/customdims"
+)
+
+// Resource kinds a property file manages
+const (
+	ResourceCustomDimension = "custom dimension"
+	ResourceKeyEvent        = "key event"
+	ResourceChannelGroup    = "channel group"
+	ResourceDataRetention   = "data retention"
+)
+
+// Action is what applying a plan does to one resource
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionArchive Action = "archive" // Custom dimensions can't be deleted
+)
+
+// State is the live configuration of the sections a file manages. Sections
+// the file leaves out stay nil.
+type State struct {
+	CustomDimensions []api.CustomDimension
+	KeyEvents        []api.KeyEvent
+	ChannelGroups    []api.ChannelGroup
+	DataRetention    *api.DataRetentionSettings
+}
+
+// Change is one planned write
+type Change struct {
+	Resource string   `json:"resource"`
+	Action   Action   `json:"action"`
+	Name     string   `json:"name"`
+	Details  []string `json:"details,omitempty"` // What differs, for updates
+
+	apply func(ctx context.Context, client api.AdminService) error
+}
+
+// Plan lists the changes that make a property match a file
+type Plan struct {
+	PropertyID string   `json:"property_id"`
+	Changes    []Change `json:"changes"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// FetchState reads the live configuration of every section the file manages
+func FetchState(ctx context.Context, client api.AdminService, propertyID string, file *File) (*State, error) {
+	state := &State{}
+	var err error
+
+	if file.CustomDimensions != nil {
+		if state.CustomDimensions, err = client.ListCustomDimensions(ctx, propertyID); err != nil {
+			return nil, fmt.Errorf("failed to list custom dimensions: %w", err)
+		}
+	}
+	if file.KeyEvents != nil {
+		if state.KeyEvents, err = client.ListKeyEvents(ctx, propertyID); err != nil {
+			return nil, fmt.Errorf("failed to list key events: %w", err)
+		}
+	}
+	if file.ChannelGroups != nil {
+		if state.ChannelGroups, err = client.ListChannelGroups(ctx, propertyID); err != nil {
+			return nil, fmt.Errorf("failed to list channel groups: %w", err)
+		}
+	}
+	if file.DataRetention != nil {
+		if state.DataRetention, err = client.GetDataRetentionSettings(ctx, propertyID); err != nil {
+			return nil, fmt.Errorf("failed to read data retention settings: %w", err)
+		}
+	}
+
+	return state, nil
+}
+
+// BuildPlan diffs a file against a property's live state. Items missing from
+// a managed section are removed unless keepUnlisted is set; built-in key
+// events and system channel groups are never touched.
+func BuildPlan(propertyID string, file *File, state *State, keepUnlisted bool) *Plan {
+	plan := &Plan{PropertyID: propertyID}
+	if file.CustomDimensions != nil {
+		plan.planCustomDimensions(file.CustomDimensions, state.CustomDimensions, keepUnlisted)
+	}
+	if file.KeyEvents != nil {
+		plan.planKeyEvents(file.KeyEvents, state.KeyEvents, keepUnlisted)
+	}
+	if file.ChannelGroups != nil {
+		plan.planChannelGroups(file.ChannelGroups, state.ChannelGroups, keepUnlisted)
+	}
+	if file.DataRetention != nil && state.DataRetention != nil {
+		plan.planDataRetention(*file.DataRetention, *state.DataRetention)
+	}
+	return plan
+}
+
+func (p *Plan) planCustomDimensions(specs []customdims.Spec, existing []api.CustomDimension, keepUnlisted bool) {
+	declared := make(map[string]customdims.Spec, len(specs))
+	for _, spec := range specs {
+		declared[spec.Scope+"/"+spec.ParameterName] = spec
+	}
+
+	dimsPlan := customdims.BuildPlan(p.PropertyID, specs, existing, keepUnlisted)
+	for _, change := range dimsPlan.Changes {
+		dimension := change.Dimension
+		name := fmt.Sprintf("%s %s", dimension.Scope, dimension.ParameterName)
+
+		switch change.Action {
+		case customdims.ActionCreate:
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceCustomDimension, Action: ActionCreate, Name: name,
+				Details: []string{fmt.Sprintf("display name '%s'", dimension.DisplayName)},
+				apply: func(ctx context.Context, client api.AdminService) error {
+					_, err := client.CreateCustomDimension(ctx, p.PropertyID, dimension)
+					return err
+				},
+			})
+		case customdims.ActionArchive:
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceCustomDimension, Action: ActionArchive, Name: name,
+				apply: func(ctx context.Context, client api.AdminService) error {
+					return client.ArchiveCustomDimension(ctx, dimension.Name)
+				},
+			})
+		case customdims.ActionSkip:
+			spec, ok := declared[dimension.Scope+"/"+dimension.ParameterName]
+			if !ok {
+				continue
+			}
+			var details []string
+			if dimension.DisplayName != spec.DisplayName {
+				details = append(details, fmt.Sprintf("display name '%s' → '%s'", dimension.DisplayName, spec.DisplayName))
+			}
+			if dimension.Description != spec.Description {
+				details = append(details, fmt.Sprintf("description '%s' → '%s'", dimension.Description, spec.Description))
+			}
+			if dimension.DisallowAdsPersonalization != spec.DisallowAdsPersonalization {
+				details = append(details, fmt.Sprintf("disallow ads personalization %t → %t", dimension.DisallowAdsPersonalization, spec.DisallowAdsPersonalization))
+			}
+			if len(details) == 0 {
+				continue
+			}
+
+			updated := dimension
+			updated.DisplayName = spec.DisplayName
+			updated.Description = spec.Description
+			updated.DisallowAdsPersonalization = spec.DisallowAdsPersonalization
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceCustomDimension, Action: ActionUpdate, Name: name, Details: details,
+				apply: func(ctx context.Context, client api.AdminService) error {
+					return client.UpdateCustomDimension(ctx, updated)
+				},
+			})
+		}
+	}
+	p.Warnings = append(p.Warnings, dimsPlan.LimitWarnings()...)
+}
+
+func (p *Plan) planKeyEvents(specs []KeyEventSpec, existing []api.KeyEvent, keepUnlisted bool) {
+	current := make(map[string]api.KeyEvent, len(existing))
+	for _, event := range existing {
+		current[event.EventName] = event
+	}
+
+	declared := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		spec := spec
+		declared[spec.EventName] = true
+
+		event, ok := current[spec.EventName]
+		if !ok {
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceKeyEvent, Action: ActionCreate, Name: spec.EventName,
+				Details: []string{"counted " + countingLabel(spec.CountingMethod)},
+				apply: func(ctx context.Context, client api.AdminService) error {
+					_, err := client.CreateKeyEvent(ctx, p.PropertyID, api.KeyEvent{EventName: spec.EventName, CountingMethod: spec.CountingMethod})
+					return err
+				},
+			})
+			continue
+		}
+
+		if event.CountingMethod != spec.CountingMethod {
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceKeyEvent, Action: ActionUpdate, Name: spec.EventName,
+				Details: []string{fmt.Sprintf("counting method %s → %s", event.CountingMethod, spec.CountingMethod)},
+				apply: func(ctx context.Context, client api.AdminService) error {
+					return client.UpdateKeyEventCountingMethod(ctx, event.Name, spec.CountingMethod)
+				},
+			})
+		}
+	}
+
+	if keepUnlisted {
+		return
+	}
+	for _, event := range existing {
+		event := event
+		if declared[event.EventName] {
+			continue
+		}
+		if !event.Deletable {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("key event '%s' is built in and can't be deleted; add it to key_events to silence this", event.EventName))
+			continue
+		}
+		p.Changes = append(p.Changes, Change{
+			Resource: ResourceKeyEvent, Action: ActionDelete, Name: event.EventName,
+			apply: func(ctx context.Context, client api.AdminService) error {
+				return client.DeleteKeyEvent(ctx, event.Name)
+			},
+		})
+	}
+}
+
+func (p *Plan) planChannelGroups(specs []ChannelGroupSpec, existing []api.ChannelGroup, keepUnlisted bool) {
+	current := make(map[string]api.ChannelGroup, len(existing))
+	for _, group := range existing {
+		if !group.SystemDefined {
+			current[group.DisplayName] = group
+		}
+	}
+
+	declared := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		declared[spec.DisplayName] = true
+		desired := api.ChannelGroup{DisplayName: spec.DisplayName, Description: spec.Description, GroupingRules: spec.Rules}
+
+		group, ok := current[spec.DisplayName]
+		if !ok {
+			p.Changes = append(p.Changes, Change{
+				Resource: ResourceChannelGroup, Action: ActionCreate, Name: spec.DisplayName,
+				Details: []string{fmt.Sprintf("%d rules", len(spec.Rules))},
+				apply: func(ctx context.Context, client api.AdminService) error {
+					_, err := client.CreateChannelGroup(ctx, p.PropertyID, desired)
+					return err
+				},
+			})
+			continue
+		}
+
+		var details []string
+		if group.Description != spec.Description {
+			details = append(details, fmt.Sprintf("description '%s' → '%s'", group.Description, spec.Description))
+		}
+		details = append(details, ruleDifferences(group.GroupingRules, spec.Rules)...)
+		if len(details) == 0 {
+			continue
+		}
+
+		desired.Name = group.Name
+		p.Changes = append(p.Changes, Change{
+			Resource: ResourceChannelGroup, Action: ActionUpdate, Name: spec.DisplayName, Details: details,
+			apply: func(ctx context.Context, client api.AdminService) error {
+				return client.UpdateChannelGroup(ctx, desired)
+			},
+		})
+	}
+
+	if keepUnlisted {
+		return
+	}
+	for _, group := range existing {
+		group := group
+		if group.SystemDefined || declared[group.DisplayName] {
+			continue
+		}
+		if group.Primary {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("channel group '%s' is the primary channel group and won't be deleted", group.DisplayName))
+			continue
+		}
+		p.Changes = append(p.Changes, Change{
+			Resource: ResourceChannelGroup, Action: ActionDelete, Name: group.DisplayName,
+			apply: func(ctx context.Context, client api.AdminService) error {
+				return client.DeleteChannelGroup(ctx, group.Name)
+			},
+		})
+	}
+}
+
+func (p *Plan) planDataRetention(spec DataRetentionSpec, current api.DataRetentionSettings) {
+	desired := current
+	var details []string
+	if spec.EventDataRetention != "" && spec.EventDataRetention != current.EventDataRetention {
+		desired.EventDataRetention = spec.EventDataRetention
+		details = append(details, fmt.Sprintf("event data retention %s → %s", current.EventDataRetention, spec.EventDataRetention))
+	}
+	if spec.ResetUserDataOnNewActivity != nil && *spec.ResetUserDataOnNewActivity != current.ResetUserDataOnNewActivity {
+		desired.ResetUserDataOnNewActivity = *spec.ResetUserDataOnNewActivity
+		details = append(details, fmt.Sprintf("reset user data on new activity %t → %t", current.ResetUserDataOnNewActivity, desired.ResetUserDataOnNewActivity))
+	}
+	if len(details) == 0 {
+		return
+	}
+
+	p.Changes = append(p.Changes, Change{
+		Resource: ResourceDataRetention, Action: ActionUpdate, Name: "settings", Details: details,
+		apply: func(ctx context.Context, client api.AdminService) error {
+			return client.UpdateDataRetentionSettings(ctx, p.PropertyID, desired)
+		},
+	})
+}
+
+// ruleDifferences describes how two rule lists differ by channel name, with
+// a catch-all when only the order changed
+func ruleDifferences(current, desired []api.GroupingRule) []string {
+	if rulesEqual(current, desired) {
+		return nil
+	}
+
+	currentByName := make(map[string]api.GroupingRule, len(current))
+	for _, rule := range current {
+		currentByName[rule.DisplayName] = rule
+	}
+	desiredNames := make(map[string]bool, len(desired))
+
+	var details []string
+	for _, rule := range desired {
+		desiredNames[rule.DisplayName] = true
+		old, ok := currentByName[rule.DisplayName]
+		switch {
+		case !ok:
+			details = append(details, fmt.Sprintf("add channel '%s'", rule.DisplayName))
+		case !rulesEqual([]api.GroupingRule{old}, []api.GroupingRule{rule}):
+			details = append(details, fmt.Sprintf("change channel '%s'", rule.DisplayName))
+		}
+	}
+	for _, rule := range current {
+		if !desiredNames[rule.DisplayName] {
+			details = append(details, fmt.Sprintf("remove channel '%s'", rule.DisplayName))
+		}
+	}
+	if len(details) == 0 {
+		details = append(details, "reorder channels")
+	}
+	return details
+}
+
+// rulesEqual compares rule lists by their API encoding
+func rulesEqual(a, b []api.GroupingRule) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+func countingLabel(method string) string {
+	if method == CountingOncePerSession {
+		return "once per session"
+	}
+	return "once per event"
+}
+
+// Count returns the number of changes with the given action
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Apply runs removals first, then updates, then creates, so archived custom
+// dimension slots are free and renamed channels don't collide. Every change
+// is attempted; progress is called after each with its error, and the number
+// of failures is returned.
+func (p *Plan) Apply(ctx context.Context, client api.AdminService, progress func(change Change, err error)) int {
+	failed := 0
+	for _, actions := range [][]Action{{ActionArchive, ActionDelete}, {ActionUpdate}, {ActionCreate}} {
+		for _, change := range p.Changes {
+			if !containsAction(actions, change.Action) {
+				continue
+			}
+			err := change.apply(ctx, client)
+			if err != nil {
+				failed++
+			}
+			if progress != nil {
+				progress(change, err)
+			}
+		}
+	}
+	return failed
+}
+
+func containsAction(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary is a one-line count of the plan's changes
+func (p *Plan) Summary() string {
+	removals := p.Count(ActionArchive) + p.Count(ActionDelete)
+	return fmt.Sprintf("%d to create, %d to update, %d to remove", p.Count(ActionCreate), p.Count(ActionUpdate), removals)
+}
+
+// Symbol is the plan-output marker for an action
+func (a Action) Symbol() string {
+	switch a {
+	case ActionCreate:
+		return "+"
+	case ActionUpdate:
+		return "~"
+	default:
+		return "-"
+	}
+}
+
+// String renders the change as one plan line without the symbol
+func (c Change) String() string {
+	line := fmt.Sprintf("%s %s", c.Resource, c.Name)
+	if c.Action == ActionArchive {
+		line += " (archive)"
+	}
+	if len(c.Details) > 0 {
+		line += ": " + strings.Join(c.Details, "; ")
+	}
+	return line
+}
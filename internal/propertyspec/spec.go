@@ -0,0 +1,187 @@
+package propertyspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/channelgroup"
+	"ga4admin/internal/customdims"
+)
+
+// Key event counting methods
+const (
+	CountingOncePerEvent   = "ONCE_PER_EVENT"
+	CountingOncePerSession = "ONCE_PER_SESSION"
+)
+
+var (
+	eventNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+	retentionPeriods = []string{
+		api.RetentionTwoMonths,
+		api.RetentionFourteenMonths,
+		api.RetentionTwentySixMonths,
+		api.RetentionThirtyEightMonths,
+		api.RetentionFiftyMonths,
+	}
+)
+
+// File is the desired configuration of one property. A section that is left
+// out is not managed; a section that is present (even as an empty list)
+// describes everything of that kind the property should have.
+type File struct {
+	Property         string             `json:"property,omitempty"` // Property ID or alias
+	CustomDimensions []customdims.Spec  `json:"custom_dimensions"`
+	KeyEvents        []KeyEventSpec     `json:"key_events"`
+	ChannelGroups    []ChannelGroupSpec `json:"channel_groups"`
+	DataRetention    *DataRetentionSpec `json:"data_retention,omitempty"`
+}
+
+// KeyEventSpec declares an event that should count as a key event
+type KeyEventSpec struct {
+	EventName      string `json:"event_name"`
+	CountingMethod string `json:"counting_method,omitempty"` // Defaults to ONCE_PER_EVENT
+}
+
+// ChannelGroupSpec declares a custom channel group. Rules use the Admin API's
+// own field names (displayName, expression, filter, ...) so groups exported
+// from GA4 can be pasted in unchanged.
+type ChannelGroupSpec struct {
+	DisplayName string             `json:"display_name"`
+	Description string             `json:"description,omitempty"`
+	Rules       []api.GroupingRule `json:"rules"`
+}
+
+// DataRetentionSpec declares the property's data retention settings. Unset
+// fields keep their current value.
+type DataRetentionSpec struct {
+	EventDataRetention         string `json:"event_data_retention,omitempty"`
+	ResetUserDataOnNewActivity *bool  `json:"reset_user_data_on_new_activity,omitempty"`
+}
+
+// Load reads and validates a YAML or JSON property file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read property file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+	case ".yaml", ".yml":
+		// Go through JSON so YAML and JSON files share one set of field names
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to parse property file: %w", err)
+		}
+		if data, err = json.Marshal(generic); err != nil {
+			return nil, fmt.Errorf("failed to parse property file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported property file '%s' (use .yaml, .yml or .json)", path)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file File
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse property file: %w", err)
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Validate checks every managed section and normalizes it in place
+func (f *File) Validate() error {
+	if f.CustomDimensions == nil && f.KeyEvents == nil && f.ChannelGroups == nil && f.DataRetention == nil {
+		return fmt.Errorf("property file manages nothing (add custom_dimensions, key_events, channel_groups or data_retention)")
+	}
+
+	if err := customdims.ValidateSpecs(f.CustomDimensions); err != nil {
+		return fmt.Errorf("custom_dimensions: %w", err)
+	}
+
+	seenEvents := make(map[string]int, len(f.KeyEvents))
+	for i := range f.KeyEvents {
+		event := &f.KeyEvents[i]
+		event.EventName = strings.TrimSpace(event.EventName)
+		event.CountingMethod = strings.ToUpper(strings.TrimSpace(event.CountingMethod))
+		if event.CountingMethod == "" {
+			event.CountingMethod = CountingOncePerEvent
+		}
+
+		switch {
+		case event.EventName == "":
+			return fmt.Errorf("key_events: entry %d: event_name is required", i+1)
+		case !eventNamePattern.MatchString(event.EventName) || len(event.EventName) > 40:
+			return fmt.Errorf("key_events: entry %d: invalid event name '%s'", i+1, event.EventName)
+		case event.CountingMethod != CountingOncePerEvent && event.CountingMethod != CountingOncePerSession:
+			return fmt.Errorf("key_events: entry %d (%s): counting_method must be %s or %s", i+1, event.EventName, CountingOncePerEvent, CountingOncePerSession)
+		}
+		if first, ok := seenEvents[event.EventName]; ok {
+			return fmt.Errorf("key_events: entry %d: '%s' is already declared by entry %d", i+1, event.EventName, first)
+		}
+		seenEvents[event.EventName] = i + 1
+	}
+
+	seenGroups := make(map[string]int, len(f.ChannelGroups))
+	for i := range f.ChannelGroups {
+		group := &f.ChannelGroups[i]
+		group.DisplayName = strings.TrimSpace(group.DisplayName)
+
+		switch {
+		case group.DisplayName == "":
+			return fmt.Errorf("channel_groups: entry %d: display_name is required", i+1)
+		case len(group.DisplayName) > 80:
+			return fmt.Errorf("channel_groups: entry %d: display name is longer than 80 characters", i+1)
+		case len(group.Rules) == 0:
+			return fmt.Errorf("channel_groups: '%s' has no rules", group.DisplayName)
+		}
+		if first, ok := seenGroups[group.DisplayName]; ok {
+			return fmt.Errorf("channel_groups: entry %d: '%s' is already declared by entry %d", i+1, group.DisplayName, first)
+		}
+		seenGroups[group.DisplayName] = i + 1
+
+		for j, rule := range group.Rules {
+			if strings.TrimSpace(rule.DisplayName) == "" {
+				return fmt.Errorf("channel_groups: '%s' rule %d has no displayName", group.DisplayName, j+1)
+			}
+			if _, err := channelgroup.ParseExpression(rule.Expression); err != nil {
+				return fmt.Errorf("channel_groups: '%s' rule '%s': %w", group.DisplayName, rule.DisplayName, err)
+			}
+		}
+	}
+
+	if f.DataRetention != nil {
+		retention := strings.ToUpper(strings.TrimSpace(f.DataRetention.EventDataRetention))
+		f.DataRetention.EventDataRetention = retention
+		if retention != "" && !contains(retentionPeriods, retention) {
+			return fmt.Errorf("data_retention: event_data_retention must be one of %s", strings.Join(retentionPeriods, ", "))
+		}
+		if retention == "" && f.DataRetention.ResetUserDataOnNewActivity == nil {
+			return fmt.Errorf("data_retention: set event_data_retention or reset_user_data_on_new_activity")
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ga4admin/internal/api"
+)
+
+// OtherBucket labels the row that folds together everything outside the top N
+const OtherBucket = "(other)"
+
+// BucketSummary describes what TopN did to a result
+type BucketSummary struct {
+	Kept     int      // Rows kept as they were
+	Bucketed int      // Rows folded into the (other) row
+	Unsummed []string // Metrics left empty in the (other) row because summing them is meaningless
+	Partial  bool     // GA4 matched more rows than were fetched, so (other) misses some
+}
+
+// TopN keeps the n rows with the highest first metric. With bucketOther the
+// remaining rows are folded into one row whose dimensions all read
+// "(other)", summing metrics that can be summed. The result is a copy;
+// totals, minimums and maximums still describe the whole result.
+func TopN(result *QueryResult, n int, bucketOther bool) (*QueryResult, BucketSummary, error) {
+	var summary BucketSummary
+	if n <= 0 {
+		return nil, summary, fmt.Errorf("top N must be positive")
+	}
+	if len(result.MetricHeaders) == 0 {
+		return nil, summary, fmt.Errorf("top N needs a metric to rank rows by")
+	}
+
+	rows := make([]api.Row, len(result.Rows))
+	copy(rows, result.Rows)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return metricAt(rows[i], 0) > metricAt(rows[j], 0)
+	})
+
+	trimmed := *result
+	if len(rows) <= n {
+		trimmed.Rows = rows
+		summary.Kept = len(rows)
+		return &trimmed, summary, nil
+	}
+
+	trimmed.Rows = rows[:n:n]
+	summary.Kept = n
+	summary.Bucketed = len(rows) - n
+
+	if bucketOther {
+		calculated := make(map[string]bool)
+		if result.QueryConfig != nil {
+			for _, metric := range result.QueryConfig.CalculatedMetrics {
+				calculated[metric.Name] = true
+			}
+		}
+
+		other := api.Row{}
+		for range result.DimensionHeaders {
+			other.DimensionValues = append(other.DimensionValues, api.DimensionValue{Value: OtherBucket})
+		}
+		for i, header := range result.MetricHeaders {
+			if calculated[header.Name] || !IsReaggregatable(header.Name) {
+				summary.Unsummed = append(summary.Unsummed, header.Name)
+				other.MetricValues = append(other.MetricValues, api.MetricValue{})
+				continue
+			}
+			sum := 0.0
+			for _, row := range rows[n:] {
+				sum += metricAt(row, i)
+			}
+			other.MetricValues = append(other.MetricValues, api.MetricValue{Value: strconv.FormatFloat(sum, 'f', -1, 64)})
+		}
+		trimmed.Rows = append(trimmed.Rows, other)
+		summary.Partial = result.RowCount > len(result.Rows)
+	}
+
+	trimmed.RowCount = len(trimmed.Rows)
+	return &trimmed, summary, nil
+}
+
+// IsReaggregatable reports whether summing the metric across rows is meaningful.
+// Rates, averages, per-X ratios and distinct user counts are not.
+func IsReaggregatable(name string) bool {
+	if strings.HasSuffix(name, "Rate") || strings.HasPrefix(name, "average") || strings.Contains(name, "Per") {
+		return false
+	}
+	switch name {
+	case "totalUsers", "activeUsers", "active1DayUsers", "active7DayUsers", "active28DayUsers":
+		return false
+	}
+	return true
+}
+
+// StoreTopN caches a TopN result under its own query ID so results show and
+// export return the trimmed rows
+func (e *Executor) StoreTopN(ctx context.Context, store api.CacheInterface, result *QueryResult, n int, bucketOther bool) error {
+	request, err := e.configToRequest(result.QueryConfig)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(struct {
+		Top         int                   `json:"top"`
+		BucketOther bool                  `json:"bucket_other"`
+		Request     *api.RunReportRequest `json:"request"`
+	}{n, bucketOther, request})
+	queryHash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	response := api.RunReportResponse{
+		DimensionHeaders: result.DimensionHeaders,
+		MetricHeaders:    result.MetricHeaders,
+		Rows:             result.Rows,
+		RowCount:         result.RowCount,
+		Totals:           result.Totals,
+		Maximums:         result.Maximums,
+		Minimums:         result.Minimums,
+		PropertyQuota:    result.PropertyQuota,
+	}
+	if result.ResponseMetadata != nil {
+		response.Metadata = *result.ResponseMetadata
+	}
+
+	queryID := e.generateQueryID(ctx, store, request, fmt.Sprintf("top%d", n))
+	ttl := derivedResultTTLHours
+	if err := store.CacheQuery(ctx, queryID, result.PropertyID, queryHash, request, response, result.RowCount, &ttl); err != nil {
+		return err
+	}
+
+	result.QueryID = queryID
+	result.QueryHash = queryHash
+	return nil
+}
+
+func metricAt(row api.Row, i int) float64 {
+	if i >= len(row.MetricValues) {
+		return 0
+	}
+	value, _ := strconv.ParseFloat(row.MetricValues[i].Value, 64)
+	return value
+}
@@ -12,13 +12,13 @@ import (
 
 // QueryBuilder provides interactive query construction capabilities
 type QueryBuilder struct {
-	dataClient *api.DataClient
+	dataClient api.DataService
 	propertyID string
 	metadata   *api.MetadataResponse
 }
 
 // NewQueryBuilder creates a new query builder for a property
-func NewQueryBuilder(dataClient *api.DataClient, propertyID string) *QueryBuilder {
+func NewQueryBuilder(dataClient api.DataService, propertyID string) *QueryBuilder {
 	return &QueryBuilder{
 		dataClient: dataClient,
 		propertyID: propertyID,
@@ -141,23 +141,14 @@ func (qb *QueryBuilder) ValidateQuery(config *QueryConfig) error {
 		return fmt.Errorf("invalid end date format: %s", config.EndDate)
 	}
 
-	// Validate dimensions exist
+	// Validate dimensions and metrics exist, suggesting close matches
 	if qb.metadata != nil {
-		for _, dimName := range config.Dimensions {
-			if !qb.dimensionExists(dimName) {
-				return fmt.Errorf("dimension '%s' not found in property", dimName)
-			}
-		}
-
-		// Validate metrics exist
-		for _, metricName := range config.Metrics {
-			if !qb.metricExists(metricName) {
-				return fmt.Errorf("metric '%s' not found in property", metricName)
-			}
+		if err := ValidateFieldNames(config, qb.metadata); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return ValidateFieldScopes(config)
 }
 
 // Helper methods for interactive configuration
@@ -203,20 +194,21 @@ func (qb *QueryBuilder) configureDimensions(config *QueryConfig) error {
 	// Show common dimensions
 	commonDims := []string{"sessionSource", "sessionMedium", "sessionCampaignName", "country", "deviceCategory"}
 	fmt.Println("Common dimensions:", strings.Join(commonDims, ", "))
-	fmt.Print("Dimensions: ")
 
-	var input string
-	fmt.Scanln(&input)
-	
-	if strings.ToLower(strings.TrimSpace(input)) == "none" {
-		return nil
-	}
+	for {
+		fmt.Print("Dimensions: ")
 
-	dimensions := strings.Split(input, ",")
-	for _, dim := range dimensions {
-		dim = strings.TrimSpace(dim)
-		if dim != "" {
-			config.Dimensions = append(config.Dimensions, dim)
+		var input string
+		fmt.Scanln(&input)
+
+		if strings.ToLower(strings.TrimSpace(input)) == "none" {
+			return nil
+		}
+
+		dimensions := splitFieldList(input)
+		if !qb.reportUnknownFields("dimension", dimensions, qb.dimensionNames()) {
+			config.Dimensions = append(config.Dimensions, dimensions...)
+			break
 		}
 	}
 
@@ -231,20 +223,21 @@ func (qb *QueryBuilder) configureMetrics(config *QueryConfig) error {
 	// Show common metrics
 	commonMetrics := []string{"activeUsers", "sessions", "screenPageViews", "eventCount"}
 	fmt.Println("Common metrics:", strings.Join(commonMetrics, ", "))
-	fmt.Print("Metrics: ")
 
-	var input string
-	fmt.Scanln(&input)
-	
-	if strings.ToLower(strings.TrimSpace(input)) == "none" {
-		return nil
-	}
+	for {
+		fmt.Print("Metrics: ")
+
+		var input string
+		fmt.Scanln(&input)
 
-	metrics := strings.Split(input, ",")
-	for _, metric := range metrics {
-		metric = strings.TrimSpace(metric)
-		if metric != "" {
-			config.Metrics = append(config.Metrics, metric)
+		if strings.ToLower(strings.TrimSpace(input)) == "none" {
+			return nil
+		}
+
+		metrics := splitFieldList(input)
+		if !qb.reportUnknownFields("metric", metrics, qb.metricNames()) {
+			config.Metrics = append(config.Metrics, metrics...)
+			break
 		}
 	}
 
@@ -331,28 +324,64 @@ func (qb *QueryBuilder) configureOptions(config *QueryConfig) error {
 }
 
 // Helper validation methods
-func (qb *QueryBuilder) dimensionExists(name string) bool {
+
+// reportUnknownFields prints a did-you-mean warning for each name not in
+// known and reports whether any were found. Without metadata nothing is checked.
+func (qb *QueryBuilder) reportUnknownFields(kind string, names, known []string) bool {
 	if qb.metadata == nil {
-		return true // Skip validation if metadata not loaded
+		return false
 	}
-	for _, dim := range qb.metadata.Dimensions {
-		if dim.APIName == name {
-			return true
+
+	knownSet := make(map[string]bool, len(known))
+	for _, name := range known {
+		knownSet[name] = true
+	}
+
+	found := false
+	for _, name := range names {
+		if !knownSet[name] {
+			fmt.Printf("⚠️  %s\n", UnknownFieldMessage(kind, name, known))
+			found = true
 		}
 	}
-	return false
+	if found {
+		fmt.Println("Please re-enter the list (or 'none').")
+	}
+	return found
 }
 
-func (qb *QueryBuilder) metricExists(name string) bool {
+func (qb *QueryBuilder) dimensionNames() []string {
 	if qb.metadata == nil {
-		return true // Skip validation if metadata not loaded
+		return nil
+	}
+	names := make([]string, 0, len(qb.metadata.Dimensions))
+	for _, dim := range qb.metadata.Dimensions {
+		names = append(names, dim.APIName)
 	}
+	return names
+}
+
+func (qb *QueryBuilder) metricNames() []string {
+	if qb.metadata == nil {
+		return nil
+	}
+	names := make([]string, 0, len(qb.metadata.Metrics))
 	for _, metric := range qb.metadata.Metrics {
-		if metric.APIName == name {
-			return true
+		names = append(names, metric.APIName)
+	}
+	return names
+}
+
+// splitFieldList splits a comma-separated list of field names
+func splitFieldList(input string) []string {
+	var fields []string
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
 		}
 	}
-	return false
+	return fields
 }
 
 func isRelativeDate(date string) bool {
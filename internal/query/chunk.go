@@ -0,0 +1,279 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// Chunk periods for ExecuteChunked
+const (
+	ChunkByMonth = "month"
+	ChunkByWeek  = "week"
+)
+
+// ChunkDimension is the dimension added to stitched results naming the date
+// range each row came from, like GA4's own "dateRange" for multi-range reports
+const ChunkDimension = "dateRange"
+
+// Chunk retry settings. Only quota, server and network errors are retried.
+const (
+	maxChunkAttempts  = 3
+	chunkRetryBackoff = 2 * time.Second
+)
+
+// derivedResultTTLHours keeps stitched and trimmed results as long as single queries
+const derivedResultTTLHours = 1
+
+const dateLayout = "2006-01-02"
+
+var daysAgoPattern = regexp.MustCompile(`^([0-9]+)daysAgo$`)
+
+// DateChunk is one slice of a chunked date range
+type DateChunk struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// Label names the chunk in the dateRange column and progress output
+func (c DateChunk) Label() string {
+	return c.StartDate + "_" + c.EndDate
+}
+
+// ChunkProgress reports the outcome of one chunk request
+type ChunkProgress struct {
+	Index    int // 0-based
+	Total    int
+	Chunk    DateChunk
+	Attempt  int
+	Rows     int // Rows returned for the chunk
+	RowCount int // Rows GA4 reports as matching, more than Rows if the limit truncated the chunk
+	Sampled  bool
+	Err      error // Set when the attempt failed; Retrying tells whether another follows
+	Retrying bool
+}
+
+// ResolveDate turns a GA4 date ("YYYY-MM-DD", "today", "yesterday" or
+// "NdaysAgo") into a calendar date in loc
+func ResolveDate(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	switch value {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if match := daysAgoPattern.FindStringSubmatch(value); match != nil {
+		days, _ := strconv.Atoi(match[1])
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	date, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' (use YYYY-MM-DD, today, yesterday or NdaysAgo)", value)
+	}
+	return date, nil
+}
+
+// SplitDateRange splits a date range into calendar months or Monday-to-Sunday
+// weeks. The first and last chunks are trimmed to the range.
+func SplitDateRange(startDate, endDate, by string, now time.Time, loc *time.Location) ([]DateChunk, error) {
+	start, err := ResolveDate(startDate, now, loc)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ResolveDate(endDate, now, loc)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end.Format(dateLayout), start.Format(dateLayout))
+	}
+
+	var chunks []DateChunk
+	for chunkStart := start; !chunkStart.After(end); {
+		var next time.Time
+		switch by {
+		case ChunkByMonth:
+			next = time.Date(chunkStart.Year(), chunkStart.Month()+1, 1, 0, 0, 0, 0, loc)
+		case ChunkByWeek:
+			// Days until the following Monday
+			next = chunkStart.AddDate(0, 0, 7-(int(chunkStart.Weekday())+6)%7)
+		default:
+			return nil, fmt.Errorf("invalid chunk period '%s' (must be '%s' or '%s')", by, ChunkByMonth, ChunkByWeek)
+		}
+
+		chunkEnd := next.AddDate(0, 0, -1)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		chunks = append(chunks, DateChunk{StartDate: chunkStart.Format(dateLayout), EndDate: chunkEnd.Format(dateLayout)})
+		chunkStart = next
+	}
+
+	return chunks, nil
+}
+
+// ExecuteChunked runs the query once per chunk and stitches the rows into one
+// result with a leading dateRange dimension. Each chunk gets the query's full
+// row limit, and failed chunks are retried on quota, server and network
+// errors. Totals, minimums and maximums cannot be stitched and are dropped.
+func (e *Executor) ExecuteChunked(ctx context.Context, config *QueryConfig, chunks []DateChunk, progress func(ChunkProgress)) (*QueryResult, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no date chunks to run")
+	}
+	for _, dimension := range config.Dimensions {
+		if dimension == ChunkDimension {
+			return nil, fmt.Errorf("chunked queries add the '%s' dimension themselves; remove it from the query", ChunkDimension)
+		}
+	}
+	// Chunks inherit the currency, so it is looked up once
+	config = e.withPropertyDefaults(ctx, config)
+	// Validate once up front so failures inside the loop come from the API
+	if err := e.validateQuery(config); err != nil {
+		return nil, fmt.Errorf("query validation failed: %w", err)
+	}
+
+	request, err := e.configToRequest(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert query config to API request: %w", err)
+	}
+	startTime := time.Now()
+	stitched := &QueryResult{
+		QueryID:     e.generateQueryID(ctx, nil, request, ""),
+		PropertyID:  config.PropertyID,
+		QueryHash:   e.generateQueryHash(config),
+		QueryConfig: config,
+		ExecutedAt:  startTime,
+		FromCache:   true,
+	}
+
+	for i, chunk := range chunks {
+		chunkConfig := *config
+		chunkConfig.StartDate = chunk.StartDate
+		chunkConfig.EndDate = chunk.EndDate
+
+		var result *QueryResult
+		var err error
+		for attempt := 1; attempt <= maxChunkAttempts; attempt++ {
+			result, err = e.Execute(ctx, &chunkConfig)
+			report := ChunkProgress{Index: i, Total: len(chunks), Chunk: chunk, Attempt: attempt, Err: err}
+			if err == nil {
+				report.Rows = len(result.Rows)
+				report.RowCount = result.RowCount
+				report.Sampled = result.ResponseMetadata != nil && len(result.ResponseMetadata.SamplingMetadatas) > 0
+			} else {
+				report.Retrying = attempt < maxChunkAttempts && isRetryableError(ctx, err)
+			}
+			if progress != nil {
+				progress(report)
+			}
+			if err == nil || !report.Retrying {
+				break
+			}
+
+			select {
+			case <-time.After(chunkRetryBackoff * time.Duration(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chunk %s failed: %w", chunk.Label(), err)
+		}
+
+		if i == 0 {
+			stitched.DimensionHeaders = append([]api.DimensionHeader{{Name: ChunkDimension}}, result.DimensionHeaders...)
+			stitched.MetricHeaders = result.MetricHeaders
+			stitched.ResponseMetadata = result.ResponseMetadata
+		}
+		for _, row := range result.Rows {
+			row.DimensionValues = append([]api.DimensionValue{{Value: chunk.Label()}}, row.DimensionValues...)
+			stitched.Rows = append(stitched.Rows, row)
+		}
+		stitched.RowCount += result.RowCount
+		stitched.FromCache = stitched.FromCache && result.FromCache
+		if result.CachedAt != nil && (stitched.CachedAt == nil || result.CachedAt.Before(*stitched.CachedAt)) {
+			stitched.CachedAt = result.CachedAt
+		}
+		if result.PropertyQuota != nil {
+			stitched.PropertyQuota = result.PropertyQuota
+		}
+	}
+
+	stitched.ExecutionTime = time.Since(startTime).String()
+	return stitched, nil
+}
+
+// StoreChunked saves a stitched result to the query cache so 'results show'
+// and 'results export' can find it by ID. It is stored under its own hash with
+// the dateRange dimension in the request, so a later unchunked run of the same
+// query never picks it up.
+func (e *Executor) StoreChunked(ctx context.Context, store api.CacheInterface, result *QueryResult, by string) error {
+	request, err := e.configToRequest(result.QueryConfig)
+	if err != nil {
+		return err
+	}
+	request.Dimensions = append([]api.Dimension{{Name: ChunkDimension}}, request.Dimensions...)
+
+	data, _ := json.Marshal(struct {
+		ChunkBy string                `json:"chunk_by"`
+		Request *api.RunReportRequest `json:"request"`
+	}{by, request})
+	queryHash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	response := api.RunReportResponse{
+		DimensionHeaders: result.DimensionHeaders,
+		MetricHeaders:    result.MetricHeaders,
+		Rows:             result.Rows,
+		RowCount:         result.RowCount,
+		PropertyQuota:    result.PropertyQuota,
+	}
+	if result.ResponseMetadata != nil {
+		response.Metadata = *result.ResponseMetadata
+	}
+
+	queryID := e.generateQueryID(ctx, store, request, by)
+	ttl := derivedResultTTLHours
+	if err := store.CacheQuery(ctx, queryID, result.PropertyID, queryHash, request, response, result.RowCount, &ttl); err != nil {
+		return err
+	}
+
+	result.QueryID = queryID
+	result.QueryHash = queryHash
+	return nil
+}
+
+// isRetryableError reports whether a failed request may succeed if repeated:
+// quota exhaustion, server errors and network failures, but not rejected
+// requests or a cancelled command
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.IsInvalidArgument() {
+			return false
+		}
+		switch apiErr.Status {
+		case "RESOURCE_EXHAUSTED", "UNAVAILABLE", "INTERNAL", "DEADLINE_EXCEEDED":
+			return true
+		}
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	// Anything else failed before GA4 answered, e.g. a dropped connection
+	return true
+}
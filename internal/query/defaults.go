@@ -0,0 +1,38 @@
+package query
+
+import (
+	"context"
+
+	"ga4admin/internal/config"
+)
+
+// PropertySource looks up the property a query runs against
+type PropertySource interface {
+	Property(ctx context.Context, propertyID string) (*config.Property, error)
+}
+
+// SetPropertySource makes queries without a currency_code request their
+// property's currency explicitly. GA4 would use it anyway, but this way the
+// request, its cache key and the stored result record which currency revenue
+// is in, and results cached before the property's currency changed are not
+// served for it.
+func (e *Executor) SetPropertySource(source PropertySource) {
+	e.properties = source
+}
+
+// withPropertyDefaults returns config with its property's currency filled
+// in. If the property can't be looked up, config is returned unchanged and
+// GA4 applies its own default.
+func (e *Executor) withPropertyDefaults(ctx context.Context, config *QueryConfig) *QueryConfig {
+	if e.properties == nil || config.CurrencyCode != "" || config.PropertyID == "" {
+		return config
+	}
+	property, err := e.properties.Property(ctx, config.PropertyID)
+	if err != nil || property == nil || property.CurrencyCode == "" {
+		return config
+	}
+
+	withDefaults := *config
+	withDefaults.CurrencyCode = property.CurrencyCode
+	return &withDefaults
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -13,11 +14,13 @@ import (
 
 // Executor handles GA4 query execution with caching and result management
 type Executor struct {
-	dataClient *api.DataClient
+	dataClient api.DataService
+	recorder   StatsRecorder  // Optional; logs executions for 'query stats'
+	properties PropertySource // Optional; fills in queries' currency
 }
 
 // NewExecutor creates a new query executor
-func NewExecutor(dataClient *api.DataClient) *Executor {
+func NewExecutor(dataClient api.DataService) *Executor {
 	return &Executor{
 		dataClient: dataClient,
 	}
@@ -26,6 +29,7 @@ func NewExecutor(dataClient *api.DataClient) *Executor {
 // Execute runs a query configuration and returns results
 func (e *Executor) Execute(ctx context.Context, config *QueryConfig) (*QueryResult, error) {
 	startTime := time.Now()
+	config = e.withPropertyDefaults(ctx, config)
 
 	// Validate query configuration
 	if err := e.validateQuery(config); err != nil {
@@ -41,8 +45,9 @@ func (e *Executor) Execute(ctx context.Context, config *QueryConfig) (*QueryResu
 	// Execute the query
 	response, err := e.dataClient.RunReport(ctx, request)
 	if err != nil {
+		e.recordExecution(ctx, config, startTime, nil, err)
 		return &QueryResult{
-			QueryID:       e.generateQueryID(config),
+			QueryID:       e.generateQueryID(ctx, nil, request, ""),
 			PropertyID:    config.PropertyID,
 			QueryHash:     e.generateQueryHash(config),
 			QueryConfig:   config,
@@ -52,9 +57,16 @@ func (e *Executor) Execute(ctx context.Context, config *QueryConfig) (*QueryResu
 		}, err
 	}
 
+	// Results served from or stored in the cache keep the cache's query ID so
+	// they can be found again with 'results show'
+	queryID := response.QueryID
+	if queryID == "" {
+		queryID = e.generateQueryID(ctx, nil, request, "")
+	}
+
 	// Build result object
 	result := &QueryResult{
-		QueryID:          e.generateQueryID(config),
+		QueryID:          queryID,
 		PropertyID:       config.PropertyID,
 		QueryHash:        e.generateQueryHash(config),
 		QueryConfig:      config,
@@ -69,8 +81,14 @@ func (e *Executor) Execute(ctx context.Context, config *QueryConfig) (*QueryResu
 		Minimums:         response.Minimums,
 		ResponseMetadata: &response.Metadata,
 		PropertyQuota:    response.PropertyQuota,
+		FromCache:        response.FromCache,
+	}
+	if response.FromCache && !response.CachedAt.IsZero() {
+		cachedAt := response.CachedAt
+		result.CachedAt = &cachedAt
 	}
 
+	e.recordExecution(ctx, config, startTime, response, nil)
 	return result, nil
 }
 
@@ -92,8 +110,48 @@ func (e *Executor) ExecuteTemplate(ctx context.Context, template *QueryTemplate,
 	return e.Execute(ctx, &config)
 }
 
-// validateQuery performs comprehensive query validation
+// Plan shows how a query would be answered - from cache, assembled from
+// results cached for other date ranges, or from the API - without running it
+func (e *Executor) Plan(ctx context.Context, config *QueryConfig) (*api.ReportPlan, error) {
+	planner, ok := e.dataClient.(api.ReportPlanner)
+	if !ok {
+		return nil, fmt.Errorf("query plans are not supported by this data client")
+	}
+
+	// Plan the request Execute would send
+	request, err := e.Request(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return planner.PlanReport(ctx, request)
+}
+
+// Request returns the API request Execute would send for a query
+func (e *Executor) Request(ctx context.Context, config *QueryConfig) (*api.RunReportRequest, error) {
+	config = e.withPropertyDefaults(ctx, config)
+	if err := e.validateQuery(config); err != nil {
+		return nil, fmt.Errorf("query validation failed: %w", err)
+	}
+
+	request, err := e.configToRequest(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert query config to API request: %w", err)
+	}
+	return request, nil
+}
+
+// validateQuery performs comprehensive query validation. Its errors match
+// ErrInvalidQuery.
 func (e *Executor) validateQuery(config *QueryConfig) error {
+	if err := e.checkQuery(config); err != nil {
+		return invalidQueryError{err}
+	}
+	return nil
+}
+
+// checkQuery runs the checks behind validateQuery
+func (e *Executor) checkQuery(config *QueryConfig) error {
 	// Required fields
 	if config.PropertyID == "" {
 		return fmt.Errorf("property ID is required")
@@ -101,10 +159,25 @@ func (e *Executor) validateQuery(config *QueryConfig) error {
 	if config.StartDate == "" || config.EndDate == "" {
 		return fmt.Errorf("date range is required (start_date and end_date)")
 	}
-	if len(config.Dimensions) == 0 && len(config.Metrics) == 0 {
+	if len(config.Dimensions) == 0 && len(config.MetricNames()) == 0 {
 		return fmt.Errorf("at least one dimension or metric is required")
 	}
 
+	// Item-scoped and event-scoped fields can't be mixed
+	if err := ValidateFieldScopes(config); err != nil {
+		return err
+	}
+
+	// Validate calculated metrics
+	for _, calculated := range config.CalculatedMetrics {
+		if err := ValidateCalculatedMetric(calculated); err != nil {
+			return fmt.Errorf("calculated metric '%s' is invalid: %w", calculated.Name, err)
+		}
+		if contains(config.Metrics, calculated.Name) {
+			return fmt.Errorf("calculated metric '%s' conflicts with a requested metric", calculated.Name)
+		}
+	}
+
 	// Limit validation
 	if config.Limit > 250000 {
 		return fmt.Errorf("limit cannot exceed 250,000 rows")
@@ -118,6 +191,13 @@ func (e *Executor) validateQuery(config *QueryConfig) error {
 		return fmt.Errorf("offset cannot be negative")
 	}
 
+	// Validate metric aggregations
+	for _, aggregation := range config.MetricAggregations {
+		if !IsValidMetricAggregation(aggregation) {
+			return fmt.Errorf("invalid metric aggregation: %s", aggregation)
+		}
+	}
+
 	// Validate filter configurations
 	for i, filter := range config.Filters {
 		if err := e.validateFilter(&filter); err != nil {
@@ -135,6 +215,16 @@ func (e *Executor) validateQuery(config *QueryConfig) error {
 	return nil
 }
 
+// Values accepted in query configs, shared by validation and the query file
+// schema
+var (
+	FilterTypes         = []string{"string", "numeric", "between", "in_list"}
+	StringMatchTypes    = []string{"EXACT", "CONTAINS", "STARTS_WITH", "BEGINS_WITH", "ENDS_WITH", "REGEX", "FULL_REGEXP", "PARTIAL_REGEXP"}
+	NumericOperations   = []string{"EQUAL", "GREATER_THAN", "LESS_THAN", "GREATER_THAN_OR_EQUAL", "LESS_THAN_OR_EQUAL"}
+	DimensionOrderTypes = []string{"ALPHANUMERIC", "CASE_INSENSITIVE_ALPHANUMERIC", "NUMERIC"}
+	MetricAggregations  = []string{"TOTAL", "MINIMUM", "MAXIMUM", "COUNT"}
+)
+
 // validateFilter validates individual filter configuration
 func (e *Executor) validateFilter(filter *FilterConfig) error {
 	if filter.FieldName == "" {
@@ -151,8 +241,7 @@ func (e *Executor) validateFilter(filter *FilterConfig) error {
 		}
 		
 		// Validate match type
-		validMatchTypes := []string{"EXACT", "CONTAINS", "STARTS_WITH", "ENDS_WITH", "REGEX"}
-		if !contains(validMatchTypes, filter.StringMatchType) {
+		if !contains(StringMatchTypes, filter.StringMatchType) {
 			return fmt.Errorf("invalid string match type: %s", filter.StringMatchType)
 		}
 
@@ -162,8 +251,7 @@ func (e *Executor) validateFilter(filter *FilterConfig) error {
 		}
 		
 		// Validate operation
-		validOperations := []string{"EQUAL", "GREATER_THAN", "LESS_THAN", "GREATER_THAN_OR_EQUAL", "LESS_THAN_OR_EQUAL"}
-		if !contains(validOperations, filter.NumericOperation) {
+		if !contains(NumericOperations, filter.NumericOperation) {
 			return fmt.Errorf("invalid numeric operation: %s", filter.NumericOperation)
 		}
 
@@ -185,9 +273,22 @@ func (e *Executor) validateFilter(filter *FilterConfig) error {
 		}
 	}
 
+	// Filters are always combined with AND; NOT negates this filter
+	switch filter.LogicOperator {
+	case "", "AND", "NOT":
+	default:
+		return fmt.Errorf("invalid logic operator: %s (filters combine with AND; use NOT to negate one)", filter.LogicOperator)
+	}
+
 	return nil
 }
 
+// ga4MatchTypes maps the shorter match type names to GA4's
+var ga4MatchTypes = map[string]string{
+	"STARTS_WITH": "BEGINS_WITH",
+	"REGEX":       "FULL_REGEXP",
+}
+
 // validateOrderBy validates order by configuration
 func (e *Executor) validateOrderBy(orderBy *OrderByConfig, config *QueryConfig) error {
 	if orderBy.FieldName == "" {
@@ -203,14 +304,13 @@ func (e *Executor) validateOrderBy(orderBy *OrderByConfig, config *QueryConfig)
 		
 		// Validate order type for dimensions
 		if orderBy.OrderType != "" {
-			validTypes := []string{"ALPHANUMERIC", "CASE_INSENSITIVE_ALPHANUMERIC", "NUMERIC"}
-			if !contains(validTypes, orderBy.OrderType) {
+			if !contains(DimensionOrderTypes, orderBy.OrderType) {
 				return fmt.Errorf("invalid order type for dimension: %s", orderBy.OrderType)
 			}
 		}
 
 	case "metric":
-		if !contains(config.Metrics, orderBy.FieldName) {
+		if !contains(config.MetricNames(), orderBy.FieldName) {
 			return fmt.Errorf("metric '%s' not found in query metrics", orderBy.FieldName)
 		}
 
@@ -218,7 +318,7 @@ func (e *Executor) validateOrderBy(orderBy *OrderByConfig, config *QueryConfig)
 		// Try to determine field type automatically
 		if contains(config.Dimensions, orderBy.FieldName) {
 			orderBy.FieldType = "dimension"
-		} else if contains(config.Metrics, orderBy.FieldName) {
+		} else if contains(config.MetricNames(), orderBy.FieldName) {
 			orderBy.FieldType = "metric"
 		} else {
 			return fmt.Errorf("field '%s' not found in dimensions or metrics", orderBy.FieldName)
@@ -255,18 +355,21 @@ func (e *Executor) configToRequest(config *QueryConfig) (*api.RunReportRequest,
 	for _, metricName := range config.Metrics {
 		request.Metrics = append(request.Metrics, api.Metric{Name: metricName})
 	}
+	for _, calculated := range config.CalculatedMetrics {
+		request.Metrics = append(request.Metrics, api.Metric{
+			Name:       calculated.Name,
+			Expression: calculated.Expression,
+		})
+	}
 
 	// Convert filters
 	if len(config.Filters) > 0 {
-		filterExpr, err := e.convertFilters(config.Filters)
+		dimensionFilter, metricFilter, err := e.splitFilters(config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert filters: %w", err)
 		}
-		
-		// Apply dimension filters vs metric filters based on field type
-		// For now, assume all filters are dimension filters
-		// TODO: Add logic to determine if field is dimension or metric
-		request.DimensionFilter = filterExpr
+		request.DimensionFilter = dimensionFilter
+		request.MetricFilter = metricFilter
 	}
 
 	// Convert order by
@@ -292,6 +395,32 @@ func (e *Executor) configToRequest(config *QueryConfig) (*api.RunReportRequest,
 	return request, nil
 }
 
+// splitFilters converts the query's filters into GA4's dimension and metric
+// filters. Filters on the query's metrics, calculated ones included, apply
+// to the metric filter; GA4 rejects them in the dimension filter.
+func (e *Executor) splitFilters(config *QueryConfig) (*api.FilterExpression, *api.FilterExpression, error) {
+	metrics := config.MetricNames()
+
+	var dimensionFilters, metricFilters []FilterConfig
+	for _, filter := range config.Filters {
+		if contains(metrics, filter.FieldName) {
+			metricFilters = append(metricFilters, filter)
+		} else {
+			dimensionFilters = append(dimensionFilters, filter)
+		}
+	}
+
+	dimensionFilter, err := e.convertFilters(dimensionFilters)
+	if err != nil {
+		return nil, nil, err
+	}
+	metricFilter, err := e.convertFilters(metricFilters)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dimensionFilter, metricFilter, nil
+}
+
 // convertFilters converts filter configurations to GA4 API filter expressions
 func (e *Executor) convertFilters(filters []FilterConfig) (*api.FilterExpression, error) {
 	if len(filters) == 0 {
@@ -329,8 +458,12 @@ func (e *Executor) convertSingleFilter(filter FilterConfig) (*api.FilterExpressi
 
 	switch filter.Type {
 	case "string":
+		matchType := filter.StringMatchType
+		if ga4Name, ok := ga4MatchTypes[matchType]; ok {
+			matchType = ga4Name
+		}
 		apiFilter.StringFilter = &api.StringFilter{
-			MatchType:     filter.StringMatchType,
+			MatchType:     matchType,
 			Value:         filter.StringValue,
 			CaseSensitive: filter.StringCaseSensitive,
 		}
@@ -381,6 +514,11 @@ func (e *Executor) convertSingleFilter(filter FilterConfig) (*api.FilterExpressi
 		return nil, fmt.Errorf("unsupported filter type: %s", filter.Type)
 	}
 
+	if filter.LogicOperator == "NOT" {
+		return &api.FilterExpression{
+			NotExpression: &api.FilterExpression{Filter: apiFilter},
+		}, nil
+	}
 	return &api.FilterExpression{
 		Filter: apiFilter,
 	}, nil
@@ -390,6 +528,10 @@ func (e *Executor) convertSingleFilter(filter FilterConfig) (*api.FilterExpressi
 func (e *Executor) applyOverrides(config *QueryConfig, overrides map[string]interface{}) error {
 	for key, value := range overrides {
 		switch key {
+		case "property_id":
+			if str, ok := value.(string); ok {
+				config.PropertyID = str
+			}
 		case "start_date":
 			if str, ok := value.(string); ok {
 				config.StartDate = str
@@ -415,19 +557,73 @@ func (e *Executor) applyOverrides(config *QueryConfig, overrides map[string]inte
 	return nil
 }
 
-// generateQueryID creates a unique identifier for a query
-func (e *Executor) generateQueryID(config *QueryConfig) string {
-	return fmt.Sprintf("query_%d", time.Now().Unix())
+// generateQueryID creates a readable, unique identifier for a query's result,
+// not yet used in store when it isn't nil
+func (e *Executor) generateQueryID(ctx context.Context, store api.CacheInterface, request *api.RunReportRequest, variant string) string {
+	presetName := ""
+	if named, ok := e.dataClient.(interface{ PresetName() string }); ok {
+		presetName = named.PresetName()
+	}
+	return api.NewQueryID(ctx, store, presetName, request, variant)
 }
 
-// generateQueryHash creates a hash for caching purposes
+// generateQueryHash creates a hash for caching purposes. Configs that ask for
+// the same data share it: fields, aggregations and filters are sorted, dates
+// normalized and names, descriptions and timestamps left out. Without an
+// explicit ordering GA4 sorts by the first metric, so it stays part of it.
 func (e *Executor) generateQueryHash(config *QueryConfig) string {
-	// Create a deterministic representation for hashing
-	data, _ := json.Marshal(config)
+	canonical := QueryConfig{
+		PropertyID:         config.PropertyID,
+		Dimensions:         sortedStrings(config.Dimensions),
+		Metrics:            sortedStrings(config.Metrics),
+		StartDate:          api.NormalizeReportDate(config.StartDate),
+		EndDate:            api.NormalizeReportDate(config.EndDate),
+		Limit:              config.Limit,
+		Offset:             config.Offset,
+		KeepEmptyRows:      config.KeepEmptyRows,
+		MetricAggregations: sortedStrings(config.MetricAggregations),
+		CurrencyCode:       config.CurrencyCode,
+		OrderBy:            config.OrderBy,
+	}
+	canonical.CalculatedMetrics = append(canonical.CalculatedMetrics, config.CalculatedMetrics...)
+	sort.SliceStable(canonical.CalculatedMetrics, func(i, j int) bool {
+		return canonical.CalculatedMetrics[i].Name < canonical.CalculatedMetrics[j].Name
+	})
+	filterKeys := make([]string, 0, len(config.Filters))
+	for _, filter := range config.Filters {
+		filter.InListValues = sortedStrings(filter.InListValues)
+		key, _ := json.Marshal(filter)
+		filterKeys = append(filterKeys, string(key))
+	}
+	sort.Strings(filterKeys)
+
+	key := struct {
+		Config       QueryConfig `json:"config"`
+		Filters      []string    `json:"filters,omitempty"`
+		DefaultOrder string      `json:"default_order,omitempty"`
+	}{Config: canonical, Filters: filterKeys}
+	if names := config.MetricNames(); len(config.OrderBy) == 0 && len(names) > 0 {
+		key.DefaultOrder = names[0]
+	}
+	data, _ := json.Marshal(&key)
 	hash := sha256.Sum256(data)
 	return fmt.Sprintf("%x", hash)
 }
 
+func sortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// IsValidMetricAggregation reports whether GA4 accepts the metric aggregation
+func IsValidMetricAggregation(aggregation string) bool {
+	return contains(MetricAggregations, aggregation)
+}
+
 // Helper function to check if slice contains string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Calculated metric column names: letters, digits and underscores
+	metricNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+	// Operands in an expression: metric names (including custom ones) or numeric literals
+	expressionTokenPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_:]*|[0-9]+(\.[0-9]+)?|[-+*/()]|\s+`)
+)
+
+// ParseCalculatedMetric parses a "name=expression" pair such as
+// "eventsPerSession=eventCount/sessions"
+func ParseCalculatedMetric(s string) (CalculatedMetric, error) {
+	name, expression, found := strings.Cut(s, "=")
+	if !found {
+		return CalculatedMetric{}, fmt.Errorf("calculated metric must have format 'name=expression', got: %s", s)
+	}
+
+	calculated := CalculatedMetric{
+		Name:       strings.TrimSpace(name),
+		Expression: strings.TrimSpace(expression),
+	}
+	if err := ValidateCalculatedMetric(calculated); err != nil {
+		return CalculatedMetric{}, fmt.Errorf("calculated metric '%s' is invalid: %w", calculated.Name, err)
+	}
+
+	return calculated, nil
+}
+
+// ValidateCalculatedMetric performs a local syntax check so obvious mistakes are
+// caught before spending API quota. GA4 still validates the referenced metrics.
+func ValidateCalculatedMetric(calculated CalculatedMetric) error {
+	if calculated.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !metricNamePattern.MatchString(calculated.Name) {
+		return fmt.Errorf("name may only contain letters, digits and underscores")
+	}
+	if calculated.Expression == "" {
+		return fmt.Errorf("expression is required")
+	}
+
+	// Every character must belong to a known token
+	if leftover := expressionTokenPattern.ReplaceAllString(calculated.Expression, ""); leftover != "" {
+		return fmt.Errorf("unsupported characters in expression: %q", leftover)
+	}
+
+	// Parentheses must balance and operators must sit between operands
+	depth := 0
+	expectOperand := true
+	for _, token := range expressionTokenPattern.FindAllString(calculated.Expression, -1) {
+		switch {
+		case strings.TrimSpace(token) == "":
+			continue
+		case token == "(":
+			if !expectOperand {
+				return fmt.Errorf("missing operator before '('")
+			}
+			depth++
+		case token == ")":
+			if expectOperand {
+				return fmt.Errorf("missing operand before ')'")
+			}
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses")
+			}
+		case strings.ContainsAny(token, "+-*/"):
+			if expectOperand {
+				return fmt.Errorf("missing operand before '%s'", token)
+			}
+			expectOperand = true
+		default:
+			if !expectOperand {
+				return fmt.Errorf("missing operator before '%s'", token)
+			}
+			expectOperand = false
+		}
+	}
+
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses")
+	}
+	if expectOperand {
+		return fmt.Errorf("expression ends with an operator")
+	}
+
+	return nil
+}
+
+// expressionOperands returns the metric names referenced by an expression
+func expressionOperands(expression string) []string {
+	var operands []string
+	for _, token := range expressionTokenPattern.FindAllString(expression, -1) {
+		if metricNamePattern.MatchString(token) || strings.Contains(token, ":") {
+			operands = append(operands, token)
+		}
+	}
+	return operands
+}
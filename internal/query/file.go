@@ -0,0 +1,63 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadQueryFile reads a query configuration from a YAML or JSON file
+func LoadQueryFile(path string) (*QueryConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %w", err)
+	}
+
+	var config QueryConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse query file: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse query file: %w", err)
+		}
+	}
+
+	return &config, nil
+}
+
+// LoadQueryFileStrict reads a query file like LoadQueryFile but rejects keys
+// that aren't part of the query schema, such as misspelled field names that
+// LoadQueryFile would silently ignore
+func LoadQueryFileStrict(path string) (*QueryConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %w", err)
+	}
+
+	var config QueryConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse query file: %w", err)
+		}
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse query file: %w", err)
+		}
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,275 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/config"
+)
+
+// GA4 core reporting quotas in tokens. ga4admin sends every request through
+// one OAuth client, so the per-project hourly limit is the hourly one that
+// binds.
+const (
+	StandardTokensPerDay                = 200000
+	StandardTokensPerProjectPerHour     = 14000
+	Analytics360TokensPerDay            = 2000000
+	Analytics360TokensPerProjectPerHour = 140000
+)
+
+// defaultTokensPerQuery is assumed for planned queries when the query log has
+// no token counts for the property yet
+const defaultTokensPerQuery = 10
+
+// scheduleCacheWindow matches the Data API client's query cache TTL: an
+// identical query repeated within it is answered from the cache
+const scheduleCacheWindow = time.Hour
+
+// QuotaWarnShare is the share of a limit above which a forecast warns and makes
+// suggestions
+const QuotaWarnShare = 0.8
+
+// manyFields is the number of dimensions and metrics above which a planned
+// query is suggested for trimming
+const manyFields = 6
+
+// Bases for a planned query's token estimate, most to least reliable
+const (
+	BasisSameFields    = "same fields"
+	BasisFieldCount    = "same field count"
+	BasisPropertyAvg   = "property average"
+	BasisDefaultTokens = "default"
+)
+
+// QuotaLimits are the token limits a forecast is checked against
+type QuotaLimits struct {
+	TokensPerDay  int
+	TokensPerHour int
+}
+
+// QuotaLimitsFor returns the limits of a standard or Analytics 360 property
+func QuotaLimitsFor(analytics360 bool) QuotaLimits {
+	if analytics360 {
+		return QuotaLimits{TokensPerDay: Analytics360TokensPerDay, TokensPerHour: Analytics360TokensPerProjectPerHour}
+	}
+	return QuotaLimits{TokensPerDay: StandardTokensPerDay, TokensPerHour: StandardTokensPerProjectPerHour}
+}
+
+// SchedulePlan lists queries planned to run on a schedule, for 'quota forecast'
+type SchedulePlan struct {
+	Queries []ScheduledQuery `yaml:"queries"`
+}
+
+// ScheduledQuery is one planned query. Template is a built-in report name or
+// a query file path relative to the plan file; the caller resolves it into
+// Config.
+type ScheduledQuery struct {
+	Name     string        `yaml:"name,omitempty"` // Defaults to the template
+	Template string        `yaml:"template"`
+	Every    string        `yaml:"every"`              // Go duration, e.g. "1h" or "24h"
+	NoCache  bool          `yaml:"no_cache,omitempty"` // Runs with 'query run --no-cache'
+	Interval time.Duration `yaml:"-"`
+	Config   *QueryConfig  `yaml:"-"`
+}
+
+// LoadSchedulePlan reads a schedule plan and parses each query's interval
+func LoadSchedulePlan(path string) (*SchedulePlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan SchedulePlan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if len(plan.Queries) == 0 {
+		return nil, fmt.Errorf("plan file lists no queries")
+	}
+
+	for i := range plan.Queries {
+		scheduled := &plan.Queries[i]
+		if scheduled.Template == "" {
+			return nil, fmt.Errorf("query %d: template is required", i+1)
+		}
+		if scheduled.Name == "" {
+			scheduled.Name = scheduled.Template
+		}
+		if scheduled.Interval, err = time.ParseDuration(scheduled.Every); err != nil {
+			return nil, fmt.Errorf("query '%s': invalid every '%s' (use e.g. 30m, 1h or 24h)", scheduled.Name, scheduled.Every)
+		}
+		if scheduled.Interval < time.Minute {
+			return nil, fmt.Errorf("query '%s': every must be at least 1m", scheduled.Name)
+		}
+	}
+	return &plan, nil
+}
+
+// PlannedQueryForecast is the expected token use of one scheduled query
+type PlannedQueryForecast struct {
+	Name         string
+	Fields       int     // Dimensions plus metrics
+	TokensPerRun float64 // Average tokens per API call
+	Basis        string  // Where TokensPerRun came from, e.g. BasisSameFields
+	CallsPerDay  int     // API calls, after runs answered from the cache
+	CallsPerHour int
+	DailyTokens  float64
+	HourlyTokens float64
+}
+
+// QuotaForecast compares past and planned token use with a property's limits
+type QuotaForecast struct {
+	PropertyID       string
+	Days             int // Length of the history window
+	Limits           QuotaLimits
+	LoggedCalls      int     // API calls with a token count in the window
+	BaselineDaily    float64 // Average tokens per day in the window
+	BaselinePeakHour int     // Most tokens used within one clock hour
+	Planned          []PlannedQueryForecast
+	PlannedDaily     float64
+	PlannedPeakHour  float64
+	Suggestions      []string
+}
+
+// DailyTotal is the expected tokens per day, history plus plan
+func (f *QuotaForecast) DailyTotal() float64 {
+	return f.BaselineDaily + f.PlannedDaily
+}
+
+// HourlyTotal is the expected tokens in the busiest hour, assuming the past
+// peak and every planned query land in the same hour
+func (f *QuotaForecast) HourlyTotal() float64 {
+	return float64(f.BaselinePeakHour) + f.PlannedPeakHour
+}
+
+// ForecastQuota estimates a property's token use from its query log over the
+// last days and the planned queries. Planned queries are priced from logged
+// calls with the same dimensions and metrics, else the same number of fields,
+// else the property average.
+func ForecastQuota(propertyID string, entries []config.QueryLogEntry, days int, planned []ScheduledQuery, limits QuotaLimits) *QuotaForecast {
+	forecast := &QuotaForecast{PropertyID: propertyID, Days: days, Limits: limits}
+
+	bySignature := make(map[string][]int)
+	byFieldCount := make(map[int][]int)
+	var all []int
+	hours := make(map[time.Time]int)
+	var total int
+	for _, entry := range entries {
+		if entry.FromCache || entry.Error != "" || entry.TokensConsumed <= 0 {
+			continue
+		}
+		forecast.LoggedCalls++
+		total += entry.TokensConsumed
+		hours[entry.ExecutedAt.Truncate(time.Hour)] += entry.TokensConsumed
+
+		signature := fieldSignature(entry.Dimensions, entry.Metrics)
+		bySignature[signature] = append(bySignature[signature], entry.TokensConsumed)
+		count := len(entry.Dimensions) + len(entry.Metrics)
+		byFieldCount[count] = append(byFieldCount[count], entry.TokensConsumed)
+		all = append(all, entry.TokensConsumed)
+	}
+	if days > 0 {
+		forecast.BaselineDaily = float64(total) / float64(days)
+	}
+	for _, tokens := range hours {
+		if tokens > forecast.BaselinePeakHour {
+			forecast.BaselinePeakHour = tokens
+		}
+	}
+
+	for _, scheduled := range planned {
+		item := PlannedQueryForecast{Name: scheduled.Name}
+		var dimensions, metrics []string
+		if scheduled.Config != nil {
+			dimensions, metrics = scheduled.Config.Dimensions, scheduled.Config.Metrics
+		}
+		item.Fields = len(dimensions) + len(metrics)
+
+		switch {
+		case len(bySignature[fieldSignature(dimensions, metrics)]) > 0:
+			item.TokensPerRun, item.Basis = average(bySignature[fieldSignature(dimensions, metrics)]), BasisSameFields
+		case len(byFieldCount[item.Fields]) > 0:
+			item.TokensPerRun, item.Basis = average(byFieldCount[item.Fields]), BasisFieldCount
+		case len(all) > 0:
+			item.TokensPerRun, item.Basis = average(all), BasisPropertyAvg
+		default:
+			item.TokensPerRun, item.Basis = defaultTokensPerQuery, BasisDefaultTokens
+		}
+
+		// Identical runs within the cache window never reach the API
+		interval := scheduled.Interval
+		if !scheduled.NoCache && interval < scheduleCacheWindow {
+			interval = scheduleCacheWindow
+		}
+		item.CallsPerDay = int(math.Ceil(float64(24*time.Hour) / float64(interval)))
+		item.CallsPerHour = int(math.Ceil(float64(time.Hour) / float64(interval)))
+		item.DailyTokens = item.TokensPerRun * float64(item.CallsPerDay)
+		item.HourlyTokens = item.TokensPerRun * float64(item.CallsPerHour)
+
+		forecast.PlannedDaily += item.DailyTokens
+		forecast.PlannedPeakHour += item.HourlyTokens
+		forecast.Planned = append(forecast.Planned, item)
+	}
+
+	forecast.Suggestions = quotaSuggestions(forecast, planned)
+	return forecast
+}
+
+// quotaSuggestions proposes ways to cut token use once the forecast nears a
+// limit, starting with the most expensive planned queries
+func quotaSuggestions(forecast *QuotaForecast, planned []ScheduledQuery) []string {
+	if forecast.DailyTotal() < QuotaWarnShare*float64(forecast.Limits.TokensPerDay) &&
+		forecast.HourlyTotal() < QuotaWarnShare*float64(forecast.Limits.TokensPerHour) {
+		return nil
+	}
+
+	order := make([]int, len(forecast.Planned))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return forecast.Planned[order[a]].DailyTokens > forecast.Planned[order[b]].DailyTokens
+	})
+
+	var suggestions []string
+	for _, i := range order {
+		item, scheduled := forecast.Planned[i], planned[i]
+		if scheduled.NoCache && scheduled.Interval < scheduleCacheWindow {
+			cached := item.TokensPerRun * math.Ceil(float64(24*time.Hour)/float64(scheduleCacheWindow))
+			suggestions = append(suggestions, fmt.Sprintf("Drop no_cache from '%s': runs less than 1h apart would come from the cache, saving ~%.0f tokens/day", item.Name, item.DailyTokens-cached))
+		}
+		if item.Fields > manyFields {
+			suggestions = append(suggestions, fmt.Sprintf("Trim '%s' (%d dimensions and metrics): each field adds to the tokens a request costs", item.Name, item.Fields))
+		}
+		if scheduled.Interval < 24*time.Hour && item.DailyTokens > 0 {
+			suggestions = append(suggestions, fmt.Sprintf("Run '%s' less often: every %s costs ~%.0f tokens/day", item.Name, scheduled.Every, item.DailyTokens))
+		}
+	}
+	if forecast.BaselineDaily > forecast.PlannedDaily {
+		suggestions = append(suggestions, "Ad-hoc queries use most of the quota: reuse cached results with 'results show' instead of 'query run --no-cache'")
+	}
+	return suggestions
+}
+
+// fieldSignature identifies a query's fields regardless of their order
+func fieldSignature(dimensions, metrics []string) string {
+	d := append([]string(nil), dimensions...)
+	m := append([]string(nil), metrics...)
+	sort.Strings(d)
+	sort.Strings(m)
+	return strings.Join(d, ",") + "|" + strings.Join(m, ",")
+}
+
+func average(values []int) float64 {
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
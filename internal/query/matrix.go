@@ -0,0 +1,205 @@
+package query
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Matrix file columns. property and template are required; blank dates fall
+// back to the command's defaults and output overrides the filename pattern.
+const (
+	MatrixColumnProperty  = "property"
+	MatrixColumnStartDate = "start_date"
+	MatrixColumnEndDate   = "end_date"
+	MatrixColumnTemplate  = "template"
+	MatrixColumnOutput    = "output"
+)
+
+// unsafeFilenameChars matches characters replaced when values are expanded
+// into output filenames
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// MatrixRow is one query definition from a run-matrix CSV file
+type MatrixRow struct {
+	Line       int // 1-based line in the CSV file, for error messages
+	PropertyID string
+	StartDate  string
+	EndDate    string
+	Template   string
+	Output     string
+}
+
+// MatrixJob is a matrix row resolved into a runnable query
+type MatrixJob struct {
+	Row    MatrixRow
+	Config *QueryConfig
+	Output string
+}
+
+// MatrixResult reports the outcome of one matrix job
+type MatrixResult struct {
+	Job      MatrixJob
+	Result   *QueryResult
+	Output   string // Path the export was written to
+	Duration time.Duration
+	Err      error
+}
+
+// LoadMatrixFile reads a run-matrix CSV file. The first line must be a
+// header naming the columns; column order is free.
+func LoadMatrixFile(path string) ([]MatrixRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read matrix file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("matrix file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse matrix header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case MatrixColumnProperty, MatrixColumnStartDate, MatrixColumnEndDate, MatrixColumnTemplate, MatrixColumnOutput:
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown matrix column '%s' (expected %s, %s, %s, %s, %s)", name,
+				MatrixColumnProperty, MatrixColumnStartDate, MatrixColumnEndDate, MatrixColumnTemplate, MatrixColumnOutput)
+		}
+	}
+	for _, required := range []string{MatrixColumnProperty, MatrixColumnTemplate} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("matrix file is missing the '%s' column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []MatrixRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse matrix file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := MatrixRow{
+			Line:       line,
+			PropertyID: field(record, MatrixColumnProperty),
+			StartDate:  field(record, MatrixColumnStartDate),
+			EndDate:    field(record, MatrixColumnEndDate),
+			Template:   field(record, MatrixColumnTemplate),
+			Output:     field(record, MatrixColumnOutput),
+		}
+		if row.PropertyID == "" {
+			return nil, fmt.Errorf("line %d: property is required", line)
+		}
+		if row.Template == "" {
+			return nil, fmt.Errorf("line %d: template is required", line)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("matrix file has no rows")
+	}
+	return rows, nil
+}
+
+// ExpandOutputPath fills the {property}, {template}, {start_date}, {end_date}
+// and {row} placeholders in pattern. Values are made filename-safe, so a
+// template given as a path contributes only its base name. Other tokens are
+// left for the export writer to fill from the result.
+func ExpandOutputPath(pattern string, row MatrixRow, index int) string {
+	template := row.Template
+	if i := strings.LastIndexAny(template, `/\`); i >= 0 {
+		template = template[i+1:]
+	}
+	if i := strings.LastIndex(template, "."); i > 0 {
+		template = template[:i]
+	}
+
+	safe := func(value string) string {
+		return unsafeFilenameChars.ReplaceAllString(value, "_")
+	}
+	return strings.NewReplacer(
+		"{property}", safe(row.PropertyID),
+		"{template}", safe(template),
+		"{start_date}", safe(row.StartDate),
+		"{end_date}", safe(row.EndDate),
+		"{row}", fmt.Sprintf("%d", index+1),
+	).Replace(pattern)
+}
+
+// ExecuteMatrix runs matrix jobs with at most `concurrency` queries in flight.
+// write is called from the worker goroutine with each successful result and
+// returns the path it wrote, so exports for different jobs are written in
+// parallel. progress is called once per job as it finishes; calls are
+// serialized so callers don't need locking.
+func (e *Executor) ExecuteMatrix(ctx context.Context, jobs []MatrixJob, concurrency int, write func(job MatrixJob, result *QueryResult) (string, error), progress func(MatrixResult)) []MatrixResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]MatrixResult, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job MatrixJob) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = MatrixResult{Job: job, Err: ctx.Err()}
+				return
+			}
+
+			start := time.Now()
+			var output string
+			result, err := e.Execute(ctx, job.Config)
+			if err == nil {
+				output, err = write(job, result)
+			}
+			results[i] = MatrixResult{Job: job, Result: result, Output: output, Duration: time.Since(start), Err: err}
+
+			if progress != nil {
+				progressMu.Lock()
+				progress(results[i])
+				progressMu.Unlock()
+			}
+		}(i, job)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -15,6 +15,9 @@ type QueryConfig struct {
 	Dimensions  []string `json:"dimensions" yaml:"dimensions"`
 	Metrics     []string `json:"metrics" yaml:"metrics"`
 
+	// Calculated metrics derived from other metrics (e.g., eventCount/sessions)
+	CalculatedMetrics []CalculatedMetric `json:"calculated_metrics,omitempty" yaml:"calculated_metrics,omitempty"`
+
 	// Date range
 	StartDate string `json:"start_date" yaml:"start_date"`
 	EndDate   string `json:"end_date" yaml:"end_date"`
@@ -39,6 +42,22 @@ type QueryConfig struct {
 	CreatedBy string    `json:"created_by,omitempty" yaml:"created_by,omitempty"`
 }
 
+// CalculatedMetric is a named GA4 metric expression evaluated server-side
+type CalculatedMetric struct {
+	Name       string `json:"name" yaml:"name"`             // Column name in the result, e.g. "eventsPerSession"
+	Expression string `json:"expression" yaml:"expression"` // e.g. "eventCount/sessions"
+}
+
+// MetricNames returns the names of all metric columns the query produces,
+// including calculated metrics
+func (c *QueryConfig) MetricNames() []string {
+	names := append([]string{}, c.Metrics...)
+	for _, calculated := range c.CalculatedMetrics {
+		names = append(names, calculated.Name)
+	}
+	return names
+}
+
 // FilterConfig represents a single filter in a query
 type FilterConfig struct {
 	FieldName string `json:"field_name" yaml:"field_name"`
@@ -80,12 +99,15 @@ type QueryResult struct {
 	PropertyID   string       `json:"property_id"`
 	QueryHash    string       `json:"query_hash"`
 	QueryConfig  *QueryConfig `json:"query_config"`
+	ViewName     string       `json:"view_name,omitempty"` // Set when read through a saved result view
+	RollupName   string       `json:"rollup_name,omitempty"` // Set when read from a rollup table
 
 	// Execution metadata
-	ExecutedAt    time.Time `json:"executed_at"`
-	ExecutionTime string    `json:"execution_time"`
-	RowCount      int       `json:"row_count"`
-	FromCache     bool      `json:"from_cache"`
+	ExecutedAt    time.Time  `json:"executed_at"`
+	ExecutionTime string     `json:"execution_time"`
+	RowCount      int        `json:"row_count"`
+	FromCache     bool       `json:"from_cache"`
+	CachedAt      *time.Time `json:"cached_at,omitempty"` // When cached results were fetched from the API
 
 	// Result data
 	DimensionHeaders []api.DimensionHeader `json:"dimension_headers"`
@@ -117,14 +139,25 @@ type QueryTemplate struct {
 
 // QueryStats represents statistics about query performance
 type QueryStats struct {
-	PropertyID         string  `json:"property_id"`
-	TotalQueries       int     `json:"total_queries"`
-	CacheHitRate       float64 `json:"cache_hit_rate"`
-	AverageRowCount    float64 `json:"average_row_count"`
-	AverageExecutionMs float64 `json:"average_execution_ms"`
-	PopularDimensions  []string `json:"popular_dimensions"`
-	PopularMetrics     []string `json:"popular_metrics"`
-	LastAnalyzed       time.Time `json:"last_analyzed"`
+	PropertyID         string       `json:"property_id"`
+	TotalQueries       int          `json:"total_queries"`
+	FailedQueries      int          `json:"failed_queries"`
+	CacheHitRate       float64      `json:"cache_hit_rate"`
+	AverageRowCount    float64      `json:"average_row_count"`
+	AverageExecutionMs float64      `json:"average_execution_ms"`
+	P95ExecutionMs     int64        `json:"p95_execution_ms"`
+	MaxExecutionMs     int64        `json:"max_execution_ms"`
+	TotalTokens        int          `json:"total_tokens"` // Quota tokens of API calls
+	PopularDimensions  []FieldUsage `json:"popular_dimensions"`
+	PopularMetrics     []FieldUsage `json:"popular_metrics"`
+	Since              time.Time    `json:"since"`
+	LastAnalyzed       time.Time    `json:"last_analyzed"`
+}
+
+// FieldUsage counts how many queries used a dimension or metric
+type FieldUsage struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
 // FilterExpression represents a complex filter combination
@@ -0,0 +1,355 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Where the published schemas live, so editors can fetch them by $id
+const (
+	schemaBaseURL           = "https://raw.githubusercontent.com/avisekrath/csga4/main/schemas/"
+	QuerySchemaFile         = "query.schema.json"
+	QueryTemplateSchemaFile = "query-template.schema.json"
+)
+
+// Schema is the subset of JSON Schema (draft-07) that describes query files.
+// It is generated from the Go structs, so it can't drift from what the loader
+// accepts.
+type Schema struct {
+	Draft                string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// schemaAnnotation adds what the struct tags can't express to one field,
+// keyed by "<Go type>.<json name>"
+type schemaAnnotation struct {
+	description string
+	enum        []string
+	minimum     *float64
+	maximum     *float64
+	required    bool
+}
+
+func bound(v float64) *float64 { return &v }
+
+var schemaAnnotations = map[string]schemaAnnotation{
+	"QueryConfig.property_id":           {description: "GA4 property ID or alias; 'query run --property' overrides it"},
+	"QueryConfig.name":                  {description: "Short name shown in query listings"},
+	"QueryConfig.description":           {description: "What the query answers"},
+	"QueryConfig.dimensions":            {description: "Dimension API names, e.g. date or sessionSource (at most 9)"},
+	"QueryConfig.metrics":               {description: "Metric API names, e.g. sessions or activeUsers (at most 10, calculated metrics included)"},
+	"QueryConfig.calculated_metrics":    {description: "Metrics computed by GA4 from other metrics"},
+	"QueryConfig.start_date":            {description: "YYYY-MM-DD or relative: today, yesterday, NdaysAgo (default 30daysAgo)"},
+	"QueryConfig.end_date":              {description: "YYYY-MM-DD or relative: today, yesterday, NdaysAgo (default yesterday)"},
+	"QueryConfig.limit":                 {description: "Maximum rows to return (default 10000)", minimum: bound(0), maximum: bound(MaxPageSize)},
+	"QueryConfig.offset":                {description: "Rows to skip", minimum: bound(0)},
+	"QueryConfig.keep_empty_rows":       {description: "Return rows whose metrics are all zero"},
+	"QueryConfig.metric_aggregations":   {description: "Summary rows to return with the data", enum: MetricAggregations},
+	"QueryConfig.currency_code":         {description: "ISO 4217 currency for revenue metrics, e.g. EUR"},
+	"QueryConfig.return_property_quota": {description: "Report the property's quota usage with the result"},
+	"QueryConfig.filters":               {description: "Dimension and metric filters"},
+	"QueryConfig.order_by":              {description: "Sort order of the rows"},
+	"QueryConfig.created_at":            {description: "Set when the query is saved"},
+	"QueryConfig.updated_at":            {description: "Set when the query is saved"},
+	"QueryConfig.created_by":            {description: "Who saved the query"},
+
+	"CalculatedMetric.name":       {description: "Column name in the result, e.g. eventsPerSession", required: true},
+	"CalculatedMetric.expression": {description: "Metric arithmetic, e.g. eventCount/sessions", required: true},
+
+	"FilterConfig.field_name":             {description: "Dimension or metric to filter on", required: true},
+	"FilterConfig.type":                   {description: "Kind of filter (default string)", enum: FilterTypes},
+	"FilterConfig.string_match_type":      {description: "How string_value is matched (default EXACT)", enum: StringMatchTypes},
+	"FilterConfig.string_value":           {description: "Value for string filters"},
+	"FilterConfig.string_case_sensitive":  {description: "Match string_value case-sensitively"},
+	"FilterConfig.numeric_operation":      {description: "Comparison for numeric filters", enum: NumericOperations},
+	"FilterConfig.numeric_value":          {description: "Value for numeric filters"},
+	"FilterConfig.between_from":           {description: "Lower bound for between filters"},
+	"FilterConfig.between_to":             {description: "Upper bound for between filters"},
+	"FilterConfig.in_list_values":         {description: "Values for in_list filters"},
+	"FilterConfig.in_list_case_sensitive": {description: "Match in_list_values case-sensitively"},
+	"FilterConfig.logic_operator":         {description: "AND (default) or NOT to negate the filter; filters always combine with AND", enum: []string{"AND", "NOT"}},
+
+	"OrderByConfig.field_name": {description: "Dimension or metric of the query to sort by", required: true},
+	"OrderByConfig.field_type": {description: "Detected from the query when omitted", enum: []string{"dimension", "metric"}},
+	"OrderByConfig.descending": {description: "Sort largest first"},
+	"OrderByConfig.order_type": {description: "How dimension values compare", enum: DimensionOrderTypes},
+
+	"QueryTemplate.name":        {description: "Template name", required: true},
+	"QueryTemplate.description": {description: "What the template reports"},
+	"QueryTemplate.category":    {description: "Group shown in template listings"},
+	"QueryTemplate.query":       {description: "The query the template runs", required: true},
+	"QueryTemplate.created_at":  {description: "Set when the template is saved"},
+	"QueryTemplate.updated_at":  {description: "Set when the template is saved"},
+	"QueryTemplate.usage_count": {description: "How often the template ran", minimum: bound(0)},
+	"QueryTemplate.last_used":   {description: "When the template last ran"},
+}
+
+// QueryConfigSchema describes query files as read by 'query run --file'
+func QueryConfigSchema() *Schema {
+	schema := generateSchema(reflect.TypeOf(QueryConfig{}))
+	schema.Draft = "http://json-schema.org/draft-07/schema#"
+	schema.ID = schemaBaseURL + QuerySchemaFile
+	schema.Title = "ga4admin query"
+	schema.Description = "A GA4 report query run with 'ga4admin query run --file'"
+	return schema
+}
+
+// QueryTemplateSchema describes saved query templates
+func QueryTemplateSchema() *Schema {
+	schema := generateSchema(reflect.TypeOf(QueryTemplate{}))
+	schema.Draft = "http://json-schema.org/draft-07/schema#"
+	schema.ID = schemaBaseURL + QueryTemplateSchemaFile
+	schema.Title = "ga4admin query template"
+	schema.Description = "A named, reusable GA4 report query"
+	return schema
+}
+
+// generateSchema maps a Go type to its schema, naming object properties after
+// their json tags
+func generateSchema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generateSchema(t.Elem())}
+	case reflect.Struct:
+		closed := false
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: &closed}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+
+			property := generateSchema(field.Type)
+			annotation := schemaAnnotations[t.Name()+"."+name]
+			property.Description = annotation.description
+			property.Minimum = annotation.minimum
+			property.Maximum = annotation.maximum
+			if annotation.enum != nil {
+				if property.Type == "array" {
+					property.Items.Enum = annotation.enum
+				} else {
+					property.Enum = annotation.enum
+				}
+			}
+			if annotation.required {
+				schema.Required = append(schema.Required, name)
+			}
+			schema.Properties[name] = property
+		}
+		return schema
+	}
+	return &Schema{}
+}
+
+// SchemaError is a value in a query file that doesn't match the schema
+type SchemaError struct {
+	Line    int
+	Column  int
+	Path    string // e.g. "filters[0].type"; empty for the document itself
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// ValidateQueryFileSchema checks a YAML or JSON query file against
+// QueryConfigSchema, returning every mismatch with its position. The error is
+// for files that can't be read or parsed at all.
+func ValidateQueryFileSchema(path string) ([]SchemaError, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %w", err)
+	}
+	return QueryConfigSchema().Validate(data)
+}
+
+// Validate checks a YAML or JSON document (JSON is read as YAML, which keeps
+// positions for both) against the schema
+func (s *Schema) Validate(data []byte) ([]SchemaError, error) {
+	var document yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&document); err != nil {
+		if err == io.EOF {
+			return nil, nil // An empty file is an empty query
+		}
+		return nil, fmt.Errorf("failed to parse query file: %w", err)
+	}
+
+	var errs []SchemaError
+	if len(document.Content) > 0 {
+		s.validateNode(document.Content[0], "", &errs)
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+	return errs, nil
+}
+
+func (s *Schema) validateNode(node *yaml.Node, path string, errs *[]SchemaError) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, SchemaError{Line: node.Line, Column: node.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if got := yamlNodeType(node); !schemaTypeMatches(s.Type, got) {
+		if s.Type == "string" && node.Kind == yaml.ScalarNode && got != "null" {
+			// YAML reads unquoted IDs such as 328687832 as numbers
+			fail("expected a string, got %s '%s' - quote it", got, node.Value)
+		} else {
+			fail("expected %s, got %s", schemaTypeNames[s.Type], got)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			present[key.Value] = true
+			property, ok := s.Properties[key.Value]
+			if !ok {
+				*errs = append(*errs, SchemaError{Line: key.Line, Column: key.Column, Path: path,
+					Message: UnknownFieldMessage("key", key.Value, s.propertyNames())})
+				continue
+			}
+			property.validateNode(value, joinSchemaPath(path, key.Value), errs)
+		}
+		for _, name := range s.Required {
+			if !present[name] {
+				fail("missing required key '%s'", name)
+			}
+		}
+
+	case "array":
+		for i, item := range node.Content {
+			s.Items.validateNode(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	default:
+		if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+			fail("'%s' is not one of %s", node.Value, strings.Join(s.Enum, ", "))
+		}
+		if s.Type == "integer" || s.Type == "number" {
+			var value float64
+			if err := node.Decode(&value); err == nil {
+				if s.Minimum != nil && value < *s.Minimum {
+					fail("%s is below the minimum of %v", node.Value, *s.Minimum)
+				}
+				if s.Maximum != nil && value > *s.Maximum {
+					fail("%s is above the maximum of %v", node.Value, *s.Maximum)
+				}
+			}
+		}
+	}
+}
+
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaTypeNames reads schema types the way errors describe values
+var schemaTypeNames = map[string]string{
+	"object":  "a mapping",
+	"array":   "a list",
+	"string":  "a string",
+	"integer": "an integer",
+	"number":  "a number",
+	"boolean": "true or false",
+}
+
+// yamlNodeType names a node's value like schemaTypeNames does
+func yamlNodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.ShortTag() {
+	case "!!str", "!!timestamp": // Unquoted dates are strings to the loader
+		return "a string"
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!bool":
+		return "true or false"
+	case "!!null":
+		return "null"
+	}
+	return node.ShortTag()
+}
+
+func schemaTypeMatches(schemaType, nodeType string) bool {
+	if schemaType == "number" && nodeType == "an integer" {
+		return true
+	}
+	return schemaTypeNames[schemaType] == nodeType
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// MarshalIndent renders the schema as the published JSON file
+func (s *Schema) MarshalIndent() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
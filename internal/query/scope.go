@@ -0,0 +1,70 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Item-scoped metrics. GA4 only allows these alongside item-scoped dimensions;
+// mixing item-scoped dimensions with event-scoped metrics fails with an
+// unhelpful "incompatible dimensions and metrics" error.
+var itemScopedMetrics = map[string]bool{
+	"itemRevenue":                   true,
+	"grossItemRevenue":              true,
+	"itemDiscountAmount":            true,
+	"itemRefundAmount":              true,
+	"itemsPurchased":                true,
+	"itemPurchaseQuantity":          true,
+	"itemsViewed":                   true,
+	"itemsAddedToCart":              true,
+	"itemsCheckedOut":               true,
+	"itemsClickedInList":            true,
+	"itemsClickedInPromotion":       true,
+	"itemsViewedInList":             true,
+	"itemsViewedInPromotion":        true,
+	"itemViewEvents":                true,
+	"itemListViewEvents":            true,
+	"itemListClickEvents":           true,
+	"itemListClickThroughRate":      true,
+	"itemPromotionClickThroughRate": true,
+	"cartToViewRate":                true,
+	"purchaseToViewRate":            true,
+}
+
+// IsItemScopedDimension reports whether a dimension describes individual items
+// (itemName, itemCategory, customItem:color, ...)
+func IsItemScopedDimension(name string) bool {
+	return strings.HasPrefix(name, "item") || strings.HasPrefix(name, "customItem:")
+}
+
+// IsItemScopedMetric reports whether a metric is computed per item
+func IsItemScopedMetric(name string) bool {
+	return itemScopedMetrics[name]
+}
+
+// ValidateFieldScopes rejects queries that combine item-scoped dimensions with
+// event- or session-scoped metrics
+func ValidateFieldScopes(config *QueryConfig) error {
+	var itemDimensions []string
+	for _, dimension := range config.Dimensions {
+		if IsItemScopedDimension(dimension) {
+			itemDimensions = append(itemDimensions, dimension)
+		}
+	}
+	if len(itemDimensions) == 0 {
+		return nil
+	}
+
+	var otherMetrics []string
+	for _, metric := range config.Metrics {
+		if !IsItemScopedMetric(metric) {
+			otherMetrics = append(otherMetrics, metric)
+		}
+	}
+	if len(otherMetrics) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("item-scoped dimension(s) [%s] cannot be combined with event-scoped metric(s) [%s]; use item metrics such as itemRevenue, itemsPurchased or itemsViewed",
+		strings.Join(itemDimensions, ", "), strings.Join(otherMetrics, ", "))
+}
@@ -0,0 +1,126 @@
+package query
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+)
+
+// StatsRecorder stores query executions for 'query stats'
+type StatsRecorder interface {
+	RecordQueryExecution(ctx context.Context, entry config.QueryLogEntry) error
+}
+
+// SetStatsRecorder makes the executor log every query it runs
+func (e *Executor) SetStatsRecorder(recorder StatsRecorder) {
+	e.recorder = recorder
+}
+
+// recordExecution logs one execution. Failures to log never fail the query.
+func (e *Executor) recordExecution(ctx context.Context, queryConfig *QueryConfig, startTime time.Time, response *api.RunReportResponse, queryErr error) {
+	if e.recorder == nil {
+		return
+	}
+
+	entry := config.QueryLogEntry{
+		PropertyID:  queryConfig.PropertyID,
+		QueryHash:   e.generateQueryHash(queryConfig),
+		ExecutedAt:  startTime,
+		ExecutionMs: time.Since(startTime).Milliseconds(),
+		Dimensions:  queryConfig.Dimensions,
+		Metrics:     queryConfig.Metrics,
+	}
+	if queryErr != nil {
+		entry.Error = queryErr.Error()
+	}
+	if response != nil {
+		entry.RowCount = response.RowCount
+		entry.FromCache = response.FromCache
+		if !response.FromCache && response.PropertyQuota != nil && response.PropertyQuota.TokensPerDay != nil {
+			entry.TokensConsumed = response.PropertyQuota.TokensPerDay.Consumed
+		}
+	}
+
+	// The query's own context may already be done; logging gets a short one
+	logCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	e.recorder.RecordQueryExecution(logCtx, entry)
+}
+
+// BuildQueryStats summarizes logged executions for a property, keeping the
+// top most-used dimensions and metrics
+func BuildQueryStats(propertyID string, entries []config.QueryLogEntry, since time.Time, top int) *QueryStats {
+	stats := &QueryStats{
+		PropertyID:   propertyID,
+		TotalQueries: len(entries),
+		Since:        since,
+		LastAnalyzed: time.Now(),
+	}
+	if len(entries) == 0 {
+		return stats
+	}
+
+	var cacheHits, totalRows int
+	var totalMs int64
+	latencies := make([]int64, 0, len(entries))
+	dimensionCounts := make(map[string]int)
+	metricCounts := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.Error != "" {
+			stats.FailedQueries++
+		}
+		if entry.FromCache {
+			cacheHits++
+		}
+		totalRows += entry.RowCount
+		totalMs += entry.ExecutionMs
+		stats.TotalTokens += entry.TokensConsumed
+		latencies = append(latencies, entry.ExecutionMs)
+
+		for _, dimension := range entry.Dimensions {
+			dimensionCounts[dimension]++
+		}
+		for _, metric := range entry.Metrics {
+			metricCounts[metric]++
+		}
+	}
+
+	count := float64(len(entries))
+	stats.CacheHitRate = float64(cacheHits) / count * 100
+	stats.AverageRowCount = float64(totalRows) / count
+	stats.AverageExecutionMs = float64(totalMs) / count
+
+	// Nearest-rank percentile
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	rank := int(math.Ceil(0.95*count)) - 1
+	stats.P95ExecutionMs = latencies[rank]
+	stats.MaxExecutionMs = latencies[len(latencies)-1]
+
+	stats.PopularDimensions = topFields(dimensionCounts, top)
+	stats.PopularMetrics = topFields(metricCounts, top)
+
+	return stats
+}
+
+// topFields returns the n most-used fields, ties broken by name
+func topFields(counts map[string]int, n int) []FieldUsage {
+	usage := make([]FieldUsage, 0, len(counts))
+	for name, count := range counts {
+		usage = append(usage, FieldUsage{Name: name, Count: count})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	if n > 0 && len(usage) > n {
+		usage = usage[:n]
+	}
+	return usage
+}
@@ -0,0 +1,69 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"ga4admin/internal/api"
+)
+
+// MaxPageSize is the largest page the Data API returns in one call
+const MaxPageSize = 250000
+
+// StreamSummary reports the outcome of a streamed query
+type StreamSummary struct {
+	Pages     int   `json:"pages"`
+	Rows      int64 `json:"rows"`
+	TotalRows int64 `json:"total_rows"` // Rows GA4 reports as matching the query
+}
+
+// ExecuteStream runs a query page by page, handing each page to onPage as it
+// arrives so callers never hold the full result in memory. maxRows caps the
+// total rows fetched; 0 fetches every matching row. config.Limit is ignored.
+func (e *Executor) ExecuteStream(ctx context.Context, config *QueryConfig, pageSize, maxRows int64, onPage func(page *api.RunReportResponse) error) (*StreamSummary, error) {
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", MaxPageSize)
+	}
+	if maxRows < 0 {
+		return nil, fmt.Errorf("row limit cannot be negative")
+	}
+
+	config.Limit = pageSize
+	config = e.withPropertyDefaults(ctx, config)
+	if err := e.validateQuery(config); err != nil {
+		return nil, fmt.Errorf("query validation failed: %w", err)
+	}
+
+	request, err := e.configToRequest(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert query config to API request: %w", err)
+	}
+
+	summary := &StreamSummary{}
+	for {
+		request.Offset = config.Offset + summary.Rows
+		request.Limit = pageSize
+		if maxRows > 0 && maxRows-summary.Rows < pageSize {
+			request.Limit = maxRows - summary.Rows
+		}
+
+		page, err := e.dataClient.RunReport(ctx, request)
+		if err != nil {
+			return summary, fmt.Errorf("failed to fetch page %d: %w", summary.Pages+1, err)
+		}
+
+		summary.Pages++
+		summary.TotalRows = int64(page.RowCount)
+		if err := onPage(page); err != nil {
+			return summary, err
+		}
+		summary.Rows += int64(len(page.Rows))
+
+		done := len(page.Rows) == 0 ||
+			config.Offset+summary.Rows >= summary.TotalRows ||
+			(maxRows > 0 && summary.Rows >= maxRows)
+		if done {
+			return summary, nil
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ga4admin/internal/api"
+)
+
+// maxSuggestions caps how many "did you mean" candidates are offered
+const maxSuggestions = 3
+
+// SuggestFields returns the candidates closest to an unknown field name:
+// those within a small edit distance, plus those that start with the name
+// (so "session" offers "sessionsPerUser"). Closest come first.
+func SuggestFields(name string, candidates []string) []string {
+	needle := strings.ToLower(name)
+	maxDistance := len([]rune(needle)) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := editDistance(needle, lower)
+		if distance <= maxDistance || (len(needle) >= 3 && strings.HasPrefix(lower, needle)) {
+			matches = append(matches, match{name: candidate, distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, m := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, m.name)
+	}
+	return suggestions
+}
+
+// UnknownFieldMessage describes an unknown field with suggestions, e.g.
+// "unknown metric 'session' — did you mean 'sessions' or 'sessionsPerUser'?"
+func UnknownFieldMessage(kind, name string, candidates []string) string {
+	message := fmt.Sprintf("unknown %s '%s'", kind, name)
+	suggestions := SuggestFields(name, candidates)
+	if len(suggestions) == 0 {
+		return message
+	}
+
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = "'" + suggestion + "'"
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("%s — did you mean %s?", message, quoted[0])
+	}
+	return fmt.Sprintf("%s — did you mean %s or %s?", message, strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// ValidateFieldNames checks every dimension, metric, calculated metric operand
+// and filter field against property metadata, reporting all unknown fields
+// with suggestions in one error
+func ValidateFieldNames(config *QueryConfig, metadata *api.MetadataResponse) error {
+	dimensionNames := make([]string, 0, len(metadata.Dimensions))
+	dimensionSet := make(map[string]bool, len(metadata.Dimensions))
+	for _, dimension := range metadata.Dimensions {
+		dimensionNames = append(dimensionNames, dimension.APIName)
+		dimensionSet[dimension.APIName] = true
+	}
+	metricNames := make([]string, 0, len(metadata.Metrics))
+	metricSet := make(map[string]bool, len(metadata.Metrics))
+	for _, metric := range metadata.Metrics {
+		metricNames = append(metricNames, metric.APIName)
+		metricSet[metric.APIName] = true
+	}
+
+	var problems []string
+	for _, dimension := range config.Dimensions {
+		switch {
+		case dimensionSet[dimension]:
+		case metricSet[dimension]:
+			problems = append(problems, fmt.Sprintf("'%s' is a metric, not a dimension", dimension))
+		default:
+			problems = append(problems, UnknownFieldMessage("dimension", dimension, dimensionNames))
+		}
+	}
+
+	metrics := append([]string{}, config.Metrics...)
+	for _, calculated := range config.CalculatedMetrics {
+		metrics = append(metrics, expressionOperands(calculated.Expression)...)
+	}
+	checked := make(map[string]bool)
+	for _, metric := range metrics {
+		if checked[metric] {
+			continue
+		}
+		checked[metric] = true
+		switch {
+		case metricSet[metric]:
+		case dimensionSet[metric]:
+			problems = append(problems, fmt.Sprintf("'%s' is a dimension, not a metric", metric))
+		default:
+			problems = append(problems, UnknownFieldMessage("metric", metric, metricNames))
+		}
+	}
+
+	for _, filter := range config.Filters {
+		if dimensionSet[filter.FieldName] || metricSet[filter.FieldName] {
+			continue
+		}
+		problems = append(problems, UnknownFieldMessage("filter field", filter.FieldName, append(dimensionNames, metricNames...)))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return invalidQueryError{fmt.Errorf("%s", strings.Join(problems, "; "))}
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
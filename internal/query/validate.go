@@ -0,0 +1,181 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+)
+
+// GA4 rejects report requests with more fields than these
+const (
+	maxRequestDimensions = 9
+	maxRequestMetrics    = 10
+)
+
+// ErrInvalidQuery matches errors from checking a query before it runs, so
+// callers can tell a rejected query from a failed request
+var ErrInvalidQuery = errors.New("invalid query")
+
+// invalidQueryError keeps a validation error's message while matching
+// ErrInvalidQuery
+type invalidQueryError struct{ err error }
+
+func (e invalidQueryError) Error() string        { return e.err.Error() }
+func (e invalidQueryError) Unwrap() error        { return e.err }
+func (e invalidQueryError) Is(target error) bool { return target == ErrInvalidQuery }
+
+// Validation stages, in the order Validate runs them
+const (
+	StageQuery         = "query"         // Required fields, dates, filters, ordering, scopes
+	StageLimits        = "limits"        // Field counts GA4 allows per request
+	StageFields        = "fields"        // Every field exists on the property
+	StageCompatibility = "compatibility" // GA4 accepts the fields together
+)
+
+// ValidationResult is the outcome of one validation stage
+type ValidationResult struct {
+	Stage   string
+	Err     error
+	Skipped string // Why the stage didn't run; empty when it ran
+}
+
+// Validate checks a query as thoroughly as possible without running it:
+// its structure, GA4's request limits, the property's metadata and, when the
+// data service supports it, GA4's compatibility check. Stages that depend on
+// a failed one are skipped. The config is not modified.
+func (e *Executor) Validate(ctx context.Context, config *QueryConfig) []ValidationResult {
+	checked := *config
+	results := make([]ValidationResult, 0, 4)
+
+	structureErr := e.validateQuery(&checked)
+	if structureErr == nil {
+		structureErr = validateDates(&checked)
+	}
+	results = append(results, ValidationResult{Stage: StageQuery, Err: structureErr})
+	if structureErr != nil {
+		for _, stage := range []string{StageLimits, StageFields, StageCompatibility} {
+			results = append(results, ValidationResult{Stage: stage, Skipped: "the query is invalid"})
+		}
+		return results
+	}
+
+	request, err := e.configToRequest(&checked)
+	if err != nil {
+		results = append(results, ValidationResult{Stage: StageLimits, Err: err})
+	} else {
+		results = append(results, ValidationResult{Stage: StageLimits, Err: validateRequestLimits(request)})
+	}
+
+	var fieldsErr error
+	if metadata, err := e.dataClient.GetMetadata(ctx, checked.PropertyID); err != nil {
+		fieldsErr = fmt.Errorf("failed to get property metadata: %w", err)
+	} else {
+		fieldsErr = ValidateFieldNames(&checked, metadata)
+	}
+	results = append(results, ValidationResult{Stage: StageFields, Err: fieldsErr})
+
+	checker, ok := e.dataClient.(api.CompatibilityChecker)
+	switch {
+	case fieldsErr != nil:
+		results = append(results, ValidationResult{Stage: StageCompatibility, Skipped: "fields could not be verified"})
+	case !ok:
+		results = append(results, ValidationResult{Stage: StageCompatibility, Skipped: "not supported by this data client"})
+	default:
+		results = append(results, ValidationResult{Stage: StageCompatibility, Err: e.checkCompatibility(ctx, checker, &checked)})
+	}
+
+	return results
+}
+
+// validateDates checks that dates are YYYY-MM-DD or relative (e.g.
+// 30daysAgo) and that absolute ranges don't end before they start
+func validateDates(config *QueryConfig) error {
+	start, startErr := time.Parse("2006-01-02", config.StartDate)
+	if startErr != nil && !isRelativeDate(config.StartDate) {
+		return fmt.Errorf("invalid start date format: %s", config.StartDate)
+	}
+	end, endErr := time.Parse("2006-01-02", config.EndDate)
+	if endErr != nil && !isRelativeDate(config.EndDate) {
+		return fmt.Errorf("invalid end date format: %s", config.EndDate)
+	}
+	if startErr == nil && endErr == nil && end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", config.EndDate, config.StartDate)
+	}
+	return nil
+}
+
+// validateRequestLimits checks the field counts GA4 allows per request
+func validateRequestLimits(request *api.RunReportRequest) error {
+	var problems []string
+	if len(request.Dimensions) > maxRequestDimensions {
+		problems = append(problems, fmt.Sprintf("%d dimensions requested, GA4 allows %d", len(request.Dimensions), maxRequestDimensions))
+	}
+	if len(request.Metrics) > maxRequestMetrics {
+		problems = append(problems, fmt.Sprintf("%d metrics requested (including calculated metrics), GA4 allows %d", len(request.Metrics), maxRequestMetrics))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkCompatibility asks GA4 which of the query's fields can't be combined.
+// Calculated metrics are checked through the metrics they are built from.
+func (e *Executor) checkCompatibility(ctx context.Context, checker api.CompatibilityChecker, config *QueryConfig) error {
+	request := &api.CheckCompatibilityRequest{
+		Property:            config.PropertyID,
+		CompatibilityFilter: api.CompatibilityIncompatible,
+	}
+
+	requested := make(map[string]bool)
+	for _, dimension := range config.Dimensions {
+		request.Dimensions = append(request.Dimensions, api.Dimension{Name: dimension})
+		requested[dimension] = true
+	}
+	metrics := append([]string{}, config.Metrics...)
+	for _, calculated := range config.CalculatedMetrics {
+		metrics = append(metrics, expressionOperands(calculated.Expression)...)
+	}
+	for _, metric := range metrics {
+		if requested[metric] {
+			continue
+		}
+		request.Metrics = append(request.Metrics, api.Metric{Name: metric})
+		requested[metric] = true
+	}
+	if len(config.Filters) > 0 {
+		dimensionFilter, metricFilter, err := e.splitFilters(config)
+		if err != nil {
+			return fmt.Errorf("failed to convert filters: %w", err)
+		}
+		request.DimensionFilter = dimensionFilter
+		request.MetricFilter = metricFilter
+	}
+
+	response, err := checker.CheckCompatibility(ctx, request)
+	if err != nil {
+		return fmt.Errorf("compatibility check failed: %w", err)
+	}
+
+	// The response covers every field on the property that is incompatible
+	// with the request; only the requested ones matter here
+	var incompatible []string
+	for _, dimension := range response.DimensionCompatibilities {
+		if requested[dimension.DimensionMetadata.APIName] && dimension.Compatibility == api.CompatibilityIncompatible {
+			incompatible = append(incompatible, dimension.DimensionMetadata.APIName)
+		}
+	}
+	for _, metric := range response.MetricCompatibilities {
+		if requested[metric.MetricMetadata.APIName] && metric.Compatibility == api.CompatibilityIncompatible {
+			incompatible = append(incompatible, metric.MetricMetadata.APIName)
+		}
+	}
+	if len(incompatible) > 0 {
+		return fmt.Errorf("GA4 can't query these fields together: %s", strings.Join(incompatible, ", "))
+	}
+	return nil
+}
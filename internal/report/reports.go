@@ -0,0 +1,124 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"ga4admin/internal/query"
+)
+
+// Report categories
+const (
+	CategoryAcquisition = "Acquisition"
+	CategoryEngagement  = "Engagement"
+	CategoryEcommerce   = "Ecommerce"
+	CategoryAudience    = "Audience"
+)
+
+// builtinReports are curated query templates for common questions, so users
+// get useful output without knowing GA4 field names. Property and date range
+// are supplied at run time.
+var builtinReports = map[string]*query.QueryTemplate{
+	"acquisition": {
+		Name:        "acquisition",
+		Description: "Sessions, users and key events by channel and source/medium",
+		Category:    CategoryAcquisition,
+		Query: &query.QueryConfig{
+			Dimensions: []string{"sessionDefaultChannelGroup", "sessionSourceMedium"},
+			Metrics:    []string{"sessions", "totalUsers", "newUsers", "engagementRate", "keyEvents", "totalRevenue"},
+			OrderBy: []query.OrderByConfig{
+				{FieldName: "sessions", FieldType: "metric", Descending: true},
+			},
+			Limit: 100,
+		},
+	},
+	"landing-pages": {
+		Name:        "landing-pages",
+		Description: "Landing page performance: sessions, engagement and key events",
+		Category:    CategoryEngagement,
+		Query: &query.QueryConfig{
+			Dimensions: []string{"landingPage"},
+			Metrics:    []string{"sessions", "engagementRate", "bounceRate", "averageSessionDuration", "keyEvents"},
+			OrderBy: []query.OrderByConfig{
+				{FieldName: "sessions", FieldType: "metric", Descending: true},
+			},
+			Limit: 100,
+		},
+	},
+	"ecommerce-funnel": {
+		Name:        "ecommerce-funnel",
+		Description: "Shopping funnel steps from product view to purchase",
+		Category:    CategoryEcommerce,
+		Query: &query.QueryConfig{
+			Dimensions: []string{"eventName"},
+			Metrics:    []string{"eventCount", "totalUsers"},
+			Filters: []query.FilterConfig{
+				{
+					FieldName:    "eventName",
+					Type:         "in_list",
+					InListValues: []string{"view_item", "add_to_cart", "begin_checkout", "add_payment_info", "purchase"},
+				},
+			},
+			OrderBy: []query.OrderByConfig{
+				{FieldName: "eventCount", FieldType: "metric", Descending: true},
+			},
+		},
+	},
+	"ecommerce": {
+		Name:        "ecommerce",
+		Description: "Item performance: views, add-to-carts, purchases and item revenue",
+		Category:    CategoryEcommerce,
+		Query: &query.QueryConfig{
+			// Item-scoped dimensions only work with item-scoped metrics
+			Dimensions: []string{"itemName", "itemCategory"},
+			Metrics:    []string{"itemsViewed", "itemsAddedToCart", "itemsPurchased", "itemRevenue"},
+			OrderBy: []query.OrderByConfig{
+				{FieldName: "itemRevenue", FieldType: "metric", Descending: true},
+			},
+			Limit: 100,
+		},
+	},
+	"geo": {
+		Name:        "geo",
+		Description: "Users, sessions and revenue by country and city",
+		Category:    CategoryAudience,
+		Query: &query.QueryConfig{
+			Dimensions: []string{"country", "city"},
+			Metrics:    []string{"activeUsers", "sessions", "engagementRate", "totalRevenue"},
+			OrderBy: []query.OrderByConfig{
+				{FieldName: "activeUsers", FieldType: "metric", Descending: true},
+			},
+			Limit: 100,
+		},
+	},
+}
+
+// List returns all built-in reports sorted by category and name
+func List() []*query.QueryTemplate {
+	reports := make([]*query.QueryTemplate, 0, len(builtinReports))
+	for _, template := range builtinReports {
+		reports = append(reports, template)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Category != reports[j].Category {
+			return reports[i].Category < reports[j].Category
+		}
+		return reports[i].Name < reports[j].Name
+	})
+
+	return reports
+}
+
+// Get returns a copy of a built-in report so callers can adjust it freely
+func Get(name string) (*query.QueryTemplate, error) {
+	template, ok := builtinReports[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown report '%s' (run 'ga4admin report list' to see available reports)", name)
+	}
+
+	copied := *template
+	queryCopy := *template.Query
+	copied.Query = &queryCopy
+	return &copied, nil
+}
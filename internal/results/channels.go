@@ -0,0 +1,207 @@
+package results
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+	"ga4admin/internal/query"
+)
+
+// channelScopes are the GA4 columns channel rules match against, by
+// attribution scope. The first scope with a source or medium column in the
+// result is used, so session and first-user values are never mixed.
+var channelScopes = []channelScope{
+	{"sessionSource", "sessionMedium", "sessionCampaignName", "sessionSourceMedium"},
+	{"firstUserSource", "firstUserMedium", "firstUserCampaignName", "firstUserSourceMedium"},
+	{"source", "medium", "campaignName", "sourceMedium"},
+}
+
+type channelScope struct {
+	source, medium, campaign, sourceMedium string
+}
+
+// ChannelMatcher assigns channels with the compiled rules of a channel mapping
+type ChannelMatcher struct {
+	Column  string // Name of the added column
+	Default string // Channel of rows no rule matches
+	rules   []channelRule
+}
+
+type channelRule struct {
+	channel                  string
+	source, medium, campaign *regexp.Regexp // nil matches anything
+}
+
+// NewChannelMatcher compiles a channel mapping
+func NewChannelMatcher(mapping config.ChannelMapping) (*ChannelMatcher, error) {
+	matcher := &ChannelMatcher{Column: mapping.Column, Default: mapping.Default}
+	if matcher.Column == "" {
+		matcher.Column = config.DefaultChannelColumn
+	}
+	if matcher.Default == "" {
+		matcher.Default = config.DefaultChannel
+	}
+
+	compile := func(pattern string) (*regexp.Regexp, error) {
+		if pattern == "" {
+			return nil, nil
+		}
+		re, err := config.CompileChannelPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("channel mapping '%s' has an invalid pattern '%s': %w", mapping.Name, pattern, err)
+		}
+		return re, nil
+	}
+
+	for _, rule := range mapping.Rules {
+		compiled := channelRule{channel: rule.Channel}
+		var err error
+		if compiled.source, err = compile(rule.Source); err != nil {
+			return nil, err
+		}
+		if compiled.medium, err = compile(rule.Medium); err != nil {
+			return nil, err
+		}
+		if compiled.campaign, err = compile(rule.Campaign); err != nil {
+			return nil, err
+		}
+		matcher.rules = append(matcher.rules, compiled)
+	}
+	return matcher, nil
+}
+
+// Channel returns the channel for a source, medium and campaign and the
+// 1-based number of the rule that matched, or 0 for the default channel
+func (m *ChannelMatcher) Channel(source, medium, campaign string) (string, int) {
+	for i, rule := range m.rules {
+		if matchesChannelPattern(rule.source, source) &&
+			matchesChannelPattern(rule.medium, medium) &&
+			matchesChannelPattern(rule.campaign, campaign) {
+			return rule.channel, i + 1
+		}
+	}
+	return m.Default, 0
+}
+
+func matchesChannelPattern(re *regexp.Regexp, value string) bool {
+	return re == nil || re.MatchString(value)
+}
+
+// uses reports which fields the rules match on
+func (m *ChannelMatcher) uses() (source, medium, campaign bool) {
+	for _, rule := range m.rules {
+		source = source || rule.source != nil
+		medium = medium || rule.medium != nil
+		campaign = campaign || rule.campaign != nil
+	}
+	return source, medium, campaign
+}
+
+// ApplyChannels adds the matcher's channel column to a result as its last
+// dimension. Rules match the result's source, medium and campaign columns;
+// a combined sourceMedium column ("google / organic") stands in for missing
+// source or medium columns. Totals, minimums and maximums are kept, since
+// the metrics are unchanged.
+func ApplyChannels(result *query.QueryResult, matcher *ChannelMatcher) (*query.QueryResult, error) {
+	index := make(map[string]int)
+	for i, header := range result.DimensionHeaders {
+		index[header.Name] = i
+	}
+	if _, ok := index[matcher.Column]; ok {
+		return nil, fmt.Errorf("channel column '%s' clashes with an existing column", matcher.Column)
+	}
+	for _, header := range result.MetricHeaders {
+		if header.Name == matcher.Column {
+			return nil, fmt.Errorf("channel column '%s' clashes with an existing column", matcher.Column)
+		}
+	}
+
+	column := func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		return -1
+	}
+	sourceCol, mediumCol, campaignCol, sourceMediumCol := -1, -1, -1, -1
+	for _, scope := range channelScopes {
+		sourceCol, mediumCol, sourceMediumCol = column(scope.source), column(scope.medium), column(scope.sourceMedium)
+		if sourceCol >= 0 || mediumCol >= 0 || sourceMediumCol >= 0 {
+			campaignCol = column(scope.campaign)
+			break
+		}
+	}
+
+	usesSource, usesMedium, usesCampaign := matcher.uses()
+	missing := func(field string, pick func(channelScope) string) error {
+		var names []string
+		for _, scope := range channelScopes {
+			names = append(names, pick(scope))
+		}
+		last := len(names) - 1
+		return fmt.Errorf("channel rules match on %s, but the result has no %s or %s column", field, strings.Join(names[:last], ", "), names[last])
+	}
+	if usesSource && sourceCol < 0 && sourceMediumCol < 0 {
+		return nil, missing("source", func(s channelScope) string { return s.source })
+	}
+	if usesMedium && mediumCol < 0 && sourceMediumCol < 0 {
+		return nil, missing("medium", func(s channelScope) string { return s.medium })
+	}
+	if usesCampaign && campaignCol < 0 {
+		return nil, missing("campaign", func(s channelScope) string { return s.campaign })
+	}
+
+	value := func(row api.Row, col int) string {
+		if col < 0 || col >= len(row.DimensionValues) {
+			return ""
+		}
+		return row.DimensionValues[col].Value
+	}
+
+	mapped := *result
+	mapped.DimensionHeaders = append(append([]api.DimensionHeader(nil), result.DimensionHeaders...), api.DimensionHeader{Name: matcher.Column})
+	mapped.Rows = make([]api.Row, len(result.Rows))
+	for i, row := range result.Rows {
+		source, medium := value(row, sourceCol), value(row, mediumCol)
+		if sourceMediumCol >= 0 {
+			combinedSource, combinedMedium, _ := strings.Cut(value(row, sourceMediumCol), " / ")
+			if sourceCol < 0 {
+				source = combinedSource
+			}
+			if mediumCol < 0 {
+				medium = combinedMedium
+			}
+		}
+		channel, _ := matcher.Channel(source, medium, value(row, campaignCol))
+		mapped.Rows[i] = withDimension(row, len(result.DimensionHeaders), channel)
+	}
+
+	// Summary rows get an empty channel so every row has the same columns
+	mapped.Totals = padSummaryRows(result.Totals, len(result.DimensionHeaders))
+	mapped.Minimums = padSummaryRows(result.Minimums, len(result.DimensionHeaders))
+	mapped.Maximums = padSummaryRows(result.Maximums, len(result.DimensionHeaders))
+	return &mapped, nil
+}
+
+// withDimension returns a copy of row with value as dimension number at
+func withDimension(row api.Row, at int, value string) api.Row {
+	dimensions := make([]api.DimensionValue, at, at+1)
+	copy(dimensions, row.DimensionValues)
+	return api.Row{
+		DimensionValues: append(dimensions, api.DimensionValue{Value: value}),
+		MetricValues:    row.MetricValues,
+	}
+}
+
+func padSummaryRows(rows []api.Row, at int) []api.Row {
+	if rows == nil {
+		return nil
+	}
+	padded := make([]api.Row, len(rows))
+	for i, row := range rows {
+		padded[i] = withDimension(row, at, "")
+	}
+	return padded
+}
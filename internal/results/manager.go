@@ -4,17 +4,21 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
+	"ga4admin/internal/api"
 	"ga4admin/internal/cache"
 	"ga4admin/internal/query"
 )
 
+// ErrNotFound means no cached result has the requested ID
+var ErrNotFound = errors.New("result not found")
+
 // Manager handles query result storage, retrieval, and export
 type Manager struct {
 	cacheClient *cache.CacheClient
@@ -27,17 +31,230 @@ func NewManager(cacheClient *cache.CacheClient) *Manager {
 	}
 }
 
-// ListResults returns all cached query results for a property
-func (m *Manager) ListResults(ctx context.Context, propertyID string, limit int) ([]ResultSummary, error) {
-	// For now, return empty list as we need to implement proper SQL query interface
-	// This is a placeholder implementation
-	return []ResultSummary{}, nil
+// ListResults returns cached query results for a property, or for every
+// property when propertyID is empty, newest first. A non-empty tag keeps only
+// results carrying it, and a non-zero since only those fetched since then.
+func (m *Manager) ListResults(ctx context.Context, propertyID, tag string, since time.Time, limit int) ([]ResultSummary, error) {
+	entries, err := m.cacheClient.ListQueries(ctx, propertyID, tag, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached queries: %w", err)
+	}
+
+	summaries := make([]ResultSummary, 0, len(entries))
+	for _, entry := range entries {
+		summaries = append(summaries, ResultSummary{
+			QueryID:      entry.QueryID,
+			PropertyID:   entry.PropertyID,
+			QueryHash:    entry.QueryHash,
+			RowCount:     entry.RowCount,
+			CreatedAt:    entry.CreatedAt,
+			LastAccessed: entry.LastAccessed,
+			ExpiresAt:    entry.ExpiresAt,
+			IsExpired:    entry.ExpiresAt != nil && time.Now().After(*entry.ExpiresAt),
+			TableName:    entry.TableName,
+			Description:  entry.Description,
+			Tags:         entry.Tags,
+			Note:         entry.Note,
+		})
+	}
+
+	return summaries, nil
 }
 
-// GetResult retrieves a specific query result by ID
+// GetResult retrieves a specific query result by ID, by the name of a
+// saved view, which is applied to its result, or by the name of a rollup,
+// which is read from its table. Expired results are still
+// returned until cache cleanup removes them.
 func (m *Manager) GetResult(ctx context.Context, queryID string) (*query.QueryResult, error) {
-	// Placeholder implementation
-	return nil, fmt.Errorf("result not found: %s", queryID)
+	var request api.RunReportRequest
+	var response api.RunReportResponse
+
+	entry, err := m.cacheClient.GetQuery(ctx, queryID, &request, &response)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return m.getView(ctx, queryID)
+	}
+
+	// The request isn't stored with its property, so restore it from the entry
+	request.Property = entry.PropertyID
+
+	return &query.QueryResult{
+		QueryID:          entry.QueryID,
+		PropertyID:       entry.PropertyID,
+		QueryHash:        entry.QueryHash,
+		QueryConfig:      requestToConfig(&request),
+		ExecutedAt:       entry.CreatedAt,
+		RowCount:         response.RowCount,
+		FromCache:        true,
+		CachedAt:         &entry.CreatedAt,
+		DimensionHeaders: response.DimensionHeaders,
+		MetricHeaders:    response.MetricHeaders,
+		Rows:             response.Rows,
+		Totals:           response.Totals,
+		Maximums:         response.Maximums,
+		Minimums:         response.Minimums,
+		ResponseMetadata: &response.Metadata,
+		PropertyQuota:    response.PropertyQuota,
+	}, nil
+}
+
+// ResolveID returns the ID of the cached result that ref names: a result ID
+// or the name of a named table
+func (m *Manager) ResolveID(ctx context.Context, ref string) (string, error) {
+	if _, found, err := m.cacheClient.QueryCachedAt(ctx, ref); err != nil {
+		return "", err
+	} else if found {
+		return ref, nil
+	}
+
+	queryID, _, err := m.cacheClient.LookupNamedTable(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if queryID == "" {
+		return "", fmt.Errorf("%w: no cached result or named table '%s'", ErrNotFound, ref)
+	}
+	if _, found, err := m.cacheClient.QueryCachedAt(ctx, queryID); err != nil {
+		return "", err
+	} else if !found {
+		return "", fmt.Errorf("%w: named table '%s' is of result %s, which is no longer cached", ErrNotFound, ref, queryID)
+	}
+	return queryID, nil
+}
+
+// getView reads a result through the saved view with this name
+func (m *Manager) getView(ctx context.Context, name string) (*query.QueryResult, error) {
+	view, err := m.cacheClient.GetResultView(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil {
+		return m.getRollup(ctx, name)
+	}
+
+	source, err := m.GetResult(ctx, view.QueryID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: view '%s' is of result %s, which is no longer cached", ErrNotFound, name, view.QueryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ApplyView(ctx, source, view.Where, view.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	result.ViewName = view.Name
+	return result, nil
+}
+
+// requestToConfig rebuilds the parts of a query configuration that can be
+// recovered from a stored API request (filters and ordering are not)
+func requestToConfig(request *api.RunReportRequest) *query.QueryConfig {
+	config := &query.QueryConfig{
+		PropertyID:         request.Property,
+		Limit:              request.Limit,
+		Offset:             request.Offset,
+		KeepEmptyRows:      request.KeepEmptyRows,
+		MetricAggregations: request.MetricAggregations,
+		CurrencyCode:       request.CurrencyCode,
+	}
+
+	if len(request.DateRanges) > 0 {
+		config.StartDate = request.DateRanges[0].StartDate
+		config.EndDate = request.DateRanges[0].EndDate
+	}
+	for _, dimension := range request.Dimensions {
+		config.Dimensions = append(config.Dimensions, dimension.Name)
+	}
+	for _, metric := range request.Metrics {
+		if metric.Expression != "" {
+			config.CalculatedMetrics = append(config.CalculatedMetrics, query.CalculatedMetric{
+				Name:       metric.Name,
+				Expression: metric.Expression,
+			})
+			continue
+		}
+		config.Metrics = append(config.Metrics, metric.Name)
+	}
+	config.Filters = append(filterConfigs(request.DimensionFilter), filterConfigs(request.MetricFilter)...)
+	for _, orderBy := range request.OrderBys {
+		switch {
+		case orderBy.Dimension != nil:
+			config.OrderBy = append(config.OrderBy, query.OrderByConfig{
+				FieldName:  orderBy.Dimension.DimensionName,
+				FieldType:  "dimension",
+				Descending: orderBy.Desc,
+				OrderType:  orderBy.Dimension.OrderType,
+			})
+		case orderBy.Metric != nil:
+			config.OrderBy = append(config.OrderBy, query.OrderByConfig{
+				FieldName:  orderBy.Metric.MetricName,
+				FieldType:  "metric",
+				Descending: orderBy.Desc,
+			})
+		}
+	}
+
+	return config
+}
+
+// filterConfigs turns a filter expression built from a query's filters, an
+// AND of single or negated filters, back into them
+func filterConfigs(expression *api.FilterExpression) []query.FilterConfig {
+	if expression == nil {
+		return nil
+	}
+	if expression.AndGroup != nil {
+		var filters []query.FilterConfig
+		for i := range expression.AndGroup.Expressions {
+			filters = append(filters, filterConfigs(&expression.AndGroup.Expressions[i])...)
+		}
+		return filters
+	}
+
+	filter, logicOperator := expression.Filter, ""
+	if expression.NotExpression != nil {
+		filter, logicOperator = expression.NotExpression.Filter, "NOT"
+	}
+	if filter == nil {
+		return nil
+	}
+
+	config := query.FilterConfig{FieldName: filter.FieldName, LogicOperator: logicOperator}
+	switch {
+	case filter.StringFilter != nil:
+		config.Type = "string"
+		config.StringMatchType = filter.StringFilter.MatchType
+		config.StringValue = filter.StringFilter.Value
+		config.StringCaseSensitive = filter.StringFilter.CaseSensitive
+	case filter.NumericFilter != nil:
+		config.Type = "numeric"
+		config.NumericOperation = filter.NumericFilter.Operation
+		config.NumericValue = numericValue(filter.NumericFilter.Value)
+	case filter.BetweenFilter != nil:
+		config.Type = "between"
+		config.BetweenFrom = numericValue(filter.BetweenFilter.FromValue)
+		config.BetweenTo = numericValue(filter.BetweenFilter.ToValue)
+	case filter.InListFilter != nil:
+		config.Type = "in_list"
+		config.InListValues = filter.InListFilter.Values
+		config.InListCaseSensitive = filter.InListFilter.CaseSensitive
+	default:
+		return nil
+	}
+	return []query.FilterConfig{config}
+}
+
+func numericValue(value api.NumericValue) float64 {
+	text := value.Int64Value
+	if text == "" {
+		text = value.DoubleValue
+	}
+	number, _ := strconv.ParseFloat(text, 64)
+	return number
 }
 
 // ExportToCSV exports query results to CSV format
@@ -48,6 +265,11 @@ func (m *Manager) ExportToCSV(ctx context.Context, queryID string, outputPath st
 		return fmt.Errorf("failed to get result: %w", err)
 	}
 
+	return WriteCSV(result, outputPath)
+}
+
+// WriteCSV writes a query result to a CSV file
+func WriteCSV(result *query.QueryResult, outputPath string) error {
 	// Create output directory if needed
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -104,6 +326,11 @@ func (m *Manager) ExportToJSON(ctx context.Context, queryID string, outputPath s
 		return fmt.Errorf("failed to get result: %w", err)
 	}
 
+	return WriteJSON(result, outputPath, prettify)
+}
+
+// WriteJSON writes a query result to a JSON file
+func WriteJSON(result *query.QueryResult, outputPath string, prettify bool) error {
 	// Create output directory if needed
 	dir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -142,119 +369,3 @@ func (m *Manager) GetResultStats(ctx context.Context, propertyID string) (*Resul
 		GeneratedAt:      time.Now(),
 	}, nil
 }
-
-// FormatResultTable formats query results for console display
-func (m *Manager) FormatResultTable(result *query.QueryResult, maxRows int, maxWidth int) ([]string, error) {
-	if len(result.Rows) == 0 {
-		return []string{"No data returned"}, nil
-	}
-
-	// Build headers
-	headers := make([]string, 0, len(result.DimensionHeaders)+len(result.MetricHeaders))
-	for _, dim := range result.DimensionHeaders {
-		headers = append(headers, dim.Name)
-	}
-	for _, metric := range result.MetricHeaders {
-		headers = append(headers, metric.Name)
-	}
-
-	// Limit rows for display
-	displayRows := result.Rows
-	if maxRows > 0 && len(displayRows) > maxRows {
-		displayRows = displayRows[:maxRows]
-	}
-
-	// Calculate column widths
-	colWidths := make([]int, len(headers))
-	for i, header := range headers {
-		colWidths[i] = len(header)
-	}
-
-	// Check data for column widths
-	for _, row := range displayRows {
-		for i, dimValue := range row.DimensionValues {
-			if i < len(colWidths) && len(dimValue.Value) > colWidths[i] {
-				colWidths[i] = min(len(dimValue.Value), maxWidth)
-			}
-		}
-		for i, metricValue := range row.MetricValues {
-			colIndex := len(row.DimensionValues) + i
-			if colIndex < len(colWidths) && len(metricValue.Value) > colWidths[colIndex] {
-				colWidths[colIndex] = min(len(metricValue.Value), maxWidth)
-			}
-		}
-	}
-
-	var lines []string
-	
-	// Header line
-	headerParts := make([]string, len(headers))
-	for i, header := range headers {
-		headerParts[i] = padOrTruncate(header, colWidths[i])
-	}
-	lines = append(lines, "| "+strings.Join(headerParts, " | ")+" |")
-	
-	// Separator line
-	separatorParts := make([]string, len(headers))
-	for i, width := range colWidths {
-		separatorParts[i] = strings.Repeat("-", width)
-	}
-	lines = append(lines, "|"+strings.Join(separatorParts, "|")+"|")
-	
-	// Data lines
-	for _, row := range displayRows {
-		rowParts := make([]string, len(headers))
-		
-		// Dimension values
-		for i, dimValue := range row.DimensionValues {
-			if i < len(rowParts) {
-				rowParts[i] = padOrTruncate(dimValue.Value, colWidths[i])
-			}
-		}
-		
-		// Metric values
-		for i, metricValue := range row.MetricValues {
-			colIndex := len(row.DimensionValues) + i
-			if colIndex < len(rowParts) {
-				// Format numeric values
-				if val, err := strconv.ParseFloat(metricValue.Value, 64); err == nil {
-					if val == float64(int64(val)) {
-						rowParts[colIndex] = padOrTruncate(fmt.Sprintf("%.0f", val), colWidths[colIndex])
-					} else {
-						rowParts[colIndex] = padOrTruncate(fmt.Sprintf("%.2f", val), colWidths[colIndex])
-					}
-				} else {
-					rowParts[colIndex] = padOrTruncate(metricValue.Value, colWidths[colIndex])
-				}
-			}
-		}
-		
-		lines = append(lines, "| "+strings.Join(rowParts, " | ")+" |")
-	}
-
-	// Add summary if rows were truncated
-	if len(result.Rows) > maxRows {
-		lines = append(lines, "")
-		lines = append(lines, fmt.Sprintf("Showing %d of %d rows", maxRows, len(result.Rows)))
-	}
-
-	return lines, nil
-}
-
-// Helper functions
-func padOrTruncate(s string, width int) string {
-	if len(s) > width {
-		if width > 3 {
-			return s[:width-3] + "..."
-		}
-		return s[:width]
-	}
-	return s + strings.Repeat(" ", width-len(s))
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
@@ -14,6 +14,8 @@ type ResultSummary struct {
 	IsExpired    bool       `json:"is_expired"`
 	TableName    string     `json:"table_name,omitempty"`
 	Description  string     `json:"description,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Note         string     `json:"note,omitempty"`
 }
 
 // ResultStats represents statistics about cached results for a property
@@ -37,6 +39,7 @@ const (
 	FormatJSON ExportFormat = "json"
 	FormatTSV  ExportFormat = "tsv"
 	FormatXLSX ExportFormat = "xlsx"
+	FormatHTML ExportFormat = "html"
 )
 
 // ExportOptions represents options for data export
@@ -51,10 +54,12 @@ type ExportOptions struct {
 // TableDisplayOptions represents options for formatting console output
 type TableDisplayOptions struct {
 	MaxRows       int  `json:"max_rows"`        // Maximum rows to display
+	Offset        int  `json:"offset"`          // Rows to skip before the first displayed
 	MaxColWidth   int  `json:"max_col_width"`   // Maximum column width
 	ShowTotals    bool `json:"show_totals"`     // Show total/summary rows
 	ShowMetadata  bool `json:"show_metadata"`   // Show query metadata
 	NumberFormat  bool `json:"number_format"`   // Format numbers with commas
+	Markdown      bool `json:"markdown"`        // Render a markdown table
 }
 
 // DefaultDisplayOptions returns sensible defaults for table display
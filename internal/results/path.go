@@ -0,0 +1,69 @@
+package results
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ga4admin/internal/query"
+)
+
+// PathTokens lists the tokens ExpandPath understands, for help text and errors
+var PathTokens = []string{"{property}", "{query}", "{date}", "{time}", "{start_date}", "{end_date}"}
+
+var (
+	pathToken = regexp.MustCompile(`\{[^{}/\\]*\}`)
+
+	// Characters replaced when result values are expanded into a path
+	unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// HasPathTokens reports whether path contains any {token}
+func HasPathTokens(path string) bool {
+	return pathToken.MatchString(path)
+}
+
+// ExpandPath fills tokens in an export path from the result's metadata:
+// {property} and {query} are the property and query IDs, {date} and {time}
+// are when the result was fetched (YYYY-MM-DD, HHMMSS) and {start_date} and
+// {end_date} are the query's date range. Unknown tokens, and tokens the
+// result has no value for, are an error so a typo never silently becomes part
+// of a filename.
+func ExpandPath(pattern string, result *query.QueryResult) (string, error) {
+	var startDate, endDate string
+	if result.QueryConfig != nil {
+		startDate = result.QueryConfig.StartDate
+		endDate = result.QueryConfig.EndDate
+	}
+
+	values := map[string]string{
+		"{property}":   result.PropertyID,
+		"{query}":      result.QueryID,
+		"{date}":       result.ExecutedAt.Format("2006-01-02"),
+		"{time}":       result.ExecutedAt.Format("150405"),
+		"{start_date}": startDate,
+		"{end_date}":   endDate,
+	}
+
+	var unknown, empty []string
+	expanded := pathToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		value, ok := values[token]
+		if !ok {
+			unknown = append(unknown, token)
+			return token
+		}
+		if value == "" {
+			empty = append(empty, token)
+			return token
+		}
+		return unsafePathChars.ReplaceAllString(value, "_")
+	})
+
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown path token(s) %s (supported: %s)", strings.Join(unknown, ", "), strings.Join(PathTokens, ", "))
+	}
+	if len(empty) > 0 {
+		return "", fmt.Errorf("path token(s) %s have no value for this result", strings.Join(empty, ", "))
+	}
+	return expanded, nil
+}
@@ -0,0 +1,338 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/duckdb"
+	"ga4admin/internal/query"
+)
+
+// MaxPivotColumns caps how many distinct values a pivot dimension may have
+const MaxPivotColumns = 100
+
+// Pivot turns the values of one dimension into columns, one per value (and
+// metric, when there are several), with the remaining dimensions as rows. Cells are summed if a value
+// appears more than once for a row. Totals, minimums and maximums are dropped.
+func Pivot(ctx context.Context, result *query.QueryResult, dimension string) (*query.QueryResult, error) {
+	pivotIndex := -1
+	var names []string
+	for i, header := range result.DimensionHeaders {
+		names = append(names, header.Name)
+		if header.Name == dimension {
+			pivotIndex = i
+		}
+	}
+	if pivotIndex < 0 {
+		return nil, fmt.Errorf("result has no dimension '%s' (available: %s)", dimension, strings.Join(names, ", "))
+	}
+	if len(result.MetricHeaders) == 0 {
+		return nil, fmt.Errorf("result has no metrics to pivot")
+	}
+
+	var groupBy []string
+	for i, header := range result.DimensionHeaders {
+		if i != pivotIndex {
+			groupBy = append(groupBy, quoteIdent(header.Name))
+		}
+	}
+	// With one metric the columns are named after the values alone; with
+	// several, DuckDB names them "<value>_<alias>"
+	var using []string
+	for _, header := range result.MetricHeaders {
+		aggregate := fmt.Sprintf("sum(%s)", quoteIdent(header.Name))
+		if len(result.MetricHeaders) > 1 {
+			aggregate += " AS " + quoteIdent(header.Name)
+		}
+		using = append(using, aggregate)
+	}
+
+	statement := fmt.Sprintf("PIVOT (SELECT * EXCLUDE (__row) FROM result) ON %s USING %s", quoteIdent(dimension), strings.Join(using, ", "))
+	if len(groupBy) > 0 {
+		statement = fmt.Sprintf("SELECT * FROM (%s GROUP BY %s) ORDER BY %s", statement, strings.Join(groupBy, ", "), strings.Join(groupBy, ", "))
+	}
+
+	return reshape(ctx, result, func(conn *sql.Conn) (string, error) {
+		var distinct int
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT count(DISTINCT %s) FROM result", quoteIdent(dimension))).Scan(&distinct); err != nil {
+			return "", err
+		}
+		if distinct > MaxPivotColumns {
+			return "", fmt.Errorf("'%s' has %d distinct values; pivot supports at most %d", dimension, distinct, MaxPivotColumns)
+		}
+		return statement, nil
+	}, len(groupBy))
+}
+
+// Melt turns metric columns into rows: each input row becomes one row per
+// metric, with a "metric" dimension naming it and a "value" column.
+func Melt(ctx context.Context, result *query.QueryResult) (*query.QueryResult, error) {
+	if len(result.MetricHeaders) == 0 {
+		return nil, fmt.Errorf("result has no metrics to melt")
+	}
+
+	var columns, metrics, metricNames []string
+	for _, header := range result.DimensionHeaders {
+		if header.Name == "metric" || header.Name == "value" {
+			return nil, fmt.Errorf("can't melt a result that already has a '%s' dimension", header.Name)
+		}
+		columns = append(columns, quoteIdent(header.Name))
+	}
+	for _, header := range result.MetricHeaders {
+		metrics = append(metrics, quoteIdent(header.Name))
+		metricNames = append(metricNames, quoteLiteral(header.Name))
+	}
+	columns = append(columns, "metric", "value")
+
+	// Keep the input row order, and the metric order within each row
+	statement := fmt.Sprintf("SELECT %s FROM (UNPIVOT result ON %s INTO NAME metric VALUE value) ORDER BY __row, list_position([%s], metric)",
+		strings.Join(columns, ", "), strings.Join(metrics, ", "), strings.Join(metricNames, ", "))
+
+	return reshape(ctx, result, func(*sql.Conn) (string, error) {
+		return statement, nil
+	}, len(result.DimensionHeaders)+1)
+}
+
+// Derivation is a computed column: Name=Expression
+type Derivation struct {
+	Name       string
+	Expression string
+}
+
+var (
+	derivationName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	totalCall      = regexp.MustCompile(`\btotal\(`)
+)
+
+// ParseDerivation parses "name=expression", e.g. "share=sessions/total(sessions)*100"
+func ParseDerivation(value string) (Derivation, error) {
+	name, expression, ok := strings.Cut(value, "=")
+	name, expression = strings.TrimSpace(name), strings.TrimSpace(expression)
+	if !ok || expression == "" {
+		return Derivation{}, fmt.Errorf("invalid derived column '%s' (use name=expression)", value)
+	}
+	if !derivationName.MatchString(name) {
+		return Derivation{}, fmt.Errorf("invalid derived column name '%s' (use letters, digits and underscores)", name)
+	}
+	return Derivation{Name: name, Expression: expression}, nil
+}
+
+// Derive appends computed columns to a result. Expressions are DuckDB SQL
+// over the result's columns and earlier derived columns; total(x) is the sum
+// of x over all rows. Derived columns are added as metrics.
+func Derive(ctx context.Context, result *query.QueryResult, derivations []Derivation) (*query.QueryResult, error) {
+	existing := make(map[string]bool)
+	for _, header := range result.DimensionHeaders {
+		existing[strings.ToLower(header.Name)] = true
+	}
+	for _, header := range result.MetricHeaders {
+		existing[strings.ToLower(header.Name)] = true
+	}
+
+	from := "result"
+	for _, derivation := range derivations {
+		if existing[strings.ToLower(derivation.Name)] {
+			return nil, fmt.Errorf("derived column '%s' clashes with an existing column", derivation.Name)
+		}
+		existing[strings.ToLower(derivation.Name)] = true
+
+		expression := expandTotals(derivation.Expression)
+		from = fmt.Sprintf("(SELECT *, (%s) AS %s FROM %s)", expression, quoteIdent(derivation.Name), from)
+	}
+	statement := fmt.Sprintf("SELECT * EXCLUDE (__row) FROM %s ORDER BY __row", from)
+
+	return reshape(ctx, result, func(*sql.Conn) (string, error) {
+		return statement, nil
+	}, len(result.DimensionHeaders))
+}
+
+// expandTotals rewrites total(x) as a window sum over the whole result
+func expandTotals(expression string) string {
+	var b strings.Builder
+	for {
+		loc := totalCall.FindStringIndex(expression)
+		if loc == nil {
+			b.WriteString(expression)
+			return b.String()
+		}
+
+		// Find the matching parenthesis so total(a + (b * c)) works
+		depth, end := 1, -1
+		for i := loc[1]; i < len(expression) && end < 0; i++ {
+			switch expression[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			// Unbalanced; leave it for DuckDB to report
+			b.WriteString(expression)
+			return b.String()
+		}
+
+		b.WriteString(expression[:loc[0]])
+		b.WriteString("(sum(" + expression[loc[1]:end] + ") OVER ())")
+		expression = expression[end+1:]
+	}
+}
+
+// reshape loads result into a scratch in-memory DuckDB table named "result"
+// (dimensions as VARCHAR, metrics as DOUBLE, plus the original row index
+// __row) and runs the statement build returns against it. The first
+// dimensions columns of the output become dimensions, the rest metrics.
+func reshape(ctx context.Context, result *query.QueryResult, build func(*sql.Conn) (string, error), dimensions int) (*query.QueryResult, error) {
+	db, err := duckdb.Open("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+	defer conn.Close()
+
+	columns := []string{"__row BIGINT"}
+	for _, header := range result.DimensionHeaders {
+		columns = append(columns, quoteIdent(header.Name)+" VARCHAR")
+	}
+	for _, header := range result.MetricHeaders {
+		columns = append(columns, quoteIdent(header.Name)+" DOUBLE")
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE result (%s)", strings.Join(columns, ", "))); err != nil {
+		return nil, fmt.Errorf("failed to load result: %w", err)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	insert, err := conn.PrepareContext(ctx, fmt.Sprintf("INSERT INTO result VALUES (%s)", placeholders))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load result: %w", err)
+	}
+	defer insert.Close()
+
+	for i, row := range result.Rows {
+		values := make([]interface{}, 0, len(columns))
+		values = append(values, i)
+		for j := range result.DimensionHeaders {
+			value := ""
+			if j < len(row.DimensionValues) {
+				value = row.DimensionValues[j].Value
+			}
+			values = append(values, value)
+		}
+		for j := range result.MetricHeaders {
+			var value interface{}
+			if j < len(row.MetricValues) {
+				if number, err := strconv.ParseFloat(row.MetricValues[j].Value, 64); err == nil {
+					value = number
+				}
+			}
+			values = append(values, value)
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return nil, fmt.Errorf("failed to load result: %w", err)
+		}
+	}
+
+	statement, err := build(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform result: %w", err)
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	reshaped := *result
+	reshaped.DimensionHeaders = nil
+	reshaped.MetricHeaders = nil
+	reshaped.Rows = nil
+	reshaped.Totals, reshaped.Minimums, reshaped.Maximums = nil, nil, nil
+	for i, name := range names {
+		if i < dimensions {
+			reshaped.DimensionHeaders = append(reshaped.DimensionHeaders, api.DimensionHeader{Name: name})
+		} else {
+			reshaped.MetricHeaders = append(reshaped.MetricHeaders, api.MetricHeader{Name: name, Type: metricType(result.MetricHeaders, name)})
+		}
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		pointers := make([]interface{}, len(names))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to read transformed result: %w", err)
+		}
+
+		var row api.Row
+		for i, value := range values {
+			if i < dimensions {
+				row.DimensionValues = append(row.DimensionValues, api.DimensionValue{Value: cellString(value)})
+			} else {
+				row.MetricValues = append(row.MetricValues, api.MetricValue{Value: cellString(value)})
+			}
+		}
+		reshaped.Rows = append(reshaped.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transformed result: %w", err)
+	}
+
+	reshaped.RowCount = len(reshaped.Rows)
+	return &reshaped, nil
+}
+
+// metricType finds the GA4 type of a reshaped column: pivot columns are named
+// "<value>_<metric>" when there are several metrics, and just "<value>" with one
+func metricType(headers []api.MetricHeader, column string) string {
+	if len(headers) == 1 {
+		return headers[0].Type
+	}
+	for _, header := range headers {
+		if column == header.Name || strings.HasSuffix(column, "_"+header.Name) {
+			return header.Type
+		}
+	}
+	return ""
+}
+
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,474 @@
+package results
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/cache"
+	"ga4admin/internal/config"
+	"ga4admin/internal/query"
+)
+
+// ErrInvalidRollup means a rollup's name, columns or aggregations were rejected
+var ErrInvalidRollup = errors.New("invalid rollup")
+
+// rollupName keeps rollup names usable unquoted in the SQL of dashboards
+// reading their rollup_<name> tables
+var rollupName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Rollup aggregations
+const (
+	RollupSum = "sum"
+	RollupAvg = "avg"
+	RollupMin = "min"
+	RollupMax = "max"
+)
+
+// Rollup statuses
+const (
+	RollupFresh    = "fresh"    // Built from the result the source refers to now
+	RollupOutdated = "outdated" // The source refers to a newer result; rebuild from the cache
+	RollupStale    = "stale"    // The source's data is older than the refresh interval; re-fetch it
+	RollupBroken   = "broken"   // The source no longer exists
+)
+
+// ParseRollupMetrics parses metrics given as "name" (summed) or
+// "name:aggregation", e.g. "sessions" or "bounceRate:avg"
+func ParseRollupMetrics(values []string) ([]config.RollupMetric, error) {
+	var metrics []config.RollupMetric
+	seen := make(map[string]bool)
+	for _, value := range values {
+		name, aggregation, _ := strings.Cut(strings.TrimSpace(value), ":")
+		if aggregation == "" {
+			aggregation = RollupSum
+		}
+		aggregation = strings.ToLower(aggregation)
+		switch aggregation {
+		case RollupSum, RollupAvg, RollupMin, RollupMax:
+		default:
+			return nil, fmt.Errorf("%w: unknown aggregation '%s' for %s (use sum, avg, min or max)", ErrInvalidRollup, aggregation, name)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%w: empty metric in '%s'", ErrInvalidRollup, value)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidRollup, name)
+		}
+		seen[name] = true
+		metrics = append(metrics, config.RollupMetric{Name: name, Aggregation: aggregation})
+	}
+	return metrics, nil
+}
+
+// FormatRollupMetrics is the "name:aggregation" form of a rollup's metrics
+func FormatRollupMetrics(metrics []config.RollupMetric) string {
+	parts := make([]string, len(metrics))
+	for i, metric := range metrics {
+		parts[i] = metric.Name + ":" + metric.Aggregation
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Aggregate groups a result's rows by the groupBy dimensions and combines
+// each metric with its aggregation. Groups are ordered by their values.
+// Metric types are the result's; averages are floats.
+func Aggregate(result *query.QueryResult, groupBy []string, metrics []config.RollupMetric) (*query.QueryResult, error) {
+	dimensionIndex := make(map[string]int)
+	for i, header := range result.DimensionHeaders {
+		dimensionIndex[header.Name] = i
+	}
+	metricIndex := make(map[string]int)
+	for i, header := range result.MetricHeaders {
+		metricIndex[header.Name] = i
+	}
+
+	groupCols := make([]int, len(groupBy))
+	seen := make(map[string]bool)
+	for i, name := range groupBy {
+		col, ok := dimensionIndex[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: can't group by '%s', which is not a dimension of the result", ErrInvalidRollup, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is grouped by twice", ErrInvalidRollup, name)
+		}
+		seen[name] = true
+		groupCols[i] = col
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("%w: no metrics to aggregate", ErrInvalidRollup)
+	}
+
+	metricCols := make([]int, len(metrics))
+	headers := make([]api.MetricHeader, len(metrics))
+	for i, metric := range metrics {
+		col, ok := metricIndex[metric.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s' is not a metric of the result", ErrInvalidRollup, metric.Name)
+		}
+		if seen[metric.Name] {
+			return nil, fmt.Errorf("%w: %s is both grouped by and aggregated", ErrInvalidRollup, metric.Name)
+		}
+		metricCols[i] = col
+		headers[i] = api.MetricHeader{Name: metric.Name, Type: result.MetricHeaders[col].Type}
+		if metric.Aggregation == RollupAvg {
+			headers[i].Type = "TYPE_FLOAT"
+		}
+	}
+
+	type group struct {
+		keys   []string
+		values []float64
+		counts []int
+	}
+	groups := make(map[string]*group)
+	var order []*group
+	for _, row := range result.Rows {
+		keys := make([]string, len(groupCols))
+		for i, col := range groupCols {
+			if col < len(row.DimensionValues) {
+				keys[i] = row.DimensionValues[col].Value
+			}
+		}
+		key := strings.Join(keys, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{keys: keys, values: make([]float64, len(metrics)), counts: make([]int, len(metrics))}
+			groups[key] = g
+			order = append(order, g)
+		}
+
+		for i, col := range metricCols {
+			if col >= len(row.MetricValues) {
+				continue
+			}
+			value, err := strconv.ParseFloat(row.MetricValues[col].Value, 64)
+			if err != nil || math.IsNaN(value) {
+				continue
+			}
+			switch {
+			case g.counts[i] == 0:
+				g.values[i] = value
+			case metrics[i].Aggregation == RollupMin:
+				g.values[i] = math.Min(g.values[i], value)
+			case metrics[i].Aggregation == RollupMax:
+				g.values[i] = math.Max(g.values[i], value)
+			default:
+				g.values[i] += value
+			}
+			g.counts[i]++
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		for k := range groupCols {
+			if order[i].keys[k] != order[j].keys[k] {
+				return order[i].keys[k] < order[j].keys[k]
+			}
+		}
+		return false
+	})
+
+	aggregated := &query.QueryResult{
+		QueryID:       result.QueryID,
+		PropertyID:    result.PropertyID,
+		QueryHash:     result.QueryHash,
+		ExecutedAt:    result.ExecutedAt,
+		ExecutionTime: result.ExecutionTime,
+		FromCache:     result.FromCache,
+		CachedAt:      result.CachedAt,
+		MetricHeaders: headers,
+	}
+	for _, name := range groupBy {
+		aggregated.DimensionHeaders = append(aggregated.DimensionHeaders, api.DimensionHeader{Name: name})
+	}
+	for _, g := range order {
+		row := api.Row{}
+		for _, key := range g.keys {
+			row.DimensionValues = append(row.DimensionValues, api.DimensionValue{Value: key})
+		}
+		for i, value := range g.values {
+			if metrics[i].Aggregation == RollupAvg && g.counts[i] > 0 {
+				value /= float64(g.counts[i])
+			}
+			row.MetricValues = append(row.MetricValues, api.MetricValue{Value: strconv.FormatFloat(value, 'f', -1, 64)})
+		}
+		aggregated.Rows = append(aggregated.Rows, row)
+	}
+	aggregated.RowCount = len(aggregated.Rows)
+	return aggregated, nil
+}
+
+// rollupTable converts an aggregated result to the columns and rows of a
+// rollup table: the date dimension as a DATE, other dimensions as text,
+// integer metrics as BIGINT and the rest as DOUBLE
+func rollupTable(result *query.QueryResult) ([]cache.RollupColumn, [][]interface{}) {
+	var columns []cache.RollupColumn
+	for _, header := range result.DimensionHeaders {
+		columnType := "VARCHAR"
+		if header.Name == "date" {
+			columnType = "DATE"
+		}
+		columns = append(columns, cache.RollupColumn{Name: header.Name, Type: columnType})
+	}
+	for _, header := range result.MetricHeaders {
+		columnType := "DOUBLE"
+		if header.Type == "TYPE_INTEGER" {
+			columnType = "BIGINT"
+		}
+		columns = append(columns, cache.RollupColumn{Name: header.Name, Type: columnType})
+	}
+
+	rows := make([][]interface{}, len(result.Rows))
+	for i, row := range result.Rows {
+		values := make([]interface{}, 0, len(columns))
+		for j, dimension := range row.DimensionValues {
+			value := dimension.Value
+			// GA4 dates are YYYYMMDD; both cache backends read YYYY-MM-DD as a date
+			if columns[j].Type == "DATE" && len(value) == 8 {
+				value = value[:4] + "-" + value[4:6] + "-" + value[6:]
+			}
+			values = append(values, value)
+		}
+		for j, metric := range row.MetricValues {
+			var value interface{}
+			if number, err := strconv.ParseFloat(metric.Value, 64); err == nil {
+				value = number
+				if columns[len(row.DimensionValues)+j].Type == "BIGINT" {
+					value = int64(math.Round(number))
+				}
+			}
+			values = append(values, value)
+		}
+		rows[i] = values
+	}
+	return columns, rows
+}
+
+// resolveRollupSource returns the result a rollup's source refers to now: the
+// result of a named table, a view or a result ID
+func (m *Manager) resolveRollupSource(ctx context.Context, source string) (*query.QueryResult, error) {
+	queryID, _, err := m.cacheClient.LookupNamedTable(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if queryID == "" {
+		queryID = source
+	}
+
+	result, err := m.GetResult(ctx, queryID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: '%s' is not a named table, view or cached result", ErrNotFound, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.RollupName != "" {
+		return nil, fmt.Errorf("%w: '%s' is itself a rollup; roll up results", ErrInvalidRollup, source)
+	}
+	return result, nil
+}
+
+// CreateRollup saves a rollup of a named table, view or result and builds
+// its table
+func (m *Manager) CreateRollup(ctx context.Context, rollup config.Rollup) (*config.Rollup, error) {
+	if !rollupName.MatchString(rollup.Name) {
+		return nil, fmt.Errorf("%w: invalid name '%s' (use letters, digits and '_', starting with a letter)", ErrInvalidRollup, rollup.Name)
+	}
+	if rollup.RefreshEvery < 0 {
+		return nil, fmt.Errorf("%w: the refresh interval can't be negative", ErrInvalidRollup)
+	}
+
+	source, err := m.resolveRollupSource(ctx, rollup.Source)
+	if err != nil {
+		return nil, err
+	}
+	aggregated, err := m.buildRollup(&rollup, source)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, rows := rollupTable(aggregated)
+	if err := m.cacheClient.CreateRollup(ctx, &rollup, columns, rows); err != nil {
+		return nil, err
+	}
+	return &rollup, nil
+}
+
+// RebuildRollup rebuilds a rollup's table from the result its source refers
+// to now
+func (m *Manager) RebuildRollup(ctx context.Context, rollup *config.Rollup) error {
+	source, err := m.resolveRollupSource(ctx, rollup.Source)
+	if err != nil {
+		return err
+	}
+	aggregated, err := m.buildRollup(rollup, source)
+	if err != nil {
+		return err
+	}
+
+	columns, rows := rollupTable(aggregated)
+	return m.cacheClient.StoreRollup(ctx, rollup, columns, rows)
+}
+
+// buildRollup aggregates source for rollup and records where the data came from
+func (m *Manager) buildRollup(rollup *config.Rollup, source *query.QueryResult) (*query.QueryResult, error) {
+	aggregated, err := Aggregate(source, rollup.GroupBy, rollup.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	rollup.PropertyID = source.PropertyID
+	metrics := make([]config.RollupMetric, len(rollup.Metrics))
+	for i, metric := range rollup.Metrics {
+		metrics[i] = metric
+		metrics[i].Type = aggregated.MetricHeaders[i].Type
+	}
+	rollup.Metrics = metrics
+	rollup.SourceQueryID = source.QueryID
+	rollup.SourceFetchedAt = source.ExecutedAt
+	if source.CachedAt != nil {
+		rollup.SourceFetchedAt = *source.CachedAt
+	}
+	return aggregated, nil
+}
+
+// RollupStatus reports whether a rollup's table is current. A rollup is
+// outdated when its source refers to another result than it was built from,
+// and stale when its data was fetched longer ago than its refresh interval.
+func (m *Manager) RollupStatus(ctx context.Context, rollup *config.Rollup, now time.Time) (string, error) {
+	queryID, _, err := m.cacheClient.LookupNamedTable(ctx, rollup.Source)
+	if err != nil {
+		return "", err
+	}
+	if queryID == "" {
+		view, err := m.cacheClient.GetResultView(ctx, rollup.Source)
+		if err != nil {
+			return "", err
+		}
+		queryID = rollup.Source
+		if view != nil {
+			queryID = view.QueryID
+		}
+	}
+	cachedAt, found, err := m.cacheClient.QueryCachedAt(ctx, queryID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case !found:
+		return RollupBroken, nil
+	case queryID != rollup.SourceQueryID:
+		// A newer result that is itself too old still needs a fetch
+		if rollup.RefreshEvery > 0 && now.Sub(cachedAt) >= rollup.RefreshEvery {
+			return RollupStale, nil
+		}
+		return RollupOutdated, nil
+	case rollup.RefreshEvery > 0 && now.Sub(rollup.SourceFetchedAt) >= rollup.RefreshEvery:
+		return RollupStale, nil
+	}
+	return RollupFresh, nil
+}
+
+// RefetchRollupSource fetches a rollup's named table again from GA4, skipping
+// the cache, and points the name at the new result. Rollups of views and
+// result IDs can't be re-fetched, as those refer to one fixed result.
+func (m *Manager) RefetchRollupSource(ctx context.Context, dataClient api.DataService, rollup *config.Rollup) error {
+	queryID, description, err := m.cacheClient.LookupNamedTable(ctx, rollup.Source)
+	if err != nil {
+		return err
+	}
+	if queryID == "" {
+		return fmt.Errorf("rollup '%s' is of '%s', which is not a named table; only named tables can be re-fetched", rollup.Name, rollup.Source)
+	}
+
+	var request api.RunReportRequest
+	var response api.RunReportResponse
+	entry, err := m.cacheClient.GetQuery(ctx, queryID, &request, &response)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("%w: named table '%s' refers to result %s, which is no longer cached", ErrNotFound, rollup.Source, queryID)
+	}
+	// The request isn't stored with its property, so restore it from the entry
+	request.Property = entry.PropertyID
+
+	fetched, err := dataClient.RunReport(api.WithoutCache(ctx), &request)
+	if err != nil {
+		return fmt.Errorf("failed to re-fetch '%s': %w", rollup.Source, err)
+	}
+	if fetched.QueryID == "" {
+		return fmt.Errorf("failed to re-fetch '%s': the result was not cached", rollup.Source)
+	}
+	_, err = m.cacheClient.CreateNamedTable(ctx, rollup.Source, entry.PropertyID, fetched.QueryID, description, cache.NameConflictOverwrite)
+	return err
+}
+
+// getRollup reads a rollup's table as a result
+func (m *Manager) getRollup(ctx context.Context, name string) (*query.QueryResult, error) {
+	rollup, err := m.cacheClient.GetRollup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if rollup == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	_, rows, err := m.cacheClient.ReadRollupTable(ctx, rollup)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshedAt := rollup.RefreshedAt
+	result := &query.QueryResult{
+		QueryID:    rollup.SourceQueryID,
+		PropertyID: rollup.PropertyID,
+		RollupName: rollup.Name,
+		ExecutedAt: rollup.SourceFetchedAt,
+		FromCache:  true,
+		CachedAt:   &refreshedAt,
+		QueryConfig: &query.QueryConfig{
+			PropertyID: rollup.PropertyID,
+			Dimensions: rollup.GroupBy,
+		},
+	}
+	for _, name := range rollup.GroupBy {
+		result.DimensionHeaders = append(result.DimensionHeaders, api.DimensionHeader{Name: name})
+	}
+	for _, metric := range rollup.Metrics {
+		result.MetricHeaders = append(result.MetricHeaders, api.MetricHeader{Name: metric.Name, Type: metric.Type})
+		result.QueryConfig.Metrics = append(result.QueryConfig.Metrics, metric.Name)
+	}
+
+	dimensions := len(rollup.GroupBy)
+	for _, values := range rows {
+		row := api.Row{}
+		for i, value := range values {
+			if i < dimensions {
+				if rollup.GroupBy[i] == "date" {
+					// Back to GA4's YYYYMMDD, as in every other result
+					value = strings.ReplaceAll(value, "-", "")
+				}
+				row.DimensionValues = append(row.DimensionValues, api.DimensionValue{Value: value})
+				continue
+			}
+			// DuckDB prints whole doubles as "12.0"
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				value = strconv.FormatFloat(number, 'f', -1, 64)
+			}
+			row.MetricValues = append(row.MetricValues, api.MetricValue{Value: value})
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
+}
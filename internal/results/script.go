@@ -0,0 +1,361 @@
+package results
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/query"
+)
+
+// MaxScriptSteps bounds the work a transform script may do, so a runaway
+// loop fails instead of hanging the command
+const MaxScriptSteps = 100_000_000
+
+// Entry points a transform script defines, exactly one of them:
+//
+//	def transform(row): ...       # called per row
+//	def transform_rows(rows): ... # called once with every row
+const (
+	rowFunction  = "transform"
+	rowsFunction = "transform_rows"
+)
+
+// Transform runs a Starlark script over a result's rows and builds a result
+// from the rows it emits. Rows are dicts of column name to value: dimensions
+// are strings, metrics ints or floats (None when empty). transform(row)
+// returns a dict, a list of dicts, or None to drop the row; transform_rows
+// returns a list of dicts. Output columns keep the input order, with new
+// columns after them; a new column is a metric when all its values are
+// numbers, otherwise a dimension.
+//
+// Scripts are sandboxed: Starlark has no file, network or clock access.
+// Besides the language built-ins they get the math module, re.match, re.find
+// and re.sub over Go regular expressions, and print, which writes to stderr.
+func Transform(ctx context.Context, result *query.QueryResult, scriptPath string) (*query.QueryResult, error) {
+	source, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	thread := &starlark.Thread{
+		Name:  "transform",
+		Print: func(_ *starlark.Thread, msg string) { fmt.Fprintln(os.Stderr, msg) },
+	}
+	thread.SetMaxExecutionSteps(MaxScriptSteps)
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{While: true, TopLevelControl: true}, thread, scriptPath, source, scriptBuiltins())
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	rowFn, perRow := globals[rowFunction].(starlark.Callable)
+	rowsFn, allRows := globals[rowsFunction].(starlark.Callable)
+	switch {
+	case perRow && allRows:
+		return nil, fmt.Errorf("%s defines both %s(row) and %s(rows); keep one", scriptPath, rowFunction, rowsFunction)
+	case !perRow && !allRows:
+		return nil, fmt.Errorf("%s must define %s(row) or %s(rows)", scriptPath, rowFunction, rowsFunction)
+	}
+
+	input := make([]starlark.Value, len(result.Rows))
+	for i, row := range result.Rows {
+		input[i] = rowDict(result, row)
+	}
+
+	var emitted []*starlark.Dict
+	if perRow {
+		for i, row := range input {
+			value, err := starlark.Call(thread, rowFn, starlark.Tuple{row}, nil)
+			if err != nil {
+				return nil, scriptError(err)
+			}
+			dicts, err := emittedRows(value, true)
+			if err != nil {
+				return nil, fmt.Errorf("%s(row) for row %d: %w", rowFunction, i+1, err)
+			}
+			emitted = append(emitted, dicts...)
+		}
+	} else {
+		value, err := starlark.Call(thread, rowsFn, starlark.Tuple{starlark.NewList(input)}, nil)
+		if err != nil {
+			return nil, scriptError(err)
+		}
+		if emitted, err = emittedRows(value, false); err != nil {
+			return nil, fmt.Errorf("%s(rows): %w", rowsFunction, err)
+		}
+	}
+
+	return buildTransformed(result, emitted)
+}
+
+// rowDict turns a result row into the dict a script receives
+func rowDict(result *query.QueryResult, row api.Row) *starlark.Dict {
+	dict := starlark.NewDict(len(result.DimensionHeaders) + len(result.MetricHeaders))
+	for i, header := range result.DimensionHeaders {
+		value := ""
+		if i < len(row.DimensionValues) {
+			value = row.DimensionValues[i].Value
+		}
+		dict.SetKey(starlark.String(header.Name), starlark.String(value))
+	}
+	for i, header := range result.MetricHeaders {
+		var value starlark.Value = starlark.None
+		if i < len(row.MetricValues) {
+			raw := row.MetricValues[i].Value
+			if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				value = starlark.MakeInt64(n)
+			} else if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				value = starlark.Float(f)
+			}
+		}
+		dict.SetKey(starlark.String(header.Name), value)
+	}
+	return dict
+}
+
+// emittedRows reads what a script returned: a list of dicts, or for
+// per-row calls also a single dict or None
+func emittedRows(value starlark.Value, single bool) ([]*starlark.Dict, error) {
+	if single {
+		switch v := value.(type) {
+		case starlark.NoneType:
+			return nil, nil
+		case *starlark.Dict:
+			return []*starlark.Dict{v}, nil
+		}
+	}
+
+	list, ok := value.(*starlark.List)
+	if !ok {
+		if single {
+			return nil, fmt.Errorf("returned %s, expected a dict, a list of dicts or None", value.Type())
+		}
+		return nil, fmt.Errorf("returned %s, expected a list of dicts", value.Type())
+	}
+	dicts := make([]*starlark.Dict, list.Len())
+	for i := range dicts {
+		dict, ok := list.Index(i).(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("item %d is %s, expected a dict", i+1, list.Index(i).Type())
+		}
+		dicts[i] = dict
+	}
+	return dicts, nil
+}
+
+// buildTransformed lays the emitted rows out as a result. Totals, minimums
+// and maximums no longer add up, so they are dropped.
+func buildTransformed(result *query.QueryResult, rows []*starlark.Dict) (*query.QueryResult, error) {
+	// Columns in input order, then new ones in the order they first appear
+	var order []string
+	seen := make(map[string]bool)
+	if len(rows) == 0 {
+		// Nothing to infer columns from; keep the input's
+		for _, header := range result.DimensionHeaders {
+			seen[header.Name] = true
+		}
+		for _, header := range result.MetricHeaders {
+			seen[header.Name] = true
+		}
+	}
+	for _, row := range rows {
+		for _, key := range row.Keys() {
+			name, ok := starlark.AsString(key)
+			if !ok {
+				return nil, fmt.Errorf("row key %s is not a string", key)
+			}
+			seen[name] = true
+		}
+	}
+	isDimension := make(map[string]bool)
+	metricTypes := make(map[string]string)
+	for _, header := range result.DimensionHeaders {
+		isDimension[header.Name] = true
+		if seen[header.Name] {
+			order = append(order, header.Name)
+		}
+	}
+	for _, header := range result.MetricHeaders {
+		metricTypes[header.Name] = header.Type
+		if seen[header.Name] {
+			order = append(order, header.Name)
+		}
+	}
+	known := make(map[string]bool, len(order))
+	for _, name := range order {
+		known[name] = true
+	}
+	for _, row := range rows {
+		for _, key := range row.Keys() {
+			name, _ := starlark.AsString(key)
+			if known[name] {
+				continue
+			}
+			known[name] = true
+			order = append(order, name)
+			isDimension[name] = !numericColumn(rows, name)
+		}
+	}
+
+	transformed := *result
+	transformed.DimensionHeaders, transformed.MetricHeaders, transformed.Rows = nil, nil, nil
+	transformed.Totals, transformed.Minimums, transformed.Maximums = nil, nil, nil
+	var dimensions, metrics []string
+	for _, name := range order {
+		if isDimension[name] {
+			dimensions = append(dimensions, name)
+			transformed.DimensionHeaders = append(transformed.DimensionHeaders, api.DimensionHeader{Name: name})
+		} else {
+			metrics = append(metrics, name)
+			transformed.MetricHeaders = append(transformed.MetricHeaders, api.MetricHeader{Name: name, Type: metricTypes[name]})
+		}
+	}
+
+	for i, dict := range rows {
+		var row api.Row
+		for _, name := range dimensions {
+			value, err := cellValue(dict, name)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			row.DimensionValues = append(row.DimensionValues, api.DimensionValue{Value: value})
+		}
+		for _, name := range metrics {
+			value, err := cellValue(dict, name)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			row.MetricValues = append(row.MetricValues, api.MetricValue{Value: value})
+		}
+		transformed.Rows = append(transformed.Rows, row)
+	}
+	transformed.RowCount = len(transformed.Rows)
+	return &transformed, nil
+}
+
+// numericColumn reports whether every value a new column has is a number
+func numericColumn(rows []*starlark.Dict, name string) bool {
+	for _, row := range rows {
+		value, found, _ := row.Get(starlark.String(name))
+		if !found || value == starlark.None {
+			continue
+		}
+		switch value.(type) {
+		case starlark.Int, starlark.Float:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func cellValue(row *starlark.Dict, name string) (string, error) {
+	value, found, _ := row.Get(starlark.String(name))
+	if !found {
+		return "", nil
+	}
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return "", nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		return v.String(), nil
+	case starlark.Float:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+	case starlark.Bool:
+		return strconv.FormatBool(bool(v)), nil
+	}
+	return "", fmt.Errorf("column '%s' is %s; use a string, number, bool or None", name, value.Type())
+}
+
+// scriptError adds the script's stack to Starlark runtime errors
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return fmt.Errorf("script failed: %s", evalErr.Backtrace())
+	}
+	return fmt.Errorf("script failed: %w", err)
+}
+
+func scriptBuiltins() starlark.StringDict {
+	re := &starlarkstruct.Module{
+		Name: "re",
+		Members: starlark.StringDict{
+			"match": starlark.NewBuiltin("re.match", reMatch),
+			"find":  starlark.NewBuiltin("re.find", reFind),
+			"sub":   starlark.NewBuiltin("re.sub", reSub),
+		},
+	}
+	return starlark.StringDict{"math": math.Module, "re": re}
+}
+
+// regexpCache keeps scripts that match per row from recompiling patterns
+var regexpCache = make(map[string]*regexp.Regexp)
+
+func compileScriptRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
+
+// re.match(pattern, s) reports whether s contains a match of pattern
+func reMatch(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &s); err != nil {
+		return nil, err
+	}
+	re, err := compileScriptRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.Bool(re.MatchString(s)), nil
+}
+
+// re.find(pattern, s) returns the first match of pattern in s, or None
+func reFind(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &s); err != nil {
+		return nil, err
+	}
+	re, err := compileScriptRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return starlark.None, nil
+	}
+	return starlark.String(s[loc[0]:loc[1]]), nil
+}
+
+// re.sub(pattern, repl, s) replaces every match of pattern; repl may use $1
+func reSub(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, repl, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &pattern, &repl, &s); err != nil {
+		return nil, err
+	}
+	re, err := compileScriptRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.String(re.ReplaceAllString(s, repl)), nil
+}
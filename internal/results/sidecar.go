@@ -0,0 +1,107 @@
+package results
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"ga4admin/internal/query"
+)
+
+// QuerySidecarPath returns the path of the query definition written next to
+// an export, e.g. report.query.yaml for report.csv
+func QuerySidecarPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".query.yaml"
+}
+
+// WriteQuerySidecar writes the query behind a result as YAML that 'query run
+// --file' accepts. Relative dates such as 7daysAgo are replaced by the dates
+// they meant when the result was fetched, in the property's time zone, so
+// running the file again asks for the same data; a comment header records
+// the result, when it was fetched and the original dates.
+func WriteQuerySidecar(result *query.QueryResult, outputPath string) error {
+	if result.QueryConfig == nil {
+		return fmt.Errorf("result %s has no stored query", result.QueryID)
+	}
+
+	fetchedAt := result.ExecutedAt
+	if result.CachedAt != nil {
+		fetchedAt = *result.CachedAt
+	}
+	loc, timeZone := time.Local, ""
+	if result.ResponseMetadata != nil && result.ResponseMetadata.TimeZone != "" {
+		if zone, err := time.LoadLocation(result.ResponseMetadata.TimeZone); err == nil {
+			loc, timeZone = zone, result.ResponseMetadata.TimeZone
+		}
+	}
+
+	config := *result.QueryConfig
+	var header strings.Builder
+	fmt.Fprintf(&header, "# Query behind result %s of property %s\n", result.QueryID, result.PropertyID)
+	fmt.Fprintf(&header, "# Fetched %s\n", fetchedAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+	for _, date := range []*string{&config.StartDate, &config.EndDate} {
+		if *date == "" {
+			continue
+		}
+		resolved, err := query.ResolveDate(*date, fetchedAt, loc)
+		if err != nil {
+			return err
+		}
+		if value := resolved.Format("2006-01-02"); value != *date {
+			fmt.Fprintf(&header, "# %s was %s\n", *date, value)
+			*date = value
+		}
+	}
+	if timeZone != "" {
+		fmt.Fprintf(&header, "# Dates are in the property's time zone, %s\n", timeZone)
+	}
+	fmt.Fprintf(&header, "# Run it again with: ga4admin query run --file %s\n", filepath.Base(QuerySidecarPath(outputPath)))
+
+	var node yaml.Node
+	if err := node.Encode(&config); err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+	// Queries written by hand have no timestamps; leave them out rather than
+	// writing zero times
+	if config.CreatedAt.IsZero() {
+		removeKey(&node, "created_at")
+	}
+	if config.UpdatedAt.IsZero() {
+		removeKey(&node, "updated_at")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header.String())
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	path := QuerySidecarPath(outputPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write query file: %w", err)
+	}
+	return nil
+}
+
+// removeKey deletes key and its value from a YAML mapping node
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"ga4admin/internal/api"
+)
+
+// StreamWriter appends report pages to a CSV file as they arrive. Data goes to
+// "<path>.partial" and is renamed into place by Close, so an interrupted
+// export never looks complete.
+type StreamWriter struct {
+	path        string
+	file        *os.File
+	writer      *csv.Writer
+	hash        hash.Hash
+	rows        int64
+	wroteHeader bool
+}
+
+// NewStreamWriter creates the partial output file for a streamed export
+func NewStreamWriter(outputPath string) (*StreamWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := os.Create(outputPath + ".partial")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CSV file: %w", err)
+	}
+
+	// Everything above the footer feeds the checksum
+	h := sha256.New()
+	return &StreamWriter{
+		path:   outputPath,
+		file:   file,
+		writer: csv.NewWriter(io.MultiWriter(file, h)),
+		hash:   h,
+	}, nil
+}
+
+// WritePage writes the header (on the first page) and the page's rows, then
+// flushes so memory use stays bounded by one page
+func (w *StreamWriter) WritePage(page *api.RunReportResponse) error {
+	if !w.wroteHeader {
+		headers := make([]string, 0, len(page.DimensionHeaders)+len(page.MetricHeaders))
+		for _, dim := range page.DimensionHeaders {
+			headers = append(headers, dim.Name)
+		}
+		for _, metric := range page.MetricHeaders {
+			headers = append(headers, metric.Name)
+		}
+		if err := w.writer.Write(headers); err != nil {
+			return fmt.Errorf("failed to write CSV headers: %w", err)
+		}
+		w.wroteHeader = true
+	}
+
+	for _, row := range page.Rows {
+		record := make([]string, 0, len(row.DimensionValues)+len(row.MetricValues))
+		for _, dimValue := range row.DimensionValues {
+			record = append(record, dimValue.Value)
+		}
+		for _, metricValue := range row.MetricValues {
+			record = append(record, metricValue.Value)
+		}
+		if err := w.writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	w.rows += int64(len(page.Rows))
+
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV rows: %w", err)
+	}
+	return nil
+}
+
+// Rows returns the number of data rows written so far
+func (w *StreamWriter) Rows() int64 {
+	return w.rows
+}
+
+// Close writes the footer line "# rows=<n> sha256=<hex>", where the checksum
+// covers every byte above the footer, and moves the file into place.
+// It returns the checksum.
+func (w *StreamWriter) Close() (string, error) {
+	checksum := hex.EncodeToString(w.hash.Sum(nil))
+	if _, err := fmt.Fprintf(w.file, "# rows=%d sha256=%s\n", w.rows, checksum); err != nil {
+		w.file.Close()
+		return "", fmt.Errorf("failed to write footer: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return "", fmt.Errorf("failed to close CSV file: %w", err)
+	}
+	if err := os.Rename(w.path+".partial", w.path); err != nil {
+		return "", fmt.Errorf("failed to move export into place: %w", err)
+	}
+	return checksum, nil
+}
+
+// Abort closes the file and leaves the partial export for inspection
+func (w *StreamWriter) Abort() {
+	w.writer.Flush()
+	w.file.Close()
+}
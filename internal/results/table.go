@@ -0,0 +1,252 @@
+package results
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+
+	"ga4admin/internal/query"
+)
+
+// RenderTable lays out query results as a text or markdown table. Numeric
+// metric columns are right-aligned, metric values get thousands separators when
+// opts.NumberFormat is set, and cells longer than opts.MaxColWidth terminal
+// columns are truncated (0 disables truncation).
+func RenderTable(result *query.QueryResult, opts TableDisplayOptions) []string {
+	if len(result.Rows) == 0 {
+		return []string{"No data returned"}
+	}
+
+	headers := make([]string, 0, len(result.DimensionHeaders)+len(result.MetricHeaders))
+	for _, dim := range result.DimensionHeaders {
+		headers = append(headers, dim.Name)
+	}
+	for _, metric := range result.MetricHeaders {
+		headers = append(headers, metric.Name)
+	}
+
+	displayRows := result.Rows
+	if opts.Offset > 0 {
+		if opts.Offset > len(displayRows) {
+			opts.Offset = len(displayRows)
+		}
+		displayRows = displayRows[opts.Offset:]
+	}
+	if opts.MaxRows > 0 && len(displayRows) > opts.MaxRows {
+		displayRows = displayRows[:opts.MaxRows]
+	}
+
+	// Collect cell text first so widths and alignment reflect what's printed.
+	// Only metric columns are right-aligned; numeric-looking dimensions such
+	// as date or hour are identifiers.
+	cells := make([][]string, len(displayRows))
+	numeric := make([]bool, len(headers))
+	for i := len(result.DimensionHeaders); i < len(numeric); i++ {
+		numeric[i] = true
+	}
+	for r, row := range displayRows {
+		cells[r] = make([]string, len(headers))
+		for i, value := range row.DimensionValues {
+			if i < len(headers) {
+				cells[r][i] = value.Value
+			}
+		}
+		for i, value := range row.MetricValues {
+			if col := len(row.DimensionValues) + i; col < len(headers) {
+				cells[r][col] = FormatMetricValue(value.Value, opts.NumberFormat)
+			}
+		}
+		for col, cell := range cells[r] {
+			if cell != "" && !isNumericCell(cell) {
+				numeric[col] = false
+			}
+		}
+	}
+
+	escape := func(s string) string { return s }
+	if opts.Markdown {
+		escape = func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+	}
+
+	colWidths := make([]int, len(headers))
+	for i, header := range headers {
+		headers[i] = TruncateToWidth(escape(header), opts.MaxColWidth)
+		colWidths[i] = DisplayWidth(headers[i])
+	}
+	for _, row := range cells {
+		for i, cell := range row {
+			row[i] = TruncateToWidth(escape(cell), opts.MaxColWidth)
+			if w := DisplayWidth(row[i]); w > colWidths[i] {
+				colWidths[i] = w
+			}
+		}
+	}
+	if opts.Markdown {
+		// Markdown needs at least three dashes per separator
+		for i := range colWidths {
+			if colWidths[i] < 3 {
+				colWidths[i] = 3
+			}
+		}
+	}
+
+	formatRow := func(values []string) string {
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = pad(value, colWidths[i], numeric[i])
+		}
+		return "| " + strings.Join(parts, " | ") + " |"
+	}
+
+	var lines []string
+	lines = append(lines, formatRow(headers))
+
+	separatorParts := make([]string, len(headers))
+	for i, w := range colWidths {
+		switch {
+		case opts.Markdown && numeric[i]:
+			separatorParts[i] = " " + strings.Repeat("-", w-1) + ": "
+		case opts.Markdown:
+			separatorParts[i] = " " + strings.Repeat("-", w) + " "
+		default:
+			separatorParts[i] = strings.Repeat("-", w+2)
+		}
+	}
+	lines = append(lines, "|"+strings.Join(separatorParts, "|")+"|")
+
+	for _, row := range cells {
+		lines = append(lines, formatRow(row))
+	}
+
+	if opts.Offset > 0 && len(displayRows) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("Showing rows %d-%d of %d", opts.Offset+1, opts.Offset+len(displayRows), len(result.Rows)))
+	} else if len(displayRows) < len(result.Rows) {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("Showing %d of %d rows", len(displayRows), len(result.Rows)))
+	}
+
+	return lines
+}
+
+// FormatMetricValue prints whole numbers without decimals and everything else
+// with two, optionally grouping thousands
+func FormatMetricValue(value string, group bool) string {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+
+	var formatted string
+	if number == float64(int64(number)) {
+		formatted = strconv.FormatInt(int64(number), 10)
+	} else {
+		formatted = strconv.FormatFloat(number, 'f', 2, 64)
+	}
+	if group {
+		formatted = groupThousands(formatted)
+	}
+	return formatted
+}
+
+// groupThousands inserts commas into the integer part of a formatted number
+func groupThousands(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i:]
+	}
+	if len(integer) <= 3 {
+		return sign + integer + fraction
+	}
+
+	var b strings.Builder
+	lead := len(integer) % 3
+	if lead > 0 {
+		b.WriteString(integer[:lead])
+	}
+	for i := lead; i < len(integer); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(integer[i : i+3])
+	}
+	return sign + b.String() + fraction
+}
+
+func isNumericCell(s string) bool {
+	_, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	return err == nil
+}
+
+// DisplayWidth counts terminal columns: East Asian wide characters take two,
+// combining marks and other zero-width runes none
+func DisplayWidth(s string) int {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	return total
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0x1F300 && r <= 0x1FAFF:
+		// Emoji render double-width in most terminals
+		return 2
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// TruncateToWidth shortens s to at most maxWidth columns, ending it with
+// "..." when there's room
+func TruncateToWidth(s string, maxWidth int) string {
+	if maxWidth <= 0 || DisplayWidth(s) <= maxWidth {
+		return s
+	}
+
+	ellipsis := "..."
+	limit := maxWidth - len(ellipsis)
+	if limit <= 0 {
+		ellipsis, limit = "", maxWidth
+	}
+
+	var b strings.Builder
+	used := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		w := runeWidth(r)
+		if used+w > limit {
+			break
+		}
+		b.WriteString(s[:size])
+		used += w
+		s = s[size:]
+	}
+	return b.String() + ellipsis
+}
+
+// pad fills s with spaces to w columns, on the left when right-aligning
+func pad(s string, w int, right bool) string {
+	gap := w - DisplayWidth(s)
+	if gap <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", gap) + s
+	}
+	return s + strings.Repeat(" ", gap)
+}
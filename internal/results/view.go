@@ -0,0 +1,158 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ga4admin/internal/config"
+	"ga4admin/internal/query"
+)
+
+// ErrInvalidView means a view's name, filter or ordering was rejected
+var ErrInvalidView = errors.New("invalid result view")
+
+// viewName keeps view names usable as shell arguments and distinct from the
+// query_<seconds> IDs of results cached by earlier versions
+var viewName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ViewOrder is one field of a view's ordering
+type ViewOrder struct {
+	Field string
+	Desc  bool
+}
+
+// ParseViewOrder parses a comma-separated ordering such as
+// "sessions desc, country". Fields sort ascending unless followed by desc.
+func ParseViewOrder(value string) ([]ViewOrder, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var orders []ViewOrder
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("%w: invalid ordering '%s' (use 'field [asc|desc]', comma-separated)", ErrInvalidView, strings.TrimSpace(part))
+		}
+
+		order := ViewOrder{Field: words[0]}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				order.Desc = true
+			default:
+				return nil, fmt.Errorf("%w: invalid sort direction '%s' for %s (use asc or desc)", ErrInvalidView, words[1], order.Field)
+			}
+		}
+		if seen[order.Field] {
+			return nil, fmt.Errorf("%w: %s is ordered by twice", ErrInvalidView, order.Field)
+		}
+		seen[order.Field] = true
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// FormatViewOrder is the canonical form of an ordering, as stored with a view
+func FormatViewOrder(orders []ViewOrder) string {
+	parts := make([]string, len(orders))
+	for i, order := range orders {
+		parts[i] = order.Field
+		if order.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ApplyView filters and orders a result. where is a DuckDB expression over
+// the result's columns ("" keeps every row); rows that tie on orderBy keep
+// their cached order. Totals, minimums and maximums are dropped.
+func ApplyView(ctx context.Context, result *query.QueryResult, where, orderBy string) (*query.QueryResult, error) {
+	orders, err := ParseViewOrder(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]bool)
+	for _, header := range result.DimensionHeaders {
+		columns[header.Name] = true
+	}
+	for _, header := range result.MetricHeaders {
+		columns[header.Name] = true
+	}
+
+	var terms []string
+	for _, order := range orders {
+		if !columns[order.Field] {
+			return nil, fmt.Errorf("%w: can't order by '%s', which is not a column of the result", ErrInvalidView, order.Field)
+		}
+		term := quoteIdent(order.Field)
+		if order.Desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	terms = append(terms, "__row")
+
+	statement := "SELECT * EXCLUDE (__row) FROM result"
+	if strings.TrimSpace(where) != "" {
+		statement += " WHERE (" + where + ")"
+	}
+	statement += " ORDER BY " + strings.Join(terms, ", ")
+
+	viewed, err := reshape(ctx, result, func(*sql.Conn) (string, error) {
+		return statement, nil
+	}, len(result.DimensionHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidView, err)
+	}
+
+	// Keep the GA4 metric types, which reshape only guesses for pivots
+	viewed.MetricHeaders = result.MetricHeaders
+	return viewed, nil
+}
+
+// CreateView saves a view named name over a cached result. The view is
+// applied once first, so a filter DuckDB rejects is reported here rather
+// than when the view is shown.
+func (m *Manager) CreateView(ctx context.Context, name, queryID, where, orderBy, description string) (*config.ResultView, error) {
+	if !viewName.MatchString(name) || strings.HasPrefix(name, "query_") {
+		return nil, fmt.Errorf("%w: invalid name '%s' (use letters, digits, '.', '_' and '-', not starting with 'query_')", ErrInvalidView, name)
+	}
+
+	result, err := m.GetResult(ctx, queryID)
+	if err != nil {
+		return nil, err
+	}
+	if result.ViewName != "" {
+		return nil, fmt.Errorf("%w: '%s' is itself a view; create views of results", ErrInvalidView, queryID)
+	}
+
+	orders, err := ParseViewOrder(orderBy)
+	if err != nil {
+		return nil, err
+	}
+	view := config.ResultView{
+		Name:        name,
+		PropertyID:  result.PropertyID,
+		QueryID:     result.QueryID,
+		Where:       strings.TrimSpace(where),
+		OrderBy:     FormatViewOrder(orders),
+		Description: description,
+	}
+	if _, err := ApplyView(ctx, result, view.Where, view.OrderBy); err != nil {
+		return nil, err
+	}
+
+	if err := m.cacheClient.CreateResultView(ctx, &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
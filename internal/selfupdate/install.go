@@ -0,0 +1,51 @@
+package selfupdate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Ways ga4admin can be installed
+const (
+	InstallBinary   = "binary"   // Downloaded release binary or source build
+	InstallHomebrew = "homebrew" // brew install ga4admin
+	InstallScoop    = "scoop"    // scoop install ga4admin
+)
+
+// DetectInstall tells from the resolved path of the running binary whether a
+// package manager installed it. Package managers track the files they install,
+// so those binaries must be upgraded through them rather than replaced.
+func DetectInstall(executable string) string {
+	path := strings.ToLower(filepath.ToSlash(executable))
+	switch {
+	case strings.Contains(path, "/cellar/ga4admin/"), strings.Contains(path, "/linuxbrew/"):
+		return InstallHomebrew
+	case strings.Contains(path, "/scoop/apps/ga4admin/"), strings.Contains(path, "/scoop/shims/"):
+		return InstallScoop
+	}
+	return InstallBinary
+}
+
+// UpgradeCommand returns the command that installs the latest release for an
+// install method
+func UpgradeCommand(method, channel string) string {
+	switch method {
+	case InstallHomebrew:
+		return "brew upgrade ga4admin"
+	case InstallScoop:
+		return "scoop update ga4admin"
+	}
+	if channel == ChannelBeta {
+		return "ga4admin self-update --channel beta"
+	}
+	return "ga4admin self-update"
+}
+
+// ChannelFor returns the channel a version was released on: pre-release
+// builds follow the beta channel, so their users hear about newer betas
+func ChannelFor(current string) string {
+	if v, ok := parseVersion(current); ok && v.pre != "" {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
@@ -0,0 +1,107 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ga4admin/internal/config"
+)
+
+// CheckInterval is how often the background check queries the release feed
+// and the most often the update notice is shown
+const CheckInterval = 24 * time.Hour
+
+// NoticeEnvVar turns the background update check off when set to any value,
+// e.g. on machines where updates are rolled out centrally
+const NoticeEnvVar = "GA4ADMIN_NO_UPDATE_NOTIFIER"
+
+// checkStateFile records the last background check in the data directory
+const checkStateFile = "update-check.json"
+
+// checkState is what the background check remembers between runs
+type checkState struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Latest     string    `json:"latest,omitempty"` // Newest version on the channel; empty if the check failed
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+// PendingNotice returns the newer version an earlier background check found,
+// or "" when there is none or the notice was already shown within
+// CheckInterval. A returned version is recorded as shown.
+func PendingNotice(current string) string {
+	state := loadCheckState()
+	if state.Latest == "" || CompareVersions(state.Latest, current) <= 0 || time.Since(state.NotifiedAt) < CheckInterval {
+		return ""
+	}
+	state.NotifiedAt = time.Now()
+	saveCheckState(state)
+	return state.Latest
+}
+
+// CheckDue reports whether the background check should query the feed again
+func CheckDue() bool {
+	return time.Since(loadCheckState().CheckedAt) >= CheckInterval
+}
+
+// RefreshCheck queries the feed for the newest release on current's channel
+// and records it for PendingNotice. Failures are recorded too, so an offline
+// machine waits a full interval before trying again.
+func RefreshCheck(ctx context.Context, client *http.Client, feedURL, current string) {
+	state := loadCheckState()
+	state.CheckedAt = time.Now()
+	state.Latest = ""
+	if release, err := Latest(ctx, client, feedURL, ChannelFor(current)); err == nil {
+		state.Latest = release.Version()
+	}
+	saveCheckState(state)
+}
+
+func checkStatePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, checkStateFile), nil
+}
+
+func loadCheckState() checkState {
+	var state checkState
+	path, err := checkStatePath()
+	if err != nil {
+		return state
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// saveCheckState writes through a temporary file, since the background check
+// may be cut off when the command exits
+func saveCheckState(state checkState) {
+	path, err := checkStatePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+checkStateFile+"-*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,340 @@
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Release channels
+const (
+	ChannelStable = "stable" // Published releases only
+	ChannelBeta   = "beta"   // Also pre-releases
+)
+
+// Release asset names besides the per-platform binaries
+const (
+	ChecksumsAsset = "checksums.txt"     // sha256sum output for every binary
+	SignatureAsset = "checksums.txt.sig" // Base64 ed25519 signature of checksums.txt
+)
+
+// DefaultFeedURL lists the project's GitHub releases
+const DefaultFeedURL = "https://api.github.com/repos/avisekrath/csga4/releases"
+
+// FeedEnvVar points self-update at another release feed, e.g. an internal mirror
+const FeedEnvVar = "GA4ADMIN_UPDATE_FEED"
+
+// FeedURL returns the release feed to check
+func FeedURL() string {
+	if feed := os.Getenv(FeedEnvVar); feed != "" {
+		return feed
+	}
+	return DefaultFeedURL
+}
+
+// PublicKey is the base64 ed25519 key release checksums are signed with. It
+// is set at build time (-ldflags "-X ga4admin/internal/selfupdate.PublicKey=...");
+// development builds have none and can only verify checksums.
+var PublicKey = ""
+
+// maxBinarySize guards against a bad feed filling the disk
+const maxBinarySize = 200 << 20
+
+// Release is one published version
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	URL        string  `json:"html_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// Version returns the release's version without the leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset with the given name
+func (r *Release) Asset(name string) (*Asset, error) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no %s asset", r.Tag, name)
+}
+
+// BinaryAssetName is the release asset built for a platform
+func BinaryAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("ga4admin-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest release on a channel. Drafts are never offered;
+// pre-releases only on the beta channel.
+func Latest(ctx context.Context, client *http.Client, feedURL, channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, fmt.Errorf("unknown channel '%s' (use %s or %s)", channel, ChannelStable, ChannelBeta)
+	}
+
+	body, err := fetch(ctx, client, feedURL, 10<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release feed: %w", err)
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to decode release feed: %w", err)
+	}
+
+	var latest *Release
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || (release.Prerelease && channel != ChannelBeta) {
+			continue
+		}
+		if _, ok := parseVersion(release.Version()); !ok {
+			continue
+		}
+		if latest == nil || CompareVersions(release.Version(), latest.Version()) > 0 {
+			latest = release
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no %s releases found", channel)
+	}
+	return latest, nil
+}
+
+// Download fetches an asset's contents
+func Download(ctx context.Context, client *http.Client, asset *Asset) ([]byte, error) {
+	data, err := fetch(ctx, client, asset.DownloadURL, maxBinarySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+// VerifySignature checks checksums.txt against its detached signature
+func VerifySignature(checksums, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), checksums, sig) {
+		return fmt.Errorf("signature does not match %s", ChecksumsAsset)
+	}
+	return nil
+}
+
+// VerifyChecksum checks a downloaded file against its line in checksums.txt
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary mode with a leading '*'
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s lists no checksum for %s", ChecksumsAsset, name)
+}
+
+// ReplaceExecutable swaps the binary at path for data. The new file is written
+// next to the old one and renamed over it, so an interrupted update never
+// leaves a half-written binary behind.
+func ReplaceExecutable(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".ga4admin-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s (try again with sudo): %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0111); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	// Windows can't overwrite a running executable, but it can rename it
+	old := path + ".old"
+	os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Rename(old, path)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(old)
+	return nil
+}
+
+// CompareVersions orders two versions like "1.2.3" or "1.3.0-beta.1",
+// returning -1, 0 or 1. A pre-release sorts before its release. Unparseable
+// versions sort first.
+func CompareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if va.numbers[i] != vb.numbers[i] {
+			if va.numbers[i] < vb.numbers[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	case comparePrerelease(va.pre, vb.pre) < 0:
+		return -1
+	default:
+		return 1
+	}
+}
+
+type version struct {
+	numbers [3]int
+	pre     string
+}
+
+func parseVersion(value string) (version, bool) {
+	var v version
+	value = strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if i := strings.IndexByte(value, '+'); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.IndexByte(value, '-'); i >= 0 {
+		v.pre = value[i+1:]
+		value = value[:i]
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+// comparePrerelease compares dot-separated identifiers, numerically when both
+// are numbers (so beta.10 sorts after beta.9)
+func comparePrerelease(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		na, errA := strconv.Atoi(partsA[i])
+		nb, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && partsA[i] != partsB[i]:
+			if partsA[i] < partsB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(partsA) < len(partsB):
+		return -1
+	case len(partsA) > len(partsB):
+		return 1
+	}
+	return 0
+}
+
+func fetch(ctx context.Context, client *http.Client, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json, application/octet-stream")
+	req.Header.Set("User-Agent", "ga4admin-self-update")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
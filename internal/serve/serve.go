@@ -0,0 +1,461 @@
+// Package serve answers read-only SQL over HTTP. The named result tables and
+// rollups of a preset's cache are loaded into an in-memory DuckDB database,
+// so tools like Metabase or Hex can query them without a copy of the cache
+// file, and nothing they send can change the cache.
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ga4admin/internal/cache"
+	"ga4admin/internal/duckdb"
+	"ga4admin/internal/query"
+	"ga4admin/internal/results"
+)
+
+// Defaults for Options
+const (
+	DefaultMaxRows      = 100000
+	DefaultQueryTimeout = time.Minute
+)
+
+// TokenEnv is the environment variable holding the token clients must send
+const TokenEnv = "GA4ADMIN_SERVE_TOKEN"
+
+// maxStatementBytes bounds the size of a request body
+const maxStatementBytes = 1 << 20
+
+// Options configure a server
+type Options struct {
+	Preset       string        // Preset whose cache is served
+	PropertyID   string        // Only serve this property's tables; empty serves all
+	Token        string        // Bearer token clients must send
+	MaxRows      int           // Rows returned per statement before truncating
+	QueryTimeout time.Duration // Longest a statement may run
+}
+
+// Server serves SQL over the loaded tables
+type Server struct {
+	opts Options
+	db   *sql.DB
+
+	// mu is held for writing while tables are reloaded, and for reading
+	// while statements run
+	mu     sync.RWMutex
+	loaded map[string]string // Table → the cache version it was loaded from
+}
+
+// Column describes a column of a statement's result
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Response is the JSON answer to a statement
+type Response struct {
+	Columns   []Column        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	RowCount  int             `json:"row_count"`
+	Truncated bool            `json:"truncated,omitempty"` // More rows than MaxRows matched
+}
+
+// New opens the server's database. External access is disabled and the
+// configuration locked, so statements can't read files or attach the cache.
+func New(opts Options) (*Server, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("a token is required")
+	}
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = DefaultMaxRows
+	}
+	if opts.QueryTimeout <= 0 {
+		opts.QueryTimeout = DefaultQueryTimeout
+	}
+
+	db, err := duckdb.Open("?enable_external_access=false&lock_configuration=true")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+	return &Server{opts: opts, db: db, loaded: make(map[string]string)}, nil
+}
+
+// GenerateToken returns a random token for servers started without one
+func GenerateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Close closes the server's database
+func (s *Server) Close() error {
+	return s.db.Close()
+}
+
+// Handler routes /sql
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sql", s.handleSQL)
+	return mux
+}
+
+// Tables returns the names of the loaded tables
+func (s *Server) Tables() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.loaded))
+	for name := range s.loaded {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Sync loads named tables and rollups that are new or changed since the
+// last sync and drops those that are gone. The cache is opened only for the
+// sync, so other ga4admin commands can use it in between.
+func (s *Server) Sync(ctx context.Context) error {
+	cacheClient, err := cache.NewCacheClient(s.opts.Preset)
+	if err != nil {
+		return fmt.Errorf("failed to open cache: %w", err)
+	}
+	defer cacheClient.Close()
+
+	// Table → the name the result is read by and its version
+	type source struct{ name, version string }
+	wanted := make(map[string]source)
+	named, err := cacheClient.NamedTableResults(ctx, s.opts.PropertyID)
+	if err != nil {
+		return fmt.Errorf("failed to list named tables: %w", err)
+	}
+	for name, queryID := range named {
+		wanted[name] = source{name: queryID, version: queryID}
+	}
+	rollups, err := cacheClient.ListRollups(ctx, s.opts.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, rollup := range rollups {
+		wanted[cache.RollupTable(rollup.Name)] = source{name: rollup.Name, version: rollup.RefreshedAt.String()}
+	}
+
+	s.mu.RLock()
+	changed := len(wanted) != len(s.loaded)
+	for table, src := range wanted {
+		if s.loaded[table] != src.version {
+			changed = true
+		}
+	}
+	s.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manager := results.NewManager(cacheClient)
+	for table := range s.loaded {
+		if _, ok := wanted[table]; ok {
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdent(table)); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", table, err)
+		}
+		delete(s.loaded, table)
+	}
+	for table, src := range wanted {
+		if s.loaded[table] == src.version {
+			continue
+		}
+		result, err := manager.GetResult(ctx, src.name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if err := s.load(ctx, table, result); err != nil {
+			return err
+		}
+		s.loaded[table] = src.version
+	}
+	return nil
+}
+
+// load replaces table with a result's rows: the date dimension as a DATE,
+// other dimensions as text, integer metrics as BIGINT and the rest as DOUBLE
+func (s *Server) load(ctx context.Context, table string, result *query.QueryResult) error {
+	var definitions []string
+	for _, header := range result.DimensionHeaders {
+		columnType := "VARCHAR"
+		if header.Name == "date" {
+			columnType = "DATE"
+		}
+		definitions = append(definitions, quoteIdent(header.Name)+" "+columnType)
+	}
+	for _, header := range result.MetricHeaders {
+		columnType := "DOUBLE"
+		if header.Type == "TYPE_INTEGER" {
+			columnType = "BIGINT"
+		}
+		definitions = append(definitions, quoteIdent(header.Name)+" "+columnType)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", table, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdent(table)); err != nil {
+		return fmt.Errorf("failed to load %s: %w", table, err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(table), strings.Join(definitions, ", "))); err != nil {
+		return fmt.Errorf("failed to load %s: %w", table, err)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(definitions)), ", ")
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(table), placeholders))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", table, err)
+	}
+	defer insert.Close()
+
+	for _, row := range result.Rows {
+		values := make([]interface{}, 0, len(definitions))
+		for j, header := range result.DimensionHeaders {
+			value := ""
+			if j < len(row.DimensionValues) {
+				value = row.DimensionValues[j].Value
+			}
+			if header.Name != "date" {
+				values = append(values, value)
+				continue
+			}
+			// GA4 returns dates as YYYYMMDD
+			day, err := time.Parse("20060102", value)
+			if err != nil {
+				return fmt.Errorf("unexpected date '%s' in %s", value, table)
+			}
+			values = append(values, day)
+		}
+		for j, header := range result.MetricHeaders {
+			var value interface{}
+			if j < len(row.MetricValues) {
+				if number, err := strconv.ParseFloat(row.MetricValues[j].Value, 64); err == nil {
+					value = number
+					if header.Type == "TYPE_INTEGER" {
+						value = int64(number)
+					}
+				}
+			}
+			values = append(values, value)
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("failed to load %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to load %s: %w", table, err)
+	}
+	return nil
+}
+
+// Query runs one SELECT statement over the loaded tables
+func (s *Server) Query(ctx context.Context, statement string) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.QueryTimeout)
+	defer cancel()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := duckdb.CheckSelect(conn, statement); err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	response := &Response{Columns: make([]Column, len(types)), Rows: [][]interface{}{}}
+	for i, columnType := range types {
+		response.Columns[i] = Column{Name: columnType.Name(), Type: columnType.DatabaseTypeName()}
+	}
+
+	for rows.Next() {
+		if len(response.Rows) == s.opts.MaxRows {
+			response.Truncated = true
+			break
+		}
+		values := make([]interface{}, len(types))
+		targets := make([]interface{}, len(types))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			values[i] = jsonValue(value, response.Columns[i].Type)
+		}
+		response.Rows = append(response.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	response.RowCount = len(response.Rows)
+	return response, nil
+}
+
+// jsonValue converts a scanned value to one that encodes readably
+func jsonValue(value interface{}, columnType string) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		if columnType == "DATE" {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case []byte:
+		return string(v)
+	}
+	return duckdb.PlainValue(value)
+}
+
+// handleSQL answers GET /sql?q=... and POST /sql with the statement as the
+// body, either as text or as {"sql": "..."}. The answer is JSON, or CSV with
+// ?format=csv or "Accept: text/csv".
+func (s *Server) handleSQL(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ga4admin"`)
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+		return
+	}
+
+	var statement string
+	switch r.Method {
+	case http.MethodGet:
+		statement = r.URL.Query().Get("q")
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxStatementBytes))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		statement = string(body)
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			var request struct {
+				SQL string `json:"sql"`
+			}
+			if err := json.Unmarshal(body, &request); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+				return
+			}
+			statement = request.SQL
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use GET or POST"))
+		return
+	}
+	if strings.TrimSpace(statement) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("no SQL statement given"))
+		return
+	}
+
+	// Serve what is already loaded if the cache is busy
+	if err := s.Sync(r.Context()); err != nil {
+		if len(s.Tables()) == 0 {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  Serving previously loaded tables: %v\n", err)
+	}
+
+	response, err := s.Query(r.Context(), statement)
+	switch {
+	case errors.Is(err, duckdb.ErrNotSelect):
+		writeError(w, http.StatusForbidden, err)
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("the statement ran longer than %s", s.opts.QueryTimeout))
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		writeCSV(w, response)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorized compares the bearer token in constant time
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+func writeCSV(w http.ResponseWriter, response *Response) {
+	w.Header().Set("Content-Type", "text/csv")
+	if response.Truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
+	writer := csv.NewWriter(w)
+	header := make([]string, len(response.Columns))
+	for i, column := range response.Columns {
+		header[i] = column.Name
+	}
+	writer.Write(header)
+	for _, row := range response.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,149 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"ga4admin/internal/api"
+	"ga4admin/internal/config"
+	"ga4admin/internal/query"
+)
+
+// Columns prepended to merged results so rows can be traced to their property
+const (
+	ColumnMember     = "member"
+	ColumnPreset     = "preset"
+	ColumnPropertyID = "property_id"
+)
+
+// Job is one member's query, resolved from the workspace template
+type Job struct {
+	Member config.WorkspaceMember
+	Config *query.QueryConfig
+}
+
+// MemberResult reports the outcome of one member's query
+type MemberResult struct {
+	Member config.WorkspaceMember
+	query.MatrixResult
+}
+
+// ExecutorFactory opens a query executor authenticated as a preset. The
+// returned function releases it.
+type ExecutorFactory func(presetName string) (*query.Executor, func(), error)
+
+// Label names a member in output: its label, or preset/property
+func Label(member config.WorkspaceMember) string {
+	if member.Label != "" {
+		return member.Label
+	}
+	return member.Preset + "/" + member.PropertyID
+}
+
+// Run executes every job with its member's preset credentials. Jobs are
+// grouped by preset so each preset's client and cache are opened once;
+// within a group at most `concurrency` queries are in flight. progress is
+// called once per job as it finishes. Results are in job order.
+func Run(ctx context.Context, jobs []Job, concurrency int, newExecutor ExecutorFactory, progress func(MemberResult)) []MemberResult {
+	results := make([]MemberResult, len(jobs))
+
+	var presets []string
+	groups := make(map[string][]int)
+	for i, job := range jobs {
+		if _, ok := groups[job.Member.Preset]; !ok {
+			presets = append(presets, job.Member.Preset)
+		}
+		groups[job.Member.Preset] = append(groups[job.Member.Preset], i)
+	}
+
+	for _, presetName := range presets {
+		indexes := groups[presetName]
+		matrixJobs := make([]query.MatrixJob, len(indexes))
+		for n, i := range indexes {
+			matrixJobs[n] = query.MatrixJob{
+				Row:    query.MatrixRow{Line: i + 1, PropertyID: jobs[i].Member.PropertyID, StartDate: jobs[i].Config.StartDate, EndDate: jobs[i].Config.EndDate},
+				Config: jobs[i].Config,
+			}
+		}
+
+		executor, release, err := newExecutor(presetName)
+		if err != nil {
+			for n, i := range indexes {
+				results[i] = MemberResult{Member: jobs[i].Member, MatrixResult: query.MatrixResult{Job: matrixJobs[n], Err: fmt.Errorf("preset '%s': %w", presetName, err)}}
+				if progress != nil {
+					progress(results[i])
+				}
+			}
+			continue
+		}
+
+		// Results stay in memory until they are merged
+		keep := func(job query.MatrixJob, result *query.QueryResult) (string, error) { return "", nil }
+		executor.ExecuteMatrix(ctx, matrixJobs, concurrency, keep, func(result query.MatrixResult) {
+			i := result.Job.Row.Line - 1
+			results[i] = MemberResult{Member: jobs[i].Member, MatrixResult: result}
+			if progress != nil {
+				progress(results[i])
+			}
+		})
+		release()
+	}
+
+	return results
+}
+
+// Merge stacks the successful results into one result with member, preset
+// and property_id columns in front. All results must come from the same
+// template, so they share dimensions and metrics.
+func Merge(results []MemberResult) *query.QueryResult {
+	var merged *query.QueryResult
+	for _, result := range results {
+		if result.Err != nil || result.Result == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &query.QueryResult{
+				ExecutedAt:    result.Result.ExecutedAt,
+				MetricHeaders: result.Result.MetricHeaders,
+				DimensionHeaders: append([]api.DimensionHeader{
+					{Name: ColumnMember}, {Name: ColumnPreset}, {Name: ColumnPropertyID},
+				}, result.Result.DimensionHeaders...),
+			}
+		}
+
+		prefix := []api.DimensionValue{
+			{Value: Label(result.Member)}, {Value: result.Member.Preset}, {Value: result.Member.PropertyID},
+		}
+		for _, row := range result.Result.Rows {
+			merged.Rows = append(merged.Rows, api.Row{
+				DimensionValues: append(append([]api.DimensionValue{}, prefix...), row.DimensionValues...),
+				MetricValues:    row.MetricValues,
+			})
+		}
+	}
+
+	if merged != nil {
+		merged.RowCount = len(merged.Rows)
+	}
+	return merged
+}
+
+// Currencies lists the currencies the successful results report revenue in.
+// More than one means merged revenue columns mix currencies.
+func Currencies(results []MemberResult) []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, result := range results {
+		if result.Err != nil || result.Result == nil || result.Result.ResponseMetadata == nil {
+			continue
+		}
+		if code := result.Result.ResponseMetadata.CurrencyCode; code != "" && !seen[code] {
+			seen[code] = true
+			currencies = append(currencies, code)
+		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}